@@ -1,6 +1,8 @@
 package collections
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"log"
@@ -105,3 +107,26 @@ func GetLogoBytes(app *pocketbase.PocketBase) ([]byte, string, error) {
 
 	return data, logo, nil
 }
+
+// GetVerificationKey returns the secret that signs document verification
+// tokens, generating and storing one the first time it is needed.
+func GetVerificationKey(app *pocketbase.PocketBase) ([]byte, error) {
+	record, err := GetAppSettings(app)
+	if err != nil {
+		return nil, err
+	}
+	if key := record.GetString("verification_key"); key != "" {
+		return hex.DecodeString(key)
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("could not generate verification key: %w", err)
+	}
+	record.Set("verification_key", hex.EncodeToString(key))
+	if err := app.Save(record); err != nil {
+		return nil, fmt.Errorf("could not save verification key: %w", err)
+	}
+	log.Println("app_settings: generated document verification key")
+	return key, nil
+}
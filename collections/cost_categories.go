@@ -0,0 +1,55 @@
+package collections
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// defaultCostCategories are the categories created the first time cost
+// categories are read. Material, labour and other are what purchase order
+// costs are classified as, so their codes must not change; names can be
+// changed on the cost categories settings page.
+var defaultCostCategories = []struct {
+	Code string
+	Name string
+}{
+	{"material", "Material"},
+	{"labour", "Labour & services"},
+	{"transport", "Transport & freight"},
+	{"site", "Site expenses"},
+	{"other", "Other"},
+}
+
+// GetCostCategories returns all cost categories in display order. If none
+// exist, the default categories are created first.
+func GetCostCategories(app *pocketbase.PocketBase) ([]*core.Record, error) {
+	col, err := app.FindCollectionByNameOrId("cost_categories")
+	if err != nil {
+		return nil, fmt.Errorf("cost_categories collection not found: %w", err)
+	}
+
+	records, err := app.FindRecordsByFilter(col, "", "sort_order,created", 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("could not query cost_categories: %w", err)
+	}
+	if len(records) > 0 {
+		return records, nil
+	}
+
+	for i, d := range defaultCostCategories {
+		record := core.NewRecord(col)
+		record.Set("code", d.Code)
+		record.Set("name", d.Name)
+		record.Set("sort_order", i+1)
+		record.Set("active", true)
+		if err := app.Save(record); err != nil {
+			return nil, fmt.Errorf("could not create default cost category %s: %w", d.Code, err)
+		}
+		records = append(records, record)
+	}
+	log.Println("cost_categories: created default cost categories")
+	return records, nil
+}
@@ -0,0 +1,37 @@
+package collections_test
+
+import (
+	"testing"
+
+	"projectcreation/collections"
+	"projectcreation/testhelpers"
+)
+
+func TestGetCostCategories_CreatesDefaultsOnce(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+
+	first, err := collections.GetCostCategories(app)
+	if err != nil {
+		t.Fatalf("GetCostCategories failed: %v", err)
+	}
+	codes := make(map[string]bool)
+	for _, r := range first {
+		codes[r.GetString("code")] = true
+		if !r.GetBool("active") {
+			t.Errorf("expected default category %s to be active", r.GetString("code"))
+		}
+	}
+	for _, code := range []string{"material", "labour", "other"} {
+		if !codes[code] {
+			t.Errorf("expected a default %q category", code)
+		}
+	}
+
+	second, err := collections.GetCostCategories(app)
+	if err != nil {
+		t.Fatalf("second GetCostCategories failed: %v", err)
+	}
+	if len(second) != len(first) {
+		t.Errorf("expected %d categories on the second call, got %d", len(first), len(second))
+	}
+}
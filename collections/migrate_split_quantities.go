@@ -0,0 +1,40 @@
+package collections
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/pocketbase/pocketbase"
+)
+
+// MigrateForwardedSplitQuantities marks the quantities of destinations split
+// before quantity-based splits as fully forwarded, so their remaining balance
+// is zero. Safe to call on every startup -- returns early if nothing to migrate.
+func MigrateForwardedSplitQuantities(app *pocketbase.PocketBase) error {
+	lines, err := app.FindRecordsByFilter(
+		"transfer_dc_dest_quantities",
+		"destination.is_split = true && forwarded_qty = 0 && quantity > 0",
+		"",
+		0,
+		0,
+		nil,
+	)
+	if err != nil {
+		return fmt.Errorf("migrate: could not query split destination quantities: %w", err)
+	}
+
+	if len(lines) == 0 {
+		return nil
+	}
+
+	log.Printf("migrate: marking %d split destination quantit(ies) as forwarded...\n", len(lines))
+
+	for _, line := range lines {
+		line.Set("forwarded_qty", line.GetInt("quantity"))
+		if err := app.Save(line); err != nil {
+			log.Printf("migrate: failed to mark destination quantity %s as forwarded: %v\n", line.Id, err)
+		}
+	}
+
+	return nil
+}
@@ -287,3 +287,66 @@ func TestMigrateOrphanBOQs_Idempotent(t *testing.T) {
 		t.Errorf("expected 1 project after idempotent runs, got %d", len(projects))
 	}
 }
+
+func TestMigrateForwardedSplitQuantities_MarksSplitDestinations(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	proj := testhelpers.CreateTestProject(t, app, "Split Project")
+
+	dcsCol, _ := app.FindCollectionByNameOrId("delivery_challans")
+	dc := core.NewRecord(dcsCol)
+	dc.Set("project", proj.Id)
+	dc.Set("dc_number", "TDC-001")
+	dc.Set("dc_type", "transfer")
+	dc.Set("status", "split")
+	dc.Set("challan_date", "2026-03-10")
+	if err := app.Save(dc); err != nil {
+		t.Fatalf("failed to create DC: %v", err)
+	}
+	tdcsCol, _ := app.FindCollectionByNameOrId("transfer_dcs")
+	tdc := core.NewRecord(tdcsCol)
+	tdc.Set("dc", dc.Id)
+	tdc.Set("tax_type", "cgst_sgst")
+	tdc.Set("num_destinations", 2)
+	if err := app.Save(tdc); err != nil {
+		t.Fatalf("failed to create transfer DC: %v", err)
+	}
+
+	shipTo := testhelpers.CreateTestAddress(t, app, proj.Id, "ship_to", "Site A")
+	destsCol, _ := app.FindCollectionByNameOrId("transfer_dc_destinations")
+	qtysCol, _ := app.FindCollectionByNameOrId("transfer_dc_dest_quantities")
+	lines := map[bool]*core.Record{}
+	for _, isSplit := range []bool{true, false} {
+		dest := core.NewRecord(destsCol)
+		dest.Set("transfer_dc", tdc.Id)
+		dest.Set("ship_to_address", shipTo.Id)
+		dest.Set("is_split", isSplit)
+		if err := app.Save(dest); err != nil {
+			t.Fatalf("failed to create destination: %v", err)
+		}
+		line := core.NewRecord(qtysCol)
+		line.Set("destination", dest.Id)
+		line.Set("source_item_type", "sub_item")
+		line.Set("source_item_id", "x")
+		line.Set("quantity", 5)
+		if err := app.Save(line); err != nil {
+			t.Fatalf("failed to create destination quantity: %v", err)
+		}
+		lines[isSplit] = line
+	}
+
+	// Run twice
+	for i := 0; i < 2; i++ {
+		if err := collections.MigrateForwardedSplitQuantities(app); err != nil {
+			t.Fatalf("MigrateForwardedSplitQuantities() run %d error: %v", i+1, err)
+		}
+	}
+
+	split, _ := app.FindRecordById("transfer_dc_dest_quantities", lines[true].Id)
+	if got := split.GetInt("forwarded_qty"); got != 5 {
+		t.Errorf("split destination forwarded_qty = %d, want 5", got)
+	}
+	pending, _ := app.FindRecordById("transfer_dc_dest_quantities", lines[false].Id)
+	if got := pending.GetInt("forwarded_qty"); got != 0 {
+		t.Errorf("pending destination forwarded_qty = %d, want 0", got)
+	}
+}
@@ -3,6 +3,7 @@ package collections
 import (
 	"fmt"
 	"log"
+	"slices"
 
 	"github.com/pocketbase/pocketbase"
 	"github.com/pocketbase/pocketbase/core"
@@ -22,6 +23,8 @@ func Setup(app *pocketbase.PocketBase) {
 		c.Fields.Add(&core.AutodateField{Name: "created", OnCreate: true})
 		c.Fields.Add(&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true})
 	})
+	// Signs the verification tokens printed on DC and PO PDFs
+	ensureField(app, "app_settings", &core.TextField{Name: "verification_key", Hidden: true})
 
 	// ── Projects (top-level container for BOQs and addresses) ────────
 	projects := ensureCollection(app, "projects", func(c *core.Collection) {
@@ -341,7 +344,7 @@ func Setup(app *pocketbase.PocketBase) {
 	})
 
 	// ── PO Line Items ────────────────────────────────────────────────
-	ensureCollection(app, "po_line_items", func(c *core.Collection) {
+	poLineItems := ensureCollection(app, "po_line_items", func(c *core.Collection) {
 		c.Fields.Add(&core.NumberField{Name: "sort_order", Required: true})
 		c.Fields.Add(&core.TextField{Name: "description", Required: true})
 		c.Fields.Add(&core.TextField{Name: "hsn_code"})
@@ -434,7 +437,7 @@ func Setup(app *pocketbase.PocketBase) {
 		c.Fields.Add(&core.NumberField{Name: "num_locations", Required: true})
 		c.Fields.Add(&core.SelectField{Name: "tax_type", Required: true, Values: []string{"cgst_sgst", "igst"}, MaxSelect: 1})
 		c.Fields.Add(&core.BoolField{Name: "reverse_charge"})
-		c.Fields.Add(&core.SelectField{Name: "status", Required: true, Values: []string{"draft", "issued"}, MaxSelect: 1})
+		c.Fields.Add(&core.SelectField{Name: "status", Required: true, Values: []string{"draft", "issued", "cancelled"}, MaxSelect: 1})
 		c.Fields.Add(&core.AutodateField{Name: "created", OnCreate: true})
 		c.Fields.Add(&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true})
 	})
@@ -444,7 +447,7 @@ func Setup(app *pocketbase.PocketBase) {
 		c.Fields.Add(&core.RelationField{Name: "project", Required: true, CollectionId: projects.Id, CascadeDelete: true, MaxSelect: 1})
 		c.Fields.Add(&core.TextField{Name: "dc_number", Required: true})
 		c.Fields.Add(&core.SelectField{Name: "dc_type", Required: true, Values: []string{"transit", "official", "transfer"}, MaxSelect: 1})
-		c.Fields.Add(&core.SelectField{Name: "status", Required: true, Values: []string{"draft", "issued", "splitting", "split"}, MaxSelect: 1})
+		c.Fields.Add(&core.SelectField{Name: "status", Required: true, Values: []string{"draft", "issued", "splitting", "split", "cancelled"}, MaxSelect: 1})
 		c.Fields.Add(&core.RelationField{Name: "template", CollectionId: dcTemplatesCol.Id, MaxSelect: 1})
 		c.Fields.Add(&core.RelationField{Name: "bill_from_address", CollectionId: addresses.Id, MaxSelect: 1})
 		c.Fields.Add(&core.RelationField{Name: "dispatch_from_address", CollectionId: addresses.Id, MaxSelect: 1})
@@ -474,7 +477,7 @@ func Setup(app *pocketbase.PocketBase) {
 	})
 
 	// Serial Numbers
-	ensureCollection(app, "serial_numbers", func(c *core.Collection) {
+	serialNumbersCol := ensureCollection(app, "serial_numbers", func(c *core.Collection) {
 		c.Fields.Add(&core.RelationField{Name: "project", Required: true, CollectionId: projects.Id, CascadeDelete: true, MaxSelect: 1})
 		c.Fields.Add(&core.RelationField{Name: "line_item", Required: true, CollectionId: dcLineItemsCol.Id, CascadeDelete: true, MaxSelect: 1})
 		c.Fields.Add(&core.TextField{Name: "serial_number", Required: true})
@@ -520,7 +523,7 @@ func Setup(app *pocketbase.PocketBase) {
 	})
 
 	// Transfer DC Destination Quantities
-	ensureCollection(app, "transfer_dc_dest_quantities", func(c *core.Collection) {
+	transferDCDestQtysCol := ensureCollection(app, "transfer_dc_dest_quantities", func(c *core.Collection) {
 		c.Fields.Add(&core.RelationField{Name: "destination", Required: true, CollectionId: transferDCDestsCol.Id, CascadeDelete: true, MaxSelect: 1})
 		c.Fields.Add(&core.SelectField{Name: "source_item_type", Required: true, Values: []string{"sub_item", "sub_sub_item"}, MaxSelect: 1})
 		c.Fields.Add(&core.TextField{Name: "source_item_id", Required: true})
@@ -539,6 +542,17 @@ func Setup(app *pocketbase.PocketBase) {
 	ensureField(app, "shipment_groups", &core.RelationField{Name: "transfer_dc", CollectionId: transferDCsCol.Id, MaxSelect: 1})
 	ensureField(app, "shipment_groups", &core.RelationField{Name: "split", CollectionId: transferDCSplitsCol.Id, MaxSelect: 1})
 
+	// Quantity-based splits: a split forwards part of a destination's
+	// quantities; forwarded_qty is how much of each has gone out so far, and
+	// the split quantities record what each split took so it can be undone
+	ensureField(app, "transfer_dc_dest_quantities", &core.NumberField{Name: "forwarded_qty"})
+	ensureCollection(app, "transfer_dc_split_quantities", func(c *core.Collection) {
+		c.Fields.Add(&core.RelationField{Name: "split", Required: true, CollectionId: transferDCSplitsCol.Id, CascadeDelete: true, MaxSelect: 1})
+		c.Fields.Add(&core.RelationField{Name: "dest_quantity", Required: true, CollectionId: transferDCDestQtysCol.Id, CascadeDelete: true, MaxSelect: 1})
+		c.Fields.Add(&core.NumberField{Name: "quantity", Required: true})
+		c.Fields.Add(&core.AutodateField{Name: "created", OnCreate: true})
+	})
+
 	// PO numbering config fields on projects
 	ensureField(app, "projects", &core.TextField{Name: "po_prefix"})
 	ensureField(app, "projects", &core.TextField{Name: "po_number_format"})
@@ -566,6 +580,444 @@ func Setup(app *pocketbase.PocketBase) {
 		CollectionId: addresses.Id,
 		MaxSelect:    1,
 	})
+
+	// ── Users (PocketBase's built-in auth collection) ────────────────
+	// role gates what a user may change; projects limits which projects
+	// they can see. Admins see every project regardless of membership.
+	ensureField(app, "users", &core.SelectField{
+		Name:      "role",
+		Values:    []string{"admin", "project_manager", "purchase", "logistics", "viewer"},
+		MaxSelect: 1,
+	})
+	ensureField(app, "users", &core.RelationField{
+		Name:         "projects",
+		CollectionId: projects.Id,
+		MaxSelect:    999,
+	})
+
+	// ── Audit Log (written by services.RegisterAuditHooks) ───────────
+	// document_collection/document_id point at the page-level record an
+	// entry belongs to (e.g. a sub item's BOQ) so History panels can list
+	// changes to a document and all of its children together.
+	usersCol, err := app.FindCollectionByNameOrId("users")
+	if err != nil {
+		log.Fatalf("Failed to find users collection: %v", err)
+	}
+	ensureCollection(app, "audit_log", func(c *core.Collection) {
+		c.Fields.Add(&core.TextField{Name: "collection_name", Required: true})
+		c.Fields.Add(&core.TextField{Name: "record_id", Required: true})
+		c.Fields.Add(&core.TextField{Name: "document_collection"})
+		c.Fields.Add(&core.TextField{Name: "document_id"})
+		c.Fields.Add(&core.TextField{Name: "project"})
+		c.Fields.Add(&core.SelectField{Name: "action", Required: true, Values: []string{"create", "update", "delete"}, MaxSelect: 1})
+		c.Fields.Add(&core.RelationField{Name: "actor", CollectionId: usersCol.Id, MaxSelect: 1})
+		c.Fields.Add(&core.TextField{Name: "actor_name"})
+		c.Fields.Add(&core.JSONField{Name: "changes", MaxSize: 200000})
+		c.Fields.Add(&core.AutodateField{Name: "created", OnCreate: true})
+		c.AddIndex("idx_audit_log_document", false, "document_collection, document_id", "")
+	})
+
+	// ── DC Cancellation (see services.CancelDC) ──────────────────────
+	ensureSelectValues(app, "delivery_challans", "status", "cancelled")
+	ensureSelectValues(app, "shipment_groups", "status", "cancelled")
+	ensureField(app, "delivery_challans", &core.TextField{Name: "cancel_reason"})
+	ensureField(app, "delivery_challans", &core.TextField{Name: "cancelled_at"})
+	ensureField(app, "delivery_challans", &core.RelationField{Name: "cancelled_by", CollectionId: usersCol.Id, MaxSelect: 1})
+	ensureField(app, "delivery_challans", &core.TextField{Name: "cancelled_by_name"})
+
+	// ── Over-dispatch override (see services.CheckOverDispatch) ──────
+	ensureField(app, "delivery_challans", &core.TextField{Name: "over_dispatch_reason"})
+
+	// ── Goods Receipt Notes (see services.CreateGRN) ─────────────────
+	// Each line records what arrived against a PO line item; only the
+	// accepted quantity (received − rejected) counts towards the order.
+	ensureSelectValues(app, "number_sequences", "sequence_type", "grn")
+	grnCol := ensureCollection(app, "grn", func(c *core.Collection) {
+		c.Fields.Add(&core.RelationField{Name: "project", Required: true, CollectionId: projects.Id, CascadeDelete: true, MaxSelect: 1})
+		c.Fields.Add(&core.RelationField{Name: "purchase_order", Required: true, CollectionId: purchaseOrders.Id, CascadeDelete: true, MaxSelect: 1})
+		c.Fields.Add(&core.TextField{Name: "grn_number", Required: true})
+		c.Fields.Add(&core.TextField{Name: "received_date"})
+		c.Fields.Add(&core.TextField{Name: "received_by"})
+		c.Fields.Add(&core.TextField{Name: "warehouse_location"})
+		c.Fields.Add(&core.TextField{Name: "transporter_name"})
+		c.Fields.Add(&core.TextField{Name: "lr_number"})
+		c.Fields.Add(&core.TextField{Name: "vehicle_number"})
+		c.Fields.Add(&core.TextField{Name: "remarks"})
+		c.Fields.Add(&core.AutodateField{Name: "created", OnCreate: true})
+		c.Fields.Add(&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true})
+	})
+	ensureCollection(app, "grn_line_items", func(c *core.Collection) {
+		c.Fields.Add(&core.RelationField{Name: "grn", Required: true, CollectionId: grnCol.Id, CascadeDelete: true, MaxSelect: 1})
+		c.Fields.Add(&core.RelationField{Name: "po_line_item", Required: true, CollectionId: poLineItems.Id, CascadeDelete: true, MaxSelect: 1})
+		c.Fields.Add(&core.TextField{Name: "description"})
+		c.Fields.Add(&core.TextField{Name: "uom"})
+		c.Fields.Add(&core.NumberField{Name: "rate"})
+		c.Fields.Add(&core.NumberField{Name: "qty_ordered"})
+		c.Fields.Add(&core.NumberField{Name: "qty_received"})
+		c.Fields.Add(&core.NumberField{Name: "qty_rejected"})
+		c.Fields.Add(&core.NumberField{Name: "qty_accepted"})
+		c.Fields.Add(&core.TextField{Name: "rejection_reason"})
+		c.Fields.Add(&core.AutodateField{Name: "created", OnCreate: true})
+		c.Fields.Add(&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true})
+	})
+
+	// ── Vendor Invoices (see services.CreateVendorInvoice) ─────────
+	// Invoice lines reference PO line items so they can be matched three
+	// ways: PO rate/qty vs GRN accepted qty vs invoiced qty/rate. The match
+	// result is stored on the invoice when it is recorded.
+	ensureField(app, "projects", &core.NumberField{Name: "invoice_qty_tolerance_pct"})
+	ensureField(app, "projects", &core.NumberField{Name: "invoice_rate_tolerance_pct"})
+	vendorInvoicesCol := ensureCollection(app, "vendor_invoices", func(c *core.Collection) {
+		c.Fields.Add(&core.RelationField{Name: "project", Required: true, CollectionId: projects.Id, CascadeDelete: true, MaxSelect: 1})
+		c.Fields.Add(&core.RelationField{Name: "purchase_order", Required: true, CollectionId: purchaseOrders.Id, CascadeDelete: true, MaxSelect: 1})
+		c.Fields.Add(&core.RelationField{Name: "vendor", Required: true, CollectionId: vendors.Id, MaxSelect: 1})
+		c.Fields.Add(&core.TextField{Name: "invoice_number", Required: true})
+		c.Fields.Add(&core.TextField{Name: "invoice_date"})
+		c.Fields.Add(&core.TextField{Name: "vendor_gstin"})
+		c.Fields.Add(&core.SelectField{Name: "match_status", Values: []string{"matched", "mismatch"}, MaxSelect: 1})
+		c.Fields.Add(&core.TextField{Name: "match_notes"})
+		c.Fields.Add(&core.TextField{Name: "remarks"})
+		c.Fields.Add(&core.AutodateField{Name: "created", OnCreate: true})
+		c.Fields.Add(&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true})
+	})
+	ensureCollection(app, "vendor_invoice_line_items", func(c *core.Collection) {
+		c.Fields.Add(&core.RelationField{Name: "vendor_invoice", Required: true, CollectionId: vendorInvoicesCol.Id, CascadeDelete: true, MaxSelect: 1})
+		c.Fields.Add(&core.RelationField{Name: "po_line_item", Required: true, CollectionId: poLineItems.Id, CascadeDelete: true, MaxSelect: 1})
+		c.Fields.Add(&core.TextField{Name: "description"})
+		c.Fields.Add(&core.TextField{Name: "uom"})
+		c.Fields.Add(&core.NumberField{Name: "qty"})
+		c.Fields.Add(&core.NumberField{Name: "rate"})
+		c.Fields.Add(&core.NumberField{Name: "gst_percent"})
+		c.Fields.Add(&core.AutodateField{Name: "created", OnCreate: true})
+		c.Fields.Add(&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true})
+	})
+
+	// ── Vendor Payments (see services.CreateVendorPayment) ─────────
+	// A payment settles one or more vendor invoices through allocations.
+	// TDS is deducted at source at the rate of the chosen section, so the
+	// amount paid out is the settled amount less TDS.
+	ensureField(app, "vendors", &core.NumberField{Name: "opening_balance"})
+	tdsSections := ensureCollection(app, "tds_sections", func(c *core.Collection) {
+		c.Fields.Add(&core.TextField{Name: "section", Required: true})
+		c.Fields.Add(&core.TextField{Name: "description"})
+		c.Fields.Add(&core.NumberField{Name: "rate_percent"})
+		c.Fields.Add(&core.BoolField{Name: "active"})
+		c.Fields.Add(&core.AutodateField{Name: "created", OnCreate: true})
+		c.Fields.Add(&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true})
+	})
+	vendorPaymentsCol := ensureCollection(app, "vendor_payments", func(c *core.Collection) {
+		c.Fields.Add(&core.RelationField{Name: "vendor", Required: true, CollectionId: vendors.Id, CascadeDelete: true, MaxSelect: 1})
+		c.Fields.Add(&core.TextField{Name: "payment_date", Required: true})
+		c.Fields.Add(&core.SelectField{Name: "mode", Required: true, Values: []string{"neft", "rtgs", "imps", "upi", "cheque", "cash"}, MaxSelect: 1})
+		c.Fields.Add(&core.TextField{Name: "utr_reference"})
+		c.Fields.Add(&core.NumberField{Name: "gross_amount"})
+		c.Fields.Add(&core.RelationField{Name: "tds_section", CollectionId: tdsSections.Id, MaxSelect: 1})
+		c.Fields.Add(&core.NumberField{Name: "tds_rate"})
+		c.Fields.Add(&core.NumberField{Name: "tds_amount"})
+		c.Fields.Add(&core.NumberField{Name: "net_amount"})
+		c.Fields.Add(&core.TextField{Name: "remarks"})
+		c.Fields.Add(&core.AutodateField{Name: "created", OnCreate: true})
+		c.Fields.Add(&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true})
+	})
+	ensureCollection(app, "vendor_payment_allocations", func(c *core.Collection) {
+		c.Fields.Add(&core.RelationField{Name: "vendor_payment", Required: true, CollectionId: vendorPaymentsCol.Id, CascadeDelete: true, MaxSelect: 1})
+		c.Fields.Add(&core.RelationField{Name: "vendor_invoice", Required: true, CollectionId: vendorInvoicesCol.Id, CascadeDelete: true, MaxSelect: 1})
+		c.Fields.Add(&core.NumberField{Name: "amount"})
+		c.Fields.Add(&core.AutodateField{Name: "created", OnCreate: true})
+		c.Fields.Add(&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true})
+	})
+
+	// ── Client Invoices (see services.CreateClientInvoice) ─────────
+	// A tax invoice bills the client for the lines of one or more issued
+	// official DCs. Each invoice line points at the DC line it bills, which
+	// is what stops a DC line from being invoiced twice. Description, HSN
+	// and pricing are copied so the invoice does not change afterwards.
+	ensureSelectValues(app, "number_sequences", "sequence_type", "inv")
+	clientInvoicesCol := ensureCollection(app, "client_invoices", func(c *core.Collection) {
+		c.Fields.Add(&core.RelationField{Name: "project", Required: true, CollectionId: projects.Id, CascadeDelete: true, MaxSelect: 1})
+		c.Fields.Add(&core.TextField{Name: "invoice_number", Required: true})
+		c.Fields.Add(&core.TextField{Name: "invoice_date", Required: true})
+		c.Fields.Add(&core.RelationField{Name: "bill_from_address", CollectionId: addresses.Id, MaxSelect: 1})
+		c.Fields.Add(&core.RelationField{Name: "bill_to_address", CollectionId: addresses.Id, MaxSelect: 1})
+		c.Fields.Add(&core.RelationField{Name: "ship_to_address", CollectionId: addresses.Id, MaxSelect: 1})
+		c.Fields.Add(&core.SelectField{Name: "tax_type", Required: true, Values: []string{"cgst_sgst", "igst"}, MaxSelect: 1})
+		c.Fields.Add(&core.BoolField{Name: "reverse_charge"})
+		c.Fields.Add(&core.NumberField{Name: "taxable_amount"})
+		c.Fields.Add(&core.NumberField{Name: "tax_amount"})
+		c.Fields.Add(&core.NumberField{Name: "round_off"})
+		c.Fields.Add(&core.NumberField{Name: "total_amount"})
+		c.Fields.Add(&core.TextField{Name: "remarks"})
+		c.Fields.Add(&core.AutodateField{Name: "created", OnCreate: true})
+		c.Fields.Add(&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true})
+	})
+	ensureCollection(app, "client_invoice_line_items", func(c *core.Collection) {
+		c.Fields.Add(&core.RelationField{Name: "client_invoice", Required: true, CollectionId: clientInvoicesCol.Id, CascadeDelete: true, MaxSelect: 1})
+		c.Fields.Add(&core.RelationField{Name: "dc", Required: true, CollectionId: dcCol.Id, CascadeDelete: true, MaxSelect: 1})
+		c.Fields.Add(&core.RelationField{Name: "dc_line_item", Required: true, CollectionId: dcLineItemsCol.Id, CascadeDelete: true, MaxSelect: 1})
+		c.Fields.Add(&core.TextField{Name: "description"})
+		c.Fields.Add(&core.TextField{Name: "hsn_code"})
+		c.Fields.Add(&core.TextField{Name: "uom"})
+		c.Fields.Add(&core.NumberField{Name: "quantity"})
+		c.Fields.Add(&core.NumberField{Name: "rate"})
+		c.Fields.Add(&core.NumberField{Name: "tax_percentage"})
+		c.Fields.Add(&core.NumberField{Name: "taxable_amount"})
+		c.Fields.Add(&core.NumberField{Name: "tax_amount"})
+		c.Fields.Add(&core.NumberField{Name: "total_amount"})
+		c.Fields.Add(&core.NumberField{Name: "line_order"})
+		c.Fields.Add(&core.AutodateField{Name: "created", OnCreate: true})
+		c.Fields.Add(&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true})
+		c.AddIndex("idx_client_invoice_line_dc_line", true, "dc_line_item", "")
+	})
+
+	// ── Billing Milestones & Receipts (see services.CreateMilestoneInvoice
+	// and services.CreateClientReceipt) ─────────────────────────────────
+	// A milestone bills a percentage of the project's BOQ quoted total. Its
+	// invoice is a client invoice with one lump-sum line kept on the invoice
+	// itself, since client_invoice_line_items only hold DC lines. Retention
+	// is the share of every invoice the client holds back until handover;
+	// receipts settle invoices through allocations.
+	ensureField(app, "projects", &core.NumberField{Name: "retention_percent"})
+	milestonesCol := ensureCollection(app, "billing_milestones", func(c *core.Collection) {
+		c.Fields.Add(&core.RelationField{Name: "project", Required: true, CollectionId: projects.Id, CascadeDelete: true, MaxSelect: 1})
+		c.Fields.Add(&core.TextField{Name: "name", Required: true})
+		c.Fields.Add(&core.NumberField{Name: "percentage"})
+		c.Fields.Add(&core.NumberField{Name: "sort_order"})
+		c.Fields.Add(&core.AutodateField{Name: "created", OnCreate: true})
+		c.Fields.Add(&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true})
+	})
+	ensureField(app, "client_invoices", &core.RelationField{Name: "billing_milestone", CollectionId: milestonesCol.Id, MaxSelect: 1})
+	ensureField(app, "client_invoices", &core.TextField{Name: "milestone_description"})
+	ensureField(app, "client_invoices", &core.TextField{Name: "sac_code"})
+	ensureField(app, "client_invoices", &core.NumberField{Name: "milestone_tax_percentage"})
+	ensureField(app, "client_invoices", &core.NumberField{Name: "retention_amount"})
+	clientReceiptsCol := ensureCollection(app, "client_receipts", func(c *core.Collection) {
+		c.Fields.Add(&core.RelationField{Name: "project", Required: true, CollectionId: projects.Id, CascadeDelete: true, MaxSelect: 1})
+		c.Fields.Add(&core.TextField{Name: "receipt_date", Required: true})
+		c.Fields.Add(&core.SelectField{Name: "mode", Required: true, Values: []string{"neft", "rtgs", "imps", "upi", "cheque", "cash"}, MaxSelect: 1})
+		c.Fields.Add(&core.TextField{Name: "reference"})
+		c.Fields.Add(&core.NumberField{Name: "amount"})
+		c.Fields.Add(&core.TextField{Name: "remarks"})
+		c.Fields.Add(&core.AutodateField{Name: "created", OnCreate: true})
+		c.Fields.Add(&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true})
+	})
+	ensureCollection(app, "client_receipt_allocations", func(c *core.Collection) {
+		c.Fields.Add(&core.RelationField{Name: "client_receipt", Required: true, CollectionId: clientReceiptsCol.Id, CascadeDelete: true, MaxSelect: 1})
+		c.Fields.Add(&core.RelationField{Name: "client_invoice", Required: true, CollectionId: clientInvoicesCol.Id, CascadeDelete: true, MaxSelect: 1})
+		c.Fields.Add(&core.NumberField{Name: "amount"})
+		c.Fields.Add(&core.AutodateField{Name: "created", OnCreate: true})
+		c.Fields.Add(&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true})
+	})
+
+	// ── Cost Categories & Budgets (see services.BuildBudgetVariance) ──
+	// Cost categories are shared by all projects; the built-in ones are
+	// identified by code so PO costs can be classified. A project has one
+	// budget per category, seeded from the BOQ budgeted prices. An alert is
+	// raised once per category and threshold (80% / 100% of budget).
+	costCategoriesCol := ensureCollection(app, "cost_categories", func(c *core.Collection) {
+		c.Fields.Add(&core.TextField{Name: "code", Required: true})
+		c.Fields.Add(&core.TextField{Name: "name", Required: true})
+		c.Fields.Add(&core.NumberField{Name: "sort_order"})
+		c.Fields.Add(&core.BoolField{Name: "active"})
+		c.Fields.Add(&core.AutodateField{Name: "created", OnCreate: true})
+		c.Fields.Add(&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true})
+		c.AddIndex("idx_cost_category_code", true, "code", "")
+	})
+	ensureCollection(app, "project_budgets", func(c *core.Collection) {
+		c.Fields.Add(&core.RelationField{Name: "project", Required: true, CollectionId: projects.Id, CascadeDelete: true, MaxSelect: 1})
+		c.Fields.Add(&core.RelationField{Name: "cost_category", Required: true, CollectionId: costCategoriesCol.Id, CascadeDelete: true, MaxSelect: 1})
+		c.Fields.Add(&core.NumberField{Name: "amount"})
+		c.Fields.Add(&core.AutodateField{Name: "created", OnCreate: true})
+		c.Fields.Add(&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true})
+		c.AddIndex("idx_project_budget_category", true, "project, cost_category", "")
+	})
+	ensureCollection(app, "budget_alerts", func(c *core.Collection) {
+		c.Fields.Add(&core.RelationField{Name: "project", Required: true, CollectionId: projects.Id, CascadeDelete: true, MaxSelect: 1})
+		c.Fields.Add(&core.RelationField{Name: "cost_category", Required: true, CollectionId: costCategoriesCol.Id, CascadeDelete: true, MaxSelect: 1})
+		c.Fields.Add(&core.NumberField{Name: "threshold"})
+		c.Fields.Add(&core.NumberField{Name: "budget"})
+		c.Fields.Add(&core.NumberField{Name: "spent"})
+		c.Fields.Add(&core.BoolField{Name: "dismissed"})
+		c.Fields.Add(&core.AutodateField{Name: "created", OnCreate: true})
+		c.Fields.Add(&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true})
+		c.AddIndex("idx_budget_alert_threshold", true, "project, cost_category, threshold", "")
+	})
+
+	// ── Site Expenses (see services.CreateExpense) ───────────────────
+	// Costs paid on site outside purchase orders. amount is before GST;
+	// only approved expenses count towards the project's costs.
+	ensureCollection(app, "expenses", func(c *core.Collection) {
+		c.Fields.Add(&core.RelationField{Name: "project", Required: true, CollectionId: projects.Id, CascadeDelete: true, MaxSelect: 1})
+		c.Fields.Add(&core.RelationField{Name: "cost_category", Required: true, CollectionId: costCategoriesCol.Id, MaxSelect: 1})
+		c.Fields.Add(&core.TextField{Name: "expense_date", Required: true})
+		c.Fields.Add(&core.TextField{Name: "description", Required: true})
+		c.Fields.Add(&core.TextField{Name: "bill_number"})
+		c.Fields.Add(&core.NumberField{Name: "amount"})
+		c.Fields.Add(&core.NumberField{Name: "gst_amount"})
+		c.Fields.Add(&core.TextField{Name: "paid_by", Required: true})
+		c.Fields.Add(&core.FileField{Name: "receipts", MaxSelect: 5, MaxSize: 5242880, MimeTypes: []string{"image/jpeg", "image/png", "application/pdf"}})
+		c.Fields.Add(&core.SelectField{Name: "status", Required: true, Values: []string{"pending", "approved", "rejected"}, MaxSelect: 1})
+		c.Fields.Add(&core.TextField{Name: "rejection_reason"})
+		c.Fields.Add(&core.TextField{Name: "reviewed_at"})
+		c.Fields.Add(&core.RelationField{Name: "reviewed_by", CollectionId: usersCol.Id, MaxSelect: 1})
+		c.Fields.Add(&core.TextField{Name: "reviewed_by_name"})
+		c.Fields.Add(&core.AutodateField{Name: "created", OnCreate: true})
+		c.Fields.Add(&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true})
+		c.AddIndex("idx_expense_project_status", false, "project, status", "")
+	})
+
+	// ── Installation Work Orders (see services.CreateWorkOrder) ──────
+	// A work order gives an installer the BOQ service items to carry out at
+	// a set of install-at sites. Each site tracks its own progress and the
+	// serial numbers installed there, picked from what was dispatched to
+	// the site's ship-to address.
+	ensureSelectValues(app, "number_sequences", "sequence_type", "wo")
+	workOrdersCol := ensureCollection(app, "work_orders", func(c *core.Collection) {
+		c.Fields.Add(&core.RelationField{Name: "project", Required: true, CollectionId: projects.Id, CascadeDelete: true, MaxSelect: 1})
+		c.Fields.Add(&core.TextField{Name: "wo_number", Required: true})
+		c.Fields.Add(&core.RelationField{Name: "installer", Required: true, CollectionId: vendors.Id, MaxSelect: 1})
+		c.Fields.Add(&core.TextField{Name: "wo_date", Required: true})
+		c.Fields.Add(&core.TextField{Name: "target_date"})
+		c.Fields.Add(&core.TextField{Name: "remarks"})
+		c.Fields.Add(&core.AutodateField{Name: "created", OnCreate: true})
+		c.Fields.Add(&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true})
+	})
+	ensureCollection(app, "work_order_lines", func(c *core.Collection) {
+		c.Fields.Add(&core.RelationField{Name: "work_order", Required: true, CollectionId: workOrdersCol.Id, CascadeDelete: true, MaxSelect: 1})
+		c.Fields.Add(&core.SelectField{Name: "source_item_type", Required: true, Values: []string{"sub_item", "sub_sub_item"}, MaxSelect: 1})
+		c.Fields.Add(&core.TextField{Name: "source_item_id", Required: true})
+		c.Fields.Add(&core.TextField{Name: "description"})
+		c.Fields.Add(&core.TextField{Name: "uom"})
+		c.Fields.Add(&core.NumberField{Name: "qty_per_site"})
+		c.Fields.Add(&core.NumberField{Name: "rate"})
+		c.Fields.Add(&core.NumberField{Name: "line_order"})
+	})
+	workOrderSitesCol := ensureCollection(app, "work_order_sites", func(c *core.Collection) {
+		c.Fields.Add(&core.RelationField{Name: "work_order", Required: true, CollectionId: workOrdersCol.Id, CascadeDelete: true, MaxSelect: 1})
+		c.Fields.Add(&core.RelationField{Name: "install_at_address", Required: true, CollectionId: addresses.Id, CascadeDelete: true, MaxSelect: 1})
+		c.Fields.Add(&core.SelectField{Name: "status", Required: true, Values: []string{"pending", "in_progress", "installed", "commissioned"}, MaxSelect: 1})
+		c.Fields.Add(&core.TextField{Name: "installation_date"})
+		c.Fields.Add(&core.TextField{Name: "remarks"})
+		c.Fields.Add(&core.RelationField{Name: "serial_numbers", CollectionId: serialNumbersCol.Id, MaxSelect: 999})
+		c.Fields.Add(&core.AutodateField{Name: "created", OnCreate: true})
+		c.Fields.Add(&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true})
+		c.AddIndex("idx_work_order_site_address", false, "install_at_address", "")
+	})
+
+	// ── Commissioning (see services.CreateCommissioning) ─────────────
+	// One commissioning record per install-at site, signed off by the
+	// client. test_results holds []services.CommissioningTest.
+	ensureSelectValues(app, "number_sequences", "sequence_type", "cc")
+	ensureCollection(app, "commissioning_records", func(c *core.Collection) {
+		c.Fields.Add(&core.RelationField{Name: "project", Required: true, CollectionId: projects.Id, CascadeDelete: true, MaxSelect: 1})
+		c.Fields.Add(&core.RelationField{Name: "install_at_address", Required: true, CollectionId: addresses.Id, CascadeDelete: true, MaxSelect: 1})
+		c.Fields.Add(&core.RelationField{Name: "work_order_site", CollectionId: workOrderSitesCol.Id, CascadeDelete: true, MaxSelect: 1})
+		c.Fields.Add(&core.TextField{Name: "certificate_number", Required: true})
+		c.Fields.Add(&core.TextField{Name: "commissioned_date", Required: true})
+		c.Fields.Add(&core.JSONField{Name: "test_results", MaxSize: 20000})
+		c.Fields.Add(&core.TextField{Name: "signed_off_by", Required: true})
+		c.Fields.Add(&core.TextField{Name: "signed_off_designation"})
+		c.Fields.Add(&core.TextField{Name: "remarks"})
+		c.Fields.Add(&core.AutodateField{Name: "created", OnCreate: true})
+		c.Fields.Add(&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true})
+		c.AddIndex("idx_commissioning_site", true, "install_at_address", "")
+	})
+
+	// ── Proof of Delivery (see services.RecordDelivery) ──────────────
+	// One delivery per official or transfer DC. Its lines record what
+	// arrived against each DC line; any shortage or damage leaves the DC
+	// partially delivered.
+	ensureSelectValues(app, "delivery_challans", "status", "delivered", "partially_delivered")
+	dcDeliveriesCol := ensureCollection(app, "dc_deliveries", func(c *core.Collection) {
+		c.Fields.Add(&core.RelationField{Name: "dc", Required: true, CollectionId: dcCol.Id, CascadeDelete: true, MaxSelect: 1})
+		c.Fields.Add(&core.TextField{Name: "received_by", Required: true})
+		c.Fields.Add(&core.TextField{Name: "received_date", Required: true})
+		c.Fields.Add(&core.TextField{Name: "remarks"})
+		c.Fields.Add(&core.FileField{Name: "signed_challans", MaxSelect: 5, MaxSize: 5242880, MimeTypes: []string{"image/jpeg", "image/png", "application/pdf"}})
+		c.Fields.Add(&core.AutodateField{Name: "created", OnCreate: true})
+		c.Fields.Add(&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true})
+		c.AddIndex("idx_dc_delivery_dc", true, "dc", "")
+	})
+	ensureCollection(app, "dc_delivery_lines", func(c *core.Collection) {
+		c.Fields.Add(&core.RelationField{Name: "delivery", Required: true, CollectionId: dcDeliveriesCol.Id, CascadeDelete: true, MaxSelect: 1})
+		c.Fields.Add(&core.RelationField{Name: "dc_line_item", Required: true, CollectionId: dcLineItemsCol.Id, CascadeDelete: true, MaxSelect: 1})
+		c.Fields.Add(&core.NumberField{Name: "qty_dispatched"})
+		c.Fields.Add(&core.NumberField{Name: "qty_received"})
+		c.Fields.Add(&core.NumberField{Name: "qty_damaged"})
+		c.Fields.Add(&core.TextField{Name: "remarks"})
+	})
+
+	// ── Returnable DCs and Material Return Notes (see services.CreateMRN) ──
+	// A returnable DC sends goods out on loan until its expected return
+	// date. A material return note brings goods back against the lines of a
+	// DC, returnable or not; the serials it returns become available for
+	// dispatch again.
+	ensureSelectValues(app, "delivery_challans", "dc_type", "returnable")
+	ensureSelectValues(app, "number_sequences", "sequence_type", "rdc", "mrn")
+	ensureField(app, "delivery_challans", &core.TextField{Name: "expected_return_date"})
+	ensureField(app, "serial_numbers", &core.SelectField{Name: "status", Values: []string{"dispatched", "available"}, MaxSelect: 1})
+	mrnCol := ensureCollection(app, "material_return_notes", func(c *core.Collection) {
+		c.Fields.Add(&core.RelationField{Name: "project", Required: true, CollectionId: projects.Id, CascadeDelete: true, MaxSelect: 1})
+		c.Fields.Add(&core.RelationField{Name: "dc", Required: true, CollectionId: dcCol.Id, CascadeDelete: true, MaxSelect: 1})
+		c.Fields.Add(&core.TextField{Name: "mrn_number", Required: true})
+		c.Fields.Add(&core.TextField{Name: "return_date", Required: true})
+		c.Fields.Add(&core.SelectField{Name: "reason", Required: true, Values: []string{"returnable", "faulty", "excess"}, MaxSelect: 1})
+		c.Fields.Add(&core.TextField{Name: "returned_by"})
+		c.Fields.Add(&core.TextField{Name: "remarks"})
+		c.Fields.Add(&core.AutodateField{Name: "created", OnCreate: true})
+		c.Fields.Add(&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true})
+	})
+	ensureCollection(app, "material_return_lines", func(c *core.Collection) {
+		c.Fields.Add(&core.RelationField{Name: "mrn", Required: true, CollectionId: mrnCol.Id, CascadeDelete: true, MaxSelect: 1})
+		c.Fields.Add(&core.RelationField{Name: "dc_line_item", Required: true, CollectionId: dcLineItemsCol.Id, CascadeDelete: true, MaxSelect: 1})
+		c.Fields.Add(&core.NumberField{Name: "quantity"})
+		c.Fields.Add(&core.SelectField{Name: "condition", Required: true, Values: []string{"good", "faulty"}, MaxSelect: 1})
+		c.Fields.Add(&core.RelationField{Name: "serial_numbers", CollectionId: serialNumbersCol.Id, MaxSelect: 999})
+		c.Fields.Add(&core.TextField{Name: "remarks"})
+	})
+
+	// ── Serial number lookups ───────────────────────────────────────
+	// Issuing a DC checks its serials against the rest of the project with
+	// one joined query over these. A serial can appear on more than one
+	// record (a cancelled DC's, or one returned and sent out again), so the
+	// (project, serial_number) index is not unique.
+	ensureIndex(app, "serial_numbers", "idx_serial_numbers_project_serial", false, "project, serial_number")
+	ensureIndex(app, "serial_numbers", "idx_serial_numbers_line_item", false, "line_item")
+	ensureIndex(app, "dc_line_items", "idx_dc_line_items_dc", false, "dc")
+	ensureIndex(app, "dc_template_items", "idx_dc_template_items_source", false, "source_item_type, source_item_id")
+}
+
+// ensureSelectValues adds any missing values to an existing select field.
+// ensureField leaves fields that already exist untouched, so new statuses on
+// an existing database have to be added this way.
+func ensureSelectValues(app *pocketbase.PocketBase, collectionName, fieldName string, values ...string) {
+	col, err := app.FindCollectionByNameOrId(collectionName)
+	if err != nil {
+		log.Printf("ensureSelectValues: collection %q not found, skipping.\n", collectionName)
+		return
+	}
+
+	field, ok := col.Fields.GetByName(fieldName).(*core.SelectField)
+	if !ok {
+		log.Printf("ensureSelectValues: %q on %q is not a select field, skipping.\n", fieldName, collectionName)
+		return
+	}
+
+	changed := false
+	for _, v := range values {
+		if !slices.Contains(field.Values, v) {
+			field.Values = append(field.Values, v)
+			changed = true
+		}
+	}
+	if !changed {
+		return
+	}
+
+	if err := app.Save(col); err != nil {
+		log.Printf("ensureSelectValues: failed to update %q on %q: %v\n", fieldName, collectionName, err)
+	} else {
+		log.Printf("ensureSelectValues: added %v to %q on collection %q\n", values, fieldName, collectionName)
+	}
 }
 
 // ensureField adds a field to an existing collection if it doesn't already exist.
@@ -590,6 +1042,27 @@ func ensureField(app *pocketbase.PocketBase, collectionName string, field core.F
 	}
 }
 
+// ensureIndex adds an index to an existing collection if it doesn't already
+// have one of that name.
+func ensureIndex(app *pocketbase.PocketBase, collectionName, indexName string, unique bool, columns string) {
+	col, err := app.FindCollectionByNameOrId(collectionName)
+	if err != nil {
+		log.Printf("ensureIndex: collection %q not found, skipping index add.\n", collectionName)
+		return
+	}
+
+	if col.GetIndex(indexName) != "" {
+		return
+	}
+
+	col.AddIndex(indexName, unique, columns, "")
+	if err := app.Save(col); err != nil {
+		log.Printf("ensureIndex: failed to add index %q to %q: %v\n", indexName, collectionName, err)
+	} else {
+		log.Printf("ensureIndex: added index %q to collection %q\n", indexName, collectionName)
+	}
+}
+
 // ensureCollection checks if a collection already exists by name. If it does,
 // the existing collection is returned. Otherwise a new base collection is
 // created, the addFields callback is invoked to populate its fields, and the
@@ -22,6 +22,26 @@ var expectedCollections = []string{
 	"project_vendors",
 	"purchase_orders",
 	"po_line_items",
+	"grn",
+	"grn_line_items",
+	"vendor_invoices",
+	"vendor_invoice_line_items",
+	"tds_sections",
+	"vendor_payments",
+	"vendor_payment_allocations",
+	"cost_categories",
+	"project_budgets",
+	"budget_alerts",
+	"expenses",
+	"work_orders",
+	"work_order_lines",
+	"work_order_sites",
+	"commissioning_records",
+	"dc_deliveries",
+	"dc_delivery_lines",
+	"material_return_notes",
+	"material_return_lines",
+	"transfer_dc_split_quantities",
 }
 
 func TestSetup_AllCollectionsExist(t *testing.T) {
@@ -410,3 +430,160 @@ func TestSetup_POCascadeDeleteOnProject(t *testing.T) {
 		t.Error("po_line_item should have been cascade-deleted with purchase_order")
 	}
 }
+
+func TestSetup_DCCancellationFields(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+
+	// Simulate a database created before cancellation existed
+	col, _ := app.FindCollectionByNameOrId("delivery_challans")
+	status := col.Fields.GetByName("status").(*core.SelectField)
+	status.Values = []string{"draft", "issued", "splitting", "split"}
+	if err := app.Save(col); err != nil {
+		t.Fatalf("failed to reset status values: %v", err)
+	}
+
+	collections.Setup(app)
+
+	col, _ = app.FindCollectionByNameOrId("delivery_challans")
+	values := col.Fields.GetByName("status").(*core.SelectField).Values
+	found := false
+	for _, v := range values {
+		if v == "cancelled" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected status values to include cancelled, got %v", values)
+	}
+	for _, name := range []string{"cancel_reason", "cancelled_at", "cancelled_by", "cancelled_by_name"} {
+		if col.Fields.GetByName(name) == nil {
+			t.Errorf("expected field %q on delivery_challans", name)
+		}
+	}
+}
+
+func TestSetup_GRNFields(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+
+	col, _ := app.FindCollectionByNameOrId("grn_line_items")
+	for _, f := range []string{"grn", "po_line_item", "qty_ordered", "qty_received", "qty_rejected", "qty_accepted", "rejection_reason", "rate"} {
+		if col.Fields.GetByName(f) == nil {
+			t.Errorf("grn_line_items: missing field %q", f)
+		}
+	}
+	if rf, ok := col.Fields.GetByName("grn").(*core.RelationField); !ok || !rf.CascadeDelete {
+		t.Error("grn_line_items.grn: expected CascadeDelete=true")
+	}
+
+	seqCol, _ := app.FindCollectionByNameOrId("number_sequences")
+	values := seqCol.Fields.GetByName("sequence_type").(*core.SelectField).Values
+	found := false
+	for _, v := range values {
+		if v == "grn" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected sequence_type values to include grn, got %v", values)
+	}
+}
+
+func TestSetup_VendorInvoiceFields(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+
+	col, _ := app.FindCollectionByNameOrId("vendor_invoice_line_items")
+	for _, f := range []string{"vendor_invoice", "po_line_item", "qty", "rate", "gst_percent"} {
+		if col.Fields.GetByName(f) == nil {
+			t.Errorf("vendor_invoice_line_items: missing field %q", f)
+		}
+	}
+	if rf, ok := col.Fields.GetByName("vendor_invoice").(*core.RelationField); !ok || !rf.CascadeDelete {
+		t.Error("vendor_invoice_line_items.vendor_invoice: expected CascadeDelete=true")
+	}
+
+	projects, _ := app.FindCollectionByNameOrId("projects")
+	for _, f := range []string{"invoice_qty_tolerance_pct", "invoice_rate_tolerance_pct"} {
+		if projects.Fields.GetByName(f) == nil {
+			t.Errorf("projects: missing field %q", f)
+		}
+	}
+}
+
+func TestSetup_VendorPaymentFields(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+
+	col, _ := app.FindCollectionByNameOrId("vendor_payments")
+	for _, f := range []string{"vendor", "payment_date", "mode", "utr_reference", "gross_amount", "tds_section", "tds_rate", "tds_amount", "net_amount"} {
+		if col.Fields.GetByName(f) == nil {
+			t.Errorf("vendor_payments: missing field %q", f)
+		}
+	}
+
+	alloc, _ := app.FindCollectionByNameOrId("vendor_payment_allocations")
+	for _, f := range []string{"vendor_payment", "vendor_invoice"} {
+		if rf, ok := alloc.Fields.GetByName(f).(*core.RelationField); !ok || !rf.CascadeDelete {
+			t.Errorf("vendor_payment_allocations.%s: expected CascadeDelete=true", f)
+		}
+	}
+
+	vendors, _ := app.FindCollectionByNameOrId("vendors")
+	if vendors.Fields.GetByName("opening_balance") == nil {
+		t.Error("vendors: missing field \"opening_balance\"")
+	}
+}
+
+func TestSetup_BillingMilestoneAndReceiptFields(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+
+	milestones, _ := app.FindCollectionByNameOrId("billing_milestones")
+	for _, f := range []string{"project", "name", "percentage", "sort_order"} {
+		if milestones.Fields.GetByName(f) == nil {
+			t.Errorf("billing_milestones: missing field %q", f)
+		}
+	}
+
+	invoices, _ := app.FindCollectionByNameOrId("client_invoices")
+	for _, f := range []string{"billing_milestone", "milestone_description", "sac_code", "milestone_tax_percentage", "retention_amount"} {
+		if invoices.Fields.GetByName(f) == nil {
+			t.Errorf("client_invoices: missing field %q", f)
+		}
+	}
+
+	alloc, _ := app.FindCollectionByNameOrId("client_receipt_allocations")
+	for _, f := range []string{"client_receipt", "client_invoice"} {
+		if rf, ok := alloc.Fields.GetByName(f).(*core.RelationField); !ok || !rf.CascadeDelete {
+			t.Errorf("client_receipt_allocations.%s: expected CascadeDelete=true", f)
+		}
+	}
+
+	projects, _ := app.FindCollectionByNameOrId("projects")
+	if projects.Fields.GetByName("retention_percent") == nil {
+		t.Error("projects: missing field \"retention_percent\"")
+	}
+}
+
+func TestSetup_SerialLookupIndexes(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+
+	// Simulate a database created before the indexes existed
+	col, _ := app.FindCollectionByNameOrId("serial_numbers")
+	col.RemoveIndex("idx_serial_numbers_project_serial")
+	if err := app.Save(col); err != nil {
+		t.Fatalf("failed to drop index: %v", err)
+	}
+
+	collections.Setup(app)
+
+	for collection, indexes := range map[string][]string{
+		"serial_numbers":    {"idx_serial_numbers_project_serial", "idx_serial_numbers_line_item"},
+		"dc_line_items":     {"idx_dc_line_items_dc"},
+		"dc_template_items": {"idx_dc_template_items_source"},
+	} {
+		col, _ := app.FindCollectionByNameOrId(collection)
+		for _, name := range indexes {
+			if col.GetIndex(name) == "" {
+				t.Errorf("%s: missing index %q", collection, name)
+			}
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package collections
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// defaultTDSSections are the sections created the first time TDS sections
+// are read. Rates can be changed afterwards on the TDS settings page.
+var defaultTDSSections = []struct {
+	Section     string
+	Description string
+	Rate        float64
+}{
+	{"194C", "Contractors — individual / HUF", 1},
+	{"194C", "Contractors — others", 2},
+	{"194H", "Commission or brokerage", 2},
+	{"194I(a)", "Rent — plant and machinery", 2},
+	{"194J(a)", "Fees for technical services", 2},
+	{"194J(b)", "Fees for professional services", 10},
+	{"194Q", "Purchase of goods", 0.1},
+}
+
+// GetTDSSections returns all TDS sections ordered by section. If none
+// exist, the default sections are created first.
+func GetTDSSections(app *pocketbase.PocketBase) ([]*core.Record, error) {
+	col, err := app.FindCollectionByNameOrId("tds_sections")
+	if err != nil {
+		return nil, fmt.Errorf("tds_sections collection not found: %w", err)
+	}
+
+	records, err := app.FindRecordsByFilter(col, "", "section,created", 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("could not query tds_sections: %w", err)
+	}
+	if len(records) > 0 {
+		return records, nil
+	}
+
+	for _, d := range defaultTDSSections {
+		record := core.NewRecord(col)
+		record.Set("section", d.Section)
+		record.Set("description", d.Description)
+		record.Set("rate_percent", d.Rate)
+		record.Set("active", true)
+		if err := app.Save(record); err != nil {
+			return nil, fmt.Errorf("could not create default TDS section %s: %w", d.Section, err)
+		}
+		records = append(records, record)
+	}
+	log.Println("tds_sections: created default TDS sections")
+	return records, nil
+}
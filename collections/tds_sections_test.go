@@ -0,0 +1,33 @@
+package collections_test
+
+import (
+	"testing"
+
+	"projectcreation/collections"
+	"projectcreation/testhelpers"
+)
+
+func TestGetTDSSections_CreatesDefaultsOnce(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+
+	first, err := collections.GetTDSSections(app)
+	if err != nil {
+		t.Fatalf("GetTDSSections failed: %v", err)
+	}
+	if len(first) == 0 {
+		t.Fatal("expected default TDS sections")
+	}
+	for _, r := range first {
+		if r.GetString("section") == "" || !r.GetBool("active") {
+			t.Errorf("unexpected default section %v", r.FieldsData())
+		}
+	}
+
+	second, err := collections.GetTDSSections(app)
+	if err != nil {
+		t.Fatalf("second GetTDSSections failed: %v", err)
+	}
+	if len(second) != len(first) {
+		t.Errorf("expected %d sections on the second call, got %d", len(first), len(second))
+	}
+}
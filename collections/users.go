@@ -0,0 +1,59 @@
+package collections
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+const defaultAdminEmail = "admin@example.com"
+
+// generatedAdminPasswordLength is the length of the password made up for the
+// initial admin when ADMIN_PASSWORD is not set.
+const generatedAdminPasswordLength = 20
+
+// EnsureAdminUser creates an initial admin account when the users collection
+// is empty so a fresh install can be signed into. The credentials come from
+// the ADMIN_EMAIL / ADMIN_PASSWORD environment variables. Without a password
+// a random one is generated and logged once, to be changed right after the
+// first login.
+func EnsureAdminUser(app *pocketbase.PocketBase) error {
+	col, err := app.FindCollectionByNameOrId("users")
+	if err != nil {
+		return fmt.Errorf("users collection not found: %w", err)
+	}
+
+	total, err := app.CountRecords(col)
+	if err != nil {
+		return fmt.Errorf("could not count users: %w", err)
+	}
+	if total > 0 {
+		return nil
+	}
+
+	email := os.Getenv("ADMIN_EMAIL")
+	if email == "" {
+		email = defaultAdminEmail
+	}
+	password := os.Getenv("ADMIN_PASSWORD")
+	if password == "" {
+		password = security.RandomString(generatedAdminPasswordLength)
+		log.Printf("users: ADMIN_PASSWORD not set, generated password for %s: %s – it is not shown again, change it after signing in", email, password)
+	}
+
+	record := core.NewRecord(col)
+	record.SetEmail(email)
+	record.SetPassword(password)
+	record.SetVerified(true)
+	record.Set("name", "Administrator")
+	record.Set("role", "admin")
+	if err := app.Save(record); err != nil {
+		return fmt.Errorf("could not create admin user: %w", err)
+	}
+	log.Printf("users: created initial admin user %s", email)
+	return nil
+}
@@ -0,0 +1,48 @@
+package collections_test
+
+import (
+	"testing"
+
+	"projectcreation/collections"
+	"projectcreation/testhelpers"
+)
+
+func TestSetup_UsersHaveRoleAndProjects(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	col, err := app.FindCollectionByNameOrId("users")
+	if err != nil {
+		t.Fatalf("users collection not found: %v", err)
+	}
+	for _, name := range []string{"role", "projects"} {
+		if col.Fields.GetByName(name) == nil {
+			t.Errorf("expected users.%s field", name)
+		}
+	}
+}
+
+func TestEnsureAdminUser_CreatesOnce(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	t.Setenv("ADMIN_PASSWORD", "")
+
+	if err := collections.EnsureAdminUser(app); err != nil {
+		t.Fatalf("EnsureAdminUser failed: %v", err)
+	}
+	if err := collections.EnsureAdminUser(app); err != nil {
+		t.Fatalf("second EnsureAdminUser failed: %v", err)
+	}
+
+	users, err := app.FindAllRecords("users")
+	if err != nil {
+		t.Fatalf("failed to query users: %v", err)
+	}
+	if len(users) != 1 {
+		t.Fatalf("expected exactly 1 user, got %d", len(users))
+	}
+	if users[0].GetString("role") != "admin" {
+		t.Errorf("expected admin role, got %q", users[0].GetString("role"))
+	}
+	// Without ADMIN_PASSWORD there is no well-known password to sign in with
+	if users[0].ValidatePassword("changeme123") {
+		t.Error("expected a generated password, not a fixed default")
+	}
+}
@@ -183,7 +183,7 @@ func HandleAddressSave(app *pocketbase.PocketBase, addressType AddressType) func
 			}
 		}
 
-		if err := app.Save(record); err != nil {
+		if err := app.SaveWithContext(e.Request.Context(), record); err != nil {
 			log.Printf("address_save: could not save address: %v", err)
 			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
 		}
@@ -191,7 +191,7 @@ func HandleAddressSave(app *pocketbase.PocketBase, addressType AddressType) func
 		// Set address_code with record ID fallback if company name was empty
 		if fields["company_name"] == "" {
 			record.Set("address_code", generateAddressCode("", record.Id))
-			_ = app.Save(record)
+			_ = app.SaveWithContext(e.Request.Context(), record)
 		}
 
 		// Redirect to address list
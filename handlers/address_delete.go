@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
@@ -44,14 +45,14 @@ func HandleAddressDelete(app *pocketbase.PocketBase) func(*core.RequestEvent) er
 
 		// If deleting a Ship To address, nullify linked Install At addresses
 		if addrType == AddressTypeShipTo {
-			if err := nullifyLinkedInstallAtAddresses(app, addressID); err != nil {
+			if err := nullifyLinkedInstallAtAddresses(e.Request.Context(), app, addressID); err != nil {
 				log.Printf("address_delete: failed to nullify linked install_at: %v", err)
 				return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
 			}
 		}
 
 		// Delete the address
-		if err := app.Delete(record); err != nil {
+		if err := app.DeleteWithContext(e.Request.Context(), record); err != nil {
 			log.Printf("address_delete: failed to delete %s: %v", addressID, err)
 			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
 		}
@@ -152,12 +153,12 @@ func HandleAddressBulkDelete(app *pocketbase.PocketBase) func(*core.RequestEvent
 
 			// Nullify linked Install At addresses if deleting Ship To
 			if addrType == AddressTypeShipTo {
-				if err := nullifyLinkedInstallAtAddresses(app, id); err != nil {
+				if err := nullifyLinkedInstallAtAddresses(e.Request.Context(), app, id); err != nil {
 					log.Printf("bulk_delete: nullify linked for %s: %v", id, err)
 				}
 			}
 
-			if err := app.Delete(record); err != nil {
+			if err := app.DeleteWithContext(e.Request.Context(), record); err != nil {
 				deleteErrors = append(deleteErrors, fmt.Sprintf("%s: delete failed", id))
 				log.Printf("bulk_delete: failed %s: %v", id, err)
 			}
@@ -185,7 +186,7 @@ func HandleAddressBulkDelete(app *pocketbase.PocketBase) func(*core.RequestEvent
 
 // nullifyLinkedInstallAtAddresses finds all Install At addresses that reference
 // the given Ship To address ID and sets their ship_to_parent to empty.
-func nullifyLinkedInstallAtAddresses(app *pocketbase.PocketBase, shipToID string) error {
+func nullifyLinkedInstallAtAddresses(ctx context.Context, app *pocketbase.PocketBase, shipToID string) error {
 	addressesCol, err := app.FindCollectionByNameOrId("addresses")
 	if err != nil {
 		return fmt.Errorf("addresses collection not found: %w", err)
@@ -204,7 +205,7 @@ func nullifyLinkedInstallAtAddresses(app *pocketbase.PocketBase, shipToID string
 
 	for _, rec := range linked {
 		rec.Set("ship_to_parent", "")
-		if err := app.Save(rec); err != nil {
+		if err := app.SaveWithContext(ctx, rec); err != nil {
 			return fmt.Errorf("nullify ship_to_parent on %s: %w", rec.Id, err)
 		}
 	}
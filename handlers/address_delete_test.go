@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -220,7 +221,7 @@ func TestNullifyLinkedInstallAtAddresses(t *testing.T) {
 	installAt.Set("ship_to_parent", shipTo.Id)
 	app.Save(installAt)
 
-	if err := nullifyLinkedInstallAtAddresses(app, shipTo.Id); err != nil {
+	if err := nullifyLinkedInstallAtAddresses(context.Background(), app, shipTo.Id); err != nil {
 		t.Fatalf("nullify failed: %v", err)
 	}
 
@@ -221,7 +221,7 @@ func HandleAddressUpdate(app *pocketbase.PocketBase, addressType AddressType) fu
 			}
 		}
 
-		if err := app.Save(addressRecord); err != nil {
+		if err := app.SaveWithContext(e.Request.Context(), addressRecord); err != nil {
 			log.Printf("address_update: could not save address %s: %v", addressID, err)
 			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
 		}
@@ -175,7 +175,7 @@ func HandleAddressImportCommit(app *pocketbase.PocketBase) func(*core.RequestEve
 		}
 
 		// Commit the import
-		importResult, err := services.CommitAddressImport(app, projectID, dbType, parsedRows)
+		importResult, err := services.CommitAddressImport(e.Request.Context(), app, projectID, dbType, parsedRows)
 		if err != nil {
 			log.Printf("address_import_commit: %v", err)
 			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
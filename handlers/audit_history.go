@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/services"
+	"projectcreation/templates"
+)
+
+// auditHistoryLimit caps the number of entries shown in a history panel.
+const auditHistoryLimit = 50
+
+// auditHistoryCollections are the documents that expose a history panel.
+var auditHistoryCollections = map[string]bool{
+	"boqs":              true,
+	"purchase_orders":   true,
+	"delivery_challans": true,
+	"grn":               true,
+	"vendor_invoices":   true,
+	"client_invoices":   true,
+	"work_orders":       true,
+}
+
+// auditCollectionLabels gives a readable name for each audited collection.
+var auditCollectionLabels = map[string]string{
+	"projects":                   "Project",
+	"boqs":                       "BOQ",
+	"main_boq_items":             "Main item",
+	"sub_items":                  "Sub item",
+	"sub_sub_items":              "Sub-sub item",
+	"purchase_orders":            "Purchase order",
+	"po_line_items":              "PO line item",
+	"delivery_challans":          "Delivery challan",
+	"dc_line_items":              "DC line item",
+	"serial_numbers":             "Serial number",
+	"addresses":                  "Address",
+	"vendors":                    "Vendor",
+	"grn":                        "GRN",
+	"grn_line_items":             "GRN line item",
+	"vendor_invoices":            "Vendor invoice",
+	"vendor_invoice_line_items":  "Vendor invoice line item",
+	"vendor_payments":            "Vendor payment",
+	"vendor_payment_allocations": "Payment allocation",
+	"tds_sections":               "TDS section",
+	"client_invoices":            "Client invoice",
+	"client_invoice_line_items":  "Client invoice line item",
+	"billing_milestones":         "Billing milestone",
+	"client_receipts":            "Client receipt",
+	"client_receipt_allocations": "Receipt allocation",
+	"cost_categories":            "Cost category",
+	"project_budgets":            "Project budget",
+	"expenses":                   "Site expense",
+	"work_orders":                "Work order",
+	"work_order_lines":           "Work order line",
+	"work_order_sites":           "Work order site",
+	"commissioning_records":      "Commissioning record",
+	"dc_deliveries":              "Proof of delivery",
+	"dc_delivery_lines":          "Delivery line",
+	"material_return_notes":      "Material return note",
+	"material_return_lines":      "Return line",
+}
+
+// formatAuditValue renders a stored audit value for display.
+func formatAuditValue(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return "—"
+	case string:
+		if val == "" {
+			return "—"
+		}
+		return val
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+// HandleAuditHistory renders the change history panel for a BOQ, PO or DC
+// (GET /projects/{projectId}/history/{collection}/{id}).
+func HandleAuditHistory(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		projectId := e.Request.PathValue("projectId")
+		collection := e.Request.PathValue("collection")
+		id := e.Request.PathValue("id")
+
+		if !auditHistoryCollections[collection] {
+			return e.String(http.StatusNotFound, "History not available")
+		}
+
+		record, err := app.FindRecordById(collection, id)
+		if err != nil || record.GetString("project") != projectId {
+			return e.String(http.StatusNotFound, "Record not found")
+		}
+
+		records, err := app.FindRecordsByFilter(
+			"audit_log",
+			"document_collection = {:col} && document_id = {:id}",
+			"-created",
+			auditHistoryLimit,
+			0,
+			dbx.Params{"col": collection, "id": id},
+		)
+		if err != nil {
+			log.Printf("audit_history: could not query history for %s/%s: %v", collection, id, err)
+			return ErrorToast(e, http.StatusInternalServerError, "Failed to load history")
+		}
+
+		entries := make([]templates.AuditHistoryEntry, 0, len(records))
+		for _, rec := range records {
+			var changes map[string]services.AuditChange
+			if err := rec.UnmarshalJSONField("changes", &changes); err != nil {
+				log.Printf("audit_history: could not parse changes of %s: %v", rec.Id, err)
+			}
+
+			fields := make([]string, 0, len(changes))
+			for field := range changes {
+				fields = append(fields, field)
+			}
+			sort.Strings(fields)
+
+			entry := templates.AuditHistoryEntry{
+				Action:     rec.GetString("action"),
+				ActorName:  rec.GetString("actor_name"),
+				Collection: auditCollectionLabels[rec.GetString("collection_name")],
+				Created:    rec.GetDateTime("created").Time().Local().Format("02 Jan 2006 15:04"),
+			}
+			for _, field := range fields {
+				entry.Changes = append(entry.Changes, templates.AuditFieldChange{
+					Field:  strings.ReplaceAll(field, "_", " "),
+					Before: formatAuditValue(changes[field].Before),
+					After:  formatAuditValue(changes[field].After),
+				})
+			}
+			entries = append(entries, entry)
+		}
+
+		return templates.AuditHistoryPanel(entries).Render(e.Request.Context(), e.Response)
+	}
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"projectcreation/services"
+	"projectcreation/testhelpers"
+)
+
+func TestHandleAuditHistory_ShowsChanges(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	services.RegisterAuditHooks(app)
+
+	project := testhelpers.CreateTestProject(t, app, "History Project")
+	vendor := testhelpers.CreateTestVendor(t, app, "History Vendor")
+	po := testhelpers.CreateTestPurchaseOrder(t, app, project.Id, vendor.Id, "PO-HIST-001")
+
+	ctx := services.WithAuditActor(context.Background(), "", "Ravi Kumar")
+	po.Set("payment_terms", "Net 45")
+	if err := app.SaveWithContext(ctx, po); err != nil {
+		t.Fatalf("failed to update PO: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/projects/"+project.Id+"/history/purchase_orders/"+po.Id, nil)
+	req.SetPathValue("projectId", project.Id)
+	req.SetPathValue("collection", "purchase_orders")
+	req.SetPathValue("id", po.Id)
+	rec := httptest.NewRecorder()
+	e := newTestRequestEvent(app, req, rec)
+
+	if err := HandleAuditHistory(app)(e); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	testhelpers.AssertHTMLContains(t, rec.Body.String(), "HISTORY", "UPDATED", "Ravi Kumar", "payment terms", "Net 45")
+}
+
+func TestHandleAuditHistory_RejectsOtherCollections(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "History Project")
+
+	req := httptest.NewRequest(http.MethodGet, "/projects/"+project.Id+"/history/users/abc", nil)
+	req.SetPathValue("projectId", project.Id)
+	req.SetPathValue("collection", "users")
+	req.SetPathValue("id", "abc")
+	rec := httptest.NewRecorder()
+	e := newTestRequestEvent(app, req, rec)
+
+	if err := HandleAuditHistory(app)(e); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", rec.Code)
+	}
+}
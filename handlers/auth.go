@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/services"
+)
+
+// User roles stored in the users.role select field.
+const (
+	RoleAdmin          = "admin"
+	RoleProjectManager = "project_manager"
+	RolePurchase       = "purchase"
+	RoleLogistics      = "logistics"
+	RoleViewer         = "viewer"
+)
+
+const CurrentUserKey contextKey = "currentUser"
+
+// authCookieName holds the PocketBase auth token for the signed-in user.
+const authCookieName = "pb_auth"
+
+// GetCurrentUser returns the signed-in user record stored by AuthMiddleware,
+// or nil when the request has no session.
+func GetCurrentUser(r *http.Request) *core.Record {
+	if val, ok := r.Context().Value(CurrentUserKey).(*core.Record); ok {
+		return val
+	}
+	return nil
+}
+
+// HasRole reports whether the user has one of the given roles.
+func HasRole(user *core.Record, roles ...string) bool {
+	if user == nil {
+		return false
+	}
+	return slices.Contains(roles, user.GetString("role"))
+}
+
+// CanAccessProject reports whether the user may see the given project.
+// Admins see every project; other roles only see projects they are a member of.
+// A nil user is not restricted — AuthMiddleware is what keeps anonymous
+// requests out, and handlers invoked without it (e.g. in tests) keep working.
+func CanAccessProject(user *core.Record, projectID string) bool {
+	if user == nil || HasRole(user, RoleAdmin) {
+		return true
+	}
+	return slices.Contains(user.GetStringSlice("projects"), projectID)
+}
+
+// isPublicPath reports whether a path is reachable without signing in.
+// PocketBase's own /api and /_ routes handle their own authentication, and
+// /verify pages check a signed token instead.
+func isPublicPath(path string) bool {
+	if path == "/login" {
+		return true
+	}
+	for _, prefix := range []string{"/static/", "/api/", "/_/", "/verify/"} {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// requestProjectID extracts the project a request is scoped to, if any.
+// Project-scoped routes use {projectId}; the project CRUD routes use {id}.
+func requestProjectID(r *http.Request) string {
+	if id := r.PathValue("projectId"); id != "" {
+		return id
+	}
+	if strings.HasPrefix(r.URL.Path, "/projects/") {
+		return r.PathValue("id")
+	}
+	return ""
+}
+
+// findUserFromCookie resolves the auth cookie to a users record.
+func findUserFromCookie(app *pocketbase.PocketBase, r *http.Request) *core.Record {
+	cookie, err := r.Cookie(authCookieName)
+	if err != nil || cookie.Value == "" {
+		return nil
+	}
+	user, err := app.FindAuthRecordByToken(cookie.Value, core.TokenTypeAuth)
+	if err != nil || user.Collection().Name != "users" {
+		return nil
+	}
+	return user
+}
+
+// clearAuthCookie expires the auth cookie.
+func clearAuthCookie(e *core.RequestEvent) {
+	http.SetCookie(e.Response, &http.Cookie{
+		Name:   authCookieName,
+		Value:  "",
+		Path:   "/",
+		MaxAge: -1,
+	})
+}
+
+// redirectToLogin sends the browser to the login page, remembering the
+// requested page so the user lands back on it after signing in.
+func redirectToLogin(e *core.RequestEvent) error {
+	loginURL := "/login"
+	if e.Request.Method == http.MethodGet && e.Request.URL.Path != "/" {
+		loginURL += "?next=" + url.QueryEscape(e.Request.URL.RequestURI())
+	}
+
+	if e.Request.Header.Get("HX-Request") == "true" {
+		e.Response.Header().Set("HX-Redirect", loginURL)
+		return e.String(http.StatusOK, "")
+	}
+	return e.Redirect(http.StatusFound, loginURL)
+}
+
+// AuthMiddleware loads the signed-in user from the auth cookie and stores it
+// in the request context. Requests without a valid session are redirected to
+// the login page, and requests for a project the user is not a member of are
+// rejected. It must be bound before ActiveProjectMiddleware.
+func AuthMiddleware(app *pocketbase.PocketBase) func(e *core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		if isPublicPath(e.Request.URL.Path) {
+			return e.Next()
+		}
+
+		user := findUserFromCookie(app, e.Request)
+		if user == nil {
+			if _, err := e.Request.Cookie(authCookieName); err == nil {
+				clearAuthCookie(e)
+			}
+			return redirectToLogin(e)
+		}
+
+		e.Auth = user
+		ctx := context.WithValue(e.Request.Context(), CurrentUserKey, user)
+		actorName := user.GetString("name")
+		if actorName == "" {
+			actorName = user.Email()
+		}
+		ctx = services.WithAuditActor(ctx, user.Id, actorName)
+		e.Request = e.Request.WithContext(ctx)
+
+		if projectID := requestProjectID(e.Request); projectID != "" && !CanAccessProject(user, projectID) {
+			return ErrorToast(e, http.StatusForbidden, "You do not have access to this project")
+		}
+
+		return e.Next()
+	}
+}
+
+// RequireRole returns a route middleware that only lets users with one of the
+// given roles through.
+func RequireRole(roles ...string) func(e *core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		user := GetCurrentUser(e.Request)
+		if user == nil {
+			return ErrorToast(e, http.StatusUnauthorized, "Please sign in to continue")
+		}
+		if !HasRole(user, roles...) {
+			return ErrorToast(e, http.StatusForbidden, "You do not have permission to do that")
+		}
+		return e.Next()
+	}
+}
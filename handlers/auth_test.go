@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"projectcreation/testhelpers"
+)
+
+func TestAuthMiddleware_RedirectsWithoutSession(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/projects/abc/boq", nil)
+	rec := httptest.NewRecorder()
+	e := newTestRequestEvent(app, req, rec)
+
+	if err := AuthMiddleware(app)(e); err != nil {
+		t.Fatalf("middleware returned error: %v", err)
+	}
+
+	if rec.Code != http.StatusFound {
+		t.Errorf("expected status 302, got %d", rec.Code)
+	}
+	expected := "/login?next=" + url.QueryEscape("/projects/abc/boq")
+	if loc := rec.Header().Get("Location"); loc != expected {
+		t.Errorf("expected redirect to %q, got %q", expected, loc)
+	}
+}
+
+func TestAuthMiddleware_HTMXRedirect(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/projects/abc/boq", nil)
+	req.Header.Set("HX-Request", "true")
+	rec := httptest.NewRecorder()
+	e := newTestRequestEvent(app, req, rec)
+
+	if err := AuthMiddleware(app)(e); err != nil {
+		t.Fatalf("middleware returned error: %v", err)
+	}
+
+	testhelpers.AssertHXRedirect(t, rec.Header().Get("HX-Redirect"), "/login")
+}
+
+func TestAuthMiddleware_PublicPaths(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+
+	for _, path := range []string{"/login", "/static/css/output.css", "/api/health", "/verify/dc.abc.def"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		rec := httptest.NewRecorder()
+		e := newTestRequestEvent(app, req, rec)
+
+		if err := AuthMiddleware(app)(e); err != nil {
+			t.Fatalf("%s: middleware returned error: %v", path, err)
+		}
+		if rec.Code == http.StatusFound {
+			t.Errorf("%s: expected no redirect for public path", path)
+		}
+	}
+}
+
+func TestAuthMiddleware_ValidSession(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	user := testhelpers.CreateTestUser(t, app, "pm@example.com", RoleProjectManager)
+	token, err := user.NewAuthToken()
+	if err != nil {
+		t.Fatalf("failed to create token: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/projects", nil)
+	req.AddCookie(&http.Cookie{Name: authCookieName, Value: token})
+	rec := httptest.NewRecorder()
+	e := newTestRequestEvent(app, req, rec)
+
+	if err := AuthMiddleware(app)(e); err != nil {
+		t.Fatalf("middleware returned error: %v", err)
+	}
+
+	if rec.Code == http.StatusFound {
+		t.Fatal("expected signed-in request not to be redirected")
+	}
+	current := GetCurrentUser(e.Request)
+	if current == nil || current.Id != user.Id {
+		t.Fatal("expected current user in context")
+	}
+	if e.Auth == nil || e.Auth.Id != user.Id {
+		t.Error("expected e.Auth to be set")
+	}
+}
+
+func TestAuthMiddleware_RejectsNonMemberProject(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	member := testhelpers.CreateTestProject(t, app, "Member Project")
+	other := testhelpers.CreateTestProject(t, app, "Other Project")
+	user := testhelpers.CreateTestUser(t, app, "viewer@example.com", RoleViewer, member.Id)
+	token, _ := user.NewAuthToken()
+
+	req := httptest.NewRequest(http.MethodGet, "/projects/"+other.Id+"/boq", nil)
+	req.SetPathValue("projectId", other.Id)
+	req.AddCookie(&http.Cookie{Name: authCookieName, Value: token})
+	rec := httptest.NewRecorder()
+	e := newTestRequestEvent(app, req, rec)
+
+	if err := AuthMiddleware(app)(e); err != nil {
+		t.Fatalf("middleware returned error: %v", err)
+	}
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected status 403, got %d", rec.Code)
+	}
+}
+
+func TestCanAccessProject(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Access Project")
+	admin := testhelpers.CreateTestUser(t, app, "admin@example.com", RoleAdmin)
+	member := testhelpers.CreateTestUser(t, app, "member@example.com", RolePurchase, project.Id)
+	outsider := testhelpers.CreateTestUser(t, app, "outsider@example.com", RolePurchase)
+
+	if !CanAccessProject(admin, project.Id) {
+		t.Error("expected admin to access every project")
+	}
+	if !CanAccessProject(member, project.Id) {
+		t.Error("expected member to access their project")
+	}
+	if CanAccessProject(outsider, project.Id) {
+		t.Error("expected non-member to be denied")
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	viewer := testhelpers.CreateTestUser(t, app, "viewer@example.com", RoleViewer)
+	purchase := testhelpers.CreateTestUser(t, app, "purchase@example.com", RolePurchase)
+	guard := RequireRole(RoleAdmin, RolePurchase)
+
+	tests := []struct {
+		name     string
+		userID   string
+		wantCode int
+	}{
+		{"no user", "", http.StatusUnauthorized},
+		{"viewer denied", viewer.Id, http.StatusForbidden},
+		{"purchase allowed", purchase.Id, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/projects/p1/po", nil)
+			if tt.userID != "" {
+				user, _ := app.FindRecordById("users", tt.userID)
+				req = req.WithContext(context.WithValue(req.Context(), CurrentUserKey, user))
+			}
+			rec := httptest.NewRecorder()
+			e := newTestRequestEvent(app, req, rec)
+
+			if err := guard(e); err != nil {
+				t.Fatalf("guard returned error: %v", err)
+			}
+			if rec.Code != tt.wantCode {
+				t.Errorf("expected status %d, got %d", tt.wantCode, rec.Code)
+			}
+		})
+	}
+}
+
+func TestHandleLogin_Success(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	testhelpers.CreateTestUser(t, app, "login@example.com", RoleLogistics)
+
+	form := url.Values{
+		"email":    {"login@example.com"},
+		"password": {"password123"},
+		"next":     {"/projects/p1/dcs/"},
+	}
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	e := newTestRequestEvent(app, req, rec)
+
+	if err := HandleLogin(app)(e); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+
+	if rec.Code != http.StatusFound {
+		t.Fatalf("expected status 302, got %d", rec.Code)
+	}
+	if loc := rec.Header().Get("Location"); loc != "/projects/p1/dcs/" {
+		t.Errorf("expected redirect to next URL, got %q", loc)
+	}
+
+	var token string
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == authCookieName {
+			token = c.Value
+		}
+	}
+	if token == "" {
+		t.Fatal("expected auth cookie to be set")
+	}
+	if _, err := app.FindAuthRecordByToken(token); err != nil {
+		t.Errorf("expected cookie to hold a valid auth token: %v", err)
+	}
+}
+
+func TestHandleLogin_InvalidPassword(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	testhelpers.CreateTestUser(t, app, "wrong@example.com", RoleViewer)
+
+	form := url.Values{"email": {"wrong@example.com"}, "password": {"nope"}}
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	e := newTestRequestEvent(app, req, rec)
+
+	if err := HandleLogin(app)(e); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+
+	testhelpers.AssertHTMLContains(t, rec.Body.String(), "Invalid email or password")
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == authCookieName && c.Value != "" {
+			t.Error("expected no auth cookie on failed login")
+		}
+	}
+}
+
+func TestSafeNextURL(t *testing.T) {
+	tests := map[string]string{
+		"":                   "/projects",
+		"/projects/p1/po":    "/projects/p1/po",
+		"//evil.example.com": "/projects",
+		"https://evil.com":   "/projects",
+		"/login":             "/projects",
+	}
+	for input, want := range tests {
+		if got := safeNextURL(input); got != want {
+			t.Errorf("safeNextURL(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
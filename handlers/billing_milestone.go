@@ -0,0 +1,336 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/services"
+	"projectcreation/templates"
+)
+
+// newBillingMilestonesData builds the milestones page of a project.
+func newBillingMilestonesData(project *core.Record, milestones []services.BillingMilestone, contractValue float64) templates.BillingMilestonesData {
+	data := templates.BillingMilestonesData{
+		ProjectID:        project.Id,
+		ContractValue:    services.FormatINR(contractValue),
+		RetentionPercent: formatQty(project.GetFloat("retention_percent")),
+		Errors:           make(map[string]string),
+	}
+	var total float64
+	for _, m := range milestones {
+		total += m.Percentage
+		data.Milestones = append(data.Milestones, templates.BillingMilestoneRow{
+			ID:            m.ID,
+			Name:          m.Name,
+			Percentage:    formatQty(m.Percentage),
+			Amount:        services.FormatINR(m.Amount),
+			InvoiceID:     m.InvoiceID,
+			InvoiceNumber: m.InvoiceNumber,
+		})
+	}
+	data.TotalPercentage = formatQty(total)
+	return data
+}
+
+// renderBillingMilestones renders the milestones page as a partial or full page.
+func renderBillingMilestones(e *core.RequestEvent, data templates.BillingMilestonesData) error {
+	var component templ.Component
+	if e.Request.Header.Get("HX-Request") == "true" {
+		component = templates.BillingMilestonesContent(data)
+	} else {
+		headerData := GetHeaderData(e.Request)
+		sidebarData := GetSidebarData(e.Request)
+		component = templates.BillingMilestonesPage(data, headerData, sidebarData)
+	}
+	return component.Render(e.Request.Context(), e.Response)
+}
+
+// redirectToBillingMilestones sends the browser back to the milestones page.
+func redirectToBillingMilestones(e *core.RequestEvent, projectId string) error {
+	redirectURL := fmt.Sprintf("/projects/%s/billing-milestones", projectId)
+	if e.Request.Header.Get("HX-Request") == "true" {
+		e.Response.Header().Set("HX-Redirect", redirectURL)
+		return e.String(http.StatusOK, "")
+	}
+	return e.Redirect(http.StatusFound, redirectURL)
+}
+
+// HandleBillingMilestones renders the project's billing milestones with the
+// contract value they are billed against.
+func HandleBillingMilestones(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		project, err := app.FindRecordById("projects", e.Request.PathValue("projectId"))
+		if err != nil {
+			return ErrorToast(e, http.StatusNotFound, "Project not found")
+		}
+
+		milestones, contractValue, err := services.ProjectBillingMilestones(app, project.Id)
+		if err != nil {
+			log.Printf("billing_milestone: could not load milestones for project %s: %v", project.Id, err)
+			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
+		}
+		return renderBillingMilestones(e, newBillingMilestonesData(project, milestones, contractValue))
+	}
+}
+
+// HandleBillingMilestoneAdd adds a milestone after the project's existing ones.
+func HandleBillingMilestoneAdd(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		if err := e.Request.ParseForm(); err != nil {
+			return ErrorToast(e, http.StatusBadRequest, "Invalid form data")
+		}
+
+		project, err := app.FindRecordById("projects", e.Request.PathValue("projectId"))
+		if err != nil {
+			return ErrorToast(e, http.StatusNotFound, "Project not found")
+		}
+
+		milestones, contractValue, err := services.ProjectBillingMilestones(app, project.Id)
+		if err != nil {
+			log.Printf("billing_milestone: could not load milestones for project %s: %v", project.Id, err)
+			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
+		}
+
+		name := strings.TrimSpace(e.Request.FormValue("name"))
+		rawPercentage := strings.TrimSpace(e.Request.FormValue("percentage"))
+		percentage, _ := strconv.ParseFloat(rawPercentage, 64)
+
+		if errors := services.ValidateBillingMilestone(milestones, name, percentage); len(errors) > 0 {
+			SetToast(e, "warning", "Please fix the errors below")
+			data := newBillingMilestonesData(project, milestones, contractValue)
+			data.NewName = name
+			data.NewPercentage = rawPercentage
+			data.Errors = errors
+			return renderBillingMilestones(e, data)
+		}
+
+		col, err := app.FindCollectionByNameOrId("billing_milestones")
+		if err != nil {
+			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
+		}
+		record := core.NewRecord(col)
+		record.Set("project", project.Id)
+		record.Set("name", name)
+		record.Set("percentage", percentage)
+		record.Set("sort_order", len(milestones)+1)
+		if err := app.SaveWithContext(e.Request.Context(), record); err != nil {
+			log.Printf("billing_milestone: could not save milestone: %v", err)
+			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
+		}
+
+		SetToast(e, "success", fmt.Sprintf("Milestone %s added", name))
+		return redirectToBillingMilestones(e, project.Id)
+	}
+}
+
+// HandleBillingMilestoneDelete removes a milestone that has not been invoiced.
+func HandleBillingMilestoneDelete(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		projectId := e.Request.PathValue("projectId")
+		milestoneID := e.Request.PathValue("id")
+
+		milestone, err := app.FindRecordById("billing_milestones", milestoneID)
+		if err != nil || milestone.GetString("project") != projectId {
+			return ErrorToast(e, http.StatusNotFound, "Milestone not found")
+		}
+
+		invoiced, _ := app.FindRecordsByFilter("client_invoices", "billing_milestone = {:id}", "", 1, 0, map[string]any{"id": milestoneID})
+		if len(invoiced) > 0 {
+			return ErrorToast(e, http.StatusConflict, fmt.Sprintf("Milestone has been invoiced on %s and cannot be deleted", invoiced[0].GetString("invoice_number")))
+		}
+
+		if err := app.DeleteWithContext(e.Request.Context(), milestone); err != nil {
+			log.Printf("billing_milestone: could not delete milestone %s: %v", milestoneID, err)
+			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
+		}
+
+		SetToast(e, "success", "Milestone deleted")
+		return redirectToBillingMilestones(e, projectId)
+	}
+}
+
+// HandleRetentionUpdate saves the retention percentage the client holds back
+// on the project's invoices. Invoices already raised keep the retention
+// worked out when they were raised.
+func HandleRetentionUpdate(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		if err := e.Request.ParseForm(); err != nil {
+			return ErrorToast(e, http.StatusBadRequest, "Invalid form data")
+		}
+
+		project, err := app.FindRecordById("projects", e.Request.PathValue("projectId"))
+		if err != nil {
+			return ErrorToast(e, http.StatusNotFound, "Project not found")
+		}
+
+		raw := strings.TrimSpace(e.Request.FormValue("retention_percent"))
+		percent, err := strconv.ParseFloat(raw, 64)
+		if raw == "" {
+			percent, err = 0, nil
+		}
+		if err != nil || percent < 0 || percent > 100 {
+			milestones, contractValue, loadErr := services.ProjectBillingMilestones(app, project.Id)
+			if loadErr != nil {
+				log.Printf("billing_milestone: could not load milestones for project %s: %v", project.Id, loadErr)
+				return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
+			}
+			SetToast(e, "warning", "Please fix the errors below")
+			data := newBillingMilestonesData(project, milestones, contractValue)
+			data.RetentionPercent = raw
+			data.Errors["retention_percent"] = "Retention must be between 0 and 100%"
+			return renderBillingMilestones(e, data)
+		}
+
+		project.Set("retention_percent", percent)
+		if err := app.SaveWithContext(e.Request.Context(), project); err != nil {
+			log.Printf("billing_milestone: could not save retention for project %s: %v", project.Id, err)
+			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
+		}
+
+		SetToast(e, "success", "Retention saved")
+		return redirectToBillingMilestones(e, project.Id)
+	}
+}
+
+// newMilestoneInvoiceCreateData builds the invoice form for a milestone.
+func newMilestoneInvoiceCreateData(app *pocketbase.PocketBase, projectId string, milestone services.BillingMilestone) templates.MilestoneInvoiceCreateData {
+	return templates.MilestoneInvoiceCreateData{
+		ProjectID:     projectId,
+		MilestoneID:   milestone.ID,
+		MilestoneName: milestone.Name,
+		Percentage:    formatQty(milestone.Percentage),
+		Amount:        services.FormatINR(milestone.Amount),
+		BillFrom:      fetchDefaultAddressOptions(app, projectId, "bill_from"),
+		BillTo:        fetchDefaultAddressOptions(app, projectId, "bill_to"),
+		GSTOptions:    services.GSTOptions,
+		Errors:        make(map[string]string),
+	}
+}
+
+// findProjectMilestone returns one of the project's milestones, with its
+// amount and invoice.
+func findProjectMilestone(app *pocketbase.PocketBase, projectId, milestoneID string) (services.BillingMilestone, bool, error) {
+	milestones, _, err := services.ProjectBillingMilestones(app, projectId)
+	if err != nil {
+		return services.BillingMilestone{}, false, err
+	}
+	idx := slices.IndexFunc(milestones, func(m services.BillingMilestone) bool { return m.ID == milestoneID })
+	if idx < 0 {
+		return services.BillingMilestone{}, false, nil
+	}
+	return milestones[idx], true, nil
+}
+
+// renderMilestoneInvoiceCreate renders the milestone invoice form as a
+// partial or full page.
+func renderMilestoneInvoiceCreate(e *core.RequestEvent, data templates.MilestoneInvoiceCreateData) error {
+	var component templ.Component
+	if e.Request.Header.Get("HX-Request") == "true" {
+		component = templates.MilestoneInvoiceCreateContent(data)
+	} else {
+		headerData := GetHeaderData(e.Request)
+		sidebarData := GetSidebarData(e.Request)
+		component = templates.MilestoneInvoiceCreatePage(data, headerData, sidebarData)
+	}
+	return component.Render(e.Request.Context(), e.Response)
+}
+
+// HandleMilestoneInvoiceCreate renders the invoice form for a milestone,
+// with the project's default bill-from address preselected.
+func HandleMilestoneInvoiceCreate(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		projectId := e.Request.PathValue("projectId")
+		project, err := app.FindRecordById("projects", projectId)
+		if err != nil {
+			return ErrorToast(e, http.StatusNotFound, "Project not found")
+		}
+
+		milestone, ok, err := findProjectMilestone(app, projectId, e.Request.PathValue("id"))
+		if err != nil {
+			log.Printf("billing_milestone: could not load milestones for project %s: %v", projectId, err)
+			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
+		}
+		if !ok {
+			return ErrorToast(e, http.StatusNotFound, "Milestone not found")
+		}
+		if milestone.Invoiced() {
+			return ErrorToast(e, http.StatusConflict, fmt.Sprintf("%s has already been invoiced on %s", milestone.Name, milestone.InvoiceNumber))
+		}
+
+		data := newMilestoneInvoiceCreateData(app, projectId, milestone)
+		data.InvoiceDate = time.Now().Format("2006-01-02")
+		data.BillFromID = project.GetString("default_bill_from")
+		data.TaxPercent = "18"
+		return renderMilestoneInvoiceCreate(e, data)
+	}
+}
+
+// HandleMilestoneInvoiceSave raises the invoice for a milestone and
+// redirects to it.
+func HandleMilestoneInvoiceSave(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		if err := e.Request.ParseForm(); err != nil {
+			return ErrorToast(e, http.StatusBadRequest, "Invalid form data")
+		}
+
+		projectId := e.Request.PathValue("projectId")
+		milestone, ok, err := findProjectMilestone(app, projectId, e.Request.PathValue("id"))
+		if err != nil {
+			log.Printf("billing_milestone: could not load milestones for project %s: %v", projectId, err)
+			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
+		}
+		if !ok {
+			return ErrorToast(e, http.StatusNotFound, "Milestone not found")
+		}
+
+		rawTax := e.Request.FormValue("tax_percentage")
+		taxPercent, _ := strconv.ParseFloat(rawTax, 64)
+		params := services.MilestoneInvoiceParams{
+			ProjectID:   projectId,
+			MilestoneID: milestone.ID,
+			InvoiceDate: strings.TrimSpace(e.Request.FormValue("invoice_date")),
+			BillFromID:  e.Request.FormValue("bill_from"),
+			BillToID:    e.Request.FormValue("bill_to"),
+			SACCode:     strings.TrimSpace(e.Request.FormValue("sac_code")),
+			TaxPercent:  taxPercent,
+			Remarks:     strings.TrimSpace(e.Request.FormValue("remarks")),
+		}
+
+		if errors := services.ValidateMilestoneInvoice(app, params); len(errors) > 0 {
+			SetToast(e, "warning", "Please fix the errors below")
+			data := newMilestoneInvoiceCreateData(app, projectId, milestone)
+			data.InvoiceDate = params.InvoiceDate
+			data.BillFromID = params.BillFromID
+			data.BillToID = params.BillToID
+			data.SACCode = params.SACCode
+			data.TaxPercent = rawTax
+			data.Remarks = params.Remarks
+			data.Errors = errors
+			return renderMilestoneInvoiceCreate(e, data)
+		}
+
+		result, err := services.CreateMilestoneInvoice(e.Request.Context(), app, params)
+		if err != nil {
+			log.Printf("billing_milestone: could not invoice milestone %s: %v", milestone.ID, err)
+			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
+		}
+
+		SetToast(e, "success", fmt.Sprintf("Invoice %s raised for %s", result.InvoiceNumber, milestone.Name))
+
+		redirectURL := fmt.Sprintf("/projects/%s/client-invoices/%s", projectId, result.InvoiceID)
+		if e.Request.Header.Get("HX-Request") == "true" {
+			e.Response.Header().Set("HX-Redirect", redirectURL)
+			return e.String(http.StatusOK, "")
+		}
+		return e.Redirect(http.StatusFound, redirectURL)
+	}
+}
@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/services"
+	"projectcreation/testhelpers"
+)
+
+// createMilestoneProject creates a project with a contract value of
+// ₹1,00,000, bill-from and bill-to addresses and an "Advance" milestone of
+// 10%.
+func createMilestoneProject(t *testing.T, app *pocketbase.PocketBase) (project, billFrom, billTo, advance *core.Record) {
+	t.Helper()
+	project = testhelpers.CreateTestProject(t, app, "Milestone Project")
+	boq := testhelpers.CreateTestBOQ(t, app, project.Id, "Milestone BOQ")
+	mainItem := testhelpers.CreateTestMainBOQItem(t, app, boq.Id, "Solar plant")
+	mainItem.Set("quoted_price", 10000)
+	if err := app.Save(mainItem); err != nil {
+		t.Fatalf("failed to price main item: %v", err)
+	}
+	billFrom = testhelpers.CreateTestAddress(t, app, project.Id, "bill_from", "Our Company")
+	billTo = testhelpers.CreateTestAddress(t, app, project.Id, "bill_to", "Client Ltd")
+
+	col, _ := app.FindCollectionByNameOrId("billing_milestones")
+	advance = core.NewRecord(col)
+	advance.Set("project", project.Id)
+	advance.Set("name", "Advance")
+	advance.Set("percentage", 10)
+	advance.Set("sort_order", 1)
+	if err := app.Save(advance); err != nil {
+		t.Fatalf("failed to save milestone: %v", err)
+	}
+	return project, billFrom, billTo, advance
+}
+
+func postHXForm(t *testing.T, app *pocketbase.PocketBase, handler func(*pocketbase.PocketBase) func(*core.RequestEvent) error, target string, pathValues map[string]string, form url.Values) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("HX-Request", "true")
+	for k, v := range pathValues {
+		req.SetPathValue(k, v)
+	}
+	rec := httptest.NewRecorder()
+	if err := handler(app)(newTestRequestEvent(app, req, rec)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	return rec
+}
+
+func TestHandleBillingMilestones_ShowsAmounts(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project, _, _, _ := createMilestoneProject(t, app)
+
+	req := httptest.NewRequest(http.MethodGet, "/projects/"+project.Id+"/billing-milestones", nil)
+	req.SetPathValue("projectId", project.Id)
+	rec := httptest.NewRecorder()
+	if err := HandleBillingMilestones(app)(newTestRequestEvent(app, req, rec)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+
+	testhelpers.AssertHTMLContains(t, rec.Body.String(), "Advance", "1,00,000", "10,000", "RAISE INVOICE")
+}
+
+func TestHandleBillingMilestoneAdd(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project, _, _, _ := createMilestoneProject(t, app)
+	path := map[string]string{"projectId": project.Id}
+	target := "/projects/" + project.Id + "/billing-milestones"
+
+	rec := postHXForm(t, app, HandleBillingMilestoneAdd, target, path, url.Values{"name": {"On supply"}, "percentage": {"95"}})
+	testhelpers.AssertHTMLContains(t, rec.Body.String(), "only 90% is left")
+
+	rec = postHXForm(t, app, HandleBillingMilestoneAdd, target, path, url.Values{"name": {"On supply"}, "percentage": {"60"}})
+	testhelpers.AssertHXRedirect(t, rec.Header().Get("HX-Redirect"), target)
+	records, _ := app.FindRecordsByFilter("billing_milestones", "project = {:pid}", "", 0, 0, map[string]any{"pid": project.Id})
+	if len(records) != 2 {
+		t.Errorf("expected 2 milestones, got %d", len(records))
+	}
+}
+
+func TestHandleBillingMilestones_AuditActor(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	services.RegisterAuditHooks(app)
+	project, _, _, advance := createMilestoneProject(t, app)
+	path := map[string]string{"projectId": project.Id}
+
+	post := func(handler func(*pocketbase.PocketBase) func(*core.RequestEvent) error, pathValues map[string]string, form url.Values) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("HX-Request", "true")
+		req = req.WithContext(services.WithAuditActor(req.Context(), "", "Meena"))
+		for k, v := range pathValues {
+			req.SetPathValue(k, v)
+		}
+		rec := httptest.NewRecorder()
+		if err := handler(app)(newTestRequestEvent(app, req, rec)); err != nil {
+			t.Fatalf("handler returned error: %v", err)
+		}
+		if rec.Header().Get("HX-Redirect") == "" {
+			t.Fatalf("expected a redirect, got %d: %s", rec.Code, rec.Body.String())
+		}
+	}
+	post(HandleBillingMilestoneAdd, path, url.Values{"name": {"On supply"}, "percentage": {"60"}})
+	post(HandleBillingMilestoneDelete, map[string]string{"projectId": project.Id, "id": advance.Id}, nil)
+	post(HandleRetentionUpdate, path, url.Values{"retention_percent": {"5"}})
+
+	for _, action := range []string{"create", "delete", "update"} {
+		entries, _ := app.FindRecordsByFilter("audit_log", "action = {:action} && collection_name != 'audit_log'", "-created", 1, 0, map[string]any{"action": action})
+		if len(entries) == 0 || entries[0].GetString("actor_name") != "Meena" {
+			t.Errorf("expected the %s to be audited with its actor", action)
+		}
+	}
+}
+
+func TestHandleMilestoneInvoiceSave_RedirectsToInvoice(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project, billFrom, billTo, advance := createMilestoneProject(t, app)
+	path := map[string]string{"projectId": project.Id, "id": advance.Id}
+	target := "/projects/" + project.Id + "/billing-milestones/" + advance.Id + "/invoice"
+
+	rec := postHXForm(t, app, HandleMilestoneInvoiceSave, target, path, url.Values{
+		"invoice_date": {"2026-04-02"}, "bill_from": {billFrom.Id}, "bill_to": {billTo.Id},
+		"sac_code": {"9954"}, "tax_percentage": {"18"},
+	})
+	invoices, _ := app.FindRecordsByFilter("client_invoices", "billing_milestone = {:id}", "", 0, 0, map[string]any{"id": advance.Id})
+	if len(invoices) != 1 {
+		t.Fatalf("expected one milestone invoice, got %d", len(invoices))
+	}
+	testhelpers.AssertHXRedirect(t, rec.Header().Get("HX-Redirect"), "/projects/"+project.Id+"/client-invoices/"+invoices[0].Id)
+
+	// A second invoice for the same milestone is refused with the form
+	rec = postHXForm(t, app, HandleMilestoneInvoiceSave, target, path, url.Values{
+		"invoice_date": {"2026-04-03"}, "bill_from": {billFrom.Id}, "bill_to": {billTo.Id}, "tax_percentage": {"18"},
+	})
+	testhelpers.AssertHTMLContains(t, rec.Body.String(), "already been invoiced")
+}
+
+func TestHandleClientReceiptSave_UpdatesAging(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project, billFrom, billTo, advance := createMilestoneProject(t, app)
+	postHXForm(t, app, HandleMilestoneInvoiceSave, "/", map[string]string{"projectId": project.Id, "id": advance.Id}, url.Values{
+		"invoice_date": {"2026-04-02"}, "bill_from": {billFrom.Id}, "bill_to": {billTo.Id}, "tax_percentage": {"18"},
+	})
+	invoices, _ := app.FindRecordsByFilter("client_invoices", "project = {:pid}", "", 0, 0, map[string]any{"pid": project.Id})
+	if len(invoices) != 1 {
+		t.Fatalf("expected one invoice, got %d", len(invoices))
+	}
+
+	rec := postHXForm(t, app, HandleClientReceiptSave, "/projects/"+project.Id+"/receipts", map[string]string{"projectId": project.Id}, url.Values{
+		"receipt_date": {"2026-04-20"}, "mode": {"neft"}, "reference": {"UTR42"},
+		"alloc_" + invoices[0].Id: {"5000"},
+	})
+	testhelpers.AssertHXRedirect(t, rec.Header().Get("HX-Redirect"), "/projects/"+project.Id+"/receivables")
+
+	req := httptest.NewRequest(http.MethodGet, "/receivables?as_of=2026-05-10", nil)
+	req.Header.Set("HX-Request", "true")
+	rec = httptest.NewRecorder()
+	if err := HandleReceivablesAging(app)(newTestRequestEvent(app, req, rec)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	// 11,800 invoiced less 5,000 received, 38 days old
+	testhelpers.AssertHTMLContains(t, rec.Body.String(), "31-60 DAYS", "Milestone Project", "6,800")
+
+	// A member of another project sees neither the project nor its amounts
+	other := testhelpers.CreateTestProject(t, app, "Other Project")
+	member := testhelpers.CreateTestUser(t, app, "member@example.com", "logistics", other.Id)
+	req = httptest.NewRequest(http.MethodGet, "/receivables?as_of=2026-05-10", nil)
+	req.Header.Set("HX-Request", "true")
+	req = req.WithContext(context.WithValue(req.Context(), CurrentUserKey, member))
+	rec = httptest.NewRecorder()
+	if err := HandleReceivablesAging(app)(newTestRequestEvent(app, req, rec)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	if body := rec.Body.String(); strings.Contains(body, "Milestone Project") || strings.Contains(body, "6,800") {
+		t.Error("expected another project's receivables to be hidden")
+	}
+}
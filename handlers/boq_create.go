@@ -96,7 +96,7 @@ func HandleBOQSave(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
 		boqRecord.Set("reference_number", refNumber)
 		boqRecord.Set("project", projectID)
 
-		if err := app.Save(boqRecord); err != nil {
+		if err := app.SaveWithContext(e.Request.Context(), boqRecord); err != nil {
 			log.Printf("boq_create: could not save BOQ: %v", err)
 			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
 		}
@@ -155,7 +155,7 @@ func HandleBOQSave(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
 			// budgeted_price set after sub-items are processed
 			itemRecord.Set("budgeted_price", 0)
 
-			if err := app.Save(itemRecord); err != nil {
+			if err := app.SaveWithContext(e.Request.Context(), itemRecord); err != nil {
 				log.Printf("boq_create: could not save main item %d: %v", i+1, err)
 				continue
 			}
@@ -250,7 +250,7 @@ func HandleBOQSave(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
 					subRecord.Set("unit_price", subUnitPrice)
 					subRecord.Set("budgeted_price", subUnitPrice)
 
-					if err := app.Save(subRecord); err != nil {
+					if err := app.SaveWithContext(e.Request.Context(), subRecord); err != nil {
 						log.Printf("boq_create: could not save sub item %d.%d: %v", i+1, si+1, err)
 						continue
 					}
@@ -270,7 +270,7 @@ func HandleBOQSave(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
 						ssRecord.Set("budgeted_price", ss.ssBudgeted)
 						ssRecord.Set("gst_percent", ss.ssGst)
 
-						if err := app.Save(ssRecord); err != nil {
+						if err := app.SaveWithContext(e.Request.Context(), ssRecord); err != nil {
 							log.Printf("boq_create: could not save sub-sub item %d.%d.%d: %v", i+1, si+1, ssi+1, err)
 						}
 					}
@@ -288,7 +288,7 @@ func HandleBOQSave(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
 				perUnitBudgeted = manualBudgeted
 			}
 			itemRecord.Set("budgeted_price", perUnitBudgeted*qty)
-			if err := app.Save(itemRecord); err != nil {
+			if err := app.SaveWithContext(e.Request.Context(), itemRecord); err != nil {
 				log.Printf("boq_create: could not update main item %d budgeted: %v", i+1, err)
 			}
 		}
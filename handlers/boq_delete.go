@@ -26,7 +26,7 @@ func HandleBOQDelete(app *pocketbase.PocketBase) func(*core.RequestEvent) error
 		}
 
 		// Delete the BOQ — cascade delete handles main_boq_items, sub_items, sub_sub_items
-		if err := app.Delete(boqRecord); err != nil {
+		if err := app.DeleteWithContext(e.Request.Context(), boqRecord); err != nil {
 			log.Printf("boq_delete: failed to delete BOQ %s: %v", boqID, err)
 			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
 		}
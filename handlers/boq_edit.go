@@ -333,7 +333,7 @@ func HandleBOQUpdate(app *pocketbase.PocketBase) func(*core.RequestEvent) error
 					ssi.Set("budgeted_price", ssBudgeted)
 					ssTotal += ssBudgeted
 
-					if err := app.Save(ssi); err != nil {
+					if err := app.SaveWithContext(e.Request.Context(), ssi); err != nil {
 						log.Printf("boq_save: error saving sub-sub item %s: %v", ssi.Id, err)
 					}
 				}
@@ -347,7 +347,7 @@ func HandleBOQUpdate(app *pocketbase.PocketBase) func(*core.RequestEvent) error
 				}
 				subBudgetTotal += si.GetFloat("budgeted_price")
 
-				if err := app.Save(si); err != nil {
+				if err := app.SaveWithContext(e.Request.Context(), si); err != nil {
 					log.Printf("boq_save: error saving sub item %s: %v", si.Id, err)
 				}
 			}
@@ -357,7 +357,7 @@ func HandleBOQUpdate(app *pocketbase.PocketBase) func(*core.RequestEvent) error
 				mi.Set("budgeted_price", subBudgetTotal*mi.GetFloat("qty"))
 			}
 
-			if err := app.Save(mi); err != nil {
+			if err := app.SaveWithContext(e.Request.Context(), mi); err != nil {
 				log.Printf("boq_save: error saving main item %s: %v", mi.Id, err)
 			}
 		}
@@ -516,7 +516,7 @@ func HandleAddMainItem(app *pocketbase.PocketBase) func(*core.RequestEvent) erro
 		record.Set("hsn_code", "")
 		record.Set("gst_percent", 18)
 
-		if err := app.Save(record); err != nil {
+		if err := app.SaveWithContext(e.Request.Context(), record); err != nil {
 			log.Printf("add_main_item: error creating record: %v", err)
 			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
 		}
@@ -560,7 +560,7 @@ func HandleAddSubItem(app *pocketbase.PocketBase) func(*core.RequestEvent) error
 		record.Set("hsn_code", "")
 		record.Set("gst_percent", 18)
 
-		if err := app.Save(record); err != nil {
+		if err := app.SaveWithContext(e.Request.Context(), record); err != nil {
 			log.Printf("add_sub_item: error creating record: %v", err)
 			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
 		}
@@ -604,7 +604,7 @@ func HandleAddSubSubItem(app *pocketbase.PocketBase) func(*core.RequestEvent) er
 		record.Set("hsn_code", "")
 		record.Set("gst_percent", 18)
 
-		if err := app.Save(record); err != nil {
+		if err := app.SaveWithContext(e.Request.Context(), record); err != nil {
 			log.Printf("add_sub_sub_item: error creating record: %v", err)
 			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
 		}
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/services"
+	"projectcreation/templates"
+)
+
+// fetchInvoiceableDCGroups returns the project's issued official DCs that
+// still have lines to invoice, grouped by shipment group. DCs of the
+// shipment group sgID, if any, are preselected, as are those in selected.
+func fetchInvoiceableDCGroups(app *pocketbase.PocketBase, projectId, sgID string, selected []string) []templates.ClientInvoiceDCGroup {
+	records, err := app.FindRecordsByFilter(
+		"delivery_challans",
+		"project = {:projectId} && dc_type = 'official' && (status = 'issued' || status = 'delivered' || status = 'partially_delivered')",
+		"dc_number",
+		0,
+		0,
+		map[string]any{"projectId": projectId},
+	)
+	if err != nil {
+		log.Printf("client_invoice_create: could not query delivery_challans: %v", err)
+		return nil
+	}
+
+	var groups []templates.ClientInvoiceDCGroup
+	groupIndex := make(map[string]int)
+	for _, dc := range records {
+		lines, err := services.InvoiceableDCLines(app, dc)
+		if err != nil {
+			log.Printf("client_invoice_create: could not load lines for DC %s: %v", dc.Id, err)
+			continue
+		}
+		if len(lines) == 0 {
+			continue
+		}
+
+		var value float64
+		for _, l := range lines {
+			value += services.CalcPOLineItem(l.Rate, l.Quantity, l.TaxPercent).Total
+		}
+		groupID := dc.GetString("shipment_group")
+		option := templates.ClientInvoiceDCOption{
+			ID:          dc.Id,
+			DCNumber:    dc.GetString("dc_number"),
+			ChallanDate: dc.GetString("challan_date"),
+			BillTo:      addressDisplayName(app, dc.GetString("bill_to_address")),
+			ShipTo:      addressDisplayName(app, dc.GetString("ship_to_address")),
+			LineCount:   len(lines),
+			Value:       services.FormatINR(value),
+			Selected:    (sgID != "" && groupID == sgID) || slices.Contains(selected, dc.Id),
+		}
+
+		idx, ok := groupIndex[groupID]
+		if !ok {
+			idx = len(groups)
+			groupIndex[groupID] = idx
+			groups = append(groups, templates.ClientInvoiceDCGroup{Label: shipmentGroupLabel(app, groupID)})
+		}
+		groups[idx].DCs = append(groups[idx].DCs, option)
+	}
+	return groups
+}
+
+// shipmentGroupLabel names a shipment group after its transit DC.
+func shipmentGroupLabel(app *pocketbase.PocketBase, sgID string) string {
+	if sgID == "" {
+		return "Not in a shipment group"
+	}
+	transits, err := app.FindRecordsByFilter(
+		"delivery_challans",
+		"shipment_group = {:sgId} && dc_type = 'transit'",
+		"",
+		1,
+		0,
+		map[string]any{"sgId": sgID},
+	)
+	if err != nil || len(transits) == 0 {
+		return "Shipment group"
+	}
+	return "Shipment via " + transits[0].GetString("dc_number")
+}
+
+// renderClientInvoiceCreate renders the invoice form as a partial or full page.
+func renderClientInvoiceCreate(e *core.RequestEvent, data templates.ClientInvoiceCreateData) error {
+	var component templ.Component
+	if e.Request.Header.Get("HX-Request") == "true" {
+		component = templates.ClientInvoiceCreateContent(data)
+	} else {
+		headerData := GetHeaderData(e.Request)
+		sidebarData := GetSidebarData(e.Request)
+		component = templates.ClientInvoiceCreatePage(data, headerData, sidebarData)
+	}
+	return component.Render(e.Request.Context(), e.Response)
+}
+
+// HandleClientInvoiceCreate renders the invoice form listing the DCs that can
+// be billed. ?shipment_group= preselects the official DCs of that group.
+func HandleClientInvoiceCreate(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		projectId := e.Request.PathValue("projectId")
+
+		if _, err := app.FindRecordById("projects", projectId); err != nil {
+			return ErrorToast(e, http.StatusNotFound, "Project not found")
+		}
+
+		sgID := e.Request.URL.Query().Get("shipment_group")
+		return renderClientInvoiceCreate(e, templates.ClientInvoiceCreateData{
+			ProjectID:   projectId,
+			Groups:      fetchInvoiceableDCGroups(app, projectId, sgID, nil),
+			InvoiceDate: time.Now().Format("2006-01-02"),
+			Errors:      make(map[string]string),
+		})
+	}
+}
+
+// HandleClientInvoiceSave raises a client invoice for the selected DCs and
+// redirects to it.
+func HandleClientInvoiceSave(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		if err := e.Request.ParseForm(); err != nil {
+			return ErrorToast(e, http.StatusBadRequest, "Invalid form data")
+		}
+
+		projectId := e.Request.PathValue("projectId")
+		params := services.ClientInvoiceParams{
+			ProjectID:   projectId,
+			InvoiceDate: strings.TrimSpace(e.Request.FormValue("invoice_date")),
+			DCIDs:       e.Request.Form["dc_ids"],
+			Remarks:     strings.TrimSpace(e.Request.FormValue("remarks")),
+		}
+
+		errors := make(map[string]string)
+		if params.InvoiceDate == "" {
+			errors["invoice_date"] = "Invoice date is required"
+		}
+		if _, err := services.PrepareClientInvoice(app, projectId, params.DCIDs); err != nil {
+			errors["dc_ids"] = err.Error()
+		}
+		if len(errors) > 0 {
+			SetToast(e, "warning", "Please fix the errors below")
+			return renderClientInvoiceCreate(e, templates.ClientInvoiceCreateData{
+				ProjectID:   projectId,
+				Groups:      fetchInvoiceableDCGroups(app, projectId, "", params.DCIDs),
+				InvoiceDate: params.InvoiceDate,
+				Remarks:     params.Remarks,
+				Errors:      errors,
+			})
+		}
+
+		result, err := services.CreateClientInvoice(e.Request.Context(), app, params)
+		if err != nil {
+			log.Printf("client_invoice_create: could not create invoice for project %s: %v", projectId, err)
+			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
+		}
+
+		SetToast(e, "success", fmt.Sprintf("Invoice %s raised for %s", result.InvoiceNumber, services.FormatINR(result.GrandTotal)))
+
+		redirectURL := fmt.Sprintf("/projects/%s/client-invoices/%s", projectId, result.InvoiceID)
+		if e.Request.Header.Get("HX-Request") == "true" {
+			e.Response.Header().Set("HX-Redirect", redirectURL)
+			return e.String(http.StatusOK, "")
+		}
+		return e.Redirect(http.StatusFound, redirectURL)
+	}
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/a-h/templ"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/services"
+	"projectcreation/templates"
+)
+
+// addressDisplayName returns the company name of an address, falling back to
+// the contact person, for use in lists.
+func addressDisplayName(app *pocketbase.PocketBase, addressID string) string {
+	if addressID == "" {
+		return ""
+	}
+	rec, err := app.FindRecordById("addresses", addressID)
+	if err != nil {
+		return ""
+	}
+	data := readAddressData(rec)
+	if data["company_name"] != "" {
+		return data["company_name"]
+	}
+	return data["contact_person"]
+}
+
+// HandleClientInvoiceList renders the client invoices of a project, newest
+// first.
+func HandleClientInvoiceList(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		projectId := e.Request.PathValue("projectId")
+
+		invoiceRecords, err := app.FindRecordsByFilter(
+			"client_invoices",
+			"project = {:projectId}",
+			"-invoice_date,-created",
+			0,
+			0,
+			map[string]any{"projectId": projectId},
+		)
+		if err != nil {
+			log.Printf("client_invoice_list: could not query client_invoices: %v", err)
+			invoiceRecords = nil
+		}
+
+		var items []templates.ClientInvoiceListItem
+		var billed float64
+		for _, inv := range invoiceRecords {
+			item := templates.ClientInvoiceListItem{
+				ID:            inv.Id,
+				InvoiceNumber: inv.GetString("invoice_number"),
+				InvoiceDate:   inv.GetString("invoice_date"),
+				BuyerName:     addressDisplayName(app, inv.GetString("bill_to_address")),
+				Taxable:       services.FormatINR(inv.GetFloat("taxable_amount")),
+				Total:         services.FormatINR(inv.GetFloat("total_amount")),
+			}
+			billed += inv.GetFloat("total_amount")
+
+			lines, err := app.FindRecordsByFilter("client_invoice_line_items", "client_invoice = {:invId}", "line_order", 0, 0, map[string]any{"invId": inv.Id})
+			if err != nil {
+				log.Printf("client_invoice_list: could not query line items for invoice %s: %v", inv.Id, err)
+				lines = nil
+			}
+			var dcIDs, dcNumbers []string
+			for _, li := range lines {
+				dcID := li.GetString("dc")
+				if slices.Contains(dcIDs, dcID) {
+					continue
+				}
+				dcIDs = append(dcIDs, dcID)
+				if dc, err := app.FindRecordById("delivery_challans", dcID); err == nil {
+					dcNumbers = append(dcNumbers, dc.GetString("dc_number"))
+				}
+			}
+			item.DCNumbers = strings.Join(dcNumbers, ", ")
+
+			items = append(items, item)
+		}
+
+		data := templates.ClientInvoiceListData{
+			Invoices:    items,
+			ProjectID:   projectId,
+			TotalCount:  len(items),
+			TotalBilled: services.FormatINR(billed),
+		}
+
+		var component templ.Component
+		if e.Request.Header.Get("HX-Request") == "true" {
+			component = templates.ClientInvoiceListContent(data)
+		} else {
+			headerData := GetHeaderData(e.Request)
+			sidebarData := GetSidebarData(e.Request)
+			component = templates.ClientInvoiceListPage(data, headerData, sidebarData)
+		}
+		return component.Render(e.Request.Context(), e.Response)
+	}
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/testhelpers"
+)
+
+// createInvoiceableDC creates an issued official DC with one priced line of
+// 5 Nos at ₹1,000 + 18% GST.
+func createInvoiceableDC(t *testing.T, app *pocketbase.PocketBase, projectID, dcNumber string) *core.Record {
+	t.Helper()
+	billFrom := testhelpers.CreateTestAddress(t, app, projectID, "bill_from", "Our Company")
+	billTo := testhelpers.CreateTestAddress(t, app, projectID, "bill_to", "Client Ltd")
+	dc := testhelpers.CreateTestDeliveryChallan(t, app, projectID, dcNumber, "official", "issued")
+	dc.Set("bill_from_address", billFrom.Id)
+	dc.Set("bill_to_address", billTo.Id)
+	if err := app.Save(dc); err != nil {
+		t.Fatalf("failed to update DC: %v", err)
+	}
+
+	boq := testhelpers.CreateTestBOQ(t, app, projectID, "Invoice BOQ")
+	mainItem := testhelpers.CreateTestMainBOQItem(t, app, boq.Id, "Lighting")
+	sub := testhelpers.CreateTestSubItem(t, app, mainItem.Id, "LED Floodlight")
+
+	lineItems, _ := app.FindCollectionByNameOrId("dc_line_items")
+	li := core.NewRecord(lineItems)
+	li.Set("dc", dc.Id)
+	li.Set("source_item_type", "sub_item")
+	li.Set("source_item_id", sub.Id)
+	li.Set("quantity", 5)
+	li.Set("rate", 1000)
+	li.Set("tax_percentage", 18)
+	li.Set("line_order", 1)
+	if err := app.Save(li); err != nil {
+		t.Fatalf("failed to save line item: %v", err)
+	}
+	return dc
+}
+
+func postClientInvoice(t *testing.T, app *pocketbase.PocketBase, projectID string, form url.Values) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/projects/"+projectID+"/client-invoices", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("HX-Request", "true")
+	req.SetPathValue("projectId", projectID)
+	rec := httptest.NewRecorder()
+	if err := HandleClientInvoiceSave(app)(newTestRequestEvent(app, req, rec)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	return rec
+}
+
+func TestHandleClientInvoiceCreate_ListsInvoiceableDCs(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Invoice Project")
+	dc := createInvoiceableDC(t, app, project.Id, "ODC-LIST-001")
+	testhelpers.CreateTestDeliveryChallan(t, app, project.Id, "ODC-DRAFT-001", "official", "draft")
+
+	req := httptest.NewRequest(http.MethodGet, "/projects/"+project.Id+"/client-invoices/create", nil)
+	req.SetPathValue("projectId", project.Id)
+	rec := httptest.NewRecorder()
+	if err := HandleClientInvoiceCreate(app)(newTestRequestEvent(app, req, rec)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rec.Code)
+	}
+	body := rec.Body.String()
+	testhelpers.AssertHTMLContains(t, body, "ODC-LIST-001", "dc_"+dc.Id, "Client Ltd")
+	if strings.Contains(body, "ODC-DRAFT-001") {
+		t.Error("expected draft DCs not to be offered for invoicing")
+	}
+}
+
+func TestHandleClientInvoiceSave_RaisesInvoiceOnce(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Invoice Project")
+	dc := createInvoiceableDC(t, app, project.Id, "ODC-SAVE-001")
+	form := url.Values{"invoice_date": {"2026-03-20"}, "dc_ids": {dc.Id}}
+
+	rec := postClientInvoice(t, app, project.Id, form)
+	invoices, _ := app.FindAllRecords("client_invoices")
+	if len(invoices) != 1 {
+		t.Fatalf("expected 1 invoice, got %d", len(invoices))
+	}
+	testhelpers.AssertHXRedirect(t, rec.Header().Get("HX-Redirect"), "/projects/"+project.Id+"/client-invoices/"+invoices[0].Id)
+	if invoices[0].GetFloat("total_amount") != 5900 {
+		t.Errorf("expected invoice total 5900, got %v", invoices[0].GetFloat("total_amount"))
+	}
+
+	// The same DC cannot be billed again
+	rec = postClientInvoice(t, app, project.Id, form)
+	if rec.Header().Get("HX-Redirect") != "" {
+		t.Error("expected the second invoice to be rejected")
+	}
+	testhelpers.AssertHTMLContains(t, rec.Body.String(), "already been invoiced")
+	if invoices, _ = app.FindAllRecords("client_invoices"); len(invoices) != 1 {
+		t.Errorf("expected still 1 invoice, got %d", len(invoices))
+	}
+}
+
+func TestHandleClientInvoiceSave_RequiresDate(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Invoice Project")
+	dc := createInvoiceableDC(t, app, project.Id, "ODC-DATE-001")
+
+	rec := postClientInvoice(t, app, project.Id, url.Values{"dc_ids": {dc.Id}})
+	testhelpers.AssertHTMLContains(t, rec.Body.String(), "Invoice date is required")
+	if invoices, _ := app.FindAllRecords("client_invoices"); len(invoices) != 0 {
+		t.Errorf("expected no invoice, got %d", len(invoices))
+	}
+}
+
+func TestHandleClientInvoiceViewAndExport(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Invoice Project")
+	dc := createInvoiceableDC(t, app, project.Id, "ODC-VIEW-001")
+	postClientInvoice(t, app, project.Id, url.Values{"invoice_date": {"2026-03-20"}, "dc_ids": {dc.Id}})
+	invoices, _ := app.FindAllRecords("client_invoices")
+	if len(invoices) != 1 {
+		t.Fatalf("expected 1 invoice, got %d", len(invoices))
+	}
+	inv := invoices[0]
+
+	req := httptest.NewRequest(http.MethodGet, "/projects/"+project.Id+"/client-invoices/"+inv.Id, nil)
+	req.SetPathValue("projectId", project.Id)
+	req.SetPathValue("id", inv.Id)
+	rec := httptest.NewRecorder()
+	if err := HandleClientInvoiceView(app)(newTestRequestEvent(app, req, rec)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	testhelpers.AssertHTMLContains(t, rec.Body.String(),
+		"TAX INVOICE",
+		inv.GetString("invoice_number"),
+		"ODC-VIEW-001",
+		"LED Floodlight",
+		"/export/pdf",
+	)
+
+	req = httptest.NewRequest(http.MethodGet, "/projects/"+project.Id+"/client-invoices/"+inv.Id+"/export/pdf", nil)
+	req.SetPathValue("projectId", project.Id)
+	req.SetPathValue("id", inv.Id)
+	rec = httptest.NewRecorder()
+	if err := HandleClientInvoiceExportPDF(app)(newTestRequestEvent(app, req, rec)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/pdf" {
+		t.Errorf("expected application/pdf, got %q", ct)
+	}
+
+	// Invoices are not visible from another project
+	other := testhelpers.CreateTestProject(t, app, "Other Project")
+	req = httptest.NewRequest(http.MethodGet, "/projects/"+other.Id+"/client-invoices/"+inv.Id, nil)
+	req.SetPathValue("projectId", other.Id)
+	req.SetPathValue("id", inv.Id)
+	rec = httptest.NewRecorder()
+	if err := HandleClientInvoiceView(app)(newTestRequestEvent(app, req, rec)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for another project, got %d", rec.Code)
+	}
+}
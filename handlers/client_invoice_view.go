@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/a-h/templ"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/collections"
+	"projectcreation/services"
+	"projectcreation/templates"
+)
+
+// findProjectClientInvoice returns the client invoice if it belongs to the
+// project.
+func findProjectClientInvoice(app *pocketbase.PocketBase, projectId, id string) (*core.Record, error) {
+	inv, err := app.FindRecordById("client_invoices", id)
+	if err != nil {
+		return nil, err
+	}
+	if inv.GetString("project") != projectId {
+		return nil, fmt.Errorf("client invoice %s does not belong to project %s", id, projectId)
+	}
+	return inv, nil
+}
+
+// clientInvoiceParty converts an export address into a party block.
+func clientInvoiceParty(addr *services.DCExportAddress) templates.ClientInvoiceParty {
+	if addr == nil {
+		return templates.ClientInvoiceParty{}
+	}
+	name := addr.CompanyName
+	if name == "" {
+		name = addr.ContactPerson
+	}
+	return templates.ClientInvoiceParty{Name: name, Address: addr.AddressLines, GSTIN: addr.GSTIN}
+}
+
+// HandleClientInvoiceView renders a client tax invoice.
+func HandleClientInvoiceView(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		projectId := e.Request.PathValue("projectId")
+		id := e.Request.PathValue("id")
+
+		inv, err := findProjectClientInvoice(app, projectId, id)
+		if err != nil {
+			log.Printf("client_invoice_view: %v", err)
+			return e.String(http.StatusNotFound, "Invoice not found")
+		}
+
+		// Reuse the export data so the page and the PDF show the same figures
+		export, err := services.BuildClientInvoiceExportData(app, inv.Id)
+		if err != nil {
+			log.Printf("client_invoice_view: could not build invoice data for %s: %v", id, err)
+			return e.String(http.StatusInternalServerError, "Failed to load invoice")
+		}
+
+		var lineItems []templates.ClientInvoiceViewLineItem
+		for _, li := range export.LineItems {
+			lineItems = append(lineItems, templates.ClientInvoiceViewLineItem{
+				SINo:        li.SINo,
+				Description: li.Description,
+				HSNCode:     li.HSNCode,
+				DCNumber:    li.DCNumber,
+				UOM:         li.UOM,
+				Qty:         formatQty(li.Qty),
+				Rate:        services.FormatINR(li.Rate),
+				TaxPercent:  formatQty(li.TaxPercent),
+				Taxable:     services.FormatINR(li.Taxable),
+				Total:       services.FormatINR(li.Total),
+			})
+		}
+
+		data := templates.ClientInvoiceViewData{
+			CompanyName:    export.CompanyName,
+			LogoURL:        collections.GetLogoURL(app),
+			ProjectID:      projectId,
+			InvoiceID:      inv.Id,
+			InvoiceNumber:  export.InvoiceNumber,
+			InvoiceDate:    export.InvoiceDate,
+			PlaceOfSupply:  export.PlaceOfSupply,
+			ReverseCharge:  export.ReverseCharge,
+			DCNumbers:      strings.Join(export.DCNumbers, ", "),
+			Milestone:      export.Milestone,
+			Supplier:       clientInvoiceParty(export.Supplier),
+			Buyer:          clientInvoiceParty(export.Buyer),
+			LineItems:      lineItems,
+			TotalBeforeTax: services.FormatINR(export.Totals.TotalBeforeTax),
+			TaxRows:        poTaxRows(export.Totals),
+			RoundOff:       services.FormatINR(export.Totals.RoundOff),
+			GrandTotal:     services.FormatINR(export.Totals.GrandTotal),
+			AmountInWords:  export.AmountInWords,
+			Remarks:        export.Remarks,
+		}
+		if export.Retention > 0 {
+			data.Retention = services.FormatINR(export.Retention)
+		}
+		if export.ShipTo != nil {
+			shipTo := clientInvoiceParty(export.ShipTo)
+			data.ShipTo = &shipTo
+		}
+
+		var component templ.Component
+		if e.Request.Header.Get("HX-Request") == "true" {
+			component = templates.ClientInvoiceViewContent(data)
+		} else {
+			headerData := GetHeaderData(e.Request)
+			sidebarData := GetSidebarData(e.Request)
+			component = templates.ClientInvoiceViewPage(data, headerData, sidebarData)
+		}
+		return component.Render(e.Request.Context(), e.Response)
+	}
+}
+
+// HandleClientInvoiceExportPDF returns a handler that generates and downloads
+// a PDF for a client invoice.
+func HandleClientInvoiceExportPDF(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		projectId := e.Request.PathValue("projectId")
+		id := e.Request.PathValue("id")
+		if id == "" {
+			return e.String(http.StatusBadRequest, "Missing invoice ID")
+		}
+
+		if _, err := findProjectClientInvoice(app, projectId, id); err != nil {
+			log.Printf("client_invoice_export: %v", err)
+			return e.String(http.StatusNotFound, "Invoice not found")
+		}
+
+		data, err := services.BuildClientInvoiceExportData(app, id)
+		if err != nil {
+			log.Printf("client_invoice_export: failed to build data: %v", err)
+			return e.String(http.StatusInternalServerError, "Failed to build invoice data")
+		}
+
+		pdfBytes, err := services.GenerateClientInvoicePDF(data)
+		if err != nil {
+			log.Printf("client_invoice_export: failed to generate PDF: %v", err)
+			return e.String(http.StatusInternalServerError, "Failed to generate PDF")
+		}
+
+		filename := fmt.Sprintf("%s.pdf", sanitizeFilename(data.InvoiceNumber))
+
+		e.Response.Header().Set("Content-Type", "application/pdf")
+		e.Response.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+		e.Response.Write(pdfBytes)
+		return nil
+	}
+}
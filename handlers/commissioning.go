@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/services"
+	"projectcreation/templates"
+)
+
+// commissioningTestRows is the number of test rows shown on the
+// commissioning form.
+const commissioningTestRows = 5
+
+// commissioningCreateData builds the commissioning form for an install-at
+// site, keeping what was entered when params is not nil. It reports false
+// when the site is not on a work order of the project.
+func commissioningCreateData(app *pocketbase.PocketBase, projectId, siteId string, params *services.CommissioningParams) (templates.CommissioningCreateData, bool) {
+	data := templates.CommissioningCreateData{
+		ProjectID:        projectId,
+		SiteID:           siteId,
+		CommissionedDate: time.Now().Format("2006-01-02"),
+		Errors:           make(map[string]string),
+	}
+
+	site, err := app.FindRecordById("addresses", siteId)
+	if err != nil || site.GetString("project") != projectId {
+		return data, false
+	}
+	data.SiteLabel = services.SiteLabel(site)
+	data.SiteArea = siteArea(site)
+
+	woSite, err := app.FindFirstRecordByData("work_order_sites", "install_at_address", siteId)
+	if err != nil {
+		return data, false
+	}
+	data.WorkOrderID = woSite.GetString("work_order")
+	data.InstallationDate = woSite.GetString("installation_date")
+	if wo, err := app.FindRecordById("work_orders", data.WorkOrderID); err == nil {
+		data.WONumber = wo.GetString("wo_number")
+	}
+
+	if params != nil {
+		data.CommissionedDate = params.CommissionedDate
+		data.SignedOffBy = params.SignedOffBy
+		data.Designation = params.Designation
+		data.Remarks = params.Remarks
+		for _, t := range params.Tests {
+			data.Tests = append(data.Tests, templates.CommissioningTestRow{Name: t.Name, Reading: t.Reading, Passed: t.Passed})
+		}
+	}
+	for len(data.Tests) < commissioningTestRows {
+		data.Tests = append(data.Tests, templates.CommissioningTestRow{Passed: true})
+	}
+	return data, true
+}
+
+// renderCommissioningCreate renders the commissioning form as a partial or
+// full page.
+func renderCommissioningCreate(e *core.RequestEvent, data templates.CommissioningCreateData) error {
+	var component templ.Component
+	if e.Request.Header.Get("HX-Request") == "true" {
+		component = templates.CommissioningCreateContent(data)
+	} else {
+		headerData := GetHeaderData(e.Request)
+		sidebarData := GetSidebarData(e.Request)
+		component = templates.CommissioningCreatePage(data, headerData, sidebarData)
+	}
+	return component.Render(e.Request.Context(), e.Response)
+}
+
+// HandleCommissioningCreate renders the commissioning form for the
+// install-at site in the site query parameter.
+func HandleCommissioningCreate(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		projectId := e.Request.PathValue("projectId")
+		data, ok := commissioningCreateData(app, projectId, e.Request.URL.Query().Get("site"), nil)
+		if !ok {
+			return ErrorToast(e, http.StatusNotFound, "The site is not on a work order of this project")
+		}
+		return renderCommissioningCreate(e, data)
+	}
+}
+
+// HandleCommissioningSave records the test results and sign-off of an
+// install-at site and issues its commissioning certificate.
+func HandleCommissioningSave(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		projectId := e.Request.PathValue("projectId")
+		if err := e.Request.ParseForm(); err != nil {
+			return ErrorToast(e, http.StatusBadRequest, "Invalid form data")
+		}
+
+		params := services.CommissioningParams{
+			ProjectID:        projectId,
+			SiteID:           e.Request.FormValue("site"),
+			CommissionedDate: e.Request.FormValue("commissioned_date"),
+			SignedOffBy:      e.Request.FormValue("signed_off_by"),
+			Designation:      e.Request.FormValue("signed_off_designation"),
+			Remarks:          e.Request.FormValue("remarks"),
+		}
+		for i := 0; ; i++ {
+			key := strconv.Itoa(i)
+			if _, ok := e.Request.Form["test_name_"+key]; !ok {
+				break
+			}
+			name := e.Request.FormValue("test_name_" + key)
+			if name == "" {
+				continue
+			}
+			params.Tests = append(params.Tests, services.CommissioningTest{
+				Name:    name,
+				Reading: e.Request.FormValue("test_reading_" + key),
+				Passed:  e.Request.FormValue("test_result_"+key) != "fail",
+			})
+		}
+
+		if errs := services.ValidateCommissioning(app, params); len(errs) > 0 {
+			data, ok := commissioningCreateData(app, projectId, params.SiteID, &params)
+			if !ok {
+				return ErrorToast(e, http.StatusBadRequest, errs["site"])
+			}
+			data.Errors = errs
+			SetToast(e, "warning", "Please fix the errors below")
+			return renderCommissioningCreate(e, data)
+		}
+
+		rec, err := services.CreateCommissioning(e.Request.Context(), app, params)
+		if err != nil {
+			log.Printf("commissioning: could not commission site %s: %v", params.SiteID, err)
+			return ErrorToast(e, http.StatusInternalServerError, "Could not commission the site. Please try again.")
+		}
+
+		SetToast(e, "success", fmt.Sprintf("Site commissioned, certificate %s", rec.GetString("certificate_number")))
+		redirectURL := fmt.Sprintf("/projects/%s/site-tracker", projectId)
+		if e.Request.Header.Get("HX-Request") == "true" {
+			e.Response.Header().Set("HX-Redirect", redirectURL)
+			return e.String(http.StatusOK, "")
+		}
+		return e.Redirect(http.StatusFound, redirectURL)
+	}
+}
+
+// HandleCommissioningCertificatePDF generates and downloads the
+// commissioning certificate of a site.
+func HandleCommissioningCertificatePDF(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		projectId := e.Request.PathValue("projectId")
+		id := e.Request.PathValue("id")
+
+		rec, err := app.FindRecordById("commissioning_records", id)
+		if err != nil || rec.GetString("project") != projectId {
+			return e.String(http.StatusNotFound, "Commissioning record not found")
+		}
+
+		data, err := services.BuildCommissioningExportData(app, id)
+		if err != nil {
+			log.Printf("commissioning_export: failed to build data: %v", err)
+			return e.String(http.StatusInternalServerError, "Failed to build certificate data")
+		}
+
+		pdfBytes, err := services.GenerateCommissioningCertificatePDF(data)
+		if err != nil {
+			log.Printf("commissioning_export: failed to generate PDF: %v", err)
+			return e.String(http.StatusInternalServerError, "Failed to generate PDF")
+		}
+
+		filename := fmt.Sprintf("%s.pdf", sanitizeFilename(data.CertificateNumber))
+
+		e.Response.Header().Set("Content-Type", "application/pdf")
+		e.Response.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+		e.Response.Write(pdfBytes)
+		return nil
+	}
+}
+
+// HandleSiteTracker renders the dispatched, delivered, installed and
+// commissioned matrix of a project's install-at sites, filtered by the
+// district and mandal query parameters.
+func HandleSiteTracker(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		projectId := e.Request.PathValue("projectId")
+		if _, err := app.FindRecordById("projects", projectId); err != nil {
+			return ErrorToast(e, http.StatusNotFound, "Project not found")
+		}
+
+		district := e.Request.URL.Query().Get("district")
+		mandal := e.Request.URL.Query().Get("mandal")
+		tracker, err := services.BuildSiteTracker(app, projectId, district, mandal)
+		if err != nil {
+			log.Printf("site_tracker: could not build tracker for project %s: %v", projectId, err)
+			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
+		}
+
+		data := templates.SiteTrackerData{
+			ProjectID:    projectId,
+			District:     district,
+			Mandal:       mandal,
+			Districts:    tracker.Districts,
+			Mandals:      tracker.Mandals,
+			Dispatched:   tracker.Dispatched,
+			Delivered:    tracker.Delivered,
+			Installed:    tracker.Installed,
+			Commissioned: tracker.Commissioned,
+		}
+		for _, r := range tracker.Rows {
+			area := r.District
+			if r.Mandal != "" {
+				if area != "" {
+					area += " / "
+				}
+				area += r.Mandal
+			}
+			data.Rows = append(data.Rows, templates.SiteTrackerRow{
+				SiteID:        r.SiteID,
+				Label:         r.Label,
+				Area:          area,
+				Dispatched:    r.Dispatched,
+				Delivered:     r.Delivered,
+				Installed:     r.Installed,
+				Commissioned:  r.Commissioned,
+				WorkOrderID:   r.WorkOrderID,
+				WONumber:      r.WONumber,
+				CertificateID: r.CertificateID,
+			})
+		}
+
+		var component templ.Component
+		if e.Request.Header.Get("HX-Request") == "true" {
+			component = templates.SiteTrackerContent(data)
+		} else {
+			headerData := GetHeaderData(e.Request)
+			sidebarData := GetSidebarData(e.Request)
+			component = templates.SiteTrackerPage(data, headerData, sidebarData)
+		}
+		return component.Render(e.Request.Context(), e.Response)
+	}
+}
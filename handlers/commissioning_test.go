@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"projectcreation/services"
+	"projectcreation/testhelpers"
+)
+
+func TestHandleCommissioningSave_CommissionsSiteOnTracker(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	ctx := context.Background()
+	project := testhelpers.CreateTestProject(t, app, "Install Project")
+	boq := testhelpers.CreateTestBOQ(t, app, project.Id, "Main BOQ")
+	main := testhelpers.CreateTestMainBOQItem(t, app, boq.Id, "Solar pump set")
+	service := testhelpers.CreateTestSubItem(t, app, main.Id, "Installation and commissioning")
+	service.Set("type", "service")
+	if err := app.Save(service); err != nil {
+		t.Fatalf("failed to save service item: %v", err)
+	}
+	installer := testhelpers.CreateTestVendor(t, app, "Surya Installers")
+	site := testhelpers.CreateTestAddress(t, app, project.Id, "install_at", "Farmer A")
+	site.Set("district_name", "Guntur")
+	if err := app.Save(site); err != nil {
+		t.Fatalf("failed to set the site's district: %v", err)
+	}
+	today := time.Now().Format("2006-01-02")
+
+	wo, err := services.CreateWorkOrder(ctx, app, services.WorkOrderParams{
+		ProjectID:   project.Id,
+		InstallerID: installer.Id,
+		WODate:      "2025-06-10",
+		Lines:       []services.WorkOrderLineParams{{SourceItemType: "sub_item", SourceItemID: service.Id, QtyPerSite: 1, Rate: 1500}},
+		SiteIDs:     []string{site.Id},
+	})
+	if err != nil {
+		t.Fatalf("CreateWorkOrder failed: %v", err)
+	}
+	woSite, _ := app.FindFirstRecordByData("work_order_sites", "work_order", wo.Id)
+	if err := services.UpdateWorkOrderSite(ctx, app, woSite.Id, services.SiteProgressParams{Status: services.SiteStatusInstalled, InstallationDate: today}); err != nil {
+		t.Fatalf("UpdateWorkOrderSite failed: %v", err)
+	}
+	pathValues := map[string]string{"projectId": project.Id}
+	target := "/projects/" + project.Id + "/commissioning"
+
+	// A failed test keeps the site uncommissioned
+	rec := postHXForm(t, app, HandleCommissioningSave, target, pathValues, url.Values{
+		"site":              {site.Id},
+		"commissioned_date": {today},
+		"signed_off_by":     {"R. Rao"},
+		"test_name_0":       {"Discharge"},
+		"test_reading_0":    {"2 lps"},
+		"test_result_0":     {"fail"},
+	})
+	testhelpers.AssertHTMLContains(t, rec.Body.String(), "Discharge failed", "Farmer A", wo.GetString("wo_number"))
+
+	rec = postHXForm(t, app, HandleCommissioningSave, target, pathValues, url.Values{
+		"site":              {site.Id},
+		"commissioned_date": {today},
+		"signed_off_by":     {"R. Rao"},
+		"test_name_0":       {"Discharge"},
+		"test_reading_0":    {"6 lps"},
+		"test_result_0":     {"pass"},
+		"test_name_1":       {""},
+	})
+	testhelpers.AssertHXRedirect(t, rec.Header().Get("HX-Redirect"), "/projects/"+project.Id+"/site-tracker")
+	cert, err := app.FindFirstRecordByData("commissioning_records", "install_at_address", site.Id)
+	if err != nil {
+		t.Fatalf("expected the commissioning record to be saved: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/projects/"+project.Id+"/site-tracker?district=Guntur", nil)
+	req.SetPathValue("projectId", project.Id)
+	req.Header.Set("HX-Request", "true")
+	rec = httptest.NewRecorder()
+	if err := HandleSiteTracker(app)(newTestRequestEvent(app, req, rec)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	testhelpers.AssertHTMLContains(t, rec.Body.String(), "Farmer A", "Guntur", wo.GetString("wo_number"), "/commissioning/"+cert.Id+"/export/pdf")
+}
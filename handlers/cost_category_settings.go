@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/a-h/templ"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/collections"
+	"projectcreation/templates"
+)
+
+// costCategoryCode derives a category code from its name, e.g.
+// "Tools & tackles" becomes "tools_tackles".
+func costCategoryCode(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case b.Len() > 0 && !strings.HasSuffix(b.String(), "_"):
+			b.WriteRune('_')
+		}
+	}
+	return strings.TrimSuffix(b.String(), "_")
+}
+
+// renderCostCategorySettings renders the cost categories page as a partial
+// or full page.
+func renderCostCategorySettings(e *core.RequestEvent, data templates.CostCategorySettingsData) error {
+	var component templ.Component
+	if e.Request.Header.Get("HX-Request") == "true" {
+		component = templates.CostCategorySettingsContent(data)
+	} else {
+		headerData := GetHeaderData(e.Request)
+		sidebarData := GetSidebarData(e.Request)
+		component = templates.CostCategorySettingsPage(data, headerData, sidebarData)
+	}
+	return component.Render(e.Request.Context(), e.Response)
+}
+
+// HandleCostCategorySettings renders the cost categories page (GET
+// /settings/cost-categories).
+func HandleCostCategorySettings(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		categories, err := collections.GetCostCategories(app)
+		if err != nil {
+			log.Printf("cost_category_settings: could not load cost categories: %v", err)
+			return ErrorToast(e, http.StatusInternalServerError, "Could not load cost categories")
+		}
+
+		data := templates.CostCategorySettingsData{Errors: make(map[string]string)}
+		for _, c := range categories {
+			data.Categories = append(data.Categories, templates.CostCategoryRow{
+				ID:     c.Id,
+				Code:   c.GetString("code"),
+				Name:   c.GetString("name"),
+				Active: c.GetBool("active"),
+			})
+		}
+		return renderCostCategorySettings(e, data)
+	}
+}
+
+// HandleCostCategorySettingsSave handles the POST /settings/cost-categories
+// form submission. It renames and (de)activates every listed category and
+// adds the blank row if it was filled in. Codes never change.
+func HandleCostCategorySettingsSave(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		if err := e.Request.ParseForm(); err != nil {
+			return ErrorToast(e, http.StatusBadRequest, "Invalid form data")
+		}
+
+		categories, err := collections.GetCostCategories(app)
+		if err != nil {
+			log.Printf("cost_category_settings_save: could not load cost categories: %v", err)
+			return ErrorToast(e, http.StatusInternalServerError, "Could not load cost categories")
+		}
+
+		data := templates.CostCategorySettingsData{
+			NewName: strings.TrimSpace(e.Request.FormValue("new_name")),
+			Errors:  make(map[string]string),
+		}
+		codes := make(map[string]bool, len(categories))
+		for _, c := range categories {
+			row := templates.CostCategoryRow{
+				ID:     c.Id,
+				Code:   c.GetString("code"),
+				Name:   strings.TrimSpace(e.Request.FormValue("name_" + c.Id)),
+				Active: e.Request.FormValue("active_"+c.Id) == "true",
+			}
+			codes[row.Code] = true
+			if row.Name == "" {
+				data.Errors[c.Id] = row.Code + ": name is required"
+			} else {
+				c.Set("name", row.Name)
+				c.Set("active", row.Active)
+			}
+			data.Categories = append(data.Categories, row)
+		}
+
+		newCode := costCategoryCode(data.NewName)
+		if data.NewName != "" && newCode == "" {
+			data.Errors["new"] = data.NewName + ": name must contain letters or digits"
+		}
+		for i := 2; codes[newCode]; i++ {
+			newCode = fmt.Sprintf("%s_%d", costCategoryCode(data.NewName), i)
+		}
+
+		if len(data.Errors) > 0 {
+			SetToast(e, "warning", "Please fix the errors below")
+			return renderCostCategorySettings(e, data)
+		}
+
+		err = app.RunInTransaction(func(txApp core.App) error {
+			for _, c := range categories {
+				if err := txApp.SaveWithContext(e.Request.Context(), c); err != nil {
+					return err
+				}
+			}
+			if data.NewName == "" {
+				return nil
+			}
+			col, err := txApp.FindCollectionByNameOrId("cost_categories")
+			if err != nil {
+				return err
+			}
+			record := core.NewRecord(col)
+			record.Set("code", newCode)
+			record.Set("name", data.NewName)
+			record.Set("sort_order", len(categories)+1)
+			record.Set("active", true)
+			return txApp.SaveWithContext(e.Request.Context(), record)
+		})
+		if err != nil {
+			log.Printf("cost_category_settings_save: could not save cost categories: %v", err)
+			return ErrorToast(e, http.StatusInternalServerError, "Could not save cost categories")
+		}
+
+		SetToast(e, "success", "Cost categories saved")
+
+		if e.Request.Header.Get("HX-Request") == "true" {
+			e.Response.Header().Set("HX-Redirect", "/settings/cost-categories")
+			return e.String(http.StatusOK, "")
+		}
+		return e.Redirect(http.StatusFound, "/settings/cost-categories")
+	}
+}
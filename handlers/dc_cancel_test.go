@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/testhelpers"
+)
+
+func newDCCancelRequest(app *pocketbase.PocketBase, projectID, dcID, reason string) (*httptest.ResponseRecorder, *core.RequestEvent) {
+	form := url.Values{"reason": {reason}}
+	req := httptest.NewRequest(http.MethodPost, "/projects/"+projectID+"/dcs/"+dcID+"/cancel", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("HX-Request", "true")
+	req.SetPathValue("projectId", projectID)
+	req.SetPathValue("id", dcID)
+	rec := httptest.NewRecorder()
+	return rec, newTestRequestEvent(app, req, rec)
+}
+
+func TestHandleDCCancel_RequiresReason(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Cancel Project")
+	dc := createTestDC(t, app, project.Id, "DC-CANCEL-001", "transfer", "issued")
+
+	rec, e := newDCCancelRequest(app, project.Id, dc.Id, "")
+	if err := HandleDCCancel(app)(e); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", rec.Code)
+	}
+	dc, _ = app.FindRecordById("delivery_challans", dc.Id)
+	if dc.GetString("status") != "issued" {
+		t.Errorf("expected DC to stay issued, got %s", dc.GetString("status"))
+	}
+}
+
+func TestHandleDCCancel_Success(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Cancel Project")
+	dc := createTestDC(t, app, project.Id, "DC-CANCEL-002", "transfer", "issued")
+
+	rec, e := newDCCancelRequest(app, project.Id, dc.Id, "Duplicate challan")
+	if err := HandleDCCancel(app)(e); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+
+	testhelpers.AssertHXRedirect(t, rec.Header().Get("HX-Redirect"), "/projects/"+project.Id+"/dcs/"+dc.Id)
+	dc, _ = app.FindRecordById("delivery_challans", dc.Id)
+	if dc.GetString("status") != "cancelled" || dc.GetString("cancel_reason") != "Duplicate challan" {
+		t.Errorf("expected DC to be cancelled with reason, got %s/%q", dc.GetString("status"), dc.GetString("cancel_reason"))
+	}
+
+	// Detail page shows the cancellation banner
+	req := httptest.NewRequest(http.MethodGet, "/projects/"+project.Id+"/dcs/"+dc.Id, nil)
+	req.SetPathValue("projectId", project.Id)
+	req.SetPathValue("id", dc.Id)
+	detailRec := httptest.NewRecorder()
+	if err := HandleDCDetail(app)(newTestRequestEvent(app, req, detailRec)); err != nil {
+		t.Fatalf("detail handler returned error: %v", err)
+	}
+	testhelpers.AssertHTMLContains(t, detailRec.Body.String(), "CANCELLED", "Duplicate challan")
+}
+
+func TestGetExistingSerials_SkipsCancelledDCs(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Serial Project")
+	issued := createTestDC(t, app, project.Id, "DC-SER-001", "transfer", "issued")
+	cancelled := createTestDC(t, app, project.Id, "DC-SER-002", "transfer", "cancelled")
+
+	lineItems, _ := app.FindCollectionByNameOrId("dc_line_items")
+	serialsCol, _ := app.FindCollectionByNameOrId("serial_numbers")
+	for serial, dc := range map[string]*core.Record{"SN-ISSUED": issued, "SN-CANCELLED": cancelled} {
+		li := core.NewRecord(lineItems)
+		li.Set("dc", dc.Id)
+		li.Set("source_item_type", "sub_item")
+		li.Set("source_item_id", "x")
+		li.Set("quantity", 1)
+		if err := app.Save(li); err != nil {
+			t.Fatalf("failed to save line item: %v", err)
+		}
+		sr := core.NewRecord(serialsCol)
+		sr.Set("project", project.Id)
+		sr.Set("line_item", li.Id)
+		sr.Set("serial_number", serial)
+		if err := app.Save(sr); err != nil {
+			t.Fatalf("failed to save serial: %v", err)
+		}
+	}
+
+	existing := getExistingSerials(app, project.Id)
+	if existing["SN-ISSUED"] != "DC-SER-001" {
+		t.Errorf("expected SN-ISSUED to be in use by DC-SER-001, got %q", existing["SN-ISSUED"])
+	}
+	if _, ok := existing["SN-CANCELLED"]; ok {
+		t.Error("expected serial on cancelled DC to be available again")
+	}
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 
@@ -32,13 +33,13 @@ func HandleDCDelete(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
 
 		// For transfer DCs, delete associated metadata first
 		if dc.GetString("dc_type") == "transfer" {
-			if err := deleteTransferDCMetadata(app, dcId); err != nil {
+			if err := deleteTransferDCMetadata(e.Request.Context(), app, dcId); err != nil {
 				return ErrorToast(e, http.StatusInternalServerError, "Failed to delete transfer DC data: "+err.Error())
 			}
 		}
 
 		// Delete the DC (cascade will handle line items and serials)
-		if err := app.Delete(dc); err != nil {
+		if err := app.DeleteWithContext(e.Request.Context(), dc); err != nil {
 			return ErrorToast(e, http.StatusInternalServerError, "Failed to delete DC: "+err.Error())
 		}
 
@@ -69,22 +70,22 @@ func deleteShipmentGroup(app *pocketbase.PocketBase, e *core.RequestEvent, proje
 		// Delete transit details
 		transitDetails, _ := app.FindRecordsByFilter("dc_transit_details", "dc = {:did}", "", 0, 0, map[string]any{"did": dc.Id})
 		for _, td := range transitDetails {
-			_ = app.Delete(td)
+			_ = app.DeleteWithContext(e.Request.Context(), td)
 		}
 		// Delete serial numbers for line items
 		lineItems, _ := app.FindRecordsByFilter("dc_line_items", "dc = {:did}", "", 0, 0, map[string]any{"did": dc.Id})
 		for _, li := range lineItems {
 			serials, _ := app.FindRecordsByFilter("serial_numbers", "line_item = {:lid}", "", 0, 0, map[string]any{"lid": li.Id})
 			for _, s := range serials {
-				_ = app.Delete(s)
+				_ = app.DeleteWithContext(e.Request.Context(), s)
 			}
-			_ = app.Delete(li)
+			_ = app.DeleteWithContext(e.Request.Context(), li)
 		}
-		_ = app.Delete(dc)
+		_ = app.DeleteWithContext(e.Request.Context(), dc)
 	}
 
 	// Delete the shipment group
-	_ = app.Delete(sg)
+	_ = app.DeleteWithContext(e.Request.Context(), sg)
 
 	SetToast(e, "success", "Shipment group and all DCs deleted")
 	redirectURL := fmt.Sprintf("/projects/%s/dcs/", projectId)
@@ -96,7 +97,7 @@ func deleteShipmentGroup(app *pocketbase.PocketBase, e *core.RequestEvent, proje
 }
 
 // deleteTransferDCMetadata deletes transfer_dcs, destinations, and dest_quantities records.
-func deleteTransferDCMetadata(app *pocketbase.PocketBase, dcID string) error {
+func deleteTransferDCMetadata(ctx context.Context, app *pocketbase.PocketBase, dcID string) error {
 	transferRecs, _ := app.FindRecordsByFilter("transfer_dcs", "dc = {:did}", "", 0, 0, map[string]any{"did": dcID})
 	for _, tdc := range transferRecs {
 		// Delete destinations and their quantities
@@ -104,17 +105,17 @@ func deleteTransferDCMetadata(app *pocketbase.PocketBase, dcID string) error {
 		for _, dest := range dests {
 			qtys, _ := app.FindRecordsByFilter("transfer_dc_dest_quantities", "destination = {:did}", "", 0, 0, map[string]any{"did": dest.Id})
 			for _, q := range qtys {
-				_ = app.Delete(q)
+				_ = app.DeleteWithContext(ctx, q)
 			}
-			_ = app.Delete(dest)
+			_ = app.DeleteWithContext(ctx, dest)
 		}
-		_ = app.Delete(tdc)
+		_ = app.DeleteWithContext(ctx, tdc)
 	}
 
 	// Delete transit details
 	transitDetails, _ := app.FindRecordsByFilter("dc_transit_details", "dc = {:did}", "", 0, 0, map[string]any{"did": dcID})
 	for _, td := range transitDetails {
-		_ = app.Delete(td)
+		_ = app.DeleteWithContext(ctx, td)
 	}
 
 	// Delete serial numbers for line items
@@ -122,9 +123,9 @@ func deleteTransferDCMetadata(app *pocketbase.PocketBase, dcID string) error {
 	for _, li := range lineItems {
 		serials, _ := app.FindRecordsByFilter("serial_numbers", "line_item = {:lid}", "", 0, 0, map[string]any{"lid": li.Id})
 		for _, s := range serials {
-			_ = app.Delete(s)
+			_ = app.DeleteWithContext(ctx, s)
 		}
-		_ = app.Delete(li)
+		_ = app.DeleteWithContext(ctx, li)
 	}
 
 	return nil
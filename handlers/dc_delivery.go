@@ -0,0 +1,294 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+
+	"projectcreation/services"
+	"projectcreation/templates"
+)
+
+// findProjectDC loads the DC in the path, checking it belongs to the
+// project in the path.
+func findProjectDC(app *pocketbase.PocketBase, e *core.RequestEvent) (*core.Record, bool) {
+	dc, err := app.FindRecordById("delivery_challans", e.Request.PathValue("id"))
+	if err != nil || dc.GetString("project") != e.Request.PathValue("projectId") {
+		return nil, false
+	}
+	return dc, true
+}
+
+// redirectToDCDetail sends the browser back to the DC detail page.
+func redirectToDCDetail(e *core.RequestEvent, projectId, dcId string) error {
+	redirectURL := fmt.Sprintf("/projects/%s/dcs/%s", projectId, dcId)
+	if e.Request.Header.Get("HX-Request") == "true" {
+		e.Response.Header().Set("HX-Redirect", redirectURL)
+		return e.String(http.StatusOK, "")
+	}
+	return e.Redirect(http.StatusFound, redirectURL)
+}
+
+// uploadedSignedChallans returns the signed challan scans posted with a
+// form. No scan, or a form that is not multipart, is not an error.
+func uploadedSignedChallans(e *core.RequestEvent) ([]*filesystem.File, error) {
+	files, err := e.FindUploadedFiles("signed_challans")
+	if errors.Is(err, http.ErrMissingFile) || errors.Is(err, http.ErrNotMultipart) {
+		return nil, nil
+	}
+	return files, err
+}
+
+func renderDCDeliveryCreate(e *core.RequestEvent, data templates.DCDeliveryCreateData) error {
+	var component templ.Component
+	if e.Request.Header.Get("HX-Request") == "true" {
+		component = templates.DCDeliveryCreateContent(data)
+	} else {
+		headerData := GetHeaderData(e.Request)
+		sidebarData := GetSidebarData(e.Request)
+		component = templates.DCDeliveryCreatePage(data, headerData, sidebarData)
+	}
+	return component.Render(e.Request.Context(), e.Response)
+}
+
+// dcDeliveryCreateData fills the DC summary of the delivery form.
+func dcDeliveryCreateData(app *pocketbase.PocketBase, dc *core.Record) templates.DCDeliveryCreateData {
+	data := templates.DCDeliveryCreateData{
+		ProjectID:   dc.GetString("project"),
+		DCID:        dc.Id,
+		DCNumber:    dc.GetString("dc_number"),
+		DCType:      dc.GetString("dc_type"),
+		ChallanDate: dc.GetString("challan_date"),
+		Errors:      make(map[string]string),
+	}
+	if shipTo := resolveDCAddressDisplay(app, dc.GetString("ship_to_address")); shipTo != nil {
+		data.ShipTo = shipTo.CompanyName
+	}
+	return data
+}
+
+// HandleDCDeliveryCreate renders the proof of delivery form for a DC, with
+// every line received in full.
+func HandleDCDeliveryCreate(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		dc, ok := findProjectDC(app, e)
+		if !ok {
+			return ErrorToast(e, http.StatusNotFound, "Delivery challan not found")
+		}
+		if err := services.CheckDeliverable(app, dc); err != nil {
+			return ErrorToast(e, http.StatusBadRequest, err.Error())
+		}
+
+		lines, err := services.DeliverableDCLines(app, dc.Id)
+		if err != nil {
+			log.Printf("dc_delivery: could not load lines of DC %s: %v", dc.Id, err)
+			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
+		}
+
+		data := dcDeliveryCreateData(app, dc)
+		data.ReceivedDate = time.Now().Format("2006-01-02")
+		for _, l := range lines {
+			data.Lines = append(data.Lines, templates.DCDeliveryCreateLine{
+				DCLineItemID: l.DCLineItemID,
+				Description:  l.Description,
+				UOM:          l.UOM,
+				Dispatched:   formatQty(l.Dispatched),
+				QtyReceived:  formatQty(l.Dispatched),
+				QtyDamaged:   "0",
+			})
+		}
+		return renderDCDeliveryCreate(e, data)
+	}
+}
+
+// HandleDCDeliverySave records the proof of delivery of a DC with any signed
+// challan scans, and marks the DC delivered or partially delivered.
+func HandleDCDeliverySave(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		if err := e.Request.ParseMultipartForm(32 << 20); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+			return ErrorToast(e, http.StatusBadRequest, "Invalid form data")
+		}
+
+		dc, ok := findProjectDC(app, e)
+		if !ok {
+			return ErrorToast(e, http.StatusNotFound, "Delivery challan not found")
+		}
+		lines, err := services.DeliverableDCLines(app, dc.Id)
+		if err != nil {
+			log.Printf("dc_delivery: could not load lines of DC %s: %v", dc.Id, err)
+			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
+		}
+
+		params := services.DeliveryParams{
+			DCID:         dc.Id,
+			ReceivedBy:   strings.TrimSpace(e.Request.FormValue("received_by")),
+			ReceivedDate: strings.TrimSpace(e.Request.FormValue("received_date")),
+			Remarks:      strings.TrimSpace(e.Request.FormValue("remarks")),
+		}
+		formLines := make([]templates.DCDeliveryCreateLine, 0, len(lines))
+		for _, l := range lines {
+			rawReceived := strings.TrimSpace(e.Request.FormValue("received_" + l.DCLineItemID))
+			rawDamaged := strings.TrimSpace(e.Request.FormValue("damaged_" + l.DCLineItemID))
+			note := strings.TrimSpace(e.Request.FormValue("note_" + l.DCLineItemID))
+			received, _ := strconv.ParseFloat(rawReceived, 64)
+			damaged, _ := strconv.ParseFloat(rawDamaged, 64)
+			params.Lines = append(params.Lines, services.DeliveryLineParams{
+				DCLineItemID: l.DCLineItemID,
+				QtyReceived:  received,
+				QtyDamaged:   damaged,
+				Remarks:      note,
+			})
+			formLines = append(formLines, templates.DCDeliveryCreateLine{
+				DCLineItemID: l.DCLineItemID,
+				Description:  l.Description,
+				UOM:          l.UOM,
+				Dispatched:   formatQty(l.Dispatched),
+				QtyReceived:  rawReceived,
+				QtyDamaged:   rawDamaged,
+				Remarks:      note,
+			})
+		}
+
+		if errors := services.ValidateDelivery(app, params); len(errors) > 0 {
+			SetToast(e, "warning", "Please fix the errors below")
+			data := dcDeliveryCreateData(app, dc)
+			data.ReceivedBy = params.ReceivedBy
+			data.ReceivedDate = params.ReceivedDate
+			data.Remarks = params.Remarks
+			data.Lines = formLines
+			data.Errors = errors
+			return renderDCDeliveryCreate(e, data)
+		}
+
+		scans, err := uploadedSignedChallans(e)
+		if err != nil {
+			return ErrorToast(e, http.StatusBadRequest, "Could not read the attached scans")
+		}
+
+		if _, err := services.RecordDelivery(e.Request.Context(), app, params, scans); err != nil {
+			log.Printf("dc_delivery: could not record delivery of DC %s: %v", dc.Id, err)
+			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
+		}
+
+		dc, _ = app.FindRecordById("delivery_challans", dc.Id)
+		if dc.GetString("status") == services.DCStatusPartiallyDelivered {
+			SetToast(e, "success", fmt.Sprintf("%s partially delivered", dc.GetString("dc_number")))
+		} else {
+			SetToast(e, "success", fmt.Sprintf("Delivery of %s recorded", dc.GetString("dc_number")))
+		}
+		return redirectToDCDetail(e, dc.GetString("project"), dc.Id)
+	}
+}
+
+// HandleDCDeliveryScansAdd attaches more signed challan scans to the proof
+// of delivery of a DC.
+func HandleDCDeliveryScansAdd(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		dc, ok := findProjectDC(app, e)
+		if !ok {
+			return ErrorToast(e, http.StatusNotFound, "Delivery challan not found")
+		}
+		delivery, err := app.FindFirstRecordByData("dc_deliveries", "dc", dc.Id)
+		if err != nil {
+			return ErrorToast(e, http.StatusNotFound, "No delivery recorded for this DC")
+		}
+
+		scans, err := uploadedSignedChallans(e)
+		if err != nil {
+			return ErrorToast(e, http.StatusBadRequest, "Could not read the attached scans")
+		}
+		if len(scans) == 0 {
+			return ErrorToast(e, http.StatusBadRequest, "Choose a scan to attach")
+		}
+
+		if err := services.AddDeliveryScans(e.Request.Context(), app, delivery, scans); err != nil {
+			log.Printf("dc_delivery: could not attach scans to delivery %s: %v", delivery.Id, err)
+			return ErrorToast(e, http.StatusBadRequest, "Scans must be JPEG, PNG or PDF files under 5 MB, at most 5 per delivery")
+		}
+
+		SetToast(e, "success", "Signed challan attached")
+		return redirectToDCDetail(e, dc.GetString("project"), dc.Id)
+	}
+}
+
+// HandleDCDeliveryScan serves a signed challan scan of a DC's delivery.
+func HandleDCDeliveryScan(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		dc, ok := findProjectDC(app, e)
+		if !ok {
+			return ErrorToast(e, http.StatusNotFound, "Delivery challan not found")
+		}
+		delivery, err := app.FindFirstRecordByData("dc_deliveries", "dc", dc.Id)
+		if err != nil {
+			return ErrorToast(e, http.StatusNotFound, "No delivery recorded for this DC")
+		}
+
+		filename := e.Request.PathValue("filename")
+		if !slices.Contains(delivery.GetStringSlice("signed_challans"), filename) {
+			return ErrorToast(e, http.StatusNotFound, "Scan not found")
+		}
+
+		fs, err := app.NewFilesystem()
+		if err != nil {
+			log.Printf("dc_delivery: could not open filesystem: %v", err)
+			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
+		}
+		defer fs.Close()
+
+		if err := fs.Serve(e.Response, e.Request, delivery.BaseFilesPath()+"/"+filename, filename); err != nil {
+			log.Printf("dc_delivery: could not serve scan %s of delivery %s: %v", filename, delivery.Id, err)
+			return ErrorToast(e, http.StatusNotFound, "Scan not found")
+		}
+		return nil
+	}
+}
+
+// buildDCDetailDelivery returns the proof of delivery of a DC for its detail
+// page, or nil when none is recorded.
+func buildDCDetailDelivery(app *pocketbase.PocketBase, projectId string, dc *core.Record) *templates.DCDetailDelivery {
+	delivery, err := app.FindFirstRecordByData("dc_deliveries", "dc", dc.Id)
+	if err != nil {
+		return nil
+	}
+
+	descriptions := make(map[string]string)
+	if lines, err := services.DeliverableDCLines(app, dc.Id); err == nil {
+		for _, l := range lines {
+			descriptions[l.DCLineItemID] = l.Description
+		}
+	}
+
+	data := &templates.DCDetailDelivery{
+		ReceivedBy:   delivery.GetString("received_by"),
+		ReceivedDate: delivery.GetString("received_date"),
+		Remarks:      delivery.GetString("remarks"),
+	}
+	lines, _ := app.FindRecordsByFilter("dc_delivery_lines", "delivery = {:id}", "dc_line_item.line_order", 0, 0, map[string]any{"id": delivery.Id})
+	for _, l := range lines {
+		data.Lines = append(data.Lines, templates.DCDetailDeliveryLine{
+			Description: descriptions[l.GetString("dc_line_item")],
+			Dispatched:  formatQty(l.GetFloat("qty_dispatched")),
+			Received:    formatQty(l.GetFloat("qty_received")),
+			Damaged:     formatQty(l.GetFloat("qty_damaged")),
+			Shortage:    formatQty(l.GetFloat("qty_dispatched") - l.GetFloat("qty_received")),
+			Remarks:     l.GetString("remarks"),
+		})
+	}
+	for _, name := range delivery.GetStringSlice("signed_challans") {
+		data.Scans = append(data.Scans, templates.DCDetailScan{
+			Name: name,
+			URL:  fmt.Sprintf("/projects/%s/dcs/%s/delivery/scans/%s", projectId, dc.Id, name),
+		})
+	}
+	return data
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"projectcreation/services"
+	"projectcreation/testhelpers"
+)
+
+func TestHandleDCDeliverySave_RecordsShortage(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Delivery Project")
+	dc := createInvoiceableDC(t, app, project.Id, "ODC-001")
+	line, _ := app.FindFirstRecordByData("dc_line_items", "dc", dc.Id)
+	pathValues := map[string]string{"projectId": project.Id, "id": dc.Id}
+	target := "/projects/" + project.Id + "/dcs/" + dc.Id + "/delivery"
+	today := time.Now().Format("2006-01-02")
+
+	// A shortage without a note is sent back to the form
+	rec := postHXForm(t, app, HandleDCDeliverySave, target, pathValues, url.Values{
+		"received_by":         {"K. Naidu"},
+		"received_date":       {today},
+		"received_" + line.Id: {"4"},
+		"damaged_" + line.Id:  {"0"},
+	})
+	testhelpers.AssertHTMLContains(t, rec.Body.String(), "LED Floodlight: note the reason for the shortage or damage", "K. Naidu")
+
+	rec = postHXForm(t, app, HandleDCDeliverySave, target, pathValues, url.Values{
+		"received_by":         {"K. Naidu"},
+		"received_date":       {today},
+		"received_" + line.Id: {"4"},
+		"damaged_" + line.Id:  {"0"},
+		"note_" + line.Id:     {"One carton missing"},
+	})
+	testhelpers.AssertHXRedirect(t, rec.Header().Get("HX-Redirect"), "/projects/"+project.Id+"/dcs/"+dc.Id)
+	dc, _ = app.FindRecordById("delivery_challans", dc.Id)
+	if dc.GetString("status") != services.DCStatusPartiallyDelivered {
+		t.Errorf("expected partially delivered, got %s", dc.GetString("status"))
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/projects/"+project.Id+"/dcs/"+dc.Id, nil)
+	req.SetPathValue("projectId", project.Id)
+	req.SetPathValue("id", dc.Id)
+	req.Header.Set("HX-Request", "true")
+	rec = httptest.NewRecorder()
+	if err := HandleDCDetail(app)(newTestRequestEvent(app, req, rec)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	testhelpers.AssertHTMLContains(t, rec.Body.String(), "Partially delivered", "K. Naidu", "One carton missing")
+}
@@ -172,13 +172,19 @@ func HandleDCDetail(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
 					shipToAddr := resolveDCAddressDisplay(app, dest.GetString("ship_to_address"))
 					isSplit := dest.GetBool("is_split")
 
-					destinations = append(destinations, templates.DCDetailDestination{
+					dd := templates.DCDetailDestination{
 						ID:          dest.Id,
 						ShipToName:  shipToAddr.CompanyName,
 						ShipToCity:  shipToAddr.City,
 						ShipToState: shipToAddr.State,
 						IsSplit:     isSplit,
-					})
+					}
+					destQtyRecs, _ := app.FindRecordsByFilter("transfer_dc_dest_quantities", "destination = {:did}", "", 0, 0, map[string]any{"did": dest.Id})
+					for _, dq := range destQtyRecs {
+						dd.TotalQty += dq.GetInt("quantity")
+						dd.ForwardedQty += dq.GetInt("forwarded_qty")
+					}
+					destinations = append(destinations, dd)
 				}
 
 				// Resolve hub address
@@ -217,6 +223,15 @@ func HandleDCDetail(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
 			Transit:       transitDetail,
 			ShipmentGroup: shipmentGroupInfo,
 			TransferInfo:  transferInfo,
+			CancelReason:  dc.GetString("cancel_reason"),
+			CancelledAt:   dc.GetString("cancelled_at"),
+			CancelledBy:   dc.GetString("cancelled_by_name"),
+			Delivery:      buildDCDetailDelivery(app, projectId, dc),
+			CanDeliver:    services.CheckDeliverable(app, dc) == nil,
+
+			ExpectedReturnDate: dc.GetString("expected_return_date"),
+			Returns:            buildDCDetailReturns(app, dc),
+			CanReturn:          services.CheckReturnable(app, dc) == nil,
 		}
 
 		var component templ.Component
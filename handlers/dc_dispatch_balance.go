@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"log"
+	"strings"
+
+	"github.com/pocketbase/pocketbase"
+
+	"projectcreation/services"
+	"projectcreation/templates"
+)
+
+// wizardDispatchBalances returns the BOQ dispatch balance of each wizard item,
+// keyed by item key. Items that are not in the project's BOQ are left out,
+// and returnable DCs, which do not draw on the BOQ, get none.
+func wizardDispatchBalances(app *pocketbase.PocketBase, projectId, editDCID, dcType string, items []templates.DCWizardItem) map[string]templates.DCWizardBalance {
+	if dcType == "returnable" {
+		return nil
+	}
+	balances, err := services.ComputeDispatchBalances(app, projectId, editDCID)
+	if err != nil {
+		log.Printf("dc_wizard: could not compute dispatch balances for project %s: %v", projectId, err)
+		return nil
+	}
+
+	result := make(map[string]templates.DCWizardBalance)
+	for _, item := range items {
+		key := services.DispatchItemKey(item.SourceItemType, item.SourceItemID)
+		b, ok := balances[key]
+		if !ok {
+			continue
+		}
+		result[key] = templates.DCWizardBalance{
+			Required:   b.Required,
+			Dispatched: b.Dispatched,
+			InDraft:    b.InDraft,
+			Remaining:  b.Remaining(),
+		}
+	}
+	return result
+}
+
+// checkWizardOverDispatch returns the items whose quantities exceed their
+// remaining BOQ balance. The draft being edited is not counted against
+// itself, and returnable DCs are never over-dispatched.
+func checkWizardOverDispatch(app *pocketbase.PocketBase, projectId, editDCID, dcType string, items []services.ShipmentItemParams) []services.OverDispatch {
+	if dcType == "returnable" {
+		return nil
+	}
+	balances, err := services.ComputeDispatchBalances(app, projectId, editDCID)
+	if err != nil {
+		log.Printf("dc_wizard: could not compute dispatch balances for project %s: %v", projectId, err)
+		return nil
+	}
+	return services.CheckOverDispatch(balances, items)
+}
+
+// overDispatchMessages formats over-dispatched items for display.
+func overDispatchMessages(over []services.OverDispatch) []string {
+	msgs := make([]string, len(over))
+	for i, o := range over {
+		msgs[i] = o.Message()
+	}
+	return msgs
+}
+
+// overDispatchError is the validation message shown when quantities exceed
+// the BOQ balance and no override reason was given.
+func overDispatchError(over []services.OverDispatch) string {
+	return "Quantities exceed the remaining BOQ balance (" + strings.Join(overDispatchMessages(over), "; ") +
+		"). Go back and enter an override reason to dispatch them anyway."
+}
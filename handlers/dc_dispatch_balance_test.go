@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"projectcreation/testhelpers"
+)
+
+// overDispatchForm builds a final wizard submission of qty units of the sub
+// item to one destination.
+func overDispatchForm(templateID, shipToID, subItemID, qty string) url.Values {
+	key := "sub_item_" + subItemID
+	return url.Values{
+		"dc_type":                {"direct"},
+		"template_id":            {templateID},
+		"challan_date":           {"2026-04-20"},
+		"num_destinations":       {"1"},
+		"ship_to_id_0":           {shipToID},
+		"tax_type":               {"cgst_sgst"},
+		"item_keys":              {key},
+		"item_type_" + key:       {"sub_item"},
+		"item_id_" + key:         {subItemID},
+		"item_rate_" + key:       {"100"},
+		"item_tax_" + key:        {"18"},
+		"qty_" + key + "_dest_0": {qty},
+	}
+}
+
+func TestHandleDCWizardStep3_ShowsDispatchBalance(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Balance Project")
+	site := testhelpers.CreateTestAddress(t, app, project.Id, "ship_to", "Site A")
+	billTo := testhelpers.CreateTestAddress(t, app, project.Id, "bill_to", "Client")
+	// BOQ requires 10 x 5 = 50; the draft holds 2 of them
+	_, tmpl, subItem := createDraftShipment(t, app, project.Id, []string{site.Id}, []int{2}, nil)
+
+	form := url.Values{
+		"dc_type":          {"direct"},
+		"template_id":      {tmpl.Id},
+		"challan_date":     {"2026-04-20"},
+		"bill_to_id":       {billTo.Id},
+		"num_destinations": {"1"},
+		"ship_to_id_0":     {site.Id},
+	}
+	req := httptest.NewRequest(http.MethodPost, "/projects/"+project.Id+"/dcs/create/step3", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("HX-Request", "true")
+	req.SetPathValue("projectId", project.Id)
+	rec := httptest.NewRecorder()
+	if err := HandleDCWizardStep3(app)(newTestRequestEvent(app, req, rec)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+
+	testhelpers.AssertHTMLContains(t, rec.Body.String(),
+		"50 required",
+		"2 in draft",
+		"48 remaining",
+		`value="sub_item_`+subItem.Id+`" data-remaining="48"`,
+		"OVER-DISPATCH OVERRIDE",
+	)
+}
+
+func TestHandleDCWizardStep4_FlagsOverDispatch(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Balance Project")
+	site := testhelpers.CreateTestAddress(t, app, project.Id, "ship_to", "Site A")
+	_, tmpl, subItem := createDraftShipment(t, app, project.Id, []string{site.Id}, []int{2}, nil)
+
+	form := overDispatchForm(tmpl.Id, site.Id, subItem.Id, "49")
+	req := httptest.NewRequest(http.MethodPost, "/projects/"+project.Id+"/dcs/create/step4", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("HX-Request", "true")
+	req.SetPathValue("projectId", project.Id)
+	rec := httptest.NewRecorder()
+	if err := HandleDCWizardStep4(app)(newTestRequestEvent(app, req, rec)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+
+	testhelpers.AssertHTMLContains(t, rec.Body.String(),
+		"VALIDATION ERRORS",
+		"Solar panel: 49 exceeds the remaining BOQ balance of 48",
+	)
+}
+
+func TestHandleDCCreate_OverDispatchNeedsReason(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Balance Project")
+	site := testhelpers.CreateTestAddress(t, app, project.Id, "ship_to", "Site A")
+	_, tmpl, subItem := createDraftShipment(t, app, project.Id, []string{site.Id}, []int{2}, nil)
+
+	form := overDispatchForm(tmpl.Id, site.Id, subItem.Id, "49")
+	req := httptest.NewRequest(http.MethodPost, "/projects/"+project.Id+"/dcs/create", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("HX-Request", "true")
+	req.SetPathValue("projectId", project.Id)
+	rec := httptest.NewRecorder()
+	if err := HandleDCCreate(app)(newTestRequestEvent(app, req, rec)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 without a reason, got %d", rec.Code)
+	}
+
+	form.Set("over_dispatch_reason", "Client approved extra spares")
+	req = httptest.NewRequest(http.MethodPost, "/projects/"+project.Id+"/dcs/create", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("HX-Request", "true")
+	req.SetPathValue("projectId", project.Id)
+	rec = httptest.NewRecorder()
+	if err := HandleDCCreate(app)(newTestRequestEvent(app, req, rec)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	if rec.Header().Get("HX-Redirect") == "" {
+		t.Fatalf("expected the DC to be created with an override reason, got status %d", rec.Code)
+	}
+
+	transits, _ := app.FindRecordsByFilter("delivery_challans",
+		"project = {:pid} && dc_type = 'transit' && over_dispatch_reason != ''", "", 0, 0,
+		map[string]any{"pid": project.Id})
+	if len(transits) != 1 || transits[0].GetString("over_dispatch_reason") != "Client approved extra spares" {
+		t.Errorf("expected the override reason on the new transit DC, got %d DCs", len(transits))
+	}
+}
@@ -3,13 +3,20 @@ package handlers
 import (
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 
+	"github.com/a-h/templ"
 	"github.com/pocketbase/pocketbase"
 	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/services"
+	"projectcreation/templates"
 )
 
-// HandleDCEdit redirects a draft DC back into the wizard for editing.
-// Currently redirects to the DC wizard step 1 with the existing template pre-selected.
+// HandleDCEdit reopens a draft DC in the DC wizard with its saved values filled
+// in. The edit_dc_id form field carries the draft through the steps, and
+// HandleDCCreate then updates it in place instead of creating a new DC.
 func HandleDCEdit(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
 	return func(e *core.RequestEvent) error {
 		projectId := e.Request.PathValue("projectId")
@@ -26,14 +33,113 @@ func HandleDCEdit(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
 			return ErrorToast(e, http.StatusBadRequest, "Only draft DCs can be edited")
 		}
 
-		// For now, redirect to the create wizard — user will need to re-enter data
-		// A full edit implementation would pre-populate the wizard with existing data
-		SetToast(e, "info", "Please create a new DC — edit with pre-population coming soon. Delete the old DC if needed.")
-		redirectURL := fmt.Sprintf("/projects/%s/dcs/create", projectId)
+		draft, err := services.LoadDCDraft(app, dcId)
+		if err != nil {
+			return ErrorToast(e, http.StatusBadRequest, "Cannot edit this DC: "+err.Error())
+		}
+
+		data := templates.DCWizardStep1Data{
+			ProjectID:      projectId,
+			EditDCID:       draft.DCID,
+			Templates:      fetchDCTemplatesForProject(app, projectId),
+			Transporters:   fetchActiveTransporters(app, projectId),
+			DCType:         draft.DCType,
+			TemplateID:     draft.TemplateID,
+			ChallanDate:    draft.ChallanDate,
+			TransporterID:  draft.TransporterID,
+			VehicleID:      draft.VehicleID,
+			EwayBillNumber: draft.EwayBillNumber,
+			DocketNumber:   draft.DocketNumber,
+			ReverseCharge:  draft.ReverseCharge,
+			Errors:         make(map[string]string),
+
+			ExpectedReturnDate: draft.ExpectedReturnDate,
+		}
+
+		var component templ.Component
 		if e.Request.Header.Get("HX-Request") == "true" {
-			e.Response.Header().Set("HX-Redirect", redirectURL)
-			return e.String(http.StatusOK, "")
+			component = templates.DCWizardStep1Content(data)
+		} else {
+			headerData := GetHeaderData(e.Request)
+			sidebarData := GetSidebarData(e.Request)
+			component = templates.DCWizardStep1Page(data, headerData, sidebarData)
+		}
+		return component.Render(e.Request.Context(), e.Response)
+	}
+}
+
+// loadEditDraft returns the draft being edited in the wizard, or nil when the
+// wizard is creating a new DC.
+func loadEditDraft(app *pocketbase.PocketBase, projectId, editDCID string) *services.DCDraft {
+	if editDCID == "" {
+		return nil
+	}
+	draft, err := services.LoadDCDraft(app, editDCID)
+	if err != nil || draft.ProjectID != projectId {
+		return nil
+	}
+	return draft
+}
+
+// applyDraftStep2 fills the step 2 addresses from the draft being edited.
+func applyDraftStep2(app *pocketbase.PocketBase, data *templates.DCWizardStep2Data, draft *services.DCDraft) {
+	data.BillFromID = draft.BillFromID
+	data.DispatchFromID = draft.DispatchFromID
+	data.BillToID = draft.BillToID
+	data.ShipToIDs = draft.ShipToIDs
+	data.NumDestinations = max(len(draft.ShipToIDs), 1)
+	data.HubAddressID = draft.HubAddressID
+	// Only pin the tax type if it was overridden when the draft was saved
+	if draft.TaxType != determineTaxType(app, draft.BillFromID, draft.ShipToIDs) {
+		data.TaxTypeOverride = draft.TaxType
+	}
+}
+
+// draftStep3Values maps a draft's quantities and serials onto the step 3 form
+// fields. Quantities follow their ship-to address, so destinations reordered
+// or added in step 2 keep the right numbers; template items the draft left
+// out start at zero.
+func draftStep3Values(draft *services.DCDraft, items []templates.DCWizardItem, shipToIDs []string) (map[string]string, map[string]string) {
+	quantities := make(map[string]string)
+	serials := make(map[string]string)
+
+	for _, wi := range items {
+		key := fmt.Sprintf("%s_%s", wi.SourceItemType, wi.SourceItemID)
+
+		var saved *services.ShipmentItemParams
+		for i := range draft.Items {
+			if draft.Items[i].SourceItemType == wi.SourceItemType && draft.Items[i].SourceItemID == wi.SourceItemID {
+				saved = &draft.Items[i]
+				break
+			}
+		}
+
+		for destIdx, sid := range shipToIDs {
+			qty := 0
+			if saved != nil {
+				if j := draftDestinationIndex(draft.ShipToIDs, destIdx, sid); j >= 0 && j < len(saved.Quantities) {
+					qty = saved.Quantities[j]
+				}
+			}
+			quantities[fmt.Sprintf("qty_%s_dest_%d", key, destIdx)] = strconv.Itoa(qty)
+		}
+		if saved != nil && len(saved.Serials) > 0 {
+			serials[fmt.Sprintf("serials_%s", key)] = strings.Join(saved.Serials, "\n")
+		}
+	}
+	return quantities, serials
+}
+
+// draftDestinationIndex finds the draft destination for a ship-to address,
+// preferring the same position when an address appears more than once.
+func draftDestinationIndex(draftShipToIDs []string, destIdx int, shipToID string) int {
+	if destIdx < len(draftShipToIDs) && draftShipToIDs[destIdx] == shipToID {
+		return destIdx
+	}
+	for j, sid := range draftShipToIDs {
+		if sid == shipToID {
+			return j
 		}
-		return e.Redirect(http.StatusFound, redirectURL)
 	}
+	return -1
 }
@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/services"
+	"projectcreation/testhelpers"
+)
+
+// createDraftShipment creates a draft direct shipment of one sub item to the
+// given ship-to addresses using a template that lists the item.
+func createDraftShipment(t *testing.T, app *pocketbase.PocketBase, projectID string, shipToIDs []string, quantities []int, serials []string) (*services.ShipmentResult, *core.Record, *core.Record) {
+	t.Helper()
+	boq := testhelpers.CreateTestBOQ(t, app, projectID, "Edit BOQ")
+	mainItem := testhelpers.CreateTestMainBOQItem(t, app, boq.Id, "Panels")
+	subItem := testhelpers.CreateTestSubItem(t, app, mainItem.Id, "Solar panel")
+	tmpl := createTestDCTemplate(t, app, projectID, "Panels Template")
+
+	col, err := app.FindCollectionByNameOrId("dc_template_items")
+	if err != nil {
+		t.Fatalf("failed to find dc_template_items collection: %v", err)
+	}
+	ti := core.NewRecord(col)
+	ti.Set("template", tmpl.Id)
+	ti.Set("source_item_type", "sub_item")
+	ti.Set("source_item_id", subItem.Id)
+	ti.Set("default_quantity", 1)
+	ti.Set("serial_tracking", "optional")
+	if err := app.Save(ti); err != nil {
+		t.Fatalf("failed to save template item: %v", err)
+	}
+
+	total := 0
+	for _, q := range quantities {
+		total += q
+	}
+	result, err := services.CreateDirectShipment(context.Background(), app, services.ShipmentParams{
+		ProjectID:      projectID,
+		TemplateID:     tmpl.Id,
+		ChallanDate:    "2026-04-10",
+		EwayBillNumber: "EWB-7788",
+		ShipToIDs:      shipToIDs,
+		TaxType:        "cgst_sgst",
+		Items: []services.ShipmentItemParams{{
+			SourceItemType: "sub_item", SourceItemID: subItem.Id, Rate: 100,
+			Quantities: quantities, TotalQty: total, Serials: serials,
+		}},
+	})
+	if err != nil {
+		t.Fatalf("CreateDirectShipment failed: %v", err)
+	}
+	return result, tmpl, subItem
+}
+
+func TestHandleDCEdit_PrefillsWizard(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Edit Project")
+	site := testhelpers.CreateTestAddress(t, app, project.Id, "ship_to", "Site A")
+	shipment, tmpl, _ := createDraftShipment(t, app, project.Id, []string{site.Id}, []int{2}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/projects/"+project.Id+"/dcs/"+shipment.OfficialDCIDs[0]+"/edit", nil)
+	req.Header.Set("HX-Request", "true")
+	req.SetPathValue("projectId", project.Id)
+	req.SetPathValue("id", shipment.OfficialDCIDs[0])
+	rec := httptest.NewRecorder()
+	if err := HandleDCEdit(app)(newTestRequestEvent(app, req, rec)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	testhelpers.AssertHTMLContains(t, rec.Body.String(),
+		"Edit Delivery Challan",
+		`name="edit_dc_id" value="`+shipment.TransitDCID+`"`,
+		`<option value="`+tmpl.Id+`" selected>`,
+		"EWB-7788",
+		"2026-04-10",
+	)
+	if strings.Contains(rec.Body.String(), `value="transfer"`) {
+		t.Error("expected the DC type to be locked to direct while editing")
+	}
+}
+
+func TestHandleDCEdit_OnlyDraft(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Edit Project")
+	dc := createTestDC(t, app, project.Id, "DC-EDIT-001", "transfer", "issued")
+
+	req := httptest.NewRequest(http.MethodGet, "/projects/"+project.Id+"/dcs/"+dc.Id+"/edit", nil)
+	req.SetPathValue("projectId", project.Id)
+	req.SetPathValue("id", dc.Id)
+	rec := httptest.NewRecorder()
+	if err := HandleDCEdit(app)(newTestRequestEvent(app, req, rec)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestHandleDCWizardStep3_EditPrefillsQuantitiesAndSerials(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Edit Project")
+	siteA := testhelpers.CreateTestAddress(t, app, project.Id, "ship_to", "Site A")
+	siteB := testhelpers.CreateTestAddress(t, app, project.Id, "ship_to", "Site B")
+	billTo := testhelpers.CreateTestAddress(t, app, project.Id, "bill_to", "Client")
+	shipment, tmpl, subItem := createDraftShipment(t, app, project.Id, []string{siteA.Id, siteB.Id}, []int{2, 3}, []string{"SN-1", "SN-2"})
+
+	// Destinations swapped in step 2: quantities follow their address
+	form := url.Values{
+		"edit_dc_id":       {shipment.TransitDCID},
+		"dc_type":          {"direct"},
+		"template_id":      {tmpl.Id},
+		"challan_date":     {"2026-04-10"},
+		"bill_to_id":       {billTo.Id},
+		"num_destinations": {"2"},
+		"ship_to_id_0":     {siteB.Id},
+		"ship_to_id_1":     {siteA.Id},
+	}
+	req := httptest.NewRequest(http.MethodPost, "/projects/"+project.Id+"/dcs/create/step3", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("HX-Request", "true")
+	req.SetPathValue("projectId", project.Id)
+	rec := httptest.NewRecorder()
+	if err := HandleDCWizardStep3(app)(newTestRequestEvent(app, req, rec)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+
+	key := "sub_item_" + subItem.Id
+	testhelpers.AssertHTMLContains(t, rec.Body.String(),
+		`name="qty_`+key+`_dest_0" value="3"`,
+		`name="qty_`+key+`_dest_1" value="2"`,
+		"SN-1\nSN-2</textarea>",
+		`name="edit_dc_id" value="`+shipment.TransitDCID+`"`,
+	)
+}
+
+func TestHandleDCCreate_UpdatesDraftInPlace(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Edit Project")
+	site := testhelpers.CreateTestAddress(t, app, project.Id, "ship_to", "Site A")
+	shipment, tmpl, subItem := createDraftShipment(t, app, project.Id, []string{site.Id}, []int{2}, []string{"SN-1", "SN-2"})
+
+	key := "sub_item_" + subItem.Id
+	form := url.Values{
+		"edit_dc_id":             {shipment.TransitDCID},
+		"dc_type":                {"direct"},
+		"template_id":            {tmpl.Id},
+		"challan_date":           {"2026-04-15"},
+		"num_destinations":       {"1"},
+		"ship_to_id_0":           {site.Id},
+		"tax_type":               {"cgst_sgst"},
+		"item_keys":              {key},
+		"item_type_" + key:       {"sub_item"},
+		"item_id_" + key:         {subItem.Id},
+		"item_rate_" + key:       {"100"},
+		"item_tax_" + key:        {"18"},
+		"qty_" + key + "_dest_0": {"3"},
+		"serials_" + key:         {"SN-1\nSN-2\nSN-3"},
+	}
+	req := httptest.NewRequest(http.MethodPost, "/projects/"+project.Id+"/dcs/create", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("HX-Request", "true")
+	req.SetPathValue("projectId", project.Id)
+	rec := httptest.NewRecorder()
+	if err := HandleDCCreate(app)(newTestRequestEvent(app, req, rec)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+
+	testhelpers.AssertHXRedirect(t, rec.Header().Get("HX-Redirect"), "/projects/"+project.Id+"/dcs/"+shipment.TransitDCID)
+
+	dcs, _ := app.FindRecordsByFilter("delivery_challans", "project = {:pid}", "", 0, 0, map[string]any{"pid": project.Id})
+	if len(dcs) != 2 {
+		t.Errorf("expected the transit and official DC only, got %d DCs", len(dcs))
+	}
+	for _, dc := range dcs {
+		if !strings.HasPrefix(dc.GetString("challan_date"), "2026-04-15") {
+			t.Errorf("DC %s was not updated: challan date %s", dc.GetString("dc_number"), dc.GetString("challan_date"))
+		}
+	}
+	official, err := app.FindRecordById("delivery_challans", shipment.OfficialDCIDs[0])
+	if err != nil {
+		t.Fatalf("official DC should be kept: %v", err)
+	}
+	if official.GetString("dc_number") != shipment.OfficialDCNums[0] {
+		t.Errorf("official DC number changed to %s", official.GetString("dc_number"))
+	}
+	serials, _ := app.FindRecordsByFilter("serial_numbers", "project = {:pid}", "", 0, 0, map[string]any{"pid": project.Id})
+	if len(serials) != 3 {
+		t.Errorf("expected 3 serials after edit, got %d", len(serials))
+	}
+}
+
+func TestHandleDCCreate_RejectsDraftOfAnotherProject(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Edit Project")
+	other := testhelpers.CreateTestProject(t, app, "Other Project")
+	site := testhelpers.CreateTestAddress(t, app, other.Id, "ship_to", "Other Site")
+	shipment, tmpl, subItem := createDraftShipment(t, app, other.Id, []string{site.Id}, []int{2}, []string{"SN-1", "SN-2"})
+
+	key := "sub_item_" + subItem.Id
+	form := url.Values{
+		"edit_dc_id":             {shipment.TransitDCID},
+		"dc_type":                {"direct"},
+		"template_id":            {tmpl.Id},
+		"challan_date":           {"2026-04-15"},
+		"num_destinations":       {"1"},
+		"ship_to_id_0":           {site.Id},
+		"tax_type":               {"cgst_sgst"},
+		"item_keys":              {key},
+		"item_type_" + key:       {"sub_item"},
+		"item_id_" + key:         {subItem.Id},
+		"item_rate_" + key:       {"100"},
+		"item_tax_" + key:        {"18"},
+		"qty_" + key + "_dest_0": {"3"},
+		"serials_" + key:         {"SN-1\nSN-2\nSN-3"},
+	}
+	// Posted to the create route of a project the draft is not on
+	req := httptest.NewRequest(http.MethodPost, "/projects/"+project.Id+"/dcs/create", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("HX-Request", "true")
+	req.SetPathValue("projectId", project.Id)
+	rec := httptest.NewRecorder()
+	if err := HandleDCCreate(app)(newTestRequestEvent(app, req, rec)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+
+	if rec.Header().Get("HX-Redirect") != "" {
+		t.Fatalf("expected the edit to be rejected, got a redirect to %s", rec.Header().Get("HX-Redirect"))
+	}
+	testhelpers.AssertHTMLContains(t, rec.Body.String(), "does not belong to this project")
+	transit, _ := app.FindRecordById("delivery_challans", shipment.TransitDCID)
+	if !strings.HasPrefix(transit.GetString("challan_date"), "2026-04-10") {
+		t.Errorf("expected the other project's transit DC unchanged, got challan date %s", transit.GetString("challan_date"))
+	}
+	sg, _ := app.FindRecordById("shipment_groups", shipment.ShipmentGroupID)
+	if sg.GetString("project") != other.Id {
+		t.Errorf("expected the shipment group to stay on its project")
+	}
+	for _, pid := range []string{project.Id, other.Id} {
+		serials, _ := app.FindRecordsByFilter("serial_numbers", "project = {:pid}", "", 0, 0, map[string]any{"pid": pid})
+		if want := map[string]int{project.Id: 0, other.Id: 2}[pid]; len(serials) != want {
+			t.Errorf("expected %d serials on project %s, got %d", want, pid, len(serials))
+		}
+	}
+}
@@ -25,6 +25,7 @@ func HandleDCExportPDF(app *pocketbase.PocketBase) func(*core.RequestEvent) erro
 			log.Printf("dc_export_pdf: %v", err)
 			return e.String(http.StatusNotFound, "DC not found")
 		}
+		data.VerifyQR = documentVerifyQR(app, services.VerifyKindDC, dcId, "Delivery Challan", data.DCNumber, data.ChallanDate, data.GrandTotal)
 
 		pdfBytes, err := services.GenerateDCPDF(data)
 		if err != nil {
@@ -87,11 +88,12 @@ func buildDCExportData(app *pocketbase.PocketBase, projectId, dcId string) (*ser
 	companyName := collections.GetCompanyName(app)
 
 	data := &services.DCExportData{
-		CompanyName: companyName,
-		DCNumber:    dc.GetString("dc_number"),
-		DCType:      dc.GetString("dc_type"),
-		Status:      dc.GetString("status"),
-		ChallanDate: dc.GetString("challan_date"),
+		CompanyName:  companyName,
+		DCNumber:     dc.GetString("dc_number"),
+		DCType:       dc.GetString("dc_type"),
+		Status:       dc.GetString("status"),
+		ChallanDate:  dc.GetString("challan_date"),
+		CancelReason: dc.GetString("cancel_reason"),
 	}
 
 	// Resolve addresses
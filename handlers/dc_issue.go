@@ -25,7 +25,7 @@ func HandleDCIssue(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
 			return ErrorToast(e, http.StatusNotFound, "Delivery challan not found in this project")
 		}
 
-		if err := services.IssueSingleDC(app, dcId); err != nil {
+		if err := services.IssueSingleDC(e.Request.Context(), app, dcId); err != nil {
 			return ErrorToast(e, http.StatusBadRequest, "Issue failed: "+err.Error())
 		}
 
@@ -155,6 +155,7 @@ func HandleDCPrint(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
 			Transit:      transit,
 			HubAddress:   hubAddress,
 			Destinations: destinations,
+			CancelReason: dc.GetString("cancel_reason"),
 		}
 
 		component := templates.DCPrintPage(data)
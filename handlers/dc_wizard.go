@@ -162,6 +162,7 @@ func HandleDCWizardStep2(app *pocketbase.PocketBase) func(*core.RequestEvent) er
 		}
 
 		projectId := e.Request.PathValue("projectId")
+		editDCID := e.Request.FormValue("edit_dc_id")
 
 		// Extract step 1 values
 		dcType := strings.TrimSpace(e.Request.FormValue("dc_type"))
@@ -171,6 +172,7 @@ func HandleDCWizardStep2(app *pocketbase.PocketBase) func(*core.RequestEvent) er
 		vehicleID := strings.TrimSpace(e.Request.FormValue("vehicle_id"))
 		ewayBillNumber := strings.TrimSpace(e.Request.FormValue("eway_bill_number"))
 		docketNumber := strings.TrimSpace(e.Request.FormValue("docket_number"))
+		expectedReturnDate := strings.TrimSpace(e.Request.FormValue("expected_return_date"))
 		reverseCharge := e.Request.FormValue("reverse_charge") == "on"
 
 		// Validate step 1
@@ -184,6 +186,11 @@ func HandleDCWizardStep2(app *pocketbase.PocketBase) func(*core.RequestEvent) er
 		if challanDate == "" {
 			errors["challan_date"] = "Challan date is required"
 		}
+		if dcType == "returnable" {
+			if err := services.CheckReturnDates(challanDate, expectedReturnDate); err != nil {
+				errors["expected_return_date"] = "Expected return date is required and cannot be before the challan date"
+			}
+		}
 
 		if len(errors) > 0 {
 			SetToast(e, "warning", "Please fix the errors below")
@@ -191,6 +198,7 @@ func HandleDCWizardStep2(app *pocketbase.PocketBase) func(*core.RequestEvent) er
 			transporters := fetchActiveTransporters(app, projectId)
 			data := templates.DCWizardStep1Data{
 				ProjectID:      projectId,
+				EditDCID:       editDCID,
 				Templates:      dcTemplates,
 				Transporters:   transporters,
 				DCType:         dcType,
@@ -202,6 +210,8 @@ func HandleDCWizardStep2(app *pocketbase.PocketBase) func(*core.RequestEvent) er
 				DocketNumber:   docketNumber,
 				ReverseCharge:  reverseCharge,
 				Errors:         errors,
+
+				ExpectedReturnDate: expectedReturnDate,
 			}
 			var component templ.Component
 			if e.Request.Header.Get("HX-Request") == "true" {
@@ -254,6 +264,7 @@ func HandleDCWizardStep2(app *pocketbase.PocketBase) func(*core.RequestEvent) er
 
 		data := templates.DCWizardStep2Data{
 			ProjectID:             projectId,
+			EditDCID:              editDCID,
 			DCType:                dcType,
 			TemplateID:            templateID,
 			TemplateName:          templateName,
@@ -264,6 +275,7 @@ func HandleDCWizardStep2(app *pocketbase.PocketBase) func(*core.RequestEvent) er
 			VehicleNumber:         vehicleNumber,
 			EwayBillNumber:        ewayBillNumber,
 			DocketNumber:          docketNumber,
+			ExpectedReturnDate:    expectedReturnDate,
 			ReverseCharge:         reverseCharge,
 			BillFromAddresses:     billFromAddresses,
 			DispatchFromAddresses: dispatchFromAddresses,
@@ -277,6 +289,10 @@ func HandleDCWizardStep2(app *pocketbase.PocketBase) func(*core.RequestEvent) er
 			Errors:                make(map[string]string),
 		}
 
+		if draft := loadEditDraft(app, projectId, editDCID); draft != nil {
+			applyDraftStep2(app, &data, draft)
+		}
+
 		var component templ.Component
 		if e.Request.Header.Get("HX-Request") == "true" {
 			component = templates.DCWizardStep2Content(data)
@@ -297,11 +313,13 @@ func HandleDCWizardBackToStep1(app *pocketbase.PocketBase) func(*core.RequestEve
 		}
 
 		projectId := e.Request.PathValue("projectId")
+		editDCID := e.Request.FormValue("edit_dc_id")
 		dcTemplates := fetchDCTemplatesForProject(app, projectId)
 		transporters := fetchActiveTransporters(app, projectId)
 
 		data := templates.DCWizardStep1Data{
 			ProjectID:      projectId,
+			EditDCID:       editDCID,
 			Templates:      dcTemplates,
 			Transporters:   transporters,
 			DCType:         e.Request.FormValue("dc_type"),
@@ -313,6 +331,8 @@ func HandleDCWizardBackToStep1(app *pocketbase.PocketBase) func(*core.RequestEve
 			DocketNumber:   e.Request.FormValue("docket_number"),
 			ReverseCharge:  e.Request.FormValue("reverse_charge") == "on",
 			Errors:         make(map[string]string),
+
+			ExpectedReturnDate: e.Request.FormValue("expected_return_date"),
 		}
 
 		var component templ.Component
@@ -335,6 +355,7 @@ func HandleDCWizardStep3(app *pocketbase.PocketBase) func(*core.RequestEvent) er
 		}
 
 		projectId := e.Request.PathValue("projectId")
+		editDCID := e.Request.FormValue("edit_dc_id")
 
 		// Extract step 1 values (carried forward)
 		dcType := e.Request.FormValue("dc_type")
@@ -344,6 +365,7 @@ func HandleDCWizardStep3(app *pocketbase.PocketBase) func(*core.RequestEvent) er
 		vehicleID := e.Request.FormValue("vehicle_id")
 		ewayBillNumber := e.Request.FormValue("eway_bill_number")
 		docketNumber := e.Request.FormValue("docket_number")
+		expectedReturnDate := e.Request.FormValue("expected_return_date")
 		reverseCharge := e.Request.FormValue("reverse_charge") == "on"
 
 		// Extract step 2 values
@@ -408,6 +430,7 @@ func HandleDCWizardStep3(app *pocketbase.PocketBase) func(*core.RequestEvent) er
 
 			data := templates.DCWizardStep2Data{
 				ProjectID:             projectId,
+				EditDCID:              editDCID,
 				DCType:                dcType,
 				TemplateID:            templateID,
 				TemplateName:          templateName,
@@ -418,6 +441,7 @@ func HandleDCWizardStep3(app *pocketbase.PocketBase) func(*core.RequestEvent) er
 				VehicleNumber:         vehicleNumber,
 				EwayBillNumber:        ewayBillNumber,
 				DocketNumber:          docketNumber,
+				ExpectedReturnDate:    expectedReturnDate,
 				ReverseCharge:         reverseCharge,
 				BillFromAddresses:     fetchAddressesByType(app, projectId, "bill_from"),
 				DispatchFromAddresses: fetchAddressesByType(app, projectId, "ship_from"),
@@ -482,6 +506,7 @@ func HandleDCWizardStep3(app *pocketbase.PocketBase) func(*core.RequestEvent) er
 
 		data := templates.DCWizardStep3Data{
 			ProjectID:      projectId,
+			EditDCID:       editDCID,
 			DCType:         dcType,
 			TemplateID:     templateID,
 			TemplateName:   templateName,
@@ -500,6 +525,14 @@ func HandleDCWizardStep3(app *pocketbase.PocketBase) func(*core.RequestEvent) er
 			Items:          wizardItems,
 			Destinations:   destinations,
 			Errors:         make(map[string]string),
+
+			ExpectedReturnDate: expectedReturnDate,
+		}
+
+		data.Balances = wizardDispatchBalances(app, projectId, editDCID, dcType, wizardItems)
+		if draft := loadEditDraft(app, projectId, editDCID); draft != nil {
+			data.Quantities, data.Serials = draftStep3Values(draft, wizardItems, shipToIDs)
+			data.OverDispatchReason = draft.OverDispatchReason
 		}
 
 		var component templ.Component
@@ -522,6 +555,7 @@ func HandleDCWizardBackToStep2(app *pocketbase.PocketBase) func(*core.RequestEve
 		}
 
 		projectId := e.Request.PathValue("projectId")
+		editDCID := e.Request.FormValue("edit_dc_id")
 
 		dcType := e.Request.FormValue("dc_type")
 		templateID := e.Request.FormValue("template_id")
@@ -530,6 +564,7 @@ func HandleDCWizardBackToStep2(app *pocketbase.PocketBase) func(*core.RequestEve
 		vehicleID := e.Request.FormValue("vehicle_id")
 		ewayBillNumber := e.Request.FormValue("eway_bill_number")
 		docketNumber := e.Request.FormValue("docket_number")
+		expectedReturnDate := e.Request.FormValue("expected_return_date")
 		reverseCharge := e.Request.FormValue("reverse_charge") == "on"
 
 		billFromID := e.Request.FormValue("bill_from_id")
@@ -575,6 +610,7 @@ func HandleDCWizardBackToStep2(app *pocketbase.PocketBase) func(*core.RequestEve
 
 		data := templates.DCWizardStep2Data{
 			ProjectID:             projectId,
+			EditDCID:              editDCID,
 			DCType:                dcType,
 			TemplateID:            templateID,
 			TemplateName:          templateName,
@@ -585,6 +621,7 @@ func HandleDCWizardBackToStep2(app *pocketbase.PocketBase) func(*core.RequestEve
 			VehicleNumber:         vehicleNumber,
 			EwayBillNumber:        ewayBillNumber,
 			DocketNumber:          docketNumber,
+			ExpectedReturnDate:    expectedReturnDate,
 			ReverseCharge:         reverseCharge,
 			BillFromAddresses:     fetchAddressesByType(app, projectId, "bill_from"),
 			DispatchFromAddresses: fetchAddressesByType(app, projectId, "ship_from"),
@@ -628,8 +665,13 @@ func HandleSerialValidate(app *pocketbase.PocketBase) func(*core.RequestEvent) e
 
 		serials := splitSerials(serialsRaw)
 
-		// Get existing serials for project
-		existingSerials := getExistingSerials(app, projectId)
+		// Look up which of these serials are in use in the project, other
+		// than on the draft being edited
+		existingSerials, err := services.FindSerialsInUse(app, projectId, serials, e.Request.FormValue("edit_dc_id"))
+		if err != nil {
+			log.Printf("dc_wizard: could not check serials: %v", err)
+			return e.String(http.StatusInternalServerError, "Could not check serial numbers")
+		}
 
 		result := services.ValidateSerials(serials, expectedQty, existingSerials)
 
@@ -639,18 +681,20 @@ func HandleSerialValidate(app *pocketbase.PocketBase) func(*core.RequestEvent) e
 	}
 }
 
-// determineTaxType determines IGST or CGST+SGST based on state comparison.
+// determineTaxType determines IGST or CGST+SGST for a DC from the states of
+// its bill-from and ship-to addresses. The supply is inter-state as soon as
+// one destination is. Until the bill-from and a ship-to address are chosen
+// the DC defaults to CGST+SGST; ship-to addresses that cannot be read are
+// skipped.
 func determineTaxType(app *pocketbase.PocketBase, billFromID string, shipToIDs []string) string {
 	if billFromID == "" || len(shipToIDs) == 0 {
-		return "cgst_sgst"
+		return services.TaxTypeCGSTSGST
 	}
-
 	billFromRec, err := app.FindRecordById("addresses", billFromID)
 	if err != nil {
-		return "cgst_sgst"
+		return services.TaxTypeCGSTSGST
 	}
-	billFromData := readAddressData(billFromRec)
-	billFromState := strings.ToLower(strings.TrimSpace(billFromData["state"]))
+	from := readAddressData(billFromRec)
 
 	for _, sid := range shipToIDs {
 		if sid == "" {
@@ -660,15 +704,12 @@ func determineTaxType(app *pocketbase.PocketBase, billFromID string, shipToIDs [
 		if err != nil {
 			continue
 		}
-		shipToData := readAddressData(shipToRec)
-		shipToState := strings.ToLower(strings.TrimSpace(shipToData["state"]))
-
-		if billFromState != shipToState {
-			return "igst"
+		to := readAddressData(shipToRec)
+		if services.DetermineTaxTypeByState(from["state"], from["gstin"], to["state"], to["gstin"]) == services.TaxTypeIGST {
+			return services.TaxTypeIGST
 		}
 	}
-
-	return "cgst_sgst"
+	return services.TaxTypeCGSTSGST
 }
 
 // splitSerials splits a newline/comma-separated serial string into individual serials.
@@ -694,6 +735,7 @@ func HandleDCWizardStep4(app *pocketbase.PocketBase) func(*core.RequestEvent) er
 		}
 
 		projectId := e.Request.PathValue("projectId")
+		editDCID := e.Request.FormValue("edit_dc_id")
 
 		// Extract all carried-forward values
 		dcType := e.Request.FormValue("dc_type")
@@ -703,6 +745,7 @@ func HandleDCWizardStep4(app *pocketbase.PocketBase) func(*core.RequestEvent) er
 		vehicleID := e.Request.FormValue("vehicle_id")
 		ewayBillNumber := e.Request.FormValue("eway_bill_number")
 		docketNumber := e.Request.FormValue("docket_number")
+		expectedReturnDate := e.Request.FormValue("expected_return_date")
 		reverseCharge := e.Request.FormValue("reverse_charge") == "on"
 		billFromID := e.Request.FormValue("bill_from_id")
 		dispatchFromID := e.Request.FormValue("dispatch_from_id")
@@ -826,7 +869,15 @@ func HandleDCWizardStep4(app *pocketbase.PocketBase) func(*core.RequestEvent) er
 
 		// Validate serials against existing in project
 		if len(errors) == 0 {
-			existingSerials := getExistingSerials(app, projectId)
+			var allSerials []string
+			for _, item := range reviewItems {
+				allSerials = append(allSerials, item.Serials...)
+			}
+			existingSerials, err := services.FindSerialsInUse(app, projectId, allSerials, editDCID)
+			if err != nil {
+				log.Printf("dc_wizard: could not check serials: %v", err)
+				errors["serials_db"] = "Could not check serial numbers. Please try again."
+			}
 			for _, item := range reviewItems {
 				if len(item.Serials) > 0 {
 					result := services.ValidateSerials(item.Serials, item.TotalQty, existingSerials)
@@ -842,6 +893,17 @@ func HandleDCWizardStep4(app *pocketbase.PocketBase) func(*core.RequestEvent) er
 			}
 		}
 
+		// Quantities beyond the BOQ balance need an override reason
+		overDispatchReason := strings.TrimSpace(e.Request.FormValue("over_dispatch_reason"))
+		dispatchItems := make([]services.ShipmentItemParams, len(reviewItems))
+		for i, item := range reviewItems {
+			dispatchItems[i] = services.ShipmentItemParams{SourceItemType: item.SourceItemType, SourceItemID: item.SourceItemID, TotalQty: item.TotalQty}
+		}
+		overDispatch := checkWizardOverDispatch(app, projectId, editDCID, dcType, dispatchItems)
+		if len(overDispatch) > 0 && overDispatchReason == "" {
+			errors["over_dispatch"] = overDispatchError(overDispatch)
+		}
+
 		// Resolve addresses for display
 		billFrom := resolveAddressDisplay(app, billFromID, "Bill From")
 		dispatchFrom := resolveAddressDisplay(app, dispatchFromID, "Dispatch From")
@@ -894,6 +956,7 @@ func HandleDCWizardStep4(app *pocketbase.PocketBase) func(*core.RequestEvent) er
 
 		data := templates.DCWizardStep4Data{
 			ProjectID:       projectId,
+			EditDCID:        editDCID,
 			DCType:          dcType,
 			TemplateID:      templateID,
 			TemplateName:    templateName,
@@ -926,6 +989,10 @@ func HandleDCWizardStep4(app *pocketbase.PocketBase) func(*core.RequestEvent) er
 			QtyFormData:     qtyFormData,
 			SerialFormData:  serialFormData,
 			Errors:          errors,
+
+			OverDispatch:       overDispatchMessages(overDispatch),
+			OverDispatchReason: overDispatchReason,
+			ExpectedReturnDate: expectedReturnDate,
 		}
 
 		var component templ.Component
@@ -948,6 +1015,7 @@ func HandleDCWizardBackToStep3(app *pocketbase.PocketBase) func(*core.RequestEve
 		}
 
 		projectId := e.Request.PathValue("projectId")
+		editDCID := e.Request.FormValue("edit_dc_id")
 
 		dcType := e.Request.FormValue("dc_type")
 		templateID := e.Request.FormValue("template_id")
@@ -956,6 +1024,7 @@ func HandleDCWizardBackToStep3(app *pocketbase.PocketBase) func(*core.RequestEve
 		vehicleID := e.Request.FormValue("vehicle_id")
 		ewayBillNumber := e.Request.FormValue("eway_bill_number")
 		docketNumber := e.Request.FormValue("docket_number")
+		expectedReturnDate := e.Request.FormValue("expected_return_date")
 		reverseCharge := e.Request.FormValue("reverse_charge") == "on"
 		billFromID := e.Request.FormValue("bill_from_id")
 		dispatchFromID := e.Request.FormValue("dispatch_from_id")
@@ -1000,6 +1069,7 @@ func HandleDCWizardBackToStep3(app *pocketbase.PocketBase) func(*core.RequestEve
 
 		data := templates.DCWizardStep3Data{
 			ProjectID:      projectId,
+			EditDCID:       editDCID,
 			DCType:         dcType,
 			TemplateID:     templateID,
 			TemplateName:   templateName,
@@ -1018,6 +1088,14 @@ func HandleDCWizardBackToStep3(app *pocketbase.PocketBase) func(*core.RequestEve
 			Items:          wizardItems,
 			Destinations:   destinations,
 			Errors:         make(map[string]string),
+
+			ExpectedReturnDate: expectedReturnDate,
+		}
+
+		data.Balances = wizardDispatchBalances(app, projectId, editDCID, dcType, wizardItems)
+		data.OverDispatchReason = e.Request.FormValue("over_dispatch_reason")
+		if draft := loadEditDraft(app, projectId, editDCID); draft != nil {
+			data.Quantities, data.Serials = draftStep3Values(draft, wizardItems, shipToIDs)
 		}
 
 		var component templ.Component
@@ -1032,7 +1110,8 @@ func HandleDCWizardBackToStep3(app *pocketbase.PocketBase) func(*core.RequestEve
 	}
 }
 
-// HandleDCCreate processes the final wizard submission and creates the DC records.
+// HandleDCCreate processes the final wizard submission and creates the DC records,
+// or updates the draft in place when the wizard was opened via HandleDCEdit.
 func HandleDCCreate(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
 	return func(e *core.RequestEvent) error {
 		if err := e.Request.ParseForm(); err != nil {
@@ -1040,6 +1119,7 @@ func HandleDCCreate(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
 		}
 
 		projectId := e.Request.PathValue("projectId")
+		editDCID := e.Request.FormValue("edit_dc_id")
 		dcType := e.Request.FormValue("dc_type")
 		numDestStr := e.Request.FormValue("num_destinations")
 		numDest, _ := strconv.Atoi(numDestStr)
@@ -1054,6 +1134,48 @@ func HandleDCCreate(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
 
 		items := services.ParseShipmentItemsFromForm(e.Request.Form, numDest)
 
+		// Hard block on over-dispatch unless an override reason was given
+		overDispatchReason := strings.TrimSpace(e.Request.FormValue("over_dispatch_reason"))
+		if over := checkWizardOverDispatch(app, projectId, editDCID, dcType, items); len(over) == 0 {
+			overDispatchReason = ""
+		} else if overDispatchReason == "" {
+			return ErrorToast(e, http.StatusBadRequest, "Quantities exceed the remaining BOQ balance; an override reason is required")
+		}
+
+		if dcType == "returnable" {
+			params := services.ReturnableDCParams{
+				ProjectID:          projectId,
+				TemplateID:         e.Request.FormValue("template_id"),
+				ChallanDate:        e.Request.FormValue("challan_date"),
+				ExpectedReturnDate: e.Request.FormValue("expected_return_date"),
+				TransporterID:      e.Request.FormValue("transporter_id"),
+				VehicleID:          e.Request.FormValue("vehicle_id"),
+				EwayBillNumber:     e.Request.FormValue("eway_bill_number"),
+				DocketNumber:       e.Request.FormValue("docket_number"),
+				BillFromID:         e.Request.FormValue("bill_from_id"),
+				DispatchFromID:     e.Request.FormValue("dispatch_from_id"),
+				BillToID:           e.Request.FormValue("bill_to_id"),
+				ShipToID:           shipToIDs[0],
+				Items:              items,
+			}
+
+			var dc *core.Record
+			var err error
+			if editDCID != "" {
+				dc, err = services.UpdateReturnableDC(e.Request.Context(), app, editDCID, params)
+				if err != nil {
+					return ErrorToast(e, http.StatusInternalServerError, "Failed to update returnable DC: "+err.Error())
+				}
+				SetToast(e, "success", "Draft DC "+dc.GetString("dc_number")+" updated")
+			} else {
+				dc, err = services.CreateReturnableDC(e.Request.Context(), app, params)
+				if err != nil {
+					return ErrorToast(e, http.StatusInternalServerError, "Failed to create returnable DC: "+err.Error())
+				}
+			}
+			return redirectToDCDetail(e, projectId, dc.Id)
+		}
+
 		if dcType == "transfer" {
 			params := services.TransferDCParams{
 				ProjectID:      projectId,
@@ -1071,11 +1193,23 @@ func HandleDCCreate(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
 				HubAddressID:   e.Request.FormValue("hub_address_id"),
 				TaxType:        e.Request.FormValue("tax_type"),
 				Items:          items,
+
+				OverDispatchReason: overDispatchReason,
 			}
 
-			result, err := services.CreateTransferDC(app, params)
-			if err != nil {
-				return ErrorToast(e, http.StatusInternalServerError, "Failed to create transfer DC: "+err.Error())
+			var result *services.TransferDCResult
+			var err error
+			if editDCID != "" {
+				result, err = services.UpdateTransferDC(e.Request.Context(), app, editDCID, params)
+				if err != nil {
+					return ErrorToast(e, http.StatusInternalServerError, "Failed to update transfer DC: "+err.Error())
+				}
+				SetToast(e, "success", "Draft DC "+result.DCNumber+" updated")
+			} else {
+				result, err = services.CreateTransferDC(e.Request.Context(), app, params)
+				if err != nil {
+					return ErrorToast(e, http.StatusInternalServerError, "Failed to create transfer DC: "+err.Error())
+				}
 			}
 
 			// Redirect to DC detail
@@ -1103,11 +1237,23 @@ func HandleDCCreate(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
 			ShipToIDs:      shipToIDs,
 			TaxType:        e.Request.FormValue("tax_type"),
 			Items:          items,
+
+			OverDispatchReason: overDispatchReason,
 		}
 
-		result, err := services.CreateDirectShipment(app, params)
-		if err != nil {
-			return ErrorToast(e, http.StatusInternalServerError, "Failed to create shipment: "+err.Error())
+		var result *services.ShipmentResult
+		var err error
+		if editDCID != "" {
+			result, err = services.UpdateDirectShipment(e.Request.Context(), app, editDCID, params)
+			if err != nil {
+				return ErrorToast(e, http.StatusInternalServerError, "Failed to update shipment: "+err.Error())
+			}
+			SetToast(e, "success", "Draft DC "+result.TransitDCNumber+" updated")
+		} else {
+			result, err = services.CreateDirectShipment(e.Request.Context(), app, params)
+			if err != nil {
+				return ErrorToast(e, http.StatusInternalServerError, "Failed to create shipment: "+err.Error())
+			}
 		}
 
 		// Redirect to transit DC detail (primary view for shipment group)
@@ -1167,30 +1313,13 @@ func parseFloat(s string) float64 {
 	return f
 }
 
-// getExistingSerials returns a map of serial_number -> dc_number for all serials in a project.
+// getExistingSerials returns a map of serial_number -> dc_number for all serials
+// in a project that are still in use (i.e. not on a cancelled DC).
 func getExistingSerials(app *pocketbase.PocketBase, projectId string) map[string]string {
-	serials, err := app.FindRecordsByFilter("serial_numbers", "project = {:pid}", "", 0, 0, map[string]any{"pid": projectId})
+	serials, err := services.ProjectSerialsInUse(app, projectId, "")
 	if err != nil {
+		log.Printf("dc_wizard: %v", err)
 		return nil
 	}
-
-	result := make(map[string]string)
-	for _, s := range serials {
-		serial := s.GetString("serial_number")
-		lineItemID := s.GetString("line_item")
-		// Resolve DC number via line_item → dc → dc_number
-		lineItem, err := app.FindRecordById("dc_line_items", lineItemID)
-		if err != nil {
-			result[serial] = "unknown"
-			continue
-		}
-		dcID := lineItem.GetString("dc")
-		dc, err := app.FindRecordById("delivery_challans", dcID)
-		if err != nil {
-			result[serial] = "unknown"
-			continue
-		}
-		result[serial] = dc.GetString("dc_number")
-	}
-	return result
+	return serials
 }
@@ -222,3 +222,37 @@ func TestHandleDCDelete_DraftSuccess(t *testing.T) {
 		t.Error("expected draft DC to be deleted, but it still exists")
 	}
 }
+
+func TestDetermineTaxType(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Tax Project")
+	billFrom := testhelpers.CreateTestAddress(t, app, project.Id, "bill_from", "Mumbai Office")
+	mumbai := testhelpers.CreateTestAddress(t, app, project.Id, "ship_to", "Mumbai Site")
+	bengaluru := testhelpers.CreateTestAddress(t, app, project.Id, "ship_to", "Bengaluru Site")
+	bengaluru.Set("state", "")
+	bengaluru.Set("gstin", "29AADCB2230M1ZV")
+	if err := app.Save(bengaluru); err != nil {
+		t.Fatalf("failed to save address: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		billFrom string
+		shipTo   []string
+		want     string
+	}{
+		{"same state", billFrom.Id, []string{mumbai.Id}, "cgst_sgst"},
+		{"one destination out of state", billFrom.Id, []string{mumbai.Id, bengaluru.Id}, "igst"},
+		{"no bill-from address", "", []string{mumbai.Id}, "cgst_sgst"},
+		{"unreadable bill-from address", "missingaddress1", []string{bengaluru.Id}, "cgst_sgst"},
+		{"no destinations", billFrom.Id, nil, "cgst_sgst"},
+		{"unreadable destination skipped", billFrom.Id, []string{"missingaddress1", mumbai.Id}, "cgst_sgst"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := determineTaxType(app, tt.billFrom, tt.shipTo); got != tt.want {
+				t.Errorf("determineTaxType() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
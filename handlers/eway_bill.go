@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/services"
+	"projectcreation/templates"
+)
+
+func renderEwayBill(e *core.RequestEvent, data templates.EwayBillData) error {
+	var component templ.Component
+	if e.Request.Header.Get("HX-Request") == "true" {
+		component = templates.EwayBillContent(data)
+	} else {
+		headerData := GetHeaderData(e.Request)
+		sidebarData := GetSidebarData(e.Request)
+		component = templates.EwayBillPage(data, headerData, sidebarData)
+	}
+	return component.Render(e.Request.Context(), e.Response)
+}
+
+// ewayBillRow converts an e-way bill for the check page.
+func ewayBillRow(b services.EwayBill) templates.EwayBillRow {
+	row := templates.EwayBillRow{
+		DCID:     b.DCID,
+		DCNumber: b.DocNo,
+		DocDate:  b.DocDate,
+		ShipTo:   b.ToTrdName,
+		Value:    services.FormatINR(b.TotInvValue),
+		Vehicle:  b.VehicleNo,
+	}
+	if b.ToPlace != "" {
+		row.ShipTo += ", " + b.ToPlace
+	}
+	return row
+}
+
+// fillEwayBillData adds the bills, skipped DCs and problems of a batch to
+// the check page.
+func fillEwayBillData(data *templates.EwayBillData, batch *services.EwayBillBatch) {
+	for _, b := range batch.Bills {
+		data.Bills = append(data.Bills, ewayBillRow(b))
+	}
+	for _, b := range batch.Skipped {
+		data.Skipped = append(data.Skipped, ewayBillRow(b))
+	}
+	for _, p := range batch.Problems {
+		data.Problems = append(data.Problems, templates.EwayBillProblemRow{DCID: p.DCID, DCNumber: p.DCNumber, Message: p.Message})
+	}
+}
+
+// writeEwayBillJSON sends the bulk-upload JSON of a batch as a download, or
+// a 400 if any DC has problems or none needs an e-way bill.
+func writeEwayBillJSON(e *core.RequestEvent, batch *services.EwayBillBatch, name string) error {
+	if len(batch.Problems) > 0 {
+		p := batch.Problems[0]
+		return e.String(http.StatusBadRequest, fmt.Sprintf("%s: %s", p.DCNumber, p.Message))
+	}
+	if len(batch.Bills) == 0 {
+		return e.String(http.StatusBadRequest, "No DC needs an e-way bill")
+	}
+	jsonBytes, err := batch.JSON()
+	if err != nil {
+		log.Printf("eway_bill: failed to generate JSON: %v", err)
+		return e.String(http.StatusInternalServerError, "Failed to generate e-way bill JSON")
+	}
+
+	filename := fmt.Sprintf("EWB_%s_%s.json", sanitizeDCFilename(name), time.Now().Format("2006-01-02"))
+	e.Response.Header().Set("Content-Type", "application/json")
+	e.Response.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	e.Response.Write(jsonBytes)
+	return nil
+}
+
+// findProjectShipmentGroup loads the shipment group in the request path,
+// checking it belongs to the project in the path.
+func findProjectShipmentGroup(app *pocketbase.PocketBase, e *core.RequestEvent) (*core.Record, bool) {
+	sg, err := app.FindRecordById("shipment_groups", e.Request.PathValue("id"))
+	if err != nil || sg.GetString("project") != e.Request.PathValue("projectId") {
+		return nil, false
+	}
+	return sg, true
+}
+
+// shipmentGroupEwayBills prepares the e-way bills of a shipment group's
+// official DCs.
+func shipmentGroupEwayBills(app *pocketbase.PocketBase, sg *core.Record) (*services.EwayBillBatch, error) {
+	dcs, err := services.ShipmentGroupEwayBillDCs(app, sg.Id)
+	if err != nil {
+		return nil, err
+	}
+	return services.PrepareEwayBills(app, dcs), nil
+}
+
+// HandleDCEwayBill checks a DC against the e-way bill portal's rules and
+// offers its bulk-upload JSON.
+func HandleDCEwayBill(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		dc, ok := findProjectDC(app, e)
+		if !ok {
+			return ErrorToast(e, http.StatusNotFound, "Delivery challan not found")
+		}
+
+		projectId := dc.GetString("project")
+		data := templates.EwayBillData{
+			ProjectID: projectId,
+			Title:     dc.GetString("dc_number"),
+			BackURL:   fmt.Sprintf("/projects/%s/dcs/%s", projectId, dc.Id),
+			BackLabel: dc.GetString("dc_number"),
+			JSONURL:   fmt.Sprintf("/projects/%s/dcs/%s/eway-bill/json", projectId, dc.Id),
+		}
+		fillEwayBillData(&data, services.PrepareEwayBills(app, []*core.Record{dc}))
+		return renderEwayBill(e, data)
+	}
+}
+
+// HandleDCEwayBillJSON downloads the e-way bill bulk-upload JSON of a DC.
+func HandleDCEwayBillJSON(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		dc, ok := findProjectDC(app, e)
+		if !ok {
+			return e.String(http.StatusNotFound, "DC not found")
+		}
+		batch := services.PrepareEwayBills(app, []*core.Record{dc})
+		return writeEwayBillJSON(e, batch, dc.GetString("dc_number"))
+	}
+}
+
+// HandleShipmentGroupEwayBill checks the official DCs of a shipment group
+// against the e-way bill portal's rules and offers their bulk-upload JSON.
+func HandleShipmentGroupEwayBill(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		sg, ok := findProjectShipmentGroup(app, e)
+		if !ok {
+			return ErrorToast(e, http.StatusNotFound, "Shipment group not found")
+		}
+		batch, err := shipmentGroupEwayBills(app, sg)
+		if err != nil {
+			log.Printf("eway_bill: could not prepare shipment group %s: %v", sg.Id, err)
+			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
+		}
+
+		projectId := sg.GetString("project")
+		data := templates.EwayBillData{
+			ProjectID: projectId,
+			Title:     "Shipment Group",
+			BackURL:   fmt.Sprintf("/projects/%s/shipment-groups/%s", projectId, sg.Id),
+			BackLabel: "SHIPMENT GROUP",
+			JSONURL:   fmt.Sprintf("/projects/%s/shipment-groups/%s/eway-bill/json", projectId, sg.Id),
+		}
+		fillEwayBillData(&data, batch)
+		return renderEwayBill(e, data)
+	}
+}
+
+// HandleShipmentGroupEwayBillJSON downloads one e-way bill bulk-upload JSON
+// for all the official DCs of a shipment group that need one.
+func HandleShipmentGroupEwayBillJSON(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		sg, ok := findProjectShipmentGroup(app, e)
+		if !ok {
+			return e.String(http.StatusNotFound, "Shipment group not found")
+		}
+		batch, err := shipmentGroupEwayBills(app, sg)
+		if err != nil {
+			log.Printf("eway_bill: could not prepare shipment group %s: %v", sg.Id, err)
+			return e.String(http.StatusInternalServerError, "Failed to generate e-way bill JSON")
+		}
+
+		name := "Shipment-" + sg.Id
+		transit, _ := app.FindRecordsByFilter("delivery_challans", "shipment_group = {:sgId} && dc_type = 'transit'", "", 1, 0, map[string]any{"sgId": sg.Id})
+		if len(transit) > 0 {
+			name = transit[0].GetString("dc_number")
+		}
+		return writeEwayBillJSON(e, batch, name)
+	}
+}
+
+// HandleEwayBillImportPage renders the upload form for the e-way bill
+// numbers returned by the portal.
+// Route: GET /projects/{projectId}/eway-bills/import
+func HandleEwayBillImportPage(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		projectId := e.Request.PathValue("projectId")
+		if _, err := app.FindRecordById("projects", projectId); err != nil {
+			return ErrorToast(e, http.StatusNotFound, "Project not found")
+		}
+
+		if e.Request.Header.Get("HX-Request") == "true" {
+			return templates.EwayBillImportContent(projectId).Render(e.Request.Context(), e.Response)
+		}
+		headerData := GetHeaderData(e.Request)
+		sidebarData := GetSidebarData(e.Request)
+		return templates.EwayBillImportPage(projectId, headerData, sidebarData).Render(e.Request.Context(), e.Response)
+	}
+}
+
+// HandleEwayBillImport saves the e-way bill numbers in an uploaded results
+// file against their DCs, returning the outcome as an HTMX partial.
+// Route: POST /projects/{projectId}/eway-bills/import
+func HandleEwayBillImport(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		projectId := e.Request.PathValue("projectId")
+		if _, err := app.FindRecordById("projects", projectId); err != nil {
+			return ErrorToast(e, http.StatusNotFound, "Project not found")
+		}
+
+		// Parse multipart form (max 10MB)
+		if err := e.Request.ParseMultipartForm(10 << 20); err != nil {
+			return ErrorToast(e, http.StatusBadRequest, "File too large or invalid form data")
+		}
+		file, header, err := e.Request.FormFile("file")
+		if err != nil {
+			return ErrorToast(e, http.StatusBadRequest, "Please select a file to upload")
+		}
+		defer file.Close()
+
+		result, err := services.ImportEwayBillNumbers(e.Request.Context(), app, projectId, file, header.Filename)
+		if err != nil {
+			log.Printf("eway_bill_import: %v", err)
+			return ErrorToast(e, http.StatusBadRequest, err.Error())
+		}
+
+		if len(result.Errors) == 0 {
+			SetToast(e, "success", fmt.Sprintf("%d e-way bill numbers imported", len(result.Updated)))
+		}
+		return templates.EwayBillImportResults(projectId, result).Render(e.Request.Context(), e.Response)
+	}
+}
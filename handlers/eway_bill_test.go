@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/testhelpers"
+)
+
+func getEwayBill(t *testing.T, app *pocketbase.PocketBase, handler func(*pocketbase.PocketBase) func(*core.RequestEvent) error, dc *core.Record, suffix string) *httptest.ResponseRecorder {
+	t.Helper()
+	projectID := dc.GetString("project")
+	req := httptest.NewRequest(http.MethodGet, "/projects/"+projectID+"/dcs/"+dc.Id+"/eway-bill"+suffix, nil)
+	req.SetPathValue("projectId", projectID)
+	req.SetPathValue("id", dc.Id)
+	req.Header.Set("HX-Request", "true")
+	rec := httptest.NewRecorder()
+	if err := handler(app)(newTestRequestEvent(app, req, rec)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	return rec
+}
+
+func TestHandleDCEwayBill(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "E-way Bill Project")
+	dc := createInvoiceableDC(t, app, project.Id, "ODC-001")
+
+	// 60 floodlights at ₹1,000 + 18% to a registered client
+	lines, _ := app.FindRecordsByFilter("dc_line_items", "dc = {:did}", "", 0, 0, map[string]any{"did": dc.Id})
+	lines[0].Set("quantity", 60)
+	if err := app.Save(lines[0]); err != nil {
+		t.Fatalf("failed to save line: %v", err)
+	}
+	for addressID, gstin := range map[string]string{
+		dc.GetString("bill_from_address"): "27AAACF1234A1Z5",
+		dc.GetString("bill_to_address"):   "27AABCC5678D1Z2",
+	} {
+		addr, _ := app.FindRecordById("addresses", addressID)
+		addr.Set("gstin", gstin)
+		if err := app.Save(addr); err != nil {
+			t.Fatalf("failed to save GSTIN: %v", err)
+		}
+	}
+
+	// Without a vehicle or transporter the JSON is held back
+	rec := getEwayBill(t, app, HandleDCEwayBill, dc, "")
+	testhelpers.AssertHTMLContains(t, rec.Body.String(), "ODC-001", "no vehicle number or transporter")
+	if strings.Contains(rec.Body.String(), "DOWNLOAD JSON") {
+		t.Error("expected no download while the DC has problems")
+	}
+	if rec = getEwayBill(t, app, HandleDCEwayBillJSON, dc, "/json"); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+
+	transit, _ := app.FindCollectionByNameOrId("dc_transit_details")
+	detail := core.NewRecord(transit)
+	detail.Set("dc", dc.Id)
+	detail.Set("vehicle_number", "MH12AB1234")
+	if err := app.Save(detail); err != nil {
+		t.Fatalf("failed to save transit details: %v", err)
+	}
+
+	rec = getEwayBill(t, app, HandleDCEwayBill, dc, "")
+	testhelpers.AssertHTMLContains(t, rec.Body.String(), "DOWNLOAD JSON", "70,800")
+
+	rec = getEwayBill(t, app, HandleDCEwayBillJSON, dc, "/json")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Header().Get("Content-Disposition"), "EWB_ODC-001_") {
+		t.Fatalf("expected the JSON download, got %d %q", rec.Code, rec.Header().Get("Content-Disposition"))
+	}
+	testhelpers.AssertHTMLContains(t, rec.Body.String(), `"docNo": "ODC-001"`, `"toGstin": "27AABCC5678D1Z2"`, `"cgstValue": 5400`)
+}
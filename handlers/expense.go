@@ -0,0 +1,340 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+
+	"projectcreation/collections"
+	"projectcreation/services"
+	"projectcreation/templates"
+)
+
+// expenseCategoryOptions returns the active cost categories for the expense
+// form.
+func expenseCategoryOptions(app *pocketbase.PocketBase) []templates.ExpenseCategoryOption {
+	categories, err := collections.GetCostCategories(app)
+	if err != nil {
+		log.Printf("expense: could not load cost categories: %v", err)
+		return nil
+	}
+	var options []templates.ExpenseCategoryOption
+	for _, c := range categories {
+		if c.GetBool("active") {
+			options = append(options, templates.ExpenseCategoryOption{ID: c.Id, Name: c.GetString("name")})
+		}
+	}
+	return options
+}
+
+// renderExpenseCreate renders the expense form as a partial or full page.
+func renderExpenseCreate(e *core.RequestEvent, data templates.ExpenseCreateData) error {
+	var component templ.Component
+	if e.Request.Header.Get("HX-Request") == "true" {
+		component = templates.ExpenseCreateContent(data)
+	} else {
+		headerData := GetHeaderData(e.Request)
+		sidebarData := GetSidebarData(e.Request)
+		component = templates.ExpenseCreatePage(data, headerData, sidebarData)
+	}
+	return component.Render(e.Request.Context(), e.Response)
+}
+
+// redirectToExpenses sends the browser back to the project's expense list.
+func redirectToExpenses(e *core.RequestEvent, projectId string) error {
+	redirectURL := fmt.Sprintf("/projects/%s/expenses", projectId)
+	if e.Request.Header.Get("HX-Request") == "true" {
+		e.Response.Header().Set("HX-Redirect", redirectURL)
+		return e.String(http.StatusOK, "")
+	}
+	return e.Redirect(http.StatusFound, redirectURL)
+}
+
+// findProjectExpense loads an expense from the path, checking it belongs to
+// the project in the path.
+func findProjectExpense(app *pocketbase.PocketBase, e *core.RequestEvent) (*core.Record, bool) {
+	expense, err := app.FindRecordById("expenses", e.Request.PathValue("id"))
+	if err != nil || expense.GetString("project") != e.Request.PathValue("projectId") {
+		return nil, false
+	}
+	return expense, true
+}
+
+// uploadedReceipts returns the receipts posted with a form. No receipt,
+// or a form that is not multipart, is not an error.
+func uploadedReceipts(e *core.RequestEvent) ([]*filesystem.File, error) {
+	files, err := e.FindUploadedFiles("receipts")
+	if errors.Is(err, http.ErrMissingFile) || errors.Is(err, http.ErrNotMultipart) {
+		return nil, nil
+	}
+	return files, err
+}
+
+// HandleExpenseList renders the project's site expenses, optionally filtered
+// by approval status.
+func HandleExpenseList(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		project, err := app.FindRecordById("projects", e.Request.PathValue("projectId"))
+		if err != nil {
+			return ErrorToast(e, http.StatusNotFound, "Project not found")
+		}
+
+		status := e.Request.URL.Query().Get("status")
+		if !slices.Contains(services.ExpenseStatuses, status) {
+			status = ""
+		}
+
+		records, err := app.FindRecordsByFilter("expenses", "project = {:projectId}", "-expense_date,-created", 0, 0, map[string]any{"projectId": project.Id})
+		if err != nil {
+			log.Printf("expense: could not load expenses for project %s: %v", project.Id, err)
+			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
+		}
+		if errs := app.ExpandRecords(records, []string{"cost_category"}, nil); len(errs) > 0 {
+			log.Printf("expense: could not expand cost categories: %v", errs)
+		}
+
+		data := templates.ExpenseListData{ProjectID: project.Id}
+		counts := make(map[string]int)
+		var approvedTotal, pendingTotal float64
+		for _, r := range records {
+			rStatus := r.GetString("status")
+			counts[rStatus]++
+			switch rStatus {
+			case services.ExpenseStatusApproved:
+				approvedTotal += r.GetFloat("amount")
+			case services.ExpenseStatusPending:
+				pendingTotal += r.GetFloat("amount")
+			}
+			if status != "" && rStatus != status {
+				continue
+			}
+
+			item := templates.ExpenseListItem{
+				ID:              r.Id,
+				ExpenseDate:     r.GetString("expense_date"),
+				Description:     r.GetString("description"),
+				BillNumber:      r.GetString("bill_number"),
+				PaidBy:          r.GetString("paid_by"),
+				Amount:          services.FormatINR(r.GetFloat("amount")),
+				GSTAmount:       services.FormatINR(r.GetFloat("gst_amount")),
+				Status:          rStatus,
+				StatusLabel:     services.ExpenseStatusLabel(rStatus),
+				ReviewedBy:      r.GetString("reviewed_by_name"),
+				RejectionReason: r.GetString("rejection_reason"),
+			}
+			if cat := r.ExpandedOne("cost_category"); cat != nil {
+				item.CategoryName = cat.GetString("name")
+			}
+			for _, name := range r.GetStringSlice("receipts") {
+				item.Receipts = append(item.Receipts, templates.ExpenseReceiptLink{
+					Name: name,
+					URL:  fmt.Sprintf("/projects/%s/expenses/%s/receipts/%s", project.Id, r.Id, name),
+				})
+			}
+			data.Expenses = append(data.Expenses, item)
+		}
+
+		data.ApprovedTotal = services.FormatINR(approvedTotal)
+		data.PendingTotal = services.FormatINR(pendingTotal)
+		data.PendingCount = counts[services.ExpenseStatusPending]
+		data.Tabs = append(data.Tabs, templates.ExpenseStatusTab{Label: "All", Count: len(records), Active: status == ""})
+		for _, s := range services.ExpenseStatuses {
+			data.Tabs = append(data.Tabs, templates.ExpenseStatusTab{
+				Value:  s,
+				Label:  services.ExpenseStatusLabel(s),
+				Count:  counts[s],
+				Active: status == s,
+			})
+		}
+
+		var component templ.Component
+		if e.Request.Header.Get("HX-Request") == "true" {
+			component = templates.ExpenseListContent(data)
+		} else {
+			headerData := GetHeaderData(e.Request)
+			sidebarData := GetSidebarData(e.Request)
+			component = templates.ExpenseListPage(data, headerData, sidebarData)
+		}
+		return component.Render(e.Request.Context(), e.Response)
+	}
+}
+
+// HandleExpenseCreate renders the form to record a site expense.
+func HandleExpenseCreate(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		project, err := app.FindRecordById("projects", e.Request.PathValue("projectId"))
+		if err != nil {
+			return ErrorToast(e, http.StatusNotFound, "Project not found")
+		}
+
+		return renderExpenseCreate(e, templates.ExpenseCreateData{
+			ProjectID:   project.Id,
+			Categories:  expenseCategoryOptions(app),
+			ExpenseDate: time.Now().Format("2006-01-02"),
+			Errors:      make(map[string]string),
+		})
+	}
+}
+
+// HandleExpenseSave records a site expense with any attached receipts,
+// pending approval.
+func HandleExpenseSave(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		if err := e.Request.ParseMultipartForm(32 << 20); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+			return ErrorToast(e, http.StatusBadRequest, "Invalid form data")
+		}
+
+		project, err := app.FindRecordById("projects", e.Request.PathValue("projectId"))
+		if err != nil {
+			return ErrorToast(e, http.StatusNotFound, "Project not found")
+		}
+
+		rawAmount := strings.TrimSpace(e.Request.FormValue("amount"))
+		rawGST := strings.TrimSpace(e.Request.FormValue("gst_amount"))
+		amount, _ := strconv.ParseFloat(rawAmount, 64)
+		gst, _ := strconv.ParseFloat(rawGST, 64)
+		params := services.ExpenseParams{
+			ProjectID:      project.Id,
+			ExpenseDate:    strings.TrimSpace(e.Request.FormValue("expense_date")),
+			CostCategoryID: e.Request.FormValue("cost_category"),
+			Description:    strings.TrimSpace(e.Request.FormValue("description")),
+			BillNumber:     strings.TrimSpace(e.Request.FormValue("bill_number")),
+			Amount:         amount,
+			GSTAmount:      gst,
+			PaidBy:         strings.TrimSpace(e.Request.FormValue("paid_by")),
+		}
+
+		if errors := services.ValidateExpense(app, params); len(errors) > 0 {
+			SetToast(e, "warning", "Please fix the errors below")
+			return renderExpenseCreate(e, templates.ExpenseCreateData{
+				ProjectID:      project.Id,
+				Categories:     expenseCategoryOptions(app),
+				ExpenseDate:    params.ExpenseDate,
+				CostCategoryID: params.CostCategoryID,
+				Description:    params.Description,
+				BillNumber:     params.BillNumber,
+				Amount:         rawAmount,
+				GSTAmount:      rawGST,
+				PaidBy:         params.PaidBy,
+				Errors:         errors,
+			})
+		}
+
+		receipts, err := uploadedReceipts(e)
+		if err != nil {
+			return ErrorToast(e, http.StatusBadRequest, "Could not read the attached receipts")
+		}
+
+		if _, err := services.CreateExpense(e.Request.Context(), app, params, receipts); err != nil {
+			log.Printf("expense: could not save expense for project %s: %v", project.Id, err)
+			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
+		}
+
+		SetToast(e, "success", fmt.Sprintf("Expense of %s recorded, pending approval", services.FormatINR(amount)))
+		return redirectToExpenses(e, project.Id)
+	}
+}
+
+// HandleExpenseReceiptsAdd attaches more receipts to an expense.
+func HandleExpenseReceiptsAdd(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		expense, ok := findProjectExpense(app, e)
+		if !ok {
+			return ErrorToast(e, http.StatusNotFound, "Expense not found")
+		}
+
+		receipts, err := uploadedReceipts(e)
+		if err != nil {
+			return ErrorToast(e, http.StatusBadRequest, "Could not read the attached receipts")
+		}
+		if len(receipts) == 0 {
+			return ErrorToast(e, http.StatusBadRequest, "Choose a receipt to attach")
+		}
+
+		if err := services.AddExpenseReceipts(e.Request.Context(), app, expense, receipts); err != nil {
+			log.Printf("expense: could not attach receipts to expense %s: %v", expense.Id, err)
+			return ErrorToast(e, http.StatusBadRequest, "Receipts must be JPEG, PNG or PDF files under 5 MB, at most 5 per expense")
+		}
+
+		SetToast(e, "success", "Receipt attached")
+		return redirectToExpenses(e, expense.GetString("project"))
+	}
+}
+
+// HandleExpenseReceipt serves a receipt attached to an expense.
+func HandleExpenseReceipt(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		expense, ok := findProjectExpense(app, e)
+		if !ok {
+			return ErrorToast(e, http.StatusNotFound, "Expense not found")
+		}
+
+		filename := e.Request.PathValue("filename")
+		if !slices.Contains(expense.GetStringSlice("receipts"), filename) {
+			return ErrorToast(e, http.StatusNotFound, "Receipt not found")
+		}
+
+		fs, err := app.NewFilesystem()
+		if err != nil {
+			log.Printf("expense: could not open filesystem: %v", err)
+			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
+		}
+		defer fs.Close()
+
+		if err := fs.Serve(e.Response, e.Request, expense.BaseFilesPath()+"/"+filename, filename); err != nil {
+			log.Printf("expense: could not serve receipt %s of expense %s: %v", filename, expense.Id, err)
+			return ErrorToast(e, http.StatusNotFound, "Receipt not found")
+		}
+		return nil
+	}
+}
+
+// reviewExpense approves or rejects the expense in the path.
+func reviewExpense(app *pocketbase.PocketBase, e *core.RequestEvent, approve bool) error {
+	expense, ok := findProjectExpense(app, e)
+	if !ok {
+		return ErrorToast(e, http.StatusNotFound, "Expense not found")
+	}
+
+	// hx-prompt sends the reason in a header; a plain form posts it
+	reason := e.Request.Header.Get("HX-Prompt")
+	if reason == "" {
+		reason = e.Request.FormValue("reason")
+	}
+
+	if err := services.ReviewExpense(e.Request.Context(), app, expense.Id, approve, reason); err != nil {
+		return ErrorToast(e, http.StatusBadRequest, err.Error())
+	}
+
+	if approve {
+		SetToast(e, "success", fmt.Sprintf("Expense of %s approved", services.FormatINR(expense.GetFloat("amount"))))
+	} else {
+		SetToast(e, "success", "Expense rejected")
+	}
+	return redirectToExpenses(e, expense.GetString("project"))
+}
+
+// HandleExpenseApprove approves a pending expense so it counts towards the
+// project's costs.
+func HandleExpenseApprove(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		return reviewExpense(app, e, true)
+	}
+}
+
+// HandleExpenseReject rejects a pending expense with a reason.
+func HandleExpenseReject(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		return reviewExpense(app, e, false)
+	}
+}
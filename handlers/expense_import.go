@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/collections"
+	"projectcreation/services"
+	"projectcreation/templates"
+)
+
+// HandleExpenseImportPage renders the expense upload form.
+// Route: GET /projects/{projectId}/expenses/import
+func HandleExpenseImportPage(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		project, err := app.FindRecordById("projects", e.Request.PathValue("projectId"))
+		if err != nil {
+			return ErrorToast(e, http.StatusNotFound, "Project not found")
+		}
+		// Seeds the default categories the import is matched against
+		if _, err := collections.GetCostCategories(app); err != nil {
+			log.Printf("expense_import: could not load cost categories: %v", err)
+		}
+
+		data := templates.ExpenseImportData{
+			ProjectID:   project.Id,
+			ProjectName: project.GetString("name"),
+		}
+
+		if e.Request.Header.Get("HX-Request") == "true" {
+			return templates.ExpenseImportContent(data).Render(e.Request.Context(), e.Response)
+		}
+
+		headerData := GetHeaderData(e.Request)
+		sidebarData := GetSidebarData(e.Request)
+		return templates.ExpenseImportPage(data, headerData, sidebarData).Render(e.Request.Context(), e.Response)
+	}
+}
+
+// HandleExpenseValidate receives an expense file, validates it, and returns
+// the validation results as an HTMX partial.
+// Route: POST /projects/{projectId}/expenses/import
+func HandleExpenseValidate(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		projectID := e.Request.PathValue("projectId")
+		if _, err := app.FindRecordById("projects", projectID); err != nil {
+			return ErrorToast(e, http.StatusNotFound, "Project not found")
+		}
+
+		// Parse multipart form (max 10MB)
+		if err := e.Request.ParseMultipartForm(10 << 20); err != nil {
+			return ErrorToast(e, http.StatusBadRequest, "File too large or invalid form data")
+		}
+
+		file, header, err := e.Request.FormFile("file")
+		if err != nil {
+			return ErrorToast(e, http.StatusBadRequest, "Please select a file to upload")
+		}
+		defer file.Close()
+
+		result, err := services.ValidateExpenseFile(app, file, header.Filename, projectID)
+		if err != nil {
+			log.Printf("expense_validate: %v", err)
+			return ErrorToast(e, http.StatusBadRequest, err.Error())
+		}
+
+		// Serialize parsed rows for the commit form
+		var parsedRowsJSON string
+		if result.ErrorRows == 0 {
+			b, err := json.Marshal(result.ParsedRows)
+			if err != nil {
+				log.Printf("expense_validate: marshal parsed rows: %v", err)
+			} else {
+				parsedRowsJSON = string(b)
+			}
+		}
+
+		return templates.ExpenseValidationResults(projectID, result, parsedRowsJSON).Render(e.Request.Context(), e.Response)
+	}
+}
+
+// HandleExpenseErrorReport downloads the expense validation errors as an
+// Excel file.
+// Route: POST /projects/{projectId}/expenses/import/errors
+func HandleExpenseErrorReport(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		var errors []services.ValidationError
+		if err := json.NewDecoder(e.Request.Body).Decode(&errors); err != nil {
+			return ErrorToast(e, http.StatusBadRequest, "Invalid error data")
+		}
+
+		xlsxBytes, err := services.GenerateErrorReport(errors)
+		if err != nil {
+			log.Printf("expense_error_report: %v", err)
+			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
+		}
+
+		filename := fmt.Sprintf("Expense_Errors_%s.xlsx", time.Now().Format("2006-01-02"))
+		e.Response.Header().Set("Content-Type",
+			"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		e.Response.Header().Set("Content-Disposition",
+			fmt.Sprintf(`attachment; filename="%s"`, filename))
+		e.Response.Write(xlsxBytes)
+		return nil
+	}
+}
+
+// HandleExpenseImportCommit re-validates and saves the uploaded expenses,
+// all pending approval.
+// Route: POST /projects/{projectId}/expenses/import/commit
+func HandleExpenseImportCommit(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		projectID := e.Request.PathValue("projectId")
+		if _, err := app.FindRecordById("projects", projectID); err != nil {
+			return ErrorToast(e, http.StatusNotFound, "Project not found")
+		}
+
+		if err := e.Request.ParseForm(); err != nil {
+			return ErrorToast(e, http.StatusBadRequest, "Invalid form data")
+		}
+
+		parsedJSON := e.Request.FormValue("parsed_rows_json")
+		if parsedJSON == "" {
+			return ErrorToast(e, http.StatusBadRequest,
+				"File data missing. Please re-upload and try again.")
+		}
+
+		var parsedRows []map[string]string
+		if err := json.Unmarshal([]byte(parsedJSON), &parsedRows); err != nil {
+			return ErrorToast(e, http.StatusBadRequest, "Invalid parsed data")
+		}
+
+		importResult, err := services.CommitExpenseImport(e.Request.Context(), app, projectID, parsedRows)
+		if err != nil {
+			log.Printf("expense_import_commit: %v", err)
+			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
+		}
+
+		if importResult.Failed > 0 {
+			return templates.ExpenseImportFailure(projectID, importResult).Render(e.Request.Context(), e.Response)
+		}
+
+		SetToast(e, "success", fmt.Sprintf("%d expenses imported, pending approval", importResult.Imported))
+		return templates.ExpenseImportSuccess(projectID, importResult.Imported).Render(e.Request.Context(), e.Response)
+	}
+}
+
+// HandleExpenseImportTemplate serves the Excel template for the expense
+// import.
+// Route: GET /projects/{projectId}/expenses/import/template
+func HandleExpenseImportTemplate(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		if _, err := app.FindRecordById("projects", e.Request.PathValue("projectId")); err != nil {
+			return e.String(http.StatusNotFound, "Project not found")
+		}
+		if _, err := collections.GetCostCategories(app); err != nil {
+			log.Printf("expense_template: could not load cost categories: %v", err)
+		}
+
+		xlsxBytes, err := services.GenerateExpenseTemplate(app)
+		if err != nil {
+			log.Printf("expense_template: failed to generate: %v", err)
+			return e.String(http.StatusInternalServerError, "Failed to generate template")
+		}
+
+		filename := fmt.Sprintf("Expense_Template_%d.xlsx", time.Now().Year())
+		e.Response.Header().Set("Content-Type",
+			"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		e.Response.Header().Set("Content-Disposition",
+			fmt.Sprintf(`attachment; filename="%s"`, filename))
+		e.Response.Write(xlsxBytes)
+		return nil
+	}
+}
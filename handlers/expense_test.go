@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"projectcreation/collections"
+	"projectcreation/testhelpers"
+)
+
+func TestHandleExpenseSave_RecordsWithReceiptAndApproves(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Expense Project")
+	categories, err := collections.GetCostCategories(app)
+	if err != nil {
+		t.Fatalf("GetCostCategories failed: %v", err)
+	}
+	pathValues := map[string]string{"projectId": project.Id}
+
+	// Missing fields are rejected and nothing is saved
+	rec := postHXForm(t, app, HandleExpenseSave, "/projects/"+project.Id+"/expenses", pathValues, url.Values{"expense_date": {"2025-06-10"}})
+	testhelpers.AssertHTMLContains(t, rec.Body.String(), "Choose a cost category", "Amount must be greater than zero")
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for k, v := range map[string]string{
+		"expense_date":  "2025-06-10",
+		"cost_category": categories[0].Id,
+		"description":   "Crane hire",
+		"amount":        "12500",
+		"gst_amount":    "2250",
+		"paid_by":       "Ravi",
+	} {
+		mw.WriteField(k, v)
+	}
+	fw, _ := mw.CreateFormFile("receipts", "bill.pdf")
+	fw.Write([]byte("%PDF-1.4\n%receipt\n"))
+	mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/projects/"+project.Id+"/expenses", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	req.Header.Set("HX-Request", "true")
+	req.SetPathValue("projectId", project.Id)
+	rec = httptest.NewRecorder()
+	if err := HandleExpenseSave(app)(newTestRequestEvent(app, req, rec)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	testhelpers.AssertHXRedirect(t, rec.Header().Get("HX-Redirect"), "/projects/"+project.Id+"/expenses")
+
+	expense, err := app.FindFirstRecordByData("expenses", "project", project.Id)
+	if err != nil {
+		t.Fatalf("expected the expense to be saved: %v", err)
+	}
+	receipts := expense.GetStringSlice("receipts")
+	if expense.GetString("status") != "pending" || len(receipts) != 1 {
+		t.Fatalf("expected a pending expense with 1 receipt, got %s with %v", expense.GetString("status"), receipts)
+	}
+
+	// The receipt is served through the project's expense route
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.SetPathValue("projectId", project.Id)
+	req.SetPathValue("id", expense.Id)
+	req.SetPathValue("filename", receipts[0])
+	rec = httptest.NewRecorder()
+	if err := HandleExpenseReceipt(app)(newTestRequestEvent(app, req, rec)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	if !bytes.Contains(rec.Body.Bytes(), []byte("%receipt")) {
+		t.Errorf("expected the receipt contents, got %q", rec.Body.String())
+	}
+
+	expensePath := map[string]string{"projectId": project.Id, "id": expense.Id}
+	rec = postHXForm(t, app, HandleExpenseApprove, "/projects/"+project.Id+"/expenses/"+expense.Id+"/approve", expensePath, url.Values{})
+	testhelpers.AssertHXRedirect(t, rec.Header().Get("HX-Redirect"), "/projects/"+project.Id+"/expenses")
+
+	req = httptest.NewRequest(http.MethodGet, "/projects/"+project.Id+"/expenses?status=approved", nil)
+	req.SetPathValue("projectId", project.Id)
+	req.Header.Set("HX-Request", "true")
+	rec = httptest.NewRecorder()
+	if err := HandleExpenseList(app)(newTestRequestEvent(app, req, rec)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	testhelpers.AssertHTMLContains(t, rec.Body.String(), "Crane hire", "Approved", receipts[0])
+}
@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/services"
+	"projectcreation/templates"
+)
+
+// fetchReceivablePOs returns the project's purchase orders that goods can be
+// received against.
+func fetchReceivablePOs(app *pocketbase.PocketBase, projectId string) []templates.GRNPOOption {
+	records, err := app.FindRecordsByFilter(
+		"purchase_orders",
+		"project = {:projectId} && (status = 'sent' || status = 'acknowledged')",
+		"-created",
+		0,
+		0,
+		map[string]any{"projectId": projectId},
+	)
+	if err != nil {
+		log.Printf("grn_create: could not query purchase_orders: %v", err)
+		return nil
+	}
+
+	var options []templates.GRNPOOption
+	for _, po := range records {
+		vendorName := ""
+		if v, err := app.FindRecordById("vendors", po.GetString("vendor")); err == nil {
+			vendorName = v.GetString("name")
+		}
+		options = append(options, templates.GRNPOOption{
+			ID:         po.Id,
+			PONumber:   po.GetString("po_number"),
+			VendorName: vendorName,
+		})
+	}
+	return options
+}
+
+// findReceivablePO returns the purchase order if it belongs to the project
+// and goods can be received against it.
+func findReceivablePO(app *pocketbase.PocketBase, projectId, poID string) (*core.Record, error) {
+	po, err := app.FindRecordById("purchase_orders", poID)
+	if err != nil {
+		return nil, err
+	}
+	if po.GetString("project") != projectId {
+		return nil, fmt.Errorf("purchase order %s does not belong to project %s", poID, projectId)
+	}
+	if !slices.Contains(services.GRNReceivableStatuses, po.GetString("status")) {
+		return nil, fmt.Errorf("purchase order %s is %s", poID, po.GetString("status"))
+	}
+	return po, nil
+}
+
+// grnCreateLines builds the form rows for every PO line with quantity still
+// pending. entered holds quantities already typed in, keyed by PO line item ID.
+func grnCreateLines(receipt []services.POReceiptLine, entered map[string]services.GRNLineParams) []templates.GRNCreateLine {
+	var lines []templates.GRNCreateLine
+	for _, r := range receipt {
+		if r.Pending() <= 0 {
+			continue
+		}
+		line := templates.GRNCreateLine{
+			POLineItemID: r.POLineItemID,
+			Description:  r.Description,
+			UOM:          r.UOM,
+			Ordered:      formatQty(r.Ordered),
+			Accepted:     formatQty(r.Accepted),
+			Pending:      formatQty(r.Pending()),
+		}
+		if p, ok := entered[r.POLineItemID]; ok {
+			if p.QtyReceived != 0 {
+				line.QtyReceived = formatQty(p.QtyReceived)
+			}
+			if p.QtyRejected != 0 {
+				line.QtyRejected = formatQty(p.QtyRejected)
+			}
+			line.RejectionReason = p.RejectionReason
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// renderGRNCreate renders the GRN form as a partial or full page.
+func renderGRNCreate(e *core.RequestEvent, data templates.GRNCreateData) error {
+	var component templ.Component
+	if e.Request.Header.Get("HX-Request") == "true" {
+		component = templates.GRNCreateContent(data)
+	} else {
+		headerData := GetHeaderData(e.Request)
+		sidebarData := GetSidebarData(e.Request)
+		component = templates.GRNCreatePage(data, headerData, sidebarData)
+	}
+	return component.Render(e.Request.Context(), e.Response)
+}
+
+// HandleGRNCreate renders the goods receipt form. The purchase order is
+// chosen with ?po=; its pending line items are listed for receipt.
+func HandleGRNCreate(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		projectId := e.Request.PathValue("projectId")
+
+		if _, err := app.FindRecordById("projects", projectId); err != nil {
+			return ErrorToast(e, http.StatusNotFound, "Project not found")
+		}
+
+		data := templates.GRNCreateData{
+			ProjectID:    projectId,
+			POs:          fetchReceivablePOs(app, projectId),
+			ReceivedDate: time.Now().Format("2006-01-02"),
+			Errors:       make(map[string]string),
+		}
+
+		if poID := e.Request.URL.Query().Get("po"); poID != "" {
+			po, err := findReceivablePO(app, projectId, poID)
+			if err != nil {
+				log.Printf("grn_create: %v", err)
+				return ErrorToast(e, http.StatusNotFound, "Goods cannot be received against this purchase order")
+			}
+			receipt, err := services.POReceiptStatus(app, po.Id)
+			if err != nil {
+				log.Printf("grn_create: could not load receipt status for PO %s: %v", po.Id, err)
+				return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
+			}
+			data.POID = po.Id
+			data.PONumber = po.GetString("po_number")
+			data.Lines = grnCreateLines(receipt, nil)
+		}
+
+		return renderGRNCreate(e, data)
+	}
+}
+
+// HandleGRNSave creates a goods receipt note from the submitted form and
+// redirects to it.
+func HandleGRNSave(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		if err := e.Request.ParseForm(); err != nil {
+			return ErrorToast(e, http.StatusBadRequest, "Invalid form data")
+		}
+
+		projectId := e.Request.PathValue("projectId")
+		poID := strings.TrimSpace(e.Request.FormValue("po_id"))
+
+		po, err := findReceivablePO(app, projectId, poID)
+		if err != nil {
+			log.Printf("grn_create: %v", err)
+			return ErrorToast(e, http.StatusBadRequest, "Goods cannot be received against this purchase order")
+		}
+
+		receipt, err := services.POReceiptStatus(app, po.Id)
+		if err != nil {
+			log.Printf("grn_create: could not load receipt status for PO %s: %v", po.Id, err)
+			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
+		}
+
+		params := services.GRNParams{
+			ProjectID:         projectId,
+			PurchaseOrderID:   po.Id,
+			ReceivedDate:      strings.TrimSpace(e.Request.FormValue("received_date")),
+			ReceivedBy:        strings.TrimSpace(e.Request.FormValue("received_by")),
+			WarehouseLocation: strings.TrimSpace(e.Request.FormValue("warehouse_location")),
+			TransporterName:   strings.TrimSpace(e.Request.FormValue("transporter_name")),
+			LRNumber:          strings.TrimSpace(e.Request.FormValue("lr_number")),
+			VehicleNumber:     strings.TrimSpace(e.Request.FormValue("vehicle_number")),
+			Remarks:           strings.TrimSpace(e.Request.FormValue("remarks")),
+		}
+		entered := make(map[string]services.GRNLineParams)
+		for _, r := range receipt {
+			if r.Pending() <= 0 {
+				continue
+			}
+			received, _ := strconv.ParseFloat(e.Request.FormValue("received_"+r.POLineItemID), 64)
+			rejected, _ := strconv.ParseFloat(e.Request.FormValue("rejected_"+r.POLineItemID), 64)
+			line := services.GRNLineParams{
+				POLineItemID:    r.POLineItemID,
+				QtyReceived:     received,
+				QtyRejected:     rejected,
+				RejectionReason: strings.TrimSpace(e.Request.FormValue("reason_" + r.POLineItemID)),
+			}
+			entered[r.POLineItemID] = line
+			params.Lines = append(params.Lines, line)
+		}
+
+		errors := services.ValidateGRNLines(receipt, params.Lines)
+		if params.ReceivedDate == "" {
+			errors["received_date"] = "Received date is required"
+		}
+		if len(errors) > 0 {
+			SetToast(e, "warning", "Please fix the errors below")
+			return renderGRNCreate(e, templates.GRNCreateData{
+				ProjectID:         projectId,
+				POs:               fetchReceivablePOs(app, projectId),
+				POID:              po.Id,
+				PONumber:          po.GetString("po_number"),
+				Lines:             grnCreateLines(receipt, entered),
+				ReceivedDate:      params.ReceivedDate,
+				ReceivedBy:        params.ReceivedBy,
+				WarehouseLocation: params.WarehouseLocation,
+				TransporterName:   params.TransporterName,
+				LRNumber:          params.LRNumber,
+				VehicleNumber:     params.VehicleNumber,
+				Remarks:           params.Remarks,
+				Errors:            errors,
+			})
+		}
+
+		result, err := services.CreateGRN(e.Request.Context(), app, params)
+		if err != nil {
+			log.Printf("grn_create: could not create GRN for PO %s: %v", po.Id, err)
+			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
+		}
+
+		msg := fmt.Sprintf("GRN %s created", result.GRNNumber)
+		if result.POCompleted {
+			msg = fmt.Sprintf("GRN %s created. %s is fully received and marked completed", result.GRNNumber, po.GetString("po_number"))
+		}
+		SetToast(e, "success", msg)
+
+		redirectURL := fmt.Sprintf("/projects/%s/grn/%s", projectId, result.GRNID)
+		if e.Request.Header.Get("HX-Request") == "true" {
+			e.Response.Header().Set("HX-Redirect", redirectURL)
+			return e.String(http.StatusOK, "")
+		}
+		return e.Redirect(http.StatusFound, redirectURL)
+	}
+}
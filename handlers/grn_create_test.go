@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/testhelpers"
+)
+
+// createSentPO creates a sent purchase order with a single line item.
+func createSentPO(t *testing.T, app *pocketbase.PocketBase, projectID, poNumber string, qty float64) (*core.Record, *core.Record) {
+	t.Helper()
+	vendor := testhelpers.CreateTestVendor(t, app, "Receipt Vendor")
+	po := testhelpers.CreateTestPurchaseOrder(t, app, projectID, vendor.Id, poNumber)
+	po.Set("status", "sent")
+	if err := app.Save(po); err != nil {
+		t.Fatalf("failed to update PO status: %v", err)
+	}
+	line := testhelpers.CreateTestPOLineItem(t, app, po.Id, 1, "Solar Inverter", qty, 25000, 18)
+	return po, line
+}
+
+func postGRN(t *testing.T, app *pocketbase.PocketBase, projectID string, form url.Values) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/projects/"+projectID+"/grn", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("HX-Request", "true")
+	req.SetPathValue("projectId", projectID)
+	rec := httptest.NewRecorder()
+	if err := HandleGRNSave(app)(newTestRequestEvent(app, req, rec)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	return rec
+}
+
+func TestHandleGRNCreate_GET_ListsPendingLines(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "GRN Project")
+	po, line := createSentPO(t, app, project.Id, "PO-GRN-001", 4)
+	vendor := testhelpers.CreateTestVendor(t, app, "Draft Vendor")
+	testhelpers.CreateTestPurchaseOrder(t, app, project.Id, vendor.Id, "PO-DRAFT-001")
+
+	req := httptest.NewRequest(http.MethodGet, "/projects/"+project.Id+"/grn/create?po="+po.Id, nil)
+	req.SetPathValue("projectId", project.Id)
+	rec := httptest.NewRecorder()
+	if err := HandleGRNCreate(app)(newTestRequestEvent(app, req, rec)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rec.Code)
+	}
+	body := rec.Body.String()
+	testhelpers.AssertHTMLContains(t, body, "PO-GRN-001", "Solar Inverter", "received_"+line.Id)
+	if strings.Contains(body, "PO-DRAFT-001") {
+		t.Error("draft POs should not be offered for receipt")
+	}
+}
+
+func TestHandleGRNSave_CompletesPO(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "GRN Save Project")
+	po, line := createSentPO(t, app, project.Id, "PO-GRN-002", 4)
+
+	form := url.Values{}
+	form.Set("po_id", po.Id)
+	form.Set("received_date", "2025-07-01")
+	form.Set("received_"+line.Id, "4")
+	rec := postGRN(t, app, project.Id, form)
+
+	grns, err := app.FindRecordsByFilter("grn", "purchase_order = {:poId}", "", 0, 0, map[string]any{"poId": po.Id})
+	if err != nil || len(grns) != 1 {
+		t.Fatalf("expected one GRN, got %d (%v)", len(grns), err)
+	}
+	testhelpers.AssertHXRedirect(t, rec.Header().Get("HX-Redirect"), "/projects/"+project.Id+"/grn/"+grns[0].Id)
+
+	updated, err := app.FindRecordById("purchase_orders", po.Id)
+	if err != nil {
+		t.Fatalf("failed to reload PO: %v", err)
+	}
+	if updated.GetString("status") != "completed" {
+		t.Errorf("expected PO status completed, got %q", updated.GetString("status"))
+	}
+}
+
+func TestHandleGRNSave_ValidationErrors(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "GRN Invalid Project")
+	po, line := createSentPO(t, app, project.Id, "PO-GRN-003", 4)
+
+	form := url.Values{}
+	form.Set("po_id", po.Id)
+	form.Set("received_date", "2025-07-01")
+	form.Set("received_"+line.Id, "4")
+	form.Set("rejected_"+line.Id, "1")
+	rec := postGRN(t, app, project.Id, form)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rec.Code)
+	}
+	testhelpers.AssertHTMLContains(t, rec.Body.String(), "a rejection reason is required")
+
+	grns, _ := app.FindAllRecords("grn")
+	if len(grns) != 0 {
+		t.Errorf("expected no GRN to be created, got %d", len(grns))
+	}
+}
+
+func TestHandleGRNSave_DraftPORejected(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "GRN Draft Project")
+	vendor := testhelpers.CreateTestVendor(t, app, "Draft Vendor")
+	po := testhelpers.CreateTestPurchaseOrder(t, app, project.Id, vendor.Id, "PO-GRN-004")
+	line := testhelpers.CreateTestPOLineItem(t, app, po.Id, 1, "Cable", 10, 20, 18)
+
+	form := url.Values{}
+	form.Set("po_id", po.Id)
+	form.Set("received_date", "2025-07-01")
+	form.Set("received_"+line.Id, "10")
+	rec := postGRN(t, app, project.Id, form)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"log"
+
+	"github.com/a-h/templ"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/services"
+	"projectcreation/templates"
+)
+
+// HandleGRNList renders the goods receipt notes of a project, newest first.
+func HandleGRNList(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		projectId := e.Request.PathValue("projectId")
+
+		grnRecords, err := app.FindRecordsByFilter(
+			"grn",
+			"project = {:projectId}",
+			"-created",
+			0,
+			0,
+			map[string]any{"projectId": projectId},
+		)
+		if err != nil {
+			log.Printf("grn_list: could not query grn: %v", err)
+			grnRecords = nil
+		}
+
+		var items []templates.GRNListItem
+		for _, grn := range grnRecords {
+			item := templates.GRNListItem{
+				ID:           grn.Id,
+				GRNNumber:    grn.GetString("grn_number"),
+				POID:         grn.GetString("purchase_order"),
+				ReceivedDate: grn.GetString("received_date"),
+			}
+
+			if po, err := app.FindRecordById("purchase_orders", item.POID); err == nil {
+				item.PONumber = po.GetString("po_number")
+				if v, err := app.FindRecordById("vendors", po.GetString("vendor")); err == nil {
+					item.VendorName = v.GetString("name")
+				}
+			} else {
+				log.Printf("grn_list: could not find PO %s: %v", item.POID, err)
+			}
+
+			lines, err := app.FindRecordsByFilter("grn_line_items", "grn = {:grnId}", "", 0, 0, map[string]any{"grnId": grn.Id})
+			if err != nil {
+				log.Printf("grn_list: could not query grn_line_items for GRN %s: %v", grn.Id, err)
+				lines = nil
+			}
+			var accepted float64
+			for _, li := range lines {
+				accepted += li.GetFloat("qty_accepted") * li.GetFloat("rate")
+				if li.GetFloat("qty_rejected") > 0 {
+					item.RejectedCount++
+				}
+			}
+			item.ItemCount = len(lines)
+			item.AcceptedValue = services.FormatINR(accepted)
+
+			items = append(items, item)
+		}
+
+		data := templates.GRNListData{
+			GRNs:       items,
+			ProjectID:  projectId,
+			TotalCount: len(items),
+		}
+
+		var component templ.Component
+		if e.Request.Header.Get("HX-Request") == "true" {
+			component = templates.GRNListContent(data)
+		} else {
+			headerData := GetHeaderData(e.Request)
+			sidebarData := GetSidebarData(e.Request)
+			component = templates.GRNListPage(data, headerData, sidebarData)
+		}
+		return component.Render(e.Request.Context(), e.Response)
+	}
+}
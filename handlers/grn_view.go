@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/a-h/templ"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/collections"
+	"projectcreation/services"
+	"projectcreation/templates"
+)
+
+// findProjectGRN returns the GRN if it belongs to the project.
+func findProjectGRN(app *pocketbase.PocketBase, projectId, id string) (*core.Record, error) {
+	grn, err := app.FindRecordById("grn", id)
+	if err != nil {
+		return nil, err
+	}
+	if grn.GetString("project") != projectId {
+		return nil, fmt.Errorf("GRN %s does not belong to project %s", id, projectId)
+	}
+	return grn, nil
+}
+
+// HandleGRNView renders a goods receipt note.
+func HandleGRNView(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		projectId := e.Request.PathValue("projectId")
+		id := e.Request.PathValue("id")
+
+		grn, err := findProjectGRN(app, projectId, id)
+		if err != nil {
+			log.Printf("grn_view: %v", err)
+			return e.String(http.StatusNotFound, "GRN not found")
+		}
+
+		// Reuse the export data so the page and the PDF show the same figures
+		export, err := services.BuildGRNExportData(app, grn.Id)
+		if err != nil {
+			log.Printf("grn_view: could not build GRN data for %s: %v", id, err)
+			return e.String(http.StatusInternalServerError, "Failed to load GRN")
+		}
+
+		poStatus := ""
+		if po, err := app.FindRecordById("purchase_orders", grn.GetString("purchase_order")); err == nil {
+			poStatus = po.GetString("status")
+		}
+
+		var lineItems []templates.GRNViewLineItem
+		for _, li := range export.LineItems {
+			lineItems = append(lineItems, templates.GRNViewLineItem{
+				SINo:            li.SINo,
+				Description:     li.Description,
+				UOM:             li.UoM,
+				Ordered:         formatQty(li.QtyOrdered),
+				Received:        formatQty(li.QtyReceived),
+				Rejected:        formatQty(li.QtyRejected),
+				Accepted:        formatQty(li.QtyAccepted),
+				Rate:            services.FormatINR(li.Rate),
+				Amount:          services.FormatINR(li.Amount),
+				RejectionReason: li.RejectionReason,
+			})
+		}
+
+		data := templates.GRNViewData{
+			CompanyName:       export.CompanyName,
+			LogoURL:           collections.GetLogoURL(app),
+			ProjectID:         projectId,
+			GRNID:             grn.Id,
+			GRNNumber:         export.GRNNumber,
+			POID:              grn.GetString("purchase_order"),
+			PONumber:          export.PONumber,
+			POStatus:          poStatus,
+			VendorName:        export.VendorName,
+			VendorGSTIN:       export.VendorGSTIN,
+			ReceivedDate:      export.ReceivedDate,
+			ReceivedBy:        export.ReceivedBy,
+			WarehouseLocation: export.WarehouseLocation,
+			TransporterName:   export.TransporterName,
+			LRNumber:          export.LRNumber,
+			VehicleNumber:     export.VehicleNumber,
+			Remarks:           export.Remarks,
+			LineItems:         lineItems,
+			TotalAccepted:     services.FormatINR(export.TotalAccepted),
+		}
+
+		var component templ.Component
+		if e.Request.Header.Get("HX-Request") == "true" {
+			component = templates.GRNViewContent(data)
+		} else {
+			headerData := GetHeaderData(e.Request)
+			sidebarData := GetSidebarData(e.Request)
+			component = templates.GRNViewPage(data, headerData, sidebarData)
+		}
+		return component.Render(e.Request.Context(), e.Response)
+	}
+}
+
+// HandleGRNExportPDF returns a handler that generates and downloads a PDF for a GRN.
+func HandleGRNExportPDF(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		projectId := e.Request.PathValue("projectId")
+		id := e.Request.PathValue("id")
+		if id == "" {
+			return e.String(http.StatusBadRequest, "Missing GRN ID")
+		}
+
+		if _, err := findProjectGRN(app, projectId, id); err != nil {
+			log.Printf("grn_export: %v", err)
+			return e.String(http.StatusNotFound, "GRN not found")
+		}
+
+		data, err := services.BuildGRNExportData(app, id)
+		if err != nil {
+			log.Printf("grn_export: failed to build data: %v", err)
+			return e.String(http.StatusInternalServerError, "Failed to build GRN data")
+		}
+
+		pdfBytes, err := services.GenerateGRNPDF(data)
+		if err != nil {
+			log.Printf("grn_export: failed to generate PDF: %v", err)
+			return e.String(http.StatusInternalServerError, "Failed to generate PDF")
+		}
+
+		filename := fmt.Sprintf("%s.pdf", sanitizeFilename(data.GRNNumber))
+
+		e.Response.Header().Set("Content-Type", "application/pdf")
+		e.Response.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+		e.Response.Write(pdfBytes)
+		return nil
+	}
+}
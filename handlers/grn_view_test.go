@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"projectcreation/services"
+	"projectcreation/testhelpers"
+)
+
+func TestHandleGRNView(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "GRN View Project")
+	po, line := createSentPO(t, app, project.Id, "PO-GRN-VIEW", 4)
+
+	result, err := services.CreateGRN(context.Background(), app, services.GRNParams{
+		ProjectID:       project.Id,
+		PurchaseOrderID: po.Id,
+		ReceivedDate:    "2025-07-01",
+		Lines: []services.GRNLineParams{
+			{POLineItemID: line.Id, QtyReceived: 3, QtyRejected: 1, RejectionReason: "Damaged casing"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateGRN failed: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/projects/"+project.Id+"/grn/"+result.GRNID, nil)
+	req.SetPathValue("projectId", project.Id)
+	req.SetPathValue("id", result.GRNID)
+	rec := httptest.NewRecorder()
+	if err := HandleGRNView(app)(newTestRequestEvent(app, req, rec)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rec.Code)
+	}
+	testhelpers.AssertHTMLContains(t, rec.Body.String(),
+		"GOODS RECEIPT NOTE",
+		result.GRNNumber,
+		"PO-GRN-VIEW",
+		"Solar Inverter",
+		"Rejected: Damaged casing",
+		"/export/pdf",
+	)
+}
+
+func TestHandleGRNView_WrongProject(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "GRN Owner Project")
+	other := testhelpers.CreateTestProject(t, app, "Other Project")
+	po, line := createSentPO(t, app, project.Id, "PO-GRN-OWN", 4)
+
+	result, err := services.CreateGRN(context.Background(), app, services.GRNParams{
+		ProjectID:       project.Id,
+		PurchaseOrderID: po.Id,
+		ReceivedDate:    "2025-07-01",
+		Lines:           []services.GRNLineParams{{POLineItemID: line.Id, QtyReceived: 1}},
+	})
+	if err != nil {
+		t.Fatalf("CreateGRN failed: %v", err)
+	}
+
+	for _, h := range []struct {
+		name    string
+		handler func(*testing.T) *httptest.ResponseRecorder
+	}{
+		{"view", func(t *testing.T) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodGet, "/projects/"+other.Id+"/grn/"+result.GRNID, nil)
+			req.SetPathValue("projectId", other.Id)
+			req.SetPathValue("id", result.GRNID)
+			rec := httptest.NewRecorder()
+			if err := HandleGRNView(app)(newTestRequestEvent(app, req, rec)); err != nil {
+				t.Fatalf("handler returned error: %v", err)
+			}
+			return rec
+		}},
+		{"pdf", func(t *testing.T) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodGet, "/projects/"+other.Id+"/grn/"+result.GRNID+"/export/pdf", nil)
+			req.SetPathValue("projectId", other.Id)
+			req.SetPathValue("id", result.GRNID)
+			rec := httptest.NewRecorder()
+			if err := HandleGRNExportPDF(app)(newTestRequestEvent(app, req, rec)); err != nil {
+				t.Fatalf("handler returned error: %v", err)
+			}
+			return rec
+		}},
+	} {
+		t.Run(h.name, func(t *testing.T) {
+			if rec := h.handler(t); rec.Code != http.StatusNotFound {
+				t.Errorf("expected status 404, got %d", rec.Code)
+			}
+		})
+	}
+}
+
+func TestHandleGRNExportPDF(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "GRN PDF Project")
+	po, line := createSentPO(t, app, project.Id, "PO-GRN-PDF", 4)
+
+	result, err := services.CreateGRN(context.Background(), app, services.GRNParams{
+		ProjectID:       project.Id,
+		PurchaseOrderID: po.Id,
+		ReceivedDate:    "2025-07-01",
+		Lines:           []services.GRNLineParams{{POLineItemID: line.Id, QtyReceived: 2}},
+	})
+	if err != nil {
+		t.Fatalf("CreateGRN failed: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/projects/"+project.Id+"/grn/"+result.GRNID+"/export/pdf", nil)
+	req.SetPathValue("projectId", project.Id)
+	req.SetPathValue("id", result.GRNID)
+	rec := httptest.NewRecorder()
+	if err := HandleGRNExportPDF(app)(newTestRequestEvent(app, req, rec)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+
+	if ct := rec.Header().Get("Content-Type"); ct != "application/pdf" {
+		t.Errorf("expected Content-Type application/pdf, got %q", ct)
+	}
+	if body := rec.Body.Bytes(); len(body) < 5 || string(body[:5]) != "%PDF-" {
+		t.Error("response is not a PDF")
+	}
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
@@ -30,7 +31,7 @@ func HandleDeleteMainItem(app *pocketbase.PocketBase) func(*core.RequestEvent) e
 			return ErrorToast(e, http.StatusNotFound, "Item not found")
 		}
 
-		if err := app.Delete(record); err != nil {
+		if err := app.DeleteWithContext(e.Request.Context(), record); err != nil {
 			log.Printf("delete_main_item: error deleting %s: %v", itemID, err)
 			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
 		}
@@ -67,13 +68,13 @@ func HandleDeleteSubItem(app *pocketbase.PocketBase) func(*core.RequestEvent) er
 		}
 		mainItemID := subItemRecord.GetString("main_item")
 
-		if err := app.Delete(subItemRecord); err != nil {
+		if err := app.DeleteWithContext(e.Request.Context(), subItemRecord); err != nil {
 			log.Printf("delete_sub_item: error deleting %s: %v", subItemID, err)
 			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
 		}
 
 		// Recalculate parent main item budgeted price
-		recalcMainItemBudgeted(app, mainItemID)
+		recalcMainItemBudgeted(e.Request.Context(), app, mainItemID)
 
 		SetToast(e, "success", "Sub-item deleted")
 
@@ -104,13 +105,13 @@ func HandleDeleteSubSubItem(app *pocketbase.PocketBase) func(*core.RequestEvent)
 		}
 		subItemID := record.GetString("sub_item")
 
-		if err := app.Delete(record); err != nil {
+		if err := app.DeleteWithContext(e.Request.Context(), record); err != nil {
 			log.Printf("delete_sub_sub_item: error deleting %s: %v", subSubItemID, err)
 			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
 		}
 
 		// Recalculate parent chain
-		recalcSubItemBudgeted(app, subItemID)
+		recalcSubItemBudgeted(e.Request.Context(), app, subItemID)
 
 		// Find the main item ID for keeping accordions open
 		subItemRecord, err := app.FindRecordById("sub_items", subItemID)
@@ -119,7 +120,7 @@ func HandleDeleteSubSubItem(app *pocketbase.PocketBase) func(*core.RequestEvent)
 			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
 		}
 		mainItemID := subItemRecord.GetString("main_item")
-		recalcMainItemBudgeted(app, mainItemID)
+		recalcMainItemBudgeted(e.Request.Context(), app, mainItemID)
 
 		SetToast(e, "success", "Sub-sub-item deleted")
 
@@ -260,7 +261,7 @@ func HandlePatchMainItem(app *pocketbase.PocketBase) func(*core.RequestEvent) er
 		}
 
 		if updated {
-			if err := app.Save(record); err != nil {
+			if err := app.SaveWithContext(e.Request.Context(), record); err != nil {
 				log.Printf("patch_main_item: error saving %s: %v", itemID, err)
 				return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
 			}
@@ -330,11 +331,11 @@ func HandlePatchSubItem(app *pocketbase.PocketBase) func(*core.RequestEvent) err
 
 		if updated {
 			// Recalculate budgeted price
-			recalcSubItemBudgeted(app, subItemID)
+			recalcSubItemBudgeted(e.Request.Context(), app, subItemID)
 			// Reload record to get updated budgeted_price
 			record, _ = app.FindRecordById("sub_items", subItemID)
 			// Recalculate parent
-			recalcMainItemBudgeted(app, record.GetString("main_item"))
+			recalcMainItemBudgeted(e.Request.Context(), app, record.GetString("main_item"))
 		}
 
 		SetToast(e, "info", "Item saved")
@@ -402,17 +403,17 @@ func HandlePatchSubSubItem(app *pocketbase.PocketBase) func(*core.RequestEvent)
 			// Recalculate this item's budgeted price
 			budgeted := record.GetFloat("qty_per_unit") * record.GetFloat("unit_price")
 			record.Set("budgeted_price", budgeted)
-			if err := app.Save(record); err != nil {
+			if err := app.SaveWithContext(e.Request.Context(), record); err != nil {
 				log.Printf("patch_sub_sub_item: error saving %s: %v", subSubItemID, err)
 				return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
 			}
 
 			// Recalculate parent chain
 			subItemID := record.GetString("sub_item")
-			recalcSubItemBudgeted(app, subItemID)
+			recalcSubItemBudgeted(e.Request.Context(), app, subItemID)
 			subItemRecord, err := app.FindRecordById("sub_items", subItemID)
 			if err == nil {
-				recalcMainItemBudgeted(app, subItemRecord.GetString("main_item"))
+				recalcMainItemBudgeted(e.Request.Context(), app, subItemRecord.GetString("main_item"))
 			}
 		}
 
@@ -423,7 +424,7 @@ func HandlePatchSubSubItem(app *pocketbase.PocketBase) func(*core.RequestEvent)
 }
 
 // recalcSubItemBudgeted recalculates a sub-item's budgeted_price from its sub-sub-items.
-func recalcSubItemBudgeted(app *pocketbase.PocketBase, subItemID string) {
+func recalcSubItemBudgeted(ctx context.Context, app *pocketbase.PocketBase, subItemID string) {
 	subSubItemsCol, err := app.FindCollectionByNameOrId("sub_sub_items")
 	if err != nil {
 		return
@@ -450,13 +451,13 @@ func recalcSubItemBudgeted(app *pocketbase.PocketBase, subItemID string) {
 		siRecord.Set("budgeted_price", budgeted)
 	}
 
-	if err := app.Save(siRecord); err != nil {
+	if err := app.SaveWithContext(ctx, siRecord); err != nil {
 		log.Printf("recalcSubItemBudgeted: error saving %s: %v", subItemID, err)
 	}
 }
 
 // recalcMainItemBudgeted recalculates a main item's budgeted_price from its sub-items.
-func recalcMainItemBudgeted(app *pocketbase.PocketBase, mainItemID string) {
+func recalcMainItemBudgeted(ctx context.Context, app *pocketbase.PocketBase, mainItemID string) {
 	subItemsCol, err := app.FindCollectionByNameOrId("sub_items")
 	if err != nil {
 		return
@@ -480,7 +481,7 @@ func recalcMainItemBudgeted(app *pocketbase.PocketBase, mainItemID string) {
 		miRecord.Set("budgeted_price", total*miRecord.GetFloat("qty"))
 	}
 
-	if err := app.Save(miRecord); err != nil {
+	if err := app.SaveWithContext(ctx, miRecord); err != nil {
 		log.Printf("recalcMainItemBudgeted: error saving %s: %v", mainItemID, err)
 	}
 }
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/collections"
+	"projectcreation/templates"
+)
+
+// safeNextURL only allows local redirect targets after login.
+func safeNextURL(next string) string {
+	if next == "" || !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/login") {
+		return "/projects"
+	}
+	return next
+}
+
+func renderLogin(app *pocketbase.PocketBase, e *core.RequestEvent, data templates.LoginData) error {
+	data.CompanyName = collections.GetCompanyName(app)
+	data.LogoURL = collections.GetLogoURL(app)
+	return templates.LoginPage(data).Render(e.Request.Context(), e.Response)
+}
+
+// HandleLoginPage renders the sign-in form (GET /login).
+func HandleLoginPage(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		next := e.Request.URL.Query().Get("next")
+
+		// Already signed in — skip the form
+		if findUserFromCookie(app, e.Request) != nil {
+			return e.Redirect(http.StatusFound, safeNextURL(next))
+		}
+
+		return renderLogin(app, e, templates.LoginData{Next: next})
+	}
+}
+
+// HandleLogin verifies the submitted credentials (POST /login) and stores a
+// PocketBase auth token in an HttpOnly cookie.
+func HandleLogin(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		if err := e.Request.ParseForm(); err != nil {
+			return ErrorToast(e, http.StatusBadRequest, "Invalid form data")
+		}
+
+		email := strings.TrimSpace(e.Request.FormValue("email"))
+		password := e.Request.FormValue("password")
+		next := e.Request.FormValue("next")
+
+		data := templates.LoginData{Email: email, Next: next}
+		if email == "" || password == "" {
+			data.Error = "Email and password are required"
+			return renderLogin(app, e, data)
+		}
+
+		user, err := app.FindAuthRecordByEmail("users", email)
+		if err != nil || !user.ValidatePassword(password) {
+			data.Error = "Invalid email or password"
+			return renderLogin(app, e, data)
+		}
+
+		token, err := user.NewAuthToken()
+		if err != nil {
+			log.Printf("login: could not create auth token for %s: %v", email, err)
+			data.Error = "Something went wrong. Please try again."
+			return renderLogin(app, e, data)
+		}
+
+		http.SetCookie(e.Response, &http.Cookie{
+			Name:     authCookieName,
+			Value:    token,
+			Path:     "/",
+			MaxAge:   int(user.Collection().AuthToken.Duration),
+			HttpOnly: true,
+			Secure:   e.Request.TLS != nil,
+			SameSite: http.SameSiteLaxMode,
+		})
+
+		return e.Redirect(http.StatusFound, safeNextURL(next))
+	}
+}
+
+// HandleLogout clears the session and active project cookies (POST /logout).
+func HandleLogout(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		clearAuthCookie(e)
+		http.SetCookie(e.Response, &http.Cookie{
+			Name:   "active_project",
+			Value:  "",
+			Path:   "/",
+			MaxAge: -1,
+		})
+
+		if e.Request.Header.Get("HX-Request") == "true" {
+			e.Response.Header().Set("HX-Redirect", "/login")
+			return e.String(http.StatusOK, "")
+		}
+		return e.Redirect(http.StatusFound, "/login")
+	}
+}
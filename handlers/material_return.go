@@ -0,0 +1,290 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/services"
+	"projectcreation/templates"
+)
+
+func renderMRNCreate(e *core.RequestEvent, data templates.MRNCreateData) error {
+	var component templ.Component
+	if e.Request.Header.Get("HX-Request") == "true" {
+		component = templates.MRNCreateContent(data)
+	} else {
+		headerData := GetHeaderData(e.Request)
+		sidebarData := GetSidebarData(e.Request)
+		component = templates.MRNCreatePage(data, headerData, sidebarData)
+	}
+	return component.Render(e.Request.Context(), e.Response)
+}
+
+// mrnCreateData fills the DC summary of the material return form.
+func mrnCreateData(app *pocketbase.PocketBase, dc *core.Record) templates.MRNCreateData {
+	reasons := services.MRNReasons(dc.GetString("dc_type"))
+	data := templates.MRNCreateData{
+		ProjectID: dc.GetString("project"),
+		DCID:      dc.Id,
+		DCNumber:  dc.GetString("dc_number"),
+		DCType:    dc.GetString("dc_type"),
+		Reasons:   reasons,
+		Reason:    reasons[0],
+		Errors:    make(map[string]string),
+	}
+	if shipTo := resolveDCAddressDisplay(app, dc.GetString("ship_to_address")); shipTo != nil {
+		data.ShipTo = shipTo.CompanyName
+	}
+	return data
+}
+
+// mrnCreateLine converts a DC line for the material return form.
+func mrnCreateLine(l services.ReturnableDCLine) templates.MRNCreateLine {
+	line := templates.MRNCreateLine{
+		DCLineItemID: l.DCLineItemID,
+		Description:  l.Description,
+		UOM:          l.UOM,
+		Dispatched:   formatQty(l.Dispatched),
+		Returned:     formatQty(l.Returned),
+		Outstanding:  formatQty(l.Outstanding()),
+		Condition:    "good",
+	}
+	for _, s := range l.Serials {
+		line.Serials = append(line.Serials, templates.MRNSerialOption{ID: s.ID, SerialNumber: s.SerialNumber})
+	}
+	return line
+}
+
+// HandleMRNCreate renders the material return form for a DC. On a
+// returnable DC every outstanding line is prefilled to come back in full.
+func HandleMRNCreate(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		dc, ok := findProjectDC(app, e)
+		if !ok {
+			return ErrorToast(e, http.StatusNotFound, "Delivery challan not found")
+		}
+		if err := services.CheckReturnable(app, dc); err != nil {
+			return ErrorToast(e, http.StatusBadRequest, err.Error())
+		}
+
+		lines, err := services.ReturnableDCLines(app, dc.Id)
+		if err != nil {
+			log.Printf("material_return: could not load lines of DC %s: %v", dc.Id, err)
+			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
+		}
+
+		data := mrnCreateData(app, dc)
+		data.ReturnDate = time.Now().Format("2006-01-02")
+		for _, l := range lines {
+			line := mrnCreateLine(l)
+			line.Quantity = "0"
+			if dc.GetString("dc_type") == "returnable" {
+				line.Quantity = formatQty(l.Outstanding())
+				for _, s := range l.Serials {
+					line.SelectedIDs = append(line.SelectedIDs, s.ID)
+				}
+			}
+			data.Lines = append(data.Lines, line)
+		}
+		return renderMRNCreate(e, data)
+	}
+}
+
+// HandleMRNSave records a material return note against a DC and makes the
+// returned serials available again.
+func HandleMRNSave(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		if err := e.Request.ParseForm(); err != nil {
+			return ErrorToast(e, http.StatusBadRequest, "Invalid form data")
+		}
+
+		dc, ok := findProjectDC(app, e)
+		if !ok {
+			return ErrorToast(e, http.StatusNotFound, "Delivery challan not found")
+		}
+		lines, err := services.ReturnableDCLines(app, dc.Id)
+		if err != nil {
+			log.Printf("material_return: could not load lines of DC %s: %v", dc.Id, err)
+			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
+		}
+
+		params := services.MRNParams{
+			DCID:       dc.Id,
+			ReturnDate: strings.TrimSpace(e.Request.FormValue("return_date")),
+			Reason:     e.Request.FormValue("reason"),
+			ReturnedBy: strings.TrimSpace(e.Request.FormValue("returned_by")),
+			Remarks:    strings.TrimSpace(e.Request.FormValue("remarks")),
+		}
+		formLines := make([]templates.MRNCreateLine, 0, len(lines))
+		for _, l := range lines {
+			rawQty := strings.TrimSpace(e.Request.FormValue("qty_" + l.DCLineItemID))
+			qty, _ := strconv.ParseFloat(rawQty, 64)
+			serialIDs := e.Request.Form["serials_"+l.DCLineItemID]
+			lineParams := services.MRNLineParams{
+				DCLineItemID: l.DCLineItemID,
+				Quantity:     qty,
+				Condition:    e.Request.FormValue("condition_" + l.DCLineItemID),
+				SerialIDs:    serialIDs,
+				Remarks:      strings.TrimSpace(e.Request.FormValue("note_" + l.DCLineItemID)),
+			}
+			params.Lines = append(params.Lines, lineParams)
+
+			line := mrnCreateLine(l)
+			line.Quantity = rawQty
+			line.Condition = lineParams.Condition
+			line.Remarks = lineParams.Remarks
+			line.SelectedIDs = serialIDs
+			formLines = append(formLines, line)
+		}
+
+		if errors := services.ValidateMRN(app, params); len(errors) > 0 {
+			SetToast(e, "warning", "Please fix the errors below")
+			data := mrnCreateData(app, dc)
+			data.ReturnDate = params.ReturnDate
+			data.Reason = params.Reason
+			data.ReturnedBy = params.ReturnedBy
+			data.Remarks = params.Remarks
+			data.Lines = formLines
+			data.Errors = errors
+			return renderMRNCreate(e, data)
+		}
+
+		mrn, err := services.CreateMRN(e.Request.Context(), app, params)
+		if err != nil {
+			log.Printf("material_return: could not record return against DC %s: %v", dc.Id, err)
+			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
+		}
+
+		SetToast(e, "success", fmt.Sprintf("%s recorded against %s", mrn.GetString("mrn_number"), dc.GetString("dc_number")))
+		return redirectToDCDetail(e, dc.GetString("project"), dc.Id)
+	}
+}
+
+// HandleMaterialReturns renders the returnable DCs of a project that still
+// have goods out, with ?view=overdue narrowing them to those past their
+// expected return date, and the material return notes recorded so far.
+func HandleMaterialReturns(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		projectId := e.Request.PathValue("projectId")
+		if _, err := app.FindRecordById("projects", projectId); err != nil {
+			return ErrorToast(e, http.StatusNotFound, "Project not found")
+		}
+
+		outstanding, err := services.BuildOutstandingReturns(app, projectId, time.Now())
+		if err != nil {
+			log.Printf("material_return: could not build outstanding returns for project %s: %v", projectId, err)
+			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
+		}
+
+		data := templates.MaterialReturnsData{ProjectID: projectId}
+		if e.Request.URL.Query().Get("view") == "overdue" {
+			data.View = "overdue"
+		}
+		for _, r := range outstanding {
+			if r.DaysOverdue > 0 {
+				data.Overdue++
+			} else if data.View == "overdue" {
+				continue
+			}
+			row := templates.OutstandingReturnRow{
+				DCID:               r.DCID,
+				DCNumber:           r.DCNumber,
+				ChallanDate:        r.ChallanDate,
+				ExpectedReturnDate: r.ExpectedReturnDate,
+				Dispatched:         formatQty(r.Dispatched),
+				Returned:           formatQty(r.Returned),
+				Outstanding:        formatQty(r.Outstanding()),
+				DaysOverdue:        r.DaysOverdue,
+			}
+			if shipTo := resolveDCAddressDisplay(app, r.ShipToID); shipTo != nil {
+				row.ShipTo = shipTo.CompanyName
+			}
+			data.Outstanding = append(data.Outstanding, row)
+		}
+
+		notes, _ := app.FindRecordsByFilter("material_return_notes", "project = {:pid}", "-return_date,-mrn_number", 0, 0, map[string]any{"pid": projectId})
+		for _, n := range notes {
+			row := templates.MRNListRow{
+				DCID:       n.GetString("dc"),
+				MRNNumber:  n.GetString("mrn_number"),
+				ReturnDate: n.GetString("return_date"),
+				Reason:     n.GetString("reason"),
+				ReturnedBy: n.GetString("returned_by"),
+			}
+			if dc, err := app.FindRecordById("delivery_challans", row.DCID); err == nil {
+				row.DCNumber = dc.GetString("dc_number")
+			}
+			qty := 0.0
+			lines, _ := app.FindRecordsByFilter("material_return_lines", "mrn = {:id}", "", 0, 0, map[string]any{"id": n.Id})
+			for _, l := range lines {
+				qty += l.GetFloat("quantity")
+				row.Serials += len(l.GetStringSlice("serial_numbers"))
+			}
+			row.Quantity = formatQty(qty)
+			data.Notes = append(data.Notes, row)
+		}
+
+		var component templ.Component
+		if e.Request.Header.Get("HX-Request") == "true" {
+			component = templates.MaterialReturnsContent(data)
+		} else {
+			headerData := GetHeaderData(e.Request)
+			sidebarData := GetSidebarData(e.Request)
+			component = templates.MaterialReturnsPage(data, headerData, sidebarData)
+		}
+		return component.Render(e.Request.Context(), e.Response)
+	}
+}
+
+// buildDCDetailReturns returns the material returned against a DC for its
+// detail page, or nil for a DC that is not returnable and has nothing
+// returned.
+func buildDCDetailReturns(app *pocketbase.PocketBase, dc *core.Record) *templates.DCDetailReturns {
+	notes, _ := app.FindRecordsByFilter("material_return_notes", "dc = {:did}", "return_date,mrn_number", 0, 0, map[string]any{"did": dc.Id})
+	if len(notes) == 0 && (dc.GetString("dc_type") != "returnable" || dc.GetString("status") == "draft") {
+		return nil
+	}
+	lines, err := services.ReturnableDCLines(app, dc.Id)
+	if err != nil {
+		log.Printf("dc_detail: could not load returns of DC %s: %v", dc.Id, err)
+		return nil
+	}
+
+	data := &templates.DCDetailReturns{}
+	for _, l := range lines {
+		data.Lines = append(data.Lines, templates.DCDetailReturnLine{
+			Description: l.Description,
+			Dispatched:  formatQty(l.Dispatched),
+			Returned:    formatQty(l.Returned),
+			Outstanding: formatQty(l.Outstanding()),
+		})
+	}
+	for _, n := range notes {
+		mrn := templates.DCDetailMRN{
+			MRNNumber:  n.GetString("mrn_number"),
+			ReturnDate: n.GetString("return_date"),
+			Reason:     n.GetString("reason"),
+			ReturnedBy: n.GetString("returned_by"),
+		}
+		var serials []string
+		returnLines, _ := app.FindRecordsByFilter("material_return_lines", "mrn = {:id}", "", 0, 0, map[string]any{"id": n.Id})
+		for _, rl := range returnLines {
+			for _, id := range rl.GetStringSlice("serial_numbers") {
+				if s, err := app.FindRecordById("serial_numbers", id); err == nil {
+					serials = append(serials, s.GetString("serial_number"))
+				}
+			}
+		}
+		mrn.Serials = strings.Join(serials, ", ")
+		data.Notes = append(data.Notes, mrn)
+	}
+	return data
+}
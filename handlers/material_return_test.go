@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"projectcreation/services"
+	"projectcreation/testhelpers"
+)
+
+func TestHandleMRNSave_ReleasesSerials(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Returns Project")
+	site := testhelpers.CreateTestAddress(t, app, project.Id, "ship_to", "Site A")
+	ctx := context.Background()
+	dc, err := services.CreateReturnableDC(ctx, app, services.ReturnableDCParams{
+		ProjectID:          project.Id,
+		ChallanDate:        "2026-03-10",
+		ExpectedReturnDate: "2026-03-20",
+		ShipToID:           site.Id,
+		Items: []services.ShipmentItemParams{{
+			SourceItemType: "sub_item", SourceItemID: "x", TotalQty: 2, Serials: []string{"INV-1", "INV-2"},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("CreateReturnableDC failed: %v", err)
+	}
+	if err := services.IssueSingleDC(ctx, app, dc.Id); err != nil {
+		t.Fatalf("IssueSingleDC failed: %v", err)
+	}
+	lines, _ := services.ReturnableDCLines(app, dc.Id)
+	line := lines[0]
+	pathValues := map[string]string{"projectId": project.Id, "id": dc.Id}
+	target := "/projects/" + project.Id + "/dcs/" + dc.Id + "/returns"
+
+	// A serial count that does not match the quantity is sent back
+	rec := postHXForm(t, app, HandleMRNSave, target, pathValues, url.Values{
+		"return_date":                    {"2026-03-25"},
+		"reason":                         {services.MRNReasonReturnable},
+		"qty_" + line.DCLineItemID:       {"2"},
+		"condition_" + line.DCLineItemID: {"good"},
+		"serials_" + line.DCLineItemID:   {line.Serials[0].ID},
+	})
+	testhelpers.AssertHTMLContains(t, rec.Body.String(), "select the 2 serials being returned")
+
+	rec = postHXForm(t, app, HandleMRNSave, target, pathValues, url.Values{
+		"return_date":                    {"2026-03-25"},
+		"reason":                         {services.MRNReasonReturnable},
+		"returned_by":                    {"K. Naidu"},
+		"qty_" + line.DCLineItemID:       {"1"},
+		"condition_" + line.DCLineItemID: {"good"},
+		"serials_" + line.DCLineItemID:   {line.Serials[0].ID},
+	})
+	testhelpers.AssertHXRedirect(t, rec.Header().Get("HX-Redirect"), "/projects/"+project.Id+"/dcs/"+dc.Id)
+
+	existing := getExistingSerials(app, project.Id)
+	if _, ok := existing["INV-1"]; ok {
+		t.Error("expected returned INV-1 to be free for dispatch")
+	}
+	if existing["INV-2"] != dc.GetString("dc_number") {
+		t.Errorf("expected INV-2 still on %s, got %q", dc.GetString("dc_number"), existing["INV-2"])
+	}
+
+	// The overdue report still shows the inverter that is out
+	req := httptest.NewRequest(http.MethodGet, "/projects/"+project.Id+"/returns?view=overdue", nil)
+	req.SetPathValue("projectId", project.Id)
+	req.Header.Set("HX-Request", "true")
+	rec = httptest.NewRecorder()
+	if err := HandleMaterialReturns(app)(newTestRequestEvent(app, req, rec)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	testhelpers.AssertHTMLContains(t, rec.Body.String(), dc.GetString("dc_number"), "2026-03-20", "DAYS", "K. Naidu")
+}
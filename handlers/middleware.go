@@ -9,6 +9,7 @@ import (
 	"github.com/pocketbase/pocketbase/core"
 
 	"projectcreation/collections"
+	"projectcreation/services"
 	"projectcreation/templates"
 )
 
@@ -43,23 +44,25 @@ func GetSidebarData(r *http.Request) templates.SidebarData {
 }
 
 // ActiveProjectMiddleware reads the "active_project" cookie, loads the project
-// record, builds HeaderData with the full project list, and stores both in the
-// request context so handlers and templates can use them.
+// record, builds HeaderData with the list of projects the signed-in user can
+// access, and stores both in the request context so handlers and templates can
+// use them.
 func ActiveProjectMiddleware(app *pocketbase.PocketBase) func(e *core.RequestEvent) error {
 	return func(e *core.RequestEvent) error {
 		var activeProj *templates.ActiveProject
+		user := GetCurrentUser(e.Request)
 
 		// Read cookie
 		cookie, err := e.Request.Cookie("active_project")
 		if err == nil && cookie.Value != "" {
 			rec, err := app.FindRecordById("projects", cookie.Value)
-			if err == nil {
+			if err == nil && CanAccessProject(user, rec.Id) {
 				activeProj = &templates.ActiveProject{
 					ID:   rec.Id,
 					Name: rec.GetString("name"),
 				}
 			} else {
-				log.Printf("middleware: active project %s not found, clearing cookie", cookie.Value)
+				log.Printf("middleware: active project %s not found or not accessible, clearing cookie", cookie.Value)
 				http.SetCookie(e.Response, &http.Cookie{
 					Name:   "active_project",
 					Value:  "",
@@ -69,12 +72,15 @@ func ActiveProjectMiddleware(app *pocketbase.PocketBase) func(e *core.RequestEve
 			}
 		}
 
-		// Build full project list for the header dropdown
+		// Build the project list for the header dropdown (members only see their projects)
 		projectsCol, _ := app.FindCollectionByNameOrId("projects")
 		var selectorItems []templates.ProjectSelectorItem
 		if projectsCol != nil {
 			records, _ := app.FindAllRecords(projectsCol)
 			for _, rec := range records {
+				if !CanAccessProject(user, rec.Id) {
+					continue
+				}
 				isActive := activeProj != nil && rec.Id == activeProj.ID
 				selectorItems = append(selectorItems, templates.ProjectSelectorItem{
 					ID:       rec.Id,
@@ -91,6 +97,13 @@ func ActiveProjectMiddleware(app *pocketbase.PocketBase) func(e *core.RequestEve
 			LogoURL:       collections.GetLogoURL(app),
 			CompanyName:   collections.GetCompanyName(app),
 		}
+		if user != nil {
+			headerData.CurrentUser = &templates.CurrentUser{
+				Name:  user.GetString("name"),
+				Email: user.Email(),
+				Role:  user.GetString("role"),
+			}
+		}
 
 		// Store in context
 		ctx := context.WithValue(e.Request.Context(), ActiveProjectKey, activeProj)
@@ -105,3 +118,15 @@ func ActiveProjectMiddleware(app *pocketbase.PocketBase) func(e *core.RequestEve
 		return e.Next()
 	}
 }
+
+// BudgetRefreshMiddleware defers the budget refresh that saving costs
+// triggers until the handler is done, so a document saved line by line
+// refreshes each project it touched once.
+func BudgetRefreshMiddleware(app *pocketbase.PocketBase) func(e *core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		ctx, flush := services.WithDeferredBudgetRefresh(e.Request.Context())
+		e.Request = e.Request.WithContext(ctx)
+		defer flush(app)
+		return e.Next()
+	}
+}
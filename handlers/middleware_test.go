@@ -151,3 +151,30 @@ func TestActiveProjectMiddleware_InvalidCookie(t *testing.T) {
 		t.Error("expected nil active project for invalid cookie")
 	}
 }
+
+func TestActiveProjectMiddleware_FiltersProjectsByMembership(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	member := testhelpers.CreateTestProject(t, app, "Member Project")
+	other := testhelpers.CreateTestProject(t, app, "Other Project")
+	user := testhelpers.CreateTestUser(t, app, "member@example.com", RoleLogistics, member.Id)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: "active_project", Value: other.Id})
+	req = req.WithContext(context.WithValue(req.Context(), CurrentUserKey, user))
+	rec := httptest.NewRecorder()
+	e := newTestRequestEvent(app, req, rec)
+
+	_ = ActiveProjectMiddleware(app)(e)
+
+	if GetActiveProject(e.Request) != nil {
+		t.Error("expected non-member active project cookie to be ignored")
+	}
+
+	headerData := GetHeaderData(e.Request)
+	if len(headerData.Projects) != 1 || headerData.Projects[0].ID != member.Id {
+		t.Errorf("expected only the member project in the selector, got %+v", headerData.Projects)
+	}
+	if headerData.CurrentUser == nil || headerData.CurrentUser.Role != RoleLogistics {
+		t.Error("expected current user in header data")
+	}
+}
@@ -198,7 +198,7 @@ func HandlePOSave(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
 		record.Set("warranty_terms", warrantyTerms)
 		record.Set("comments", comments)
 
-		if err := app.Save(record); err != nil {
+		if err := app.SaveWithContext(e.Request.Context(), record); err != nil {
 			log.Printf("po_create: could not save purchase order: %v", err)
 			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
 		}
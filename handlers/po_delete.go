@@ -29,7 +29,7 @@ func HandlePODelete(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
 				fmt.Sprintf("Cannot delete a %s purchase order", status))
 		}
 
-		if err := app.Delete(po); err != nil {
+		if err := app.DeleteWithContext(e.Request.Context(), po); err != nil {
 			log.Printf("Error deleting purchase order %s: %v", id, err)
 			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
 		}
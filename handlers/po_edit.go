@@ -73,7 +73,7 @@ func buildPOEditData(app *pocketbase.PocketBase, projectID, id string) (template
 		})
 	}
 
-	totals := services.CalcPOTotals(calcItems)
+	totals := services.CalcPOTotalsForTaxType(calcItems, services.POTaxType(app, record))
 
 	// Fetch address options
 	billToAddresses := fetchAddressesByType(app, projectID, "bill_from")
@@ -99,6 +99,7 @@ func buildPOEditData(app *pocketbase.PocketBase, projectID, id string) (template
 		Comments:        record.GetString("comments"),
 		LineItems:       lineItems,
 		TotalBeforeTax:  services.FormatINR(totals.TotalBeforeTax),
+		TaxRows:         poTaxRows(totals),
 		IGSTPercent:     fmt.Sprintf("%.2f", totals.IGSTPercent),
 		IGSTAmount:      services.FormatINR(totals.IGSTAmount),
 		RoundOff:        services.FormatINR(totals.RoundOff),
@@ -244,7 +245,7 @@ func HandlePOUpdate(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
 			record.Set("status", newStatus)
 		}
 
-		if err := app.Save(record); err != nil {
+		if err := app.SaveWithContext(e.Request.Context(), record); err != nil {
 			log.Printf("po_update: could not save PO %s: %v", id, err)
 			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
 		}
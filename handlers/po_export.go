@@ -36,6 +36,7 @@ func HandlePOExportPDF(app *pocketbase.PocketBase) func(*core.RequestEvent) erro
 			log.Printf("po_export: failed to build data: %v", err)
 			return e.String(http.StatusInternalServerError, "Failed to build PO data")
 		}
+		data.VerifyQR = documentVerifyQR(app, services.VerifyKindPO, id, "Purchase Order", data.PONumber, data.OrderDate, data.GrandTotal)
 
 		pdfBytes, err := services.GeneratePOPDF(data)
 		if err != nil {
@@ -9,6 +9,7 @@ import (
 	"github.com/pocketbase/pocketbase"
 	"github.com/pocketbase/pocketbase/core"
 
+	"projectcreation/services"
 	"projectcreation/templates"
 )
 
@@ -28,6 +29,22 @@ func getNextSortOrder(app *pocketbase.PocketBase, poId string) int {
 	return existing[0].GetInt("sort_order") + 1
 }
 
+// checkPOOverOrder returns the order balance of a BOQ item when qty exceeds
+// the quantity still to order, or nil when it fits. Line item
+// excludeLineItemID is not counted against the balance.
+func checkPOOverOrder(app *pocketbase.PocketBase, projectId, sourceItemType, sourceItemId, excludeLineItemID string, qty float64) *services.OrderBalance {
+	balances, err := services.ComputeOrderBalances(app, projectId, excludeLineItemID)
+	if err != nil {
+		log.Printf("po_line_items: could not compute order balances for project %s: %v", projectId, err)
+		return nil
+	}
+	b := balances[services.DispatchItemKey(sourceItemType, sourceItemId)]
+	if b == nil || qty <= b.Remaining() {
+		return nil
+	}
+	return b
+}
+
 // HandlePOAddLineItem handles POST /projects/:projectId/po/:id/line-items
 // Creates a manual line item for the given PO.
 func HandlePOAddLineItem(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
@@ -106,7 +123,7 @@ func HandlePOAddLineItem(app *pocketbase.PocketBase) func(*core.RequestEvent) er
 		record.Set("source_item_type", "manual")
 		record.Set("source_item_id", "")
 
-		if err := app.Save(record); err != nil {
+		if err := app.SaveWithContext(e.Request.Context(), record); err != nil {
 			log.Printf("po_line_items: HandlePOAddLineItem: could not save line item: %v", err)
 			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
 		}
@@ -175,6 +192,13 @@ func HandlePOAddLineItemFromBOQ(app *pocketbase.PocketBase) func(*core.RequestEv
 			rate = sourceRecord.GetFloat("unit_price")
 		}
 
+		// Ordering more than the BOQ quantity needs an explicit override
+		if e.Request.FormValue("override_boq_qty") != "true" {
+			if b := checkPOOverOrder(app, projectId, sourceItemType, sourceItemId, "", qty); b != nil {
+				return ErrorToast(e, http.StatusBadRequest, b.OverOrderMessage(qty))
+			}
+		}
+
 		nextSortOrder := getNextSortOrder(app, poId)
 
 		col, err := app.FindCollectionByNameOrId("po_line_items")
@@ -195,7 +219,7 @@ func HandlePOAddLineItemFromBOQ(app *pocketbase.PocketBase) func(*core.RequestEv
 		record.Set("source_item_type", sourceItemType)
 		record.Set("source_item_id", sourceItemId)
 
-		if err := app.Save(record); err != nil {
+		if err := app.SaveWithContext(e.Request.Context(), record); err != nil {
 			log.Printf("po_line_items: HandlePOAddLineItemFromBOQ: could not save line item: %v", err)
 			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
 		}
@@ -245,6 +269,19 @@ func HandlePOUpdateLineItem(app *pocketbase.PocketBase) func(*core.RequestEvent)
 		}
 		if v := strings.TrimSpace(e.Request.FormValue("qty")); v != "" {
 			if qty, err := strconv.ParseFloat(v, 64); err == nil {
+				sourceItemType := item.GetString("source_item_type")
+				if sourceItemType != "manual" && sourceItemType != "" && e.Request.FormValue("override_boq_qty") != "true" {
+					if b := checkPOOverOrder(app, projectId, sourceItemType, item.GetString("source_item_id"), itemId, qty); b != nil {
+						SetToast(e, "warning", "Quantity exceeds the BOQ balance")
+						data, buildErr := buildPOEditData(app, projectId, poId)
+						if buildErr != nil {
+							log.Printf("po_line_items: HandlePOUpdateLineItem: buildPOEditData failed: %v", buildErr)
+							return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
+						}
+						data.OverOrder = &templates.POOverOrder{ItemID: itemId, Qty: qty, Message: b.OverOrderMessage(qty)}
+						return templates.POLineItemsSection(data).Render(e.Request.Context(), e.Response)
+					}
+				}
 				item.Set("qty", qty)
 			}
 		}
@@ -259,7 +296,7 @@ func HandlePOUpdateLineItem(app *pocketbase.PocketBase) func(*core.RequestEvent)
 			}
 		}
 
-		if err := app.Save(item); err != nil {
+		if err := app.SaveWithContext(e.Request.Context(), item); err != nil {
 			log.Printf("po_line_items: HandlePOUpdateLineItem: could not save line item %s: %v", itemId, err)
 			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
 		}
@@ -293,7 +330,7 @@ func HandlePODeleteLineItem(app *pocketbase.PocketBase) func(*core.RequestEvent)
 			return ErrorToast(e, http.StatusForbidden, "Line item does not belong to this purchase order")
 		}
 
-		if err := app.Delete(item); err != nil {
+		if err := app.DeleteWithContext(e.Request.Context(), item); err != nil {
 			log.Printf("po_line_items: HandlePODeleteLineItem: could not delete line item %s: %v", itemId, err)
 			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
 		}
@@ -331,6 +368,20 @@ func HandlePOBOQPicker(app *pocketbase.PocketBase) func(*core.RequestEvent) erro
 			boqRecords = nil
 		}
 
+		balances, err := services.ComputeOrderBalances(app, projectId, "")
+		if err != nil {
+			log.Printf("po_line_items: HandlePOBOQPicker: could not compute order balances: %v", err)
+			balances = nil
+		}
+		// orderBalance returns the ordered and remaining quantities of a BOQ item
+		orderBalance := func(sourceItemType, id string) (float64, float64) {
+			b := balances[services.DispatchItemKey(sourceItemType, id)]
+			if b == nil {
+				return 0, 0
+			}
+			return b.Ordered, b.Remaining()
+		}
+
 		var boqs []templates.BOQPickerBOQ
 		for _, boqRec := range boqRecords {
 			boqId := boqRec.Id
@@ -387,6 +438,7 @@ func HandlePOBOQPicker(app *pocketbase.PocketBase) func(*core.RequestEvent) erro
 
 					var subSubItems []templates.BOQPickerSubSubItem
 					for _, ssiRec := range subSubItemRecords {
+						ordered, remaining := orderBalance("sub_sub_item", ssiRec.Id)
 						subSubItems = append(subSubItems, templates.BOQPickerSubSubItem{
 							ID:          ssiRec.Id,
 							Description: ssiRec.GetString("description"),
@@ -395,9 +447,12 @@ func HandlePOBOQPicker(app *pocketbase.PocketBase) func(*core.RequestEvent) erro
 							UoM:         ssiRec.GetString("uom"),
 							Rate:        ssiRec.GetFloat("unit_price"),
 							GSTPercent:  ssiRec.GetFloat("gst_percent"),
+							Ordered:     ordered,
+							Remaining:   remaining,
 						})
 					}
 
+					ordered, remaining := orderBalance("sub_item", siRec.Id)
 					subItems = append(subItems, templates.BOQPickerSubItem{
 						ID:          siRec.Id,
 						Description: siRec.GetString("description"),
@@ -406,10 +461,13 @@ func HandlePOBOQPicker(app *pocketbase.PocketBase) func(*core.RequestEvent) erro
 						UoM:         siRec.GetString("uom"),
 						Rate:        siRec.GetFloat("unit_price"),
 						GSTPercent:  siRec.GetFloat("gst_percent"),
+						Ordered:     ordered,
+						Remaining:   remaining,
 						SubSubItems: subSubItems,
 					})
 				}
 
+				ordered, remaining := orderBalance("main_item", miRec.Id)
 				mainItems = append(mainItems, templates.BOQPickerMainItem{
 					ID:          miRec.Id,
 					Description: miRec.GetString("description"),
@@ -418,6 +476,8 @@ func HandlePOBOQPicker(app *pocketbase.PocketBase) func(*core.RequestEvent) erro
 					UoM:         miRec.GetString("uom"),
 					Rate:        miRec.GetFloat("unit_price"),
 					GSTPercent:  miRec.GetFloat("gst_percent"),
+					Ordered:     ordered,
+					Remaining:   remaining,
 					SubItems:    subItems,
 				})
 			}
@@ -7,6 +7,9 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
 	"projectcreation/testhelpers"
 )
 
@@ -589,3 +592,165 @@ func TestHandlePOBOQPicker_EmptyBOQ(t *testing.T) {
 
 	testhelpers.AssertHTMLContains(t, rec.Body.String(), "No BOQ items found")
 }
+
+// ---------------------------------------------------------------------------
+// BOQ Order Balance Tests
+// ---------------------------------------------------------------------------
+
+// setBOQSource marks a line item as sourced from the given BOQ item.
+func setBOQSource(t *testing.T, app *pocketbase.PocketBase, item *core.Record, sourceType, sourceID string) {
+	t.Helper()
+	item.Set("source_item_type", sourceType)
+	item.Set("source_item_id", sourceID)
+	if err := app.Save(item); err != nil {
+		t.Fatalf("failed to set line item source: %v", err)
+	}
+}
+
+func postAddFromBOQ(t *testing.T, app *pocketbase.PocketBase, projectID, poID string, form url.Values) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/projects/"+projectID+"/po/"+poID+"/line-items/from-boq",
+		strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("HX-Request", "true")
+	req.SetPathValue("projectId", projectID)
+	req.SetPathValue("id", poID)
+	rec := httptest.NewRecorder()
+	if err := HandlePOAddLineItemFromBOQ(app)(newTestRequestEvent(app, req, rec)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	return rec
+}
+
+func TestHandlePOAddLineItemFromBOQ_BlocksOverOrder(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Test Project")
+	vendor := testhelpers.CreateTestVendor(t, app, "Test Vendor")
+	earlierPO := testhelpers.CreateTestPurchaseOrder(t, app, project.Id, vendor.Id, "FSS-PO-TEST/25-26/001")
+	po := testhelpers.CreateTestPurchaseOrder(t, app, project.Id, vendor.Id, "FSS-PO-TEST/25-26/002")
+	boq := testhelpers.CreateTestBOQ(t, app, project.Id, "Main BOQ")
+	mainItem := testhelpers.CreateTestMainBOQItem(t, app, boq.Id, "Transformer") // qty 10
+
+	// 4 of 10 already ordered; adding the full BOQ quantity again is too much
+	earlier := testhelpers.CreateTestPOLineItem(t, app, earlierPO.Id, 1, "Transformer", 4, 500, 18)
+	setBOQSource(t, app, earlier, "main_item", mainItem.Id)
+
+	form := url.Values{"source_item_type": {"main_item"}, "source_item_id": {mainItem.Id}}
+	rec := postAddFromBOQ(t, app, project.Id, po.Id, form)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", rec.Code)
+	}
+	testhelpers.AssertHTMLContains(t, rec.Body.String(), "Transformer: 10 exceeds the 6 still to order")
+	items, _ := app.FindRecordsByFilter("po_line_items", "purchase_order = {:poId}", "", 0, 0, map[string]any{"poId": po.Id})
+	if len(items) != 0 {
+		t.Fatalf("expected no line item to be added, got %d", len(items))
+	}
+
+	form.Set("override_boq_qty", "true")
+	rec = postAddFromBOQ(t, app, project.Id, po.Id, form)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200 with override, got %d", rec.Code)
+	}
+	items, _ = app.FindRecordsByFilter("po_line_items", "purchase_order = {:poId}", "", 0, 0, map[string]any{"poId": po.Id})
+	if len(items) != 1 || items[0].GetFloat("qty") != 10 {
+		t.Fatalf("expected one line item of qty 10 with override, got %d", len(items))
+	}
+}
+
+func TestHandlePOAddLineItemFromBOQ_IgnoresCancelledPOs(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Test Project")
+	vendor := testhelpers.CreateTestVendor(t, app, "Test Vendor")
+	cancelledPO := testhelpers.CreateTestPurchaseOrder(t, app, project.Id, vendor.Id, "FSS-PO-TEST/25-26/001")
+	cancelledPO.Set("status", "cancelled")
+	if err := app.Save(cancelledPO); err != nil {
+		t.Fatalf("failed to cancel PO: %v", err)
+	}
+	po := testhelpers.CreateTestPurchaseOrder(t, app, project.Id, vendor.Id, "FSS-PO-TEST/25-26/002")
+	boq := testhelpers.CreateTestBOQ(t, app, project.Id, "Main BOQ")
+	mainItem := testhelpers.CreateTestMainBOQItem(t, app, boq.Id, "Transformer")
+
+	cancelled := testhelpers.CreateTestPOLineItem(t, app, cancelledPO.Id, 1, "Transformer", 10, 500, 18)
+	setBOQSource(t, app, cancelled, "main_item", mainItem.Id)
+
+	rec := postAddFromBOQ(t, app, project.Id, po.Id, url.Values{"source_item_type": {"main_item"}, "source_item_id": {mainItem.Id}})
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rec.Code)
+	}
+}
+
+func TestHandlePOUpdateLineItem_BlocksOverOrder(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Test Project")
+	vendor := testhelpers.CreateTestVendor(t, app, "Test Vendor")
+	po := testhelpers.CreateTestPurchaseOrder(t, app, project.Id, vendor.Id, "FSS-PO-TEST/25-26/001")
+	boq := testhelpers.CreateTestBOQ(t, app, project.Id, "Main BOQ")
+	mainItem := testhelpers.CreateTestMainBOQItem(t, app, boq.Id, "Main Item")
+	subItem := testhelpers.CreateTestSubItem(t, app, mainItem.Id, "Cable Drum") // 5 × 10 = 50
+	lineItem := testhelpers.CreateTestPOLineItem(t, app, po.Id, 1, "Cable Drum", 20, 100, 18)
+	setBOQSource(t, app, lineItem, "sub_item", subItem.Id)
+
+	patch := func(form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPatch,
+			"/projects/"+project.Id+"/po/"+po.Id+"/line-items/"+lineItem.Id,
+			strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("HX-Request", "true")
+		req.SetPathValue("projectId", project.Id)
+		req.SetPathValue("id", po.Id)
+		req.SetPathValue("itemId", lineItem.Id)
+		rec := httptest.NewRecorder()
+		if err := HandlePOUpdateLineItem(app)(newTestRequestEvent(app, req, rec)); err != nil {
+			t.Fatalf("handler returned error: %v", err)
+		}
+		return rec
+	}
+
+	// The line's own quantity does not count against it
+	patch(url.Values{"qty": {"50"}})
+	if got, _ := app.FindRecordById("po_line_items", lineItem.Id); got.GetFloat("qty") != 50 {
+		t.Fatalf("expected qty 50 to be saved, got %v", got.GetFloat("qty"))
+	}
+
+	rec := patch(url.Values{"qty": {"60"}})
+	testhelpers.AssertHTMLContains(t, rec.Body.String(),
+		"QUANTITY NOT SAVED",
+		"Cable Drum: 60 exceeds the 50 still to order",
+		"override_boq_qty",
+	)
+	if got, _ := app.FindRecordById("po_line_items", lineItem.Id); got.GetFloat("qty") != 50 {
+		t.Errorf("expected qty to stay 50, got %v", got.GetFloat("qty"))
+	}
+
+	patch(url.Values{"qty": {"60"}, "override_boq_qty": {"true"}})
+	if got, _ := app.FindRecordById("po_line_items", lineItem.Id); got.GetFloat("qty") != 60 {
+		t.Errorf("expected qty 60 with override, got %v", got.GetFloat("qty"))
+	}
+}
+
+func TestHandlePOBOQPicker_ShowsRemainingToOrder(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Test Project")
+	vendor := testhelpers.CreateTestVendor(t, app, "Test Vendor")
+	po := testhelpers.CreateTestPurchaseOrder(t, app, project.Id, vendor.Id, "FSS-PO-TEST/25-26/001")
+	boq := testhelpers.CreateTestBOQ(t, app, project.Id, "Electrical Works")
+	mainItem := testhelpers.CreateTestMainBOQItem(t, app, boq.Id, "Panel Installation") // qty 10
+	lineItem := testhelpers.CreateTestPOLineItem(t, app, po.Id, 1, "Panel Installation", 7.5, 100, 18)
+	setBOQSource(t, app, lineItem, "main_item", mainItem.Id)
+
+	req := httptest.NewRequest(http.MethodGet, "/projects/"+project.Id+"/po/"+po.Id+"/boq-picker", nil)
+	req.Header.Set("HX-Request", "true")
+	req.SetPathValue("projectId", project.Id)
+	req.SetPathValue("id", po.Id)
+	rec := httptest.NewRecorder()
+	if err := HandlePOBOQPicker(app)(newTestRequestEvent(app, req, rec)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+
+	testhelpers.AssertHTMLContains(t, rec.Body.String(),
+		"TO ORDER",
+		`title="Ordered: 7.50"`,
+		"2.50",
+		"OVER-ORDER",
+	)
+}
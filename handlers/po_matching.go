@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"slices"
+
+	"github.com/a-h/templ"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/services"
+	"projectcreation/templates"
+)
+
+// HandlePOMatching renders the three-way matching report of a purchase
+// order: ordered quantity and rate, GRN accepted quantity, and what has
+// been invoiced, checked against the project's tolerance.
+func HandlePOMatching(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		projectId := e.Request.PathValue("projectId")
+		poID := e.Request.PathValue("id")
+
+		po, err := app.FindRecordById("purchase_orders", poID)
+		if err != nil || po.GetString("project") != projectId {
+			return ErrorToast(e, http.StatusNotFound, "Purchase order not found")
+		}
+
+		match, err := services.ComputeInvoiceMatch(app, po.Id, "")
+		if err != nil {
+			log.Printf("po_matching: could not compute match for PO %s: %v", po.Id, err)
+			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
+		}
+		invoices, err := app.FindRecordsByFilter("vendor_invoices", "purchase_order = {:poId}", "invoice_date", 0, 0, map[string]any{"poId": po.Id})
+		if err != nil {
+			log.Printf("po_matching: could not query vendor_invoices for PO %s: %v", po.Id, err)
+			invoices = nil
+		}
+
+		tol := services.ProjectInvoiceTolerance(app, projectId)
+		data := templates.POMatchingData{
+			ProjectID:  projectId,
+			POID:       po.Id,
+			PONumber:   po.GetString("po_number"),
+			POStatus:   po.GetString("status"),
+			RatePct:    formatQty(tol.RatePct),
+			QtyPct:     formatQty(tol.QtyPct),
+			Invoices:   vendorInvoiceListItems(app, invoices),
+			CanInvoice: slices.Contains(services.InvoiceableStatuses, po.GetString("status")),
+		}
+		if v, err := app.FindRecordById("vendors", po.GetString("vendor")); err == nil {
+			data.VendorName = v.GetString("name")
+		}
+
+		for _, m := range match {
+			line := templates.POMatchingLine{
+				Description: m.Description,
+				UOM:         m.UOM,
+				Ordered:     formatQty(m.OrderedQty),
+				PORate:      services.FormatINR(m.PORate),
+				POGST:       formatQty(m.POGSTPercent),
+				Accepted:    formatQty(m.AcceptedQty),
+				Invoiced:    formatQty(m.InvoicedQty),
+				Issues:      m.Issues(tol),
+			}
+			if m.InvoicedQty > 0 {
+				line.InvoicedRate = services.FormatINR(m.InvoicedRate())
+			}
+			if len(line.Issues) > 0 {
+				data.IssuesCount++
+			}
+			data.Lines = append(data.Lines, line)
+		}
+
+		var component templ.Component
+		if e.Request.Header.Get("HX-Request") == "true" {
+			component = templates.POMatchingContent(data)
+		} else {
+			headerData := GetHeaderData(e.Request)
+			sidebarData := GetSidebarData(e.Request)
+			component = templates.POMatchingPage(data, headerData, sidebarData)
+		}
+		return component.Render(e.Request.Context(), e.Response)
+	}
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"projectcreation/testhelpers"
+)
+
+func TestHandlePOMatching(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Matching Project")
+	po, line := createReceivedPO(t, app, project.Id, "PO-MATCH-001", 4, 2)
+	postVendorInvoice(t, app, project.Id, invoiceForm(po, line, "VI-M1", "3", "25000"))
+
+	req := httptest.NewRequest(http.MethodGet, "/projects/"+project.Id+"/po/"+po.Id+"/matching", nil)
+	req.SetPathValue("projectId", project.Id)
+	req.SetPathValue("id", po.Id)
+	rec := httptest.NewRecorder()
+	if err := HandlePOMatching(app)(newTestRequestEvent(app, req, rec)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rec.Code)
+	}
+	testhelpers.AssertHTMLContains(t, rec.Body.String(), "PO-MATCH-001", "Solar Inverter", "VI-M1", "MISMATCH", "exceeds the 2 accepted on GRNs")
+}
+
+func TestHandleVendorInvoiceView(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Invoice View Project")
+	po, line := createReceivedPO(t, app, project.Id, "PO-MATCH-002", 4, 4)
+	postVendorInvoice(t, app, project.Id, invoiceForm(po, line, "VI-V1", "4", "25000"))
+
+	invoices, err := app.FindAllRecords("vendor_invoices")
+	if err != nil || len(invoices) != 1 {
+		t.Fatalf("expected one invoice, got %d (%v)", len(invoices), err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/projects/"+project.Id+"/invoices/"+invoices[0].Id, nil)
+	req.SetPathValue("projectId", project.Id)
+	req.SetPathValue("id", invoices[0].Id)
+	rec := httptest.NewRecorder()
+	if err := HandleVendorInvoiceView(app)(newTestRequestEvent(app, req, rec)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rec.Code)
+	}
+	testhelpers.AssertHTMLContains(t, rec.Body.String(), "VI-V1", "PO-MATCH-002", "MATCHED", "GRAND TOTAL")
+
+	other := testhelpers.CreateTestProject(t, app, "Other Project")
+	req = httptest.NewRequest(http.MethodGet, "/projects/"+other.Id+"/invoices/"+invoices[0].Id, nil)
+	req.SetPathValue("projectId", other.Id)
+	req.SetPathValue("id", invoices[0].Id)
+	rec = httptest.NewRecorder()
+	if err := HandleVendorInvoiceView(app)(newTestRequestEvent(app, req, rec)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for another project's invoice, got %d", rec.Code)
+	}
+}
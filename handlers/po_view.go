@@ -142,7 +142,7 @@ func HandlePOView(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
 		}
 
 		// 8. Calculate order-level totals
-		totals := services.CalcPOTotals(calcItems)
+		totals := services.CalcPOTotalsForTaxType(calcItems, services.POTaxType(app, po))
 
 		// 9. Format date fields for display
 		orderDate := po.GetString("order_date")
@@ -168,6 +168,7 @@ func HandlePOView(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
 			ShipTo:         shipTo,
 			LineItems:      lineItems,
 			TotalBeforeTax: services.FormatINR(totals.TotalBeforeTax),
+			TaxRows:        poTaxRows(totals),
 			IGSTPercent:    fmt.Sprintf("%.2f", totals.IGSTPercent),
 			IGSTAmount:     services.FormatINR(totals.IGSTAmount),
 			RoundOff:       services.FormatINR(totals.RoundOff),
@@ -187,3 +188,15 @@ func HandlePOView(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
 		return component.Render(e.Request.Context(), e.Response)
 	}
 }
+
+// poTaxRows formats the CGST/SGST or IGST lines of a PO's totals for display.
+func poTaxRows(totals services.POTotals) []templates.POTaxRow {
+	var rows []templates.POTaxRow
+	for _, line := range totals.TaxLines() {
+		rows = append(rows, templates.POTaxRow{
+			Label:  line.Label,
+			Amount: services.FormatINR(line.Amount),
+		})
+	}
+	return rows
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"projectcreation/testhelpers"
@@ -252,3 +253,38 @@ func TestHandlePOView_BankDetails(t *testing.T) {
 		"Main Branch",
 	)
 }
+
+func TestHandlePOView_IntraStateTaxSplit(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Tax Split Project")
+	vendor := testhelpers.CreateTestVendor(t, app, "Local Vendor")
+	po := testhelpers.CreateTestPurchaseOrder(t, app, project.Id, vendor.Id, "PO-2026-TAX-001")
+	billTo := testhelpers.CreateTestAddress(t, app, project.Id, "bill_from", "Our Billing Company")
+	po.Set("bill_to_address", billTo.Id)
+	if err := app.Save(po); err != nil {
+		t.Fatalf("failed to save PO: %v", err)
+	}
+	testhelpers.CreateTestPOLineItem(t, app, po.Id, 1, "Steel Frame", 10, 1000, 18)
+	testhelpers.CreateTestPOLineItem(t, app, po.Id, 2, "Bolts", 100, 10, 12)
+
+	req := httptest.NewRequest(http.MethodGet, "/projects/"+project.Id+"/po/"+po.Id, nil)
+	req.SetPathValue("projectId", project.Id)
+	req.SetPathValue("id", po.Id)
+	rec := httptest.NewRecorder()
+	if err := HandlePOView(app)(newTestRequestEvent(app, req, rec)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+
+	body := rec.Body.String()
+	testhelpers.AssertHTMLContains(t, body,
+		"CGST @ 6%",
+		"SGST @ 6%",
+		"CGST @ 9%",
+		"SGST @ 9%",
+		"900.00",
+		"60.00",
+	)
+	if strings.Contains(body, "IGST @") {
+		t.Error("expected no IGST line for an intra-state PO")
+	}
+}
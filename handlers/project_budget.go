@@ -0,0 +1,261 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/a-h/templ"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/collections"
+	"projectcreation/services"
+	"projectcreation/templates"
+)
+
+// loadProjectBudget seeds any missing budgets of a project and returns its
+// budget variance with the open alerts.
+func loadProjectBudget(ctx context.Context, app *pocketbase.PocketBase, projectID string) (*services.BudgetVariance, []services.BudgetAlert, error) {
+	if _, err := collections.GetCostCategories(app); err != nil {
+		return nil, nil, err
+	}
+	if err := services.SeedProjectBudgets(ctx, app, projectID, false); err != nil {
+		return nil, nil, err
+	}
+	variance, err := services.BuildBudgetVariance(app, projectID)
+	if err != nil {
+		return nil, nil, err
+	}
+	alerts, err := services.OpenBudgetAlerts(app, projectID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return variance, alerts, nil
+}
+
+// newProjectBudgetData formats a budget variance and its alerts for the
+// budget page.
+func newProjectBudgetData(projectID string, v *services.BudgetVariance, alerts []services.BudgetAlert) templates.ProjectBudgetData {
+	data := templates.ProjectBudgetData{
+		ProjectID:   projectID,
+		TotalBudget: services.FormatINR(v.Total.Budget),
+		TotalSpent:  services.FormatINR(v.Total.Spent),
+		Variance:    services.FormatINR(v.Total.Variance()),
+		UsedPercent: fmt.Sprintf("%.1f%%", v.Total.UsedPercent()),
+		Errors:      make(map[string]string),
+	}
+	for _, l := range v.Lines {
+		data.Rows = append(data.Rows, templates.ProjectBudgetRow{
+			CategoryID:  l.CategoryID,
+			Name:        l.Name,
+			Budget:      formatQty(l.Budget),
+			Committed:   services.FormatINR(l.Committed),
+			Actual:      services.FormatINR(l.Actual),
+			Spent:       services.FormatINR(l.Spent),
+			Variance:    services.FormatINR(l.Variance()),
+			UsedPercent: fmt.Sprintf("%.1f%%", l.UsedPercent()),
+			Status:      l.Status(),
+		})
+	}
+	for _, a := range alerts {
+		data.Alerts = append(data.Alerts, templates.ProjectBudgetAlertRow{
+			ID:           a.ID,
+			CategoryName: a.CategoryName,
+			Threshold:    fmt.Sprintf("%.0f%%", a.Threshold),
+			Budget:       services.FormatINR(a.Budget),
+			Spent:        services.FormatINR(a.Spent),
+			Raised:       a.Raised,
+			Over:         a.Threshold >= 100,
+		})
+	}
+	return data
+}
+
+// renderProjectBudget renders the budget page as a partial or full page.
+func renderProjectBudget(e *core.RequestEvent, data templates.ProjectBudgetData) error {
+	var component templ.Component
+	if e.Request.Header.Get("HX-Request") == "true" {
+		component = templates.ProjectBudgetContent(data)
+	} else {
+		headerData := GetHeaderData(e.Request)
+		sidebarData := GetSidebarData(e.Request)
+		component = templates.ProjectBudgetPage(data, headerData, sidebarData)
+	}
+	return component.Render(e.Request.Context(), e.Response)
+}
+
+func redirectToProjectBudget(e *core.RequestEvent, projectId string) error {
+	redirectURL := fmt.Sprintf("/projects/%s/budget", projectId)
+	if e.Request.Header.Get("HX-Request") == "true" {
+		e.Response.Header().Set("HX-Redirect", redirectURL)
+		return e.String(http.StatusOK, "")
+	}
+	return e.Redirect(http.StatusFound, redirectURL)
+}
+
+// HandleProjectBudget renders the project's budget against its costs by
+// cost category. Budgets are seeded from the BOQ the first time.
+func HandleProjectBudget(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		projectID := e.Request.PathValue("projectId")
+		if _, err := app.FindRecordById("projects", projectID); err != nil {
+			return ErrorToast(e, http.StatusNotFound, "Project not found")
+		}
+
+		variance, alerts, err := loadProjectBudget(e.Request.Context(), app, projectID)
+		if err != nil {
+			log.Printf("project_budget: could not load budget for project %s: %v", projectID, err)
+			return ErrorToast(e, http.StatusInternalServerError, "Could not load the project budget")
+		}
+		return renderProjectBudget(e, newProjectBudgetData(projectID, variance, alerts))
+	}
+}
+
+// HandleProjectBudgetSave handles the POST of the budget amounts, one
+// budget_{categoryId} field per cost category.
+func HandleProjectBudgetSave(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		if err := e.Request.ParseForm(); err != nil {
+			return ErrorToast(e, http.StatusBadRequest, "Invalid form data")
+		}
+		projectID := e.Request.PathValue("projectId")
+		if _, err := app.FindRecordById("projects", projectID); err != nil {
+			return ErrorToast(e, http.StatusNotFound, "Project not found")
+		}
+
+		variance, alerts, err := loadProjectBudget(e.Request.Context(), app, projectID)
+		if err != nil {
+			log.Printf("project_budget_save: could not load budget for project %s: %v", projectID, err)
+			return ErrorToast(e, http.StatusInternalServerError, "Could not load the project budget")
+		}
+
+		data := newProjectBudgetData(projectID, variance, alerts)
+		amounts := make(map[string]float64, len(variance.Lines))
+		for i, l := range variance.Lines {
+			raw := strings.TrimSpace(e.Request.FormValue("budget_" + l.CategoryID))
+			data.Rows[i].Budget = raw
+			amount, err := strconv.ParseFloat(raw, 64)
+			if raw == "" {
+				amount, err = 0, nil
+			}
+			if err != nil || amount < 0 {
+				data.Errors[l.CategoryID] = l.Name + ": budget must be a positive amount"
+				continue
+			}
+			amounts[l.CategoryID] = amount
+		}
+		if len(data.Errors) > 0 {
+			SetToast(e, "warning", "Please fix the errors below")
+			return renderProjectBudget(e, data)
+		}
+
+		col, err := app.FindCollectionByNameOrId("project_budgets")
+		if err != nil {
+			log.Printf("project_budget_save: project_budgets collection not found: %v", err)
+			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
+		}
+		err = app.RunInTransaction(func(txApp core.App) error {
+			for _, l := range variance.Lines {
+				budget := core.NewRecord(col)
+				if l.BudgetID != "" {
+					existing, err := txApp.FindRecordById("project_budgets", l.BudgetID)
+					if err != nil {
+						return err
+					}
+					if existing.GetFloat("amount") == amounts[l.CategoryID] {
+						continue
+					}
+					budget = existing
+				} else {
+					budget.Set("project", projectID)
+					budget.Set("cost_category", l.CategoryID)
+				}
+				budget.Set("amount", amounts[l.CategoryID])
+				if err := txApp.SaveWithContext(e.Request.Context(), budget); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			log.Printf("project_budget_save: could not save budgets for project %s: %v", projectID, err)
+			return ErrorToast(e, http.StatusInternalServerError, "Could not save the budgets")
+		}
+
+		SetToast(e, "success", "Budgets saved")
+		return redirectToProjectBudget(e, projectID)
+	}
+}
+
+// HandleProjectBudgetReset sets the material and labour budgets back to
+// the BOQ budgeted value.
+func HandleProjectBudgetReset(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		projectID := e.Request.PathValue("projectId")
+		if _, err := app.FindRecordById("projects", projectID); err != nil {
+			return ErrorToast(e, http.StatusNotFound, "Project not found")
+		}
+		if _, err := collections.GetCostCategories(app); err != nil {
+			log.Printf("project_budget_reset: could not load cost categories: %v", err)
+			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
+		}
+		if err := services.SeedProjectBudgets(e.Request.Context(), app, projectID, true); err != nil {
+			log.Printf("project_budget_reset: could not reset budgets for project %s: %v", projectID, err)
+			return ErrorToast(e, http.StatusInternalServerError, "Could not reset the budgets")
+		}
+
+		SetToast(e, "success", "Budgets reset from the BOQ")
+		return redirectToProjectBudget(e, projectID)
+	}
+}
+
+// HandleBudgetAlertDismiss dismisses a budget alert of the project.
+func HandleBudgetAlertDismiss(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		projectID := e.Request.PathValue("projectId")
+		alert, err := app.FindRecordById("budget_alerts", e.Request.PathValue("id"))
+		if err != nil || alert.GetString("project") != projectID {
+			return ErrorToast(e, http.StatusNotFound, "Alert not found")
+		}
+
+		alert.Set("dismissed", true)
+		if err := app.Save(alert); err != nil {
+			log.Printf("project_budget: could not dismiss alert %s: %v", alert.Id, err)
+			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
+		}
+		return redirectToProjectBudget(e, projectID)
+	}
+}
+
+// HandleProjectBudgetExportExcel generates and downloads the project's
+// budget variance report as an Excel file.
+func HandleProjectBudgetExportExcel(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		projectID := e.Request.PathValue("projectId")
+
+		variance, err := services.BuildBudgetVariance(app, projectID)
+		if err != nil {
+			log.Printf("project_budget_export: could not build variance for project %s: %v", projectID, err)
+			return e.String(http.StatusNotFound, "Project not found")
+		}
+
+		xlsxBytes, err := services.GenerateBudgetVarianceExcel(variance)
+		if err != nil {
+			log.Printf("project_budget_export: generate failed: %v", err)
+			return e.String(http.StatusInternalServerError, "Failed to generate Excel file")
+		}
+
+		filename := fmt.Sprintf("%s_Budget.xlsx", sanitizeFilename(variance.ProjectName))
+
+		e.Response.Header().Set("Content-Type",
+			"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		e.Response.Header().Set("Content-Disposition",
+			fmt.Sprintf(`attachment; filename="%s"`, filename))
+		e.Response.Write(xlsxBytes)
+		return nil
+	}
+}
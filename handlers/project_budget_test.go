@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"projectcreation/testhelpers"
+)
+
+func TestHandleProjectBudget_SeedsAndSaves(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project, _, _, _ := createMilestoneProject(t, app)
+
+	req := httptest.NewRequest(http.MethodGet, "/projects/"+project.Id+"/budget", nil)
+	req.SetPathValue("projectId", project.Id)
+	req.Header.Set("HX-Request", "true")
+	rec := httptest.NewRecorder()
+	if err := HandleProjectBudget(app)(newTestRequestEvent(app, req, rec)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	testhelpers.AssertHTMLContains(t, rec.Body.String(), "Material", "Labour &amp; services", "Site expenses", "SAVE BUDGETS")
+
+	material, err := app.FindFirstRecordByFilter("project_budgets", "project = {:pid} && cost_category.code = 'material'", map[string]any{"pid": project.Id})
+	if err != nil {
+		t.Fatalf("expected the material budget to be seeded: %v", err)
+	}
+	categoryID := material.GetString("cost_category")
+	pathValues := map[string]string{"projectId": project.Id}
+
+	// A negative amount is rejected and nothing is saved
+	rec = postHXForm(t, app, HandleProjectBudgetSave, "/projects/"+project.Id+"/budget", pathValues, url.Values{"budget_" + categoryID: {"-5"}})
+	testhelpers.AssertHTMLContains(t, rec.Body.String(), "budget must be a positive amount")
+
+	rec = postHXForm(t, app, HandleProjectBudgetSave, "/projects/"+project.Id+"/budget", pathValues, url.Values{"budget_" + categoryID: {"45000"}})
+	testhelpers.AssertHXRedirect(t, rec.Header().Get("HX-Redirect"), "/projects/"+project.Id+"/budget")
+	if material, _ = app.FindRecordById("project_budgets", material.Id); material.GetFloat("amount") != 45000 {
+		t.Errorf("expected the material budget saved as 45,000, got %.2f", material.GetFloat("amount"))
+	}
+}
+
+func TestHandleCostCategorySettingsSave_AddsCategory(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+
+	rec := postHXForm(t, app, HandleCostCategorySettingsSave, "/settings/cost-categories", nil, url.Values{"new_name": {"Tools & tackles"}})
+	// Every listed category needs a name, so an empty form is rejected
+	testhelpers.AssertHTMLContains(t, rec.Body.String(), "name is required")
+
+	form := url.Values{"new_name": {"Tools & tackles"}}
+	categories, _ := app.FindAllRecords("cost_categories")
+	for _, c := range categories {
+		form.Set("name_"+c.Id, c.GetString("name"))
+		form.Set("active_"+c.Id, "true")
+	}
+	rec = postHXForm(t, app, HandleCostCategorySettingsSave, "/settings/cost-categories", nil, form)
+	testhelpers.AssertHXRedirect(t, rec.Header().Get("HX-Redirect"), "/settings/cost-categories")
+
+	added, err := app.FindFirstRecordByData("cost_categories", "code", "tools_tackles")
+	if err != nil {
+		t.Fatalf("expected the new category to be saved: %v", err)
+	}
+	if added.GetString("name") != "Tools & tackles" || !added.GetBool("active") {
+		t.Errorf("unexpected new category %q active=%v", added.GetString("name"), added.GetBool("active"))
+	}
+}
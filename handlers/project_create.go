@@ -100,7 +100,7 @@ func HandleProjectSave(app *pocketbase.PocketBase) func(*core.RequestEvent) erro
 		record.Set("status", status)
 		record.Set("ship_to_equals_install_at", shipToEqualsInstallAt)
 
-		if err := app.Save(record); err != nil {
+		if err := app.SaveWithContext(e.Request.Context(), record); err != nil {
 			log.Printf("project_create: could not save project: %v", err)
 			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
 		}
@@ -109,6 +109,14 @@ func HandleProjectSave(app *pocketbase.PocketBase) func(*core.RequestEvent) erro
 			log.Printf("project_create: failed to create default address settings: %v", err)
 		}
 
+		// Non-admins only see projects they belong to, so make the creator a member
+		if user := GetCurrentUser(e.Request); user != nil && !HasRole(user, RoleAdmin) {
+			user.Set("projects+", record.Id)
+			if err := app.SaveWithContext(e.Request.Context(), user); err != nil {
+				log.Printf("project_create: could not add project %s to user %s: %v", record.Id, user.Id, err)
+			}
+		}
+
 		SetToast(e, "success", "Project created successfully")
 
 		if e.Request.Header.Get("HX-Request") == "true" {
@@ -41,18 +41,18 @@ func HandleProjectDelete(app *pocketbase.PocketBase) func(*core.RequestEvent) er
 
 		for _, boq := range boqs {
 			if deleteBoqs {
-				if err := app.Delete(boq); err != nil {
+				if err := app.DeleteWithContext(e.Request.Context(), boq); err != nil {
 					log.Printf("project_delete: failed to delete BOQ %s: %v", boq.Id, err)
 				}
 			} else {
 				boq.Set("project", "")
-				if err := app.Save(boq); err != nil {
+				if err := app.SaveWithContext(e.Request.Context(), boq); err != nil {
 					log.Printf("project_delete: failed to unlink BOQ %s: %v", boq.Id, err)
 				}
 			}
 		}
 
-		if err := app.Delete(projectRecord); err != nil {
+		if err := app.DeleteWithContext(e.Request.Context(), projectRecord); err != nil {
 			log.Printf("project_delete: failed to delete project %s: %v", projectID, err)
 			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
 		}
@@ -171,7 +171,7 @@ func HandleProjectUpdate(app *pocketbase.PocketBase) func(*core.RequestEvent) er
 		record.Set("status", status)
 		record.Set("ship_to_equals_install_at", shipToEqualsInstallAt)
 
-		if err := app.Save(record); err != nil {
+		if err := app.SaveWithContext(e.Request.Context(), record); err != nil {
 			log.Printf("project_update: could not save project %s: %v", projectID, err)
 			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
 		}
@@ -48,8 +48,12 @@ func HandleProjectList(app *pocketbase.PocketBase) func(*core.RequestEvent) erro
 
 		var items []templates.ProjectListItem
 
+		user := GetCurrentUser(e.Request)
 		for _, rec := range records {
 			projectID := rec.Id
+			if !CanAccessProject(user, projectID) {
+				continue
+			}
 
 			boqs, err := app.FindRecordsByFilter(
 				boqsCol,
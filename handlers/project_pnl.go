@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/a-h/templ"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/collections"
+	"projectcreation/services"
+	"projectcreation/templates"
+)
+
+// pnlRow formats a P&L line for display.
+func pnlRow(l services.PnLLine) templates.ProjectPnLRow {
+	return templates.ProjectPnLRow{
+		Label:     l.Label,
+		Quoted:    services.FormatINR(l.Quoted),
+		Invoiced:  services.FormatINR(l.Invoiced),
+		Committed: services.FormatINR(l.Committed),
+		Actual:    services.FormatINR(l.Actual),
+		Cost:      services.FormatINR(l.Cost),
+		Margin:    services.FormatINR(l.Margin()),
+		MarginPct: fmt.Sprintf("%.1f%%", l.MarginPercent()),
+		Loss:      l.Margin() < 0,
+	}
+}
+
+// HandleProjectPnL renders a project's profit and loss by BOQ main item
+// and by cost category.
+func HandleProjectPnL(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		projectID := e.Request.PathValue("projectId")
+
+		pnl, err := services.BuildProjectPnL(app, projectID)
+		if err != nil {
+			log.Printf("project_pnl: could not build P&L for project %s: %v", projectID, err)
+			return ErrorToast(e, http.StatusNotFound, "Project not found")
+		}
+
+		data := templates.ProjectPnLData{
+			ProjectID: projectID,
+			Quoted:    services.FormatINR(pnl.Total.Quoted),
+			Invoiced:  services.FormatINR(pnl.Total.Invoiced),
+			Committed: services.FormatINR(pnl.Total.Committed),
+			Actual:    services.FormatINR(pnl.Total.Actual),
+			Margin:    services.FormatINR(pnl.Total.Margin()),
+			MarginPct: fmt.Sprintf("%.1f%%", pnl.Total.MarginPercent()),
+			Total:     pnlRow(pnl.Total),
+		}
+		for _, l := range pnl.ByMainItem {
+			data.Rows = append(data.Rows, pnlRow(l))
+		}
+		for _, l := range pnl.ByCategory {
+			share := 0.0
+			if pnl.Total.Cost != 0 {
+				share = l.Cost / pnl.Total.Cost * 100
+			}
+			data.Categories = append(data.Categories, templates.ProjectPnLCategoryRow{
+				Label:     l.Label,
+				Committed: services.FormatINR(l.Committed),
+				Actual:    services.FormatINR(l.Actual),
+				Cost:      services.FormatINR(l.Cost),
+				Share:     fmt.Sprintf("%.1f%%", share),
+			})
+		}
+
+		var component templ.Component
+		if e.Request.Header.Get("HX-Request") == "true" {
+			component = templates.ProjectPnLContent(data)
+		} else {
+			headerData := GetHeaderData(e.Request)
+			sidebarData := GetSidebarData(e.Request)
+			component = templates.ProjectPnLPage(data, headerData, sidebarData)
+		}
+		return component.Render(e.Request.Context(), e.Response)
+	}
+}
+
+// HandleProjectPnLExportPDF generates and downloads a project's P&L as a
+// PDF file.
+func HandleProjectPnLExportPDF(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		projectID := e.Request.PathValue("projectId")
+
+		pnl, err := services.BuildProjectPnL(app, projectID)
+		if err != nil {
+			log.Printf("project_pnl_export: could not build P&L for project %s: %v", projectID, err)
+			return e.String(http.StatusNotFound, "Project not found")
+		}
+
+		pdfBytes, err := services.GenerateProjectPnLPDF(pnl, collections.GetCompanyName(app))
+		if err != nil {
+			log.Printf("project_pnl_export: PDF generate failed: %v", err)
+			return e.String(http.StatusInternalServerError, "Failed to generate PDF")
+		}
+
+		filename := fmt.Sprintf("%s_PnL.pdf", sanitizeFilename(pnl.ProjectName))
+
+		e.Response.Header().Set("Content-Type", "application/pdf")
+		e.Response.Header().Set("Content-Disposition",
+			fmt.Sprintf(`attachment; filename="%s"`, filename))
+		e.Response.Write(pdfBytes)
+		return nil
+	}
+}
+
+// HandleProjectPnLExportExcel generates and downloads a project's P&L as
+// an Excel file.
+func HandleProjectPnLExportExcel(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		projectID := e.Request.PathValue("projectId")
+
+		pnl, err := services.BuildProjectPnL(app, projectID)
+		if err != nil {
+			log.Printf("project_pnl_export: could not build P&L for project %s: %v", projectID, err)
+			return e.String(http.StatusNotFound, "Project not found")
+		}
+
+		xlsxBytes, err := services.GenerateProjectPnLExcel(pnl)
+		if err != nil {
+			log.Printf("project_pnl_export: Excel generate failed: %v", err)
+			return e.String(http.StatusInternalServerError, "Failed to generate Excel file")
+		}
+
+		filename := fmt.Sprintf("%s_PnL.xlsx", sanitizeFilename(pnl.ProjectName))
+
+		e.Response.Header().Set("Content-Type",
+			"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		e.Response.Header().Set("Content-Disposition",
+			fmt.Sprintf(`attachment; filename="%s"`, filename))
+		e.Response.Write(xlsxBytes)
+		return nil
+	}
+}
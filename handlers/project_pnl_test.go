@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"projectcreation/testhelpers"
+)
+
+func TestHandleProjectPnL_ShowsMargin(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project, _, _, _ := createMilestoneProject(t, app)
+	vendor := testhelpers.CreateTestVendor(t, app, "PnL Vendor")
+	po := testhelpers.CreateTestPurchaseOrder(t, app, project.Id, vendor.Id, "PO-1")
+	testhelpers.CreateTestPOLineItem(t, app, po.Id, 1, "Freight", 1, 25000, 18)
+
+	req := httptest.NewRequest(http.MethodGet, "/projects/"+project.Id+"/pnl", nil)
+	req.SetPathValue("projectId", project.Id)
+	req.Header.Set("HX-Request", "true")
+	rec := httptest.NewRecorder()
+	if err := HandleProjectPnL(app)(newTestRequestEvent(app, req, rec)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+
+	// 1,00,000 quoted against 25,000 committed on a manual PO line
+	testhelpers.AssertHTMLContains(t, rec.Body.String(), "Solar plant", "1,00,000", "25,000", "75,000", "75.0%", "Not itemised")
+}
+
+func TestHandleProjectPnLExportExcel(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project, _, _, _ := createMilestoneProject(t, app)
+
+	req := httptest.NewRequest(http.MethodGet, "/projects/"+project.Id+"/pnl/export/excel", nil)
+	req.SetPathValue("projectId", project.Id)
+	rec := httptest.NewRecorder()
+	if err := HandleProjectPnLExportExcel(app)(newTestRequestEvent(app, req, rec)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+
+	if ct := rec.Header().Get("Content-Type"); ct != "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet" {
+		t.Errorf("unexpected content type %q", ct)
+	}
+	if cd := rec.Header().Get("Content-Disposition"); cd != `attachment; filename="Milestone-Project_PnL.xlsx"` {
+		t.Errorf("unexpected content disposition %q", cd)
+	}
+}
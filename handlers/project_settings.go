@@ -141,6 +141,11 @@ func HandleProjectSettings(app *pocketbase.PocketBase) func(*core.RequestEvent)
 		data.BillFromAddresses = fetchDefaultAddressOptions(app, projectID, "bill_from")
 		data.DispatchFromAddresses = fetchDefaultAddressOptions(app, projectID, "dispatch_from")
 
+		// Invoice matching tolerance
+		tol := services.ProjectInvoiceTolerance(app, projectID)
+		data.InvoiceQtyTolerance = formatQty(tol.QtyPct)
+		data.InvoiceRateTolerance = formatQty(tol.RatePct)
+
 		var component templ.Component
 		if e.Request.Header.Get("HX-Request") == "true" {
 			component = templates.ProjectSettingsContent(data)
@@ -216,7 +221,11 @@ func HandleProjectSettingsSave(app *pocketbase.PocketBase) func(*core.RequestEve
 		project.Set("default_bill_from", e.Request.FormValue("default_bill_from"))
 		project.Set("default_dispatch_from", e.Request.FormValue("default_dispatch_from"))
 
-		if err := app.Save(project); err != nil {
+		// Save invoice matching tolerance
+		project.Set("invoice_qty_tolerance_pct", formPercent(e.Request.FormValue("invoice_qty_tolerance_pct")))
+		project.Set("invoice_rate_tolerance_pct", formPercent(e.Request.FormValue("invoice_rate_tolerance_pct")))
+
+		if err := app.SaveWithContext(e.Request.Context(), project); err != nil {
 			log.Printf("project_settings_save: failed to save project fields: %v", err)
 			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
 		}
@@ -286,6 +295,16 @@ func formInt(s string, defaultVal int) int {
 	return v
 }
 
+// formPercent parses a form value as a percentage, returning 0 if empty,
+// invalid or negative.
+func formPercent(s string) float64 {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || v < 0 {
+		return 0
+	}
+	return v
+}
+
 // fetchDefaultAddressOptions returns address select items for the default address pickers.
 func fetchDefaultAddressOptions(app *pocketbase.PocketBase, projectID, addressType string) []templates.DefaultAddressOption {
 	// For dispatch_from, the DB type might be "ship_from" (legacy) — handle both
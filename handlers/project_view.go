@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"fmt"
 	"log"
 	"net/http"
 
@@ -8,6 +9,7 @@ import (
 	"github.com/pocketbase/pocketbase"
 	"github.com/pocketbase/pocketbase/core"
 
+	"projectcreation/services"
 	"projectcreation/templates"
 )
 
@@ -67,6 +69,16 @@ func HandleProjectView(app *pocketbase.PocketBase) func(*core.RequestEvent) erro
 			createdDate = dt.Time().Format("02 Jan 2006")
 		}
 
+		alerts, err := services.OpenBudgetAlerts(app, projectID)
+		if err != nil {
+			log.Printf("project_view: could not load budget alerts for project %s: %v", projectID, err)
+		}
+		var budgetAlerts []string
+		for _, a := range alerts {
+			budgetAlerts = append(budgetAlerts, fmt.Sprintf("%s has crossed %.0f%% of its budget: %s spent of %s",
+				a.CategoryName, a.Threshold, services.FormatINR(a.Spent), services.FormatINR(a.Budget)))
+		}
+
 		data := templates.ProjectViewData{
 			ID:                    projectID,
 			Name:                  record.GetString("name"),
@@ -77,6 +89,7 @@ func HandleProjectView(app *pocketbase.PocketBase) func(*core.RequestEvent) erro
 			BOQCount:              len(boqs),
 			AddressCount:          addressCount,
 			CreatedDate:           createdDate,
+			BudgetAlerts:          budgetAlerts,
 		}
 
 		var component templ.Component
@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/services"
+	"projectcreation/templates"
+)
+
+// HandleProjectReceivables renders the project's client invoices with what
+// has been received, what is due and the retention still held.
+func HandleProjectReceivables(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		project, err := app.FindRecordById("projects", e.Request.PathValue("projectId"))
+		if err != nil {
+			return ErrorToast(e, http.StatusNotFound, "Project not found")
+		}
+
+		balances, err := services.ClientInvoiceBalances(app, project.Id)
+		if err != nil {
+			log.Printf("receivables: could not load invoices for project %s: %v", project.Id, err)
+			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
+		}
+
+		data := templates.ProjectReceivablesData{ProjectID: project.Id}
+		var invoiced, received, due, held float64
+		now := time.Now()
+		for _, b := range balances {
+			invoiced += b.Total
+			received += b.Received
+			due += b.Due()
+			held += b.RetentionHeld()
+			data.Invoices = append(data.Invoices, templates.ReceivableRow{
+				InvoiceID:     b.InvoiceID,
+				InvoiceNumber: b.InvoiceNumber,
+				InvoiceDate:   b.InvoiceDate,
+				BuyerName:     b.BuyerName,
+				AgeDays:       services.InvoiceAgeDays(b.InvoiceDate, now),
+				Total:         services.FormatINR(b.Total),
+				Received:      services.FormatINR(b.Received),
+				Due:           services.FormatINR(b.Due()),
+				Retention:     services.FormatINR(b.RetentionHeld()),
+			})
+		}
+		data.TotalInvoiced = services.FormatINR(invoiced)
+		data.TotalReceived = services.FormatINR(received)
+		data.TotalDue = services.FormatINR(due)
+		data.TotalHeld = services.FormatINR(held)
+
+		var component templ.Component
+		if e.Request.Header.Get("HX-Request") == "true" {
+			component = templates.ProjectReceivablesContent(data)
+		} else {
+			headerData := GetHeaderData(e.Request)
+			sidebarData := GetSidebarData(e.Request)
+			component = templates.ProjectReceivablesPage(data, headerData, sidebarData)
+		}
+		return component.Render(e.Request.Context(), e.Response)
+	}
+}
+
+// outstandingClientInvoices returns the project's client invoices that still
+// have something left to receive, oldest first.
+func outstandingClientInvoices(app *pocketbase.PocketBase, projectId string) ([]services.ClientInvoiceBalance, error) {
+	balances, err := services.ClientInvoiceBalances(app, projectId)
+	if err != nil {
+		return nil, err
+	}
+	var outstanding []services.ClientInvoiceBalance
+	for _, b := range balances {
+		if b.Outstanding() > 0.005 {
+			outstanding = append(outstanding, b)
+		}
+	}
+	return outstanding, nil
+}
+
+// newClientReceiptCreateData builds the receipt form for a project. amounts
+// holds what has been entered against each invoice, keyed by invoice ID.
+func newClientReceiptCreateData(projectId string, balances []services.ClientInvoiceBalance, amounts map[string]string) templates.ClientReceiptCreateData {
+	data := templates.ClientReceiptCreateData{
+		ProjectID: projectId,
+		Errors:    make(map[string]string),
+	}
+	for _, mode := range services.PaymentModes {
+		data.Modes = append(data.Modes, templates.PaymentModeOption{
+			Value: mode,
+			Label: services.PaymentModeLabel(mode),
+		})
+	}
+	for _, b := range balances {
+		data.Invoices = append(data.Invoices, templates.ClientReceiptInvoiceRow{
+			InvoiceID:     b.InvoiceID,
+			InvoiceNumber: b.InvoiceNumber,
+			InvoiceDate:   b.InvoiceDate,
+			Total:         services.FormatINR(b.Total),
+			Received:      services.FormatINR(b.Received),
+			Outstanding:   services.FormatINR(b.Outstanding()),
+			Retention:     services.FormatINR(b.RetentionHeld()),
+			Amount:        amounts[b.InvoiceID],
+		})
+	}
+	return data
+}
+
+// renderClientReceiptCreate renders the receipt form as a partial or full page.
+func renderClientReceiptCreate(e *core.RequestEvent, data templates.ClientReceiptCreateData) error {
+	var component templ.Component
+	if e.Request.Header.Get("HX-Request") == "true" {
+		component = templates.ClientReceiptCreateContent(data)
+	} else {
+		headerData := GetHeaderData(e.Request)
+		sidebarData := GetSidebarData(e.Request)
+		component = templates.ClientReceiptCreatePage(data, headerData, sidebarData)
+	}
+	return component.Render(e.Request.Context(), e.Response)
+}
+
+// HandleClientReceiptCreate renders the receipt form with the project's
+// outstanding client invoices.
+func HandleClientReceiptCreate(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		project, err := app.FindRecordById("projects", e.Request.PathValue("projectId"))
+		if err != nil {
+			return ErrorToast(e, http.StatusNotFound, "Project not found")
+		}
+
+		balances, err := outstandingClientInvoices(app, project.Id)
+		if err != nil {
+			log.Printf("receivables: could not load invoices for project %s: %v", project.Id, err)
+			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
+		}
+
+		data := newClientReceiptCreateData(project.Id, balances, nil)
+		data.ReceiptDate = time.Now().Format("2006-01-02")
+		data.Mode = "neft"
+		return renderClientReceiptCreate(e, data)
+	}
+}
+
+// HandleClientReceiptSave records a client receipt from the submitted form
+// and redirects to the project's receivables.
+func HandleClientReceiptSave(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		if err := e.Request.ParseForm(); err != nil {
+			return ErrorToast(e, http.StatusBadRequest, "Invalid form data")
+		}
+
+		project, err := app.FindRecordById("projects", e.Request.PathValue("projectId"))
+		if err != nil {
+			return ErrorToast(e, http.StatusNotFound, "Project not found")
+		}
+
+		list, err := outstandingClientInvoices(app, project.Id)
+		if err != nil {
+			log.Printf("receivables: could not load invoices for project %s: %v", project.Id, err)
+			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
+		}
+
+		params := services.ClientReceiptParams{
+			ProjectID:   project.Id,
+			ReceiptDate: strings.TrimSpace(e.Request.FormValue("receipt_date")),
+			Mode:        e.Request.FormValue("mode"),
+			Reference:   strings.TrimSpace(e.Request.FormValue("reference")),
+			Remarks:     strings.TrimSpace(e.Request.FormValue("remarks")),
+		}
+		balances := make(map[string]services.ClientInvoiceBalance, len(list))
+		amounts := make(map[string]string, len(list))
+		for _, b := range list {
+			balances[b.InvoiceID] = b
+			raw := strings.TrimSpace(e.Request.FormValue("alloc_" + b.InvoiceID))
+			amounts[b.InvoiceID] = raw
+			amount, _ := strconv.ParseFloat(raw, 64)
+			params.Allocations = append(params.Allocations, services.ReceiptAllocationParams{
+				ClientInvoiceID: b.InvoiceID,
+				Amount:          amount,
+			})
+		}
+
+		if errors := services.ValidateClientReceipt(params, balances); len(errors) > 0 {
+			SetToast(e, "warning", "Please fix the errors below")
+			data := newClientReceiptCreateData(project.Id, list, amounts)
+			data.ReceiptDate = params.ReceiptDate
+			data.Mode = params.Mode
+			data.Reference = params.Reference
+			data.Remarks = params.Remarks
+			data.Errors = errors
+			return renderClientReceiptCreate(e, data)
+		}
+
+		result, err := services.CreateClientReceipt(e.Request.Context(), app, params)
+		if err != nil {
+			log.Printf("receivables: could not record receipt for project %s: %v", project.Id, err)
+			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
+		}
+
+		SetToast(e, "success", fmt.Sprintf("Receipt of %s recorded", services.FormatINR(result.Amount)))
+
+		redirectURL := fmt.Sprintf("/projects/%s/receivables", project.Id)
+		if e.Request.Header.Get("HX-Request") == "true" {
+			e.Response.Header().Set("HX-Redirect", redirectURL)
+			return e.String(http.StatusOK, "")
+		}
+		return e.Redirect(http.StatusFound, redirectURL)
+	}
+}
+
+// HandleReceivablesAging renders the amount due from clients across the
+// projects the user can see in 0-30/31-60/61-90/90+ day buckets, as of today or the as_of
+// date given.
+func HandleReceivablesAging(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		asOf := time.Now()
+		if raw := e.Request.URL.Query().Get("as_of"); raw != "" {
+			if d, err := time.Parse("2006-01-02", raw); err == nil {
+				asOf = d
+			}
+		}
+
+		user := GetCurrentUser(e.Request)
+		aging, err := services.BuildReceivablesAging(app, asOf, func(projectID string) bool {
+			return CanAccessProject(user, projectID)
+		})
+		if err != nil {
+			log.Printf("receivables: could not build aging: %v", err)
+			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
+		}
+
+		toRow := func(r services.AgingRow) templates.ReceivablesAgingRow {
+			row := templates.ReceivablesAgingRow{
+				ProjectID:   r.ProjectID,
+				ProjectName: r.ProjectName,
+				Due:         services.FormatINR(r.Due()),
+				Retention:   services.FormatINR(r.Retention),
+			}
+			for _, amount := range r.Buckets {
+				row.Buckets = append(row.Buckets, services.FormatINR(amount))
+			}
+			return row
+		}
+		data := templates.ReceivablesAgingData{
+			AsOf:    aging.AsOf,
+			Buckets: services.AgingBuckets,
+			Total:   toRow(aging.Total),
+		}
+		for _, r := range aging.Rows {
+			data.Rows = append(data.Rows, toRow(r))
+		}
+
+		var component templ.Component
+		if e.Request.Header.Get("HX-Request") == "true" {
+			component = templates.ReceivablesAgingContent(data)
+		} else {
+			headerData := GetHeaderData(e.Request)
+			sidebarData := GetSidebarData(e.Request)
+			component = templates.ReceivablesAgingPage(data, headerData, sidebarData)
+		}
+		return component.Render(e.Request.Context(), e.Response)
+	}
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/services"
+	"projectcreation/templates"
+)
+
+// wizardSerialItems lists the serial-tracked items posted by DC wizard
+// step 3, with the total quantity entered for each.
+func wizardSerialItems(app *pocketbase.PocketBase, r *http.Request) []services.SerialImportItem {
+	numDest, _ := strconv.Atoi(r.FormValue("num_destinations"))
+	var items []services.SerialImportItem
+	for _, key := range deduplicateStrings(r.Form["item_keys"]) {
+		if r.FormValue("item_serial_tracking_"+key) == "none" {
+			continue
+		}
+		itemType := r.FormValue("item_type_" + key)
+		itemID := r.FormValue("item_id_" + key)
+		collection := "sub_items"
+		if itemType == "sub_sub_item" {
+			collection = "sub_sub_items"
+		}
+		source, err := app.FindRecordById(collection, itemID)
+		if err != nil {
+			log.Printf("serial_import: could not find %s %s: %v", collection, itemID, err)
+			continue
+		}
+
+		totalQty := 0
+		for i := 0; i < numDest; i++ {
+			qty, _ := strconv.Atoi(r.FormValue(fmt.Sprintf("qty_%s_dest_%d", key, i)))
+			totalQty += qty
+		}
+		items = append(items, services.SerialImportItem{
+			Key:         key,
+			Code:        itemID,
+			Description: source.GetString("description"),
+			ExpectedQty: totalQty,
+		})
+	}
+	return items
+}
+
+// HandleDCWizardSerialTemplate downloads an .xlsx listing the serial-tracked
+// items of a DC template, for their serial numbers to be filled in.
+// Route: GET /projects/{projectId}/dcs/create/serials/template?template_id=
+func HandleDCWizardSerialTemplate(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		templateID := e.Request.URL.Query().Get("template_id")
+		tmpl, err := app.FindRecordById("dc_templates", templateID)
+		if err != nil || tmpl.GetString("project") != e.Request.PathValue("projectId") {
+			return e.String(http.StatusNotFound, "DC template not found")
+		}
+
+		var items []services.SerialImportItem
+		for _, item := range fetchTemplateItemsWithBOQ(app, templateID) {
+			if item.SerialTracking == "none" {
+				continue
+			}
+			items = append(items, services.SerialImportItem{Code: item.SourceItemID, Description: item.Description})
+		}
+
+		xlsxBytes, err := services.GenerateSerialImportTemplate(items)
+		if err != nil {
+			log.Printf("serial_template: failed to generate: %v", err)
+			return e.String(http.StatusInternalServerError, "Failed to generate template")
+		}
+
+		filename := fmt.Sprintf("Serials_%s.xlsx", sanitizeDCFilename(tmpl.GetString("name")))
+		e.Response.Header().Set("Content-Type",
+			"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		e.Response.Header().Set("Content-Disposition",
+			fmt.Sprintf(`attachment; filename="%s"`, filename))
+		e.Response.Write(xlsxBytes)
+		return nil
+	}
+}
+
+// HandleDCWizardSerialImport reads the serial numbers in an uploaded sheet
+// and fills them into the serial boxes of DC wizard step 3. The step 3 form
+// is posted with the file, so the sheet is checked against the items and
+// quantities on screen.
+// Route: POST /projects/{projectId}/dcs/create/serials/import
+func HandleDCWizardSerialImport(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		projectId := e.Request.PathValue("projectId")
+
+		// Parse multipart form (max 10MB)
+		if err := e.Request.ParseMultipartForm(10 << 20); err != nil {
+			return ErrorToast(e, http.StatusBadRequest, "File too large or invalid form data")
+		}
+		file, header, err := e.Request.FormFile("serials_file")
+		if err != nil {
+			return ErrorToast(e, http.StatusBadRequest, "Please select a file to upload")
+		}
+		defer file.Close()
+
+		items := wizardSerialItems(app, e.Request)
+		if len(items) == 0 {
+			return ErrorToast(e, http.StatusBadRequest, "None of the items on this DC take serial numbers")
+		}
+
+		existingSerials, err := services.ProjectSerialsInUse(app, projectId, e.Request.FormValue("edit_dc_id"))
+		if err != nil {
+			log.Printf("serial_import: %v", err)
+			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
+		}
+
+		result, err := services.ImportSerials(file, header.Filename, items, existingSerials)
+		if err != nil {
+			log.Printf("serial_import: %v", err)
+			return ErrorToast(e, http.StatusBadRequest, err.Error())
+		}
+
+		data := templates.DCWizardSerialImportData{
+			Errors:   result.Errors,
+			Warnings: result.Warnings,
+			Total:    result.Total,
+		}
+		for _, item := range items {
+			if serials, ok := result.Serials[item.Key]; ok {
+				data.Items = append(data.Items, templates.DCWizardSerialImportItem{
+					Key:         item.Key,
+					Description: item.Description,
+					Serials:     serials,
+				})
+			}
+		}
+		if len(result.Errors) == 0 {
+			SetToast(e, "success", fmt.Sprintf("%d serial numbers imported", result.Total))
+		}
+		return templates.DCWizardSerialImportResults(data).Render(e.Request.Context(), e.Response)
+	}
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/testhelpers"
+)
+
+// postSerialImport posts a step 3 form for one serial-tracked item with a
+// quantity of 2, along with a serials CSV.
+func postSerialImport(t *testing.T, app *pocketbase.PocketBase, projectID, itemKey, itemID, csv string) *httptest.ResponseRecorder {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for k, v := range map[string]string{
+		"num_destinations":                "1",
+		"item_keys":                       itemKey,
+		"item_type_" + itemKey:            "sub_item",
+		"item_id_" + itemKey:              itemID,
+		"item_serial_tracking_" + itemKey: "required",
+		"qty_" + itemKey + "_dest_0":      "2",
+	} {
+		mw.WriteField(k, v)
+	}
+	fw, _ := mw.CreateFormFile("serials_file", "serials.csv")
+	fw.Write([]byte(csv))
+	mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/projects/"+projectID+"/dcs/create/serials/import", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	req.Header.Set("HX-Request", "true")
+	req.SetPathValue("projectId", projectID)
+	rec := httptest.NewRecorder()
+	if err := HandleDCWizardSerialImport(app)(newTestRequestEvent(app, req, rec)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	return rec
+}
+
+func TestHandleDCWizardSerialImport(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Serial Import Project")
+	dc := createInvoiceableDC(t, app, project.Id, "ODC-001")
+	lines, _ := app.FindRecordsByFilter("dc_line_items", "dc = {:did}", "", 0, 0, map[string]any{"did": dc.Id})
+	itemID := lines[0].GetString("source_item_id")
+	itemKey := "sub_item_" + itemID
+
+	rec := postSerialImport(t, app, project.Id, itemKey, itemID, "Item Code,Serial Number\n"+itemID+",FL-001\n"+itemID+",FL-002\n")
+	testhelpers.AssertHTMLContains(t, rec.Body.String(),
+		"2 SERIAL NUMBERS IMPORTED",
+		`id="serials-`+itemKey+`"`,
+		`hx-swap-oob="true"`,
+		"FL-001\nFL-002",
+	)
+
+	// A serial already shipped on another DC stops the import
+	serials, _ := app.FindCollectionByNameOrId("serial_numbers")
+	sn := core.NewRecord(serials)
+	sn.Set("project", project.Id)
+	sn.Set("line_item", lines[0].Id)
+	sn.Set("serial_number", "FL-002")
+	if err := app.Save(sn); err != nil {
+		t.Fatalf("failed to save serial: %v", err)
+	}
+	rec = postSerialImport(t, app, project.Id, itemKey, itemID, "Description,Serial Number\nLED Floodlight,FL-001\nLED Floodlight,FL-002\n")
+	testhelpers.AssertHTMLContains(t, rec.Body.String(), "NOTHING WAS IMPORTED", "Row 3, Serial Number: FL-002 is already on ODC-001")
+	if strings.Contains(rec.Body.String(), "hx-swap-oob") {
+		t.Error("expected no serial boxes to be filled")
+	}
+}
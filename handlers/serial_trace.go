@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/a-h/templ"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/services"
+	"projectcreation/templates"
+)
+
+// serialLookupMatchLimit caps the similar serial numbers offered when a
+// search has no exact match.
+const serialLookupMatchLimit = 50
+
+// HandleSerialLookup traces a serial number across the projects the user
+// can see. Without an exact match it lists the serial numbers containing
+// the search text.
+// Route: GET /serials?q=
+func HandleSerialLookup(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		data := templates.SerialLookupData{Query: strings.TrimSpace(e.Request.URL.Query().Get("q"))}
+		user := GetCurrentUser(e.Request)
+
+		if data.Query != "" {
+			traces, err := services.TraceSerial(app, data.Query)
+			if err != nil {
+				log.Printf("serial_lookup: could not trace %q: %v", data.Query, err)
+				return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
+			}
+			for _, t := range traces {
+				if CanAccessProject(user, t.ProjectID) {
+					data.Traces = append(data.Traces, t)
+				}
+			}
+
+			if len(data.Traces) == 0 {
+				matches, err := services.SearchSerials(app, data.Query, serialLookupMatchLimit, func(projectID string) bool {
+					return CanAccessProject(user, projectID)
+				})
+				if err != nil {
+					log.Printf("serial_lookup: could not search %q: %v", data.Query, err)
+					return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
+				}
+				data.Matches = matches
+			}
+		}
+
+		var component templ.Component
+		if e.Request.Header.Get("HX-Request") == "true" {
+			component = templates.SerialLookupContent(data)
+		} else {
+			headerData := GetHeaderData(e.Request)
+			sidebarData := GetSidebarData(e.Request)
+			component = templates.SerialLookupPage(data, headerData, sidebarData)
+		}
+		return component.Render(e.Request.Context(), e.Response)
+	}
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/testhelpers"
+)
+
+// createSerialOnDC saves a transfer DC line carrying one serial number.
+func createSerialOnDC(t *testing.T, app *pocketbase.PocketBase, projectID, dcNumber, serial string) *core.Record {
+	t.Helper()
+	dc := createTestDC(t, app, projectID, dcNumber, "transfer", "issued")
+	lineItems, _ := app.FindCollectionByNameOrId("dc_line_items")
+	li := core.NewRecord(lineItems)
+	li.Set("dc", dc.Id)
+	li.Set("source_item_type", "sub_item")
+	li.Set("source_item_id", "x")
+	li.Set("quantity", 1)
+	if err := app.Save(li); err != nil {
+		t.Fatalf("failed to save line item: %v", err)
+	}
+	serialsCol, _ := app.FindCollectionByNameOrId("serial_numbers")
+	sr := core.NewRecord(serialsCol)
+	sr.Set("project", projectID)
+	sr.Set("line_item", li.Id)
+	sr.Set("serial_number", serial)
+	if err := app.Save(sr); err != nil {
+		t.Fatalf("failed to save serial: %v", err)
+	}
+	return dc
+}
+
+func getSerialLookup(t *testing.T, app *pocketbase.PocketBase, query string, user *core.Record) string {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/serials?q="+url.QueryEscape(query), nil)
+	req.Header.Set("HX-Request", "true")
+	if user != nil {
+		req = req.WithContext(context.WithValue(req.Context(), CurrentUserKey, user))
+	}
+	rec := httptest.NewRecorder()
+	if err := HandleSerialLookup(app)(newTestRequestEvent(app, req, rec)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	return rec.Body.String()
+}
+
+func TestHandleSerialLookup_TracesAcrossVisibleProjects(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	north := testhelpers.CreateTestProject(t, app, "North Project")
+	south := testhelpers.CreateTestProject(t, app, "South Project")
+	createSerialOnDC(t, app, north.Id, "TDC-N-001", "INV-500")
+	createSerialOnDC(t, app, south.Id, "TDC-S-001", "INV-500")
+	createSerialOnDC(t, app, north.Id, "TDC-N-002", "INV-5001")
+
+	body := getSerialLookup(t, app, "INV-500", nil)
+	testhelpers.AssertHTMLContains(t, body, "North Project", "South Project", "TDC-N-001", "TDC-S-001", "Dispatched")
+
+	// A member of one project only sees that project's history
+	member := testhelpers.CreateTestUser(t, app, "north@example.com", "logistics", north.Id)
+	body = getSerialLookup(t, app, "INV-500", member)
+	testhelpers.AssertHTMLContains(t, body, "North Project", "TDC-N-001")
+	if strings.Contains(body, "TDC-S-001") {
+		t.Error("expected the other project's DC to be hidden")
+	}
+
+	// Without an exact match, similar serial numbers are offered
+	body = getSerialLookup(t, app, "INV-50", member)
+	testhelpers.AssertHTMLContains(t, body, "SIMILAR SERIAL NUMBERS", "INV-500", "INV-5001")
+
+	body = getSerialLookup(t, app, "NOPE", member)
+	testhelpers.AssertHTMLContains(t, body, "No serial number matches")
+}
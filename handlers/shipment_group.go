@@ -32,6 +32,7 @@ func HandleShipmentGroupDetail(app *pocketbase.PocketBase) func(*core.RequestEve
 
 		var transitDC *templates.ShipmentGroupDCItem
 		var officialDCs []templates.ShipmentGroupDCItem
+		var delivered int
 
 		for _, dc := range dcs {
 			dcType := dc.GetString("dc_type")
@@ -76,6 +77,9 @@ func HandleShipmentGroupDetail(app *pocketbase.PocketBase) func(*core.RequestEve
 				transitDC = &item
 			} else {
 				officialDCs = append(officialDCs, item)
+				if item.Status == services.DCStatusDelivered || item.Status == services.DCStatusPartiallyDelivered {
+					delivered++
+				}
 			}
 		}
 
@@ -87,6 +91,7 @@ func HandleShipmentGroupDetail(app *pocketbase.PocketBase) func(*core.RequestEve
 			Created:      sg.GetString("created"),
 			TransitDC:    transitDC,
 			OfficialDCs:  officialDCs,
+			Delivered:    delivered,
 		}
 
 		var component templ.Component
@@ -115,7 +120,7 @@ func HandleShipmentGroupIssueAll(app *pocketbase.PocketBase) func(*core.RequestE
 			return ErrorToast(e, http.StatusNotFound, "Shipment group not found in this project")
 		}
 
-		if err := services.IssueShipmentGroup(app, sgId); err != nil {
+		if err := services.IssueShipmentGroup(e.Request.Context(), app, sgId); err != nil {
 			return ErrorToast(e, http.StatusBadRequest, fmt.Sprintf("Failed to issue: %s", err.Error()))
 		}
 
@@ -148,13 +153,13 @@ func HandleShipmentGroupDelete(app *pocketbase.PocketBase) func(*core.RequestEve
 		// Delete all DCs in the group (cascade should handle line items, serials, etc.)
 		dcs, _ := app.FindRecordsByFilter("delivery_challans", "shipment_group = {:sgid}", "", 0, 0, map[string]any{"sgid": sgId})
 		for _, dc := range dcs {
-			if err := app.Delete(dc); err != nil {
+			if err := app.DeleteWithContext(e.Request.Context(), dc); err != nil {
 				return ErrorToast(e, http.StatusInternalServerError, fmt.Sprintf("Failed to delete DC %s: %s", dc.GetString("dc_number"), err.Error()))
 			}
 		}
 
 		// Delete the shipment group
-		if err := app.Delete(sg); err != nil {
+		if err := app.DeleteWithContext(e.Request.Context(), sg); err != nil {
 			return ErrorToast(e, http.StatusInternalServerError, "Failed to delete shipment group")
 		}
 
@@ -12,15 +12,18 @@ import (
 // It reads the active project from middleware context and queries address counts.
 func BuildSidebarData(r *http.Request, app *pocketbase.PocketBase) templates.SidebarData {
 	activeProj := GetActiveProject(r)
+	isAdmin := HasRole(GetCurrentUser(r), RoleAdmin)
 	if activeProj == nil {
 		return templates.SidebarData{
 			ActivePath: r.URL.Path,
+			IsAdmin:    isAdmin,
 		}
 	}
 
 	data := templates.SidebarData{
 		ActiveProject: activeProj,
 		ActivePath:    r.URL.Path,
+		IsAdmin:       isAdmin,
 	}
 
 	// Load project record for config
@@ -40,6 +43,14 @@ func BuildSidebarData(r *http.Request, app *pocketbase.PocketBase) templates.Sid
 	poRecords, _ := app.FindRecordsByFilter("purchase_orders", "project = {:pid}", "", 0, 0, map[string]any{"pid": activeProj.ID})
 	data.POCount = len(poRecords)
 
+	// Count GRNs for this project
+	grnRecords, _ := app.FindRecordsByFilter("grn", "project = {:pid}", "", 0, 0, map[string]any{"pid": activeProj.ID})
+	data.GRNCount = len(grnRecords)
+
+	// Count vendor invoices for this project
+	invoiceRecords, _ := app.FindRecordsByFilter("vendor_invoices", "project = {:pid}", "", 0, 0, map[string]any{"pid": activeProj.ID})
+	data.InvoiceCount = len(invoiceRecords)
+
 	// Count linked vendors for this project
 	vendorLinks, _ := app.FindRecordsByFilter("project_vendors", "project = {:pid}", "", 0, 0, map[string]any{"pid": activeProj.ID})
 	data.VendorCount = len(vendorLinks)
@@ -56,6 +67,28 @@ func BuildSidebarData(r *http.Request, app *pocketbase.PocketBase) templates.Sid
 	dcs, _ := app.FindRecordsByFilter("delivery_challans", "project = {:pid}", "", 0, 0, map[string]any{"pid": activeProj.ID})
 	data.DCCount = len(dcs)
 
+	// Count installation work orders
+	workOrders, _ := app.FindRecordsByFilter("work_orders", "project = {:pid}", "", 0, 0, map[string]any{"pid": activeProj.ID})
+	data.WorkOrderCount = len(workOrders)
+
+	// Count client invoices
+	clientInvoices, _ := app.FindRecordsByFilter("client_invoices", "project = {:pid}", "", 0, 0, map[string]any{"pid": activeProj.ID})
+	data.ClientInvoiceCount = len(clientInvoices)
+
+	// Count billing milestones and client receipts
+	milestones, _ := app.FindRecordsByFilter("billing_milestones", "project = {:pid}", "", 0, 0, map[string]any{"pid": activeProj.ID})
+	data.BillingMilestoneCount = len(milestones)
+	receipts, _ := app.FindRecordsByFilter("client_receipts", "project = {:pid}", "", 0, 0, map[string]any{"pid": activeProj.ID})
+	data.ClientReceiptCount = len(receipts)
+
+	// Count site expenses awaiting approval
+	pendingExpenses, _ := app.FindRecordsByFilter("expenses", "project = {:pid} && status = 'pending'", "", 0, 0, map[string]any{"pid": activeProj.ID})
+	data.PendingExpenseCount = len(pendingExpenses)
+
+	// Count open budget alerts
+	budgetAlerts, _ := app.FindRecordsByFilter("budget_alerts", "project = {:pid} && dismissed = false", "", 0, 0, map[string]any{"pid": activeProj.ID})
+	data.BudgetAlertCount = len(budgetAlerts)
+
 	// Count addresses by type
 	addrCol, _ := app.FindCollectionByNameOrId("addresses")
 	if addrCol != nil {
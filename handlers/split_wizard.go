@@ -3,6 +3,8 @@ package handlers
 import (
 	"fmt"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/a-h/templ"
@@ -31,7 +33,7 @@ func HandleSplitStep1(app *pocketbase.PocketBase) func(*core.RequestEvent) error
 			return ErrorToast(e, http.StatusBadRequest, "Only transfer DCs can be split")
 		}
 		status := dc.GetString("status")
-		if status != "issued" && status != "splitting" {
+		if !slices.Contains(services.DCIssuedStatuses, status) && status != "splitting" {
 			return ErrorToast(e, http.StatusBadRequest, "DC must be issued before splitting")
 		}
 
@@ -42,34 +44,11 @@ func HandleSplitStep1(app *pocketbase.PocketBase) func(*core.RequestEvent) error
 		}
 		transferDC := transferRecs[0]
 
-		// Fetch destinations
-		destRecs, _ := app.FindRecordsByFilter("transfer_dc_destinations", "transfer_dc = {:tid}", "", 0, 0, map[string]any{"tid": transferDC.Id})
-		var destinations []templates.SplitDestination
+		// Fetch destinations with what remains to forward
+		destinations := splitDestinations(app, transferDC.Id)
 		hasUnsplit := false
-		for _, dest := range destRecs {
-			addr := resolveDCAddressDisplay(app, dest.GetString("ship_to_address"))
-			isSplit := dest.GetBool("is_split")
-
-			// Fetch per-item quantities for this destination
-			destQtyRecs, _ := app.FindRecordsByFilter("transfer_dc_dest_quantities", "destination = {:did}", "", 0, 0, map[string]any{"did": dest.Id})
-			totalQty := 0
-			for _, dq := range destQtyRecs {
-				totalQty += dq.GetInt("quantity")
-			}
-
-			sd := templates.SplitDestination{
-				ID:       dest.Id,
-				IsSplit:  isSplit,
-				TotalQty: totalQty,
-			}
-			if addr != nil {
-				sd.ShipToName = addr.CompanyName
-				sd.ShipToCity = addr.City
-				sd.ShipToState = addr.State
-			}
-
-			destinations = append(destinations, sd)
-			if !isSplit {
+		for _, dest := range destinations {
+			if !dest.IsSplit {
 				hasUnsplit = true
 			}
 		}
@@ -98,7 +77,8 @@ func HandleSplitStep1(app *pocketbase.PocketBase) func(*core.RequestEvent) error
 	}
 }
 
-// HandleSplitStep2 renders step 2: transporter selection and serial assignment.
+// HandleSplitStep2 renders step 2: quantities to forward, transporter selection
+// and serial assignment.
 func HandleSplitStep2(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
 	return func(e *core.RequestEvent) error {
 		projectId := e.Request.PathValue("projectId")
@@ -129,22 +109,19 @@ func HandleSplitStep2(app *pocketbase.PocketBase) func(*core.RequestEvent) error
 		// Fetch parent line items
 		parentLineItems, _ := app.FindRecordsByFilter("dc_line_items", "dc = {:did}", "line_order", 0, 0, map[string]any{"did": dcId})
 
-		// Build items with quantities for selected destinations
+		// Build items with the quantities remaining for selected destinations,
+		// proposing to forward all of it
+		destNames := splitDestinationNames(app, selectedDestIDs)
 		var items []templates.SplitWizardItem
 		for _, li := range parentLineItems {
 			sourceType := li.GetString("source_item_type")
 			sourceID := li.GetString("source_item_id")
 
-			// Calculate total qty across selected destinations
+			dests := splitItemDestinations(app, selectedDestIDs, destNames, sourceType, sourceID)
 			totalQty := 0
-			for _, destID := range selectedDestIDs {
-				destQtyRecs, _ := app.FindRecordsByFilter("transfer_dc_dest_quantities",
-					"destination = {:did} && source_item_type = {:sit} && source_item_id = {:sid}",
-					"", 1, 0,
-					map[string]any{"did": destID, "sit": sourceType, "sid": sourceID})
-				if len(destQtyRecs) > 0 {
-					totalQty += destQtyRecs[0].GetInt("quantity")
-				}
+			for i := range dests {
+				dests[i].Qty = dests[i].Remaining
+				totalQty += dests[i].Remaining
 			}
 
 			if totalQty == 0 {
@@ -186,6 +163,7 @@ func HandleSplitStep2(app *pocketbase.PocketBase) func(*core.RequestEvent) error
 				TotalQty:         totalQty,
 				SerialTracking:   serialTracking,
 				AvailableSerials: availableSerials,
+				Destinations:     dests,
 			})
 		}
 
@@ -250,17 +228,11 @@ func HandleSplitStep3(app *pocketbase.PocketBase) func(*core.RequestEvent) error
 		}
 
 		// Resolve destination names
+		destNamesByID := splitDestinationNames(app, selectedDestIDs)
 		var destNames []string
 		for _, destID := range selectedDestIDs {
-			if destRec, err := app.FindRecordById("transfer_dc_destinations", destID); err == nil {
-				addr := resolveDCAddressDisplay(app, destRec.GetString("ship_to_address"))
-				if addr != nil {
-					name := addr.CompanyName
-					if addr.City != "" {
-						name += ", " + addr.City
-					}
-					destNames = append(destNames, name)
-				}
+			if name, ok := destNamesByID[destID]; ok {
+				destNames = append(destNames, name)
 			}
 		}
 
@@ -273,15 +245,16 @@ func HandleSplitStep3(app *pocketbase.PocketBase) func(*core.RequestEvent) error
 			sourceType := li.GetString("source_item_type")
 			sourceID := li.GetString("source_item_id")
 
+			// Read the quantities to forward entered in step 2
+			dests := splitItemDestinations(app, selectedDestIDs, destNamesByID, sourceType, sourceID)
 			totalQty := 0
-			for _, destID := range selectedDestIDs {
-				destQtyRecs, _ := app.FindRecordsByFilter("transfer_dc_dest_quantities",
-					"destination = {:did} && source_item_type = {:sit} && source_item_id = {:sid}",
-					"", 1, 0,
-					map[string]any{"did": destID, "sit": sourceType, "sid": sourceID})
-				if len(destQtyRecs) > 0 {
-					totalQty += destQtyRecs[0].GetInt("quantity")
+			for i, dest := range dests {
+				qty, err := parseSplitQty(e.Request.FormValue(fmt.Sprintf("qty_%s", dest.DestQtyID)))
+				if err != nil || qty > dest.Remaining {
+					return ErrorToast(e, http.StatusBadRequest, fmt.Sprintf("Enter a quantity between 0 and %d for %s", dest.Remaining, dest.DestinationName))
 				}
+				dests[i].Qty = qty
+				totalQty += qty
 			}
 
 			if totalQty == 0 {
@@ -310,6 +283,9 @@ func HandleSplitStep3(app *pocketbase.PocketBase) func(*core.RequestEvent) error
 					}
 				}
 			}
+			if len(serials) > totalQty {
+				return ErrorToast(e, http.StatusBadRequest, fmt.Sprintf("%d serial numbers assigned to %s, which forwards %d", len(serials), description, totalQty))
+			}
 			serialAssignments[li.Id] = serials
 
 			reviewItems = append(reviewItems, templates.SplitReviewItem{
@@ -317,8 +293,12 @@ func HandleSplitStep3(app *pocketbase.PocketBase) func(*core.RequestEvent) error
 				Description:      description,
 				TotalQty:         totalQty,
 				Serials:          serials,
+				Destinations:     dests,
 			})
 		}
+		if len(reviewItems) == 0 {
+			return ErrorToast(e, http.StatusBadRequest, "Enter a quantity to forward for at least one item")
+		}
 
 		data := templates.SplitWizardStep3Data{
 			ProjectID:        projectId,
@@ -384,10 +364,26 @@ func HandleSplitCreate(app *pocketbase.PocketBase) func(*core.RequestEvent) erro
 			}
 		}
 
+		// Parse the quantities to forward; lines left blank forward nothing
+		quantities := make(map[string]int)
+		for _, destID := range selectedDestIDs {
+			destQtyRecs, _ := app.FindRecordsByFilter("transfer_dc_dest_quantities", "destination = {:did}", "", 0, 0, map[string]any{"did": destID})
+			for _, dq := range destQtyRecs {
+				qty, err := parseSplitQty(e.Request.FormValue(fmt.Sprintf("qty_%s", dq.Id)))
+				if err != nil {
+					return ErrorToast(e, http.StatusBadRequest, "Invalid quantity to forward")
+				}
+				if qty > 0 {
+					quantities[dq.Id] = qty
+				}
+			}
+		}
+
 		params := services.SplitParams{
 			ProjectID:         projectId,
 			TransferDCID:      dcId,
 			DestinationIDs:    selectedDestIDs,
+			Quantities:        quantities,
 			TransporterID:     transporterID,
 			VehicleID:         vehicleID,
 			EwayBillNumber:    ewayBillNumber,
@@ -395,7 +391,7 @@ func HandleSplitCreate(app *pocketbase.PocketBase) func(*core.RequestEvent) erro
 			SerialAssignments: serialAssignments,
 		}
 
-		result, err := services.CreateSplit(app, params)
+		result, err := services.CreateSplit(e.Request.Context(), app, params)
 		if err != nil {
 			return ErrorToast(e, http.StatusInternalServerError, fmt.Sprintf("Failed to create split: %s", err.Error()))
 		}
@@ -416,7 +412,7 @@ func HandleSplitUndo(app *pocketbase.PocketBase) func(*core.RequestEvent) error
 		dcId := e.Request.PathValue("id")
 		splitId := e.Request.PathValue("sid")
 
-		if err := services.UndoSplit(app, splitId); err != nil {
+		if err := services.UndoSplit(e.Request.Context(), app, splitId); err != nil {
 			return ErrorToast(e, http.StatusInternalServerError, fmt.Sprintf("Failed to undo split: %s", err.Error()))
 		}
 
@@ -442,20 +438,40 @@ func handleSplitStep1WithError(app *pocketbase.PocketBase, e *core.RequestEvent,
 	}
 	transferDC := transferRecs[0]
 
-	destRecs, _ := app.FindRecordsByFilter("transfer_dc_destinations", "transfer_dc = {:tid}", "", 0, 0, map[string]any{"tid": transferDC.Id})
+	data := templates.SplitWizardStep1Data{
+		ProjectID:    projectId,
+		DCID:         dcId,
+		DCNumber:     dc.GetString("dc_number"),
+		Destinations: splitDestinations(app, transferDC.Id),
+		Errors:       map[string]string{"destinations": errMsg},
+	}
+
+	var component templ.Component
+	if e.Request.Header.Get("HX-Request") == "true" {
+		component = templates.SplitWizardStep1Content(data)
+	} else {
+		headerData := GetHeaderData(e.Request)
+		sidebarData := GetSidebarData(e.Request)
+		component = templates.SplitWizardStep1Page(data, headerData, sidebarData)
+	}
+	return component.Render(e.Request.Context(), e.Response)
+}
+
+// splitDestinations lists a transfer DC's destinations for step 1 with their
+// total quantities and what remains to be forwarded.
+func splitDestinations(app *pocketbase.PocketBase, transferDCID string) []templates.SplitDestination {
+	destRecs, _ := app.FindRecordsByFilter("transfer_dc_destinations", "transfer_dc = {:tid}", "", 0, 0, map[string]any{"tid": transferDCID})
 	var destinations []templates.SplitDestination
 	for _, dest := range destRecs {
 		addr := resolveDCAddressDisplay(app, dest.GetString("ship_to_address"))
 		destQtyRecs, _ := app.FindRecordsByFilter("transfer_dc_dest_quantities", "destination = {:did}", "", 0, 0, map[string]any{"did": dest.Id})
-		totalQty := 0
-		for _, dq := range destQtyRecs {
-			totalQty += dq.GetInt("quantity")
-		}
-
 		sd := templates.SplitDestination{
-			ID:       dest.Id,
-			IsSplit:  dest.GetBool("is_split"),
-			TotalQty: totalQty,
+			ID:      dest.Id,
+			IsSplit: dest.GetBool("is_split"),
+		}
+		for _, dq := range destQtyRecs {
+			sd.TotalQty += dq.GetInt("quantity")
+			sd.RemainingQty += dq.GetInt("quantity") - dq.GetInt("forwarded_qty")
 		}
 		if addr != nil {
 			sd.ShipToName = addr.CompanyName
@@ -464,22 +480,62 @@ func handleSplitStep1WithError(app *pocketbase.PocketBase, e *core.RequestEvent,
 		}
 		destinations = append(destinations, sd)
 	}
+	return destinations
+}
 
-	data := templates.SplitWizardStep1Data{
-		ProjectID:    projectId,
-		DCID:         dcId,
-		DCNumber:     dc.GetString("dc_number"),
-		Destinations: destinations,
-		Errors:       map[string]string{"destinations": errMsg},
+// splitDestinationNames maps destination IDs to a "company, city" label.
+func splitDestinationNames(app *pocketbase.PocketBase, destIDs []string) map[string]string {
+	names := make(map[string]string, len(destIDs))
+	for _, destID := range destIDs {
+		destRec, err := app.FindRecordById("transfer_dc_destinations", destID)
+		if err != nil {
+			continue
+		}
+		if addr := resolveDCAddressDisplay(app, destRec.GetString("ship_to_address")); addr != nil {
+			name := addr.CompanyName
+			if addr.City != "" {
+				name += ", " + addr.City
+			}
+			names[destID] = name
+		}
 	}
+	return names
+}
 
-	var component templ.Component
-	if e.Request.Header.Get("HX-Request") == "true" {
-		component = templates.SplitWizardStep1Content(data)
-	} else {
-		headerData := GetHeaderData(e.Request)
-		sidebarData := GetSidebarData(e.Request)
-		component = templates.SplitWizardStep1Page(data, headerData, sidebarData)
+// splitItemDestinations returns each selected destination's remaining share
+// of an item. Destinations with nothing left of it are omitted.
+func splitItemDestinations(app *pocketbase.PocketBase, destIDs []string, destNames map[string]string, sourceType, sourceID string) []templates.SplitItemDestination {
+	var dests []templates.SplitItemDestination
+	for _, destID := range destIDs {
+		destQtyRecs, _ := app.FindRecordsByFilter("transfer_dc_dest_quantities",
+			"destination = {:did} && source_item_type = {:sit} && source_item_id = {:sid}",
+			"", 1, 0,
+			map[string]any{"did": destID, "sit": sourceType, "sid": sourceID})
+		if len(destQtyRecs) == 0 {
+			continue
+		}
+		remaining := destQtyRecs[0].GetInt("quantity") - destQtyRecs[0].GetInt("forwarded_qty")
+		if remaining <= 0 {
+			continue
+		}
+		dests = append(dests, templates.SplitItemDestination{
+			DestQtyID:       destQtyRecs[0].Id,
+			DestinationName: destNames[destID],
+			Remaining:       remaining,
+		})
 	}
-	return component.Render(e.Request.Context(), e.Response)
+	return dests
+}
+
+// parseSplitQty parses a quantity to forward; blank means none.
+func parseSplitQty(raw string) (int, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0, nil
+	}
+	qty, err := strconv.Atoi(raw)
+	if err != nil || qty < 0 {
+		return 0, fmt.Errorf("invalid quantity %q", raw)
+	}
+	return qty, nil
 }
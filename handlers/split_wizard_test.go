@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/services"
+	"projectcreation/testhelpers"
+)
+
+// createIssuedTransferDC creates an issued transfer DC sending one sub item
+// to two sites, returning the DC and the first site's destination quantity.
+func createIssuedTransferDC(t *testing.T, app *pocketbase.PocketBase, projectID string, qtyA, qtyB int) (*core.Record, *core.Record) {
+	t.Helper()
+	boq := testhelpers.CreateTestBOQ(t, app, projectID, "Transfer BOQ")
+	mainItem := testhelpers.CreateTestMainBOQItem(t, app, boq.Id, "Panels")
+	subItem := testhelpers.CreateTestSubItem(t, app, mainItem.Id, "Solar panel")
+	siteA := testhelpers.CreateTestAddress(t, app, projectID, "ship_to", "Site A")
+	siteB := testhelpers.CreateTestAddress(t, app, projectID, "ship_to", "Site B")
+
+	result, err := services.CreateTransferDC(context.Background(), app, services.TransferDCParams{
+		ProjectID:   projectID,
+		ChallanDate: "2026-04-10",
+		ShipToIDs:   []string{siteA.Id, siteB.Id},
+		TaxType:     "igst",
+		Items: []services.ShipmentItemParams{{
+			SourceItemType: "sub_item", SourceItemID: subItem.Id, Rate: 100,
+			Quantities: []int{qtyA, qtyB}, TotalQty: qtyA + qtyB,
+		}},
+	})
+	if err != nil {
+		t.Fatalf("CreateTransferDC failed: %v", err)
+	}
+	dc, _ := app.FindRecordById("delivery_challans", result.DCID)
+	dc.Set("status", "issued")
+	if err := app.Save(dc); err != nil {
+		t.Fatalf("failed to issue transfer DC: %v", err)
+	}
+	destQty, err := app.FindFirstRecordByFilter("transfer_dc_dest_quantities",
+		"destination.ship_to_address = {:addr}", map[string]any{"addr": siteA.Id})
+	if err != nil {
+		t.Fatalf("failed to find destination quantity: %v", err)
+	}
+	return dc, destQty
+}
+
+func TestSplitWizard_ForwardsPartOfADestination(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Split Project")
+	dc, destQty := createIssuedTransferDC(t, app, project.Id, 6, 4)
+	destID := destQty.GetString("destination")
+	qtyField := "qty_" + destQty.Id
+	base := fmt.Sprintf("/projects/%s/transfer-dcs/%s/split", project.Id, dc.Id)
+	pathValues := map[string]string{"projectId": project.Id, "id": dc.Id}
+
+	// Step 2 proposes forwarding everything remaining
+	rec := postHXForm(t, app, HandleSplitStep2, base+"/step2", pathValues, url.Values{"destination_ids": {destID}})
+	testhelpers.AssertHTMLContains(t, rec.Body.String(), "QUANTITIES TO FORWARD", fmt.Sprintf(`name="%s"`, qtyField), `max="6"`)
+
+	// Step 3 rejects more than remains and reviews the rest
+	rec = postHXForm(t, app, HandleSplitStep3, base+"/step3", pathValues, url.Values{"destination_ids": {destID}, qtyField: {"7"}})
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for too much, got %d", rec.Code)
+	}
+	rec = postHXForm(t, app, HandleSplitStep3, base+"/step3", pathValues, url.Values{"destination_ids": {destID}, qtyField: {"2"}})
+	testhelpers.AssertHTMLContains(t, rec.Body.String(), "2 of 6 remaining", fmt.Sprintf(`name="%s" value="2"`, qtyField))
+
+	rec = postHXForm(t, app, HandleSplitCreate, base, pathValues, url.Values{"destination_ids": {destID}, qtyField: {"2"}})
+	if rec.Header().Get("HX-Redirect") == "" {
+		t.Fatalf("expected a redirect to the new transit DC, got %d: %s", rec.Code, rec.Body.String())
+	}
+	destQty, _ = app.FindRecordById("transfer_dc_dest_quantities", destQty.Id)
+	if destQty.GetInt("forwarded_qty") != 2 {
+		t.Errorf("expected 2 forwarded, got %d", destQty.GetInt("forwarded_qty"))
+	}
+	dc, _ = app.FindRecordById("delivery_challans", dc.Id)
+	if dc.GetString("status") != "splitting" {
+		t.Errorf("expected the transfer DC to be splitting, got %s", dc.GetString("status"))
+	}
+
+	// Step 1 shows the site as partly forwarded and still selectable
+	req := httptest.NewRequest(http.MethodGet, base, nil)
+	req.Header.Set("HX-Request", "true")
+	req.SetPathValue("projectId", project.Id)
+	req.SetPathValue("id", dc.Id)
+	rec = httptest.NewRecorder()
+	if err := HandleSplitStep1(app)(newTestRequestEvent(app, req, rec)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	testhelpers.AssertHTMLContains(t, rec.Body.String(), "PARTIAL", fmt.Sprintf(`value="%s"`, destID))
+}
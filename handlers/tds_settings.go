@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/a-h/templ"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/collections"
+	"projectcreation/templates"
+)
+
+// parseTDSRate parses a TDS rate, which must be between 0 and 100.
+func parseTDSRate(raw string) (float64, bool) {
+	rate, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+	if err != nil || rate < 0 || rate > 100 {
+		return 0, false
+	}
+	return rate, true
+}
+
+// renderTDSSettings renders the TDS settings page as a partial or full page.
+func renderTDSSettings(e *core.RequestEvent, data templates.TDSSettingsData) error {
+	var component templ.Component
+	if e.Request.Header.Get("HX-Request") == "true" {
+		component = templates.TDSSettingsContent(data)
+	} else {
+		headerData := GetHeaderData(e.Request)
+		sidebarData := GetSidebarData(e.Request)
+		component = templates.TDSSettingsPage(data, headerData, sidebarData)
+	}
+	return component.Render(e.Request.Context(), e.Response)
+}
+
+// HandleTDSSettings renders the TDS sections page (GET /settings/tds).
+func HandleTDSSettings(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		sections, err := collections.GetTDSSections(app)
+		if err != nil {
+			log.Printf("tds_settings: could not load TDS sections: %v", err)
+			return ErrorToast(e, http.StatusInternalServerError, "Could not load TDS sections")
+		}
+
+		data := templates.TDSSettingsData{Errors: make(map[string]string)}
+		for _, s := range sections {
+			data.Sections = append(data.Sections, templates.TDSSectionRow{
+				ID:          s.Id,
+				Section:     s.GetString("section"),
+				Description: s.GetString("description"),
+				Rate:        formatQty(s.GetFloat("rate_percent")),
+				Active:      s.GetBool("active"),
+			})
+		}
+		return renderTDSSettings(e, data)
+	}
+}
+
+// HandleTDSSettingsSave handles the POST /settings/tds form submission. It
+// updates every listed section and adds the blank row if it was filled in.
+func HandleTDSSettingsSave(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		if err := e.Request.ParseForm(); err != nil {
+			return ErrorToast(e, http.StatusBadRequest, "Invalid form data")
+		}
+
+		sections, err := collections.GetTDSSections(app)
+		if err != nil {
+			log.Printf("tds_settings_save: could not load TDS sections: %v", err)
+			return ErrorToast(e, http.StatusInternalServerError, "Could not load TDS sections")
+		}
+
+		data := templates.TDSSettingsData{
+			NewSection:     strings.TrimSpace(e.Request.FormValue("new_section")),
+			NewDescription: strings.TrimSpace(e.Request.FormValue("new_description")),
+			NewRate:        strings.TrimSpace(e.Request.FormValue("new_rate")),
+			Errors:         make(map[string]string),
+		}
+		for _, s := range sections {
+			row := templates.TDSSectionRow{
+				ID:          s.Id,
+				Section:     strings.TrimSpace(e.Request.FormValue("section_" + s.Id)),
+				Description: strings.TrimSpace(e.Request.FormValue("description_" + s.Id)),
+				Rate:        strings.TrimSpace(e.Request.FormValue("rate_" + s.Id)),
+				Active:      e.Request.FormValue("active_"+s.Id) == "true",
+			}
+			rate, ok := parseTDSRate(row.Rate)
+			switch {
+			case row.Section == "":
+				data.Errors[s.Id] = "Section is required"
+			case !ok:
+				data.Errors[s.Id] = row.Section + ": rate must be between 0 and 100"
+			default:
+				s.Set("section", row.Section)
+				s.Set("description", row.Description)
+				s.Set("rate_percent", rate)
+				s.Set("active", row.Active)
+			}
+			data.Sections = append(data.Sections, row)
+		}
+
+		newRate, newRateOK := parseTDSRate(data.NewRate)
+		if data.NewSection != "" && !newRateOK {
+			data.Errors["new"] = data.NewSection + ": rate must be between 0 and 100"
+		}
+
+		if len(data.Errors) > 0 {
+			SetToast(e, "warning", "Please fix the errors below")
+			return renderTDSSettings(e, data)
+		}
+
+		err = app.RunInTransaction(func(txApp core.App) error {
+			for _, s := range sections {
+				if err := txApp.SaveWithContext(e.Request.Context(), s); err != nil {
+					return err
+				}
+			}
+			if data.NewSection == "" {
+				return nil
+			}
+			col, err := txApp.FindCollectionByNameOrId("tds_sections")
+			if err != nil {
+				return err
+			}
+			record := core.NewRecord(col)
+			record.Set("section", data.NewSection)
+			record.Set("description", data.NewDescription)
+			record.Set("rate_percent", newRate)
+			record.Set("active", true)
+			return txApp.SaveWithContext(e.Request.Context(), record)
+		})
+		if err != nil {
+			log.Printf("tds_settings_save: could not save TDS sections: %v", err)
+			return ErrorToast(e, http.StatusInternalServerError, "Could not save TDS sections")
+		}
+
+		SetToast(e, "success", "TDS sections saved")
+
+		if e.Request.Header.Get("HX-Request") == "true" {
+			e.Response.Header().Set("HX-Redirect", "/settings/tds")
+			return e.String(http.StatusOK, "")
+		}
+		return e.Redirect(http.StatusFound, "/settings/tds")
+	}
+}
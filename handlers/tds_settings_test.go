@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase"
+
+	"projectcreation/collections"
+	"projectcreation/testhelpers"
+)
+
+func postTDSSettings(t *testing.T, app *pocketbase.PocketBase, form url.Values) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/settings/tds", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("HX-Request", "true")
+	rec := httptest.NewRecorder()
+	if err := HandleTDSSettingsSave(app)(newTestRequestEvent(app, req, rec)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	return rec
+}
+
+// tdsSettingsForm returns the settings form as currently saved.
+func tdsSettingsForm(t *testing.T, app *pocketbase.PocketBase) url.Values {
+	t.Helper()
+	sections, err := collections.GetTDSSections(app)
+	if err != nil {
+		t.Fatalf("GetTDSSections failed: %v", err)
+	}
+	form := url.Values{}
+	for _, s := range sections {
+		form.Set("section_"+s.Id, s.GetString("section"))
+		form.Set("description_"+s.Id, s.GetString("description"))
+		form.Set("rate_"+s.Id, formatQty(s.GetFloat("rate_percent")))
+		if s.GetBool("active") {
+			form.Set("active_"+s.Id, "true")
+		}
+	}
+	return form
+}
+
+func TestHandleTDSSettingsSave_UpdatesAndAdds(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	section := findTDSSection(t, app, "194J(b)", 10)
+
+	form := tdsSettingsForm(t, app)
+	form.Set("rate_"+section.Id, "7.5")
+	form.Del("active_" + section.Id)
+	form.Set("new_section", "194-I(b)")
+	form.Set("new_description", "Rent — land and building")
+	form.Set("new_rate", "10")
+	rec := postTDSSettings(t, app, form)
+
+	testhelpers.AssertHXRedirect(t, rec.Header().Get("HX-Redirect"), "/settings/tds")
+
+	updated, err := app.FindRecordById("tds_sections", section.Id)
+	if err != nil {
+		t.Fatalf("section not found: %v", err)
+	}
+	if updated.GetFloat("rate_percent") != 7.5 || updated.GetBool("active") {
+		t.Errorf("expected rate 7.5 and inactive, got %v and %v", updated.GetFloat("rate_percent"), updated.GetBool("active"))
+	}
+	added, err := app.FindFirstRecordByData("tds_sections", "section", "194-I(b)")
+	if err != nil || added.GetFloat("rate_percent") != 10 || !added.GetBool("active") {
+		t.Errorf("expected the new section at 10%% and active, got %v (%v)", added, err)
+	}
+}
+
+func TestHandleTDSSettingsSave_RejectsBadRate(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	section := findTDSSection(t, app, "194C", 2)
+
+	form := tdsSettingsForm(t, app)
+	form.Set("rate_"+section.Id, "150")
+	rec := postTDSSettings(t, app, form)
+
+	testhelpers.AssertHTMLContains(t, rec.Body.String(), "rate must be between 0 and 100")
+	unchanged, _ := app.FindRecordById("tds_sections", section.Id)
+	if unchanged.GetFloat("rate_percent") != 2 {
+		t.Errorf("expected the rate to stay at 2, got %v", unchanged.GetFloat("rate_percent"))
+	}
+}
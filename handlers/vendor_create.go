@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/a-h/templ"
@@ -62,6 +63,7 @@ func HandleVendorSave(app *pocketbase.PocketBase) func(*core.RequestEvent) error
 			BankAccountNo:       strings.TrimSpace(e.Request.FormValue("bank_account_no")),
 			BankIFSC:            strings.TrimSpace(e.Request.FormValue("bank_ifsc")),
 			BankBranch:          strings.TrimSpace(e.Request.FormValue("bank_branch")),
+			OpeningBalance:      strings.TrimSpace(e.Request.FormValue("opening_balance")),
 			Notes:               strings.TrimSpace(e.Request.FormValue("notes")),
 			Errors:              make(map[string]string),
 		}
@@ -94,7 +96,7 @@ func HandleVendorSave(app *pocketbase.PocketBase) func(*core.RequestEvent) error
 		record := core.NewRecord(vendorsCol)
 		setVendorFields(record, data)
 
-		if err := app.Save(record); err != nil {
+		if err := app.SaveWithContext(e.Request.Context(), record); err != nil {
 			log.Printf("vendor_create: could not save vendor: %v", err)
 			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
 		}
@@ -106,7 +108,7 @@ func HandleVendorSave(app *pocketbase.PocketBase) func(*core.RequestEvent) error
 				link := core.NewRecord(pvCol)
 				link.Set("project", projectID)
 				link.Set("vendor", record.Id)
-				if err := app.Save(link); err != nil {
+				if err := app.SaveWithContext(e.Request.Context(), link); err != nil {
 					log.Printf("vendor_create: could not link vendor to project: %v", err)
 				}
 			}
@@ -147,5 +149,16 @@ func setVendorFields(record *core.Record, data templates.VendorFormData) {
 	record.Set("bank_account_no", data.BankAccountNo)
 	record.Set("bank_ifsc", data.BankIFSC)
 	record.Set("bank_branch", data.BankBranch)
+	openingBalance, _ := strconv.ParseFloat(data.OpeningBalance, 64)
+	record.Set("opening_balance", openingBalance)
 	record.Set("notes", data.Notes)
 }
+
+// formatOpeningBalance returns a vendor's opening balance as a form value,
+// blank when there is none.
+func formatOpeningBalance(v float64) string {
+	if v == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -32,7 +32,7 @@ func HandleVendorDelete(app *pocketbase.PocketBase) func(*core.RequestEvent) err
 			return ErrorToast(e, http.StatusConflict, "Cannot delete vendor — it has existing purchase orders")
 		}
 
-		if err := app.Delete(record); err != nil {
+		if err := app.DeleteWithContext(e.Request.Context(), record); err != nil {
 			log.Printf("vendor_delete: failed to delete vendor %s: %v", vendorID, err)
 			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
 		}
@@ -45,6 +45,7 @@ func HandleVendorEdit(app *pocketbase.PocketBase) func(*core.RequestEvent) error
 			BankAccountNo:       record.GetString("bank_account_no"),
 			BankIFSC:            record.GetString("bank_ifsc"),
 			BankBranch:          record.GetString("bank_branch"),
+			OpeningBalance:      formatOpeningBalance(record.GetFloat("opening_balance")),
 			Notes:               record.GetString("notes"),
 			IsEdit:              true,
 			Errors:              make(map[string]string),
@@ -99,6 +100,7 @@ func HandleVendorUpdate(app *pocketbase.PocketBase) func(*core.RequestEvent) err
 			BankAccountNo:       strings.TrimSpace(e.Request.FormValue("bank_account_no")),
 			BankIFSC:            strings.TrimSpace(e.Request.FormValue("bank_ifsc")),
 			BankBranch:          strings.TrimSpace(e.Request.FormValue("bank_branch")),
+			OpeningBalance:      strings.TrimSpace(e.Request.FormValue("opening_balance")),
 			Notes:               strings.TrimSpace(e.Request.FormValue("notes")),
 			IsEdit:              true,
 			Errors:              make(map[string]string),
@@ -124,7 +126,7 @@ func HandleVendorUpdate(app *pocketbase.PocketBase) func(*core.RequestEvent) err
 
 		setVendorFields(record, data)
 
-		if err := app.Save(record); err != nil {
+		if err := app.SaveWithContext(e.Request.Context(), record); err != nil {
 			log.Printf("vendor_update: could not save vendor %s: %v", vendorID, err)
 			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
 		}
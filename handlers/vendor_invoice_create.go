@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/services"
+	"projectcreation/templates"
+)
+
+// fetchInvoiceablePOs returns the project's purchase orders that vendor
+// invoices can be recorded against.
+func fetchInvoiceablePOs(app *pocketbase.PocketBase, projectId string) []templates.GRNPOOption {
+	records, err := app.FindRecordsByFilter(
+		"purchase_orders",
+		"project = {:projectId} && (status = 'sent' || status = 'acknowledged' || status = 'completed')",
+		"-created",
+		0,
+		0,
+		map[string]any{"projectId": projectId},
+	)
+	if err != nil {
+		log.Printf("vendor_invoice_create: could not query purchase_orders: %v", err)
+		return nil
+	}
+
+	var options []templates.GRNPOOption
+	for _, po := range records {
+		vendorName := ""
+		if v, err := app.FindRecordById("vendors", po.GetString("vendor")); err == nil {
+			vendorName = v.GetString("name")
+		}
+		options = append(options, templates.GRNPOOption{
+			ID:         po.Id,
+			PONumber:   po.GetString("po_number"),
+			VendorName: vendorName,
+		})
+	}
+	return options
+}
+
+// findInvoiceablePO returns the purchase order if it belongs to the project
+// and vendor invoices can be recorded against it.
+func findInvoiceablePO(app *pocketbase.PocketBase, projectId, poID string) (*core.Record, error) {
+	po, err := app.FindRecordById("purchase_orders", poID)
+	if err != nil {
+		return nil, err
+	}
+	if po.GetString("project") != projectId {
+		return nil, fmt.Errorf("purchase order %s does not belong to project %s", poID, projectId)
+	}
+	if !slices.Contains(services.InvoiceableStatuses, po.GetString("status")) {
+		return nil, fmt.Errorf("purchase order %s is %s", poID, po.GetString("status"))
+	}
+	return po, nil
+}
+
+// vendorInvoiceCreateLines builds the form rows for every PO line. Without
+// entered values, the quantity defaults to what is accepted but not yet
+// invoiced, and the rate and GST to the PO's.
+func vendorInvoiceCreateLines(match []services.InvoiceMatchLine, entered map[string]services.InvoiceLineParams) []templates.VendorInvoiceCreateLine {
+	var lines []templates.VendorInvoiceCreateLine
+	for _, m := range match {
+		line := templates.VendorInvoiceCreateLine{
+			POLineItemID: m.POLineItemID,
+			Description:  m.Description,
+			UOM:          m.UOM,
+			Ordered:      formatQty(m.OrderedQty),
+			Accepted:     formatQty(m.AcceptedQty),
+			Invoiced:     formatQty(m.InvoicedQty),
+			PORate:       services.FormatINR(m.PORate),
+			POGST:        formatQty(m.POGSTPercent),
+		}
+		if p, ok := entered[m.POLineItemID]; ok {
+			line.Qty = formatQty(p.Qty)
+			line.Rate = formatQty(p.Rate)
+			line.GSTPercent = formatQty(p.GSTPercent)
+		} else {
+			line.Qty = formatQty(max(m.AcceptedQty-m.InvoicedQty, 0))
+			line.Rate = strconv.FormatFloat(m.PORate, 'f', -1, 64)
+			line.GSTPercent = formatQty(m.POGSTPercent)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// renderVendorInvoiceCreate renders the invoice form as a partial or full page.
+func renderVendorInvoiceCreate(e *core.RequestEvent, data templates.VendorInvoiceCreateData) error {
+	var component templ.Component
+	if e.Request.Header.Get("HX-Request") == "true" {
+		component = templates.VendorInvoiceCreateContent(data)
+	} else {
+		headerData := GetHeaderData(e.Request)
+		sidebarData := GetSidebarData(e.Request)
+		component = templates.VendorInvoiceCreatePage(data, headerData, sidebarData)
+	}
+	return component.Render(e.Request.Context(), e.Response)
+}
+
+// HandleVendorInvoiceCreate renders the vendor invoice form. The purchase
+// order is chosen with ?po=; all of its line items are listed for billing.
+func HandleVendorInvoiceCreate(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		projectId := e.Request.PathValue("projectId")
+
+		if _, err := app.FindRecordById("projects", projectId); err != nil {
+			return ErrorToast(e, http.StatusNotFound, "Project not found")
+		}
+
+		tol := services.ProjectInvoiceTolerance(app, projectId)
+		data := templates.VendorInvoiceCreateData{
+			ProjectID:   projectId,
+			POs:         fetchInvoiceablePOs(app, projectId),
+			InvoiceDate: time.Now().Format("2006-01-02"),
+			RatePct:     formatQty(tol.RatePct),
+			QtyPct:      formatQty(tol.QtyPct),
+			Errors:      make(map[string]string),
+		}
+
+		if poID := e.Request.URL.Query().Get("po"); poID != "" {
+			po, err := findInvoiceablePO(app, projectId, poID)
+			if err != nil {
+				log.Printf("vendor_invoice_create: %v", err)
+				return ErrorToast(e, http.StatusNotFound, "Invoices cannot be recorded against this purchase order")
+			}
+			match, err := services.ComputeInvoiceMatch(app, po.Id, "")
+			if err != nil {
+				log.Printf("vendor_invoice_create: could not load match for PO %s: %v", po.Id, err)
+				return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
+			}
+			data.POID = po.Id
+			data.PONumber = po.GetString("po_number")
+			if v, err := app.FindRecordById("vendors", po.GetString("vendor")); err == nil {
+				data.VendorName = v.GetString("name")
+				data.VendorGSTIN = v.GetString("gstin")
+				data.InvoiceGSTIN = data.VendorGSTIN
+			}
+			data.Lines = vendorInvoiceCreateLines(match, nil)
+		}
+
+		return renderVendorInvoiceCreate(e, data)
+	}
+}
+
+// HandleVendorInvoiceSave records a vendor invoice from the submitted form
+// and redirects to it. Mismatched invoices are saved with a warning.
+func HandleVendorInvoiceSave(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		if err := e.Request.ParseForm(); err != nil {
+			return ErrorToast(e, http.StatusBadRequest, "Invalid form data")
+		}
+
+		projectId := e.Request.PathValue("projectId")
+		poID := strings.TrimSpace(e.Request.FormValue("po_id"))
+
+		po, err := findInvoiceablePO(app, projectId, poID)
+		if err != nil {
+			log.Printf("vendor_invoice_create: %v", err)
+			return ErrorToast(e, http.StatusBadRequest, "Invoices cannot be recorded against this purchase order")
+		}
+		vendor, err := app.FindRecordById("vendors", po.GetString("vendor"))
+		if err != nil {
+			log.Printf("vendor_invoice_create: could not find vendor for PO %s: %v", po.Id, err)
+			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
+		}
+		match, err := services.ComputeInvoiceMatch(app, po.Id, "")
+		if err != nil {
+			log.Printf("vendor_invoice_create: could not load match for PO %s: %v", po.Id, err)
+			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
+		}
+
+		params := services.VendorInvoiceParams{
+			ProjectID:       projectId,
+			PurchaseOrderID: po.Id,
+			InvoiceNumber:   strings.TrimSpace(e.Request.FormValue("invoice_number")),
+			InvoiceDate:     strings.TrimSpace(e.Request.FormValue("invoice_date")),
+			VendorGSTIN:     strings.TrimSpace(e.Request.FormValue("vendor_gstin")),
+			Remarks:         strings.TrimSpace(e.Request.FormValue("remarks")),
+		}
+		entered := make(map[string]services.InvoiceLineParams)
+		for _, m := range match {
+			qty, _ := strconv.ParseFloat(e.Request.FormValue("qty_"+m.POLineItemID), 64)
+			rate, _ := strconv.ParseFloat(e.Request.FormValue("rate_"+m.POLineItemID), 64)
+			gst, _ := strconv.ParseFloat(e.Request.FormValue("gst_"+m.POLineItemID), 64)
+			line := services.InvoiceLineParams{
+				POLineItemID: m.POLineItemID,
+				Qty:          qty,
+				Rate:         rate,
+				GSTPercent:   gst,
+			}
+			entered[m.POLineItemID] = line
+			params.Lines = append(params.Lines, line)
+		}
+
+		if errors := services.ValidateVendorInvoice(app, po, vendor, params); len(errors) > 0 {
+			tol := services.ProjectInvoiceTolerance(app, projectId)
+			SetToast(e, "warning", "Please fix the errors below")
+			return renderVendorInvoiceCreate(e, templates.VendorInvoiceCreateData{
+				ProjectID:     projectId,
+				POs:           fetchInvoiceablePOs(app, projectId),
+				POID:          po.Id,
+				PONumber:      po.GetString("po_number"),
+				VendorName:    vendor.GetString("name"),
+				VendorGSTIN:   vendor.GetString("gstin"),
+				Lines:         vendorInvoiceCreateLines(match, entered),
+				InvoiceNumber: params.InvoiceNumber,
+				InvoiceDate:   params.InvoiceDate,
+				InvoiceGSTIN:  params.VendorGSTIN,
+				Remarks:       params.Remarks,
+				RatePct:       formatQty(tol.RatePct),
+				QtyPct:        formatQty(tol.QtyPct),
+				Errors:        errors,
+			})
+		}
+
+		result, err := services.CreateVendorInvoice(e.Request.Context(), app, params)
+		if err != nil {
+			log.Printf("vendor_invoice_create: could not create invoice for PO %s: %v", po.Id, err)
+			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
+		}
+
+		if result.MatchStatus == "mismatch" {
+			SetToast(e, "warning", fmt.Sprintf("Invoice %s recorded with %d mismatch(es) against %s", params.InvoiceNumber, len(result.Issues), po.GetString("po_number")))
+		} else {
+			SetToast(e, "success", fmt.Sprintf("Invoice %s recorded and matched", params.InvoiceNumber))
+		}
+
+		redirectURL := fmt.Sprintf("/projects/%s/invoices/%s", projectId, result.InvoiceID)
+		if e.Request.Header.Get("HX-Request") == "true" {
+			e.Response.Header().Set("HX-Redirect", redirectURL)
+			return e.String(http.StatusOK, "")
+		}
+		return e.Redirect(http.StatusFound, redirectURL)
+	}
+}
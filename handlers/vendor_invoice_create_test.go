@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/services"
+	"projectcreation/testhelpers"
+)
+
+// createReceivedPO creates a sent purchase order with one line item and a
+// GRN accepting the given quantity of it.
+func createReceivedPO(t *testing.T, app *pocketbase.PocketBase, projectID, poNumber string, qty, accepted float64) (*core.Record, *core.Record) {
+	t.Helper()
+	po, line := createSentPO(t, app, projectID, poNumber, qty)
+	_, err := services.CreateGRN(context.Background(), app, services.GRNParams{
+		ProjectID:       projectID,
+		PurchaseOrderID: po.Id,
+		ReceivedDate:    "2025-07-01",
+		Lines:           []services.GRNLineParams{{POLineItemID: line.Id, QtyReceived: accepted}},
+	})
+	if err != nil {
+		t.Fatalf("failed to create GRN: %v", err)
+	}
+	return po, line
+}
+
+func postVendorInvoice(t *testing.T, app *pocketbase.PocketBase, projectID string, form url.Values) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/projects/"+projectID+"/invoices", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("HX-Request", "true")
+	req.SetPathValue("projectId", projectID)
+	rec := httptest.NewRecorder()
+	if err := HandleVendorInvoiceSave(app)(newTestRequestEvent(app, req, rec)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	return rec
+}
+
+// invoiceForm returns a form billing qty of line at rate with 18% GST.
+func invoiceForm(po, line *core.Record, number, qty, rate string) url.Values {
+	form := url.Values{}
+	form.Set("po_id", po.Id)
+	form.Set("invoice_number", number)
+	form.Set("invoice_date", "2025-07-05")
+	form.Set("vendor_gstin", "27AADCB2230M1ZV")
+	form.Set("qty_"+line.Id, qty)
+	form.Set("rate_"+line.Id, rate)
+	form.Set("gst_"+line.Id, "18")
+	return form
+}
+
+func TestHandleVendorInvoiceCreate_GET_PrefillsAcceptedQty(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Invoice Project")
+	po, line := createReceivedPO(t, app, project.Id, "PO-INV-001", 4, 3)
+
+	req := httptest.NewRequest(http.MethodGet, "/projects/"+project.Id+"/invoices/create?po="+po.Id, nil)
+	req.SetPathValue("projectId", project.Id)
+	rec := httptest.NewRecorder()
+	if err := HandleVendorInvoiceCreate(app)(newTestRequestEvent(app, req, rec)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rec.Code)
+	}
+	body := rec.Body.String()
+	testhelpers.AssertHTMLContains(t, body, "PO-INV-001", "Solar Inverter", "qty_"+line.Id, "27AADCB2230M1ZV")
+	if !strings.Contains(body, `name="qty_`+line.Id+`" value="3"`) {
+		t.Error("expected the invoiced quantity to default to the accepted quantity")
+	}
+}
+
+func TestHandleVendorInvoiceSave_Matched(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Invoice Save Project")
+	po, line := createReceivedPO(t, app, project.Id, "PO-INV-002", 4, 4)
+
+	rec := postVendorInvoice(t, app, project.Id, invoiceForm(po, line, "VI-100", "4", "25000"))
+
+	invoices, err := app.FindRecordsByFilter("vendor_invoices", "purchase_order = {:poId}", "", 0, 0, map[string]any{"poId": po.Id})
+	if err != nil || len(invoices) != 1 {
+		t.Fatalf("expected one invoice, got %d (%v)", len(invoices), err)
+	}
+	testhelpers.AssertHXRedirect(t, rec.Header().Get("HX-Redirect"), "/projects/"+project.Id+"/invoices/"+invoices[0].Id)
+	if invoices[0].GetString("match_status") != "matched" {
+		t.Errorf("expected matched, got %q (%s)", invoices[0].GetString("match_status"), invoices[0].GetString("match_notes"))
+	}
+}
+
+func TestHandleVendorInvoiceSave_FlagsMismatch(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Invoice Mismatch Project")
+	po, line := createReceivedPO(t, app, project.Id, "PO-INV-003", 4, 2)
+
+	postVendorInvoice(t, app, project.Id, invoiceForm(po, line, "VI-200", "4", "26000"))
+
+	invoices, _ := app.FindRecordsByFilter("vendor_invoices", "purchase_order = {:poId}", "", 0, 0, map[string]any{"poId": po.Id})
+	if len(invoices) != 1 {
+		t.Fatalf("expected the mismatched invoice to be recorded, got %d", len(invoices))
+	}
+	if invoices[0].GetString("match_status") != "mismatch" {
+		t.Errorf("expected mismatch, got %q", invoices[0].GetString("match_status"))
+	}
+	notes := invoices[0].GetString("match_notes")
+	if !strings.Contains(notes, "above the PO rate") || !strings.Contains(notes, "accepted on GRNs") {
+		t.Errorf("expected rate and quantity issues, got %q", notes)
+	}
+}
+
+func TestHandleVendorInvoiceSave_ValidationErrors(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Invoice Invalid Project")
+	po, line := createReceivedPO(t, app, project.Id, "PO-INV-004", 4, 4)
+
+	form := invoiceForm(po, line, "VI-300", "4", "25000")
+	form.Set("vendor_gstin", "29AADCB2230M1ZV")
+	rec := postVendorInvoice(t, app, project.Id, form)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rec.Code)
+	}
+	testhelpers.AssertHTMLContains(t, rec.Body.String(), "does not match the vendor")
+
+	invoices, _ := app.FindAllRecords("vendor_invoices")
+	if len(invoices) != 0 {
+		t.Errorf("expected no invoice to be created, got %d", len(invoices))
+	}
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"log"
+
+	"github.com/a-h/templ"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/services"
+	"projectcreation/templates"
+)
+
+// vendorInvoiceListItems builds list rows for the given vendor invoices.
+func vendorInvoiceListItems(app *pocketbase.PocketBase, invoices []*core.Record) []templates.VendorInvoiceListItem {
+	var items []templates.VendorInvoiceListItem
+	for _, inv := range invoices {
+		item := templates.VendorInvoiceListItem{
+			ID:            inv.Id,
+			InvoiceNumber: inv.GetString("invoice_number"),
+			InvoiceDate:   inv.GetString("invoice_date"),
+			POID:          inv.GetString("purchase_order"),
+			MatchStatus:   inv.GetString("match_status"),
+		}
+		if v, err := app.FindRecordById("vendors", inv.GetString("vendor")); err == nil {
+			item.VendorName = v.GetString("name")
+		}
+
+		po, err := app.FindRecordById("purchase_orders", item.POID)
+		if err != nil {
+			log.Printf("vendor_invoice_list: could not find PO %s: %v", item.POID, err)
+			items = append(items, item)
+			continue
+		}
+		item.PONumber = po.GetString("po_number")
+
+		lines, err := app.FindRecordsByFilter("vendor_invoice_line_items", "vendor_invoice = {:id}", "", 0, 0, map[string]any{"id": inv.Id})
+		if err != nil {
+			log.Printf("vendor_invoice_list: could not query line items for invoice %s: %v", inv.Id, err)
+			lines = nil
+		}
+		item.Total = services.FormatINR(services.VendorInvoiceTotals(app, po, lines).GrandTotal)
+
+		items = append(items, item)
+	}
+	return items
+}
+
+// HandleVendorInvoiceList renders the vendor invoices of a project, newest first.
+func HandleVendorInvoiceList(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		projectId := e.Request.PathValue("projectId")
+
+		records, err := app.FindRecordsByFilter(
+			"vendor_invoices",
+			"project = {:projectId}",
+			"-created",
+			0,
+			0,
+			map[string]any{"projectId": projectId},
+		)
+		if err != nil {
+			log.Printf("vendor_invoice_list: could not query vendor_invoices: %v", err)
+			records = nil
+		}
+
+		items := vendorInvoiceListItems(app, records)
+		data := templates.VendorInvoiceListData{
+			Invoices:   items,
+			ProjectID:  projectId,
+			TotalCount: len(items),
+		}
+		for _, item := range items {
+			if item.MatchStatus == "mismatch" {
+				data.MismatchCount++
+			}
+		}
+
+		var component templ.Component
+		if e.Request.Header.Get("HX-Request") == "true" {
+			component = templates.VendorInvoiceListContent(data)
+		} else {
+			headerData := GetHeaderData(e.Request)
+			sidebarData := GetSidebarData(e.Request)
+			component = templates.VendorInvoiceListPage(data, headerData, sidebarData)
+		}
+		return component.Render(e.Request.Context(), e.Response)
+	}
+}
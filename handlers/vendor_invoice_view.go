@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/a-h/templ"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/services"
+	"projectcreation/templates"
+)
+
+// findProjectVendorInvoice returns the vendor invoice if it belongs to the project.
+func findProjectVendorInvoice(app *pocketbase.PocketBase, projectId, id string) (*core.Record, error) {
+	inv, err := app.FindRecordById("vendor_invoices", id)
+	if err != nil {
+		return nil, err
+	}
+	if inv.GetString("project") != projectId {
+		return nil, fmt.Errorf("vendor invoice %s does not belong to project %s", id, projectId)
+	}
+	return inv, nil
+}
+
+// HandleVendorInvoiceView renders a vendor invoice with the match result
+// recorded when it was entered.
+func HandleVendorInvoiceView(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		projectId := e.Request.PathValue("projectId")
+		id := e.Request.PathValue("id")
+
+		inv, err := findProjectVendorInvoice(app, projectId, id)
+		if err != nil {
+			log.Printf("vendor_invoice_view: %v", err)
+			return e.String(http.StatusNotFound, "Vendor invoice not found")
+		}
+		po, err := app.FindRecordById("purchase_orders", inv.GetString("purchase_order"))
+		if err != nil {
+			log.Printf("vendor_invoice_view: could not find PO for invoice %s: %v", id, err)
+			return e.String(http.StatusInternalServerError, "Failed to load vendor invoice")
+		}
+		lines, err := app.FindRecordsByFilter("vendor_invoice_line_items", "vendor_invoice = {:id}", "created", 0, 0, map[string]any{"id": inv.Id})
+		if err != nil {
+			log.Printf("vendor_invoice_view: could not query line items for invoice %s: %v", id, err)
+			return e.String(http.StatusInternalServerError, "Failed to load vendor invoice")
+		}
+
+		data := templates.VendorInvoiceViewData{
+			ProjectID:     projectId,
+			InvoiceID:     inv.Id,
+			InvoiceNumber: inv.GetString("invoice_number"),
+			InvoiceDate:   inv.GetString("invoice_date"),
+			POID:          po.Id,
+			PONumber:      po.GetString("po_number"),
+			InvoiceGSTIN:  inv.GetString("vendor_gstin"),
+			MatchStatus:   inv.GetString("match_status"),
+			Remarks:       inv.GetString("remarks"),
+		}
+		if notes := inv.GetString("match_notes"); notes != "" {
+			data.MatchNotes = strings.Split(notes, "\n")
+		}
+		if v, err := app.FindRecordById("vendors", inv.GetString("vendor")); err == nil {
+			data.VendorName = v.GetString("name")
+			data.VendorGSTIN = v.GetString("gstin")
+		}
+
+		for i, li := range lines {
+			calc := services.CalcPOLineItem(li.GetFloat("rate"), li.GetFloat("qty"), li.GetFloat("gst_percent"))
+			data.LineItems = append(data.LineItems, templates.VendorInvoiceViewLineItem{
+				SINo:        i + 1,
+				Description: li.GetString("description"),
+				UOM:         li.GetString("uom"),
+				Qty:         formatQty(calc.Qty),
+				Rate:        services.FormatINR(calc.Rate),
+				GSTPercent:  formatQty(calc.GSTPercent),
+				Amount:      services.FormatINR(calc.BeforeGST),
+			})
+		}
+		totals := services.VendorInvoiceTotals(app, po, lines)
+		data.TotalBeforeTax = services.FormatINR(totals.TotalBeforeTax)
+		data.TaxRows = poTaxRows(totals)
+		data.GrandTotal = services.FormatINR(totals.GrandTotal)
+
+		var component templ.Component
+		if e.Request.Header.Get("HX-Request") == "true" {
+			component = templates.VendorInvoiceViewContent(data)
+		} else {
+			headerData := GetHeaderData(e.Request)
+			sidebarData := GetSidebarData(e.Request)
+			component = templates.VendorInvoiceViewPage(data, headerData, sidebarData)
+		}
+		return component.Render(e.Request.Context(), e.Response)
+	}
+}
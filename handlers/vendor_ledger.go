@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+
+	"github.com/a-h/templ"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/services"
+	"projectcreation/templates"
+)
+
+// ledgerAmount formats a debit or credit cell, blank when zero.
+func ledgerAmount(v float64) string {
+	if v == 0 {
+		return ""
+	}
+	return services.FormatINR(v)
+}
+
+// HandleVendorLedger renders a vendor's running ledger, optionally limited
+// to the period given by the from and to query parameters. Users who are not
+// admins only see invoices on their own projects.
+func HandleVendorLedger(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		vendorID := e.Request.PathValue("id")
+		from := e.Request.URL.Query().Get("from")
+		to := e.Request.URL.Query().Get("to")
+
+		vendor, err := app.FindRecordById("vendors", vendorID)
+		if err != nil {
+			return ErrorToast(e, http.StatusNotFound, "Vendor not found")
+		}
+
+		user := GetCurrentUser(e.Request)
+		ledger, err := services.BuildVendorLedger(app, vendorID, from, to, func(projectID string) bool {
+			return CanAccessProject(user, projectID)
+		})
+		if err != nil {
+			log.Printf("vendor_ledger: could not build ledger for vendor %s: %v", vendorID, err)
+			return ErrorToast(e, http.StatusInternalServerError, "Could not load the vendor ledger")
+		}
+
+		query := url.Values{}
+		if from != "" {
+			query.Set("from", from)
+		}
+		if to != "" {
+			query.Set("to", to)
+		}
+		exportURL := "/vendors/" + vendorID + "/ledger/export/excel"
+		if len(query) > 0 {
+			exportURL += "?" + query.Encode()
+		}
+
+		data := templates.VendorLedgerData{
+			VendorID:       vendorID,
+			VendorName:     ledger.VendorName,
+			VendorGSTIN:    ledger.VendorGSTIN,
+			VendorPAN:      ledger.VendorPAN,
+			BankName:       vendor.GetString("bank_name"),
+			BankAccountNo:  vendor.GetString("bank_account_no"),
+			BankIFSC:       vendor.GetString("bank_ifsc"),
+			From:           from,
+			To:             to,
+			ExportURL:      exportURL,
+			OpeningBalance: services.FormatINR(ledger.OpeningBalance),
+			TotalDebit:     services.FormatINR(ledger.TotalDebit),
+			TotalCredit:    services.FormatINR(ledger.TotalCredit),
+			ClosingBalance: services.FormatINR(ledger.ClosingBalance),
+		}
+		for _, entry := range ledger.Entries {
+			data.Rows = append(data.Rows, templates.VendorLedgerRow{
+				Date:      entry.Date,
+				Type:      services.LedgerKindLabel(entry.Kind),
+				Reference: entry.Reference,
+				Narration: entry.Narration,
+				Debit:     ledgerAmount(entry.Debit),
+				Credit:    ledgerAmount(entry.Credit),
+				Balance:   services.FormatINR(entry.Balance),
+			})
+		}
+
+		var component templ.Component
+		if e.Request.Header.Get("HX-Request") == "true" {
+			component = templates.VendorLedgerContent(data)
+		} else {
+			headerData := GetHeaderData(e.Request)
+			sidebarData := GetSidebarData(e.Request)
+			component = templates.VendorLedgerPage(data, headerData, sidebarData)
+		}
+		return component.Render(e.Request.Context(), e.Response)
+	}
+}
+
+// HandleVendorLedgerExportExcel generates and downloads a vendor's ledger
+// as an Excel file, for the same period as the ledger page.
+func HandleVendorLedgerExportExcel(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		vendorID := e.Request.PathValue("id")
+		from := e.Request.URL.Query().Get("from")
+		to := e.Request.URL.Query().Get("to")
+
+		if _, err := app.FindRecordById("vendors", vendorID); err != nil {
+			return e.String(http.StatusNotFound, "Vendor not found")
+		}
+
+		user := GetCurrentUser(e.Request)
+		ledger, err := services.BuildVendorLedger(app, vendorID, from, to, func(projectID string) bool {
+			return CanAccessProject(user, projectID)
+		})
+		if err != nil {
+			log.Printf("vendor_ledger_export: could not build ledger for vendor %s: %v", vendorID, err)
+			return e.String(http.StatusInternalServerError, "Failed to build vendor ledger")
+		}
+
+		xlsxBytes, err := services.GenerateVendorLedgerExcel(ledger)
+		if err != nil {
+			log.Printf("vendor_ledger_export: generate failed: %v", err)
+			return e.String(http.StatusInternalServerError, "Failed to generate Excel file")
+		}
+
+		filename := fmt.Sprintf("%s_Ledger.xlsx", sanitizeFilename(ledger.VendorName))
+
+		e.Response.Header().Set("Content-Type",
+			"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		e.Response.Header().Set("Content-Disposition",
+			fmt.Sprintf(`attachment; filename="%s"`, filename))
+		e.Response.Write(xlsxBytes)
+		return nil
+	}
+}
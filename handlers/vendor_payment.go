@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/collections"
+	"projectcreation/services"
+	"projectcreation/templates"
+)
+
+// outstandingInvoiceBalances returns the vendor's invoices that still have
+// something left to pay, oldest first, on the projects the user can see.
+func outstandingInvoiceBalances(app *pocketbase.PocketBase, vendorID string, user *core.Record) ([]services.InvoiceBalance, error) {
+	balances, err := services.VendorInvoiceBalances(app, vendorID)
+	if err != nil {
+		return nil, err
+	}
+	var outstanding []services.InvoiceBalance
+	for _, b := range balances {
+		if b.Outstanding() > 0.005 && CanAccessProject(user, b.ProjectID) {
+			outstanding = append(outstanding, b)
+		}
+	}
+	return outstanding, nil
+}
+
+// tdsSectionOptions returns the active TDS sections for the payment form.
+func tdsSectionOptions(app *pocketbase.PocketBase) []templates.TDSSectionOption {
+	sections, err := collections.GetTDSSections(app)
+	if err != nil {
+		log.Printf("vendor_payment: could not load TDS sections: %v", err)
+		return nil
+	}
+	var options []templates.TDSSectionOption
+	for _, s := range sections {
+		if !s.GetBool("active") {
+			continue
+		}
+		label := s.GetString("section")
+		if desc := s.GetString("description"); desc != "" {
+			label += " — " + desc
+		}
+		options = append(options, templates.TDSSectionOption{
+			ID:    s.Id,
+			Label: fmt.Sprintf("%s (%s%%)", label, formatQty(s.GetFloat("rate_percent"))),
+		})
+	}
+	return options
+}
+
+// newVendorPaymentCreateData builds the payment form for a vendor. amounts
+// holds what has been entered against each invoice, keyed by invoice ID.
+func newVendorPaymentCreateData(app *pocketbase.PocketBase, vendor *core.Record, balances []services.InvoiceBalance, amounts map[string]string) templates.VendorPaymentCreateData {
+	var bank []string
+	for _, field := range []string{"bank_beneficiary_name", "bank_name", "bank_account_no", "bank_ifsc"} {
+		if v := vendor.GetString(field); v != "" {
+			bank = append(bank, v)
+		}
+	}
+	data := templates.VendorPaymentCreateData{
+		VendorID:    vendor.Id,
+		VendorName:  vendor.GetString("name"),
+		BankSummary: strings.Join(bank, " · "),
+		TDSSections: tdsSectionOptions(app),
+		Errors:      make(map[string]string),
+	}
+	for _, mode := range services.PaymentModes {
+		data.Modes = append(data.Modes, templates.PaymentModeOption{
+			Value: mode,
+			Label: services.PaymentModeLabel(mode),
+		})
+	}
+	for _, b := range balances {
+		data.Invoices = append(data.Invoices, templates.VendorPaymentInvoiceRow{
+			InvoiceID:     b.InvoiceID,
+			InvoiceNumber: b.InvoiceNumber,
+			InvoiceDate:   b.InvoiceDate,
+			ProjectName:   b.ProjectName,
+			PONumber:      b.PONumber,
+			Total:         services.FormatINR(b.Total),
+			Settled:       services.FormatINR(b.Settled),
+			Outstanding:   services.FormatINR(b.Outstanding()),
+			Amount:        amounts[b.InvoiceID],
+		})
+	}
+	return data
+}
+
+// renderVendorPaymentCreate renders the payment form as a partial or full page.
+func renderVendorPaymentCreate(e *core.RequestEvent, data templates.VendorPaymentCreateData) error {
+	var component templ.Component
+	if e.Request.Header.Get("HX-Request") == "true" {
+		component = templates.VendorPaymentCreateContent(data)
+	} else {
+		headerData := GetHeaderData(e.Request)
+		sidebarData := GetSidebarData(e.Request)
+		component = templates.VendorPaymentCreatePage(data, headerData, sidebarData)
+	}
+	return component.Render(e.Request.Context(), e.Response)
+}
+
+// HandleVendorPaymentCreate renders the payment form with the vendor's
+// outstanding invoices.
+func HandleVendorPaymentCreate(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		vendor, err := app.FindRecordById("vendors", e.Request.PathValue("id"))
+		if err != nil {
+			return ErrorToast(e, http.StatusNotFound, "Vendor not found")
+		}
+
+		balances, err := outstandingInvoiceBalances(app, vendor.Id, GetCurrentUser(e.Request))
+		if err != nil {
+			log.Printf("vendor_payment: could not load invoices for vendor %s: %v", vendor.Id, err)
+			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
+		}
+
+		data := newVendorPaymentCreateData(app, vendor, balances, nil)
+		data.PaymentDate = time.Now().Format("2006-01-02")
+		data.Mode = "neft"
+		return renderVendorPaymentCreate(e, data)
+	}
+}
+
+// HandleVendorPaymentSave records a vendor payment from the submitted form
+// and redirects to the vendor's ledger.
+func HandleVendorPaymentSave(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		if err := e.Request.ParseForm(); err != nil {
+			return ErrorToast(e, http.StatusBadRequest, "Invalid form data")
+		}
+
+		vendor, err := app.FindRecordById("vendors", e.Request.PathValue("id"))
+		if err != nil {
+			return ErrorToast(e, http.StatusNotFound, "Vendor not found")
+		}
+
+		list, err := outstandingInvoiceBalances(app, vendor.Id, GetCurrentUser(e.Request))
+		if err != nil {
+			log.Printf("vendor_payment: could not load invoices for vendor %s: %v", vendor.Id, err)
+			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
+		}
+		listed := make(map[string]bool, len(list))
+		for _, b := range list {
+			listed[b.InvoiceID] = true
+		}
+		// Amounts against invoices the form did not offer, such as those on
+		// projects the user cannot see, are refused rather than dropped
+		for key, values := range e.Request.PostForm {
+			invoiceID, ok := strings.CutPrefix(key, "alloc_")
+			if !ok || listed[invoiceID] {
+				continue
+			}
+			if amount, _ := strconv.ParseFloat(strings.TrimSpace(strings.Join(values, "")), 64); amount != 0 {
+				return ErrorToast(e, http.StatusForbidden, "You cannot pay an invoice that is not listed for you")
+			}
+		}
+
+		params := services.VendorPaymentParams{
+			VendorID:     vendor.Id,
+			PaymentDate:  strings.TrimSpace(e.Request.FormValue("payment_date")),
+			Mode:         e.Request.FormValue("mode"),
+			UTRReference: strings.TrimSpace(e.Request.FormValue("utr_reference")),
+			TDSSectionID: e.Request.FormValue("tds_section"),
+			Remarks:      strings.TrimSpace(e.Request.FormValue("remarks")),
+		}
+		balances := make(map[string]services.InvoiceBalance, len(list))
+		amounts := make(map[string]string, len(list))
+		for _, b := range list {
+			balances[b.InvoiceID] = b
+			raw := strings.TrimSpace(e.Request.FormValue("alloc_" + b.InvoiceID))
+			amounts[b.InvoiceID] = raw
+			amount, _ := strconv.ParseFloat(raw, 64)
+			params.Allocations = append(params.Allocations, services.PaymentAllocationParams{
+				VendorInvoiceID: b.InvoiceID,
+				Amount:          amount,
+			})
+		}
+
+		if errors := services.ValidateVendorPayment(app, params, balances); len(errors) > 0 {
+			SetToast(e, "warning", "Please fix the errors below")
+			data := newVendorPaymentCreateData(app, vendor, list, amounts)
+			data.PaymentDate = params.PaymentDate
+			data.Mode = params.Mode
+			data.UTRReference = params.UTRReference
+			data.TDSSectionID = params.TDSSectionID
+			data.Remarks = params.Remarks
+			data.Errors = errors
+			return renderVendorPaymentCreate(e, data)
+		}
+
+		result, err := services.CreateVendorPayment(e.Request.Context(), app, params)
+		if err != nil {
+			log.Printf("vendor_payment: could not record payment for vendor %s: %v", vendor.Id, err)
+			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
+		}
+
+		msg := fmt.Sprintf("Payment of %s recorded", services.FormatINR(result.NetAmount))
+		if result.TDSAmount > 0 {
+			msg += fmt.Sprintf(" (TDS %s deducted)", services.FormatINR(result.TDSAmount))
+		}
+		SetToast(e, "success", msg)
+
+		redirectURL := fmt.Sprintf("/vendors/%s/ledger", vendor.Id)
+		if e.Request.Header.Get("HX-Request") == "true" {
+			e.Response.Header().Set("HX-Redirect", redirectURL)
+			return e.String(http.StatusOK, "")
+		}
+		return e.Redirect(http.StatusFound, redirectURL)
+	}
+}
@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/collections"
+	"projectcreation/testhelpers"
+)
+
+// createInvoicedVendor records a ₹1,18,000 invoice (4 × 25,000 + 18% GST)
+// and returns its vendor and invoice.
+func createInvoicedVendor(t *testing.T, app *pocketbase.PocketBase, projectName string) (*core.Record, *core.Record) {
+	t.Helper()
+	project := testhelpers.CreateTestProject(t, app, projectName)
+	po, line := createReceivedPO(t, app, project.Id, "PO-PAY-001", 4, 4)
+	postVendorInvoice(t, app, project.Id, invoiceForm(po, line, "VI-PAY-1", "4", "25000"))
+
+	invoices, err := app.FindRecordsByFilter("vendor_invoices", "purchase_order = {:poId}", "", 0, 0, map[string]any{"poId": po.Id})
+	if err != nil || len(invoices) != 1 {
+		t.Fatalf("expected one invoice, got %d (%v)", len(invoices), err)
+	}
+	vendor, err := app.FindRecordById("vendors", po.GetString("vendor"))
+	if err != nil {
+		t.Fatalf("vendor not found: %v", err)
+	}
+	return vendor, invoices[0]
+}
+
+// findTDSSection returns the default TDS section with the given section and rate.
+func findTDSSection(t *testing.T, app *pocketbase.PocketBase, section string, rate float64) *core.Record {
+	t.Helper()
+	sections, err := collections.GetTDSSections(app)
+	if err != nil {
+		t.Fatalf("GetTDSSections failed: %v", err)
+	}
+	for _, s := range sections {
+		if s.GetString("section") == section && s.GetFloat("rate_percent") == rate {
+			return s
+		}
+	}
+	t.Fatalf("TDS section %s @ %v%% not found", section, rate)
+	return nil
+}
+
+func postVendorPayment(t *testing.T, app *pocketbase.PocketBase, vendorID string, form url.Values) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/vendors/"+vendorID+"/payments", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("HX-Request", "true")
+	req.SetPathValue("id", vendorID)
+	rec := httptest.NewRecorder()
+	if err := HandleVendorPaymentSave(app)(newTestRequestEvent(app, req, rec)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	return rec
+}
+
+func TestHandleVendorPaymentCreate_ListsOutstandingInvoices(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	vendor, invoice := createInvoicedVendor(t, app, "Payment Form Project")
+
+	req := httptest.NewRequest(http.MethodGet, "/vendors/"+vendor.Id+"/payments/create", nil)
+	req.Header.Set("HX-Request", "true")
+	req.SetPathValue("id", vendor.Id)
+	rec := httptest.NewRecorder()
+	if err := HandleVendorPaymentCreate(app)(newTestRequestEvent(app, req, rec)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+
+	body := rec.Body.String()
+	testhelpers.AssertHTMLContains(t, body, "VI-PAY-1", "alloc_"+invoice.Id, "194C")
+}
+
+func TestHandleVendorPaymentSave_DeductsTDS(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	vendor, invoice := createInvoicedVendor(t, app, "Payment Save Project")
+	section := findTDSSection(t, app, "194C", 2)
+
+	form := url.Values{}
+	form.Set("payment_date", "2025-07-10")
+	form.Set("mode", "neft")
+	form.Set("utr_reference", "UTR0001")
+	form.Set("tds_section", section.Id)
+	form.Set("alloc_"+invoice.Id, "118000")
+	rec := postVendorPayment(t, app, vendor.Id, form)
+
+	testhelpers.AssertHXRedirect(t, rec.Header().Get("HX-Redirect"), "/vendors/"+vendor.Id+"/ledger")
+
+	payments, err := app.FindRecordsByFilter("vendor_payments", "vendor = {:vendor}", "", 0, 0, map[string]any{"vendor": vendor.Id})
+	if err != nil || len(payments) != 1 {
+		t.Fatalf("expected one payment, got %d (%v)", len(payments), err)
+	}
+	// TDS is 2% of the pre-GST ₹1,00,000
+	if payments[0].GetFloat("tds_amount") != 2000 || payments[0].GetFloat("net_amount") != 116000 {
+		t.Errorf("expected TDS 2000 and net 116000, got %v and %v",
+			payments[0].GetFloat("tds_amount"), payments[0].GetFloat("net_amount"))
+	}
+}
+
+func TestHandleVendorPayment_HidesOtherProjectsInvoices(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	vendor, invoice := createInvoicedVendor(t, app, "Hidden Payment Project")
+	other := testhelpers.CreateTestProject(t, app, "Other Project")
+	member := testhelpers.CreateTestUser(t, app, "member@example.com", "purchase", other.Id)
+
+	req := httptest.NewRequest(http.MethodGet, "/vendors/"+vendor.Id+"/payments/create", nil)
+	req.Header.Set("HX-Request", "true")
+	req.SetPathValue("id", vendor.Id)
+	req = req.WithContext(context.WithValue(req.Context(), CurrentUserKey, member))
+	rec := httptest.NewRecorder()
+	if err := HandleVendorPaymentCreate(app)(newTestRequestEvent(app, req, rec)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	if body := rec.Body.String(); strings.Contains(body, "VI-PAY-1") || strings.Contains(body, "Hidden Payment Project") {
+		t.Error("expected another project's invoices to be hidden from the payment form")
+	}
+
+	form := url.Values{}
+	form.Set("payment_date", "2025-07-10")
+	form.Set("mode", "cash")
+	form.Set("alloc_"+invoice.Id, "18000")
+	req = httptest.NewRequest(http.MethodPost, "/vendors/"+vendor.Id+"/payments", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("HX-Request", "true")
+	req.SetPathValue("id", vendor.Id)
+	req = req.WithContext(context.WithValue(req.Context(), CurrentUserKey, member))
+	rec = httptest.NewRecorder()
+	if err := HandleVendorPaymentSave(app)(newTestRequestEvent(app, req, rec)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for a payment against a hidden invoice, got %d", rec.Code)
+	}
+	if payments, _ := app.FindAllRecords("vendor_payments"); len(payments) != 0 {
+		t.Errorf("expected no payment to be recorded, got %d", len(payments))
+	}
+}
+
+func TestHandleVendorPaymentSave_ValidationErrors(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	vendor, invoice := createInvoicedVendor(t, app, "Payment Invalid Project")
+
+	form := url.Values{}
+	form.Set("payment_date", "2025-07-10")
+	form.Set("mode", "neft")
+	form.Set("alloc_"+invoice.Id, "200000")
+	rec := postVendorPayment(t, app, vendor.Id, form)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rec.Code)
+	}
+	testhelpers.AssertHTMLContains(t, rec.Body.String(), "is outstanding", "UTR / cheque reference is required")
+
+	payments, _ := app.FindAllRecords("vendor_payments")
+	if len(payments) != 0 {
+		t.Errorf("expected no payment to be recorded, got %d", len(payments))
+	}
+}
+
+func TestHandleVendorLedger_ShowsClosingPayable(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	vendor, invoice := createInvoicedVendor(t, app, "Ledger Project")
+
+	form := url.Values{}
+	form.Set("payment_date", "2025-07-10")
+	form.Set("mode", "cash")
+	form.Set("alloc_"+invoice.Id, "18000")
+	postVendorPayment(t, app, vendor.Id, form)
+
+	req := httptest.NewRequest(http.MethodGet, "/vendors/"+vendor.Id+"/ledger", nil)
+	req.Header.Set("HX-Request", "true")
+	req.SetPathValue("id", vendor.Id)
+	rec := httptest.NewRecorder()
+	if err := HandleVendorLedger(app)(newTestRequestEvent(app, req, rec)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	testhelpers.AssertHTMLContains(t, rec.Body.String(), "VI-PAY-1", "Payment against VI-PAY-1", "1,00,000")
+
+	req = httptest.NewRequest(http.MethodGet, "/vendors/"+vendor.Id+"/ledger/export/excel", nil)
+	req.SetPathValue("id", vendor.Id)
+	rec = httptest.NewRecorder()
+	if err := HandleVendorLedgerExportExcel(app)(newTestRequestEvent(app, req, rec)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet" {
+		t.Errorf("unexpected content-type: %s", ct)
+	}
+	if rec.Body.Len() == 0 {
+		t.Error("expected non-empty response body")
+	}
+
+	// A member of another project sees neither the invoice nor the payment
+	other := testhelpers.CreateTestProject(t, app, "Other Project")
+	member := testhelpers.CreateTestUser(t, app, "member@example.com", "purchase", other.Id)
+	req = httptest.NewRequest(http.MethodGet, "/vendors/"+vendor.Id+"/ledger", nil)
+	req.Header.Set("HX-Request", "true")
+	req.SetPathValue("id", vendor.Id)
+	req = req.WithContext(context.WithValue(req.Context(), CurrentUserKey, member))
+	rec = httptest.NewRecorder()
+	if err := HandleVendorLedger(app)(newTestRequestEvent(app, req, rec)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	if body := rec.Body.String(); strings.Contains(body, "VI-PAY-1") || strings.Contains(body, "Ledger Project") {
+		t.Error("expected another project's invoices to be hidden")
+	}
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/collections"
+	"projectcreation/services"
+	"projectcreation/templates"
+)
+
+// documentVerifyQR returns the QR code content printed on a DC or PO PDF,
+// or "" if the document cannot be signed.
+func documentVerifyQR(app *pocketbase.PocketBase, kind, id, title, number, date string, total float64) string {
+	key, err := collections.GetVerificationKey(app)
+	if err != nil {
+		log.Printf("verify: could not load verification key: %v", err)
+		return ""
+	}
+	url := strings.TrimRight(app.Settings().Meta.AppURL, "/") + "/verify/" + services.SignVerificationToken(key, kind, id, total)
+	return services.DocumentQRContent(title, number, date, total, url)
+}
+
+// HandleVerifyDocument shows whether the document behind a QR code's token
+// is genuine and still valid (GET /verify/{token}). It is public, so it
+// shows nothing beyond the document's number, date, total and status.
+func HandleVerifyDocument(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		data := templates.VerifyData{
+			CompanyName: collections.GetCompanyName(app),
+			LogoURL:     collections.GetLogoURL(app),
+		}
+
+		key, err := collections.GetVerificationKey(app)
+		if err != nil {
+			log.Printf("verify: could not load verification key: %v", err)
+			e.Response.WriteHeader(http.StatusInternalServerError)
+			return templates.VerifyPage(data).Render(e.Request.Context(), e.Response)
+		}
+
+		kind, id, total, ok := services.ParseVerificationToken(key, e.Request.PathValue("token"))
+		if ok {
+			doc, err := services.VerifyDocument(app, kind, id, total)
+			if err != nil {
+				log.Printf("verify: signed %s %s no longer exists: %v", kind, id, err)
+			} else {
+				data.Document = doc
+			}
+		}
+		if data.Document == nil {
+			e.Response.WriteHeader(http.StatusNotFound)
+		}
+		return templates.VerifyPage(data).Render(e.Request.Context(), e.Response)
+	}
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase"
+
+	"projectcreation/collections"
+	"projectcreation/services"
+	"projectcreation/testhelpers"
+)
+
+func getVerify(t *testing.T, app *pocketbase.PocketBase, token string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/verify/"+token, nil)
+	req.SetPathValue("token", token)
+	rec := httptest.NewRecorder()
+	if err := HandleVerifyDocument(app)(newTestRequestEvent(app, req, rec)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	return rec
+}
+
+func TestHandleVerifyDocument(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Secret Client Project")
+	dc := createInvoiceableDC(t, app, project.Id, "ODC-001")
+	vendor := testhelpers.CreateTestVendor(t, app, "Test Vendor")
+	po := testhelpers.CreateTestPurchaseOrder(t, app, project.Id, vendor.Id, "PO-001")
+
+	key, err := collections.GetVerificationKey(app)
+	if err != nil {
+		t.Fatalf("GetVerificationKey failed: %v", err)
+	}
+	if again, _ := collections.GetVerificationKey(app); string(again) != string(key) {
+		t.Fatal("expected the verification key to be kept")
+	}
+	dcToken := services.SignVerificationToken(key, services.VerifyKindDC, dc.Id, 70800)
+
+	rec := getVerify(t, app, dcToken)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	testhelpers.AssertHTMLContains(t, rec.Body.String(), "GENUINE", "Delivery Challan", "ODC-001", "2026-03-10", "70,800.00")
+	if strings.Contains(rec.Body.String(), "Secret Client Project") {
+		t.Error("expected the page not to show the project")
+	}
+
+	dc.Set("status", "cancelled")
+	if err := app.Save(dc); err != nil {
+		t.Fatalf("failed to cancel DC: %v", err)
+	}
+	testhelpers.AssertHTMLContains(t, getVerify(t, app, dcToken).Body.String(), "CANCELLED", "ODC-001")
+
+	// Test POs start as drafts
+	rec = getVerify(t, app, services.SignVerificationToken(key, services.VerifyKindPO, po.Id, 0))
+	testhelpers.AssertHTMLContains(t, rec.Body.String(), "NOT ISSUED", "Purchase Order", "PO-001")
+	if strings.Contains(rec.Body.String(), "Total") {
+		t.Error("expected no total for a document printed without one")
+	}
+
+	// A token pointed at another document fails its signature
+	forged := strings.Replace(dcToken, dc.Id, po.Id, 1)
+	rec = getVerify(t, app, forged)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for a forged token, got %d", rec.Code)
+	}
+	testhelpers.AssertHTMLContains(t, rec.Body.String(), "NOT VERIFIED")
+	if strings.Contains(rec.Body.String(), "PO-001") {
+		t.Error("expected a forged token to show no document")
+	}
+
+	// So does a token whose total was altered
+	altered := strings.Replace(dcToken, ".7080000.", ".9080000.", 1)
+	if rec = getVerify(t, app, altered); rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for an altered total, got %d", rec.Code)
+	}
+}
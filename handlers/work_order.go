@@ -0,0 +1,438 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/services"
+	"projectcreation/templates"
+)
+
+// siteArea returns the district and mandal of an install-at site.
+func siteArea(rec *core.Record) string {
+	var parts []string
+	for _, p := range []string{rec.GetString("district_name"), rec.GetString("mandal_name")} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return strings.Join(parts, " / ")
+}
+
+// assignedSiteIDs returns the install-at sites of a project already on a
+// work order.
+func assignedSiteIDs(app *pocketbase.PocketBase, projectId string) (map[string]bool, error) {
+	sites, err := app.FindRecordsByFilter("work_order_sites", "work_order.project = {:projectId}", "", 0, 0, map[string]any{"projectId": projectId})
+	if err != nil {
+		return nil, err
+	}
+	assigned := make(map[string]bool, len(sites))
+	for _, s := range sites {
+		assigned[s.GetString("install_at_address")] = true
+	}
+	return assigned, nil
+}
+
+// workOrderCreateData builds the work order form, keeping what was entered
+// when params is not nil.
+func workOrderCreateData(app *pocketbase.PocketBase, projectId string, params *services.WorkOrderParams, form map[string]string) templates.WorkOrderCreateData {
+	data := templates.WorkOrderCreateData{
+		ProjectID:  projectId,
+		Installers: fetchLinkedVendors(app, projectId),
+		WODate:     time.Now().Format("2006-01-02"),
+		Errors:     make(map[string]string),
+	}
+	selectedSites := make(map[string]bool)
+	if params != nil {
+		data.InstallerID = params.InstallerID
+		data.WODate = params.WODate
+		data.TargetDate = params.TargetDate
+		data.Remarks = params.Remarks
+		for _, id := range params.SiteIDs {
+			selectedSites[id] = true
+		}
+	}
+
+	items, err := services.ServiceBOQItems(app, projectId)
+	if err != nil {
+		log.Printf("work_order: could not load service items for project %s: %v", projectId, err)
+	}
+	for _, it := range items {
+		item := templates.WorkOrderCreateItem{
+			SourceItemID: it.SourceItemID,
+			Description:  it.Description,
+			UOM:          it.UOM,
+			BOQQty:       formatQty(it.Qty),
+			QtyPerSite:   "1",
+			Rate:         strconv.FormatFloat(it.Rate, 'f', -1, 64),
+		}
+		if params != nil {
+			item.Selected = form["item_"+it.SourceItemID] != ""
+			item.QtyPerSite = form["qty_"+it.SourceItemID]
+			item.Rate = form["rate_"+it.SourceItemID]
+		}
+		data.Items = append(data.Items, item)
+	}
+
+	assigned, err := assignedSiteIDs(app, projectId)
+	if err != nil {
+		log.Printf("work_order: could not load work order sites for project %s: %v", projectId, err)
+	}
+	sites, err := app.FindRecordsByFilter("addresses", "project = {:projectId} && address_type = 'install_at'", "address_code,created", 0, 0, map[string]any{"projectId": projectId})
+	if err != nil {
+		log.Printf("work_order: could not load install-at sites for project %s: %v", projectId, err)
+	}
+	for _, s := range sites {
+		if assigned[s.Id] {
+			continue
+		}
+		data.Sites = append(data.Sites, templates.WorkOrderSiteOption{
+			ID:       s.Id,
+			Label:    services.SiteLabel(s),
+			Area:     siteArea(s),
+			Selected: selectedSites[s.Id],
+		})
+	}
+	return data
+}
+
+// renderWorkOrderCreate renders the work order form as a partial or full
+// page.
+func renderWorkOrderCreate(e *core.RequestEvent, data templates.WorkOrderCreateData) error {
+	var component templ.Component
+	if e.Request.Header.Get("HX-Request") == "true" {
+		component = templates.WorkOrderCreateContent(data)
+	} else {
+		headerData := GetHeaderData(e.Request)
+		sidebarData := GetSidebarData(e.Request)
+		component = templates.WorkOrderCreatePage(data, headerData, sidebarData)
+	}
+	return component.Render(e.Request.Context(), e.Response)
+}
+
+// redirectToWorkOrder sends the browser to a work order's page.
+func redirectToWorkOrder(e *core.RequestEvent, projectId, workOrderId string) error {
+	redirectURL := fmt.Sprintf("/projects/%s/work-orders/%s", projectId, workOrderId)
+	if e.Request.Header.Get("HX-Request") == "true" {
+		e.Response.Header().Set("HX-Redirect", redirectURL)
+		return e.String(http.StatusOK, "")
+	}
+	return e.Redirect(http.StatusFound, redirectURL)
+}
+
+// findProjectWorkOrder loads a work order from the path, checking it belongs
+// to the project in the path.
+func findProjectWorkOrder(app *pocketbase.PocketBase, e *core.RequestEvent) (*core.Record, bool) {
+	wo, err := app.FindRecordById("work_orders", e.Request.PathValue("id"))
+	if err != nil || wo.GetString("project") != e.Request.PathValue("projectId") {
+		return nil, false
+	}
+	return wo, true
+}
+
+// workOrderValue returns the value of a work order: every line's rate ×
+// quantity per site, times the number of sites.
+func workOrderValue(lines []*core.Record, siteCount int) float64 {
+	var perSite float64
+	for _, l := range lines {
+		perSite += l.GetFloat("qty_per_site") * l.GetFloat("rate")
+	}
+	return perSite * float64(siteCount)
+}
+
+// siteIsInstalled reports whether a work order site is installed or
+// commissioned.
+func siteIsInstalled(site *core.Record) bool {
+	status := site.GetString("status")
+	return status == services.SiteStatusInstalled || status == services.SiteStatusCommissioned
+}
+
+// HandleWorkOrderList renders the project's installation work orders.
+func HandleWorkOrderList(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		project, err := app.FindRecordById("projects", e.Request.PathValue("projectId"))
+		if err != nil {
+			return ErrorToast(e, http.StatusNotFound, "Project not found")
+		}
+
+		records, err := app.FindRecordsByFilter("work_orders", "project = {:projectId}", "-wo_date,-created", 0, 0, map[string]any{"projectId": project.Id})
+		if err != nil {
+			log.Printf("work_order: could not load work orders for project %s: %v", project.Id, err)
+			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
+		}
+		if errs := app.ExpandRecords(records, []string{"installer"}, nil); len(errs) > 0 {
+			log.Printf("work_order: could not expand installers: %v", errs)
+		}
+
+		data := templates.WorkOrderListData{ProjectID: project.Id}
+		for _, wo := range records {
+			lines, err := app.FindRecordsByFilter("work_order_lines", "work_order = {:id}", "line_order", 0, 0, map[string]any{"id": wo.Id})
+			if err != nil {
+				log.Printf("work_order: could not load lines of %s: %v", wo.Id, err)
+			}
+			sites, err := app.FindRecordsByFilter("work_order_sites", "work_order = {:id}", "", 0, 0, map[string]any{"id": wo.Id})
+			if err != nil {
+				log.Printf("work_order: could not load sites of %s: %v", wo.Id, err)
+			}
+
+			item := templates.WorkOrderListItem{
+				ID:         wo.Id,
+				WONumber:   wo.GetString("wo_number"),
+				WODate:     wo.GetString("wo_date"),
+				TargetDate: wo.GetString("target_date"),
+				SiteCount:  len(sites),
+				Value:      services.FormatINR(workOrderValue(lines, len(sites))),
+			}
+			if installer := wo.ExpandedOne("installer"); installer != nil {
+				item.InstallerName = installer.GetString("name")
+			}
+			for _, s := range sites {
+				if siteIsInstalled(s) {
+					item.InstalledCount++
+				}
+			}
+			data.AssignedCount += item.SiteCount
+			data.InstalledCount += item.InstalledCount
+			data.WorkOrders = append(data.WorkOrders, item)
+		}
+
+		installSites, err := app.FindRecordsByFilter("addresses", "project = {:projectId} && address_type = 'install_at'", "", 0, 0, map[string]any{"projectId": project.Id})
+		if err != nil {
+			log.Printf("work_order: could not count install-at sites for project %s: %v", project.Id, err)
+		}
+		data.SiteCount = len(installSites)
+
+		var component templ.Component
+		if e.Request.Header.Get("HX-Request") == "true" {
+			component = templates.WorkOrderListContent(data)
+		} else {
+			headerData := GetHeaderData(e.Request)
+			sidebarData := GetSidebarData(e.Request)
+			component = templates.WorkOrderListPage(data, headerData, sidebarData)
+		}
+		return component.Render(e.Request.Context(), e.Response)
+	}
+}
+
+// HandleWorkOrderCreate renders the form to raise an installation work
+// order.
+func HandleWorkOrderCreate(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		project, err := app.FindRecordById("projects", e.Request.PathValue("projectId"))
+		if err != nil {
+			return ErrorToast(e, http.StatusNotFound, "Project not found")
+		}
+		return renderWorkOrderCreate(e, workOrderCreateData(app, project.Id, nil, nil))
+	}
+}
+
+// HandleWorkOrderSave numbers and saves an installation work order for the
+// chosen service items and install-at sites.
+func HandleWorkOrderSave(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		if err := e.Request.ParseForm(); err != nil {
+			return ErrorToast(e, http.StatusBadRequest, "Invalid form data")
+		}
+
+		project, err := app.FindRecordById("projects", e.Request.PathValue("projectId"))
+		if err != nil {
+			return ErrorToast(e, http.StatusNotFound, "Project not found")
+		}
+
+		params := services.WorkOrderParams{
+			ProjectID:   project.Id,
+			InstallerID: e.Request.FormValue("installer"),
+			WODate:      strings.TrimSpace(e.Request.FormValue("wo_date")),
+			TargetDate:  strings.TrimSpace(e.Request.FormValue("target_date")),
+			Remarks:     strings.TrimSpace(e.Request.FormValue("remarks")),
+			SiteIDs:     e.Request.Form["sites"],
+		}
+		form := make(map[string]string)
+		items, err := services.ServiceBOQItems(app, project.Id)
+		if err != nil {
+			log.Printf("work_order: could not load service items for project %s: %v", project.Id, err)
+			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
+		}
+		for _, it := range items {
+			for _, key := range []string{"item_", "qty_", "rate_"} {
+				form[key+it.SourceItemID] = strings.TrimSpace(e.Request.FormValue(key + it.SourceItemID))
+			}
+			if form["item_"+it.SourceItemID] == "" {
+				continue
+			}
+			qty, _ := strconv.ParseFloat(form["qty_"+it.SourceItemID], 64)
+			rate, _ := strconv.ParseFloat(form["rate_"+it.SourceItemID], 64)
+			params.Lines = append(params.Lines, services.WorkOrderLineParams{
+				SourceItemType: it.SourceItemType,
+				SourceItemID:   it.SourceItemID,
+				QtyPerSite:     qty,
+				Rate:           rate,
+			})
+		}
+
+		if errors := services.ValidateWorkOrder(app, params); len(errors) > 0 {
+			SetToast(e, "warning", "Please fix the errors below")
+			data := workOrderCreateData(app, project.Id, &params, form)
+			data.Errors = errors
+			return renderWorkOrderCreate(e, data)
+		}
+
+		wo, err := services.CreateWorkOrder(e.Request.Context(), app, params)
+		if err != nil {
+			log.Printf("work_order: could not save work order for project %s: %v", project.Id, err)
+			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
+		}
+
+		SetToast(e, "success", fmt.Sprintf("Work order %s raised for %d site(s)", wo.GetString("wo_number"), len(params.SiteIDs)))
+		return redirectToWorkOrder(e, project.Id, wo.Id)
+	}
+}
+
+// HandleWorkOrderView renders a work order with the installation progress
+// of each of its sites.
+func HandleWorkOrderView(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		wo, ok := findProjectWorkOrder(app, e)
+		if !ok {
+			return ErrorToast(e, http.StatusNotFound, "Work order not found")
+		}
+
+		lines, err := app.FindRecordsByFilter("work_order_lines", "work_order = {:id}", "line_order", 0, 0, map[string]any{"id": wo.Id})
+		if err != nil {
+			log.Printf("work_order: could not load lines of %s: %v", wo.Id, err)
+			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
+		}
+		sites, err := app.FindRecordsByFilter("work_order_sites", "work_order = {:id}", "created", 0, 0, map[string]any{"id": wo.Id})
+		if err != nil {
+			log.Printf("work_order: could not load sites of %s: %v", wo.Id, err)
+			return ErrorToast(e, http.StatusInternalServerError, "Something went wrong. Please try again.")
+		}
+		if errs := app.ExpandRecords(sites, []string{"install_at_address"}, nil); len(errs) > 0 {
+			log.Printf("work_order: could not expand sites: %v", errs)
+		}
+
+		data := templates.WorkOrderViewData{
+			ProjectID:  wo.GetString("project"),
+			WOID:       wo.Id,
+			WONumber:   wo.GetString("wo_number"),
+			WODate:     wo.GetString("wo_date"),
+			TargetDate: wo.GetString("target_date"),
+			Remarks:    wo.GetString("remarks"),
+			TotalValue: services.FormatINR(workOrderValue(lines, len(sites))),
+		}
+		if installer, err := app.FindRecordById("vendors", wo.GetString("installer")); err == nil {
+			data.InstallerName = installer.GetString("name")
+		}
+		for _, s := range services.SiteStatuses {
+			if s == services.SiteStatusCommissioned {
+				continue // set by commissioning the site
+			}
+			data.Statuses = append(data.Statuses, templates.WorkOrderStatusOption{Value: s, Label: services.SiteStatusLabel(s)})
+		}
+
+		for i, l := range lines {
+			totalQty := l.GetFloat("qty_per_site") * float64(len(sites))
+			data.Lines = append(data.Lines, templates.WorkOrderViewLine{
+				SINo:        i + 1,
+				Description: l.GetString("description"),
+				UOM:         l.GetString("uom"),
+				QtyPerSite:  formatQty(l.GetFloat("qty_per_site")),
+				TotalQty:    formatQty(totalQty),
+				Rate:        services.FormatINR(l.GetFloat("rate")),
+				Amount:      services.FormatINR(totalQty * l.GetFloat("rate")),
+			})
+		}
+
+		certificates := make(map[string]string)
+		records, err := app.FindRecordsByFilter("commissioning_records", "project = {:pid}", "", 0, 0, map[string]any{"pid": data.ProjectID})
+		if err != nil {
+			log.Printf("work_order: could not load commissioning records: %v", err)
+		}
+		for _, r := range records {
+			certificates[r.GetString("install_at_address")] = r.Id
+		}
+
+		for _, s := range sites {
+			site := templates.WorkOrderViewSite{
+				ID:               s.Id,
+				AddressID:        s.GetString("install_at_address"),
+				CertificateID:    certificates[s.GetString("install_at_address")],
+				Status:           s.GetString("status"),
+				StatusLabel:      services.SiteStatusLabel(s.GetString("status")),
+				InstallationDate: s.GetString("installation_date"),
+				Remarks:          s.GetString("remarks"),
+			}
+			if address := s.ExpandedOne("install_at_address"); address != nil {
+				site.Label = services.SiteLabel(address)
+				site.Area = siteArea(address)
+			}
+			installed := make(map[string]bool)
+			for _, id := range s.GetStringSlice("serial_numbers") {
+				installed[id] = true
+			}
+			site.InstalledCount = len(installed)
+			serials, err := services.SiteDeliveredSerials(app, s.GetString("install_at_address"))
+			if err != nil {
+				log.Printf("work_order: could not load serials for site %s: %v", s.Id, err)
+			}
+			for _, sn := range serials {
+				site.Serials = append(site.Serials, templates.WorkOrderSerialOption{
+					ID:           sn.Id,
+					SerialNumber: sn.GetString("serial_number"),
+					Selected:     installed[sn.Id],
+				})
+			}
+			data.Sites = append(data.Sites, site)
+		}
+
+		var component templ.Component
+		if e.Request.Header.Get("HX-Request") == "true" {
+			component = templates.WorkOrderViewContent(data)
+		} else {
+			headerData := GetHeaderData(e.Request)
+			sidebarData := GetSidebarData(e.Request)
+			component = templates.WorkOrderViewPage(data, headerData, sidebarData)
+		}
+		return component.Render(e.Request.Context(), e.Response)
+	}
+}
+
+// HandleWorkOrderSiteUpdate records the installation status, date, remarks
+// and installed serial numbers of one work order site.
+func HandleWorkOrderSiteUpdate(app *pocketbase.PocketBase) func(*core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		if err := e.Request.ParseForm(); err != nil {
+			return ErrorToast(e, http.StatusBadRequest, "Invalid form data")
+		}
+
+		wo, ok := findProjectWorkOrder(app, e)
+		if !ok {
+			return ErrorToast(e, http.StatusNotFound, "Work order not found")
+		}
+		site, err := app.FindRecordById("work_order_sites", e.Request.PathValue("siteId"))
+		if err != nil || site.GetString("work_order") != wo.Id {
+			return ErrorToast(e, http.StatusNotFound, "Work order site not found")
+		}
+
+		params := services.SiteProgressParams{
+			Status:           e.Request.FormValue("status"),
+			InstallationDate: e.Request.FormValue("installation_date"),
+			Remarks:          e.Request.FormValue("remarks"),
+			SerialIDs:        e.Request.Form["serials"],
+		}
+		if err := services.UpdateWorkOrderSite(e.Request.Context(), app, site.Id, params); err != nil {
+			return ErrorToast(e, http.StatusBadRequest, err.Error())
+		}
+
+		SetToast(e, "success", fmt.Sprintf("Site marked %s", strings.ToLower(services.SiteStatusLabel(params.Status))))
+		return redirectToWorkOrder(e, wo.GetString("project"), wo.Id)
+	}
+}
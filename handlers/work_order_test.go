@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"projectcreation/testhelpers"
+)
+
+func TestHandleWorkOrderSave_RaisesOrderAndRecordsProgress(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Install Project")
+	boq := testhelpers.CreateTestBOQ(t, app, project.Id, "Main BOQ")
+	main := testhelpers.CreateTestMainBOQItem(t, app, boq.Id, "Solar pump set")
+	service := testhelpers.CreateTestSubItem(t, app, main.Id, "Installation and commissioning")
+	service.Set("type", "service")
+	if err := app.Save(service); err != nil {
+		t.Fatalf("failed to save service item: %v", err)
+	}
+	installer := testhelpers.CreateTestVendor(t, app, "Surya Installers")
+	testhelpers.LinkVendorToProject(t, app, project.Id, installer.Id)
+	site := testhelpers.CreateTestAddress(t, app, project.Id, "install_at", "Farmer A")
+	pathValues := map[string]string{"projectId": project.Id}
+
+	// Nothing picked: the form comes back with errors
+	rec := postHXForm(t, app, HandleWorkOrderSave, "/projects/"+project.Id+"/work-orders", pathValues, url.Values{
+		"installer": {installer.Id},
+		"wo_date":   {"2025-06-10"},
+	})
+	testhelpers.AssertHTMLContains(t, rec.Body.String(), "Choose at least one service item", "Choose at least one install-at site", "Farmer A")
+
+	rec = postHXForm(t, app, HandleWorkOrderSave, "/projects/"+project.Id+"/work-orders", pathValues, url.Values{
+		"installer":          {installer.Id},
+		"wo_date":            {"2025-06-10"},
+		"item_" + service.Id: {"1"},
+		"qty_" + service.Id:  {"1"},
+		"rate_" + service.Id: {"1750"},
+		"sites":              {site.Id},
+	})
+	wo, err := app.FindFirstRecordByData("work_orders", "project", project.Id)
+	if err != nil {
+		t.Fatalf("expected the work order to be saved: %v", err)
+	}
+	testhelpers.AssertHXRedirect(t, rec.Header().Get("HX-Redirect"), "/projects/"+project.Id+"/work-orders/"+wo.Id)
+
+	woSite, err := app.FindFirstRecordByData("work_order_sites", "work_order", wo.Id)
+	if err != nil {
+		t.Fatalf("expected the site to be on the work order: %v", err)
+	}
+	sitePath := map[string]string{"projectId": project.Id, "id": wo.Id, "siteId": woSite.Id}
+	target := "/projects/" + project.Id + "/work-orders/" + wo.Id + "/sites/" + woSite.Id
+
+	rec = postHXForm(t, app, HandleWorkOrderSiteUpdate, target, sitePath, url.Values{"status": {"installed"}})
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected installed without a date to be rejected, got %d", rec.Code)
+	}
+	rec = postHXForm(t, app, HandleWorkOrderSiteUpdate, target, sitePath, url.Values{
+		"status":            {"installed"},
+		"installation_date": {time.Now().Format("2006-01-02")},
+		"remarks":           {"Pump running"},
+	})
+	testhelpers.AssertHXRedirect(t, rec.Header().Get("HX-Redirect"), "/projects/"+project.Id+"/work-orders/"+wo.Id)
+
+	req := httptest.NewRequest(http.MethodGet, "/projects/"+project.Id+"/work-orders/"+wo.Id, nil)
+	req.SetPathValue("projectId", project.Id)
+	req.SetPathValue("id", wo.Id)
+	req.Header.Set("HX-Request", "true")
+	rec = httptest.NewRecorder()
+	if err := HandleWorkOrderView(app)(newTestRequestEvent(app, req, rec)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	testhelpers.AssertHTMLContains(t, rec.Body.String(), wo.GetString("wo_number"), "Surya Installers", "Installation and commissioning", "Installed", "Pump running")
+}
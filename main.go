@@ -12,11 +12,17 @@ import (
 
 	"projectcreation/collections"
 	"projectcreation/handlers"
+	"projectcreation/services"
 )
 
 func main() {
 	app := pocketbase.New()
 
+	// Record create/update/delete history for the audited collections
+	services.RegisterAuditHooks(app)
+	// Keep BOQ actual prices and budget alerts in step with project costs
+	services.RegisterBudgetHooks(app)
+
 	// Create collections and seed data on startup
 	app.OnServe().BindFunc(func(se *core.ServeEvent) error {
 		collections.Setup(app)
@@ -32,6 +38,12 @@ func main() {
 		if err := collections.MigrateAddressesToFlexible(app); err != nil {
 			log.Printf("Warning: address flexible migration failed: %v", err)
 		}
+		if err := collections.MigrateForwardedSplitQuantities(app); err != nil {
+			log.Printf("Warning: split quantity migration failed: %v", err)
+		}
+		if err := collections.EnsureAdminUser(app); err != nil {
+			log.Printf("Warning: admin user setup failed: %v", err)
+		}
 		return se.Next()
 	})
 
@@ -39,8 +51,24 @@ func main() {
 	app.OnServe().BindFunc(func(se *core.ServeEvent) error {
 		se.Router.GET("/static/{path...}", apis.Static(os.DirFS("./static"), false))
 
-		// Apply active project middleware globally
+		// Require a signed-in user, then resolve the active project for them
+		se.Router.BindFunc(handlers.AuthMiddleware(app))
 		se.Router.BindFunc(handlers.ActiveProjectMiddleware(app))
+		se.Router.BindFunc(handlers.BudgetRefreshMiddleware(app))
+
+		// Role guards for routes that change data
+		adminOnly := handlers.RequireRole(handlers.RoleAdmin)
+		projectEditors := handlers.RequireRole(handlers.RoleAdmin, handlers.RoleProjectManager)
+		purchaseEditors := handlers.RequireRole(handlers.RoleAdmin, handlers.RoleProjectManager, handlers.RolePurchase)
+		logisticsEditors := handlers.RequireRole(handlers.RoleAdmin, handlers.RoleProjectManager, handlers.RoleLogistics)
+
+		// ── Authentication ───────────────────────────────────────
+		se.Router.GET("/login", handlers.HandleLoginPage(app))
+		se.Router.POST("/login", handlers.HandleLogin(app))
+		se.Router.POST("/logout", handlers.HandleLogout(app))
+
+		// ── Document verification (public) ───────────────────────
+		se.Router.GET("/verify/{token}", handlers.HandleVerifyDocument(app))
 
 		// ── Project activation ───────────────────────────────────
 		se.Router.POST("/projects/{id}/activate", handlers.HandleProjectActivate(app))
@@ -48,14 +76,14 @@ func main() {
 
 		// ── Project CRUD ─────────────────────────────────────────
 		se.Router.GET("/projects", handlers.HandleProjectList(app))
-		se.Router.GET("/projects/create", handlers.HandleProjectCreate(app))
-		se.Router.POST("/projects", handlers.HandleProjectSave(app))
-		se.Router.GET("/projects/{id}/edit", handlers.HandleProjectEdit(app))
-		se.Router.POST("/projects/{id}/save", handlers.HandleProjectUpdate(app))
-		se.Router.DELETE("/projects/{id}", handlers.HandleProjectDelete(app))
-		se.Router.GET("/projects/{id}/settings", handlers.HandleProjectSettings(app))
+		se.Router.GET("/projects/create", handlers.HandleProjectCreate(app)).BindFunc(projectEditors)
+		se.Router.POST("/projects", handlers.HandleProjectSave(app)).BindFunc(projectEditors)
+		se.Router.GET("/projects/{id}/edit", handlers.HandleProjectEdit(app)).BindFunc(projectEditors)
+		se.Router.POST("/projects/{id}/save", handlers.HandleProjectUpdate(app)).BindFunc(projectEditors)
+		se.Router.DELETE("/projects/{id}", handlers.HandleProjectDelete(app)).BindFunc(projectEditors)
+		se.Router.GET("/projects/{id}/settings", handlers.HandleProjectSettings(app)).BindFunc(projectEditors)
 		se.Router.GET("/projects/{id}", handlers.HandleProjectView(app))
-		se.Router.POST("/projects/{id}/settings", handlers.HandleProjectSettingsSave(app))
+		se.Router.POST("/projects/{id}/settings", handlers.HandleProjectSettingsSave(app)).BindFunc(projectEditors)
 
 		// Project-scoped address routes
 		addressTypes := []struct {
@@ -82,21 +110,21 @@ func main() {
 			se.Router.GET(
 				"/projects/{projectId}/addresses/"+at.slug+"/new",
 				handlers.HandleAddressCreate(app, at.addrType),
-			)
+			).BindFunc(logisticsEditors)
 			se.Router.POST(
 				"/projects/{projectId}/addresses/"+at.slug+"/new",
 				handlers.HandleAddressSave(app, at.addrType),
-			)
+			).BindFunc(logisticsEditors)
 
 			// Edit form (GET renders form, POST updates address)
 			se.Router.GET(
 				"/projects/{projectId}/addresses/"+at.slug+"/{addressId}/edit",
 				handlers.HandleAddressEdit(app, at.addrType),
-			)
+			).BindFunc(logisticsEditors)
 			se.Router.POST(
 				"/projects/{projectId}/addresses/"+at.slug+"/{addressId}/edit",
 				handlers.HandleAddressUpdate(app, at.addrType),
-			)
+			).BindFunc(logisticsEditors)
 		}
 
 		// Address template download
@@ -105,13 +133,13 @@ func main() {
 
 		// Address import - upload & validate
 		se.Router.GET("/projects/{projectId}/addresses/{type}/import",
-			handlers.HandleAddressImportPage(app))
+			handlers.HandleAddressImportPage(app)).BindFunc(logisticsEditors)
 		se.Router.POST("/projects/{projectId}/addresses/{type}/import",
-			handlers.HandleAddressValidate(app))
+			handlers.HandleAddressValidate(app)).BindFunc(logisticsEditors)
 
 		// Address import - commit
 		se.Router.POST("/projects/{projectId}/addresses/{type}/import/commit",
-			handlers.HandleAddressImportCommit(app))
+			handlers.HandleAddressImportCommit(app)).BindFunc(logisticsEditors)
 
 		// Address import - download error report
 		se.Router.POST("/projects/{projectId}/addresses/{type}/import/errors",
@@ -123,46 +151,46 @@ func main() {
 
 		// Address delete operations (bulk must be before {addressId} to avoid matching "bulk" as an ID)
 		se.Router.DELETE("/projects/{projectId}/addresses/{type}/bulk",
-			handlers.HandleAddressBulkDelete(app))
+			handlers.HandleAddressBulkDelete(app)).BindFunc(logisticsEditors)
 		se.Router.GET("/projects/{projectId}/addresses/{type}/{addressId}/delete-info",
 			handlers.HandleAddressDeleteInfo(app))
 		se.Router.DELETE("/projects/{projectId}/addresses/{type}/{addressId}",
-			handlers.HandleAddressDelete(app))
+			handlers.HandleAddressDelete(app)).BindFunc(logisticsEditors)
 
 		// ── Project-scoped BOQ routes ───────────────────────────
 		// BOQ creation
-		se.Router.GET("/projects/{projectId}/boq/create", handlers.HandleBOQCreate(app))
-		se.Router.POST("/projects/{projectId}/boq", handlers.HandleBOQSave(app))
+		se.Router.GET("/projects/{projectId}/boq/create", handlers.HandleBOQCreate(app)).BindFunc(projectEditors)
+		se.Router.POST("/projects/{projectId}/boq", handlers.HandleBOQSave(app)).BindFunc(projectEditors)
 
 		// BOQ edit mode
-		se.Router.GET("/projects/{projectId}/boq/{id}/edit", handlers.HandleBOQEdit(app))
+		se.Router.GET("/projects/{projectId}/boq/{id}/edit", handlers.HandleBOQEdit(app)).BindFunc(projectEditors)
 		se.Router.GET("/projects/{projectId}/boq/{id}/view", handlers.HandleBOQViewMode(app))
-		se.Router.POST("/projects/{projectId}/boq/{id}/save", handlers.HandleBOQUpdate(app))
+		se.Router.POST("/projects/{projectId}/boq/{id}/save", handlers.HandleBOQUpdate(app)).BindFunc(projectEditors)
 
 		// BOQ delete
-		se.Router.DELETE("/projects/{projectId}/boq/{id}", handlers.HandleBOQDelete(app))
+		se.Router.DELETE("/projects/{projectId}/boq/{id}", handlers.HandleBOQDelete(app)).BindFunc(projectEditors)
 
 		// BOQ export
 		se.Router.GET("/projects/{projectId}/boq/{id}/export/excel", handlers.HandleBOQExportExcel(app))
 		se.Router.GET("/projects/{projectId}/boq/{id}/export/pdf", handlers.HandleBOQExportPDF(app))
 
 		// BOQ edit - add items
-		se.Router.POST("/projects/{projectId}/boq/{id}/main-items", handlers.HandleAddMainItem(app))
-		se.Router.POST("/projects/{projectId}/boq/{id}/main-item/{mainItemId}/subitems", handlers.HandleAddSubItem(app))
-		se.Router.POST("/projects/{projectId}/boq/{id}/subitem/{subItemId}/subsubitems", handlers.HandleAddSubSubItem(app))
+		se.Router.POST("/projects/{projectId}/boq/{id}/main-items", handlers.HandleAddMainItem(app)).BindFunc(projectEditors)
+		se.Router.POST("/projects/{projectId}/boq/{id}/main-item/{mainItemId}/subitems", handlers.HandleAddSubItem(app)).BindFunc(projectEditors)
+		se.Router.POST("/projects/{projectId}/boq/{id}/subitem/{subItemId}/subsubitems", handlers.HandleAddSubSubItem(app)).BindFunc(projectEditors)
 
 		// BOQ edit - delete items
-		se.Router.DELETE("/projects/{projectId}/boq/{id}/main-item/{itemId}", handlers.HandleDeleteMainItem(app))
-		se.Router.DELETE("/projects/{projectId}/boq/{id}/subitem/{subItemId}", handlers.HandleDeleteSubItem(app))
-		se.Router.DELETE("/projects/{projectId}/boq/{id}/subsubitem/{subSubItemId}", handlers.HandleDeleteSubSubItem(app))
+		se.Router.DELETE("/projects/{projectId}/boq/{id}/main-item/{itemId}", handlers.HandleDeleteMainItem(app)).BindFunc(projectEditors)
+		se.Router.DELETE("/projects/{projectId}/boq/{id}/subitem/{subItemId}", handlers.HandleDeleteSubItem(app)).BindFunc(projectEditors)
+		se.Router.DELETE("/projects/{projectId}/boq/{id}/subsubitem/{subSubItemId}", handlers.HandleDeleteSubSubItem(app)).BindFunc(projectEditors)
 
 		// BOQ edit - expand/collapse (lazy load sub-items)
 		se.Router.GET("/projects/{projectId}/boq/{id}/main-item/{itemId}/subitems", handlers.HandleExpandMainItem(app))
 
 		// BOQ edit - patch individual fields (optional auto-save)
-		se.Router.PATCH("/projects/{projectId}/boq/{id}/main-item/{itemId}", handlers.HandlePatchMainItem(app))
-		se.Router.PATCH("/projects/{projectId}/boq/{id}/subitem/{subItemId}", handlers.HandlePatchSubItem(app))
-		se.Router.PATCH("/projects/{projectId}/boq/{id}/subsubitem/{subSubItemId}", handlers.HandlePatchSubSubItem(app))
+		se.Router.PATCH("/projects/{projectId}/boq/{id}/main-item/{itemId}", handlers.HandlePatchMainItem(app)).BindFunc(projectEditors)
+		se.Router.PATCH("/projects/{projectId}/boq/{id}/subitem/{subItemId}", handlers.HandlePatchSubItem(app)).BindFunc(projectEditors)
+		se.Router.PATCH("/projects/{projectId}/boq/{id}/subsubitem/{subSubItemId}", handlers.HandlePatchSubSubItem(app)).BindFunc(projectEditors)
 
 		// BOQ view (must be after specific /boq/{id}/* routes)
 		se.Router.GET("/projects/{projectId}/boq/{id}", handlers.HandleBOQView(app))
@@ -172,100 +200,210 @@ func main() {
 
 		// ── Vendor CRUD (global) ─────────────────────────────────
 		se.Router.GET("/vendors", handlers.HandleVendorList(app))
-		se.Router.GET("/vendors/create", handlers.HandleVendorCreate(app))
-		se.Router.POST("/vendors", handlers.HandleVendorSave(app))
-		se.Router.GET("/vendors/{id}/edit", handlers.HandleVendorEdit(app))
-		se.Router.POST("/vendors/{id}/save", handlers.HandleVendorUpdate(app))
-		se.Router.DELETE("/vendors/{id}", handlers.HandleVendorDelete(app))
+		se.Router.GET("/vendors/create", handlers.HandleVendorCreate(app)).BindFunc(purchaseEditors)
+		se.Router.POST("/vendors", handlers.HandleVendorSave(app)).BindFunc(purchaseEditors)
+		se.Router.GET("/vendors/{id}/edit", handlers.HandleVendorEdit(app)).BindFunc(purchaseEditors)
+		se.Router.POST("/vendors/{id}/save", handlers.HandleVendorUpdate(app)).BindFunc(purchaseEditors)
+		se.Router.DELETE("/vendors/{id}", handlers.HandleVendorDelete(app)).BindFunc(purchaseEditors)
+
+		// ── Vendor payments & ledger ─────────────────────────────
+		se.Router.GET("/vendors/{id}/ledger", handlers.HandleVendorLedger(app))
+		se.Router.GET("/vendors/{id}/ledger/export/excel", handlers.HandleVendorLedgerExportExcel(app))
+		se.Router.GET("/vendors/{id}/payments/create", handlers.HandleVendorPaymentCreate(app)).BindFunc(purchaseEditors)
+		se.Router.POST("/vendors/{id}/payments", handlers.HandleVendorPaymentSave(app)).BindFunc(purchaseEditors)
 
 		// ── Vendor (project-scoped) ──────────────────────────────
 		se.Router.GET("/projects/{projectId}/vendors", handlers.HandleVendorList(app))
-		se.Router.GET("/projects/{projectId}/vendors/create", handlers.HandleVendorCreate(app))
-		se.Router.POST("/projects/{projectId}/vendors", handlers.HandleVendorSave(app))
-		se.Router.POST("/projects/{projectId}/vendors/{id}/link", handlers.HandleVendorLink(app))
-		se.Router.DELETE("/projects/{projectId}/vendors/{id}/link", handlers.HandleVendorUnlink(app))
+		se.Router.GET("/projects/{projectId}/vendors/create", handlers.HandleVendorCreate(app)).BindFunc(purchaseEditors)
+		se.Router.POST("/projects/{projectId}/vendors", handlers.HandleVendorSave(app)).BindFunc(purchaseEditors)
+		se.Router.POST("/projects/{projectId}/vendors/{id}/link", handlers.HandleVendorLink(app)).BindFunc(purchaseEditors)
+		se.Router.DELETE("/projects/{projectId}/vendors/{id}/link", handlers.HandleVendorUnlink(app)).BindFunc(purchaseEditors)
 
 		// ── Purchase Order CRUD ──────────────────────────────────
-		se.Router.GET("/projects/{projectId}/po/create", handlers.HandlePOCreate(app))
-		se.Router.POST("/projects/{projectId}/po", handlers.HandlePOSave(app))
-		se.Router.GET("/projects/{projectId}/po/{id}/edit", handlers.HandlePOEdit(app))
-		se.Router.POST("/projects/{projectId}/po/{id}/save", handlers.HandlePOUpdate(app))
+		se.Router.GET("/projects/{projectId}/po/create", handlers.HandlePOCreate(app)).BindFunc(purchaseEditors)
+		se.Router.POST("/projects/{projectId}/po", handlers.HandlePOSave(app)).BindFunc(purchaseEditors)
+		se.Router.GET("/projects/{projectId}/po/{id}/edit", handlers.HandlePOEdit(app)).BindFunc(purchaseEditors)
+		se.Router.POST("/projects/{projectId}/po/{id}/save", handlers.HandlePOUpdate(app)).BindFunc(purchaseEditors)
 
 		// ── PO Line Items ───────────────────────────────────────
-		se.Router.POST("/projects/{projectId}/po/{id}/line-items", handlers.HandlePOAddLineItem(app))
-		se.Router.POST("/projects/{projectId}/po/{id}/line-items/from-boq", handlers.HandlePOAddLineItemFromBOQ(app))
-		se.Router.PATCH("/projects/{projectId}/po/{id}/line-items/{itemId}", handlers.HandlePOUpdateLineItem(app))
-		se.Router.DELETE("/projects/{projectId}/po/{id}/line-items/{itemId}", handlers.HandlePODeleteLineItem(app))
+		se.Router.POST("/projects/{projectId}/po/{id}/line-items", handlers.HandlePOAddLineItem(app)).BindFunc(purchaseEditors)
+		se.Router.POST("/projects/{projectId}/po/{id}/line-items/from-boq", handlers.HandlePOAddLineItemFromBOQ(app)).BindFunc(purchaseEditors)
+		se.Router.PATCH("/projects/{projectId}/po/{id}/line-items/{itemId}", handlers.HandlePOUpdateLineItem(app)).BindFunc(purchaseEditors)
+		se.Router.DELETE("/projects/{projectId}/po/{id}/line-items/{itemId}", handlers.HandlePODeleteLineItem(app)).BindFunc(purchaseEditors)
 
 		// ── BOQ Picker ──────────────────────────────────────────
-		se.Router.GET("/projects/{projectId}/po/{id}/boq-picker", handlers.HandlePOBOQPicker(app))
+		se.Router.GET("/projects/{projectId}/po/{id}/boq-picker", handlers.HandlePOBOQPicker(app)).BindFunc(purchaseEditors)
 
-		// ── PO Export ───────────────────────────────────────────
+		// ── PO Export & Matching ────────────────────────────────
 		se.Router.GET("/projects/{projectId}/po/{id}/export/pdf", handlers.HandlePOExportPDF(app))
+		se.Router.GET("/projects/{projectId}/po/{id}/matching", handlers.HandlePOMatching(app))
 
 		// ── PO List, View, Delete (after specific /po/{id}/* routes) ──
 		se.Router.GET("/projects/{projectId}/po", handlers.HandlePOList(app))
 		se.Router.GET("/projects/{projectId}/po/{id}", handlers.HandlePOView(app))
-		se.Router.DELETE("/projects/{projectId}/po/{id}", handlers.HandlePODelete(app))
+		se.Router.DELETE("/projects/{projectId}/po/{id}", handlers.HandlePODelete(app)).BindFunc(purchaseEditors)
+
+		// ── Goods Receipt Notes ─────────────────────────────────
+		se.Router.GET("/projects/{projectId}/grn/create", handlers.HandleGRNCreate(app)).BindFunc(purchaseEditors)
+		se.Router.POST("/projects/{projectId}/grn", handlers.HandleGRNSave(app)).BindFunc(purchaseEditors)
+		se.Router.GET("/projects/{projectId}/grn/{id}/export/pdf", handlers.HandleGRNExportPDF(app))
+		se.Router.GET("/projects/{projectId}/grn", handlers.HandleGRNList(app))
+		se.Router.GET("/projects/{projectId}/grn/{id}", handlers.HandleGRNView(app))
+
+		// ── Vendor Invoices ─────────────────────────────────────
+		se.Router.GET("/projects/{projectId}/invoices/create", handlers.HandleVendorInvoiceCreate(app)).BindFunc(purchaseEditors)
+		se.Router.POST("/projects/{projectId}/invoices", handlers.HandleVendorInvoiceSave(app)).BindFunc(purchaseEditors)
+		se.Router.GET("/projects/{projectId}/invoices", handlers.HandleVendorInvoiceList(app))
+		se.Router.GET("/projects/{projectId}/invoices/{id}", handlers.HandleVendorInvoiceView(app))
 
 		// ── DC Templates ────────────────────────────────────────
 		se.Router.GET("/projects/{projectId}/dc-templates/", handlers.HandleDCTemplateList(app))
-		se.Router.GET("/projects/{projectId}/dc-templates/create", handlers.HandleDCTemplateCreate(app))
-		se.Router.POST("/projects/{projectId}/dc-templates/create", handlers.HandleDCTemplateSave(app))
-		se.Router.GET("/projects/{projectId}/dc-templates/{id}/edit", handlers.HandleDCTemplateEdit(app))
-		se.Router.POST("/projects/{projectId}/dc-templates/{id}/edit", handlers.HandleDCTemplateUpdate(app))
-		se.Router.DELETE("/projects/{projectId}/dc-templates/{id}/delete", handlers.HandleDCTemplateDelete(app))
-		se.Router.POST("/projects/{projectId}/dc-templates/{id}/duplicate", handlers.HandleDCTemplateDuplicate(app))
+		se.Router.GET("/projects/{projectId}/dc-templates/create", handlers.HandleDCTemplateCreate(app)).BindFunc(logisticsEditors)
+		se.Router.POST("/projects/{projectId}/dc-templates/create", handlers.HandleDCTemplateSave(app)).BindFunc(logisticsEditors)
+		se.Router.GET("/projects/{projectId}/dc-templates/{id}/edit", handlers.HandleDCTemplateEdit(app)).BindFunc(logisticsEditors)
+		se.Router.POST("/projects/{projectId}/dc-templates/{id}/edit", handlers.HandleDCTemplateUpdate(app)).BindFunc(logisticsEditors)
+		se.Router.DELETE("/projects/{projectId}/dc-templates/{id}/delete", handlers.HandleDCTemplateDelete(app)).BindFunc(logisticsEditors)
+		se.Router.POST("/projects/{projectId}/dc-templates/{id}/duplicate", handlers.HandleDCTemplateDuplicate(app)).BindFunc(logisticsEditors)
 
 		// ── Transporters ────────────────────────────────────────
 		se.Router.GET("/projects/{projectId}/transporters/", handlers.HandleTransporterList(app))
-		se.Router.GET("/projects/{projectId}/transporters/create", handlers.HandleTransporterCreate(app))
-		se.Router.POST("/projects/{projectId}/transporters/create", handlers.HandleTransporterSave(app))
+		se.Router.GET("/projects/{projectId}/transporters/create", handlers.HandleTransporterCreate(app)).BindFunc(logisticsEditors)
+		se.Router.POST("/projects/{projectId}/transporters/create", handlers.HandleTransporterSave(app)).BindFunc(logisticsEditors)
 		se.Router.GET("/projects/{projectId}/transporters/{id}", handlers.HandleTransporterDetail(app))
-		se.Router.GET("/projects/{projectId}/transporters/{id}/edit", handlers.HandleTransporterEdit(app))
-		se.Router.POST("/projects/{projectId}/transporters/{id}/edit", handlers.HandleTransporterUpdate(app))
-		se.Router.POST("/projects/{projectId}/transporters/{id}/toggle", handlers.HandleTransporterToggle(app))
-		se.Router.POST("/projects/{projectId}/transporters/{id}/vehicles", handlers.HandleVehicleAdd(app))
-		se.Router.DELETE("/projects/{projectId}/transporters/{id}/vehicles/{vid}", handlers.HandleVehicleDelete(app))
+		se.Router.GET("/projects/{projectId}/transporters/{id}/edit", handlers.HandleTransporterEdit(app)).BindFunc(logisticsEditors)
+		se.Router.POST("/projects/{projectId}/transporters/{id}/edit", handlers.HandleTransporterUpdate(app)).BindFunc(logisticsEditors)
+		se.Router.POST("/projects/{projectId}/transporters/{id}/toggle", handlers.HandleTransporterToggle(app)).BindFunc(logisticsEditors)
+		se.Router.POST("/projects/{projectId}/transporters/{id}/vehicles", handlers.HandleVehicleAdd(app)).BindFunc(logisticsEditors)
+		se.Router.DELETE("/projects/{projectId}/transporters/{id}/vehicles/{vid}", handlers.HandleVehicleDelete(app)).BindFunc(logisticsEditors)
 
 		// ── DC Wizard ───────────────────────────────────────────
-		se.Router.GET("/projects/{projectId}/dcs/create", handlers.HandleDCWizardStep1(app))
-		se.Router.POST("/projects/{projectId}/dcs/create/step2", handlers.HandleDCWizardStep2(app))
-		se.Router.POST("/projects/{projectId}/dcs/create/back-to-step1", handlers.HandleDCWizardBackToStep1(app))
-		se.Router.POST("/projects/{projectId}/dcs/create/step3", handlers.HandleDCWizardStep3(app))
-		se.Router.POST("/projects/{projectId}/dcs/create/back-to-step2", handlers.HandleDCWizardBackToStep2(app))
-		se.Router.POST("/projects/{projectId}/dcs/create/step4", handlers.HandleDCWizardStep4(app))
-		se.Router.POST("/projects/{projectId}/dcs/create/back-to-step3", handlers.HandleDCWizardBackToStep3(app))
-		se.Router.POST("/projects/{projectId}/dcs/create", handlers.HandleDCCreate(app))
-		se.Router.POST("/projects/{projectId}/api/serials/validate", handlers.HandleSerialValidate(app))
-
-		// ── DC List, Detail, Issue, Edit, Delete ──────────────────
+		se.Router.GET("/projects/{projectId}/dcs/create", handlers.HandleDCWizardStep1(app)).BindFunc(logisticsEditors)
+		se.Router.POST("/projects/{projectId}/dcs/create/step2", handlers.HandleDCWizardStep2(app)).BindFunc(logisticsEditors)
+		se.Router.POST("/projects/{projectId}/dcs/create/back-to-step1", handlers.HandleDCWizardBackToStep1(app)).BindFunc(logisticsEditors)
+		se.Router.POST("/projects/{projectId}/dcs/create/step3", handlers.HandleDCWizardStep3(app)).BindFunc(logisticsEditors)
+		se.Router.POST("/projects/{projectId}/dcs/create/back-to-step2", handlers.HandleDCWizardBackToStep2(app)).BindFunc(logisticsEditors)
+		se.Router.POST("/projects/{projectId}/dcs/create/step4", handlers.HandleDCWizardStep4(app)).BindFunc(logisticsEditors)
+		se.Router.POST("/projects/{projectId}/dcs/create/back-to-step3", handlers.HandleDCWizardBackToStep3(app)).BindFunc(logisticsEditors)
+		se.Router.GET("/projects/{projectId}/dcs/create/serials/template", handlers.HandleDCWizardSerialTemplate(app)).BindFunc(logisticsEditors)
+		se.Router.POST("/projects/{projectId}/dcs/create/serials/import", handlers.HandleDCWizardSerialImport(app)).BindFunc(logisticsEditors)
+		se.Router.POST("/projects/{projectId}/dcs/create", handlers.HandleDCCreate(app)).BindFunc(logisticsEditors)
+		se.Router.POST("/projects/{projectId}/api/serials/validate", handlers.HandleSerialValidate(app)).BindFunc(logisticsEditors)
+
+		// ── DC List, Detail, Issue, Cancel, Edit, Delete ──────────
 		se.Router.GET("/projects/{projectId}/dcs/", handlers.HandleDCList(app))
 		se.Router.GET("/projects/{projectId}/dcs/{id}", handlers.HandleDCDetail(app))
-		se.Router.GET("/projects/{projectId}/dcs/{id}/edit", handlers.HandleDCEdit(app))
-		se.Router.POST("/projects/{projectId}/dcs/{id}/issue", handlers.HandleDCIssue(app))
-		se.Router.DELETE("/projects/{projectId}/dcs/{id}", handlers.HandleDCDelete(app))
+		se.Router.GET("/projects/{projectId}/dcs/{id}/edit", handlers.HandleDCEdit(app)).BindFunc(logisticsEditors)
+		se.Router.POST("/projects/{projectId}/dcs/{id}/issue", handlers.HandleDCIssue(app)).BindFunc(logisticsEditors)
+		se.Router.POST("/projects/{projectId}/dcs/{id}/cancel", handlers.HandleDCCancel(app)).BindFunc(logisticsEditors)
+		se.Router.DELETE("/projects/{projectId}/dcs/{id}", handlers.HandleDCDelete(app)).BindFunc(logisticsEditors)
+
+		// ── Proof of Delivery ───────────────────────────────────
+		se.Router.GET("/projects/{projectId}/dcs/{id}/delivery", handlers.HandleDCDeliveryCreate(app)).BindFunc(logisticsEditors)
+		se.Router.POST("/projects/{projectId}/dcs/{id}/delivery", handlers.HandleDCDeliverySave(app)).BindFunc(logisticsEditors)
+		se.Router.POST("/projects/{projectId}/dcs/{id}/delivery/scans", handlers.HandleDCDeliveryScansAdd(app)).BindFunc(logisticsEditors)
+		se.Router.GET("/projects/{projectId}/dcs/{id}/delivery/scans/{filename}", handlers.HandleDCDeliveryScan(app))
+
+		// ── Material Returns ────────────────────────────────────
+		se.Router.GET("/projects/{projectId}/returns", handlers.HandleMaterialReturns(app))
+		se.Router.GET("/projects/{projectId}/dcs/{id}/returns", handlers.HandleMRNCreate(app)).BindFunc(logisticsEditors)
+		se.Router.POST("/projects/{projectId}/dcs/{id}/returns", handlers.HandleMRNSave(app)).BindFunc(logisticsEditors)
 
 		// ── Split Wizard (Transfer DCs) ──────────────────────────
-		se.Router.GET("/projects/{projectId}/transfer-dcs/{id}/split", handlers.HandleSplitStep1(app))
-		se.Router.POST("/projects/{projectId}/transfer-dcs/{id}/split/step2", handlers.HandleSplitStep2(app))
-		se.Router.POST("/projects/{projectId}/transfer-dcs/{id}/split/step3", handlers.HandleSplitStep3(app))
-		se.Router.POST("/projects/{projectId}/transfer-dcs/{id}/split", handlers.HandleSplitCreate(app))
-		se.Router.POST("/projects/{projectId}/transfer-dcs/{id}/splits/{sid}/undo", handlers.HandleSplitUndo(app))
+		se.Router.GET("/projects/{projectId}/transfer-dcs/{id}/split", handlers.HandleSplitStep1(app)).BindFunc(logisticsEditors)
+		se.Router.POST("/projects/{projectId}/transfer-dcs/{id}/split/step2", handlers.HandleSplitStep2(app)).BindFunc(logisticsEditors)
+		se.Router.POST("/projects/{projectId}/transfer-dcs/{id}/split/step3", handlers.HandleSplitStep3(app)).BindFunc(logisticsEditors)
+		se.Router.POST("/projects/{projectId}/transfer-dcs/{id}/split", handlers.HandleSplitCreate(app)).BindFunc(logisticsEditors)
+		se.Router.POST("/projects/{projectId}/transfer-dcs/{id}/splits/{sid}/undo", handlers.HandleSplitUndo(app)).BindFunc(logisticsEditors)
 
 		// ── DC Exports ──────────────────────────────────────────
 		se.Router.GET("/projects/{projectId}/dcs/{id}/export/pdf", handlers.HandleDCExportPDF(app))
 		se.Router.GET("/projects/{projectId}/dcs/{id}/export/excel", handlers.HandleDCExportExcel(app))
 		se.Router.GET("/projects/{projectId}/dcs/{id}/print", handlers.HandleDCPrint(app))
 
+		// ── E-way Bills ─────────────────────────────────────────
+		se.Router.GET("/projects/{projectId}/dcs/{id}/eway-bill", handlers.HandleDCEwayBill(app))
+		se.Router.GET("/projects/{projectId}/dcs/{id}/eway-bill/json", handlers.HandleDCEwayBillJSON(app))
+		se.Router.GET("/projects/{projectId}/shipment-groups/{id}/eway-bill", handlers.HandleShipmentGroupEwayBill(app))
+		se.Router.GET("/projects/{projectId}/shipment-groups/{id}/eway-bill/json", handlers.HandleShipmentGroupEwayBillJSON(app))
+		se.Router.GET("/projects/{projectId}/eway-bills/import", handlers.HandleEwayBillImportPage(app)).BindFunc(logisticsEditors)
+		se.Router.POST("/projects/{projectId}/eway-bills/import", handlers.HandleEwayBillImport(app)).BindFunc(logisticsEditors)
+
 		// ── Shipment Groups ─────────────────────────────────────
 		se.Router.GET("/projects/{projectId}/shipment-groups/{id}", handlers.HandleShipmentGroupDetail(app))
-		se.Router.POST("/projects/{projectId}/shipment-groups/{id}/issue", handlers.HandleShipmentGroupIssueAll(app))
-		se.Router.DELETE("/projects/{projectId}/shipment-groups/{id}", handlers.HandleShipmentGroupDelete(app))
+		se.Router.POST("/projects/{projectId}/shipment-groups/{id}/issue", handlers.HandleShipmentGroupIssueAll(app)).BindFunc(logisticsEditors)
+		se.Router.DELETE("/projects/{projectId}/shipment-groups/{id}", handlers.HandleShipmentGroupDelete(app)).BindFunc(logisticsEditors)
+
+		// ── Client Invoices ─────────────────────────────────────
+		se.Router.GET("/projects/{projectId}/client-invoices/create", handlers.HandleClientInvoiceCreate(app)).BindFunc(logisticsEditors)
+		se.Router.POST("/projects/{projectId}/client-invoices", handlers.HandleClientInvoiceSave(app)).BindFunc(logisticsEditors)
+		se.Router.GET("/projects/{projectId}/client-invoices/{id}/export/pdf", handlers.HandleClientInvoiceExportPDF(app))
+		se.Router.GET("/projects/{projectId}/client-invoices", handlers.HandleClientInvoiceList(app))
+		se.Router.GET("/projects/{projectId}/client-invoices/{id}", handlers.HandleClientInvoiceView(app))
+
+		// ── Billing Milestones & Receivables ────────────────────
+		se.Router.GET("/projects/{projectId}/billing-milestones", handlers.HandleBillingMilestones(app))
+		se.Router.POST("/projects/{projectId}/billing-milestones", handlers.HandleBillingMilestoneAdd(app)).BindFunc(projectEditors)
+		se.Router.POST("/projects/{projectId}/billing-milestones/retention", handlers.HandleRetentionUpdate(app)).BindFunc(projectEditors)
+		se.Router.DELETE("/projects/{projectId}/billing-milestones/{id}", handlers.HandleBillingMilestoneDelete(app)).BindFunc(projectEditors)
+		se.Router.GET("/projects/{projectId}/billing-milestones/{id}/invoice", handlers.HandleMilestoneInvoiceCreate(app)).BindFunc(logisticsEditors)
+		se.Router.POST("/projects/{projectId}/billing-milestones/{id}/invoice", handlers.HandleMilestoneInvoiceSave(app)).BindFunc(logisticsEditors)
+		se.Router.GET("/projects/{projectId}/receivables", handlers.HandleProjectReceivables(app))
+		se.Router.GET("/projects/{projectId}/receipts/create", handlers.HandleClientReceiptCreate(app)).BindFunc(projectEditors)
+		se.Router.POST("/projects/{projectId}/receipts", handlers.HandleClientReceiptSave(app)).BindFunc(projectEditors)
+		se.Router.GET("/receivables", handlers.HandleReceivablesAging(app))
+
+		// ── Serial Lookup (global, across projects) ─────────────
+		se.Router.GET("/serials", handlers.HandleSerialLookup(app))
+
+		// ── Project P&L ─────────────────────────────────────────
+		se.Router.GET("/projects/{projectId}/pnl", handlers.HandleProjectPnL(app))
+		se.Router.GET("/projects/{projectId}/pnl/export/pdf", handlers.HandleProjectPnLExportPDF(app))
+		se.Router.GET("/projects/{projectId}/pnl/export/excel", handlers.HandleProjectPnLExportExcel(app))
+
+		// ── Project Budget ──────────────────────────────────────
+		se.Router.GET("/projects/{projectId}/budget", handlers.HandleProjectBudget(app))
+		se.Router.POST("/projects/{projectId}/budget", handlers.HandleProjectBudgetSave(app)).BindFunc(projectEditors)
+		se.Router.POST("/projects/{projectId}/budget/reset", handlers.HandleProjectBudgetReset(app)).BindFunc(projectEditors)
+		se.Router.POST("/projects/{projectId}/budget/alerts/{id}/dismiss", handlers.HandleBudgetAlertDismiss(app)).BindFunc(projectEditors)
+		se.Router.GET("/projects/{projectId}/budget/export/excel", handlers.HandleProjectBudgetExportExcel(app))
+
+		// ── Site Expenses ───────────────────────────────────────
+		se.Router.GET("/projects/{projectId}/expenses", handlers.HandleExpenseList(app))
+		se.Router.GET("/projects/{projectId}/expenses/new", handlers.HandleExpenseCreate(app))
+		se.Router.POST("/projects/{projectId}/expenses", handlers.HandleExpenseSave(app)).BindFunc(logisticsEditors)
+		se.Router.GET("/projects/{projectId}/expenses/import", handlers.HandleExpenseImportPage(app))
+		se.Router.POST("/projects/{projectId}/expenses/import", handlers.HandleExpenseValidate(app)).BindFunc(logisticsEditors)
+		se.Router.POST("/projects/{projectId}/expenses/import/commit", handlers.HandleExpenseImportCommit(app)).BindFunc(logisticsEditors)
+		se.Router.POST("/projects/{projectId}/expenses/import/errors", handlers.HandleExpenseErrorReport(app))
+		se.Router.GET("/projects/{projectId}/expenses/import/template", handlers.HandleExpenseImportTemplate(app))
+		se.Router.POST("/projects/{projectId}/expenses/{id}/approve", handlers.HandleExpenseApprove(app)).BindFunc(projectEditors)
+		se.Router.POST("/projects/{projectId}/expenses/{id}/reject", handlers.HandleExpenseReject(app)).BindFunc(projectEditors)
+		se.Router.POST("/projects/{projectId}/expenses/{id}/receipts", handlers.HandleExpenseReceiptsAdd(app)).BindFunc(logisticsEditors)
+		se.Router.GET("/projects/{projectId}/expenses/{id}/receipts/{filename}", handlers.HandleExpenseReceipt(app))
+
+		// ── Installation Work Orders ────────────────────────────
+		se.Router.GET("/projects/{projectId}/work-orders", handlers.HandleWorkOrderList(app))
+		se.Router.GET("/projects/{projectId}/work-orders/new", handlers.HandleWorkOrderCreate(app))
+		se.Router.POST("/projects/{projectId}/work-orders", handlers.HandleWorkOrderSave(app)).BindFunc(projectEditors)
+		se.Router.GET("/projects/{projectId}/work-orders/{id}", handlers.HandleWorkOrderView(app))
+		se.Router.POST("/projects/{projectId}/work-orders/{id}/sites/{siteId}", handlers.HandleWorkOrderSiteUpdate(app)).BindFunc(logisticsEditors)
+
+		// ── Commissioning & Site Tracker ────────────────────────
+		se.Router.GET("/projects/{projectId}/site-tracker", handlers.HandleSiteTracker(app))
+		se.Router.GET("/projects/{projectId}/commissioning/new", handlers.HandleCommissioningCreate(app))
+		se.Router.POST("/projects/{projectId}/commissioning", handlers.HandleCommissioningSave(app)).BindFunc(logisticsEditors)
+		se.Router.GET("/projects/{projectId}/commissioning/{id}/export/pdf", handlers.HandleCommissioningCertificatePDF(app))
+
+		// ── Change History ──────────────────────────────────────
+		se.Router.GET("/projects/{projectId}/history/{collection}/{id}", handlers.HandleAuditHistory(app))
 
 		// ── App Settings (global) ───────────────────────────────
-		se.Router.GET("/settings", handlers.HandleAppSettings(app))
-		se.Router.POST("/settings", handlers.HandleAppSettingsSave(app))
+		se.Router.GET("/settings", handlers.HandleAppSettings(app)).BindFunc(adminOnly)
+		se.Router.POST("/settings", handlers.HandleAppSettingsSave(app)).BindFunc(adminOnly)
+		se.Router.GET("/settings/tds", handlers.HandleTDSSettings(app)).BindFunc(adminOnly)
+		se.Router.POST("/settings/tds", handlers.HandleTDSSettingsSave(app)).BindFunc(adminOnly)
+		se.Router.GET("/settings/cost-categories", handlers.HandleCostCategorySettings(app)).BindFunc(adminOnly)
+		se.Router.POST("/settings/cost-categories", handlers.HandleCostCategorySettingsSave(app)).BindFunc(adminOnly)
 
 		// ── Legacy BOQ redirects ─────────────────────────────────
 		se.Router.GET("/boq", func(e *core.RequestEvent) error {
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -34,6 +35,7 @@ type ImportRowError struct {
 // Strategy: Process in chunks. Within each chunk, if any insert fails,
 // roll back the entire chunk and record errors. Continue with next chunk.
 func CommitAddressImport(
+	ctx context.Context,
 	app *pocketbase.PocketBase,
 	projectID string,
 	addressType string,
@@ -83,7 +85,7 @@ func CommitAddressImport(
 		}
 		chunk := parsedRows[chunkStart:chunkEnd]
 
-		chunkErrors := insertChunk(app, col, projectID, addressType, chunk, chunkStart, shipToLookup)
+		chunkErrors := insertChunk(ctx, app, col, projectID, addressType, chunk, chunkStart, shipToLookup)
 		if len(chunkErrors) > 0 {
 			result.Errors = append(result.Errors, chunkErrors...)
 			result.Failed += len(chunk) // entire chunk failed
@@ -99,6 +101,7 @@ func CommitAddressImport(
 // insertChunk inserts a batch of rows within a RunInTransaction block.
 // If any row fails, the entire chunk is rolled back and errors are returned.
 func insertChunk(
+	ctx context.Context,
 	app *pocketbase.PocketBase,
 	col *core.Collection,
 	projectID string,
@@ -167,7 +170,7 @@ func insertChunk(
 				}
 			}
 
-			if err := txApp.Save(record); err != nil {
+			if err := txApp.SaveWithContext(ctx, record); err != nil {
 				chunkErrors = append(chunkErrors, ImportRowError{
 					Row:     rowNum,
 					Field:   "",
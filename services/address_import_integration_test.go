@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"testing"
 
 	"projectcreation/collections"
@@ -35,7 +36,7 @@ func TestCommitAddressImport_Success(t *testing.T) {
 		},
 	}
 
-	result, err := CommitAddressImport(app, proj.Id, "ship_to", rows)
+	result, err := CommitAddressImport(context.Background(), app, proj.Id, "ship_to", rows)
 	if err != nil {
 		t.Fatalf("CommitAddressImport() error: %v", err)
 	}
@@ -65,7 +66,7 @@ func TestCommitAddressImport_Empty(t *testing.T) {
 	proj := testhelpers.CreateTestProject(t, app, "Empty Import")
 	collections.MigrateDefaultAddressSettings(app)
 
-	result, err := CommitAddressImport(app, proj.Id, "ship_to", []map[string]string{})
+	result, err := CommitAddressImport(context.Background(), app, proj.Id, "ship_to", []map[string]string{})
 	if err != nil {
 		t.Fatalf("CommitAddressImport() error: %v", err)
 	}
@@ -90,7 +91,7 @@ func TestCommitAddressImport_ValidationErrors(t *testing.T) {
 		},
 	}
 
-	result, err := CommitAddressImport(app, proj.Id, "ship_to", rows)
+	result, err := CommitAddressImport(context.Background(), app, proj.Id, "ship_to", rows)
 	if err != nil {
 		t.Fatalf("CommitAddressImport() error: %v", err)
 	}
@@ -129,7 +130,7 @@ func TestCommitAddressImport_InstallAt_WithShipToRef(t *testing.T) {
 		},
 	}
 
-	result, err := CommitAddressImport(app, proj.Id, "install_at", rows)
+	result, err := CommitAddressImport(context.Background(), app, proj.Id, "install_at", rows)
 	if err != nil {
 		t.Fatalf("CommitAddressImport() error: %v", err)
 	}
@@ -170,7 +171,7 @@ func TestCommitAddressImport_InstallAt_InvalidRef(t *testing.T) {
 		},
 	}
 
-	result, err := CommitAddressImport(app, proj.Id, "install_at", rows)
+	result, err := CommitAddressImport(context.Background(), app, proj.Id, "install_at", rows)
 	if err != nil {
 		t.Fatalf("CommitAddressImport() error: %v", err)
 	}
@@ -200,7 +201,7 @@ func TestCommitAddressImport_LargeBatch(t *testing.T) {
 		}
 	}
 
-	result, err := CommitAddressImport(app, proj.Id, "ship_to", rows)
+	result, err := CommitAddressImport(context.Background(), app, proj.Id, "ship_to", rows)
 	if err != nil {
 		t.Fatalf("CommitAddressImport() error: %v", err)
 	}
@@ -100,7 +100,11 @@ func GenerateAddressTemplate(app *pocketbase.PocketBase, projectID, addressType
 	})
 
 	// 7. Create hidden Instructions sheet
-	addInstructionsSheet(f, fields, requiredSet, addressType)
+	typeName := "Ship To"
+	if addressType == "install_at" {
+		typeName = "Install At"
+	}
+	addInstructionsSheet(f, fields, requiredSet, fmt.Sprintf("%s Address Import - Instructions", typeName))
 
 	// 8. Write to buffer
 	var buf bytes.Buffer
@@ -111,7 +115,7 @@ func GenerateAddressTemplate(app *pocketbase.PocketBase, projectID, addressType
 }
 
 // addInstructionsSheet creates a hidden sheet with field descriptions.
-func addInstructionsSheet(f *excelize.File, fields []TemplateField, requiredSet map[string]bool, addressType string) {
+func addInstructionsSheet(f *excelize.File, fields []TemplateField, requiredSet map[string]bool, title string) {
 	instSheet := "Instructions"
 	f.NewSheet(instSheet)
 
@@ -123,11 +127,7 @@ func addInstructionsSheet(f *excelize.File, fields []TemplateField, requiredSet
 		Fill: excelize.Fill{Type: "pattern", Color: []string{"#E5E7EB"}, Pattern: 1},
 	})
 
-	typeName := "Ship To"
-	if addressType == "install_at" {
-		typeName = "Install At"
-	}
-	f.SetCellValue(instSheet, "A1", fmt.Sprintf("%s Address Import - Instructions", typeName))
+	f.SetCellValue(instSheet, "A1", title)
 	f.SetCellStyle(instSheet, "A1", "A1", titleStyle)
 
 	instructionHeaders := []string{"Field Name", "Required?", "Format Rule", "Description", "Example"}
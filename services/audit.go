@@ -0,0 +1,291 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"reflect"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// AuditedCollections lists the collections whose changes are written to audit_log.
+var AuditedCollections = []string{
+	"projects",
+	"boqs",
+	"main_boq_items",
+	"sub_items",
+	"sub_sub_items",
+	"purchase_orders",
+	"po_line_items",
+	"delivery_challans",
+	"dc_line_items",
+	"serial_numbers",
+	"addresses",
+	"vendors",
+	"grn",
+	"grn_line_items",
+	"vendor_invoices",
+	"vendor_invoice_line_items",
+	"vendor_payments",
+	"vendor_payment_allocations",
+	"tds_sections",
+	"client_invoices",
+	"client_invoice_line_items",
+	"billing_milestones",
+	"client_receipts",
+	"client_receipt_allocations",
+	"cost_categories",
+	"project_budgets",
+	"expenses",
+	"work_orders",
+	"work_order_lines",
+	"work_order_sites",
+	"commissioning_records",
+	"dc_deliveries",
+	"dc_delivery_lines",
+	"material_return_notes",
+	"material_return_lines",
+}
+
+// auditSkipFields are bookkeeping fields that never appear in a diff.
+// actual_price on BOQ items is derived from PO costs by
+// RefreshBOQActualPrices.
+var auditSkipFields = map[string]bool{
+	"id":           true,
+	"created":      true,
+	"updated":      true,
+	"actual_price": true,
+}
+
+// AuditChange is a single field's value before and after a change.
+type AuditChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// AuditActor identifies who made a change.
+type AuditActor struct {
+	ID   string
+	Name string
+}
+
+type auditActorKey struct{}
+
+// WithAuditActor returns a context that attributes record changes saved with it
+// (via app.SaveWithContext / app.DeleteWithContext) to the given user.
+func WithAuditActor(ctx context.Context, userID, name string) context.Context {
+	return context.WithValue(ctx, auditActorKey{}, AuditActor{ID: userID, Name: name})
+}
+
+// auditActorFromContext returns the actor stored by WithAuditActor, if any.
+func auditActorFromContext(ctx context.Context) AuditActor {
+	if ctx == nil {
+		return AuditActor{}
+	}
+	if actor, ok := ctx.Value(auditActorKey{}).(AuditActor); ok {
+		return actor
+	}
+	return AuditActor{}
+}
+
+// DiffRecordFields compares two field maps and returns the fields whose value
+// changed. For creates pass a nil before map; for deletes a nil after map —
+// empty values are then left out so the entry only lists meaningful fields.
+func DiffRecordFields(before, after map[string]any) map[string]AuditChange {
+	changes := make(map[string]AuditChange)
+
+	keys := make(map[string]bool)
+	for k := range before {
+		keys[k] = true
+	}
+	for k := range after {
+		keys[k] = true
+	}
+
+	for k := range keys {
+		if auditSkipFields[k] {
+			continue
+		}
+		b, a := before[k], after[k]
+		if before == nil && isEmptyAuditValue(a) {
+			continue
+		}
+		if after == nil && isEmptyAuditValue(b) {
+			continue
+		}
+		if auditValuesEqual(b, a) {
+			continue
+		}
+		changes[k] = AuditChange{Before: b, After: a}
+	}
+
+	return changes
+}
+
+func auditValuesEqual(a, b any) bool {
+	aj, errA := json.Marshal(a)
+	bj, errB := json.Marshal(b)
+	if errA != nil || errB != nil {
+		return reflect.DeepEqual(a, b)
+	}
+	return string(aj) == string(bj)
+}
+
+func isEmptyAuditValue(v any) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Map, reflect.String:
+		return rv.Len() == 0
+	}
+	return rv.IsZero()
+}
+
+// RegisterAuditHooks binds record hooks that write an audit_log entry for
+// every create, update and delete in AuditedCollections. Entries are saved
+// through the same app instance as the change so they roll back with it.
+func RegisterAuditHooks(app *pocketbase.PocketBase) {
+	app.OnRecordCreate(AuditedCollections...).BindFunc(func(e *core.RecordEvent) error {
+		if err := e.Next(); err != nil {
+			return err
+		}
+		writeAuditEntry(e, "create", nil, e.Record.FieldsData(), "", "")
+		return nil
+	})
+
+	app.OnRecordUpdate(AuditedCollections...).BindFunc(func(e *core.RecordEvent) error {
+		// Read the stored values rather than Original(): a record reused right
+		// after its create still has an empty original.
+		var before map[string]any
+		if stored, err := e.App.FindRecordById(e.Record.Collection(), e.Record.Id); err == nil {
+			before = stored.FieldsData()
+		} else {
+			before = e.Record.Original().FieldsData()
+		}
+		if err := e.Next(); err != nil {
+			return err
+		}
+		writeAuditEntry(e, "update", before, e.Record.FieldsData(), "", "")
+		return nil
+	})
+
+	app.OnRecordDelete(AuditedCollections...).BindFunc(func(e *core.RecordEvent) error {
+		// Resolve the parent document while it still exists
+		before := e.Record.FieldsData()
+		docCol, docID := auditDocument(e.App, e.Record)
+		if err := e.Next(); err != nil {
+			return err
+		}
+		writeAuditEntry(e, "delete", before, nil, docCol, docID)
+		return nil
+	})
+}
+
+func writeAuditEntry(e *core.RecordEvent, action string, before, after map[string]any, docCol, docID string) {
+	changes := DiffRecordFields(before, after)
+	if action == "update" && len(changes) == 0 {
+		return
+	}
+
+	col, err := e.App.FindCollectionByNameOrId("audit_log")
+	if err != nil {
+		log.Printf("audit: audit_log collection not found: %v", err)
+		return
+	}
+
+	if docCol == "" {
+		docCol, docID = auditDocument(e.App, e.Record)
+	}
+
+	actor := auditActorFromContext(e.Context)
+
+	entry := core.NewRecord(col)
+	entry.Set("collection_name", e.Record.Collection().Name)
+	entry.Set("record_id", e.Record.Id)
+	entry.Set("document_collection", docCol)
+	entry.Set("document_id", docID)
+	entry.Set("project", auditProject(e.App, e.Record, docCol, docID))
+	entry.Set("action", action)
+	entry.Set("actor", actor.ID)
+	entry.Set("actor_name", actor.Name)
+	entry.Set("changes", changes)
+	if err := e.App.Save(entry); err != nil {
+		log.Printf("audit: failed to record %s of %s/%s: %v", action, e.Record.Collection().Name, e.Record.Id, err)
+	}
+}
+
+// auditDocument maps a record to the page-level document it belongs to:
+// BOQ items roll up to their BOQ, PO lines to their PO, GRN lines to their
+// GRN, payment and receipt allocations to their payment or receipt, work
+// order lines and sites to their work order, and DC lines, serials, proof
+// of delivery and material returns to their DC. Other records are their own
+// document.
+func auditDocument(app core.App, rec *core.Record) (string, string) {
+	switch rec.Collection().Name {
+	case "main_boq_items":
+		return "boqs", rec.GetString("boq")
+	case "sub_items":
+		if mi, err := app.FindRecordById("main_boq_items", rec.GetString("main_item")); err == nil {
+			return "boqs", mi.GetString("boq")
+		}
+	case "sub_sub_items":
+		if si, err := app.FindRecordById("sub_items", rec.GetString("sub_item")); err == nil {
+			if mi, err := app.FindRecordById("main_boq_items", si.GetString("main_item")); err == nil {
+				return "boqs", mi.GetString("boq")
+			}
+		}
+	case "po_line_items":
+		return "purchase_orders", rec.GetString("purchase_order")
+	case "dc_line_items":
+		return "delivery_challans", rec.GetString("dc")
+	case "grn_line_items":
+		return "grn", rec.GetString("grn")
+	case "vendor_invoice_line_items":
+		return "vendor_invoices", rec.GetString("vendor_invoice")
+	case "vendor_payment_allocations":
+		return "vendor_payments", rec.GetString("vendor_payment")
+	case "client_invoice_line_items":
+		return "client_invoices", rec.GetString("client_invoice")
+	case "client_receipt_allocations":
+		return "client_receipts", rec.GetString("client_receipt")
+	case "work_order_lines", "work_order_sites":
+		return "work_orders", rec.GetString("work_order")
+	case "dc_deliveries":
+		return "delivery_challans", rec.GetString("dc")
+	case "dc_delivery_lines":
+		if d, err := app.FindRecordById("dc_deliveries", rec.GetString("delivery")); err == nil {
+			return "delivery_challans", d.GetString("dc")
+		}
+	case "material_return_notes":
+		return "delivery_challans", rec.GetString("dc")
+	case "material_return_lines":
+		if m, err := app.FindRecordById("material_return_notes", rec.GetString("mrn")); err == nil {
+			return "delivery_challans", m.GetString("dc")
+		}
+	case "serial_numbers":
+		if li, err := app.FindRecordById("dc_line_items", rec.GetString("line_item")); err == nil {
+			return "delivery_challans", li.GetString("dc")
+		}
+	}
+	return rec.Collection().Name, rec.Id
+}
+
+// auditProject returns the project an entry belongs to, if any.
+func auditProject(app core.App, rec *core.Record, docCol, docID string) string {
+	if rec.Collection().Name == "projects" {
+		return rec.Id
+	}
+	if p := rec.GetString("project"); p != "" {
+		return p
+	}
+	if docCol != "" && docID != "" && docID != rec.Id {
+		if doc, err := app.FindRecordById(docCol, docID); err == nil {
+			return doc.GetString("project")
+		}
+	}
+	return ""
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"projectcreation/testhelpers"
+
+	"github.com/pocketbase/dbx"
+)
+
+func TestDiffRecordFields_Update(t *testing.T) {
+	before := map[string]any{"id": "r1", "title": "Old", "qty": 5.0, "updated": "a"}
+	after := map[string]any{"id": "r1", "title": "New", "qty": 5.0, "updated": "b"}
+
+	changes := DiffRecordFields(before, after)
+	if len(changes) != 1 {
+		t.Fatalf("expected 1 change, got %d: %v", len(changes), changes)
+	}
+	if c := changes["title"]; c.Before != "Old" || c.After != "New" {
+		t.Errorf("unexpected title change: %+v", c)
+	}
+}
+
+func TestDiffRecordFields_CreateSkipsEmpty(t *testing.T) {
+	after := map[string]any{"id": "r1", "title": "New", "notes": "", "qty": 0.0, "tags": []string{}}
+
+	changes := DiffRecordFields(nil, after)
+	if len(changes) != 1 {
+		t.Fatalf("expected only title in create diff, got %v", changes)
+	}
+	if c := changes["title"]; c.Before != nil || c.After != "New" {
+		t.Errorf("unexpected title change: %+v", c)
+	}
+}
+
+func TestAuditHooks_RecordActorAndDocument(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	RegisterAuditHooks(app)
+
+	project := testhelpers.CreateTestProject(t, app, "Audit Project")
+	boq := testhelpers.CreateTestBOQ(t, app, project.Id, "Audit BOQ")
+	item := testhelpers.CreateTestMainBOQItem(t, app, boq.Id, "Cable")
+	user := testhelpers.CreateTestUser(t, app, "pm@example.com", "project_manager", project.Id)
+
+	ctx := WithAuditActor(context.Background(), user.Id, "Priya")
+	item.Set("description", "Armoured cable")
+	if err := app.SaveWithContext(ctx, item); err != nil {
+		t.Fatalf("failed to update item: %v", err)
+	}
+
+	entries, err := app.FindRecordsByFilter("audit_log",
+		"collection_name = 'main_boq_items' && action = 'update'", "", 0, 0)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("expected 1 update entry, got %d (err %v)", len(entries), err)
+	}
+	entry := entries[0]
+	if entry.GetString("document_collection") != "boqs" || entry.GetString("document_id") != boq.Id {
+		t.Errorf("expected entry to roll up to BOQ %s, got %s/%s",
+			boq.Id, entry.GetString("document_collection"), entry.GetString("document_id"))
+	}
+	if entry.GetString("project") != project.Id {
+		t.Errorf("expected project %s, got %s", project.Id, entry.GetString("project"))
+	}
+	if entry.GetString("actor") != user.Id || entry.GetString("actor_name") != "Priya" {
+		t.Errorf("expected actor %s/Priya, got %s/%s", user.Id, entry.GetString("actor"), entry.GetString("actor_name"))
+	}
+
+	var changes map[string]AuditChange
+	if err := entry.UnmarshalJSONField("changes", &changes); err != nil {
+		t.Fatalf("failed to parse changes: %v", err)
+	}
+	if c, ok := changes["description"]; !ok || c.Before != "Cable" || c.After != "Armoured cable" {
+		t.Errorf("unexpected description change: %+v", changes)
+	}
+
+	// Deleting still resolves the parent document
+	if err := app.DeleteWithContext(ctx, item); err != nil {
+		t.Fatalf("failed to delete item: %v", err)
+	}
+	total, err := app.CountRecords("audit_log", dbx.HashExp{"document_id": boq.Id, "action": "delete"})
+	if err != nil || total != 1 {
+		t.Errorf("expected 1 delete entry for the BOQ, got %d (err %v)", total, err)
+	}
+}
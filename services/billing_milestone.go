@@ -0,0 +1,257 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// BillingMilestone is a stage of a contract billed as a percentage of the
+// project's contract value.
+type BillingMilestone struct {
+	ID            string
+	Name          string
+	Percentage    float64
+	Amount        float64 // taxable value billed at this milestone
+	InvoiceID     string  // "" until the milestone is invoiced
+	InvoiceNumber string
+}
+
+// Invoiced reports whether an invoice has been raised for the milestone.
+func (m BillingMilestone) Invoiced() bool {
+	return m.InvoiceID != ""
+}
+
+// ProjectContractValue returns the quoted total of all the project's BOQs,
+// before GST. Billing milestones are percentages of this value.
+func ProjectContractValue(app core.App, projectID string) (float64, error) {
+	boqs, err := app.FindRecordsByFilter("boqs", "project = {:pid}", "", 0, 0, map[string]any{"pid": projectID})
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch BOQs: %w", err)
+	}
+	var items []MainItemForTotals
+	for _, boq := range boqs {
+		mainItems, err := app.FindRecordsByFilter("main_boq_items", "boq = {:boqId}", "", 0, 0, map[string]any{"boqId": boq.Id})
+		if err != nil {
+			return 0, fmt.Errorf("failed to fetch main items of BOQ %s: %w", boq.Id, err)
+		}
+		for _, mi := range mainItems {
+			items = append(items, MainItemForTotals{
+				Qty:           mi.GetFloat("qty"),
+				QuotedPrice:   mi.GetFloat("quoted_price"),
+				BudgetedPrice: mi.GetFloat("budgeted_price"),
+			})
+		}
+	}
+	return CalcBOQTotals(items).TotalQuoted, nil
+}
+
+// MilestoneAmount returns the taxable value of a milestone, rounded to paise.
+func MilestoneAmount(contractValue, percentage float64) float64 {
+	return math.Round(contractValue*percentage) / 100
+}
+
+// ProjectBillingMilestones returns the project's milestones in order, with
+// the amount each bills and the invoice raised for it, along with the
+// contract value the amounts are based on.
+func ProjectBillingMilestones(app core.App, projectID string) ([]BillingMilestone, float64, error) {
+	contractValue, err := ProjectContractValue(app, projectID)
+	if err != nil {
+		return nil, 0, err
+	}
+	records, err := app.FindRecordsByFilter("billing_milestones", "project = {:pid}", "sort_order,created", 0, 0, map[string]any{"pid": projectID})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch billing milestones: %w", err)
+	}
+	invoices, err := app.FindRecordsByFilter("client_invoices", "project = {:pid} && billing_milestone != ''", "", 0, 0, map[string]any{"pid": projectID})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch milestone invoices: %w", err)
+	}
+	invoiceFor := make(map[string]*core.Record, len(invoices))
+	for _, inv := range invoices {
+		invoiceFor[inv.GetString("billing_milestone")] = inv
+	}
+
+	milestones := make([]BillingMilestone, 0, len(records))
+	for _, rec := range records {
+		m := BillingMilestone{
+			ID:         rec.Id,
+			Name:       rec.GetString("name"),
+			Percentage: rec.GetFloat("percentage"),
+			Amount:     MilestoneAmount(contractValue, rec.GetFloat("percentage")),
+		}
+		if inv, ok := invoiceFor[rec.Id]; ok {
+			m.InvoiceID = inv.Id
+			m.InvoiceNumber = inv.GetString("invoice_number")
+			// An invoiced milestone bills what was invoiced, even if the
+			// BOQ has changed since
+			m.Amount = inv.GetFloat("taxable_amount")
+		}
+		milestones = append(milestones, m)
+	}
+	return milestones, contractValue, nil
+}
+
+// ValidateBillingMilestone checks a new milestone against the project's
+// existing ones. It returns one message per problem, keyed by form field.
+func ValidateBillingMilestone(existing []BillingMilestone, name string, percentage float64) map[string]string {
+	errs := make(map[string]string)
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		errs["name"] = "Milestone name is required"
+	} else if slices.ContainsFunc(existing, func(m BillingMilestone) bool { return strings.EqualFold(m.Name, name) }) {
+		errs["name"] = fmt.Sprintf("A milestone named %q already exists", name)
+	}
+
+	var total float64
+	for _, m := range existing {
+		total += m.Percentage
+	}
+	switch {
+	case percentage <= 0 || percentage > 100:
+		errs["percentage"] = "Percentage must be more than 0 and at most 100"
+	case total+percentage > 100.0001:
+		errs["percentage"] = fmt.Sprintf("Milestones already bill %s%% of the contract; only %s%% is left",
+			formatGSTRate(total), formatGSTRate(100-total))
+	}
+	return errs
+}
+
+// RetentionAmount returns the retention the client holds back on an invoice
+// total, at the project's retention percentage, rounded to the rupee.
+func RetentionAmount(app core.App, projectID string, total float64) float64 {
+	project, err := app.FindRecordById("projects", projectID)
+	if err != nil {
+		return 0
+	}
+	return math.Round(total * project.GetFloat("retention_percent") / 100)
+}
+
+// MilestoneInvoiceParams holds the inputs for a milestone invoice.
+type MilestoneInvoiceParams struct {
+	ProjectID   string
+	MilestoneID string
+	InvoiceDate string
+	BillFromID  string
+	BillToID    string
+	SACCode     string
+	TaxPercent  float64
+	Remarks     string
+}
+
+// ValidateMilestoneInvoice checks a milestone invoice before it is raised.
+// It returns one message per problem, keyed by form field ("" for problems
+// with the milestone itself).
+func ValidateMilestoneInvoice(app core.App, params MilestoneInvoiceParams) map[string]string {
+	errs := make(map[string]string)
+
+	milestone, err := app.FindRecordById("billing_milestones", params.MilestoneID)
+	if err != nil || milestone.GetString("project") != params.ProjectID {
+		errs[""] = "Billing milestone not found"
+	} else if invoiced, _ := app.FindRecordsByFilter("client_invoices", "billing_milestone = {:id}", "", 1, 0, map[string]any{"id": milestone.Id}); len(invoiced) > 0 {
+		errs[""] = fmt.Sprintf("%s has already been invoiced on %s", milestone.GetString("name"), invoiced[0].GetString("invoice_number"))
+	}
+
+	if params.InvoiceDate == "" {
+		errs["invoice_date"] = "Invoice date is required"
+	}
+	for field, check := range map[string]struct{ id, addressType, label string }{
+		"bill_from": {params.BillFromID, "bill_from", "Choose the address billed from"},
+		"bill_to":   {params.BillToID, "bill_to", "Choose the client billed to"},
+	} {
+		addr, err := app.FindRecordById("addresses", check.id)
+		if err != nil || addr.GetString("project") != params.ProjectID || addr.GetString("address_type") != check.addressType {
+			errs[field] = check.label
+		}
+	}
+	if !slices.Contains(GSTOptions, int(params.TaxPercent)) || params.TaxPercent != math.Trunc(params.TaxPercent) {
+		errs["tax_percentage"] = "Choose a GST rate"
+	}
+	return errs
+}
+
+// CreateMilestoneInvoice raises a tax invoice for a billing milestone. The
+// invoice bills the milestone's share of the contract value as a single
+// lump-sum line and takes its number from the project's "inv" sequence, like
+// invoices raised against DCs.
+func CreateMilestoneInvoice(ctx context.Context, app *pocketbase.PocketBase, params MilestoneInvoiceParams) (*ClientInvoiceResult, error) {
+	if errs := ValidateMilestoneInvoice(app, params); len(errs) > 0 {
+		msgs := make([]string, 0, len(errs))
+		for _, m := range errs {
+			msgs = append(msgs, m)
+		}
+		slices.Sort(msgs)
+		return nil, fmt.Errorf("invalid milestone invoice: %s", strings.Join(msgs, "; "))
+	}
+
+	milestone, err := app.FindRecordById("billing_milestones", params.MilestoneID)
+	if err != nil {
+		return nil, fmt.Errorf("billing milestone not found: %w", err)
+	}
+	contractValue, err := ProjectContractValue(app, params.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	amount := MilestoneAmount(contractValue, milestone.GetFloat("percentage"))
+	if amount <= 0 {
+		return nil, fmt.Errorf("the project has no quoted BOQ value to bill")
+	}
+
+	var from, to map[string]string
+	if addr, err := app.FindRecordById("addresses", params.BillFromID); err == nil {
+		from = ReadAddressData(addr)
+	}
+	if addr, err := app.FindRecordById("addresses", params.BillToID); err == nil {
+		to = ReadAddressData(addr)
+	}
+	taxType := DetermineTaxTypeByState(from["state"], from["gstin"], to["state"], to["gstin"])
+	totals := CalcPOTotalsForTaxType([]POLineItemCalc{CalcPOLineItem(amount, 1, params.TaxPercent)}, taxType)
+
+	docDate, err := time.Parse("2006-01-02", params.InvoiceDate)
+	if err != nil {
+		docDate = time.Now()
+	}
+	invoiceNumber, err := NextDocNumber(app, params.ProjectID, "inv", docDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate invoice number: %w", err)
+	}
+
+	invoiceCol, err := app.FindCollectionByNameOrId("client_invoices")
+	if err != nil {
+		return nil, fmt.Errorf("client_invoices collection not found: %w", err)
+	}
+	invoice := core.NewRecord(invoiceCol)
+	invoice.Set("project", params.ProjectID)
+	invoice.Set("invoice_number", invoiceNumber)
+	invoice.Set("invoice_date", params.InvoiceDate)
+	invoice.Set("bill_from_address", params.BillFromID)
+	invoice.Set("bill_to_address", params.BillToID)
+	invoice.Set("tax_type", totals.TaxType)
+	invoice.Set("billing_milestone", milestone.Id)
+	invoice.Set("milestone_description", fmt.Sprintf("%s — %s%% of contract value", milestone.GetString("name"), formatGSTRate(milestone.GetFloat("percentage"))))
+	invoice.Set("sac_code", strings.TrimSpace(params.SACCode))
+	invoice.Set("milestone_tax_percentage", params.TaxPercent)
+	invoice.Set("taxable_amount", totals.TotalBeforeTax)
+	invoice.Set("tax_amount", totals.IGSTAmount+totals.CGSTAmount+totals.SGSTAmount)
+	invoice.Set("round_off", totals.RoundOff)
+	invoice.Set("total_amount", totals.GrandTotal)
+	invoice.Set("retention_amount", RetentionAmount(app, params.ProjectID, totals.GrandTotal))
+	invoice.Set("remarks", strings.TrimSpace(params.Remarks))
+	if err := app.SaveWithContext(ctx, invoice); err != nil {
+		return nil, fmt.Errorf("failed to create milestone invoice: %w", err)
+	}
+
+	return &ClientInvoiceResult{
+		InvoiceID:     invoice.Id,
+		InvoiceNumber: invoiceNumber,
+		LineCount:     1,
+		GrandTotal:    totals.GrandTotal,
+	}, nil
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/testhelpers"
+)
+
+// setupMilestoneTest creates a project with a contract value of ₹1,00,000
+// (10 Nos quoted at ₹10,000), 5% retention, bill-from and bill-to
+// addresses in Maharashtra and an "Advance" milestone of 10%.
+func setupMilestoneTest(t *testing.T) (app *pocketbase.PocketBase, project, billFrom, billTo, advance *core.Record) {
+	t.Helper()
+	app = testhelpers.NewTestApp(t)
+	project = testhelpers.CreateTestProject(t, app, "Milestone Project")
+	project.Set("retention_percent", 5)
+	if err := app.Save(project); err != nil {
+		t.Fatalf("failed to set retention: %v", err)
+	}
+	boq := testhelpers.CreateTestBOQ(t, app, project.Id, "Milestone BOQ")
+	mainItem := testhelpers.CreateTestMainBOQItem(t, app, boq.Id, "Solar plant")
+	mainItem.Set("quoted_price", 10000)
+	if err := app.Save(mainItem); err != nil {
+		t.Fatalf("failed to price main item: %v", err)
+	}
+	billFrom = testhelpers.CreateTestAddress(t, app, project.Id, "bill_from", "Our Company")
+	billTo = testhelpers.CreateTestAddress(t, app, project.Id, "bill_to", "Client Ltd")
+	advance = saveTestRecord(t, app, "billing_milestones", map[string]any{
+		"project": project.Id, "name": "Advance", "percentage": 10, "sort_order": 1,
+	})
+	return app, project, billFrom, billTo, advance
+}
+
+func TestProjectBillingMilestones(t *testing.T) {
+	app, project, _, _, _ := setupMilestoneTest(t)
+	saveTestRecord(t, app, "billing_milestones", map[string]any{
+		"project": project.Id, "name": "On supply", "percentage": 60, "sort_order": 2,
+	})
+
+	milestones, contractValue, err := ProjectBillingMilestones(app, project.Id)
+	if err != nil {
+		t.Fatalf("ProjectBillingMilestones failed: %v", err)
+	}
+	if contractValue != 100000 {
+		t.Errorf("expected contract value 100000, got %v", contractValue)
+	}
+	if len(milestones) != 2 || milestones[0].Name != "Advance" || milestones[1].Amount != 60000 {
+		t.Errorf("unexpected milestones: %+v", milestones)
+	}
+}
+
+func TestValidateBillingMilestone(t *testing.T) {
+	existing := []BillingMilestone{{Name: "Advance", Percentage: 10}, {Name: "On supply", Percentage: 60}}
+	tests := []struct {
+		name       string
+		milestone  string
+		percentage float64
+		wantField  string
+	}{
+		{"valid", "On commissioning", 30, ""},
+		{"missing_name", " ", 10, "name"},
+		{"duplicate_name", "advance", 10, "name"},
+		{"zero", "On installation", 0, "percentage"},
+		{"over_100", "On installation", 31, "percentage"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := ValidateBillingMilestone(existing, tt.milestone, tt.percentage)
+			if tt.wantField == "" {
+				if len(errs) != 0 {
+					t.Errorf("expected no errors, got %v", errs)
+				}
+				return
+			}
+			if _, ok := errs[tt.wantField]; !ok {
+				t.Errorf("expected an error on %q, got %v", tt.wantField, errs)
+			}
+		})
+	}
+}
+
+func TestCreateMilestoneInvoice(t *testing.T) {
+	app, project, billFrom, billTo, advance := setupMilestoneTest(t)
+	params := MilestoneInvoiceParams{
+		ProjectID:   project.Id,
+		MilestoneID: advance.Id,
+		InvoiceDate: "2026-04-02",
+		BillFromID:  billFrom.Id,
+		BillToID:    billTo.Id,
+		SACCode:     "9954",
+		TaxPercent:  18,
+	}
+
+	result, err := CreateMilestoneInvoice(context.Background(), app, params)
+	if err != nil {
+		t.Fatalf("CreateMilestoneInvoice failed: %v", err)
+	}
+	if !strings.Contains(result.InvoiceNumber, "INV-2627") || result.GrandTotal != 11800 {
+		t.Errorf("expected an FY 2627 invoice of 11800, got %s for %v", result.InvoiceNumber, result.GrandTotal)
+	}
+
+	invoice, _ := app.FindRecordById("client_invoices", result.InvoiceID)
+	if invoice.GetString("tax_type") != TaxTypeCGSTSGST || invoice.GetFloat("retention_amount") != 590 {
+		t.Errorf("expected CGST+SGST with 590 retention, got %s with %v", invoice.GetString("tax_type"), invoice.GetFloat("retention_amount"))
+	}
+
+	data, err := BuildClientInvoiceExportData(app, result.InvoiceID)
+	if err != nil {
+		t.Fatalf("BuildClientInvoiceExportData failed: %v", err)
+	}
+	if data.Milestone != "Advance" || len(data.LineItems) != 1 || data.LineItems[0].HSNCode != "9954" || data.Totals.GrandTotal != 11800 {
+		t.Errorf("unexpected export data: milestone %q, lines %+v, total %v", data.Milestone, data.LineItems, data.Totals.GrandTotal)
+	}
+	if _, err := GenerateClientInvoicePDF(data); err != nil {
+		t.Errorf("GenerateClientInvoicePDF failed: %v", err)
+	}
+
+	// A milestone is invoiced once
+	_, err = CreateMilestoneInvoice(context.Background(), app, params)
+	if err == nil || !strings.Contains(err.Error(), "already been invoiced") {
+		t.Errorf("expected the second invoice to be rejected, got %v", err)
+	}
+}
+
+func TestValidateMilestoneInvoice_RejectsWrongAddresses(t *testing.T) {
+	app, project, billFrom, billTo, advance := setupMilestoneTest(t)
+
+	errs := ValidateMilestoneInvoice(app, MilestoneInvoiceParams{
+		ProjectID:   project.Id,
+		MilestoneID: advance.Id,
+		InvoiceDate: "2026-04-02",
+		BillFromID:  billTo.Id,
+		BillToID:    billFrom.Id,
+		TaxPercent:  7,
+	})
+	for _, field := range []string{"bill_from", "bill_to", "tax_percentage"} {
+		if _, ok := errs[field]; !ok {
+			t.Errorf("expected an error on %q, got %v", field, errs)
+		}
+	}
+}
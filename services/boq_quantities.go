@@ -0,0 +1,84 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// boqQuantity is the total project quantity of a single BOQ item.
+type boqQuantity struct {
+	SourceItemType string // "main_item", "sub_item" or "sub_sub_item"
+	SourceItemID   string
+	Description    string
+	UOM            string
+	Qty            float64
+	MainItemID     string // the main item the item belongs to, or is
+	ParentKey      string // DispatchItemKey of the parent item; "" for main items
+	ItemType       string // "product" or "service"; "" for main items
+}
+
+// projectBOQQuantities returns the total quantity of every BOQ item in the
+// project, keyed by DispatchItemKey. Main items use their own quantity; sub
+// items and sub sub items multiply qty_per_unit by their parent's total.
+func projectBOQQuantities(app core.App, projectID string) (map[string]*boqQuantity, error) {
+	quantities := make(map[string]*boqQuantity)
+
+	mainItems, err := app.FindRecordsByFilter("main_boq_items", "boq.project = {:pid}", "", 0, 0, map[string]any{"pid": projectID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch BOQ items: %w", err)
+	}
+	mainQty := make(map[string]float64, len(mainItems))
+	for _, mi := range mainItems {
+		mainQty[mi.Id] = mi.GetFloat("qty")
+		quantities[DispatchItemKey("main_item", mi.Id)] = &boqQuantity{
+			SourceItemType: "main_item",
+			SourceItemID:   mi.Id,
+			Description:    mi.GetString("description"),
+			UOM:            mi.GetString("uom"),
+			Qty:            mi.GetFloat("qty"),
+			MainItemID:     mi.Id,
+		}
+	}
+
+	subItems, err := app.FindRecordsByFilter("sub_items", "main_item.boq.project = {:pid}", "", 0, 0, map[string]any{"pid": projectID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch BOQ sub items: %w", err)
+	}
+	subQty := make(map[string]float64, len(subItems))
+	subMain := make(map[string]string, len(subItems))
+	for _, si := range subItems {
+		qty := si.GetFloat("qty_per_unit") * mainQty[si.GetString("main_item")]
+		subQty[si.Id] = qty
+		subMain[si.Id] = si.GetString("main_item")
+		quantities[DispatchItemKey("sub_item", si.Id)] = &boqQuantity{
+			SourceItemType: "sub_item",
+			SourceItemID:   si.Id,
+			Description:    si.GetString("description"),
+			UOM:            si.GetString("uom"),
+			Qty:            qty,
+			MainItemID:     si.GetString("main_item"),
+			ParentKey:      DispatchItemKey("main_item", si.GetString("main_item")),
+			ItemType:       si.GetString("type"),
+		}
+	}
+
+	subSubItems, err := app.FindRecordsByFilter("sub_sub_items", "sub_item.main_item.boq.project = {:pid}", "", 0, 0, map[string]any{"pid": projectID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch BOQ sub sub items: %w", err)
+	}
+	for _, ssi := range subSubItems {
+		quantities[DispatchItemKey("sub_sub_item", ssi.Id)] = &boqQuantity{
+			SourceItemType: "sub_sub_item",
+			SourceItemID:   ssi.Id,
+			Description:    ssi.GetString("description"),
+			UOM:            ssi.GetString("uom"),
+			Qty:            ssi.GetFloat("qty_per_unit") * subQty[ssi.GetString("sub_item")],
+			MainItemID:     subMain[ssi.GetString("sub_item")],
+			ParentKey:      DispatchItemKey("sub_item", ssi.GetString("sub_item")),
+			ItemType:       ssi.GetString("type"),
+		}
+	}
+
+	return quantities, nil
+}
@@ -0,0 +1,323 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// InvoiceableLine is a DC line that can be billed on a client invoice.
+type InvoiceableLine struct {
+	DCID         string
+	DCNumber     string
+	DCLineItemID string
+	Description  string
+	HSNCode      string
+	UOM          string
+	Quantity     float64
+	Rate         float64
+	TaxPercent   float64
+}
+
+// ClientInvoiceDraft is what a set of DCs would be billed as: the parties
+// and tax terms they share and their lines not yet invoiced.
+type ClientInvoiceDraft struct {
+	BillFromID    string
+	BillToID      string
+	ShipToID      string // only set when every DC ships to the same address
+	TaxType       string
+	ReverseCharge bool
+	Lines         []InvoiceableLine
+}
+
+// Totals returns the invoice totals for the draft's lines.
+func (d *ClientInvoiceDraft) Totals() POTotals {
+	calcs := make([]POLineItemCalc, len(d.Lines))
+	for i, l := range d.Lines {
+		calcs[i] = CalcPOLineItem(l.Rate, l.Quantity, l.TaxPercent)
+	}
+	return CalcPOTotalsForTaxType(calcs, d.TaxType)
+}
+
+// ClientInvoiceParams holds the inputs for a client invoice.
+type ClientInvoiceParams struct {
+	ProjectID   string
+	InvoiceDate string
+	DCIDs       []string
+	Remarks     string
+}
+
+// ClientInvoiceResult holds the output of CreateClientInvoice.
+type ClientInvoiceResult struct {
+	InvoiceID     string
+	InvoiceNumber string
+	LineCount     int
+	GrandTotal    float64
+}
+
+// InvoicedDCLines returns the DC lines of the given DCs that are already on
+// a client invoice, mapped to the ID of that invoice.
+func InvoicedDCLines(app core.App, dcIDs []string) (map[string]string, error) {
+	invoiced := make(map[string]string)
+	for _, dcID := range dcIDs {
+		lines, err := app.FindRecordsByFilter("client_invoice_line_items", "dc = {:dcId}", "", 0, 0, map[string]any{"dcId": dcID})
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch invoiced lines: %w", err)
+		}
+		for _, l := range lines {
+			invoiced[l.GetString("dc_line_item")] = l.GetString("client_invoice")
+		}
+	}
+	return invoiced, nil
+}
+
+// InvoiceableDCLines returns the lines of a DC that have not been invoiced
+// yet, in line order. Official DCs are usually created without pricing, so
+// a line without a rate takes its rate and tax from the transit DC line for
+// the same item in its shipment group.
+func InvoiceableDCLines(app core.App, dc *core.Record) ([]InvoiceableLine, error) {
+	records, err := app.FindRecordsByFilter("dc_line_items", "dc = {:dcId}", "line_order", 0, 0, map[string]any{"dcId": dc.Id})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch DC line items: %w", err)
+	}
+	invoiced, err := InvoicedDCLines(app, []string{dc.Id})
+	if err != nil {
+		return nil, err
+	}
+	transitPricing, err := shipmentGroupPricing(app, dc.GetString("shipment_group"))
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []InvoiceableLine
+	for _, li := range records {
+		if _, ok := invoiced[li.Id]; ok {
+			continue
+		}
+		line := InvoiceableLine{
+			DCID:         dc.Id,
+			DCNumber:     dc.GetString("dc_number"),
+			DCLineItemID: li.Id,
+			Quantity:     li.GetFloat("quantity"),
+			Rate:         li.GetFloat("rate"),
+			TaxPercent:   li.GetFloat("tax_percentage"),
+		}
+		key := li.GetString("source_item_type") + ":" + li.GetString("source_item_id")
+		if line.Rate == 0 {
+			if p, ok := transitPricing[key]; ok {
+				line.Rate = p.GetFloat("rate")
+				line.TaxPercent = p.GetFloat("tax_percentage")
+			}
+		}
+
+		collection := "sub_items"
+		if li.GetString("source_item_type") == "sub_sub_item" {
+			collection = "sub_sub_items"
+		}
+		if source, err := app.FindRecordById(collection, li.GetString("source_item_id")); err == nil {
+			line.Description = source.GetString("description")
+			line.HSNCode = source.GetString("hsn_code")
+			line.UOM = source.GetString("uom")
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+// shipmentGroupPricing returns the priced transit DC lines of a shipment
+// group, keyed by source item type and ID.
+func shipmentGroupPricing(app core.App, sgID string) (map[string]*core.Record, error) {
+	pricing := make(map[string]*core.Record)
+	if sgID == "" {
+		return pricing, nil
+	}
+	lines, err := app.FindRecordsByFilter("dc_line_items",
+		"dc.shipment_group = {:sgId} && dc.dc_type = 'transit'",
+		"line_order", 0, 0, map[string]any{"sgId": sgID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch transit DC pricing: %w", err)
+	}
+	for _, li := range lines {
+		if li.GetFloat("rate") == 0 {
+			continue
+		}
+		key := li.GetString("source_item_type") + ":" + li.GetString("source_item_id")
+		if _, ok := pricing[key]; !ok {
+			pricing[key] = li
+		}
+	}
+	return pricing, nil
+}
+
+// dcTaxTerms returns the tax type and reverse charge flag of a DC, taken from
+// its shipment group or, for a DC outside a group, from the states of its
+// bill-from and bill-to addresses.
+func dcTaxTerms(app core.App, dc *core.Record) (string, bool) {
+	if sgID := dc.GetString("shipment_group"); sgID != "" {
+		if sg, err := app.FindRecordById("shipment_groups", sgID); err == nil {
+			return sg.GetString("tax_type"), sg.GetBool("reverse_charge")
+		}
+	}
+	var from, to map[string]string
+	if addr, err := app.FindRecordById("addresses", dc.GetString("bill_from_address")); err == nil {
+		from = ReadAddressData(addr)
+	}
+	if addr, err := app.FindRecordById("addresses", dc.GetString("bill_to_address")); err == nil {
+		to = ReadAddressData(addr)
+	}
+	return DetermineTaxTypeByState(from["state"], from["gstin"], to["state"], to["gstin"]), false
+}
+
+// PrepareClientInvoice checks that the DCs can be billed on one invoice and
+// returns the draft. Every DC must be an issued official DC of the project,
+// and all of them must share the bill-from and bill-to parties and the tax
+// terms. The returned error is suitable to show to the user.
+func PrepareClientInvoice(app core.App, projectID string, dcIDs []string) (*ClientInvoiceDraft, error) {
+	if len(dcIDs) == 0 {
+		return nil, fmt.Errorf("select at least one delivery challan")
+	}
+
+	draft := &ClientInvoiceDraft{}
+	shipTo := ""
+	for i, dcID := range dcIDs {
+		dc, err := app.FindRecordById("delivery_challans", dcID)
+		if err != nil || dc.GetString("project") != projectID {
+			return nil, fmt.Errorf("delivery challan not found")
+		}
+		number := dc.GetString("dc_number")
+		if dc.GetString("dc_type") != "official" {
+			return nil, fmt.Errorf("%s is not an official DC; only official DCs can be invoiced", number)
+		}
+		if !slices.Contains(DCIssuedStatuses, dc.GetString("status")) {
+			return nil, fmt.Errorf("%s is %s; only issued DCs can be invoiced", number, dc.GetString("status"))
+		}
+
+		taxType, reverseCharge := dcTaxTerms(app, dc)
+		if i == 0 {
+			draft.BillFromID = dc.GetString("bill_from_address")
+			draft.BillToID = dc.GetString("bill_to_address")
+			draft.TaxType = taxType
+			draft.ReverseCharge = reverseCharge
+			shipTo = dc.GetString("ship_to_address")
+		} else {
+			switch {
+			case dc.GetString("bill_to_address") != draft.BillToID:
+				return nil, fmt.Errorf("%s is billed to a different party; invoice it separately", number)
+			case dc.GetString("bill_from_address") != draft.BillFromID:
+				return nil, fmt.Errorf("%s is billed from a different address; invoice it separately", number)
+			case taxType != draft.TaxType || reverseCharge != draft.ReverseCharge:
+				return nil, fmt.Errorf("%s has different tax terms; invoice it separately", number)
+			}
+			if dc.GetString("ship_to_address") != shipTo {
+				shipTo = ""
+			}
+		}
+
+		lines, err := InvoiceableDCLines(app, dc)
+		if err != nil {
+			return nil, err
+		}
+		draft.Lines = append(draft.Lines, lines...)
+	}
+	draft.ShipToID = shipTo
+
+	if len(draft.Lines) == 0 {
+		return nil, fmt.Errorf("every line on the selected DCs has already been invoiced")
+	}
+	for _, l := range draft.Lines {
+		if l.Rate <= 0 {
+			return nil, fmt.Errorf("%s: %s has no rate; price it on the transit DC first", l.DCNumber, l.Description)
+		}
+	}
+	return draft, nil
+}
+
+// CreateClientInvoice raises a tax invoice for the lines of the given DCs
+// that have not been invoiced yet. The invoice number comes from the
+// project's "inv" sequence.
+func CreateClientInvoice(ctx context.Context, app *pocketbase.PocketBase, params ClientInvoiceParams) (*ClientInvoiceResult, error) {
+	dcIDs := slices.Compact(slices.Sorted(slices.Values(params.DCIDs)))
+	draft, err := PrepareClientInvoice(app, params.ProjectID, dcIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	docDate, err := time.Parse("2006-01-02", params.InvoiceDate)
+	if err != nil {
+		docDate = time.Now()
+	}
+	invoiceNumber, err := NextDocNumber(app, params.ProjectID, "inv", docDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate invoice number: %w", err)
+	}
+
+	invoiceCol, err := app.FindCollectionByNameOrId("client_invoices")
+	if err != nil {
+		return nil, fmt.Errorf("client_invoices collection not found: %w", err)
+	}
+	lineCol, err := app.FindCollectionByNameOrId("client_invoice_line_items")
+	if err != nil {
+		return nil, fmt.Errorf("client_invoice_line_items collection not found: %w", err)
+	}
+
+	totals := draft.Totals()
+	result := &ClientInvoiceResult{
+		InvoiceNumber: invoiceNumber,
+		LineCount:     len(draft.Lines),
+		GrandTotal:    totals.GrandTotal,
+	}
+	err = app.RunInTransaction(func(txApp core.App) error {
+		invoice := core.NewRecord(invoiceCol)
+		invoice.Set("project", params.ProjectID)
+		invoice.Set("invoice_number", invoiceNumber)
+		invoice.Set("invoice_date", params.InvoiceDate)
+		invoice.Set("bill_from_address", draft.BillFromID)
+		invoice.Set("bill_to_address", draft.BillToID)
+		invoice.Set("ship_to_address", draft.ShipToID)
+		invoice.Set("tax_type", totals.TaxType)
+		invoice.Set("reverse_charge", draft.ReverseCharge)
+		invoice.Set("taxable_amount", totals.TotalBeforeTax)
+		invoice.Set("tax_amount", totals.IGSTAmount+totals.CGSTAmount+totals.SGSTAmount)
+		invoice.Set("round_off", totals.RoundOff)
+		invoice.Set("total_amount", totals.GrandTotal)
+		invoice.Set("retention_amount", RetentionAmount(txApp, params.ProjectID, totals.GrandTotal))
+		invoice.Set("remarks", strings.TrimSpace(params.Remarks))
+		if err := txApp.SaveWithContext(ctx, invoice); err != nil {
+			return fmt.Errorf("failed to create client invoice: %w", err)
+		}
+		result.InvoiceID = invoice.Id
+
+		for i, l := range draft.Lines {
+			calc := CalcPOLineItem(l.Rate, l.Quantity, l.TaxPercent)
+			rec := core.NewRecord(lineCol)
+			rec.Set("client_invoice", invoice.Id)
+			rec.Set("dc", l.DCID)
+			rec.Set("dc_line_item", l.DCLineItemID)
+			rec.Set("description", l.Description)
+			rec.Set("hsn_code", l.HSNCode)
+			rec.Set("uom", l.UOM)
+			rec.Set("quantity", l.Quantity)
+			rec.Set("rate", l.Rate)
+			rec.Set("tax_percentage", l.TaxPercent)
+			rec.Set("taxable_amount", calc.BeforeGST)
+			rec.Set("tax_amount", calc.GSTAmount)
+			rec.Set("total_amount", calc.Total)
+			rec.Set("line_order", i+1)
+			// The unique index on dc_line_item rejects a line invoiced
+			// concurrently by someone else
+			if err := txApp.SaveWithContext(ctx, rec); err != nil {
+				return fmt.Errorf("failed to add %s to the invoice: %w", l.DCNumber, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,178 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"slices"
+
+	"github.com/pocketbase/pocketbase"
+
+	"projectcreation/collections"
+)
+
+// ClientInvoiceExportData holds all data needed to generate a tax invoice PDF.
+type ClientInvoiceExportData struct {
+	// Company branding (from app_settings)
+	CompanyName  string
+	LogoBytes    []byte
+	LogoFilename string
+
+	// Invoice header
+	InvoiceNumber string
+	InvoiceDate   string
+	PlaceOfSupply string
+	ReverseCharge bool
+	DCNumbers     []string // the DCs billed, in order of first line
+	Milestone     string   // the billing milestone, for milestone invoices
+
+	// Parties
+	Supplier *DCExportAddress
+	Buyer    *DCExportAddress
+	ShipTo   *DCExportAddress
+
+	// Line items and totals
+	LineItems     []ClientInvoiceExportLineItem
+	Totals        POTotals
+	AmountInWords string
+	Retention     float64 // held back by the client until handover
+	Remarks       string
+}
+
+// ClientInvoiceExportLineItem holds a single invoice line for PDF export.
+type ClientInvoiceExportLineItem struct {
+	SINo        int
+	Description string
+	HSNCode     string
+	UOM         string
+	DCNumber    string
+	Qty         float64
+	Rate        float64
+	TaxPercent  float64
+	Taxable     float64
+	TaxAmount   float64
+	Total       float64
+}
+
+// BuildClientInvoiceExportData assembles all data needed for PDF generation
+// from PocketBase records.
+func BuildClientInvoiceExportData(app *pocketbase.PocketBase, invoiceID string) (*ClientInvoiceExportData, error) {
+	invoice, err := app.FindRecordById("client_invoices", invoiceID)
+	if err != nil {
+		return nil, fmt.Errorf("client invoice not found: %w", err)
+	}
+
+	data := &ClientInvoiceExportData{
+		InvoiceNumber: invoice.GetString("invoice_number"),
+		InvoiceDate:   invoice.GetString("invoice_date"),
+		ReverseCharge: invoice.GetBool("reverse_charge"),
+		Retention:     invoice.GetFloat("retention_amount"),
+		Remarks:       invoice.GetString("remarks"),
+	}
+
+	var buyerState, buyerGSTIN string
+	data.Supplier, _, _ = clientInvoiceAddress(app, invoice.GetString("bill_from_address"))
+	data.Buyer, buyerState, buyerGSTIN = clientInvoiceAddress(app, invoice.GetString("bill_to_address"))
+	data.ShipTo, _, _ = clientInvoiceAddress(app, invoice.GetString("ship_to_address"))
+	data.PlaceOfSupply = PlaceOfSupply(buyerState, buyerGSTIN)
+
+	var calcs []POLineItemCalc
+	if milestoneID := invoice.GetString("billing_milestone"); milestoneID != "" {
+		// A milestone invoice has a single lump-sum line held on the invoice
+		if m, err := app.FindRecordById("billing_milestones", milestoneID); err == nil {
+			data.Milestone = m.GetString("name")
+		}
+		calc := CalcPOLineItem(invoice.GetFloat("taxable_amount"), 1, invoice.GetFloat("milestone_tax_percentage"))
+		calcs = []POLineItemCalc{calc}
+		data.LineItems = []ClientInvoiceExportLineItem{{
+			SINo:        1,
+			Description: invoice.GetString("milestone_description"),
+			HSNCode:     invoice.GetString("sac_code"),
+			UOM:         "LS",
+			Qty:         calc.Qty,
+			Rate:        calc.Rate,
+			TaxPercent:  calc.GSTPercent,
+			Taxable:     calc.BeforeGST,
+			TaxAmount:   calc.GSTAmount,
+			Total:       calc.Total,
+		}}
+	} else {
+		calcs = addClientInvoiceDCLines(app, invoiceID, data)
+	}
+
+	data.Totals = CalcPOTotalsForTaxType(calcs, invoice.GetString("tax_type"))
+	data.AmountInWords = AmountToWords(data.Totals.GrandTotal)
+
+	data.CompanyName = collections.GetCompanyName(app)
+	data.LogoBytes, data.LogoFilename, _ = collections.GetLogoBytes(app)
+
+	return data, nil
+}
+
+// addClientInvoiceDCLines adds the DC lines billed on an invoice to data and
+// returns their pricing.
+func addClientInvoiceDCLines(app *pocketbase.PocketBase, invoiceID string, data *ClientInvoiceExportData) []POLineItemCalc {
+	lineRecords, err := app.FindRecordsByFilter("client_invoice_line_items", "client_invoice = {:invId}", "line_order", 0, 0, map[string]any{"invId": invoiceID})
+	if err != nil {
+		log.Printf("client_invoice_export: could not fetch line items for invoice %s: %v", invoiceID, err)
+		lineRecords = nil
+	}
+
+	dcNumbers := make(map[string]string)
+	var calcs []POLineItemCalc
+	for i, li := range lineRecords {
+		dcID := li.GetString("dc")
+		if _, ok := dcNumbers[dcID]; !ok {
+			if dc, err := app.FindRecordById("delivery_challans", dcID); err == nil {
+				dcNumbers[dcID] = dc.GetString("dc_number")
+			} else {
+				dcNumbers[dcID] = ""
+			}
+			if n := dcNumbers[dcID]; n != "" && !slices.Contains(data.DCNumbers, n) {
+				data.DCNumbers = append(data.DCNumbers, n)
+			}
+		}
+
+		calc := CalcPOLineItem(li.GetFloat("rate"), li.GetFloat("quantity"), li.GetFloat("tax_percentage"))
+		calcs = append(calcs, calc)
+		data.LineItems = append(data.LineItems, ClientInvoiceExportLineItem{
+			SINo:        i + 1,
+			Description: li.GetString("description"),
+			HSNCode:     li.GetString("hsn_code"),
+			UOM:         li.GetString("uom"),
+			DCNumber:    dcNumbers[dcID],
+			Qty:         calc.Qty,
+			Rate:        calc.Rate,
+			TaxPercent:  calc.GSTPercent,
+			Taxable:     calc.BeforeGST,
+			TaxAmount:   calc.GSTAmount,
+			Total:       calc.Total,
+		})
+	}
+	return calcs
+}
+
+// clientInvoiceAddress resolves an address for the invoice, along with the
+// state and GSTIN used to work out the place of supply.
+func clientInvoiceAddress(app *pocketbase.PocketBase, addressID string) (*DCExportAddress, string, string) {
+	if addressID == "" {
+		return nil, "", ""
+	}
+	rec, err := app.FindRecordById("addresses", addressID)
+	if err != nil {
+		log.Printf("client_invoice_export: could not find address %s: %v", addressID, err)
+		return nil, "", ""
+	}
+	addr := ReadAddressData(rec)
+
+	cityStatePin := joinNonEmpty([]string{addr["city"], addr["state"]}, ", ")
+	if addr["pin_code"] != "" {
+		cityStatePin = joinNonEmpty([]string{cityStatePin, addr["pin_code"]}, " - ")
+	}
+	return &DCExportAddress{
+		CompanyName:   addr["company_name"],
+		AddressLines:  joinNonEmpty([]string{addr["address_line_1"], addr["address_line_2"], cityStatePin}, ", "),
+		ContactPerson: addr["contact_person"],
+		Phone:         addr["phone"],
+		GSTIN:         addr["gstin"],
+	}, addr["state"], addr["gstin"]
+}
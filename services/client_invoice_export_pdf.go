@@ -0,0 +1,368 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/johnfercher/maroto/v2"
+	"github.com/johnfercher/maroto/v2/pkg/components/col"
+	"github.com/johnfercher/maroto/v2/pkg/components/image"
+	"github.com/johnfercher/maroto/v2/pkg/components/row"
+	"github.com/johnfercher/maroto/v2/pkg/components/text"
+	"github.com/johnfercher/maroto/v2/pkg/config"
+	"github.com/johnfercher/maroto/v2/pkg/consts/align"
+	"github.com/johnfercher/maroto/v2/pkg/consts/fontstyle"
+	"github.com/johnfercher/maroto/v2/pkg/consts/orientation"
+	"github.com/johnfercher/maroto/v2/pkg/consts/pagesize"
+	"github.com/johnfercher/maroto/v2/pkg/core"
+	"github.com/johnfercher/maroto/v2/pkg/props"
+)
+
+// GenerateClientInvoicePDF creates a GST tax invoice PDF using maroto/v2.
+// It returns the raw PDF bytes or an error.
+func GenerateClientInvoicePDF(data *ClientInvoiceExportData) ([]byte, error) {
+	cfg := config.NewBuilder().
+		WithOrientation(orientation.Vertical).
+		WithPageSize(pagesize.A4).
+		WithLeftMargin(10).
+		WithTopMargin(10).
+		WithRightMargin(10).
+		WithPageNumber(props.PageNumber{
+			Pattern: "Page {current} of {total}",
+			Place:   props.RightBottom,
+			Size:    7,
+			Color:   &props.Color{Red: 120, Green: 120, Blue: 120},
+		}).
+		Build()
+
+	m := maroto.New(cfg)
+
+	addInvoiceHeader(m, data)
+	addInvoiceParties(m, data)
+	addInvoiceLineItems(m, data)
+	addInvoiceTotals(m, data)
+	addInvoiceAmountInWords(m, data)
+	addInvoiceRemarks(m, data)
+	addInvoiceSignatures(m, data)
+
+	doc, err := m.Generate()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate invoice PDF: %w", err)
+	}
+
+	return doc.GetBytes(), nil
+}
+
+// addInvoiceHeader adds company logo/name, the "TAX INVOICE" title, and the
+// invoice number, date, place of supply and reverse charge.
+func addInvoiceHeader(m core.Maroto, data *ClientInvoiceExportData) {
+	titleStyle := props.Text{
+		Size:  14,
+		Style: fontstyle.Bold,
+		Align: align.Right,
+		Color: &props.Color{Red: 33, Green: 37, Blue: 41},
+	}
+
+	if len(data.LogoBytes) > 0 {
+		titleStyle.Top = 2
+		m.AddRows(
+			row.New(14).Add(
+				col.New(3).Add(
+					image.NewFromBytes(data.LogoBytes, logoExtension(data.LogoFilename), props.Rect{
+						Percent: 80,
+						Center:  false,
+					}),
+				),
+				col.New(3).Add(
+					text.New(data.CompanyName, props.Text{
+						Size:  11,
+						Style: fontstyle.Bold,
+						Align: align.Left,
+						Top:   3,
+					}),
+				),
+				col.New(6).Add(text.New("TAX INVOICE", titleStyle)),
+			),
+		)
+	} else {
+		m.AddRows(
+			row.New(10).Add(
+				col.New(6).Add(
+					text.New(data.CompanyName, props.Text{
+						Size:  14,
+						Style: fontstyle.Bold,
+						Align: align.Left,
+					}),
+				),
+				col.New(6).Add(text.New("TAX INVOICE", titleStyle)),
+			),
+		)
+	}
+
+	greyStyle := props.Text{
+		Size:  9,
+		Align: align.Right,
+		Color: &props.Color{Red: 100, Green: 100, Blue: 100},
+	}
+	reverseCharge := "No"
+	if data.ReverseCharge {
+		reverseCharge = "Yes"
+	}
+
+	m.AddRows(
+		row.New(7).Add(
+			col.New(4).Add(
+				text.New(fmt.Sprintf("Invoice #: %s", data.InvoiceNumber), props.Text{
+					Size:  10,
+					Style: fontstyle.Bold,
+					Align: align.Left,
+				}),
+			),
+			col.New(4).Add(text.New(fmt.Sprintf("Date: %s", data.InvoiceDate), props.Text{
+				Size:  9,
+				Align: align.Center,
+				Color: &props.Color{Red: 100, Green: 100, Blue: 100},
+			})),
+			col.New(4).Add(text.New(fmt.Sprintf("Reverse Charge: %s", reverseCharge), greyStyle)),
+		),
+	)
+
+	against := fmtField("Against DC", strings.Join(data.DCNumbers, ", "))
+	if data.Milestone != "" {
+		against = fmtField("Milestone", data.Milestone)
+	}
+	m.AddRows(
+		row.New(7).Add(
+			col.New(8).Add(text.New(against, props.Text{
+				Size:  8,
+				Align: align.Left,
+			})),
+			col.New(4).Add(text.New(fmtField("Place of Supply", data.PlaceOfSupply), greyStyle)),
+		),
+	)
+
+	m.AddRows(row.New(3))
+}
+
+// addInvoiceParties adds the supplier and buyer blocks, and the consignee
+// when the goods were shipped to a single address.
+func addInvoiceParties(m core.Maroto, data *ClientInvoiceExportData) {
+	sectionLabel := props.Text{
+		Size:  7,
+		Style: fontstyle.Bold,
+		Align: align.Left,
+		Color: &props.Color{Red: 100, Green: 100, Blue: 100},
+	}
+	headerBg := &props.Color{Red: 245, Green: 243, Blue: 239}
+	headerCell := &props.Cell{BackgroundColor: headerBg}
+
+	m.AddRows(
+		row.New(7).Add(
+			col.New(6).Add(text.New("SUPPLIER", sectionLabel)).WithStyle(headerCell),
+			col.New(6).Add(text.New("BUYER (BILL TO)", sectionLabel)).WithStyle(headerCell),
+		),
+	)
+	addDCAddressPair(m, data.Supplier, data.Buyer)
+
+	if data.ShipTo != nil {
+		m.AddRows(
+			row.New(7).Add(
+				col.New(6).Add(text.New("CONSIGNEE (SHIP TO)", sectionLabel)).WithStyle(headerCell),
+				col.New(6).WithStyle(headerCell),
+			),
+		)
+		addDCAddressPair(m, data.ShipTo, nil)
+	}
+
+	m.AddRows(row.New(3))
+}
+
+// addInvoiceLineItems adds the invoice lines table with header and body rows.
+func addInvoiceLineItems(m core.Maroto, data *ClientInvoiceExportData) {
+	headerBg := &props.Color{Red: 33, Green: 37, Blue: 41}
+	headerText := props.Text{
+		Size:  7,
+		Style: fontstyle.Bold,
+		Align: align.Center,
+		Color: &props.Color{Red: 255, Green: 255, Blue: 255},
+	}
+	headerTextLeft := headerText
+	headerTextLeft.Align = align.Left
+	headerCell := props.Cell{BackgroundColor: headerBg}
+
+	m.AddRows(
+		row.New(8).Add(
+			col.New(1).Add(text.New("SI No", headerText)).WithStyle(&headerCell),
+			col.New(3).Add(text.New("Description", headerTextLeft)).WithStyle(&headerCell),
+			col.New(1).Add(text.New("HSN", headerText)).WithStyle(&headerCell),
+			col.New(1).Add(text.New("Qty", headerText)).WithStyle(&headerCell),
+			col.New(1).Add(text.New("UoM", headerText)).WithStyle(&headerCell),
+			col.New(1).Add(text.New("Rate", headerText)).WithStyle(&headerCell),
+			col.New(1).Add(text.New("Taxable", headerText)).WithStyle(&headerCell),
+			col.New(1).Add(text.New("GST%", headerText)).WithStyle(&headerCell),
+			col.New(1).Add(text.New("GST Amt", headerText)).WithStyle(&headerCell),
+			col.New(1).Add(text.New("Total", headerText)).WithStyle(&headerCell),
+		),
+	)
+
+	altBg := &props.Color{Red: 248, Green: 249, Blue: 250}
+
+	for i, item := range data.LineItems {
+		bodyText := props.Text{Size: 7, Align: align.Center}
+		bodyTextLeft := props.Text{Size: 7, Align: align.Left}
+		bodyTextRight := props.Text{Size: 7, Align: align.Right}
+
+		description := item.Description
+		if len(data.DCNumbers) > 1 && item.DCNumber != "" {
+			description = fmt.Sprintf("%s (%s)", item.Description, item.DCNumber)
+		}
+
+		cols := []core.Col{
+			col.New(1).Add(text.New(fmt.Sprintf("%d", item.SINo), bodyText)),
+			col.New(3).Add(text.New(description, bodyTextLeft)),
+			col.New(1).Add(text.New(item.HSNCode, bodyText)),
+			col.New(1).Add(text.New(formatQty(item.Qty), bodyTextRight)),
+			col.New(1).Add(text.New(item.UOM, bodyText)),
+			col.New(1).Add(text.New(FormatINR(item.Rate), bodyTextRight)),
+			col.New(1).Add(text.New(FormatINR(item.Taxable), bodyTextRight)),
+			col.New(1).Add(text.New(formatGSTRate(item.TaxPercent)+"%", bodyText)),
+			col.New(1).Add(text.New(FormatINR(item.TaxAmount), bodyTextRight)),
+			col.New(1).Add(text.New(FormatINR(item.Total), bodyTextRight)),
+		}
+		if i%2 == 1 {
+			for j := range cols {
+				cols[j] = cols[j].WithStyle(&props.Cell{BackgroundColor: altBg})
+			}
+		}
+
+		m.AddRows(row.New(7).Add(cols...))
+	}
+
+	m.AddRows(row.New(2))
+}
+
+// addInvoiceTotals adds the taxable value, CGST/SGST or IGST per rate,
+// round off and grand total.
+func addInvoiceTotals(m core.Maroto, data *ClientInvoiceExportData) {
+	summaryBg := &props.Color{Red: 245, Green: 245, Blue: 245}
+	summaryCell := &props.Cell{BackgroundColor: summaryBg}
+
+	labelStyle := props.Text{Size: 8, Style: fontstyle.Bold, Align: align.Right}
+	valueStyle := props.Text{Size: 8, Align: align.Right}
+
+	m.AddRows(row.New(7).Add(
+		col.New(9).Add(text.New("Taxable Value", labelStyle)).WithStyle(summaryCell),
+		col.New(3).Add(text.New(FormatINR(data.Totals.TotalBeforeTax), valueStyle)).WithStyle(summaryCell),
+	))
+
+	for _, line := range data.Totals.TaxLines() {
+		m.AddRows(row.New(7).Add(
+			col.New(9).Add(text.New(line.Label, labelStyle)).WithStyle(summaryCell),
+			col.New(3).Add(text.New(FormatINR(line.Amount), valueStyle)).WithStyle(summaryCell),
+		))
+	}
+
+	m.AddRows(row.New(7).Add(
+		col.New(9).Add(text.New("Round Off", labelStyle)).WithStyle(summaryCell),
+		col.New(3).Add(text.New(FormatINR(data.Totals.RoundOff), valueStyle)).WithStyle(summaryCell),
+	))
+
+	grandBg := &props.Color{Red: 33, Green: 37, Blue: 41}
+	grandCell := &props.Cell{BackgroundColor: grandBg}
+	grandStyle := props.Text{Size: 9, Style: fontstyle.Bold, Align: align.Right, Color: &props.Color{Red: 255, Green: 255, Blue: 255}}
+
+	m.AddRows(row.New(8).Add(
+		col.New(9).Add(text.New("Invoice Total", grandStyle)).WithStyle(grandCell),
+		col.New(3).Add(text.New(FormatINR(data.Totals.GrandTotal), grandStyle)).WithStyle(grandCell),
+	))
+
+	m.AddRows(row.New(3))
+}
+
+// addInvoiceAmountInWords adds the amount in words and, for reverse charge
+// supplies, the note that tax is payable by the recipient.
+func addInvoiceAmountInWords(m core.Maroto, data *ClientInvoiceExportData) {
+	m.AddRows(
+		row.New(8).Add(
+			col.New(12).Add(
+				text.New(fmt.Sprintf("Amount in Words: %s", data.AmountInWords), props.Text{
+					Size:  8,
+					Style: fontstyle.BoldItalic,
+					Align: align.Left,
+				}),
+			),
+		),
+	)
+
+	if data.ReverseCharge {
+		m.AddRows(
+			row.New(7).Add(
+				col.New(12).Add(text.New("Tax on this supply is payable by the recipient under reverse charge.", props.Text{
+					Size:  8,
+					Align: align.Left,
+				})),
+			),
+		)
+	}
+
+	m.AddRows(row.New(3))
+}
+
+// addInvoiceRemarks adds the remarks section if non-empty.
+func addInvoiceRemarks(m core.Maroto, data *ClientInvoiceExportData) {
+	if data.Remarks == "" {
+		return
+	}
+
+	m.AddRows(
+		row.New(6).Add(
+			col.New(12).Add(text.New("REMARKS", props.Text{
+				Size:  7,
+				Style: fontstyle.Bold,
+				Align: align.Left,
+				Color: &props.Color{Red: 100, Green: 100, Blue: 100},
+			})),
+		),
+	)
+	m.AddRows(
+		row.New(7).Add(
+			col.New(12).Add(text.New(data.Remarks, props.Text{
+				Size:  8,
+				Align: align.Left,
+			})),
+		),
+	)
+
+	m.AddRows(row.New(3))
+}
+
+// addInvoiceSignatures adds the receiver and authorised signatory lines.
+func addInvoiceSignatures(m core.Maroto, data *ClientInvoiceExportData) {
+	m.AddRows(row.New(10))
+
+	lineStyle := props.Text{
+		Size:  8,
+		Align: align.Center,
+		Color: &props.Color{Red: 100, Green: 100, Blue: 100},
+	}
+	labelStyle := props.Text{
+		Size:  7,
+		Style: fontstyle.Bold,
+		Align: align.Center,
+		Color: &props.Color{Red: 100, Green: 100, Blue: 100},
+	}
+
+	signatory := "Authorized Signatory"
+	if data.CompanyName != "" {
+		signatory = fmt.Sprintf("For %s — Authorized Signatory", data.CompanyName)
+	}
+
+	m.AddRows(row.New(6).Add(
+		col.New(6).Add(text.New("____________________________", lineStyle)),
+		col.New(6).Add(text.New("____________________________", lineStyle)),
+	))
+	m.AddRows(row.New(7).Add(
+		col.New(6).Add(text.New("Receiver Signature", labelStyle)),
+		col.New(6).Add(text.New(signatory, labelStyle)),
+	))
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/testhelpers"
+)
+
+// setupInvoiceTest creates an issued shipment group whose transit DC prices
+// 10 Mtrs of cable at ₹200 + 18% GST and whose official DC carries the same
+// line unpriced, as the DC wizard creates it.
+func setupInvoiceTest(t *testing.T) (app *pocketbase.PocketBase, project, sg, transit, official *core.Record) {
+	t.Helper()
+	app = testhelpers.NewTestApp(t)
+	project = testhelpers.CreateTestProject(t, app, "Invoice Project")
+	boq := testhelpers.CreateTestBOQ(t, app, project.Id, "Invoice BOQ")
+	mainItem := testhelpers.CreateTestMainBOQItem(t, app, boq.Id, "Cabling")
+	cable := testhelpers.CreateTestSubItem(t, app, mainItem.Id, "Armoured cable")
+	billFrom := testhelpers.CreateTestAddress(t, app, project.Id, "bill_from", "Our Company")
+	billTo := testhelpers.CreateTestAddress(t, app, project.Id, "bill_to", "Client Ltd")
+
+	sg = saveTestRecord(t, app, "shipment_groups", map[string]any{
+		"project": project.Id, "num_locations": 1, "tax_type": "cgst_sgst", "status": "issued",
+	})
+	transit = testhelpers.CreateTestDeliveryChallan(t, app, project.Id, "TDC-INV", "transit", "issued")
+	official = testhelpers.CreateTestDeliveryChallan(t, app, project.Id, "ODC-INV", "official", "issued")
+	for _, dc := range []*core.Record{transit, official} {
+		dc.Set("shipment_group", sg.Id)
+		dc.Set("bill_from_address", billFrom.Id)
+		dc.Set("bill_to_address", billTo.Id)
+		if err := app.Save(dc); err != nil {
+			t.Fatalf("failed to update DC: %v", err)
+		}
+	}
+	saveTestRecord(t, app, "dc_line_items", map[string]any{
+		"dc": transit.Id, "source_item_type": "sub_item", "source_item_id": cable.Id, "quantity": 10,
+		"rate": 200, "tax_percentage": 18, "taxable_amount": 2000, "tax_amount": 360, "total_amount": 2360, "line_order": 1,
+	})
+	saveTestRecord(t, app, "dc_line_items", map[string]any{
+		"dc": official.Id, "source_item_type": "sub_item", "source_item_id": cable.Id, "quantity": 10, "line_order": 1,
+	})
+	return app, project, sg, transit, official
+}
+
+func TestCreateClientInvoice_PricesFromTransitDC(t *testing.T) {
+	app, project, _, _, official := setupInvoiceTest(t)
+
+	result, err := CreateClientInvoice(context.Background(), app, ClientInvoiceParams{
+		ProjectID:   project.Id,
+		InvoiceDate: "2026-03-15",
+		DCIDs:       []string{official.Id},
+	})
+	if err != nil {
+		t.Fatalf("CreateClientInvoice failed: %v", err)
+	}
+	if !strings.Contains(result.InvoiceNumber, "INV-2526") {
+		t.Errorf("expected an INV number for FY 2526, got %q", result.InvoiceNumber)
+	}
+	if result.LineCount != 1 || result.GrandTotal != 2360 {
+		t.Errorf("expected 1 line totalling 2360, got %d lines totalling %v", result.LineCount, result.GrandTotal)
+	}
+
+	invoice, err := app.FindRecordById("client_invoices", result.InvoiceID)
+	if err != nil {
+		t.Fatalf("invoice not found: %v", err)
+	}
+	if invoice.GetString("tax_type") != TaxTypeCGSTSGST || invoice.GetFloat("tax_amount") != 360 {
+		t.Errorf("expected CGST+SGST of 360, got %s %v", invoice.GetString("tax_type"), invoice.GetFloat("tax_amount"))
+	}
+
+	lines, _ := app.FindRecordsByFilter("client_invoice_line_items", "client_invoice = {:id}", "", 0, 0, map[string]any{"id": invoice.Id})
+	if len(lines) != 1 {
+		t.Fatalf("expected 1 invoice line, got %d", len(lines))
+	}
+	if lines[0].GetFloat("rate") != 200 || lines[0].GetString("hsn_code") != "8504" || lines[0].GetString("description") != "Armoured cable" {
+		t.Errorf("unexpected invoice line: rate %v, HSN %q, description %q",
+			lines[0].GetFloat("rate"), lines[0].GetString("hsn_code"), lines[0].GetString("description"))
+	}
+}
+
+func TestCreateClientInvoice_LinesInvoicedOnce(t *testing.T) {
+	app, project, _, _, official := setupInvoiceTest(t)
+	params := ClientInvoiceParams{ProjectID: project.Id, InvoiceDate: "2026-03-15", DCIDs: []string{official.Id}}
+
+	if _, err := CreateClientInvoice(context.Background(), app, params); err != nil {
+		t.Fatalf("first invoice failed: %v", err)
+	}
+	_, err := CreateClientInvoice(context.Background(), app, params)
+	if err == nil || !strings.Contains(err.Error(), "already been invoiced") {
+		t.Fatalf("expected the second invoice to be rejected, got %v", err)
+	}
+
+	invoices, _ := app.FindAllRecords("client_invoices")
+	if len(invoices) != 1 {
+		t.Errorf("expected one invoice, got %d", len(invoices))
+	}
+}
+
+func TestPrepareClientInvoice_RejectsIneligibleDCs(t *testing.T) {
+	app, project, _, transit, official := setupInvoiceTest(t)
+	draft := testhelpers.CreateTestDeliveryChallan(t, app, project.Id, "ODC-DRAFT", "official", "draft")
+	other := testhelpers.CreateTestDeliveryChallan(t, app, project.Id, "ODC-OTHER", "official", "issued")
+
+	tests := []struct {
+		name  string
+		dcIDs []string
+		want  string
+	}{
+		{"none", nil, "at least one"},
+		{"transit", []string{transit.Id}, "not an official DC"},
+		{"draft", []string{draft.Id}, "only issued DCs"},
+		{"different_buyer", []string{official.Id, other.Id}, "different party"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := PrepareClientInvoice(app, project.Id, tt.dcIDs)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestCancelDC_BlockedOnceInvoiced(t *testing.T) {
+	app, project, _, transit, official := setupInvoiceTest(t)
+	result, err := CreateClientInvoice(context.Background(), app, ClientInvoiceParams{
+		ProjectID: project.Id, InvoiceDate: "2026-03-15", DCIDs: []string{official.Id},
+	})
+	if err != nil {
+		t.Fatalf("CreateClientInvoice failed: %v", err)
+	}
+
+	err = CancelDC(context.Background(), app, transit.Id, "Wrong site")
+	if err == nil || !strings.Contains(err.Error(), result.InvoiceNumber) {
+		t.Fatalf("expected cancellation to be blocked by %s, got %v", result.InvoiceNumber, err)
+	}
+	transit, _ = app.FindRecordById("delivery_challans", transit.Id)
+	if transit.GetString("status") != "issued" {
+		t.Errorf("expected the transit DC to stay issued, got %s", transit.GetString("status"))
+	}
+}
+
+func TestGenerateClientInvoicePDF(t *testing.T) {
+	app, project, _, _, official := setupInvoiceTest(t)
+	result, err := CreateClientInvoice(context.Background(), app, ClientInvoiceParams{
+		ProjectID: project.Id, InvoiceDate: "2026-03-15", DCIDs: []string{official.Id},
+	})
+	if err != nil {
+		t.Fatalf("CreateClientInvoice failed: %v", err)
+	}
+
+	data, err := BuildClientInvoiceExportData(app, result.InvoiceID)
+	if err != nil {
+		t.Fatalf("BuildClientInvoiceExportData failed: %v", err)
+	}
+	if data.PlaceOfSupply != "27 - Maharashtra" || len(data.DCNumbers) != 1 || data.DCNumbers[0] != "ODC-INV" {
+		t.Errorf("unexpected header: place of supply %q, DCs %v", data.PlaceOfSupply, data.DCNumbers)
+	}
+	if lines := data.Totals.TaxLines(); len(lines) != 2 || lines[0].Label != "CGST @ 9%" {
+		t.Errorf("expected CGST and SGST lines, got %+v", lines)
+	}
+
+	pdf, err := GenerateClientInvoicePDF(data)
+	if err != nil {
+		t.Fatalf("GenerateClientInvoicePDF failed: %v", err)
+	}
+	if !strings.HasPrefix(string(pdf), "%PDF") {
+		t.Error("expected PDF output")
+	}
+}
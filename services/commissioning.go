@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// CommissioningTest is one test carried out before a site is handed over,
+// stored in a commissioning record's test_results.
+type CommissioningTest struct {
+	Name    string `json:"name"`
+	Reading string `json:"reading"`
+	Passed  bool   `json:"passed"`
+}
+
+// CommissioningTests reads the test results of a commissioning record.
+func CommissioningTests(rec *core.Record) []CommissioningTest {
+	var tests []CommissioningTest
+	if err := rec.UnmarshalJSONField("test_results", &tests); err != nil {
+		return nil
+	}
+	return tests
+}
+
+// CommissioningParams holds the inputs for commissioning an install-at site.
+type CommissioningParams struct {
+	ProjectID        string
+	SiteID           string // install-at address
+	CommissionedDate string
+	SignedOffBy      string // client representative
+	Designation      string
+	Remarks          string
+	Tests            []CommissioningTest
+}
+
+// installedWorkOrderSite returns the work order site of an install-at
+// address, or nil when the address is not on a work order.
+func installedWorkOrderSite(app core.App, projectID, siteID string) (*core.Record, error) {
+	onOrder, err := siteWorkOrders(app, projectID)
+	if err != nil {
+		return nil, err
+	}
+	return onOrder[siteID], nil
+}
+
+// ValidateCommissioning checks a site can be commissioned. The site must be
+// installed on a work order and not already commissioned, and every test
+// must pass. Errors are keyed by form field name.
+func ValidateCommissioning(app core.App, params CommissioningParams) map[string]string {
+	errs := make(map[string]string)
+
+	site, err := app.FindRecordById("addresses", params.SiteID)
+	if err != nil || site.GetString("project") != params.ProjectID || site.GetString("address_type") != "install_at" {
+		errs["site"] = "Choose an install-at site of this project"
+		return errs
+	}
+	if existing, err := app.FindFirstRecordByData("commissioning_records", "install_at_address", site.Id); err == nil {
+		errs["site"] = fmt.Sprintf("%s is already commissioned on certificate %s", SiteLabel(site), existing.GetString("certificate_number"))
+		return errs
+	}
+	woSite, err := installedWorkOrderSite(app, params.ProjectID, site.Id)
+	if err != nil {
+		errs["site"] = "Could not check the site's work order"
+		return errs
+	}
+	if woSite == nil || woSite.GetString("status") != SiteStatusInstalled {
+		errs["site"] = fmt.Sprintf("%s has to be installed on a work order before it is commissioned", SiteLabel(site))
+		return errs
+	}
+
+	date, err := time.Parse("2006-01-02", params.CommissionedDate)
+	switch {
+	case err != nil:
+		errs["commissioned_date"] = "Commissioning date is required"
+	case date.After(time.Now()):
+		errs["commissioned_date"] = "Commissioning date cannot be in the future"
+	case params.CommissionedDate < woSite.GetString("installation_date"):
+		errs["commissioned_date"] = "Commissioning date cannot be before the installation date"
+	}
+	if strings.TrimSpace(params.SignedOffBy) == "" {
+		errs["signed_off_by"] = "Enter who signed off for the client"
+	}
+
+	if len(params.Tests) == 0 {
+		errs["tests"] = "Record at least one test result"
+	}
+	for _, t := range params.Tests {
+		switch {
+		case strings.TrimSpace(t.Reading) == "":
+			errs["tests"] = fmt.Sprintf("%s: enter the reading", t.Name)
+		case !t.Passed:
+			errs["tests"] = fmt.Sprintf("%s failed; a site can only be commissioned once every test passes", t.Name)
+		}
+	}
+	return errs
+}
+
+// CreateCommissioning numbers and saves the commissioning record of an
+// install-at site and marks its work order site commissioned.
+func CreateCommissioning(ctx context.Context, app *pocketbase.PocketBase, params CommissioningParams) (*core.Record, error) {
+	if errs := ValidateCommissioning(app, params); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid commissioning: %s", strings.Join(slices.Sorted(maps.Values(errs)), "; "))
+	}
+	woSite, err := installedWorkOrderSite(app, params.ProjectID, params.SiteID)
+	if err != nil {
+		return nil, err
+	}
+
+	docDate, _ := time.Parse("2006-01-02", params.CommissionedDate)
+	number, err := NextDocNumber(app, params.ProjectID, "cc", docDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate certificate number: %w", err)
+	}
+
+	col, err := app.FindCollectionByNameOrId("commissioning_records")
+	if err != nil {
+		return nil, fmt.Errorf("commissioning_records collection not found: %w", err)
+	}
+
+	rec := core.NewRecord(col)
+	err = app.RunInTransaction(func(txApp core.App) error {
+		rec.Set("project", params.ProjectID)
+		rec.Set("install_at_address", params.SiteID)
+		rec.Set("work_order_site", woSite.Id)
+		rec.Set("certificate_number", number)
+		rec.Set("commissioned_date", params.CommissionedDate)
+		rec.Set("test_results", params.Tests)
+		rec.Set("signed_off_by", strings.TrimSpace(params.SignedOffBy))
+		rec.Set("signed_off_designation", strings.TrimSpace(params.Designation))
+		rec.Set("remarks", strings.TrimSpace(params.Remarks))
+		if err := txApp.SaveWithContext(ctx, rec); err != nil {
+			return fmt.Errorf("failed to save commissioning record: %w", err)
+		}
+
+		woSite.Set("status", SiteStatusCommissioned)
+		if err := txApp.SaveWithContext(ctx, woSite); err != nil {
+			return fmt.Errorf("failed to mark work order site commissioned: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/pocketbase/pocketbase"
+
+	"projectcreation/collections"
+)
+
+// CommissioningExportData holds all data needed to generate a commissioning
+// certificate PDF.
+type CommissioningExportData struct {
+	// Company branding (from app_settings)
+	CompanyName  string
+	LogoBytes    []byte
+	LogoFilename string
+
+	// Certificate
+	CertificateNumber string
+	CommissionedDate  string
+
+	// Project and site
+	ProjectName string
+	ClientName  string
+	SiteLabel   string
+	SiteAddress string // address lines, city, district and state on one line
+	District    string
+	Mandal      string
+
+	// Installation
+	WONumber         string
+	InstallerName    string
+	InstallationDate string
+	Scope            []string // work order line descriptions
+	SerialNumbers    []string
+
+	Tests       []CommissioningTest
+	SignedOffBy string
+	Designation string
+	Remarks     string
+}
+
+// BuildCommissioningExportData assembles the certificate of a commissioning
+// record from PocketBase records.
+func BuildCommissioningExportData(app *pocketbase.PocketBase, recordID string) (*CommissioningExportData, error) {
+	rec, err := app.FindRecordById("commissioning_records", recordID)
+	if err != nil {
+		return nil, fmt.Errorf("commissioning record not found: %w", err)
+	}
+
+	data := &CommissioningExportData{
+		CertificateNumber: rec.GetString("certificate_number"),
+		CommissionedDate:  rec.GetString("commissioned_date"),
+		Tests:             CommissioningTests(rec),
+		SignedOffBy:       rec.GetString("signed_off_by"),
+		Designation:       rec.GetString("signed_off_designation"),
+		Remarks:           rec.GetString("remarks"),
+	}
+
+	if project, err := app.FindRecordById("projects", rec.GetString("project")); err == nil {
+		data.ProjectName = project.GetString("name")
+		data.ClientName = project.GetString("client_name")
+	} else {
+		log.Printf("commissioning_export: could not find project for %s: %v", recordID, err)
+	}
+
+	if site, err := app.FindRecordById("addresses", rec.GetString("install_at_address")); err == nil {
+		addr := ReadAddressData(site)
+		data.SiteLabel = SiteLabel(site)
+		data.SiteAddress = joinNonEmpty([]string{addr["address_line_1"], addr["address_line_2"], addr["city"], addr["district"], addr["state"], addr["pin_code"]}, ", ")
+		data.District = site.GetString("district_name")
+		data.Mandal = site.GetString("mandal_name")
+	} else {
+		log.Printf("commissioning_export: could not find site for %s: %v", recordID, err)
+	}
+
+	woSite, err := app.FindRecordById("work_order_sites", rec.GetString("work_order_site"))
+	if err != nil {
+		log.Printf("commissioning_export: could not find work order site for %s: %v", recordID, err)
+	} else {
+		data.InstallationDate = woSite.GetString("installation_date")
+		if wo, err := app.FindRecordById("work_orders", woSite.GetString("work_order")); err == nil {
+			data.WONumber = wo.GetString("wo_number")
+			if installer, err := app.FindRecordById("vendors", wo.GetString("installer")); err == nil {
+				data.InstallerName = installer.GetString("name")
+			}
+			lines, _ := app.FindRecordsByFilter("work_order_lines", "work_order = {:id}", "line_order", 0, 0, map[string]any{"id": wo.Id})
+			for _, l := range lines {
+				data.Scope = append(data.Scope, l.GetString("description"))
+			}
+		}
+		if errs := app.ExpandRecord(woSite, []string{"serial_numbers"}, nil); len(errs) > 0 {
+			log.Printf("commissioning_export: could not expand serials for %s: %v", recordID, errs)
+		}
+		for _, sn := range woSite.ExpandedAll("serial_numbers") {
+			data.SerialNumbers = append(data.SerialNumbers, sn.GetString("serial_number"))
+		}
+	}
+
+	data.CompanyName = collections.GetCompanyName(app)
+	data.LogoBytes, data.LogoFilename, _ = collections.GetLogoBytes(app)
+
+	return data, nil
+}
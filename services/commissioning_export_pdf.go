@@ -0,0 +1,275 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/johnfercher/maroto/v2"
+	"github.com/johnfercher/maroto/v2/pkg/components/col"
+	"github.com/johnfercher/maroto/v2/pkg/components/image"
+	"github.com/johnfercher/maroto/v2/pkg/components/row"
+	"github.com/johnfercher/maroto/v2/pkg/components/text"
+	"github.com/johnfercher/maroto/v2/pkg/config"
+	"github.com/johnfercher/maroto/v2/pkg/consts/align"
+	"github.com/johnfercher/maroto/v2/pkg/consts/fontstyle"
+	"github.com/johnfercher/maroto/v2/pkg/consts/orientation"
+	"github.com/johnfercher/maroto/v2/pkg/consts/pagesize"
+	"github.com/johnfercher/maroto/v2/pkg/core"
+	"github.com/johnfercher/maroto/v2/pkg/props"
+)
+
+// GenerateCommissioningCertificatePDF creates the commissioning certificate
+// of a site using maroto/v2. It returns the raw PDF bytes or an error.
+func GenerateCommissioningCertificatePDF(data *CommissioningExportData) ([]byte, error) {
+	cfg := config.NewBuilder().
+		WithOrientation(orientation.Vertical).
+		WithPageSize(pagesize.A4).
+		WithLeftMargin(15).
+		WithTopMargin(15).
+		WithRightMargin(15).
+		Build()
+
+	m := maroto.New(cfg)
+
+	addCommissioningHeader(m, data)
+	addCommissioningSite(m, data)
+	addCommissioningInstallation(m, data)
+	addCommissioningTests(m, data)
+	addCommissioningStatement(m, data)
+	addCommissioningSignatures(m, data)
+
+	doc, err := m.Generate()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate commissioning certificate PDF: %w", err)
+	}
+
+	return doc.GetBytes(), nil
+}
+
+// commissioningLabelStyle is the style of the small grey section labels.
+var commissioningLabelStyle = props.Text{
+	Size:  7,
+	Style: fontstyle.Bold,
+	Align: align.Left,
+	Color: &props.Color{Red: 100, Green: 100, Blue: 100},
+}
+
+// addCommissioningHeader adds the company logo/name, the certificate title
+// and its number and date.
+func addCommissioningHeader(m core.Maroto, data *CommissioningExportData) {
+	if len(data.LogoBytes) > 0 {
+		m.AddRows(
+			row.New(14).Add(
+				col.New(3).Add(
+					image.NewFromBytes(data.LogoBytes, logoExtension(data.LogoFilename), props.Rect{
+						Percent: 80,
+						Center:  false,
+					}),
+				),
+				col.New(9).Add(
+					text.New(data.CompanyName, props.Text{
+						Size:  12,
+						Style: fontstyle.Bold,
+						Align: align.Right,
+						Top:   3,
+					}),
+				),
+			),
+		)
+	} else {
+		m.AddRows(
+			row.New(10).Add(
+				col.New(12).Add(
+					text.New(data.CompanyName, props.Text{
+						Size:  14,
+						Style: fontstyle.Bold,
+						Align: align.Left,
+					}),
+				),
+			),
+		)
+	}
+
+	m.AddRows(row.New(6))
+	m.AddRows(
+		row.New(12).Add(
+			col.New(12).Add(text.New("COMMISSIONING CERTIFICATE", props.Text{
+				Size:  16,
+				Style: fontstyle.Bold,
+				Align: align.Center,
+				Color: &props.Color{Red: 33, Green: 37, Blue: 41},
+			})),
+		),
+	)
+	m.AddRows(
+		row.New(8).Add(
+			col.New(6).Add(text.New(fmt.Sprintf("Certificate #: %s", data.CertificateNumber), props.Text{
+				Size:  9,
+				Style: fontstyle.Bold,
+				Align: align.Left,
+			})),
+			col.New(6).Add(text.New(fmt.Sprintf("Date of commissioning: %s", data.CommissionedDate), props.Text{
+				Size:  9,
+				Style: fontstyle.Bold,
+				Align: align.Right,
+			})),
+		),
+	)
+	m.AddRows(row.New(4))
+}
+
+// addCommissioningSite adds the project, client and install-at site.
+func addCommissioningSite(m core.Maroto, data *CommissioningExportData) {
+	valueStyle := props.Text{Size: 9, Align: align.Left}
+
+	m.AddRows(
+		row.New(6).Add(
+			col.New(6).Add(text.New("PROJECT", commissioningLabelStyle)),
+			col.New(6).Add(text.New("CLIENT", commissioningLabelStyle)),
+		),
+		row.New(7).Add(
+			col.New(6).Add(text.New(data.ProjectName, valueStyle)),
+			col.New(6).Add(text.New(data.ClientName, valueStyle)),
+		),
+		row.New(6).Add(
+			col.New(12).Add(text.New("INSTALLATION SITE", commissioningLabelStyle)),
+		),
+		row.New(7).Add(
+			col.New(12).Add(text.New(data.SiteLabel, props.Text{Size: 9, Style: fontstyle.Bold, Align: align.Left})),
+		),
+	)
+	if data.SiteAddress != "" {
+		m.AddRows(row.New(7).Add(col.New(12).Add(text.New(data.SiteAddress, valueStyle))))
+	}
+	if area := joinNonEmpty([]string{fmtField("District", data.District), fmtField("Mandal", data.Mandal)}, " | "); area != "" {
+		m.AddRows(row.New(7).Add(col.New(12).Add(text.New(area, valueStyle))))
+	}
+	m.AddRows(row.New(3))
+}
+
+// addCommissioningInstallation adds the work order, installer, scope and
+// installed serial numbers.
+func addCommissioningInstallation(m core.Maroto, data *CommissioningExportData) {
+	valueStyle := props.Text{Size: 9, Align: align.Left}
+
+	m.AddRows(
+		row.New(6).Add(
+			col.New(4).Add(text.New("WORK ORDER", commissioningLabelStyle)),
+			col.New(4).Add(text.New("INSTALLER", commissioningLabelStyle)),
+			col.New(4).Add(text.New("INSTALLED ON", commissioningLabelStyle)),
+		),
+		row.New(7).Add(
+			col.New(4).Add(text.New(data.WONumber, valueStyle)),
+			col.New(4).Add(text.New(data.InstallerName, valueStyle)),
+			col.New(4).Add(text.New(data.InstallationDate, valueStyle)),
+		),
+	)
+	if len(data.Scope) > 0 {
+		m.AddRows(
+			row.New(6).Add(col.New(12).Add(text.New("SCOPE OF WORK", commissioningLabelStyle))),
+			row.New(7).Add(col.New(12).Add(text.New(strings.Join(data.Scope, "; "), valueStyle))),
+		)
+	}
+	if len(data.SerialNumbers) > 0 {
+		m.AddRows(
+			row.New(6).Add(col.New(12).Add(text.New("EQUIPMENT SERIAL NUMBERS", commissioningLabelStyle))),
+			row.New(7).Add(col.New(12).Add(text.New(strings.Join(data.SerialNumbers, ", "), valueStyle))),
+		)
+	}
+	m.AddRows(row.New(4))
+}
+
+// addCommissioningTests adds the table of test results.
+func addCommissioningTests(m core.Maroto, data *CommissioningExportData) {
+	headerBg := &props.Color{Red: 33, Green: 37, Blue: 41}
+	headerText := props.Text{
+		Size:  7,
+		Style: fontstyle.Bold,
+		Align: align.Center,
+		Color: &props.Color{Red: 255, Green: 255, Blue: 255},
+	}
+	headerTextLeft := headerText
+	headerTextLeft.Align = align.Left
+	headerCell := props.Cell{BackgroundColor: headerBg}
+
+	m.AddRows(
+		row.New(8).Add(
+			col.New(1).Add(text.New("SI No", headerText)).WithStyle(&headerCell),
+			col.New(6).Add(text.New("Test", headerTextLeft)).WithStyle(&headerCell),
+			col.New(3).Add(text.New("Reading", headerText)).WithStyle(&headerCell),
+			col.New(2).Add(text.New("Result", headerText)).WithStyle(&headerCell),
+		),
+	)
+
+	altBg := &props.Color{Red: 248, Green: 249, Blue: 250}
+	for i, t := range data.Tests {
+		bodyText := props.Text{Size: 8, Align: align.Center}
+		result := "Pass"
+		if !t.Passed {
+			result = "Fail"
+		}
+		cols := []core.Col{
+			col.New(1).Add(text.New(fmt.Sprintf("%d", i+1), bodyText)),
+			col.New(6).Add(text.New(t.Name, props.Text{Size: 8, Align: align.Left})),
+			col.New(3).Add(text.New(t.Reading, bodyText)),
+			col.New(2).Add(text.New(result, bodyText)),
+		}
+		if i%2 == 1 {
+			for j := range cols {
+				cols[j] = cols[j].WithStyle(&props.Cell{BackgroundColor: altBg})
+			}
+		}
+		m.AddRows(row.New(7).Add(cols...))
+	}
+
+	m.AddRows(row.New(4))
+}
+
+// addCommissioningStatement adds the certifying statement and any remarks.
+func addCommissioningStatement(m core.Maroto, data *CommissioningExportData) {
+	statement := fmt.Sprintf(
+		"This is to certify that the equipment listed above has been installed, tested and commissioned at the site on %s, and has been handed over in working condition.",
+		data.CommissionedDate,
+	)
+	m.AddRows(row.New(12).Add(col.New(12).Add(text.New(statement, props.Text{Size: 9, Align: align.Left}))))
+
+	if data.Remarks != "" {
+		m.AddRows(
+			row.New(6).Add(col.New(12).Add(text.New("REMARKS", commissioningLabelStyle))),
+			row.New(7).Add(col.New(12).Add(text.New(data.Remarks, props.Text{Size: 9, Align: align.Left}))),
+		)
+	}
+}
+
+// addCommissioningSignatures adds the client and contractor sign-off.
+func addCommissioningSignatures(m core.Maroto, data *CommissioningExportData) {
+	m.AddRows(row.New(18))
+
+	lineStyle := props.Text{
+		Size:  8,
+		Align: align.Center,
+		Color: &props.Color{Red: 100, Green: 100, Blue: 100},
+	}
+	labelStyle := props.Text{
+		Size:  7,
+		Style: fontstyle.Bold,
+		Align: align.Center,
+		Color: &props.Color{Red: 100, Green: 100, Blue: 100},
+	}
+	nameStyle := props.Text{Size: 8, Align: align.Center}
+
+	m.AddRows(
+		row.New(6).Add(
+			col.New(6).Add(text.New("____________________", lineStyle)),
+			col.New(6).Add(text.New("____________________", lineStyle)),
+		),
+		row.New(6).Add(
+			col.New(6).Add(text.New(joinNonEmpty([]string{data.SignedOffBy, data.Designation}, ", "), nameStyle)),
+			col.New(6),
+		),
+		row.New(7).Add(
+			col.New(6).Add(text.New("For the Client", labelStyle)),
+			col.New(6).Add(text.New(fmt.Sprintf("For %s", data.CompanyName), labelStyle)),
+		),
+	)
+}
@@ -0,0 +1,200 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// installedSite puts both sites of setupWorkOrderTest on a work order and
+// marks the first one installed today. It returns the work order sites.
+func installedSite(t *testing.T, app *pocketbase.PocketBase, project, service, installer *core.Record, sites []*core.Record) (first, second *core.Record) {
+	t.Helper()
+	ctx := context.Background()
+	wo, err := CreateWorkOrder(ctx, app, WorkOrderParams{
+		ProjectID:   project.Id,
+		InstallerID: installer.Id,
+		WODate:      "2025-06-10",
+		Lines:       []WorkOrderLineParams{{SourceItemType: "sub_item", SourceItemID: service.Id, QtyPerSite: 1, Rate: 1500}},
+		SiteIDs:     []string{sites[0].Id, sites[1].Id},
+	})
+	if err != nil {
+		t.Fatalf("CreateWorkOrder failed: %v", err)
+	}
+	first, _ = app.FindFirstRecordByFilter("work_order_sites", "work_order = {:wo} && install_at_address = {:a}", map[string]any{"wo": wo.Id, "a": sites[0].Id})
+	second, _ = app.FindFirstRecordByFilter("work_order_sites", "work_order = {:wo} && install_at_address = {:a}", map[string]any{"wo": wo.Id, "a": sites[1].Id})
+	if err := UpdateWorkOrderSite(ctx, app, first.Id, SiteProgressParams{Status: SiteStatusInstalled, InstallationDate: time.Now().Format("2006-01-02")}); err != nil {
+		t.Fatalf("UpdateWorkOrderSite failed: %v", err)
+	}
+	return first, second
+}
+
+func TestValidateCommissioning(t *testing.T) {
+	app, project, service, installer, _, sites := setupWorkOrderTest(t)
+	installedSite(t, app, project, service, installer, sites)
+
+	valid := CommissioningParams{
+		ProjectID:        project.Id,
+		SiteID:           sites[0].Id,
+		CommissionedDate: time.Now().Format("2006-01-02"),
+		SignedOffBy:      "R. Rao",
+		Tests:            []CommissioningTest{{Name: "Insulation resistance", Reading: "50 MΩ", Passed: true}},
+	}
+	if errs := ValidateCommissioning(app, valid); len(errs) > 0 {
+		t.Fatalf("expected no errors, got %v", errs)
+	}
+
+	tests := []struct {
+		name  string
+		edit  func(p *CommissioningParams)
+		field string
+	}{
+		{"site not installed", func(p *CommissioningParams) { p.SiteID = sites[1].Id }, "site"},
+		{"before installation", func(p *CommissioningParams) { p.CommissionedDate = "2025-01-01" }, "commissioned_date"},
+		{"future date", func(p *CommissioningParams) {
+			p.CommissionedDate = time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+		}, "commissioned_date"},
+		{"no sign-off", func(p *CommissioningParams) { p.SignedOffBy = " " }, "signed_off_by"},
+		{"no tests", func(p *CommissioningParams) { p.Tests = nil }, "tests"},
+		{"failed test", func(p *CommissioningParams) {
+			p.Tests = []CommissioningTest{{Name: "Discharge", Reading: "2 lps", Passed: false}}
+		}, "tests"},
+		{"missing reading", func(p *CommissioningParams) {
+			p.Tests = []CommissioningTest{{Name: "Discharge", Passed: true}}
+		}, "tests"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := valid
+			tt.edit(&p)
+			if errs := ValidateCommissioning(app, p); errs[tt.field] == "" {
+				t.Errorf("expected an error on %s, got %v", tt.field, errs)
+			}
+		})
+	}
+}
+
+func TestCreateCommissioning_IssuesCertificate(t *testing.T) {
+	app, project, service, installer, _, sites := setupWorkOrderTest(t)
+	first, _ := installedSite(t, app, project, service, installer, sites)
+	ctx := context.Background()
+
+	params := CommissioningParams{
+		ProjectID:        project.Id,
+		SiteID:           sites[0].Id,
+		CommissionedDate: time.Now().Format("2006-01-02"),
+		SignedOffBy:      "R. Rao",
+		Designation:      "Assistant Engineer",
+		Tests: []CommissioningTest{
+			{Name: "Insulation resistance", Reading: "50 MΩ", Passed: true},
+			{Name: "Discharge", Reading: "6 lps", Passed: true},
+		},
+	}
+	rec, err := CreateCommissioning(ctx, app, params)
+	if err != nil {
+		t.Fatalf("CreateCommissioning failed: %v", err)
+	}
+	if !strings.Contains(rec.GetString("certificate_number"), "CC") {
+		t.Errorf("expected a CC number, got %q", rec.GetString("certificate_number"))
+	}
+	if tests := CommissioningTests(rec); len(tests) != 2 || tests[1].Reading != "6 lps" {
+		t.Errorf("unexpected test results %v", tests)
+	}
+	first, _ = app.FindRecordById("work_order_sites", first.Id)
+	if first.GetString("status") != SiteStatusCommissioned {
+		t.Errorf("expected the work order site commissioned, got %s", first.GetString("status"))
+	}
+
+	if _, err := CreateCommissioning(ctx, app, params); err == nil {
+		t.Error("expected a second certificate for the site to be rejected")
+	}
+	if err := UpdateWorkOrderSite(ctx, app, first.Id, SiteProgressParams{Status: SiteStatusInstalled, InstallationDate: first.GetString("installation_date")}); err == nil {
+		t.Error("expected a commissioned site to keep its status")
+	}
+
+	data, err := BuildCommissioningExportData(app, rec.Id)
+	if err != nil {
+		t.Fatalf("BuildCommissioningExportData failed: %v", err)
+	}
+	if data.SiteLabel == "" || data.InstallerName != "Surya Installers" || len(data.Scope) != 1 {
+		t.Errorf("unexpected export data %+v", data)
+	}
+	pdf, err := GenerateCommissioningCertificatePDF(data)
+	if err != nil {
+		t.Fatalf("GenerateCommissioningCertificatePDF failed: %v", err)
+	}
+	if !bytes.HasPrefix(pdf, []byte("%PDF")) {
+		t.Error("expected PDF output")
+	}
+}
+
+func TestUpdateWorkOrderSite_CannotMarkCommissioned(t *testing.T) {
+	app, project, service, installer, _, sites := setupWorkOrderTest(t)
+	first, _ := installedSite(t, app, project, service, installer, sites)
+	err := UpdateWorkOrderSite(context.Background(), app, first.Id, SiteProgressParams{Status: SiteStatusCommissioned, InstallationDate: first.GetString("installation_date")})
+	if err == nil {
+		t.Error("expected commissioned to be set only through a commissioning record")
+	}
+}
+
+func TestBuildSiteTracker(t *testing.T) {
+	app, project, service, installer, shipTo, sites := setupWorkOrderTest(t)
+	for i, area := range [][2]string{{"Guntur", "Tenali"}, {"Krishna", "Gudivada"}} {
+		sites[i].Set("district_name", area[0])
+		sites[i].Set("mandal_name", area[1])
+		if err := app.Save(sites[i]); err != nil {
+			t.Fatalf("failed to set the site's district: %v", err)
+		}
+	}
+	_, _, official := issuedShipmentGroup(t, app, project.Id, "001", "PMP-1")
+	official.Set("ship_to_address", shipTo.Id)
+	if err := app.Save(official); err != nil {
+		t.Fatalf("failed to address the DC: %v", err)
+	}
+	installedSite(t, app, project, service, installer, sites)
+	if _, err := CreateCommissioning(context.Background(), app, CommissioningParams{
+		ProjectID:        project.Id,
+		SiteID:           sites[0].Id,
+		CommissionedDate: time.Now().Format("2006-01-02"),
+		SignedOffBy:      "R. Rao",
+		Tests:            []CommissioningTest{{Name: "Discharge", Reading: "6 lps", Passed: true}},
+	}); err != nil {
+		t.Fatalf("CreateCommissioning failed: %v", err)
+	}
+
+	tracker, err := BuildSiteTracker(app, project.Id, "", "")
+	if err != nil {
+		t.Fatalf("BuildSiteTracker failed: %v", err)
+	}
+	if len(tracker.Rows) != 2 || tracker.Dispatched != 2 || tracker.Installed != 1 || tracker.Commissioned != 1 {
+		t.Errorf("unexpected tracker %+v", tracker)
+	}
+	if tracker.Delivered != 0 {
+		t.Errorf("expected no site delivered before proof of delivery, got %d", tracker.Delivered)
+	}
+	if _, err := RecordDelivery(context.Background(), app, DeliveryParams{DCID: official.Id, ReceivedBy: "K. Naidu", ReceivedDate: time.Now().Format("2006-01-02")}, nil); err != nil {
+		t.Fatalf("RecordDelivery failed: %v", err)
+	}
+	if tracker, _ = BuildSiteTracker(app, project.Id, "", ""); tracker.Delivered != 2 {
+		t.Errorf("expected both sites delivered through their ship-to, got %d", tracker.Delivered)
+	}
+	if len(tracker.Districts) != 2 || len(tracker.Mandals) != 2 {
+		t.Errorf("expected 2 districts and 2 mandals, got %v and %v", tracker.Districts, tracker.Mandals)
+	}
+
+	tracker, err = BuildSiteTracker(app, project.Id, "Krishna", "")
+	if err != nil {
+		t.Fatalf("BuildSiteTracker failed: %v", err)
+	}
+	if len(tracker.Rows) != 1 || tracker.Rows[0].SiteID != sites[1].Id || tracker.Rows[0].Installed || tracker.Rows[0].WONumber == "" {
+		t.Errorf("expected only the Krishna site, pending, got %+v", tracker.Rows)
+	}
+	if len(tracker.Mandals) != 1 || tracker.Mandals[0] != "Gudivada" {
+		t.Errorf("expected the mandals of Krishna only, got %v", tracker.Mandals)
+	}
+}
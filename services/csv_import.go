@@ -118,26 +118,12 @@ func ValidateAddressFile(
 	requiredSet := GetRequiredFields(app, projectID, addressType)
 
 	// 3. Parse file based on extension
-	var headers []string
-	var dataRows [][]string
-	var err error
-
-	lowerName := strings.ToLower(fileName)
-	if strings.HasSuffix(lowerName, ".csv") {
-		headers, dataRows, err = parseCSV(file)
-	} else if strings.HasSuffix(lowerName, ".xlsx") {
-		headers, dataRows, err = parseExcel(file)
-	} else {
-		return nil, fmt.Errorf("unsupported file format: must be .csv or .xlsx")
-	}
+	headers, dataRows, err := parseImportFile(file, fileName)
 	if err != nil {
 		return nil, err
 	}
 
-	// 4. Map headers to field keys
-	columnKeys, _ := mapHeadersToFields(headers, fields)
-
-	// 5. Build required field key set (always-required + project-configured)
+	// 4. Build required field key set (always-required + project-configured)
 	isRequired := make(map[string]bool)
 	for _, f := range fields {
 		if f.AlwaysRequired || requiredSet[f.Key] {
@@ -145,7 +131,7 @@ func ValidateAddressFile(
 		}
 	}
 
-	// 6. For Install At, load existing Ship To company_name values for reference validation
+	// 5. For Install At, load existing Ship To company_name values for reference validation
 	var shipToNames map[string]bool
 	if addressType == "install_at" {
 		shipToNames, err = loadShipToCompanyNames(app, projectID)
@@ -154,7 +140,51 @@ func ValidateAddressFile(
 		}
 	}
 
-	// 7. Validate each row
+	// 6. Validate each row
+	return validateImportRows(headers, dataRows, fields, isRequired, fileName, func(rowNum int, rowData map[string]string) []ValidationError {
+		// Field-format validations (only if value is non-empty)
+		rowErrors := validateImportFieldFormats(rowNum, rowData)
+
+		// Ship To Reference validation for Install At
+		if addressType == "install_at" {
+			ref := rowData["ship_to_reference"]
+			if ref != "" && !shipToNames[ref] {
+				rowErrors = append(rowErrors, ValidationError{
+					Row:     rowNum,
+					Field:   "Ship To Reference",
+					Message: fmt.Sprintf("No Ship To address with company name %q found in this project", ref),
+				})
+			}
+		}
+		return rowErrors
+	}), nil
+}
+
+// parseImportFile parses an uploaded .csv or .xlsx file, picking the parser
+// by the file's extension.
+func parseImportFile(file multipart.File, fileName string) ([]string, [][]string, error) {
+	lowerName := strings.ToLower(fileName)
+	if strings.HasSuffix(lowerName, ".csv") {
+		return parseCSV(file)
+	} else if strings.HasSuffix(lowerName, ".xlsx") {
+		return parseExcel(file)
+	}
+	return nil, nil, fmt.Errorf("unsupported file format: must be .csv or .xlsx")
+}
+
+// validateImportRows maps each data row onto the template fields by its
+// column headers, checks the required fields and runs checkRow for any
+// further validation of the row. Every row is kept in ParsedRows.
+func validateImportRows(
+	headers []string,
+	dataRows [][]string,
+	fields []TemplateField,
+	isRequired map[string]bool,
+	fileName string,
+	checkRow func(rowNum int, rowData map[string]string) []ValidationError,
+) *ValidationResult {
+	columnKeys, _ := mapHeadersToFields(headers, fields)
+
 	result := &ValidationResult{
 		TotalRows:  len(dataRows),
 		FileName:   fileName,
@@ -199,20 +229,7 @@ func ValidateAddressFile(
 			}
 		}
 
-		// Field-format validations (only if value is non-empty)
-		rowErrors = append(rowErrors, validateImportFieldFormats(rowNum, rowData)...)
-
-		// Ship To Reference validation for Install At
-		if addressType == "install_at" {
-			ref := rowData["ship_to_reference"]
-			if ref != "" && !shipToNames[ref] {
-				rowErrors = append(rowErrors, ValidationError{
-					Row:     rowNum,
-					Field:   "Ship To Reference",
-					Message: fmt.Sprintf("No Ship To address with company name %q found in this project", ref),
-				})
-			}
-		}
+		rowErrors = append(rowErrors, checkRow(rowNum, rowData)...)
 
 		if len(rowErrors) > 0 {
 			result.Errors = append(result.Errors, rowErrors...)
@@ -228,7 +245,7 @@ func ValidateAddressFile(
 	result.ErrorRows = len(errorRowSet)
 	result.ValidRows = result.TotalRows - result.ErrorRows
 
-	return result, nil
+	return result
 }
 
 // validateImportFieldFormats checks format-specific rules for non-empty values.
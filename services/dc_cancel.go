@@ -0,0 +1,176 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// cancelInfo carries the reason, time and actor stamped on every DC a
+// cancellation touches.
+type cancelInfo struct {
+	Reason string
+	At     string
+	Actor  AuditActor
+}
+
+// CancelDC voids an issued delivery challan. The reason is mandatory; the
+// actor is taken from ctx (see WithAuditActor).
+//
+//   - A DC in a shipment group cancels the whole group (transit and official
+//     DCs). If the group was split from a transfer DC, its destinations are
+//     released so they can be split again.
+//   - A transfer DC also cancels every shipment group split from it.
+//
+// Serials on cancelled DCs stay on record but no longer count as used. A DC
+// already billed on a client invoice, with proof of delivery recorded, or
+// with material returned against it, cannot be cancelled.
+func CancelDC(ctx context.Context, app *pocketbase.PocketBase, dcID, reason string) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return fmt.Errorf("a cancellation reason is required")
+	}
+
+	dc, err := app.FindRecordById("delivery_challans", dcID)
+	if err != nil {
+		return fmt.Errorf("delivery challan not found: %w", err)
+	}
+
+	switch dc.GetString("status") {
+	case "cancelled":
+		return fmt.Errorf("DC is already cancelled")
+	case "draft":
+		return fmt.Errorf("draft DCs cannot be cancelled; delete the draft instead")
+	}
+
+	info := cancelInfo{
+		Reason: reason,
+		At:     time.Now().UTC().Format(time.RFC3339),
+		Actor:  auditActorFromContext(ctx),
+	}
+
+	return app.RunInTransaction(func(txApp core.App) error {
+		if sgID := dc.GetString("shipment_group"); sgID != "" {
+			return cancelShipmentGroup(ctx, txApp, sgID, info, true)
+		}
+		if dc.GetString("dc_type") == "transfer" {
+			return cancelTransferDC(ctx, txApp, dc, info)
+		}
+		return markDCCancelled(ctx, txApp, dc, info)
+	})
+}
+
+// cancelShipmentGroup cancels every DC in a shipment group and the group
+// itself. releaseSplit frees the transfer DC destinations the group was split
+// from; it is false when the parent transfer DC is being cancelled too.
+func cancelShipmentGroup(ctx context.Context, app core.App, sgID string, info cancelInfo, releaseSplit bool) error {
+	sg, err := app.FindRecordById("shipment_groups", sgID)
+	if err != nil {
+		return fmt.Errorf("shipment group not found: %w", err)
+	}
+
+	dcs, err := app.FindRecordsByFilter("delivery_challans", "shipment_group = {:sgid}", "", 0, 0, map[string]any{"sgid": sgID})
+	if err != nil {
+		return fmt.Errorf("failed to fetch DCs for shipment group: %w", err)
+	}
+	for _, dc := range dcs {
+		if dc.GetString("status") == "cancelled" {
+			continue
+		}
+		if err := markDCCancelled(ctx, app, dc, info); err != nil {
+			return err
+		}
+	}
+
+	sg.Set("status", "cancelled")
+	if err := app.SaveWithContext(ctx, sg); err != nil {
+		return fmt.Errorf("failed to cancel shipment group: %w", err)
+	}
+
+	if releaseSplit && sg.GetString("transfer_dc") != "" {
+		return releaseSplitDestinations(ctx, app, sg)
+	}
+	return nil
+}
+
+// cancelTransferDC cancels a transfer DC together with all shipment groups
+// split from it.
+func cancelTransferDC(ctx context.Context, app core.App, dc *core.Record, info cancelInfo) error {
+	transferRecs, _ := app.FindRecordsByFilter("transfer_dcs", "dc = {:did}", "", 1, 0, map[string]any{"did": dc.Id})
+	if len(transferRecs) > 0 {
+		groups, err := app.FindRecordsByFilter("shipment_groups", "transfer_dc = {:tid}", "", 0, 0, map[string]any{"tid": transferRecs[0].Id})
+		if err != nil {
+			return fmt.Errorf("failed to fetch split shipment groups: %w", err)
+		}
+		for _, sg := range groups {
+			if sg.GetString("status") == "cancelled" {
+				continue
+			}
+			if err := cancelShipmentGroup(ctx, app, sg.Id, info, false); err != nil {
+				return err
+			}
+		}
+	}
+
+	return markDCCancelled(ctx, app, dc, info)
+}
+
+// releaseSplitDestinations hands the quantities of a cancelled split back to
+// its destinations and recomputes the parent transfer DC's status.
+func releaseSplitDestinations(ctx context.Context, app core.App, sg *core.Record) error {
+	transferDC, err := app.FindRecordById("transfer_dcs", sg.GetString("transfer_dc"))
+	if err != nil {
+		return fmt.Errorf("transfer DC metadata not found: %w", err)
+	}
+
+	if err := releaseSplitQuantities(ctx, app, transferDC.Id, sg.Id); err != nil {
+		return err
+	}
+
+	numSplit := transferDC.GetInt("num_split")
+	if numSplit > 0 {
+		numSplit--
+	}
+	transferDC.Set("num_split", numSplit)
+	if err := app.SaveWithContext(ctx, transferDC); err != nil {
+		return fmt.Errorf("failed to update transfer DC: %w", err)
+	}
+
+	return refreshTransferDCStatus(ctx, app, transferDC)
+}
+
+// markDCCancelled stamps a DC as cancelled. A DC billed on a client invoice
+// or with its delivery or a material return recorded cannot be cancelled.
+func markDCCancelled(ctx context.Context, app core.App, dc *core.Record, info cancelInfo) error {
+	if _, err := app.FindFirstRecordByData("dc_deliveries", "dc", dc.Id); err == nil {
+		return fmt.Errorf("DC %s has been delivered and cannot be cancelled", dc.GetString("dc_number"))
+	}
+	if mrn, err := app.FindFirstRecordByData("material_return_notes", "dc", dc.Id); err == nil {
+		return fmt.Errorf("DC %s has material returned on %s and cannot be cancelled", dc.GetString("dc_number"), mrn.GetString("mrn_number"))
+	}
+	invoiced, err := InvoicedDCLines(app, []string{dc.Id})
+	if err != nil {
+		return err
+	}
+	for _, invoiceID := range invoiced {
+		number := invoiceID
+		if inv, err := app.FindRecordById("client_invoices", invoiceID); err == nil {
+			number = inv.GetString("invoice_number")
+		}
+		return fmt.Errorf("DC %s is billed on invoice %s and cannot be cancelled", dc.GetString("dc_number"), number)
+	}
+
+	dc.Set("status", "cancelled")
+	dc.Set("cancel_reason", info.Reason)
+	dc.Set("cancelled_at", info.At)
+	dc.Set("cancelled_by", info.Actor.ID)
+	dc.Set("cancelled_by_name", info.Actor.Name)
+	if err := app.SaveWithContext(ctx, dc); err != nil {
+		return fmt.Errorf("failed to cancel DC %s: %w", dc.GetString("dc_number"), err)
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/testhelpers"
+)
+
+// saveTestRecord creates a record in the given collection with the given fields.
+func saveTestRecord(t testing.TB, app *pocketbase.PocketBase, collection string, fields map[string]any) *core.Record {
+	t.Helper()
+	col, err := app.FindCollectionByNameOrId(collection)
+	if err != nil {
+		t.Fatalf("failed to find %s collection: %v", collection, err)
+	}
+	rec := core.NewRecord(col)
+	for k, v := range fields {
+		rec.Set(k, v)
+	}
+	if err := app.Save(rec); err != nil {
+		t.Fatalf("failed to save %s record: %v", collection, err)
+	}
+	return rec
+}
+
+// issuedShipmentGroup creates an issued shipment group with one transit DC
+// (carrying the given serials) and one official DC.
+func issuedShipmentGroup(t *testing.T, app *pocketbase.PocketBase, projectID, suffix string, serials ...string) (sg, transit, official *core.Record) {
+	t.Helper()
+	sg = saveTestRecord(t, app, "shipment_groups", map[string]any{
+		"project": projectID, "num_locations": 1, "tax_type": "igst", "status": "issued",
+	})
+	transit = testhelpers.CreateTestDeliveryChallan(t, app, projectID, "TDC-"+suffix, "transit", "issued")
+	official = testhelpers.CreateTestDeliveryChallan(t, app, projectID, "ODC-"+suffix, "official", "issued")
+	for _, dc := range []*core.Record{transit, official} {
+		dc.Set("shipment_group", sg.Id)
+		if err := app.Save(dc); err != nil {
+			t.Fatalf("failed to link DC to group: %v", err)
+		}
+	}
+	li := saveTestRecord(t, app, "dc_line_items", map[string]any{
+		"dc": transit.Id, "source_item_type": "sub_item", "source_item_id": "x", "quantity": max(len(serials), 1),
+	})
+	for _, s := range serials {
+		saveTestRecord(t, app, "serial_numbers", map[string]any{
+			"project": projectID, "line_item": li.Id, "serial_number": s,
+		})
+	}
+	return sg, transit, official
+}
+
+func TestCancelDC_RequiresReason(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Cancel Project")
+	dc := testhelpers.CreateTestDeliveryChallan(t, app, project.Id, "TDC-001", "transfer", "issued")
+
+	if err := CancelDC(context.Background(), app, dc.Id, "   "); err == nil {
+		t.Fatal("expected error for empty reason")
+	}
+	draft := testhelpers.CreateTestDeliveryChallan(t, app, project.Id, "TDC-002", "transfer", "draft")
+	if err := CancelDC(context.Background(), app, draft.Id, "Wrong site"); err == nil {
+		t.Fatal("expected error when cancelling a draft")
+	}
+}
+
+func TestCancelDC_ShipmentGroupCascades(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Cancel Project")
+	user := testhelpers.CreateTestUser(t, app, "logistics@example.com", "logistics", project.Id)
+	sg, transit, official := issuedShipmentGroup(t, app, project.Id, "001", "SN-1", "SN-2")
+
+	ctx := WithAuditActor(context.Background(), user.Id, "Lakshmi")
+	if err := CancelDC(ctx, app, official.Id, "Vehicle breakdown"); err != nil {
+		t.Fatalf("CancelDC failed: %v", err)
+	}
+
+	for _, id := range []string{transit.Id, official.Id} {
+		dc, _ := app.FindRecordById("delivery_challans", id)
+		if dc.GetString("status") != "cancelled" {
+			t.Errorf("expected DC %s to be cancelled, got %s", dc.GetString("dc_number"), dc.GetString("status"))
+		}
+		if dc.GetString("cancel_reason") != "Vehicle breakdown" || dc.GetString("cancelled_at") == "" {
+			t.Errorf("expected reason and date on %s", dc.GetString("dc_number"))
+		}
+		if dc.GetString("cancelled_by") != user.Id || dc.GetString("cancelled_by_name") != "Lakshmi" {
+			t.Errorf("expected actor on %s", dc.GetString("dc_number"))
+		}
+	}
+	sg, _ = app.FindRecordById("shipment_groups", sg.Id)
+	if sg.GetString("status") != "cancelled" {
+		t.Errorf("expected shipment group to be cancelled, got %s", sg.GetString("status"))
+	}
+
+	if err := CancelDC(ctx, app, transit.Id, "Again"); err == nil {
+		t.Error("expected error when cancelling an already cancelled DC")
+	}
+}
+
+func TestCancelDC_TransferDCSplits(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Transfer Project")
+	siteA := testhelpers.CreateTestAddress(t, app, project.Id, "ship_to", "Site A")
+	siteB := testhelpers.CreateTestAddress(t, app, project.Id, "ship_to", "Site B")
+
+	parent := testhelpers.CreateTestDeliveryChallan(t, app, project.Id, "STDC-001", "transfer", "split")
+	tdc := saveTestRecord(t, app, "transfer_dcs", map[string]any{
+		"dc": parent.Id, "tax_type": "igst", "num_destinations": 2, "num_split": 2,
+	})
+
+	// Two splits, one per destination
+	var groups []*core.Record
+	var transits []*core.Record
+	for i, addr := range []*core.Record{siteA, siteB} {
+		sg, transit, _ := issuedShipmentGroup(t, app, project.Id, []string{"A", "B"}[i])
+		sg.Set("transfer_dc", tdc.Id)
+		split := saveTestRecord(t, app, "transfer_dc_splits", map[string]any{
+			"transfer_dc": tdc.Id, "shipment_group": sg.Id, "split_number": i + 1,
+		})
+		sg.Set("split", split.Id)
+		if err := app.Save(sg); err != nil {
+			t.Fatalf("failed to link split: %v", err)
+		}
+		saveTestRecord(t, app, "transfer_dc_destinations", map[string]any{
+			"transfer_dc": tdc.Id, "ship_to_address": addr.Id, "is_split": true, "split_group": sg.Id,
+		})
+		groups = append(groups, sg)
+		transits = append(transits, transit)
+	}
+
+	// Cancelling one split frees its destination and reopens the parent
+	if err := CancelDC(context.Background(), app, transits[0].Id, "Site not ready"); err != nil {
+		t.Fatalf("CancelDC on split failed: %v", err)
+	}
+	dests, _ := app.FindRecordsByFilter("transfer_dc_destinations", "ship_to_address = {:a}", "", 0, 0, map[string]any{"a": siteA.Id})
+	if len(dests) != 1 || dests[0].GetBool("is_split") || dests[0].GetString("split_group") != "" {
+		t.Error("expected site A destination to be released")
+	}
+	tdc, _ = app.FindRecordById("transfer_dcs", tdc.Id)
+	if tdc.GetInt("num_split") != 1 {
+		t.Errorf("expected num_split 1, got %d", tdc.GetInt("num_split"))
+	}
+	parent, _ = app.FindRecordById("delivery_challans", parent.Id)
+	if parent.GetString("status") != "splitting" {
+		t.Errorf("expected parent to be splitting, got %s", parent.GetString("status"))
+	}
+
+	// Cancelling the parent cascades to the remaining split
+	if err := CancelDC(context.Background(), app, parent.Id, "Order withdrawn"); err != nil {
+		t.Fatalf("CancelDC on transfer DC failed: %v", err)
+	}
+	parent, _ = app.FindRecordById("delivery_challans", parent.Id)
+	if parent.GetString("status") != "cancelled" {
+		t.Errorf("expected parent to be cancelled, got %s", parent.GetString("status"))
+	}
+	sg, _ := app.FindRecordById("shipment_groups", groups[1].Id)
+	if sg.GetString("status") != "cancelled" {
+		t.Errorf("expected remaining split group to be cancelled, got %s", sg.GetString("status"))
+	}
+	transit, _ := app.FindRecordById("delivery_challans", transits[1].Id)
+	if transit.GetString("cancel_reason") != "Order withdrawn" {
+		t.Errorf("expected split DC to carry the parent's reason, got %q", transit.GetString("cancel_reason"))
+	}
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
@@ -37,6 +38,9 @@ type ShipmentParams struct {
 	ShipToIDs      []string
 	TaxType        string
 	Items          []ShipmentItemParams
+	// OverDispatchReason explains why quantities beyond the remaining BOQ
+	// balance were allowed (see CheckOverDispatch).
+	OverDispatchReason string
 }
 
 // ShipmentResult contains IDs of created records.
@@ -65,6 +69,9 @@ type TransferDCParams struct {
 	HubAddressID   string
 	TaxType        string
 	Items          []ShipmentItemParams
+	// OverDispatchReason explains why quantities beyond the remaining BOQ
+	// balance were allowed (see CheckOverDispatch).
+	OverDispatchReason string
 }
 
 // TransferDCResult contains IDs of created records.
@@ -74,7 +81,7 @@ type TransferDCResult struct {
 }
 
 // CreateDirectShipment creates a shipment group with 1 transit DC + N official DCs.
-func CreateDirectShipment(app *pocketbase.PocketBase, params ShipmentParams) (*ShipmentResult, error) {
+func CreateDirectShipment(ctx context.Context, app *pocketbase.PocketBase, params ShipmentParams) (*ShipmentResult, error) {
 	result := &ShipmentResult{}
 	docDate, err := time.Parse("2006-01-02", params.ChallanDate)
 	if err != nil {
@@ -96,7 +103,7 @@ func CreateDirectShipment(app *pocketbase.PocketBase, params ShipmentParams) (*S
 	sgRec.Set("tax_type", params.TaxType)
 	sgRec.Set("reverse_charge", params.ReverseCharge)
 	sgRec.Set("status", "draft")
-	if err := app.Save(sgRec); err != nil {
+	if err := app.SaveWithContext(ctx, sgRec); err != nil {
 		return nil, fmt.Errorf("failed to create shipment group: %w", err)
 	}
 	result.ShipmentGroupID = sgRec.Id
@@ -136,8 +143,9 @@ func CreateDirectShipment(app *pocketbase.PocketBase, params ShipmentParams) (*S
 		transitDC.Set("ship_to_address", params.ShipToIDs[0])
 	}
 	transitDC.Set("challan_date", params.ChallanDate)
+	transitDC.Set("over_dispatch_reason", params.OverDispatchReason)
 	transitDC.Set("shipment_group", sgRec.Id)
-	if err := app.Save(transitDC); err != nil {
+	if err := app.SaveWithContext(ctx, transitDC); err != nil {
 		return nil, fmt.Errorf("failed to create transit DC: %w", err)
 	}
 	result.TransitDCID = transitDC.Id
@@ -173,7 +181,7 @@ func CreateDirectShipment(app *pocketbase.PocketBase, params ShipmentParams) (*S
 		lineItem.Set("tax_amount", taxAmount)
 		lineItem.Set("total_amount", totalAmount)
 		lineItem.Set("line_order", i+1)
-		if err := app.Save(lineItem); err != nil {
+		if err := app.SaveWithContext(ctx, lineItem); err != nil {
 			return nil, fmt.Errorf("failed to create transit DC line item: %w", err)
 		}
 
@@ -183,7 +191,7 @@ func CreateDirectShipment(app *pocketbase.PocketBase, params ShipmentParams) (*S
 			serialRec.Set("project", params.ProjectID)
 			serialRec.Set("line_item", lineItem.Id)
 			serialRec.Set("serial_number", serial)
-			if err := app.Save(serialRec); err != nil {
+			if err := app.SaveWithContext(ctx, serialRec); err != nil {
 				return nil, fmt.Errorf("failed to save serial number %s: %w", serial, err)
 			}
 		}
@@ -207,7 +215,7 @@ func CreateDirectShipment(app *pocketbase.PocketBase, params ShipmentParams) (*S
 			detail.Set("vehicle_number", vehicleNumber)
 			detail.Set("eway_bill_number", params.EwayBillNumber)
 			detail.Set("docket_number", params.DocketNumber)
-			if err := app.Save(detail); err != nil {
+			if err := app.SaveWithContext(ctx, detail); err != nil {
 				return nil, fmt.Errorf("failed to create transit details: %w", err)
 			}
 		}
@@ -256,7 +264,7 @@ func CreateDirectShipment(app *pocketbase.PocketBase, params ShipmentParams) (*S
 		odc.Set("ship_to_address", shipToID)
 		odc.Set("challan_date", params.ChallanDate)
 		odc.Set("shipment_group", sgRec.Id)
-		if err := app.Save(odc); err != nil {
+		if err := app.SaveWithContext(ctx, odc); err != nil {
 			return nil, fmt.Errorf("failed to create official DC: %w", err)
 		}
 
@@ -276,7 +284,7 @@ func CreateDirectShipment(app *pocketbase.PocketBase, params ShipmentParams) (*S
 			odcLineItem.Set("source_item_id", item.SourceItemID)
 			odcLineItem.Set("quantity", qty)
 			odcLineItem.Set("line_order", lineIdx+1)
-			if err := app.Save(odcLineItem); err != nil {
+			if err := app.SaveWithContext(ctx, odcLineItem); err != nil {
 				return nil, fmt.Errorf("failed to create official DC line item: %w", err)
 			}
 		}
@@ -286,7 +294,7 @@ func CreateDirectShipment(app *pocketbase.PocketBase, params ShipmentParams) (*S
 }
 
 // CreateTransferDC creates a transfer DC with destination plan.
-func CreateTransferDC(app *pocketbase.PocketBase, params TransferDCParams) (*TransferDCResult, error) {
+func CreateTransferDC(ctx context.Context, app *pocketbase.PocketBase, params TransferDCParams) (*TransferDCResult, error) {
 	result := &TransferDCResult{}
 	docDate, err := time.Parse("2006-01-02", params.ChallanDate)
 	if err != nil {
@@ -327,7 +335,8 @@ func CreateTransferDC(app *pocketbase.PocketBase, params TransferDCParams) (*Tra
 		dc.Set("ship_to_address", params.ShipToIDs[0])
 	}
 	dc.Set("challan_date", params.ChallanDate)
-	if err := app.Save(dc); err != nil {
+	dc.Set("over_dispatch_reason", params.OverDispatchReason)
+	if err := app.SaveWithContext(ctx, dc); err != nil {
 		return nil, fmt.Errorf("failed to create transfer DC: %w", err)
 	}
 	result.DCID = dc.Id
@@ -363,7 +372,7 @@ func CreateTransferDC(app *pocketbase.PocketBase, params TransferDCParams) (*Tra
 		lineItem.Set("tax_amount", taxAmount)
 		lineItem.Set("total_amount", totalAmount)
 		lineItem.Set("line_order", i+1)
-		if err := app.Save(lineItem); err != nil {
+		if err := app.SaveWithContext(ctx, lineItem); err != nil {
 			return nil, fmt.Errorf("failed to create transfer DC line item: %w", err)
 		}
 
@@ -373,7 +382,7 @@ func CreateTransferDC(app *pocketbase.PocketBase, params TransferDCParams) (*Tra
 			serialRec.Set("project", params.ProjectID)
 			serialRec.Set("line_item", lineItem.Id)
 			serialRec.Set("serial_number", serial)
-			if err := app.Save(serialRec); err != nil {
+			if err := app.SaveWithContext(ctx, serialRec); err != nil {
 				return nil, fmt.Errorf("failed to save serial number %s: %w", serial, err)
 			}
 		}
@@ -410,7 +419,7 @@ func CreateTransferDC(app *pocketbase.PocketBase, params TransferDCParams) (*Tra
 	transferDC.Set("docket_number", params.DocketNumber)
 	transferDC.Set("num_destinations", len(params.ShipToIDs))
 	transferDC.Set("num_split", 0)
-	if err := app.Save(transferDC); err != nil {
+	if err := app.SaveWithContext(ctx, transferDC); err != nil {
 		return nil, fmt.Errorf("failed to create transfer DC metadata: %w", err)
 	}
 
@@ -434,7 +443,7 @@ func CreateTransferDC(app *pocketbase.PocketBase, params TransferDCParams) (*Tra
 		destRec.Set("transfer_dc", transferDC.Id)
 		destRec.Set("ship_to_address", shipToID)
 		destRec.Set("is_split", false)
-		if err := app.Save(destRec); err != nil {
+		if err := app.SaveWithContext(ctx, destRec); err != nil {
 			return nil, fmt.Errorf("failed to create transfer DC destination: %w", err)
 		}
 
@@ -449,7 +458,7 @@ func CreateTransferDC(app *pocketbase.PocketBase, params TransferDCParams) (*Tra
 			destQty.Set("source_item_type", item.SourceItemType)
 			destQty.Set("source_item_id", item.SourceItemID)
 			destQty.Set("quantity", item.Quantities[destIdx])
-			if err := app.Save(destQty); err != nil {
+			if err := app.SaveWithContext(ctx, destQty); err != nil {
 				return nil, fmt.Errorf("failed to create destination quantity: %w", err)
 			}
 		}
@@ -467,7 +476,7 @@ func CreateTransferDC(app *pocketbase.PocketBase, params TransferDCParams) (*Tra
 			detail.Set("vehicle_number", vehicleNumber)
 			detail.Set("eway_bill_number", params.EwayBillNumber)
 			detail.Set("docket_number", params.DocketNumber)
-			_ = app.Save(detail)
+			_ = app.SaveWithContext(ctx, detail)
 		}
 	}
 
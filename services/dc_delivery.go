@@ -0,0 +1,274 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+)
+
+// DC statuses set by proof of delivery.
+const (
+	DCStatusDelivered          = "delivered"
+	DCStatusPartiallyDelivered = "partially_delivered"
+)
+
+// DCIssuedStatuses are the statuses of an issued DC that has not been split
+// or cancelled: issued, and either delivery status once proof of delivery
+// is recorded.
+var DCIssuedStatuses = []string{"issued", DCStatusDelivered, DCStatusPartiallyDelivered}
+
+// DeliverableDCLine is a line of a DC with the quantity dispatched on it.
+type DeliverableDCLine struct {
+	DCLineItemID string
+	Description  string
+	UOM          string
+	Dispatched   float64
+}
+
+// DeliveryLineParams is what arrived against one DC line. Damaged goods are
+// part of the received quantity.
+type DeliveryLineParams struct {
+	DCLineItemID string
+	QtyReceived  float64
+	QtyDamaged   float64
+	Remarks      string
+}
+
+// DeliveryParams holds the proof of delivery of a DC.
+type DeliveryParams struct {
+	DCID         string
+	ReceivedBy   string
+	ReceivedDate string
+	Remarks      string
+	Lines        []DeliveryLineParams
+}
+
+// DeliveryStatus returns delivered when every line arrived in full and
+// undamaged, and partially delivered otherwise.
+func DeliveryStatus(lines []DeliveryLineParams, dispatched map[string]float64) string {
+	for _, l := range lines {
+		if l.QtyReceived < dispatched[l.DCLineItemID] || l.QtyDamaged > 0 {
+			return DCStatusPartiallyDelivered
+		}
+	}
+	return DCStatusDelivered
+}
+
+// CheckDeliverable reports why proof of delivery cannot be recorded for a
+// DC, or nil when it can. Only issued official and transfer DCs (including
+// transfer DCs being split) without a delivery already recorded qualify.
+func CheckDeliverable(app core.App, dc *core.Record) error {
+	switch dc.GetString("dc_type") {
+	case "official", "transfer":
+	default:
+		return fmt.Errorf("proof of delivery is recorded on official and transfer DCs, not %s DCs", dc.GetString("dc_type"))
+	}
+	switch dc.GetString("status") {
+	case "issued", "splitting", "split":
+	case DCStatusDelivered, DCStatusPartiallyDelivered:
+		return fmt.Errorf("delivery of %s is already recorded", dc.GetString("dc_number"))
+	default:
+		return fmt.Errorf("%s is %s; only issued DCs can be delivered", dc.GetString("dc_number"), dc.GetString("status"))
+	}
+	if _, err := app.FindFirstRecordByData("dc_deliveries", "dc", dc.Id); err == nil {
+		return fmt.Errorf("delivery of %s is already recorded", dc.GetString("dc_number"))
+	}
+	return nil
+}
+
+// DeliverableDCLines returns the lines of a DC in line order, with their
+// descriptions resolved from the BOQ.
+func DeliverableDCLines(app core.App, dcID string) ([]DeliverableDCLine, error) {
+	items, err := app.FindRecordsByFilter("dc_line_items", "dc = {:did}", "line_order", 0, 0, map[string]any{"did": dcID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch DC line items: %w", err)
+	}
+	lines := make([]DeliverableDCLine, 0, len(items))
+	for _, li := range items {
+		line := DeliverableDCLine{DCLineItemID: li.Id, Dispatched: li.GetFloat("quantity")}
+		collection := "sub_items"
+		if li.GetString("source_item_type") == "sub_sub_item" {
+			collection = "sub_sub_items"
+		}
+		if source, err := app.FindRecordById(collection, li.GetString("source_item_id")); err == nil {
+			line.Description = source.GetString("description")
+			line.UOM = source.GetString("uom")
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+// ValidateDelivery checks the proof of delivery of a DC. Errors are keyed
+// by form field name, or by DC line item ID for a line.
+func ValidateDelivery(app core.App, params DeliveryParams) map[string]string {
+	errs := make(map[string]string)
+
+	dc, err := app.FindRecordById("delivery_challans", params.DCID)
+	if err != nil {
+		errs["dc"] = "Delivery challan not found"
+		return errs
+	}
+	if err := CheckDeliverable(app, dc); err != nil {
+		errs["dc"] = err.Error()
+		return errs
+	}
+
+	if strings.TrimSpace(params.ReceivedBy) == "" {
+		errs["received_by"] = "Enter who received the goods"
+	}
+	date, err := time.Parse("2006-01-02", params.ReceivedDate)
+	switch {
+	case err != nil:
+		errs["received_date"] = "Received date is required"
+	case date.After(time.Now()):
+		errs["received_date"] = "Received date cannot be in the future"
+	case params.ReceivedDate < dc.GetString("challan_date"):
+		errs["received_date"] = "Received date cannot be before the challan date"
+	}
+
+	lines, err := DeliverableDCLines(app, dc.Id)
+	if err != nil {
+		errs["dc"] = "Could not load the DC's line items"
+		return errs
+	}
+	byID := make(map[string]DeliverableDCLine, len(lines))
+	for _, l := range lines {
+		byID[l.DCLineItemID] = l
+	}
+	if len(params.Lines) != len(lines) {
+		errs["dc"] = "Enter the received quantity for every line of the DC"
+	}
+	for _, l := range params.Lines {
+		line, ok := byID[l.DCLineItemID]
+		if !ok {
+			errs[l.DCLineItemID] = "Line item does not belong to this DC"
+			continue
+		}
+		switch {
+		case l.QtyReceived < 0 || l.QtyDamaged < 0:
+			errs[l.DCLineItemID] = fmt.Sprintf("%s: quantities cannot be negative", line.Description)
+		case l.QtyReceived > line.Dispatched:
+			errs[l.DCLineItemID] = fmt.Sprintf("%s: received quantity cannot exceed the %s dispatched", line.Description, formatQty(line.Dispatched))
+		case l.QtyDamaged > l.QtyReceived:
+			errs[l.DCLineItemID] = fmt.Sprintf("%s: damaged quantity cannot exceed the received quantity", line.Description)
+		case (l.QtyReceived < line.Dispatched || l.QtyDamaged > 0) && strings.TrimSpace(l.Remarks) == "":
+			errs[l.DCLineItemID] = fmt.Sprintf("%s: note the reason for the shortage or damage", line.Description)
+		}
+	}
+	return errs
+}
+
+// RecordDelivery saves the proof of delivery of a DC with any signed challan
+// scans, and marks the DC delivered or partially delivered. A transfer DC
+// already being split keeps its split status; the delivery applies once its
+// splits are cancelled (see restingDCStatus).
+func RecordDelivery(ctx context.Context, app *pocketbase.PocketBase, params DeliveryParams, scans []*filesystem.File) (*core.Record, error) {
+	if errs := ValidateDelivery(app, params); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid delivery: %s", strings.Join(slices.Sorted(maps.Values(errs)), "; "))
+	}
+	dc, err := app.FindRecordById("delivery_challans", params.DCID)
+	if err != nil {
+		return nil, fmt.Errorf("delivery challan not found: %w", err)
+	}
+	lines, err := DeliverableDCLines(app, dc.Id)
+	if err != nil {
+		return nil, err
+	}
+	dispatched := make(map[string]float64, len(lines))
+	for _, l := range lines {
+		dispatched[l.DCLineItemID] = l.Dispatched
+	}
+
+	deliveryCol, err := app.FindCollectionByNameOrId("dc_deliveries")
+	if err != nil {
+		return nil, fmt.Errorf("dc_deliveries collection not found: %w", err)
+	}
+	lineCol, err := app.FindCollectionByNameOrId("dc_delivery_lines")
+	if err != nil {
+		return nil, fmt.Errorf("dc_delivery_lines collection not found: %w", err)
+	}
+
+	delivery := core.NewRecord(deliveryCol)
+	err = app.RunInTransaction(func(txApp core.App) error {
+		delivery.Set("dc", dc.Id)
+		delivery.Set("received_by", strings.TrimSpace(params.ReceivedBy))
+		delivery.Set("received_date", params.ReceivedDate)
+		delivery.Set("remarks", strings.TrimSpace(params.Remarks))
+		if len(scans) > 0 {
+			delivery.Set("signed_challans", scans)
+		}
+		if err := txApp.SaveWithContext(ctx, delivery); err != nil {
+			return fmt.Errorf("failed to save delivery: %w", err)
+		}
+
+		for _, l := range params.Lines {
+			rec := core.NewRecord(lineCol)
+			rec.Set("delivery", delivery.Id)
+			rec.Set("dc_line_item", l.DCLineItemID)
+			rec.Set("qty_dispatched", dispatched[l.DCLineItemID])
+			rec.Set("qty_received", l.QtyReceived)
+			rec.Set("qty_damaged", l.QtyDamaged)
+			rec.Set("remarks", strings.TrimSpace(l.Remarks))
+			if err := txApp.SaveWithContext(ctx, rec); err != nil {
+				return fmt.Errorf("failed to save delivery line: %w", err)
+			}
+		}
+
+		if dc.GetString("status") == "issued" {
+			dc.Set("status", DeliveryStatus(params.Lines, dispatched))
+			if err := txApp.SaveWithContext(ctx, dc); err != nil {
+				return fmt.Errorf("failed to update DC status: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// AddDeliveryScans attaches more signed challan scans to a delivery.
+func AddDeliveryScans(ctx context.Context, app *pocketbase.PocketBase, delivery *core.Record, scans []*filesystem.File) error {
+	if len(scans) == 0 {
+		return fmt.Errorf("choose at least one scan")
+	}
+	delivery.Set("signed_challans+", scans)
+	if err := app.SaveWithContext(ctx, delivery); err != nil {
+		return fmt.Errorf("failed to save scans: %w", err)
+	}
+	return nil
+}
+
+// restingDCStatus returns the status a transfer DC falls back to once none
+// of it is split: its delivery status if proof of delivery was recorded,
+// otherwise issued.
+func restingDCStatus(app core.App, dcID string) string {
+	delivery, err := app.FindFirstRecordByData("dc_deliveries", "dc", dcID)
+	if err != nil {
+		return "issued"
+	}
+	lines, err := app.FindRecordsByFilter("dc_delivery_lines", "delivery = {:id}", "", 0, 0, map[string]any{"id": delivery.Id})
+	if err != nil {
+		return "issued"
+	}
+	params := make([]DeliveryLineParams, 0, len(lines))
+	dispatched := make(map[string]float64, len(lines))
+	for _, l := range lines {
+		params = append(params, DeliveryLineParams{
+			DCLineItemID: l.GetString("dc_line_item"),
+			QtyReceived:  l.GetFloat("qty_received"),
+			QtyDamaged:   l.GetFloat("qty_damaged"),
+		})
+		dispatched[l.GetString("dc_line_item")] = l.GetFloat("qty_dispatched")
+	}
+	return DeliveryStatus(params, dispatched)
+}
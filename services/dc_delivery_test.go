@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/testhelpers"
+)
+
+// deliverableDC creates an issued official DC in a shipment group with two
+// lines, 10 and 4 units dispatched.
+func deliverableDC(t *testing.T) (app *pocketbase.PocketBase, dc *core.Record, lines []*core.Record) {
+	t.Helper()
+	app = testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Delivery Project")
+	boq := testhelpers.CreateTestBOQ(t, app, project.Id, "Main BOQ")
+	main := testhelpers.CreateTestMainBOQItem(t, app, boq.Id, "Solar pump set")
+	pump := testhelpers.CreateTestSubItem(t, app, main.Id, "Pump")
+	panel := testhelpers.CreateTestSubItem(t, app, main.Id, "Panel")
+	_, _, dc = issuedShipmentGroup(t, app, project.Id, "001")
+	for i, item := range []struct {
+		source *core.Record
+		qty    int
+	}{{pump, 10}, {panel, 4}} {
+		lines = append(lines, saveTestRecord(t, app, "dc_line_items", map[string]any{
+			"dc": dc.Id, "source_item_type": "sub_item", "source_item_id": item.source.Id, "quantity": item.qty, "line_order": i + 1,
+		}))
+	}
+	return app, dc, lines
+}
+
+func fullDelivery(dc *core.Record, lines []*core.Record) DeliveryParams {
+	return DeliveryParams{
+		DCID:         dc.Id,
+		ReceivedBy:   "K. Naidu",
+		ReceivedDate: time.Now().Format("2006-01-02"),
+		Lines: []DeliveryLineParams{
+			{DCLineItemID: lines[0].Id, QtyReceived: 10},
+			{DCLineItemID: lines[1].Id, QtyReceived: 4},
+		},
+	}
+}
+
+func TestValidateDelivery(t *testing.T) {
+	app, dc, lines := deliverableDC(t)
+	valid := fullDelivery(dc, lines)
+	if errs := ValidateDelivery(app, valid); len(errs) > 0 {
+		t.Fatalf("expected no errors, got %v", errs)
+	}
+
+	tests := []struct {
+		name  string
+		edit  func(p *DeliveryParams)
+		field string
+	}{
+		{"no receiver", func(p *DeliveryParams) { p.ReceivedBy = " " }, "received_by"},
+		{"before challan date", func(p *DeliveryParams) { p.ReceivedDate = "2026-03-09" }, "received_date"},
+		{"future date", func(p *DeliveryParams) {
+			p.ReceivedDate = time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+		}, "received_date"},
+		{"missing line", func(p *DeliveryParams) { p.Lines = p.Lines[:1] }, "dc"},
+		{"over-received", func(p *DeliveryParams) {
+			p.Lines = []DeliveryLineParams{{DCLineItemID: lines[0].Id, QtyReceived: 11}, p.Lines[1]}
+		}, lines[0].Id},
+		{"damaged more than received", func(p *DeliveryParams) {
+			p.Lines = []DeliveryLineParams{{DCLineItemID: lines[0].Id, QtyReceived: 2, QtyDamaged: 3, Remarks: "Crushed"}, p.Lines[1]}
+		}, lines[0].Id},
+		{"shortage without a note", func(p *DeliveryParams) {
+			p.Lines = []DeliveryLineParams{{DCLineItemID: lines[0].Id, QtyReceived: 8}, p.Lines[1]}
+		}, lines[0].Id},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := valid
+			tt.edit(&p)
+			if errs := ValidateDelivery(app, p); errs[tt.field] == "" {
+				t.Errorf("expected an error on %s, got %v", tt.field, errs)
+			}
+		})
+	}
+}
+
+func TestRecordDelivery_Statuses(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("in full", func(t *testing.T) {
+		app, dc, lines := deliverableDC(t)
+		if _, err := RecordDelivery(ctx, app, fullDelivery(dc, lines), nil); err != nil {
+			t.Fatalf("RecordDelivery failed: %v", err)
+		}
+		dc, _ = app.FindRecordById("delivery_challans", dc.Id)
+		if dc.GetString("status") != DCStatusDelivered {
+			t.Errorf("expected delivered, got %s", dc.GetString("status"))
+		}
+		if _, err := RecordDelivery(ctx, app, fullDelivery(dc, lines), nil); err == nil {
+			t.Error("expected a second delivery of the DC to be rejected")
+		}
+	})
+
+	t.Run("short and damaged", func(t *testing.T) {
+		app, dc, lines := deliverableDC(t)
+		params := fullDelivery(dc, lines)
+		params.Lines[0] = DeliveryLineParams{DCLineItemID: lines[0].Id, QtyReceived: 9, QtyDamaged: 1, Remarks: "One lost, one cracked"}
+		delivery, err := RecordDelivery(ctx, app, params, nil)
+		if err != nil {
+			t.Fatalf("RecordDelivery failed: %v", err)
+		}
+		dc, _ = app.FindRecordById("delivery_challans", dc.Id)
+		if dc.GetString("status") != DCStatusPartiallyDelivered {
+			t.Errorf("expected partially delivered, got %s", dc.GetString("status"))
+		}
+		saved, _ := app.FindRecordsByFilter("dc_delivery_lines", "delivery = {:id}", "", 0, 0, map[string]any{"id": delivery.Id})
+		if len(saved) != 2 {
+			t.Fatalf("expected 2 delivery lines, got %d", len(saved))
+		}
+		for _, l := range saved {
+			if l.GetString("dc_line_item") == lines[0].Id && (l.GetFloat("qty_dispatched") != 10 || l.GetFloat("qty_damaged") != 1) {
+				t.Errorf("unexpected delivery line %v", l.PublicExport())
+			}
+		}
+	})
+}
+
+func TestCancelDC_RejectsDeliveredDC(t *testing.T) {
+	app, dc, lines := deliverableDC(t)
+	ctx := context.Background()
+	if _, err := RecordDelivery(ctx, app, fullDelivery(dc, lines), nil); err != nil {
+		t.Fatalf("RecordDelivery failed: %v", err)
+	}
+	if err := CancelDC(ctx, app, dc.Id, "Wrong site"); err == nil {
+		t.Error("expected a delivered DC to be kept")
+	}
+	dc, _ = app.FindRecordById("delivery_challans", dc.Id)
+	if dc.GetString("status") != DCStatusDelivered {
+		t.Errorf("expected the DC to stay delivered, got %s", dc.GetString("status"))
+	}
+}
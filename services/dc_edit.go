@@ -0,0 +1,638 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// DCDraft is a draft DC read back into the values the DC wizard works with.
+// Direct shipments leave HubAddressID empty.
+type DCDraft struct {
+	DCID               string // transit DC of the shipment group, or the transfer or returnable DC
+	DCType             string // "direct", "transfer" or "returnable"
+	ExpectedReturnDate string // returnable DCs only
+	TransferDCParams
+}
+
+// LoadDCDraft reads a draft DC (any DC of a draft shipment group, or a draft
+// transfer or returnable DC) back into wizard values so it can be edited.
+func LoadDCDraft(app core.App, dcID string) (*DCDraft, error) {
+	dc, err := app.FindRecordById("delivery_challans", dcID)
+	if err != nil {
+		return nil, fmt.Errorf("delivery challan not found: %w", err)
+	}
+	if dc.GetString("status") != "draft" {
+		return nil, fmt.Errorf("only draft DCs can be edited")
+	}
+
+	if sgID := dc.GetString("shipment_group"); sgID != "" {
+		return loadShipmentDraft(app, sgID)
+	}
+	switch dc.GetString("dc_type") {
+	case "transfer":
+		return loadTransferDraft(app, dc)
+	case "returnable":
+		return loadReturnableDraft(app, dc)
+	}
+	return nil, fmt.Errorf("DC %s cannot be edited in the wizard", dc.GetString("dc_number"))
+}
+
+func loadShipmentDraft(app core.App, sgID string) (*DCDraft, error) {
+	sg, err := app.FindRecordById("shipment_groups", sgID)
+	if err != nil {
+		return nil, fmt.Errorf("shipment group not found: %w", err)
+	}
+
+	transit, officials, err := shipmentGroupDCs(app, sgID)
+	if err != nil {
+		return nil, err
+	}
+
+	draft := &DCDraft{DCID: transit.Id, DCType: "direct"}
+	fillDraftFromDC(draft, transit)
+	draft.TaxType = sg.GetString("tax_type")
+	draft.ReverseCharge = sg.GetBool("reverse_charge")
+	if t := sg.GetString("template"); t != "" {
+		draft.TemplateID = t
+	}
+	fillDraftTransport(app, draft, transit.Id, "", "", "", "")
+
+	items, err := draftItemsFromDC(app, transit.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Each official DC is one destination
+	for destIdx, odc := range officials {
+		draft.ShipToIDs = append(draft.ShipToIDs, odc.GetString("ship_to_address"))
+		lines, _ := app.FindRecordsByFilter("dc_line_items", "dc = {:did}", "line_order", 0, 0, map[string]any{"did": odc.Id})
+		for _, li := range lines {
+			setDraftQuantity(items, li.GetString("source_item_type"), li.GetString("source_item_id"), destIdx, len(officials), li.GetInt("quantity"))
+		}
+	}
+	if len(draft.ShipToIDs) == 0 {
+		draft.ShipToIDs = []string{transit.GetString("ship_to_address")}
+	}
+	draft.Items = items
+	return draft, nil
+}
+
+func loadTransferDraft(app core.App, dc *core.Record) (*DCDraft, error) {
+	draft := &DCDraft{DCID: dc.Id, DCType: "transfer"}
+	fillDraftFromDC(draft, dc)
+
+	items, err := draftItemsFromDC(app, dc.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	transferRecs, _ := app.FindRecordsByFilter("transfer_dcs", "dc = {:did}", "", 1, 0, map[string]any{"did": dc.Id})
+	if len(transferRecs) == 0 {
+		return nil, fmt.Errorf("transfer DC metadata not found")
+	}
+	tdc := transferRecs[0]
+	draft.HubAddressID = tdc.GetString("hub_address")
+	draft.TaxType = tdc.GetString("tax_type")
+	draft.ReverseCharge = tdc.GetBool("reverse_charge")
+	fillDraftTransport(app, draft, dc.Id, tdc.GetString("transporter"), tdc.GetString("vehicle_number"),
+		tdc.GetString("eway_bill_number"), tdc.GetString("docket_number"))
+
+	dests, _ := app.FindRecordsByFilter("transfer_dc_destinations", "transfer_dc = {:tid}", "created", 0, 0, map[string]any{"tid": tdc.Id})
+	for destIdx, dest := range dests {
+		draft.ShipToIDs = append(draft.ShipToIDs, dest.GetString("ship_to_address"))
+		qtys, _ := app.FindRecordsByFilter("transfer_dc_dest_quantities", "destination = {:did}", "", 0, 0, map[string]any{"did": dest.Id})
+		for _, q := range qtys {
+			setDraftQuantity(items, q.GetString("source_item_type"), q.GetString("source_item_id"), destIdx, len(dests), q.GetInt("quantity"))
+		}
+	}
+	draft.Items = items
+	return draft, nil
+}
+
+// shipmentGroupDCs returns the transit DC and the official DCs of a shipment
+// group, officials in the order they were created.
+func shipmentGroupDCs(app core.App, sgID string) (*core.Record, []*core.Record, error) {
+	dcs, err := app.FindRecordsByFilter("delivery_challans", "shipment_group = {:sgid}", "created,dc_number", 0, 0, map[string]any{"sgid": sgID})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch DCs for shipment group: %w", err)
+	}
+	var transit *core.Record
+	var officials []*core.Record
+	for _, dc := range dcs {
+		switch dc.GetString("dc_type") {
+		case "transit":
+			transit = dc
+		case "official":
+			officials = append(officials, dc)
+		}
+	}
+	if transit == nil {
+		return nil, nil, fmt.Errorf("transit DC not found in shipment group")
+	}
+	return transit, officials, nil
+}
+
+func fillDraftFromDC(draft *DCDraft, dc *core.Record) {
+	draft.ProjectID = dc.GetString("project")
+	draft.TemplateID = dc.GetString("template")
+	draft.ChallanDate = dc.GetString("challan_date")
+	if len(draft.ChallanDate) > 10 {
+		draft.ChallanDate = draft.ChallanDate[:10]
+	}
+	draft.BillFromID = dc.GetString("bill_from_address")
+	draft.DispatchFromID = dc.GetString("dispatch_from_address")
+	draft.BillToID = dc.GetString("bill_to_address")
+	draft.OverDispatchReason = dc.GetString("over_dispatch_reason")
+}
+
+// fillDraftTransport reads transporter details from the DC's transit details,
+// falling back to the given values. Vehicles are stored by number, so the
+// vehicle is matched back to the transporter's fleet.
+func fillDraftTransport(app core.App, draft *DCDraft, dcID, transporterID, vehicleNumber, ewayBill, docket string) {
+	details, _ := app.FindRecordsByFilter("dc_transit_details", "dc = {:did}", "", 1, 0, map[string]any{"did": dcID})
+	if len(details) > 0 {
+		transporterID = details[0].GetString("transporter")
+		vehicleNumber = details[0].GetString("vehicle_number")
+		ewayBill = details[0].GetString("eway_bill_number")
+		docket = details[0].GetString("docket_number")
+	}
+	draft.TransporterID = transporterID
+	draft.EwayBillNumber = ewayBill
+	draft.DocketNumber = docket
+	if transporterID != "" && vehicleNumber != "" {
+		vehicles, _ := app.FindRecordsByFilter("transporter_vehicles",
+			"transporter = {:tid} && vehicle_number = {:num}", "", 1, 0,
+			map[string]any{"tid": transporterID, "num": vehicleNumber})
+		if len(vehicles) > 0 {
+			draft.VehicleID = vehicles[0].Id
+		}
+	}
+}
+
+// draftItemsFromDC reads the priced line items and serials of a DC.
+func draftItemsFromDC(app core.App, dcID string) ([]ShipmentItemParams, error) {
+	lines, err := app.FindRecordsByFilter("dc_line_items", "dc = {:did}", "line_order", 0, 0, map[string]any{"did": dcID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch line items: %w", err)
+	}
+	items := make([]ShipmentItemParams, 0, len(lines))
+	for _, li := range lines {
+		item := ShipmentItemParams{
+			SourceItemType: li.GetString("source_item_type"),
+			SourceItemID:   li.GetString("source_item_id"),
+			Rate:           li.GetFloat("rate"),
+			TaxPercentage:  li.GetFloat("tax_percentage"),
+			TotalQty:       li.GetInt("quantity"),
+		}
+		serials, _ := app.FindRecordsByFilter("serial_numbers", "line_item = {:lid}", "created", 0, 0, map[string]any{"lid": li.Id})
+		for _, s := range serials {
+			item.Serials = append(item.Serials, s.GetString("serial_number"))
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func setDraftQuantity(items []ShipmentItemParams, sourceType, sourceID string, destIdx, numDest, qty int) {
+	for i := range items {
+		if items[i].SourceItemType != sourceType || items[i].SourceItemID != sourceID {
+			continue
+		}
+		if len(items[i].Quantities) < numDest {
+			quantities := make([]int, numDest)
+			copy(quantities, items[i].Quantities)
+			items[i].Quantities = quantities
+		}
+		items[i].Quantities[destIdx] = qty
+		return
+	}
+}
+
+// UpdateDirectShipment rewrites a draft shipment group from wizard values.
+// The transit DC and official DCs keep their numbers: an official DC stays
+// with its ship-to address where possible, destinations that no longer have
+// any quantity drop their DC, and only new destinations allocate a number.
+// The shipment must belong to params.ProjectID.
+func UpdateDirectShipment(ctx context.Context, app *pocketbase.PocketBase, transitDCID string, params ShipmentParams) (*ShipmentResult, error) {
+	transitDC, err := app.FindRecordById("delivery_challans", transitDCID)
+	if err != nil {
+		return nil, fmt.Errorf("transit DC not found: %w", err)
+	}
+	if transitDC.GetString("status") != "draft" {
+		return nil, fmt.Errorf("only draft DCs can be edited")
+	}
+	if transitDC.GetString("project") != params.ProjectID {
+		return nil, fmt.Errorf("DC %s does not belong to this project", transitDC.GetString("dc_number"))
+	}
+	sg, err := app.FindRecordById("shipment_groups", transitDC.GetString("shipment_group"))
+	if err != nil {
+		return nil, fmt.Errorf("shipment group not found: %w", err)
+	}
+	if sg.GetString("status") != "draft" {
+		return nil, fmt.Errorf("only draft shipment groups can be edited")
+	}
+	if sg.GetString("project") != params.ProjectID {
+		return nil, fmt.Errorf("shipment group of DC %s does not belong to this project", transitDC.GetString("dc_number"))
+	}
+	_, officials, err := shipmentGroupDCs(app, sg.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	docDate, err := time.Parse("2006-01-02", params.ChallanDate)
+	if err != nil {
+		docDate = time.Now()
+	}
+
+	// Match existing official DCs to destinations by ship-to address first,
+	// then hand out the rest in order.
+	type destination struct {
+		index  int
+		shipTo string
+		odc    *core.Record
+		number string
+	}
+	var dests []*destination
+	for destIdx, shipToID := range params.ShipToIDs {
+		if shipToID == "" || !destinationHasQty(params.Items, destIdx) {
+			continue
+		}
+		dests = append(dests, &destination{index: destIdx, shipTo: shipToID})
+	}
+
+	used := make(map[string]bool)
+	for _, d := range dests {
+		for _, odc := range officials {
+			if !used[odc.Id] && odc.GetString("ship_to_address") == d.shipTo {
+				d.odc = odc
+				used[odc.Id] = true
+				break
+			}
+		}
+	}
+	var spare []*core.Record
+	for _, odc := range officials {
+		if !used[odc.Id] {
+			spare = append(spare, odc)
+		}
+	}
+	for _, d := range dests {
+		if d.odc == nil && len(spare) > 0 {
+			d.odc = spare[0]
+			spare = spare[1:]
+		}
+	}
+
+	// Numbers for new destinations are allocated before the transaction,
+	// as the numbering sequence runs its own.
+	for _, d := range dests {
+		if d.odc != nil {
+			continue
+		}
+		if d.number, err = NextDocNumber(app, params.ProjectID, "odc", docDate); err != nil {
+			return nil, fmt.Errorf("failed to generate official DC number: %w", err)
+		}
+	}
+
+	result := &ShipmentResult{
+		ShipmentGroupID: sg.Id,
+		TransitDCID:     transitDC.Id,
+		TransitDCNumber: transitDC.GetString("dc_number"),
+	}
+	err = app.RunInTransaction(func(txApp core.App) error {
+		// 1. Shipment group
+		sg.Set("template", params.TemplateID)
+		sg.Set("num_locations", len(params.ShipToIDs))
+		sg.Set("tax_type", params.TaxType)
+		sg.Set("reverse_charge", params.ReverseCharge)
+		if err := txApp.SaveWithContext(ctx, sg); err != nil {
+			return fmt.Errorf("failed to update shipment group: %w", err)
+		}
+
+		// 2. Transit DC, its priced line items and transit details
+		shipTo := ""
+		if len(params.ShipToIDs) > 0 {
+			shipTo = params.ShipToIDs[0]
+		}
+		setDraftDCFields(transitDC, params.TemplateID, params.BillFromID, params.DispatchFromID, params.BillToID, shipTo, params.ChallanDate)
+		transitDC.Set("over_dispatch_reason", params.OverDispatchReason)
+		if err := txApp.SaveWithContext(ctx, transitDC); err != nil {
+			return fmt.Errorf("failed to update transit DC: %w", err)
+		}
+		if err := replaceDCLineItems(ctx, txApp, transitDC.Id, params.ProjectID, params.Items, -1); err != nil {
+			return err
+		}
+		if err := saveDraftTransitDetails(ctx, txApp, transitDC.Id, params.TransporterID, params.VehicleID, params.EwayBillNumber, params.DocketNumber); err != nil {
+			return err
+		}
+
+		// 3. Official DCs — those without a destination any more are removed
+		for _, odc := range spare {
+			if err := deleteDraftDC(ctx, txApp, odc); err != nil {
+				return err
+			}
+		}
+
+		dcCol := transitDC.Collection()
+		for _, d := range dests {
+			odc := d.odc
+			if odc == nil {
+				odc = core.NewRecord(dcCol)
+				odc.Set("project", params.ProjectID)
+				odc.Set("dc_number", d.number)
+				odc.Set("dc_type", "official")
+				odc.Set("status", "draft")
+				odc.Set("shipment_group", sg.Id)
+			}
+			setDraftDCFields(odc, params.TemplateID, params.BillFromID, params.DispatchFromID, params.BillToID, d.shipTo, params.ChallanDate)
+			if err := txApp.SaveWithContext(ctx, odc); err != nil {
+				return fmt.Errorf("failed to save official DC: %w", err)
+			}
+			if err := replaceDCLineItems(ctx, txApp, odc.Id, params.ProjectID, params.Items, d.index); err != nil {
+				return err
+			}
+			result.OfficialDCIDs = append(result.OfficialDCIDs, odc.Id)
+			result.OfficialDCNums = append(result.OfficialDCNums, odc.GetString("dc_number"))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// UpdateTransferDC rewrites a draft transfer DC from wizard values, keeping
+// its DC number. The destination plan is rebuilt from scratch. The DC must
+// belong to params.ProjectID.
+func UpdateTransferDC(ctx context.Context, app *pocketbase.PocketBase, dcID string, params TransferDCParams) (*TransferDCResult, error) {
+	var result *TransferDCResult
+	err := app.RunInTransaction(func(txApp core.App) error {
+		dc, err := txApp.FindRecordById("delivery_challans", dcID)
+		if err != nil {
+			return fmt.Errorf("transfer DC not found: %w", err)
+		}
+		if dc.GetString("dc_type") != "transfer" || dc.GetString("status") != "draft" {
+			return fmt.Errorf("only draft transfer DCs can be edited")
+		}
+		if dc.GetString("project") != params.ProjectID {
+			return fmt.Errorf("DC %s does not belong to this project", dc.GetString("dc_number"))
+		}
+		transferRecs, _ := txApp.FindRecordsByFilter("transfer_dcs", "dc = {:did}", "", 1, 0, map[string]any{"did": dc.Id})
+		if len(transferRecs) == 0 {
+			return fmt.Errorf("transfer DC metadata not found")
+		}
+		transferDC := transferRecs[0]
+
+		// 1. DC, line items and transit details
+		shipTo := ""
+		if len(params.ShipToIDs) > 0 {
+			shipTo = params.ShipToIDs[0]
+		}
+		setDraftDCFields(dc, params.TemplateID, params.BillFromID, params.DispatchFromID, params.BillToID, shipTo, params.ChallanDate)
+		dc.Set("over_dispatch_reason", params.OverDispatchReason)
+		if err := txApp.SaveWithContext(ctx, dc); err != nil {
+			return fmt.Errorf("failed to update transfer DC: %w", err)
+		}
+		if err := replaceDCLineItems(ctx, txApp, dc.Id, params.ProjectID, params.Items, -1); err != nil {
+			return err
+		}
+		if err := saveDraftTransitDetails(ctx, txApp, dc.Id, params.TransporterID, params.VehicleID, params.EwayBillNumber, params.DocketNumber); err != nil {
+			return err
+		}
+
+		// 2. Transfer DC metadata
+		transferDC.Set("hub_address", params.HubAddressID)
+		transferDC.Set("template", params.TemplateID)
+		transferDC.Set("tax_type", params.TaxType)
+		transferDC.Set("reverse_charge", params.ReverseCharge)
+		transferDC.Set("transporter", params.TransporterID)
+		transferDC.Set("vehicle_number", vehicleNumberForID(txApp, params.VehicleID))
+		transferDC.Set("eway_bill_number", params.EwayBillNumber)
+		transferDC.Set("docket_number", params.DocketNumber)
+		transferDC.Set("num_destinations", len(params.ShipToIDs))
+		if err := txApp.SaveWithContext(ctx, transferDC); err != nil {
+			return fmt.Errorf("failed to update transfer DC metadata: %w", err)
+		}
+
+		// 3. Destination plan
+		oldDests, _ := txApp.FindRecordsByFilter("transfer_dc_destinations", "transfer_dc = {:tid}", "", 0, 0, map[string]any{"tid": transferDC.Id})
+		for _, dest := range oldDests {
+			qtys, _ := txApp.FindRecordsByFilter("transfer_dc_dest_quantities", "destination = {:did}", "", 0, 0, map[string]any{"did": dest.Id})
+			for _, q := range qtys {
+				if err := txApp.DeleteWithContext(ctx, q); err != nil {
+					return fmt.Errorf("failed to remove destination quantity: %w", err)
+				}
+			}
+			if err := txApp.DeleteWithContext(ctx, dest); err != nil {
+				return fmt.Errorf("failed to remove destination: %w", err)
+			}
+		}
+
+		destCol, err := txApp.FindCollectionByNameOrId("transfer_dc_destinations")
+		if err != nil {
+			return fmt.Errorf("transfer_dc_destinations collection not found: %w", err)
+		}
+		destQtyCol, err := txApp.FindCollectionByNameOrId("transfer_dc_dest_quantities")
+		if err != nil {
+			return fmt.Errorf("transfer_dc_dest_quantities collection not found: %w", err)
+		}
+		for destIdx, shipToID := range params.ShipToIDs {
+			if shipToID == "" {
+				continue
+			}
+			destRec := core.NewRecord(destCol)
+			destRec.Set("transfer_dc", transferDC.Id)
+			destRec.Set("ship_to_address", shipToID)
+			destRec.Set("is_split", false)
+			if err := txApp.SaveWithContext(ctx, destRec); err != nil {
+				return fmt.Errorf("failed to create transfer DC destination: %w", err)
+			}
+			for _, item := range params.Items {
+				if destIdx >= len(item.Quantities) || item.Quantities[destIdx] == 0 {
+					continue
+				}
+				destQty := core.NewRecord(destQtyCol)
+				destQty.Set("destination", destRec.Id)
+				destQty.Set("source_item_type", item.SourceItemType)
+				destQty.Set("source_item_id", item.SourceItemID)
+				destQty.Set("quantity", item.Quantities[destIdx])
+				if err := txApp.SaveWithContext(ctx, destQty); err != nil {
+					return fmt.Errorf("failed to create destination quantity: %w", err)
+				}
+			}
+		}
+
+		result = &TransferDCResult{DCID: dc.Id, DCNumber: dc.GetString("dc_number")}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func setDraftDCFields(dc *core.Record, templateID, billFromID, dispatchFromID, billToID, shipToID, challanDate string) {
+	dc.Set("template", templateID)
+	dc.Set("bill_from_address", billFromID)
+	dc.Set("dispatch_from_address", dispatchFromID)
+	dc.Set("bill_to_address", billToID)
+	dc.Set("ship_to_address", shipToID)
+	dc.Set("challan_date", challanDate)
+}
+
+func destinationHasQty(items []ShipmentItemParams, destIdx int) bool {
+	for _, item := range items {
+		if destIdx < len(item.Quantities) && item.Quantities[destIdx] > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func vehicleNumberForID(app core.App, vehicleID string) string {
+	if vehicleID == "" {
+		return ""
+	}
+	if vRec, err := app.FindRecordById("transporter_vehicles", vehicleID); err == nil {
+		return vRec.GetString("vehicle_number")
+	}
+	return ""
+}
+
+// replaceDCLineItems drops a DC's line items (and their serials) and writes
+// new ones. destIdx -1 writes the priced totals with serials, as on transit
+// and transfer DCs; otherwise only that destination's quantities are written,
+// as on official DCs.
+func replaceDCLineItems(ctx context.Context, app core.App, dcID, projectID string, items []ShipmentItemParams, destIdx int) error {
+	if err := deleteDCLineItems(ctx, app, dcID); err != nil {
+		return err
+	}
+
+	lineItemCol, err := app.FindCollectionByNameOrId("dc_line_items")
+	if err != nil {
+		return fmt.Errorf("dc_line_items collection not found: %w", err)
+	}
+	serialCol, err := app.FindCollectionByNameOrId("serial_numbers")
+	if err != nil {
+		return fmt.Errorf("serial_numbers collection not found: %w", err)
+	}
+
+	for i, item := range items {
+		lineItem := core.NewRecord(lineItemCol)
+		lineItem.Set("dc", dcID)
+		lineItem.Set("source_item_type", item.SourceItemType)
+		lineItem.Set("source_item_id", item.SourceItemID)
+		lineItem.Set("line_order", i+1)
+
+		if destIdx >= 0 {
+			if destIdx >= len(item.Quantities) || item.Quantities[destIdx] == 0 {
+				continue
+			}
+			lineItem.Set("quantity", item.Quantities[destIdx])
+			if err := app.SaveWithContext(ctx, lineItem); err != nil {
+				return fmt.Errorf("failed to save official DC line item: %w", err)
+			}
+			continue
+		}
+
+		if item.TotalQty == 0 {
+			continue
+		}
+		taxableAmount := item.Rate * float64(item.TotalQty)
+		taxAmount := taxableAmount * item.TaxPercentage / 100.0
+		lineItem.Set("quantity", item.TotalQty)
+		lineItem.Set("rate", item.Rate)
+		lineItem.Set("tax_percentage", item.TaxPercentage)
+		lineItem.Set("taxable_amount", taxableAmount)
+		lineItem.Set("tax_amount", taxAmount)
+		lineItem.Set("total_amount", taxableAmount+taxAmount)
+		if err := app.SaveWithContext(ctx, lineItem); err != nil {
+			return fmt.Errorf("failed to save DC line item: %w", err)
+		}
+
+		for _, serial := range item.Serials {
+			serialRec := core.NewRecord(serialCol)
+			serialRec.Set("project", projectID)
+			serialRec.Set("line_item", lineItem.Id)
+			serialRec.Set("serial_number", serial)
+			if err := app.SaveWithContext(ctx, serialRec); err != nil {
+				return fmt.Errorf("failed to save serial number %s: %w", serial, err)
+			}
+		}
+	}
+	return nil
+}
+
+func deleteDCLineItems(ctx context.Context, app core.App, dcID string) error {
+	lineItems, _ := app.FindRecordsByFilter("dc_line_items", "dc = {:did}", "", 0, 0, map[string]any{"did": dcID})
+	for _, li := range lineItems {
+		serials, _ := app.FindRecordsByFilter("serial_numbers", "line_item = {:lid}", "", 0, 0, map[string]any{"lid": li.Id})
+		for _, s := range serials {
+			if err := app.DeleteWithContext(ctx, s); err != nil {
+				return fmt.Errorf("failed to remove serial number: %w", err)
+			}
+		}
+		if err := app.DeleteWithContext(ctx, li); err != nil {
+			return fmt.Errorf("failed to remove line item: %w", err)
+		}
+	}
+	return nil
+}
+
+// saveDraftTransitDetails updates, creates or removes a DC's transit details
+// so they match the wizard values.
+func saveDraftTransitDetails(ctx context.Context, app core.App, dcID, transporterID, vehicleID, ewayBill, docket string) error {
+	details, _ := app.FindRecordsByFilter("dc_transit_details", "dc = {:did}", "", 0, 0, map[string]any{"did": dcID})
+
+	if transporterID == "" && ewayBill == "" && docket == "" {
+		for _, d := range details {
+			if err := app.DeleteWithContext(ctx, d); err != nil {
+				return fmt.Errorf("failed to remove transit details: %w", err)
+			}
+		}
+		return nil
+	}
+
+	var detail *core.Record
+	if len(details) > 0 {
+		detail = details[0]
+	} else {
+		col, err := app.FindCollectionByNameOrId("dc_transit_details")
+		if err != nil {
+			return fmt.Errorf("dc_transit_details collection not found: %w", err)
+		}
+		detail = core.NewRecord(col)
+		detail.Set("dc", dcID)
+	}
+	detail.Set("transporter", transporterID)
+	detail.Set("vehicle_number", vehicleNumberForID(app, vehicleID))
+	detail.Set("eway_bill_number", ewayBill)
+	detail.Set("docket_number", docket)
+	if err := app.SaveWithContext(ctx, detail); err != nil {
+		return fmt.Errorf("failed to save transit details: %w", err)
+	}
+	return nil
+}
+
+func deleteDraftDC(ctx context.Context, app core.App, dc *core.Record) error {
+	if err := deleteDCLineItems(ctx, app, dc.Id); err != nil {
+		return err
+	}
+	details, _ := app.FindRecordsByFilter("dc_transit_details", "dc = {:did}", "", 0, 0, map[string]any{"did": dc.Id})
+	for _, d := range details {
+		if err := app.DeleteWithContext(ctx, d); err != nil {
+			return fmt.Errorf("failed to remove transit details: %w", err)
+		}
+	}
+	if err := app.DeleteWithContext(ctx, dc); err != nil {
+		return fmt.Errorf("failed to remove official DC %s: %w", dc.GetString("dc_number"), err)
+	}
+	return nil
+}
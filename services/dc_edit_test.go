@@ -0,0 +1,255 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"projectcreation/testhelpers"
+)
+
+func TestUpdateDirectShipment_KeepsDCNumbers(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Edit Project")
+	billFrom := testhelpers.CreateTestAddress(t, app, project.Id, "bill_from", "Head Office")
+	siteA := testhelpers.CreateTestAddress(t, app, project.Id, "ship_to", "Site A")
+	siteB := testhelpers.CreateTestAddress(t, app, project.Id, "ship_to", "Site B")
+	siteC := testhelpers.CreateTestAddress(t, app, project.Id, "ship_to", "Site C")
+	transporter := testhelpers.CreateTestTransporter(t, app, project.Id, "Fast Freight")
+	vehicle := testhelpers.CreateTestVehicle(t, app, transporter.Id, "KA01AB1234")
+	ctx := context.Background()
+
+	created, err := CreateDirectShipment(ctx, app, ShipmentParams{
+		ProjectID:     project.Id,
+		ChallanDate:   "2026-04-10",
+		TransporterID: transporter.Id,
+		VehicleID:     vehicle.Id,
+		BillFromID:    billFrom.Id,
+		ShipToIDs:     []string{siteA.Id, siteB.Id},
+		TaxType:       "cgst_sgst",
+		Items: []ShipmentItemParams{{
+			SourceItemType: "sub_item", SourceItemID: "item1", Rate: 100, TaxPercentage: 18,
+			Quantities: []int{1, 2}, TotalQty: 3, Serials: []string{"SN-1", "SN-2", "SN-3"},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("CreateDirectShipment failed: %v", err)
+	}
+
+	// Any DC of the group opens the whole shipment
+	draft, err := LoadDCDraft(app, created.OfficialDCIDs[1])
+	if err != nil {
+		t.Fatalf("LoadDCDraft failed: %v", err)
+	}
+	if draft.DCID != created.TransitDCID || draft.DCType != "direct" {
+		t.Errorf("expected transit DC %s (direct), got %s (%s)", created.TransitDCID, draft.DCID, draft.DCType)
+	}
+	if !slices.Equal(draft.ShipToIDs, []string{siteA.Id, siteB.Id}) {
+		t.Errorf("unexpected ship-to IDs: %v", draft.ShipToIDs)
+	}
+	if draft.VehicleID != vehicle.Id || draft.ChallanDate != "2026-04-10" {
+		t.Errorf("expected vehicle %s and date 2026-04-10, got %s and %s", vehicle.Id, draft.VehicleID, draft.ChallanDate)
+	}
+	if len(draft.Items) != 1 || !slices.Equal(draft.Items[0].Quantities, []int{1, 2}) || len(draft.Items[0].Serials) != 3 {
+		t.Fatalf("unexpected draft items: %+v", draft.Items)
+	}
+
+	// Drop Site A, keep Site B and add Site C
+	updated, err := UpdateDirectShipment(ctx, app, draft.DCID, ShipmentParams{
+		ProjectID:   project.Id,
+		ChallanDate: "2026-04-12",
+		BillFromID:  billFrom.Id,
+		ShipToIDs:   []string{siteB.Id, siteC.Id},
+		TaxType:     "cgst_sgst",
+		Items: []ShipmentItemParams{{
+			SourceItemType: "sub_item", SourceItemID: "item1", Rate: 100, TaxPercentage: 18,
+			Quantities: []int{2, 2}, TotalQty: 4, Serials: []string{"SN-2", "SN-3", "SN-4", "SN-5"},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("UpdateDirectShipment failed: %v", err)
+	}
+
+	if updated.TransitDCNumber != created.TransitDCNumber {
+		t.Errorf("transit DC number changed: %s -> %s", created.TransitDCNumber, updated.TransitDCNumber)
+	}
+	if len(updated.OfficialDCIDs) != 2 || updated.OfficialDCIDs[0] != created.OfficialDCIDs[1] {
+		t.Fatalf("expected Site B to keep official DC %s, got %v", created.OfficialDCIDs[1], updated.OfficialDCIDs)
+	}
+	if updated.OfficialDCNums[0] != created.OfficialDCNums[1] {
+		t.Errorf("Site B DC number changed: %s -> %s", created.OfficialDCNums[1], updated.OfficialDCNums[0])
+	}
+	// Site A's number is reused for Site C rather than allocating a new one
+	if updated.OfficialDCNums[1] != created.OfficialDCNums[0] {
+		t.Errorf("expected Site C to reuse %s, got %s", created.OfficialDCNums[0], updated.OfficialDCNums[1])
+	}
+
+	reloaded, err := LoadDCDraft(app, updated.TransitDCID)
+	if err != nil {
+		t.Fatalf("LoadDCDraft after update failed: %v", err)
+	}
+	if reloaded.ChallanDate != "2026-04-12" || reloaded.TransporterID != "" {
+		t.Errorf("expected new date and no transporter, got %s / %q", reloaded.ChallanDate, reloaded.TransporterID)
+	}
+	if !slices.Equal(reloaded.Items[0].Serials, []string{"SN-2", "SN-3", "SN-4", "SN-5"}) {
+		t.Errorf("unexpected serials after update: %v", reloaded.Items[0].Serials)
+	}
+	details, _ := app.FindRecordsByFilter("dc_transit_details", "dc = {:did}", "", 0, 0, map[string]any{"did": updated.TransitDCID})
+	if len(details) != 0 {
+		t.Errorf("expected transit details to be removed, got %d", len(details))
+	}
+}
+
+func TestUpdateTransferDC_KeepsDCNumber(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Edit Project")
+	hub := testhelpers.CreateTestAddress(t, app, project.Id, "ship_to", "Hub")
+	newHub := testhelpers.CreateTestAddress(t, app, project.Id, "ship_to", "New Hub")
+	siteA := testhelpers.CreateTestAddress(t, app, project.Id, "ship_to", "Site A")
+	siteB := testhelpers.CreateTestAddress(t, app, project.Id, "ship_to", "Site B")
+	ctx := context.Background()
+
+	created, err := CreateTransferDC(ctx, app, TransferDCParams{
+		ProjectID:    project.Id,
+		ChallanDate:  "2026-04-10",
+		ShipToIDs:    []string{siteA.Id},
+		HubAddressID: hub.Id,
+		TaxType:      "igst",
+		Items: []ShipmentItemParams{{
+			SourceItemType: "sub_item", SourceItemID: "item1", Rate: 50,
+			Quantities: []int{5}, TotalQty: 5,
+		}},
+	})
+	if err != nil {
+		t.Fatalf("CreateTransferDC failed: %v", err)
+	}
+
+	updated, err := UpdateTransferDC(ctx, app, created.DCID, TransferDCParams{
+		ProjectID:    project.Id,
+		ChallanDate:  "2026-04-11",
+		ShipToIDs:    []string{siteA.Id, siteB.Id},
+		HubAddressID: newHub.Id,
+		TaxType:      "igst",
+		Items: []ShipmentItemParams{{
+			SourceItemType: "sub_item", SourceItemID: "item1", Rate: 50,
+			Quantities: []int{3, 4}, TotalQty: 7,
+		}},
+	})
+	if err != nil {
+		t.Fatalf("UpdateTransferDC failed: %v", err)
+	}
+	if updated.DCID != created.DCID || updated.DCNumber != created.DCNumber {
+		t.Errorf("expected DC %s (%s) to be kept, got %s (%s)", created.DCID, created.DCNumber, updated.DCID, updated.DCNumber)
+	}
+
+	draft, err := LoadDCDraft(app, created.DCID)
+	if err != nil {
+		t.Fatalf("LoadDCDraft failed: %v", err)
+	}
+	if draft.DCType != "transfer" || draft.HubAddressID != newHub.Id {
+		t.Errorf("expected transfer draft with new hub, got %s / %s", draft.DCType, draft.HubAddressID)
+	}
+	if !slices.Equal(draft.ShipToIDs, []string{siteA.Id, siteB.Id}) {
+		t.Errorf("unexpected ship-to IDs: %v", draft.ShipToIDs)
+	}
+	if len(draft.Items) != 1 || draft.Items[0].TotalQty != 7 || !slices.Equal(draft.Items[0].Quantities, []int{3, 4}) {
+		t.Errorf("unexpected draft items: %+v", draft.Items)
+	}
+
+	dcs, _ := app.FindRecordsByFilter("delivery_challans", "project = {:pid}", "", 0, 0, map[string]any{"pid": project.Id})
+	if len(dcs) != 1 {
+		t.Errorf("expected no extra DCs, got %d", len(dcs))
+	}
+}
+
+func TestLoadDCDraft_RejectsIssued(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Edit Project")
+	dc := testhelpers.CreateTestDeliveryChallan(t, app, project.Id, "TDC-001", "transfer", "issued")
+
+	if _, err := LoadDCDraft(app, dc.Id); err == nil {
+		t.Fatal("expected error for an issued DC")
+	}
+}
+
+func TestUpdateTransferDC_RollsBackOnError(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Edit Project")
+	hub := testhelpers.CreateTestAddress(t, app, project.Id, "ship_to", "Hub")
+	siteA := testhelpers.CreateTestAddress(t, app, project.Id, "ship_to", "Site A")
+	ctx := context.Background()
+
+	items := []ShipmentItemParams{{
+		SourceItemType: "sub_item", SourceItemID: "item1", Rate: 50,
+		Quantities: []int{5}, TotalQty: 5,
+	}}
+	created, err := CreateTransferDC(ctx, app, TransferDCParams{
+		ProjectID:    project.Id,
+		ChallanDate:  "2026-04-10",
+		ShipToIDs:    []string{siteA.Id},
+		HubAddressID: hub.Id,
+		TaxType:      "igst",
+		Items:        items,
+	})
+	if err != nil {
+		t.Fatalf("CreateTransferDC failed: %v", err)
+	}
+
+	// The second destination points at an address that does not exist, so
+	// the update fails after the DC itself has been rewritten
+	_, err = UpdateTransferDC(ctx, app, created.DCID, TransferDCParams{
+		ProjectID:    project.Id,
+		ChallanDate:  "2026-04-11",
+		ShipToIDs:    []string{siteA.Id, "missingaddress1"},
+		HubAddressID: hub.Id,
+		TaxType:      "igst",
+		Items:        items,
+	})
+	if err == nil {
+		t.Fatal("expected the update to fail")
+	}
+
+	draft, err := LoadDCDraft(app, created.DCID)
+	if err != nil {
+		t.Fatalf("LoadDCDraft failed: %v", err)
+	}
+	if draft.ChallanDate != "2026-04-10" || !slices.Equal(draft.ShipToIDs, []string{siteA.Id}) {
+		t.Errorf("expected the draft left unchanged, got date %s and ship-to %v", draft.ChallanDate, draft.ShipToIDs)
+	}
+}
+
+func TestUpdateTransferDC_RejectsAnotherProject(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Edit Project")
+	other := testhelpers.CreateTestProject(t, app, "Other Project")
+	hub := testhelpers.CreateTestAddress(t, app, other.Id, "ship_to", "Hub")
+	site := testhelpers.CreateTestAddress(t, app, other.Id, "ship_to", "Site A")
+	ctx := context.Background()
+
+	params := TransferDCParams{
+		ProjectID:    other.Id,
+		ChallanDate:  "2026-04-10",
+		ShipToIDs:    []string{site.Id},
+		HubAddressID: hub.Id,
+		TaxType:      "igst",
+		Items: []ShipmentItemParams{{
+			SourceItemType: "sub_item", SourceItemID: "item1", Rate: 50,
+			Quantities: []int{5}, TotalQty: 5,
+		}},
+	}
+	created, err := CreateTransferDC(ctx, app, params)
+	if err != nil {
+		t.Fatalf("CreateTransferDC failed: %v", err)
+	}
+
+	params.ProjectID = project.Id
+	params.ChallanDate = "2026-04-11"
+	if _, err := UpdateTransferDC(ctx, app, created.DCID, params); err == nil {
+		t.Fatal("expected another project's transfer DC to be rejected")
+	}
+	dc, _ := app.FindRecordById("delivery_challans", created.DCID)
+	if !strings.HasPrefix(dc.GetString("challan_date"), "2026-04-10") {
+		t.Errorf("expected the transfer DC unchanged, got challan date %s", dc.GetString("challan_date"))
+	}
+}
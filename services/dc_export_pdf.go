@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/johnfercher/maroto/v2"
+	"github.com/johnfercher/maroto/v2/pkg/components/code"
 	"github.com/johnfercher/maroto/v2/pkg/components/col"
 	"github.com/johnfercher/maroto/v2/pkg/components/row"
 	"github.com/johnfercher/maroto/v2/pkg/components/text"
@@ -55,10 +56,11 @@ type DCExportData struct {
 	LogoBytes    []byte
 	LogoFilename string
 
-	DCNumber    string
-	DCType      string // transit, official, transfer
-	Status      string
-	ChallanDate string
+	DCNumber     string
+	DCType       string // transit, official, transfer
+	Status       string
+	ChallanDate  string
+	CancelReason string
 
 	BillFrom     *DCExportAddress
 	DispatchFrom *DCExportAddress
@@ -75,6 +77,10 @@ type DCExportData struct {
 	// Transfer DC specific
 	HubAddress   string
 	Destinations []string // destination names for transfer DCs
+
+	// VerifyQR is the content of the verification QR code; none is printed
+	// when it is empty.
+	VerifyQR string
 }
 
 // GenerateDCPDF creates a PDF document for a Delivery Challan.
@@ -95,6 +101,12 @@ func GenerateDCPDF(data *DCExportData) ([]byte, error) {
 
 	m := maroto.New(cfg)
 
+	if data.Status == "cancelled" {
+		if err := addDCCancelledStamp(m, data); err != nil {
+			return nil, fmt.Errorf("failed to add cancelled stamp: %w", err)
+		}
+	}
+
 	addDCHeader(m, data)
 	addDCAddresses(m, data)
 	addDCTransport(m, data)
@@ -109,6 +121,9 @@ func GenerateDCPDF(data *DCExportData) ([]byte, error) {
 	}
 
 	addDCSerials(m, data)
+	if data.VerifyQR != "" {
+		addVerifyQR(m, data.VerifyQR)
+	}
 	addDCSignatures(m)
 
 	doc, err := m.Generate()
@@ -119,6 +134,36 @@ func GenerateDCPDF(data *DCExportData) ([]byte, error) {
 	return doc.GetBytes(), nil
 }
 
+// addDCCancelledStamp registers a page header so every page of a cancelled
+// DC carries a CANCELLED stamp and the cancellation reason.
+func addDCCancelledStamp(m core.Maroto, data *DCExportData) error {
+	red := &props.Color{Red: 220, Green: 38, Blue: 38}
+	rows := []core.Row{
+		row.New(14).Add(
+			col.New(12).Add(
+				text.New("CANCELLED", props.Text{
+					Size:  28,
+					Style: fontstyle.Bold,
+					Align: align.Center,
+					Color: red,
+				}),
+			),
+		),
+	}
+	if data.CancelReason != "" {
+		rows = append(rows, row.New(6).Add(
+			col.New(12).Add(
+				text.New("Reason: "+data.CancelReason, props.Text{
+					Size:  8,
+					Align: align.Center,
+					Color: red,
+				}),
+			),
+		))
+	}
+	return m.RegisterHeader(rows...)
+}
+
 func addDCHeader(m core.Maroto, data *DCExportData) {
 	// Company name + DC title
 	m.AddRows(
@@ -554,3 +599,29 @@ func addDCSignatures(m core.Maroto) {
 		col.New(6).Add(text.New("Authorized Signatory", labelStyle)),
 	))
 }
+
+// addVerifyQR prints a document's verification QR code with a short
+// instruction beside it.
+func addVerifyQR(m core.Maroto, content string) {
+	m.AddRows(row.New(4))
+	m.AddRows(row.New(28).Add(
+		code.NewQrCol(2, content, props.Rect{Percent: 100, Center: true}),
+		col.New(10).Add(
+			text.New("SCAN TO VERIFY", props.Text{
+				Size:  8,
+				Style: fontstyle.Bold,
+				Align: align.Left,
+				Left:  3,
+				Top:   8,
+				Color: &props.Color{Red: 100, Green: 100, Blue: 100},
+			}),
+			text.New("Scan this code to check that the document is genuine and whether it has been cancelled.", props.Text{
+				Size:  7,
+				Align: align.Left,
+				Left:  3,
+				Top:   13,
+				Color: &props.Color{Red: 120, Green: 120, Blue: 120},
+			}),
+		),
+	))
+}
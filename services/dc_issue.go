@@ -1,14 +1,16 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"time"
 
 	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
 )
 
 // IssueShipmentGroup validates and issues all DCs in a shipment group atomically.
-func IssueShipmentGroup(app *pocketbase.PocketBase, groupID string) error {
+func IssueShipmentGroup(ctx context.Context, app *pocketbase.PocketBase, groupID string) error {
 	// Fetch shipment group
 	sg, err := app.FindRecordById("shipment_groups", groupID)
 	if err != nil {
@@ -32,13 +34,24 @@ func IssueShipmentGroup(app *pocketbase.PocketBase, groupID string) error {
 		}
 	}
 
+	// A split forwards the serials of its transfer DC, so those are not a
+	// conflict
+	var transferDCID string
+	if tdcID := sg.GetString("transfer_dc"); tdcID != "" {
+		transferDC, err := app.FindRecordById("transfer_dcs", tdcID)
+		if err != nil {
+			return fmt.Errorf("transfer DC metadata not found: %w", err)
+		}
+		transferDCID = transferDC.GetString("dc")
+	}
+
 	// Validate serial numbers for transit DC
 	projectID := sg.GetString("project")
 	for _, dc := range dcs {
 		if dc.GetString("dc_type") != "transit" {
 			continue
 		}
-		if err := validateDCSerials(app, dc.Id, projectID); err != nil {
+		if err := validateDCSerials(app, dc.Id, projectID, transferDCID); err != nil {
 			return err
 		}
 	}
@@ -48,14 +61,14 @@ func IssueShipmentGroup(app *pocketbase.PocketBase, groupID string) error {
 	for _, dc := range dcs {
 		dc.Set("status", "issued")
 		dc.Set("issued_at", now)
-		if err := app.Save(dc); err != nil {
+		if err := app.SaveWithContext(ctx, dc); err != nil {
 			return fmt.Errorf("failed to issue DC %s: %w", dc.GetString("dc_number"), err)
 		}
 	}
 
 	// Update shipment group status
 	sg.Set("status", "issued")
-	if err := app.Save(sg); err != nil {
+	if err := app.SaveWithContext(ctx, sg); err != nil {
 		return fmt.Errorf("failed to update shipment group status: %w", err)
 	}
 
@@ -63,7 +76,7 @@ func IssueShipmentGroup(app *pocketbase.PocketBase, groupID string) error {
 }
 
 // IssueTransferDC validates and issues a transfer DC.
-func IssueTransferDC(app *pocketbase.PocketBase, dcID string) error {
+func IssueTransferDC(ctx context.Context, app *pocketbase.PocketBase, dcID string) error {
 	dc, err := app.FindRecordById("delivery_challans", dcID)
 	if err != nil {
 		return fmt.Errorf("delivery challan not found: %w", err)
@@ -85,15 +98,16 @@ func IssueTransferDC(app *pocketbase.PocketBase, dcID string) error {
 	now := time.Now().UTC().Format(time.RFC3339)
 	dc.Set("status", "issued")
 	dc.Set("issued_at", now)
-	if err := app.Save(dc); err != nil {
+	if err := app.SaveWithContext(ctx, dc); err != nil {
 		return fmt.Errorf("failed to issue DC: %w", err)
 	}
 
 	return nil
 }
 
-// IssueSingleDC issues a single DC (transit, official, or transfer).
-func IssueSingleDC(app *pocketbase.PocketBase, dcID string) error {
+// IssueSingleDC issues a single DC (transit, official, transfer or
+// returnable).
+func IssueSingleDC(ctx context.Context, app *pocketbase.PocketBase, dcID string) error {
 	dc, err := app.FindRecordById("delivery_challans", dcID)
 	if err != nil {
 		return fmt.Errorf("delivery challan not found: %w", err)
@@ -107,15 +121,15 @@ func IssueSingleDC(app *pocketbase.PocketBase, dcID string) error {
 
 	// If it's part of a shipment group, issue the whole group
 	if sgID := dc.GetString("shipment_group"); sgID != "" {
-		return IssueShipmentGroup(app, sgID)
+		return IssueShipmentGroup(ctx, app, sgID)
 	}
 
 	// Transfer DC
 	if dcType == "transfer" {
-		return IssueTransferDC(app, dcID)
+		return IssueTransferDC(ctx, app, dcID)
 	}
 
-	// Standalone DC (shouldn't happen normally, but handle gracefully)
+	// Standalone DC: a returnable DC
 	projectID := dc.GetString("project")
 	if err := validateDCSerials(app, dcID, projectID); err != nil {
 		return err
@@ -124,78 +138,50 @@ func IssueSingleDC(app *pocketbase.PocketBase, dcID string) error {
 	now := time.Now().UTC().Format(time.RFC3339)
 	dc.Set("status", "issued")
 	dc.Set("issued_at", now)
-	return app.Save(dc)
+	return app.SaveWithContext(ctx, dc)
 }
 
-// validateDCSerials checks that all line items with required serial tracking have correct serial counts.
-func validateDCSerials(app *pocketbase.PocketBase, dcID, projectID string) error {
+// validateDCSerials checks that all line items with required serial tracking
+// have correct serial counts, and that none of the DC's serials is in use on
+// another DC in the project other than parentDCIDs, the DCs it forwards
+// serials from. The checks take a fixed number of queries however many
+// serials the project holds.
+func validateDCSerials(app core.App, dcID, projectID string, parentDCIDs ...string) error {
 	lineItems, err := app.FindRecordsByFilter("dc_line_items", "dc = {:did}", "line_order", 0, 0, map[string]any{"did": dcID})
 	if err != nil {
 		return fmt.Errorf("failed to fetch line items: %w", err)
 	}
 
-	// Get existing serials for uniqueness check (exclude this DC's serials)
-	allSerials, _ := app.FindRecordsByFilter("serial_numbers", "project = {:pid}", "", 0, 0, map[string]any{"pid": projectID})
-	existingSerials := make(map[string]string)
-	thisDCSerials := make(map[string]bool)
+	// This DC's serials, by line
+	serialRecs, err := app.FindRecordsByFilter("serial_numbers", "line_item.dc = {:did}", "created", 0, 0, map[string]any{"did": dcID})
+	if err != nil {
+		return fmt.Errorf("failed to fetch serial numbers: %w", err)
+	}
+	serialsByLine := make(map[string][]string)
+	allSerials := make([]string, 0, len(serialRecs))
+	for _, sr := range serialRecs {
+		serial := sr.GetString("serial_number")
+		serialsByLine[sr.GetString("line_item")] = append(serialsByLine[sr.GetString("line_item")], serial)
+		allSerials = append(allSerials, serial)
+	}
 
-	// First pass: identify serials belonging to this DC
-	for _, li := range lineItems {
-		serialRecs, _ := app.FindRecordsByFilter("serial_numbers", "line_item = {:lid}", "", 0, 0, map[string]any{"lid": li.Id})
-		for _, sr := range serialRecs {
-			thisDCSerials[sr.GetString("serial_number")] = true
-		}
+	// Of those, the ones already in use elsewhere in the project
+	existingSerials, err := FindSerialsInUse(app, projectID, allSerials, append([]string{dcID}, parentDCIDs...)...)
+	if err != nil {
+		return err
 	}
 
-	// Build existing map excluding this DC's serials
-	for _, sr := range allSerials {
-		serial := sr.GetString("serial_number")
-		if thisDCSerials[serial] {
-			continue
-		}
-		lineItemID := sr.GetString("line_item")
-		liRec, err := app.FindRecordById("dc_line_items", lineItemID)
-		if err != nil {
-			existingSerials[serial] = "unknown"
-			continue
-		}
-		otherDCID := liRec.GetString("dc")
-		otherDC, err := app.FindRecordById("delivery_challans", otherDCID)
-		if err != nil {
-			existingSerials[serial] = "unknown"
-			continue
-		}
-		existingSerials[serial] = otherDC.GetString("dc_number")
+	tracking, err := serialTrackingBySource(app, lineItems)
+	if err != nil {
+		return err
 	}
 
 	// Validate each line item
 	for _, li := range lineItems {
 		qty := li.GetInt("quantity")
-		serialRecs, _ := app.FindRecordsByFilter("serial_numbers", "line_item = {:lid}", "", 0, 0, map[string]any{"lid": li.Id})
-
-		var serials []string
-		for _, sr := range serialRecs {
-			serials = append(serials, sr.GetString("serial_number"))
-		}
-
-		// Resolve source item to check serial tracking requirement
-		collection := "sub_items"
-		if li.GetString("source_item_type") == "sub_sub_item" {
-			collection = "sub_sub_items"
-		}
-
-		// Check serial tracking from template item
-		templateItems, _ := app.FindRecordsByFilter("dc_template_items",
-			"source_item_type = {:sit} && source_item_id = {:sid}",
-			"", 1, 0,
-			map[string]any{"sit": li.GetString("source_item_type"), "sid": li.GetString("source_item_id")})
-
-		serialTracking := "none"
-		if len(templateItems) > 0 {
-			serialTracking = templateItems[0].GetString("serial_tracking")
-		}
-		_ = collection // used for potential future lookups
+		serials := serialsByLine[li.Id]
 
+		serialTracking := tracking[li.GetString("source_item_type")+":"+li.GetString("source_item_id")]
 		if serialTracking == "required" && qty > 0 {
 			if len(serials) != qty {
 				return fmt.Errorf("item requires %d serial number(s), but has %d", qty, len(serials))
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// ReturnableDCParams holds the wizard values of a returnable DC: goods sent
+// out on loan to one ship-to address, due back by ExpectedReturnDate.
+// Returnable DCs do not draw on the BOQ dispatch balance.
+type ReturnableDCParams struct {
+	ProjectID          string
+	TemplateID         string
+	ChallanDate        string
+	ExpectedReturnDate string
+	TransporterID      string
+	VehicleID          string
+	EwayBillNumber     string
+	DocketNumber       string
+	BillFromID         string
+	DispatchFromID     string
+	BillToID           string
+	ShipToID           string
+	Items              []ShipmentItemParams
+}
+
+// OutstandingReturn is an issued returnable DC with goods not yet returned.
+type OutstandingReturn struct {
+	DCID               string
+	DCNumber           string
+	ShipToID           string
+	ChallanDate        string
+	ExpectedReturnDate string
+	Dispatched         float64
+	Returned           float64
+	DaysOverdue        int // 0 until the expected return date has passed
+}
+
+// Outstanding is the quantity still to come back.
+func (r OutstandingReturn) Outstanding() float64 {
+	return r.Dispatched - r.Returned
+}
+
+// CheckReturnDates checks the expected return date of a returnable DC
+// against its challan date.
+func CheckReturnDates(challanDate, expectedReturnDate string) error {
+	if _, err := time.Parse("2006-01-02", expectedReturnDate); err != nil {
+		return fmt.Errorf("expected return date is required")
+	}
+	if expectedReturnDate < challanDate {
+		return fmt.Errorf("expected return date cannot be before the challan date")
+	}
+	return nil
+}
+
+// CreateReturnableDC creates a draft returnable DC with its priced line
+// items, serials and transit details.
+func CreateReturnableDC(ctx context.Context, app *pocketbase.PocketBase, params ReturnableDCParams) (*core.Record, error) {
+	if err := CheckReturnDates(params.ChallanDate, params.ExpectedReturnDate); err != nil {
+		return nil, err
+	}
+	docDate, err := time.Parse("2006-01-02", params.ChallanDate)
+	if err != nil {
+		docDate = time.Now()
+	}
+
+	rdcNum, err := NextDocNumber(app, params.ProjectID, "rdc", docDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate returnable DC number: %w", err)
+	}
+	dcCol, err := app.FindCollectionByNameOrId("delivery_challans")
+	if err != nil {
+		return nil, fmt.Errorf("delivery_challans collection not found: %w", err)
+	}
+
+	dc := core.NewRecord(dcCol)
+	dc.Set("project", params.ProjectID)
+	dc.Set("dc_number", rdcNum)
+	dc.Set("dc_type", "returnable")
+	dc.Set("status", "draft")
+	if err := saveReturnableDC(ctx, app, dc, params); err != nil {
+		return nil, err
+	}
+	return dc, nil
+}
+
+// UpdateReturnableDC rewrites a draft returnable DC from wizard values,
+// keeping its DC number.
+func UpdateReturnableDC(ctx context.Context, app *pocketbase.PocketBase, dcID string, params ReturnableDCParams) (*core.Record, error) {
+	dc, err := app.FindRecordById("delivery_challans", dcID)
+	if err != nil {
+		return nil, fmt.Errorf("returnable DC not found: %w", err)
+	}
+	if dc.GetString("dc_type") != "returnable" || dc.GetString("status") != "draft" {
+		return nil, fmt.Errorf("only draft returnable DCs can be edited")
+	}
+	if err := CheckReturnDates(params.ChallanDate, params.ExpectedReturnDate); err != nil {
+		return nil, err
+	}
+	if err := saveReturnableDC(ctx, app, dc, params); err != nil {
+		return nil, err
+	}
+	return dc, nil
+}
+
+func saveReturnableDC(ctx context.Context, app core.App, dc *core.Record, params ReturnableDCParams) error {
+	setDraftDCFields(dc, params.TemplateID, params.BillFromID, params.DispatchFromID, params.BillToID, params.ShipToID, params.ChallanDate)
+	dc.Set("expected_return_date", params.ExpectedReturnDate)
+	if err := app.SaveWithContext(ctx, dc); err != nil {
+		return fmt.Errorf("failed to save returnable DC: %w", err)
+	}
+	if err := replaceDCLineItems(ctx, app, dc.Id, params.ProjectID, params.Items, -1); err != nil {
+		return err
+	}
+	return saveDraftTransitDetails(ctx, app, dc.Id, params.TransporterID, params.VehicleID, params.EwayBillNumber, params.DocketNumber)
+}
+
+func loadReturnableDraft(app core.App, dc *core.Record) (*DCDraft, error) {
+	draft := &DCDraft{DCID: dc.Id, DCType: "returnable", ExpectedReturnDate: dc.GetString("expected_return_date")}
+	fillDraftFromDC(draft, dc)
+	fillDraftTransport(app, draft, dc.Id, "", "", "", "")
+
+	items, err := draftItemsFromDC(app, dc.Id)
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		items[i].Quantities = []int{items[i].TotalQty}
+	}
+	draft.Items = items
+	draft.ShipToIDs = []string{dc.GetString("ship_to_address")}
+	return draft, nil
+}
+
+// BuildOutstandingReturns lists the issued returnable DCs of a project that
+// still have goods out, most overdue first. Days overdue are counted to
+// today.
+func BuildOutstandingReturns(app core.App, projectID string, today time.Time) ([]OutstandingReturn, error) {
+	dcs, err := app.FindRecordsByFilter("delivery_challans",
+		"project = {:pid} && dc_type = 'returnable' && status != 'draft' && status != 'cancelled'",
+		"expected_return_date,dc_number", 0, 0, map[string]any{"pid": projectID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch returnable DCs: %w", err)
+	}
+
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	var result []OutstandingReturn
+	for _, dc := range dcs {
+		lines, err := ReturnableDCLines(app, dc.Id)
+		if err != nil {
+			return nil, err
+		}
+		r := OutstandingReturn{
+			DCID:               dc.Id,
+			DCNumber:           dc.GetString("dc_number"),
+			ShipToID:           dc.GetString("ship_to_address"),
+			ChallanDate:        dc.GetString("challan_date"),
+			ExpectedReturnDate: dc.GetString("expected_return_date"),
+		}
+		for _, l := range lines {
+			r.Dispatched += l.Dispatched
+			r.Returned += l.Returned
+		}
+		if r.Outstanding() <= 0 {
+			continue
+		}
+		if due, err := time.Parse("2006-01-02", r.ExpectedReturnDate); err == nil && today.After(due) {
+			r.DaysOverdue = int(today.Sub(due).Hours() / 24)
+		}
+		result = append(result, r)
+	}
+	return result, nil
+}
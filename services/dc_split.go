@@ -1,9 +1,12 @@
 package services
 
 import (
+	"context"
 	"fmt"
+	"slices"
 	"time"
 
+	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase"
 	"github.com/pocketbase/pocketbase/core"
 )
@@ -13,6 +16,10 @@ type SplitParams struct {
 	ProjectID      string
 	TransferDCID   string // delivery_challans record ID
 	DestinationIDs []string // transfer_dc_destinations IDs to split
+	// Quantities maps transfer_dc_dest_quantities ID -> quantity to forward in
+	// this split. Nil forwards everything remaining on the selected
+	// destinations; otherwise lines left out of the map forward nothing.
+	Quantities     map[string]int
 	TransporterID  string
 	VehicleID      string
 	EwayBillNumber string
@@ -31,17 +38,20 @@ type SplitResult struct {
 	OfficialDCNums  []string
 }
 
-// CreateSplit creates a child shipment group from selected transfer DC destinations.
-func CreateSplit(app *pocketbase.PocketBase, params SplitParams) (*SplitResult, error) {
+// CreateSplit creates a child shipment group forwarding some or all of the
+// remaining quantities of selected transfer DC destinations. What is forwarded
+// is recorded against the destination quantities, and the transfer DC only
+// reaches split status once nothing remains on any destination.
+func CreateSplit(ctx context.Context, app *pocketbase.PocketBase, params SplitParams) (*SplitResult, error) {
 	result := &SplitResult{}
 
-	// 1. Validate transfer DC exists and is issued/splitting
+	// 1. Validate transfer DC exists and is issued (or delivered) or splitting
 	dc, err := app.FindRecordById("delivery_challans", params.TransferDCID)
 	if err != nil {
 		return nil, fmt.Errorf("delivery challan not found: %w", err)
 	}
 	status := dc.GetString("status")
-	if status != "issued" && status != "splitting" {
+	if !slices.Contains(DCIssuedStatuses, status) && status != "splitting" {
 		return nil, fmt.Errorf("transfer DC must be in issued or splitting status, got %s", status)
 	}
 	if dc.GetString("dc_type") != "transfer" {
@@ -56,30 +66,61 @@ func CreateSplit(app *pocketbase.PocketBase, params SplitParams) (*SplitResult,
 		return nil, fmt.Errorf("transfer DC metadata not found")
 	}
 	transferDC := transferRecs[0]
-	numDest := transferDC.GetInt("num_destinations")
 	numSplit := transferDC.GetInt("num_split")
 
-	// 3. Validate selected destinations are not already split
+	// 3. Validate selected destinations still have quantities to forward
 	for _, destID := range params.DestinationIDs {
 		destRec, err := app.FindRecordById("transfer_dc_destinations", destID)
 		if err != nil {
 			return nil, fmt.Errorf("destination %s not found: %w", destID, err)
 		}
 		if destRec.GetBool("is_split") {
-			return nil, fmt.Errorf("destination %s is already split", destID)
+			return nil, fmt.Errorf("destination %s is already fully split", destID)
 		}
 		if destRec.GetString("transfer_dc") != transferDC.Id {
 			return nil, fmt.Errorf("destination %s does not belong to this transfer DC", destID)
 		}
 	}
 
-	// 4. Fetch parent DC line items for pricing info
+	// 4. Work out how much of each destination quantity this split forwards
+	destIDs := make([]any, len(params.DestinationIDs))
+	for i, id := range params.DestinationIDs {
+		destIDs[i] = id
+	}
+	var destQtyRecs []*core.Record
+	if len(destIDs) > 0 {
+		destQtyRecs, err = app.FindAllRecords("transfer_dc_dest_quantities", dbx.In("destination", destIDs...))
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch destination quantities: %w", err)
+		}
+	}
+	planned := make(map[string]int, len(destQtyRecs))
+	destQtyByItem := make(map[string]*core.Record, len(destQtyRecs))
+	for _, dq := range destQtyRecs {
+		remaining := dq.GetInt("quantity") - dq.GetInt("forwarded_qty")
+		qty := remaining
+		if params.Quantities != nil {
+			qty = params.Quantities[dq.Id]
+		}
+		if qty < 0 || qty > remaining {
+			return nil, fmt.Errorf("cannot forward %d of an item with %d remaining", qty, remaining)
+		}
+		planned[dq.Id] = qty
+		destQtyByItem[dq.GetString("destination")+":"+dq.GetString("source_item_type")+":"+dq.GetString("source_item_id")] = dq
+	}
+	for id := range params.Quantities {
+		if _, ok := planned[id]; !ok {
+			return nil, fmt.Errorf("quantity %s does not belong to the selected destinations", id)
+		}
+	}
+
+	// 5. Fetch parent DC line items for pricing info
 	parentLineItems, err := app.FindRecordsByFilter("dc_line_items", "dc = {:did}", "line_order", 0, 0, map[string]any{"did": params.TransferDCID})
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch parent line items: %w", err)
 	}
 
-	// 5. Compute per-item total quantities across selected destinations
+	// 6. Compute per-item quantities forwarded across selected destinations
 	type itemQtyInfo struct {
 		SourceItemType string
 		SourceItemID   string
@@ -104,23 +145,35 @@ func CreateSplit(app *pocketbase.PocketBase, params SplitParams) (*SplitResult,
 		}
 
 		for _, destID := range params.DestinationIDs {
-			destQtyRecs, _ := app.FindRecordsByFilter("transfer_dc_dest_quantities",
-				"destination = {:did} && source_item_type = {:sit} && source_item_id = {:sid}",
-				"", 1, 0,
-				map[string]any{"did": destID, "sit": info.SourceItemType, "sid": info.SourceItemID})
-			if len(destQtyRecs) > 0 {
-				qty := destQtyRecs[0].GetInt("quantity")
-				info.PerDestQty[destID] = qty
-				info.TotalQty += qty
+			if dq, ok := destQtyByItem[destID+":"+info.SourceItemType+":"+info.SourceItemID]; ok && planned[dq.Id] > 0 {
+				info.PerDestQty[destID] = planned[dq.Id]
+				info.TotalQty += planned[dq.Id]
 			}
 		}
 
 		if info.TotalQty > 0 {
+			if n := len(params.SerialAssignments[li.Id]); n > info.TotalQty {
+				return nil, fmt.Errorf("%d serial numbers assigned to an item forwarding %d", n, info.TotalQty)
+			}
 			itemInfos = append(itemInfos, info)
 		}
 	}
+	if len(itemInfos) == 0 {
+		return nil, fmt.Errorf("nothing to split: no quantity selected")
+	}
+
+	// Only destinations receiving something in this split get an official DC
+	var splitDestIDs []string
+	for _, destID := range params.DestinationIDs {
+		for _, info := range itemInfos {
+			if info.PerDestQty[destID] > 0 {
+				splitDestIDs = append(splitDestIDs, destID)
+				break
+			}
+		}
+	}
 
-	// 6. Resolve addresses from transfer DC
+	// 7. Resolve addresses from transfer DC
 	billFromID := dc.GetString("bill_from_address")
 	dispatchFromID := dc.GetString("dispatch_from_address")
 	billToID := dc.GetString("bill_to_address")
@@ -133,7 +186,7 @@ func CreateSplit(app *pocketbase.PocketBase, params SplitParams) (*SplitResult,
 		}
 	}
 
-	// 7. Create child Shipment Group
+	// 8. Create child Shipment Group
 	sgCol, err := app.FindCollectionByNameOrId("shipment_groups")
 	if err != nil {
 		return nil, fmt.Errorf("shipment_groups collection not found: %w", err)
@@ -144,24 +197,24 @@ func CreateSplit(app *pocketbase.PocketBase, params SplitParams) (*SplitResult,
 	if templateID != "" {
 		sgRec.Set("template", templateID)
 	}
-	sgRec.Set("num_locations", len(params.DestinationIDs))
+	sgRec.Set("num_locations", len(splitDestIDs))
 	sgRec.Set("tax_type", transferDC.GetString("tax_type"))
 	sgRec.Set("reverse_charge", transferDC.GetBool("reverse_charge"))
 	sgRec.Set("status", "draft")
 	sgRec.Set("transfer_dc", transferDC.Id)
-	if err := app.Save(sgRec); err != nil {
+	if err := app.SaveWithContext(ctx, sgRec); err != nil {
 		return nil, fmt.Errorf("failed to create shipment group: %w", err)
 	}
 	result.ShipmentGroupID = sgRec.Id
 
-	// 8. Generate Transit DC number
+	// 9. Generate Transit DC number
 	transitDCNum, err := NextDocNumber(app, projectID, "tdc", docDate)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate transit DC number: %w", err)
 	}
 	result.TransitDCNumber = transitDCNum
 
-	// 9. Create Transit DC
+	// 10. Create Transit DC
 	dcCol, err := app.FindCollectionByNameOrId("delivery_challans")
 	if err != nil {
 		return nil, fmt.Errorf("delivery_challans collection not found: %w", err)
@@ -185,19 +238,19 @@ func CreateSplit(app *pocketbase.PocketBase, params SplitParams) (*SplitResult,
 		transitDCRec.Set("bill_to_address", billToID)
 	}
 	// Set first destination's ship_to as primary
-	if len(params.DestinationIDs) > 0 {
-		if firstDest, err := app.FindRecordById("transfer_dc_destinations", params.DestinationIDs[0]); err == nil {
+	if len(splitDestIDs) > 0 {
+		if firstDest, err := app.FindRecordById("transfer_dc_destinations", splitDestIDs[0]); err == nil {
 			transitDCRec.Set("ship_to_address", firstDest.GetString("ship_to_address"))
 		}
 	}
 	transitDCRec.Set("challan_date", docDate.Format("2006-01-02"))
 	transitDCRec.Set("shipment_group", sgRec.Id)
-	if err := app.Save(transitDCRec); err != nil {
+	if err := app.SaveWithContext(ctx, transitDCRec); err != nil {
 		return nil, fmt.Errorf("failed to create transit DC: %w", err)
 	}
 	result.TransitDCID = transitDCRec.Id
 
-	// 10. Create transit DC line items with pricing and assigned serials
+	// 11. Create transit DC line items with pricing and assigned serials
 	lineItemCol, err := app.FindCollectionByNameOrId("dc_line_items")
 	if err != nil {
 		return nil, fmt.Errorf("dc_line_items collection not found: %w", err)
@@ -224,7 +277,7 @@ func CreateSplit(app *pocketbase.PocketBase, params SplitParams) (*SplitResult,
 		lineItem.Set("tax_amount", taxAmount)
 		lineItem.Set("total_amount", totalAmount)
 		lineItem.Set("line_order", info.LineOrder)
-		if err := app.Save(lineItem); err != nil {
+		if err := app.SaveWithContext(ctx, lineItem); err != nil {
 			return nil, fmt.Errorf("failed to create transit DC line item: %w", err)
 		}
 
@@ -235,14 +288,14 @@ func CreateSplit(app *pocketbase.PocketBase, params SplitParams) (*SplitResult,
 				serialRec.Set("project", projectID)
 				serialRec.Set("line_item", lineItem.Id)
 				serialRec.Set("serial_number", serial)
-				if err := app.Save(serialRec); err != nil {
+				if err := app.SaveWithContext(ctx, serialRec); err != nil {
 					return nil, fmt.Errorf("failed to save serial number %s: %w", serial, err)
 				}
 			}
 		}
 	}
 
-	// 11. Create transit details
+	// 12. Create transit details
 	if params.TransporterID != "" || params.EwayBillNumber != "" || params.DocketNumber != "" {
 		transitDetailCol, err := app.FindCollectionByNameOrId("dc_transit_details")
 		if err == nil {
@@ -260,12 +313,12 @@ func CreateSplit(app *pocketbase.PocketBase, params SplitParams) (*SplitResult,
 			detail.Set("vehicle_number", vehicleNumber)
 			detail.Set("eway_bill_number", params.EwayBillNumber)
 			detail.Set("docket_number", params.DocketNumber)
-			_ = app.Save(detail)
+			_ = app.SaveWithContext(ctx, detail)
 		}
 	}
 
-	// 12. Create Official DCs (one per selected destination)
-	for _, destID := range params.DestinationIDs {
+	// 13. Create Official DCs (one per destination in this split)
+	for _, destID := range splitDestIDs {
 		destRec, err := app.FindRecordById("transfer_dc_destinations", destID)
 		if err != nil {
 			continue
@@ -297,7 +350,7 @@ func CreateSplit(app *pocketbase.PocketBase, params SplitParams) (*SplitResult,
 		odc.Set("ship_to_address", shipToID)
 		odc.Set("challan_date", docDate.Format("2006-01-02"))
 		odc.Set("shipment_group", sgRec.Id)
-		if err := app.Save(odc); err != nil {
+		if err := app.SaveWithContext(ctx, odc); err != nil {
 			return nil, fmt.Errorf("failed to create official DC: %w", err)
 		}
 
@@ -317,61 +370,84 @@ func CreateSplit(app *pocketbase.PocketBase, params SplitParams) (*SplitResult,
 			odcLineItem.Set("source_item_id", info.SourceItemID)
 			odcLineItem.Set("quantity", qty)
 			odcLineItem.Set("line_order", info.LineOrder)
-			if err := app.Save(odcLineItem); err != nil {
+			if err := app.SaveWithContext(ctx, odcLineItem); err != nil {
 				return nil, fmt.Errorf("failed to create official DC line item: %w", err)
 			}
 		}
 	}
 
-	// 13. Create transfer_dc_splits record
+	// 14. Create transfer_dc_splits record
 	splitCol, err := app.FindCollectionByNameOrId("transfer_dc_splits")
 	if err != nil {
 		return nil, fmt.Errorf("transfer_dc_splits collection not found: %w", err)
 	}
 
+	// Number splits by every split ever made: cancelled splits keep their
+	// record (and number) even though they no longer count towards num_split.
+	prevSplits, _ := app.CountRecords("transfer_dc_splits", dbx.HashExp{"transfer_dc": transferDC.Id})
+
 	splitRec := core.NewRecord(splitCol)
 	splitRec.Set("transfer_dc", transferDC.Id)
 	splitRec.Set("shipment_group", sgRec.Id)
-	splitRec.Set("split_number", numSplit+1)
-	if err := app.Save(splitRec); err != nil {
+	splitRec.Set("split_number", int(prevSplits)+1)
+	if err := app.SaveWithContext(ctx, splitRec); err != nil {
 		return nil, fmt.Errorf("failed to create split record: %w", err)
 	}
 	result.SplitID = splitRec.Id
 
 	// Link split back to shipment group
 	sgRec.Set("split", splitRec.Id)
-	_ = app.Save(sgRec)
+	_ = app.SaveWithContext(ctx, sgRec)
 
-	// 14. Mark destinations as split
+	// 15. Record what this split forwarded from each destination quantity
+	splitQtyCol, err := app.FindCollectionByNameOrId("transfer_dc_split_quantities")
+	if err != nil {
+		return nil, fmt.Errorf("transfer_dc_split_quantities collection not found: %w", err)
+	}
+	remainingByDest := make(map[string]int)
+	for _, dq := range destQtyRecs {
+		if qty := planned[dq.Id]; qty > 0 {
+			splitQty := core.NewRecord(splitQtyCol)
+			splitQty.Set("split", splitRec.Id)
+			splitQty.Set("dest_quantity", dq.Id)
+			splitQty.Set("quantity", qty)
+			if err := app.SaveWithContext(ctx, splitQty); err != nil {
+				return nil, fmt.Errorf("failed to record split quantity: %w", err)
+			}
+			dq.Set("forwarded_qty", dq.GetInt("forwarded_qty")+qty)
+			if err := app.SaveWithContext(ctx, dq); err != nil {
+				return nil, fmt.Errorf("failed to update destination quantity: %w", err)
+			}
+		}
+		remainingByDest[dq.GetString("destination")] += dq.GetInt("quantity") - dq.GetInt("forwarded_qty")
+	}
+
+	// 16. Mark destinations with nothing left to forward as split
 	for _, destID := range params.DestinationIDs {
 		destRec, err := app.FindRecordById("transfer_dc_destinations", destID)
 		if err != nil {
 			continue
 		}
-		destRec.Set("is_split", true)
-		destRec.Set("split_group", sgRec.Id)
-		_ = app.Save(destRec)
+		destRec.Set("is_split", remainingByDest[destID] == 0)
+		if slices.Contains(splitDestIDs, destID) {
+			destRec.Set("split_group", sgRec.Id)
+		}
+		_ = app.SaveWithContext(ctx, destRec)
 	}
 
-	// 15. Update transfer DC metadata
-	newNumSplit := numSplit + 1
-	transferDC.Set("num_split", newNumSplit)
-	_ = app.Save(transferDC)
-
-	// 16. Update parent DC status based on split progress
-	if newNumSplit >= numDest {
-		dc.Set("status", "split")
-	} else {
-		dc.Set("status", "splitting")
+	// 17. Update transfer DC metadata and parent DC status
+	transferDC.Set("num_split", numSplit+1)
+	_ = app.SaveWithContext(ctx, transferDC)
+	if err := refreshTransferDCStatus(ctx, app, transferDC); err != nil {
+		return nil, err
 	}
-	_ = app.Save(dc)
 
 	return result, nil
 }
 
 // UndoSplit reverses a split operation, deleting the child shipment group
-// and resetting destination split flags.
-func UndoSplit(app *pocketbase.PocketBase, splitID string) error {
+// and handing its quantities back to the destinations.
+func UndoSplit(ctx context.Context, app *pocketbase.PocketBase, splitID string) error {
 	// 1. Find the split record
 	splitRec, err := app.FindRecordById("transfer_dc_splits", splitID)
 	if err != nil {
@@ -387,14 +463,7 @@ func UndoSplit(app *pocketbase.PocketBase, splitID string) error {
 		return fmt.Errorf("transfer DC metadata not found: %w", err)
 	}
 
-	// 3. Find parent DC record
-	parentDCID := transferDC.GetString("dc")
-	parentDC, err := app.FindRecordById("delivery_challans", parentDCID)
-	if err != nil {
-		return fmt.Errorf("parent DC not found: %w", err)
-	}
-
-	// 4. Verify shipment group is in draft (can't undo issued splits)
+	// 3. Verify shipment group is in draft (can't undo issued splits)
 	sg, err := app.FindRecordById("shipment_groups", sgID)
 	if err != nil {
 		return fmt.Errorf("shipment group not found: %w", err)
@@ -403,18 +472,13 @@ func UndoSplit(app *pocketbase.PocketBase, splitID string) error {
 		return fmt.Errorf("cannot undo split: shipment group is already %s", sg.GetString("status"))
 	}
 
-	// 5. Reset destinations that reference this shipment group
-	destRecs, _ := app.FindRecordsByFilter("transfer_dc_destinations",
-		"transfer_dc = {:tid} && split_group = {:sgid}",
-		"", 0, 0,
-		map[string]any{"tid": transferDCID, "sgid": sgID})
-	for _, dest := range destRecs {
-		dest.Set("is_split", false)
-		dest.Set("split_group", "")
-		_ = app.Save(dest)
+	// 4. Hand the forwarded quantities back to the destinations (before the
+	// split record goes, taking its split quantities with it)
+	if err := releaseSplitQuantities(ctx, app, transferDCID, sgID); err != nil {
+		return err
 	}
 
-	// 6. Delete child DCs in the shipment group (cascade will handle line items, serials)
+	// 5. Delete child DCs in the shipment group (cascade will handle line items, serials)
 	childDCs, _ := app.FindRecordsByFilter("delivery_challans", "shipment_group = {:sgid}", "", 0, 0, map[string]any{"sgid": sgID})
 	for _, childDC := range childDCs {
 		// Delete associated serial numbers and transit details first
@@ -422,43 +486,135 @@ func UndoSplit(app *pocketbase.PocketBase, splitID string) error {
 		for _, li := range childLineItems {
 			childSerials, _ := app.FindRecordsByFilter("serial_numbers", "line_item = {:lid}", "", 0, 0, map[string]any{"lid": li.Id})
 			for _, sr := range childSerials {
-				_ = app.Delete(sr)
+				_ = app.DeleteWithContext(ctx, sr)
 			}
-			_ = app.Delete(li)
+			_ = app.DeleteWithContext(ctx, li)
 		}
 		transitDetails, _ := app.FindRecordsByFilter("dc_transit_details", "dc = {:did}", "", 0, 0, map[string]any{"did": childDC.Id})
 		for _, td := range transitDetails {
-			_ = app.Delete(td)
+			_ = app.DeleteWithContext(ctx, td)
 		}
-		_ = app.Delete(childDC)
+		_ = app.DeleteWithContext(ctx, childDC)
 	}
 
-	// 7. Delete the split record
-	_ = app.Delete(splitRec)
+	// 6. Delete the split record
+	_ = app.DeleteWithContext(ctx, splitRec)
 
-	// 8. Delete the shipment group
-	_ = app.Delete(sg)
+	// 7. Delete the shipment group
+	_ = app.DeleteWithContext(ctx, sg)
 
-	// 9. Decrement num_split on transfer DC
+	// 8. Decrement num_split on transfer DC and recompute parent DC status
 	numSplit := transferDC.GetInt("num_split")
 	if numSplit > 0 {
 		numSplit--
 	}
 	transferDC.Set("num_split", numSplit)
-	_ = app.Save(transferDC)
+	_ = app.SaveWithContext(ctx, transferDC)
+
+	return refreshTransferDCStatus(ctx, app, transferDC)
+}
+
+// releaseSplitQuantities hands the quantities a split forwarded back to the
+// destinations of its transfer DC. Splits made before quantity-based splits
+// have no split quantities; they always took whole destinations, so those
+// destinations are released in full.
+func releaseSplitQuantities(ctx context.Context, app core.App, transferDCID, sgID string) error {
+	splitQtys, err := app.FindRecordsByFilter("transfer_dc_split_quantities",
+		"split.shipment_group = {:sgid}", "", 0, 0, map[string]any{"sgid": sgID})
+	if err != nil {
+		return fmt.Errorf("failed to fetch split quantities: %w", err)
+	}
+
+	if len(splitQtys) == 0 {
+		destRecs, _ := app.FindRecordsByFilter("transfer_dc_destinations",
+			"transfer_dc = {:tid} && split_group = {:sgid}",
+			"", 0, 0,
+			map[string]any{"tid": transferDCID, "sgid": sgID})
+		for _, dest := range destRecs {
+			destQtys, _ := app.FindRecordsByFilter("transfer_dc_dest_quantities", "destination = {:did}", "", 0, 0, map[string]any{"did": dest.Id})
+			for _, dq := range destQtys {
+				dq.Set("forwarded_qty", 0)
+				if err := app.SaveWithContext(ctx, dq); err != nil {
+					return fmt.Errorf("failed to release destination quantity: %w", err)
+				}
+			}
+			dest.Set("is_split", false)
+			dest.Set("split_group", "")
+			if err := app.SaveWithContext(ctx, dest); err != nil {
+				return fmt.Errorf("failed to release destination: %w", err)
+			}
+		}
+		return nil
+	}
+
+	var destIDs []string
+	for _, sq := range splitQtys {
+		dq, err := app.FindRecordById("transfer_dc_dest_quantities", sq.GetString("dest_quantity"))
+		if err == nil {
+			dq.Set("forwarded_qty", max(dq.GetInt("forwarded_qty")-sq.GetInt("quantity"), 0))
+			if err := app.SaveWithContext(ctx, dq); err != nil {
+				return fmt.Errorf("failed to release destination quantity: %w", err)
+			}
+			if !slices.Contains(destIDs, dq.GetString("destination")) {
+				destIDs = append(destIDs, dq.GetString("destination"))
+			}
+		}
+		if err := app.DeleteWithContext(ctx, sq); err != nil {
+			return fmt.Errorf("failed to remove split quantity: %w", err)
+		}
+	}
 
-	// 10. Recompute parent DC status
-	if numSplit == 0 {
-		parentDC.Set("status", "issued")
+	// The destinations now have quantities left to forward; they point at
+	// their latest remaining split, if any
+	for _, destID := range destIDs {
+		dest, err := app.FindRecordById("transfer_dc_destinations", destID)
+		if err != nil {
+			continue
+		}
+		dest.Set("is_split", false)
+		if dest.GetString("split_group") == sgID {
+			dest.Set("split_group", "")
+			latest, err := app.FindRecordsByFilter("transfer_dc_split_quantities",
+				"dest_quantity.destination = {:did}", "-created", 1, 0, map[string]any{"did": destID})
+			if err == nil && len(latest) > 0 {
+				if split, err := app.FindRecordById("transfer_dc_splits", latest[0].GetString("split")); err == nil {
+					dest.Set("split_group", split.GetString("shipment_group"))
+				}
+			}
+		}
+		if err := app.SaveWithContext(ctx, dest); err != nil {
+			return fmt.Errorf("failed to release destination: %w", err)
+		}
+	}
+	return nil
+}
+
+// refreshTransferDCStatus sets a transfer DC's status from its split
+// progress: resting with no active splits, split once every destination has
+// been fully forwarded, and splitting in between. Cancelled DCs are left alone.
+func refreshTransferDCStatus(ctx context.Context, app core.App, transferDC *core.Record) error {
+	parentDC, err := app.FindRecordById("delivery_challans", transferDC.GetString("dc"))
+	if err != nil {
+		return fmt.Errorf("parent DC not found: %w", err)
+	}
+	if parentDC.GetString("status") == "cancelled" {
+		return nil
+	}
+	if transferDC.GetInt("num_split") == 0 {
+		parentDC.Set("status", restingDCStatus(app, parentDC.Id))
 	} else {
-		numDest := transferDC.GetInt("num_destinations")
-		if numSplit >= numDest {
+		pending, err := app.CountRecords("transfer_dc_destinations", dbx.HashExp{"transfer_dc": transferDC.Id, "is_split": false})
+		if err != nil {
+			return fmt.Errorf("failed to count pending destinations: %w", err)
+		}
+		if pending == 0 {
 			parentDC.Set("status", "split")
 		} else {
 			parentDC.Set("status", "splitting")
 		}
 	}
-	_ = app.Save(parentDC)
-
+	if err := app.SaveWithContext(ctx, parentDC); err != nil {
+		return fmt.Errorf("failed to update parent DC status: %w", err)
+	}
 	return nil
 }
@@ -1,7 +1,13 @@
 package services
 
 import (
+	"context"
 	"testing"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/testhelpers"
 )
 
 func TestCreateSplit_InvalidStatus(t *testing.T) {
@@ -70,3 +76,152 @@ func TestSplitResult_Structure(t *testing.T) {
 		t.Errorf("expected transit DC number 'TDC-001', got %s", result.TransitDCNumber)
 	}
 }
+
+// issuedTransferDC creates an issued transfer DC with one priced item sent to
+// two destinations, returning the DC and each destination with its quantity.
+func issuedTransferDC(t *testing.T, app *pocketbase.PocketBase, projectID string, qtyA, qtyB int) (dc *core.Record, dests, destQtys []*core.Record) {
+	t.Helper()
+	dc = testhelpers.CreateTestDeliveryChallan(t, app, projectID, "STDC-001", "transfer", "issued")
+	tdc := saveTestRecord(t, app, "transfer_dcs", map[string]any{
+		"dc": dc.Id, "tax_type": "igst", "num_destinations": 2,
+	})
+	saveTestRecord(t, app, "dc_line_items", map[string]any{
+		"dc": dc.Id, "source_item_type": "sub_item", "source_item_id": "x", "quantity": qtyA + qtyB,
+		"rate": 100, "tax_percentage": 18, "line_order": 1,
+	})
+	for i, qty := range []int{qtyA, qtyB} {
+		addr := testhelpers.CreateTestAddress(t, app, projectID, "ship_to", []string{"Site A", "Site B"}[i])
+		dest := saveTestRecord(t, app, "transfer_dc_destinations", map[string]any{
+			"transfer_dc": tdc.Id, "ship_to_address": addr.Id,
+		})
+		destQty := saveTestRecord(t, app, "transfer_dc_dest_quantities", map[string]any{
+			"destination": dest.Id, "source_item_type": "sub_item", "source_item_id": "x", "quantity": qty,
+		})
+		dests = append(dests, dest)
+		destQtys = append(destQtys, destQty)
+	}
+	return dc, dests, destQtys
+}
+
+func TestCreateSplit_PartialQuantities(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Split Project")
+	ctx := context.Background()
+	dc, dests, destQtys := issuedTransferDC(t, app, project.Id, 6, 4)
+
+	expect := func(status string, forwardedA, forwardedB int) {
+		t.Helper()
+		parent, _ := app.FindRecordById("delivery_challans", dc.Id)
+		if parent.GetString("status") != status {
+			t.Errorf("expected parent to be %s, got %s", status, parent.GetString("status"))
+		}
+		for i, want := range []int{forwardedA, forwardedB} {
+			dq, _ := app.FindRecordById("transfer_dc_dest_quantities", destQtys[i].Id)
+			if dq.GetInt("forwarded_qty") != want {
+				t.Errorf("expected destination %d to have forwarded %d, got %d", i+1, want, dq.GetInt("forwarded_qty"))
+			}
+		}
+	}
+
+	// Forward part of site A now
+	first, err := CreateSplit(ctx, app, SplitParams{
+		TransferDCID:   dc.Id,
+		DestinationIDs: []string{dests[0].Id},
+		Quantities:     map[string]int{destQtys[0].Id: 2},
+	})
+	if err != nil {
+		t.Fatalf("CreateSplit failed: %v", err)
+	}
+	expect("splitting", 2, 0)
+	if len(first.OfficialDCIDs) != 1 {
+		t.Errorf("expected 1 official DC, got %d", len(first.OfficialDCIDs))
+	}
+	line, _ := app.FindFirstRecordByData("dc_line_items", "dc", first.TransitDCID)
+	if line.GetInt("quantity") != 2 || line.GetFloat("taxable_amount") != 200 {
+		t.Errorf("expected the transit DC to carry 2 at 200, got %d at %.2f", line.GetInt("quantity"), line.GetFloat("taxable_amount"))
+	}
+	destA, _ := app.FindRecordById("transfer_dc_destinations", dests[0].Id)
+	if destA.GetBool("is_split") {
+		t.Error("expected site A to still have quantities to forward")
+	}
+
+	// More than remains, or nothing at all, is rejected
+	if _, err := CreateSplit(ctx, app, SplitParams{
+		TransferDCID: dc.Id, DestinationIDs: []string{dests[0].Id}, Quantities: map[string]int{destQtys[0].Id: 5},
+	}); err == nil {
+		t.Error("expected forwarding more than remains to fail")
+	}
+	if _, err := CreateSplit(ctx, app, SplitParams{
+		TransferDCID: dc.Id, DestinationIDs: []string{dests[0].Id}, Quantities: map[string]int{},
+	}); err == nil {
+		t.Error("expected an empty split to fail")
+	}
+
+	// Forwarding everything remaining completes the split
+	second, err := CreateSplit(ctx, app, SplitParams{
+		TransferDCID:   dc.Id,
+		DestinationIDs: []string{dests[0].Id, dests[1].Id},
+	})
+	if err != nil {
+		t.Fatalf("CreateSplit failed: %v", err)
+	}
+	expect("split", 6, 4)
+	for _, dest := range dests {
+		rec, _ := app.FindRecordById("transfer_dc_destinations", dest.Id)
+		if !rec.GetBool("is_split") {
+			t.Errorf("expected destination %s to be fully split", dest.Id)
+		}
+	}
+
+	// Undoing hands the quantities back
+	if err := UndoSplit(ctx, app, second.SplitID); err != nil {
+		t.Fatalf("UndoSplit failed: %v", err)
+	}
+	expect("splitting", 2, 0)
+	destA, _ = app.FindRecordById("transfer_dc_destinations", dests[0].Id)
+	if destA.GetBool("is_split") || destA.GetString("split_group") != first.ShipmentGroupID {
+		t.Errorf("expected site A to point back at the first split, got %q", destA.GetString("split_group"))
+	}
+	if err := UndoSplit(ctx, app, first.SplitID); err != nil {
+		t.Fatalf("UndoSplit failed: %v", err)
+	}
+	expect("issued", 0, 0)
+}
+
+func TestCreateSplit_IssuesWithForwardedSerials(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Split Project")
+	ctx := context.Background()
+	dc, dests, _ := issuedTransferDC(t, app, project.Id, 2, 1)
+	parentLine, _ := app.FindFirstRecordByData("dc_line_items", "dc", dc.Id)
+	for _, serial := range []string{"SN-1", "SN-2", "SN-3"} {
+		saveTestRecord(t, app, "serial_numbers", map[string]any{
+			"project": project.Id, "line_item": parentLine.Id, "serial_number": serial,
+		})
+	}
+
+	result, err := CreateSplit(ctx, app, SplitParams{
+		TransferDCID:      dc.Id,
+		DestinationIDs:    []string{dests[0].Id},
+		SerialAssignments: map[string][]string{parentLine.Id: {"SN-1", "SN-2"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateSplit failed: %v", err)
+	}
+	if err := IssueShipmentGroup(ctx, app, result.ShipmentGroupID); err != nil {
+		t.Fatalf("expected the split to issue with its transfer DC's serials, got %v", err)
+	}
+
+	// A second split cannot carry a serial the first one already took
+	second, err := CreateSplit(ctx, app, SplitParams{
+		TransferDCID:      dc.Id,
+		DestinationIDs:    []string{dests[1].Id},
+		SerialAssignments: map[string][]string{parentLine.Id: {"SN-1"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateSplit failed: %v", err)
+	}
+	if err := IssueShipmentGroup(ctx, app, second.ShipmentGroupID); err == nil {
+		t.Error("expected SN-1 to conflict with the first split")
+	}
+}
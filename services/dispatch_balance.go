@@ -0,0 +1,150 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// DispatchBalance compares the BOQ quantity of a leaf item (sub item or sub
+// sub item) with what has been put on delivery challans so far.
+type DispatchBalance struct {
+	SourceItemType string
+	SourceItemID   string
+	Description    string
+	UOM            string
+	Required       float64 // qty_per_unit × parent quantity
+	Dispatched     float64 // on issued DCs
+	InDraft        float64 // on draft DCs
+}
+
+// Remaining returns the quantity still free to put on a new DC. Drafts count
+// against the balance since they are expected to be issued.
+func (b DispatchBalance) Remaining() float64 {
+	return b.Required - b.Dispatched - b.InDraft
+}
+
+// OverDispatch describes an item whose DC quantity exceeds its remaining
+// BOQ balance.
+type OverDispatch struct {
+	SourceItemType string
+	SourceItemID   string
+	Description    string
+	Quantity       int
+	Remaining      float64
+}
+
+// Message returns a human-readable description of the over-dispatch.
+func (o OverDispatch) Message() string {
+	return fmt.Sprintf("%s: %d exceeds the remaining BOQ balance of %s", o.Description, o.Quantity, formatQty(o.Remaining))
+}
+
+// DispatchItemKey returns the key used for a BOQ leaf item in dispatch
+// balance maps, matching the DC wizard item keys.
+func DispatchItemKey(sourceItemType, sourceItemID string) string {
+	return sourceItemType + "_" + sourceItemID
+}
+
+// ComputeDispatchBalances returns the dispatch balance of every BOQ leaf item
+// in the project, keyed by DispatchItemKey.
+//
+// Goods are counted once per shipment: on the transit DC of a direct
+// shipment and on the transfer DC of a transfer. Official DCs and shipments
+// split from a transfer DC repeat those quantities and are skipped, as are
+// cancelled DCs. excludeDCID leaves out a draft being edited so its own
+// quantities are not counted against it.
+func ComputeDispatchBalances(app core.App, projectID, excludeDCID string) (map[string]*DispatchBalance, error) {
+	quantities, err := projectBOQQuantities(app, projectID)
+	if err != nil {
+		return nil, err
+	}
+	balances := make(map[string]*DispatchBalance)
+	for key, q := range quantities {
+		// Only leaf items go on delivery challans
+		if q.SourceItemType == "main_item" {
+			continue
+		}
+		balances[key] = &DispatchBalance{
+			SourceItemType: q.SourceItemType,
+			SourceItemID:   q.SourceItemID,
+			Description:    q.Description,
+			UOM:            q.UOM,
+			Required:       q.Qty,
+		}
+	}
+
+	// Shipment groups split from a transfer DC carry goods already counted
+	// on the transfer DC
+	splitGroups := make(map[string]bool)
+	groups, err := app.FindRecordsByFilter("shipment_groups", "project = {:pid} && transfer_dc != ''", "", 0, 0, map[string]any{"pid": projectID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch shipment groups: %w", err)
+	}
+	for _, sg := range groups {
+		splitGroups[sg.Id] = true
+	}
+
+	dcs, err := app.FindRecordsByFilter("delivery_challans", "project = {:pid} && status != 'cancelled'", "", 0, 0, map[string]any{"pid": projectID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch delivery challans: %w", err)
+	}
+	counted := make(map[string]bool) // DC ID -> is draft
+	for _, dc := range dcs {
+		if dc.Id == excludeDCID {
+			continue
+		}
+		switch dc.GetString("dc_type") {
+		case "transfer":
+		case "transit":
+			if splitGroups[dc.GetString("shipment_group")] {
+				continue
+			}
+		default:
+			continue
+		}
+		counted[dc.Id] = dc.GetString("status") == "draft"
+	}
+
+	lineItems, err := app.FindRecordsByFilter("dc_line_items", "dc.project = {:pid}", "", 0, 0, map[string]any{"pid": projectID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch DC line items: %w", err)
+	}
+	for _, li := range lineItems {
+		isDraft, ok := counted[li.GetString("dc")]
+		if !ok {
+			continue
+		}
+		b := balances[DispatchItemKey(li.GetString("source_item_type"), li.GetString("source_item_id"))]
+		if b == nil {
+			continue
+		}
+		if isDraft {
+			b.InDraft += li.GetFloat("quantity")
+		} else {
+			b.Dispatched += li.GetFloat("quantity")
+		}
+	}
+
+	return balances, nil
+}
+
+// CheckOverDispatch returns the items whose total quantity exceeds their
+// remaining BOQ balance. Items that are not in the project's BOQ are not
+// checked.
+func CheckOverDispatch(balances map[string]*DispatchBalance, items []ShipmentItemParams) []OverDispatch {
+	var over []OverDispatch
+	for _, item := range items {
+		b := balances[DispatchItemKey(item.SourceItemType, item.SourceItemID)]
+		if b == nil || float64(item.TotalQty) <= b.Remaining() {
+			continue
+		}
+		over = append(over, OverDispatch{
+			SourceItemType: item.SourceItemType,
+			SourceItemID:   item.SourceItemID,
+			Description:    b.Description,
+			Quantity:       item.TotalQty,
+			Remaining:      b.Remaining(),
+		})
+	}
+	return over
+}
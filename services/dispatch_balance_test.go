@@ -0,0 +1,104 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/testhelpers"
+)
+
+// dispatchTestDC creates a DC carrying qty of the given sub item.
+func dispatchTestDC(t *testing.T, app *pocketbase.PocketBase, projectID, number, dcType, status, sgID, subItemID string, qty int) *core.Record {
+	t.Helper()
+	dc := testhelpers.CreateTestDeliveryChallan(t, app, projectID, number, dcType, status)
+	if sgID != "" {
+		dc.Set("shipment_group", sgID)
+		if err := app.Save(dc); err != nil {
+			t.Fatalf("failed to link DC to group: %v", err)
+		}
+	}
+	saveTestRecord(t, app, "dc_line_items", map[string]any{
+		"dc": dc.Id, "source_item_type": "sub_item", "source_item_id": subItemID, "quantity": qty,
+	})
+	return dc
+}
+
+func TestComputeDispatchBalances(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Dispatch Project")
+	boq := testhelpers.CreateTestBOQ(t, app, project.Id, "Dispatch BOQ")
+	mainItem := testhelpers.CreateTestMainBOQItem(t, app, boq.Id, "Lighting")  // qty 10
+	subItem := testhelpers.CreateTestSubItem(t, app, mainItem.Id, "LED Panel") // 5 per unit
+	subSub := testhelpers.CreateTestSubSubItem(t, app, subItem.Id, "Driver")   // 2 per unit
+
+	directSG := saveTestRecord(t, app, "shipment_groups", map[string]any{
+		"project": project.Id, "num_locations": 1, "tax_type": "igst", "status": "issued",
+	})
+	dispatchTestDC(t, app, project.Id, "TDC-1", "transit", "issued", directSG.Id, subItem.Id, 12)
+	// Official DCs repeat the transit quantities
+	dispatchTestDC(t, app, project.Id, "ODC-1", "official", "issued", directSG.Id, subItem.Id, 12)
+
+	transfer := dispatchTestDC(t, app, project.Id, "STDC-1", "transfer", "split", "", subItem.Id, 8)
+	transferMeta := saveTestRecord(t, app, "transfer_dcs", map[string]any{
+		"dc": transfer.Id, "tax_type": "igst", "num_destinations": 1,
+	})
+	// A split of the transfer DC repeats its quantities
+	splitSG := saveTestRecord(t, app, "shipment_groups", map[string]any{
+		"project": project.Id, "num_locations": 1, "tax_type": "igst", "status": "issued", "transfer_dc": transferMeta.Id,
+	})
+	dispatchTestDC(t, app, project.Id, "TDC-2", "transit", "issued", splitSG.Id, subItem.Id, 8)
+
+	draftSG := saveTestRecord(t, app, "shipment_groups", map[string]any{
+		"project": project.Id, "num_locations": 1, "tax_type": "igst", "status": "draft",
+	})
+	draft := dispatchTestDC(t, app, project.Id, "TDC-3", "transit", "draft", draftSG.Id, subItem.Id, 4)
+	dispatchTestDC(t, app, project.Id, "STDC-2", "transfer", "cancelled", "", subItem.Id, 100)
+
+	balances, err := ComputeDispatchBalances(app, project.Id, "")
+	if err != nil {
+		t.Fatalf("ComputeDispatchBalances failed: %v", err)
+	}
+
+	b := balances[DispatchItemKey("sub_item", subItem.Id)]
+	if b == nil {
+		t.Fatal("expected a balance for the sub item")
+	}
+	if b.Required != 50 || b.Dispatched != 20 || b.InDraft != 4 || b.Remaining() != 26 {
+		t.Errorf("sub item balance = required %v, dispatched %v, in draft %v, remaining %v; want 50, 20, 4, 26",
+			b.Required, b.Dispatched, b.InDraft, b.Remaining())
+	}
+	if ss := balances[DispatchItemKey("sub_sub_item", subSub.Id)]; ss == nil || ss.Required != 100 || ss.Dispatched != 0 {
+		t.Errorf("sub sub item balance = %+v, want 100 required and nothing dispatched", ss)
+	}
+
+	// The draft being edited does not count against itself
+	balances, err = ComputeDispatchBalances(app, project.Id, draft.Id)
+	if err != nil {
+		t.Fatalf("ComputeDispatchBalances failed: %v", err)
+	}
+	if got := balances[DispatchItemKey("sub_item", subItem.Id)].InDraft; got != 0 {
+		t.Errorf("InDraft excluding the edited draft = %v, want 0", got)
+	}
+}
+
+func TestCheckOverDispatch(t *testing.T) {
+	balances := map[string]*DispatchBalance{
+		"sub_item_a": {SourceItemType: "sub_item", SourceItemID: "a", Description: "Panel", Required: 10, Dispatched: 4, InDraft: 2},
+		"sub_item_b": {SourceItemType: "sub_item", SourceItemID: "b", Description: "Cable", Required: 10},
+	}
+	items := []ShipmentItemParams{
+		{SourceItemType: "sub_item", SourceItemID: "a", TotalQty: 5},
+		{SourceItemType: "sub_item", SourceItemID: "b", TotalQty: 10},
+		{SourceItemType: "sub_item", SourceItemID: "not-in-boq", TotalQty: 99},
+	}
+
+	over := CheckOverDispatch(balances, items)
+	if len(over) != 1 || over[0].SourceItemID != "a" || over[0].Remaining != 4 {
+		t.Fatalf("CheckOverDispatch = %+v, want only item a with 4 remaining", over)
+	}
+	if want := "Panel: 5 exceeds the remaining BOQ balance of 4"; over[0].Message() != want {
+		t.Errorf("Message() = %q, want %q", over[0].Message(), want)
+	}
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// Document kinds that carry a verification QR code.
+const (
+	VerifyKindDC = "dc"
+	VerifyKindPO = "po"
+)
+
+// verifySignatureBytes is how much of the HMAC-SHA256 a token keeps; 12
+// bytes keep the QR code small while leaving forgery out of reach.
+const verifySignatureBytes = 12
+
+// Verification states shown on the public verification page.
+const (
+	VerifyStateIssued    = "issued"
+	VerifyStateCancelled = "cancelled"
+	VerifyStateDraft     = "draft"
+)
+
+// DocumentVerification is the little a public verification page may show
+// about a document.
+type DocumentVerification struct {
+	Kind   string // VerifyKindDC or VerifyKindPO
+	Title  string // "Delivery Challan" or "Purchase Order"
+	Number string
+	Date   string
+	State  string  // VerifyStateIssued, VerifyStateCancelled or VerifyStateDraft
+	Total  float64 // the total signed into the printed code; 0 when it printed none
+}
+
+func verifySignature(key []byte, kind, id, paise string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(kind + ":" + id + ":" + paise))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:verifySignatureBytes])
+}
+
+// SignVerificationToken returns the token that proves a document was
+// printed by this app with the given total, in the form
+// "<kind>.<record id>.<total in paise>.<signature>". Signing the total
+// means a printout with an altered amount no longer matches its code.
+func SignVerificationToken(key []byte, kind, id string, total float64) string {
+	paise := strconv.FormatInt(int64(math.Round(total*100)), 10)
+	return kind + "." + id + "." + paise + "." + verifySignature(key, kind, id, paise)
+}
+
+// ParseVerificationToken checks a token's signature and returns the
+// document and total it was issued for.
+func ParseVerificationToken(key []byte, token string) (kind, id string, total float64, ok bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 4 || parts[1] == "" {
+		return "", "", 0, false
+	}
+	kind, id = parts[0], parts[1]
+	if kind != VerifyKindDC && kind != VerifyKindPO {
+		return "", "", 0, false
+	}
+	paise, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil || paise < 0 {
+		return "", "", 0, false
+	}
+	if !hmac.Equal([]byte(parts[3]), []byte(verifySignature(key, kind, id, parts[2]))) {
+		return "", "", 0, false
+	}
+	return kind, id, float64(paise) / 100, true
+}
+
+// DocumentQRContent is the text encoded in a document's QR code. A total of
+// zero is left out, as official DCs print no amounts.
+func DocumentQRContent(title, number, date string, total float64, verifyURL string) string {
+	lines := []string{
+		fmt.Sprintf("%s %s", title, number),
+		"Date: " + verifyDate(date),
+	}
+	if total > 0 {
+		lines = append(lines, fmt.Sprintf("Total: INR %.2f", total))
+	}
+	lines = append(lines, "Verify: "+verifyURL)
+	return strings.Join(lines, "\n")
+}
+
+// verifyDate drops the time from a stored date.
+func verifyDate(date string) string {
+	if len(date) > 10 {
+		return date[:10]
+	}
+	return date
+}
+
+// verifyState reduces a DC or PO status to what the verification page
+// shows: every status past draft counts as issued until it is cancelled.
+func verifyState(status string) string {
+	switch status {
+	case "draft":
+		return VerifyStateDraft
+	case "cancelled":
+		return VerifyStateCancelled
+	}
+	return VerifyStateIssued
+}
+
+// VerifyDocument loads what the public verification page shows about a
+// DC or PO, with the total its token was signed for.
+func VerifyDocument(app core.App, kind, id string, total float64) (*DocumentVerification, error) {
+	switch kind {
+	case VerifyKindDC:
+		dc, err := app.FindRecordById("delivery_challans", id)
+		if err != nil {
+			return nil, fmt.Errorf("delivery challan not found: %w", err)
+		}
+		return &DocumentVerification{
+			Kind:   kind,
+			Title:  "Delivery Challan",
+			Number: dc.GetString("dc_number"),
+			Date:   verifyDate(dc.GetString("challan_date")),
+			State:  verifyState(dc.GetString("status")),
+			Total:  total,
+		}, nil
+	case VerifyKindPO:
+		po, err := app.FindRecordById("purchase_orders", id)
+		if err != nil {
+			return nil, fmt.Errorf("purchase order not found: %w", err)
+		}
+		return &DocumentVerification{
+			Kind:   kind,
+			Title:  "Purchase Order",
+			Number: po.GetString("po_number"),
+			Date:   verifyDate(po.GetString("order_date")),
+			State:  verifyState(po.GetString("status")),
+			Total:  total,
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown document kind %q", kind)
+}
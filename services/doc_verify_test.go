@@ -0,0 +1,92 @@
+package services
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVerificationToken(t *testing.T) {
+	key := []byte("test-verification-key")
+	token := SignVerificationToken(key, VerifyKindDC, "abc123def456ghi", 70800.5)
+
+	kind, id, total, ok := ParseVerificationToken(key, token)
+	if !ok || kind != VerifyKindDC || id != "abc123def456ghi" || total != 70800.5 {
+		t.Fatalf("expected the token to round-trip, got %q %q %v %v", kind, id, total, ok)
+	}
+
+	sig := token[strings.LastIndex(token, ".")+1:]
+	for name, bad := range map[string]string{
+		"other document": "dc.abc123def456ghj.7080050." + sig,
+		"other kind":     "po.abc123def456ghi.7080050." + sig,
+		"other total":    "dc.abc123def456ghi.9080050." + sig,
+		"unknown kind":   SignVerificationToken(key, "grn", "abc123def456ghi", 0),
+		"no total":       "dc.abc123def456ghi." + sig,
+		"no signature":   "dc.abc123def456ghi.7080050",
+		"empty":          "",
+	} {
+		if _, _, _, ok := ParseVerificationToken(key, bad); ok {
+			t.Errorf("%s: expected %q to be rejected", name, bad)
+		}
+	}
+	if _, _, _, ok := ParseVerificationToken([]byte("another-key"), token); ok {
+		t.Error("expected a token signed with another key to be rejected")
+	}
+}
+
+func TestDocumentQRContent(t *testing.T) {
+	got := DocumentQRContent("Delivery Challan", "TDC-001", "2026-03-10 00:00:00.000Z", 70800, "https://example.com/verify/x")
+	want := "Delivery Challan TDC-001\nDate: 2026-03-10\nTotal: INR 70800.00\nVerify: https://example.com/verify/x"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	if got := DocumentQRContent("Delivery Challan", "ODC-001", "2026-03-10", 0, "u"); strings.Contains(got, "Total") {
+		t.Errorf("expected no total for an unpriced DC, got %q", got)
+	}
+}
+
+func TestGenerateDCPDF_VerifyQR(t *testing.T) {
+	data := &DCExportData{
+		CompanyName: "FSS Engineering",
+		DCNumber:    "TDC-001",
+		DCType:      "transit",
+		Status:      "issued",
+		ChallanDate: "2026-03-10",
+		LineItems: []DCExportLineItem{
+			{SINo: 1, Description: "DC cable", HSNCode: "8544", Qty: 2, UOM: "Mtrs", Rate: 30000, TaxPercent: 18, Taxable: 60000, TaxAmount: 10800, Total: 70800},
+		},
+		GrandTotal: 70800,
+	}
+	plain, err := GenerateDCPDF(data)
+	if err != nil {
+		t.Fatalf("GenerateDCPDF() error = %v", err)
+	}
+
+	data.VerifyQR = DocumentQRContent("Delivery Challan", data.DCNumber, data.ChallanDate, data.GrandTotal, "https://example.com/verify/dc.x.y")
+	withQR, err := GenerateDCPDF(data)
+	if err != nil {
+		t.Fatalf("GenerateDCPDF() with QR error = %v", err)
+	}
+	if len(withQR) <= len(plain) {
+		t.Errorf("expected the QR code to add to the PDF, got %d bytes vs %d", len(withQR), len(plain))
+	}
+}
+
+func TestGeneratePOPDF_VerifyQR(t *testing.T) {
+	data := &POExportData{
+		CompanyName: "FSS Engineering",
+		PONumber:    "FY-24-25/006",
+		OrderDate:   "2025-01-15",
+		Vendor:      POExportVendor{Name: "Test Vendor"},
+		GrandTotal:  1180,
+		VerifyQR:    DocumentQRContent("Purchase Order", "FY-24-25/006", "2025-01-15", 1180, "https://example.com/verify/po.x.y"),
+	}
+
+	result, err := GeneratePOPDF(data)
+	if err != nil {
+		t.Fatalf("GeneratePOPDF() error = %v", err)
+	}
+	if len(result) == 0 {
+		t.Fatal("GeneratePOPDF() returned empty bytes")
+	}
+}
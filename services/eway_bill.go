@@ -0,0 +1,644 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"mime/multipart"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// EwayBillThreshold is the consignment value, tax included, below which no
+// e-way bill is needed.
+const EwayBillThreshold = 50000.0
+
+// Codes of the NIC e-way bill bulk-upload JSON. Every DC goes out as an
+// outward delivery challan moved by road.
+const (
+	ewayBillJSONVersion   = "1.0.0621"
+	ewbSupplyOutward      = "O"
+	ewbDocTypeChallan     = "CHL"
+	ewbSubSupplySupply    = 1
+	ewbSubSupplyOthers    = 8
+	ewbTransModeRoad      = 1
+	ewbVehicleRegular     = "R"
+	ewbUnregisteredGSTIN  = "URP"
+	ewbTransRegular       = 1 // bill-to and ship-to, bill-from and dispatch-from the same
+	ewbTransBillToShipTo  = 2
+	ewbTransBillFromDisp  = 3
+	ewbTransCombination   = 4
+	ewbDateLayout         = "02/01/2006"
+	ewbMaxDocNumberLength = 16
+)
+
+var (
+	ewbDocNumberPattern = regexp.MustCompile(`^[A-Za-z0-9/-]+$`)
+	ewbHSNPattern       = regexp.MustCompile(`^[0-9]{4,8}$`)
+	ewbVehiclePattern   = regexp.MustCompile(`^[A-Z]{2}[0-9]{1,2}[A-Z]{0,3}[0-9]{4}$`)
+	ewbNumberPattern    = regexp.MustCompile(`^[0-9]{12}$`)
+)
+
+// EwayBillItem is a line of an e-way bill.
+type EwayBillItem struct {
+	ItemNo        int     `json:"itemNo"`
+	ProductName   string  `json:"productName"`
+	ProductDesc   string  `json:"productDesc"`
+	HSNCode       int     `json:"hsnCode"`
+	Quantity      float64 `json:"quantity"`
+	QtyUnit       string  `json:"qtyUnit"`
+	TaxableAmount float64 `json:"taxableAmount"`
+	SGSTRate      float64 `json:"sgstRate"`
+	CGSTRate      float64 `json:"cgstRate"`
+	IGSTRate      float64 `json:"igstRate"`
+	CessRate      float64 `json:"cessRate"`
+	CessNonAdvol  float64 `json:"cessNonAdvol"`
+}
+
+// EwayBill is one bill of the NIC bulk-upload JSON.
+type EwayBill struct {
+	UserGSTIN           string         `json:"userGstin"`
+	SupplyType          string         `json:"supplyType"`
+	SubSupplyType       int            `json:"subSupplyType"`
+	SubSupplyDesc       string         `json:"subSupplyDesc"`
+	DocType             string         `json:"docType"`
+	DocNo               string         `json:"docNo"`
+	DocDate             string         `json:"docDate"`
+	TransType           int            `json:"transType"`
+	FromGSTIN           string         `json:"fromGstin"`
+	FromTrdName         string         `json:"fromTrdName"`
+	FromAddr1           string         `json:"fromAddr1"`
+	FromAddr2           string         `json:"fromAddr2"`
+	FromPlace           string         `json:"fromPlace"`
+	FromPincode         int            `json:"fromPincode"`
+	FromStateCode       int            `json:"fromStateCode"`
+	ActualFromStateCode int            `json:"actualFromStateCode"`
+	ToGSTIN             string         `json:"toGstin"`
+	ToTrdName           string         `json:"toTrdName"`
+	ToAddr1             string         `json:"toAddr1"`
+	ToAddr2             string         `json:"toAddr2"`
+	ToPlace             string         `json:"toPlace"`
+	ToPincode           int            `json:"toPincode"`
+	ToStateCode         int            `json:"toStateCode"`
+	ActualToStateCode   int            `json:"actualToStateCode"`
+	TotalValue          float64        `json:"totalValue"`
+	CGSTValue           float64        `json:"cgstValue"`
+	SGSTValue           float64        `json:"sgstValue"`
+	IGSTValue           float64        `json:"igstValue"`
+	CessValue           float64        `json:"cessValue"`
+	TotNonAdvolVal      float64        `json:"TotNonAdvolVal"`
+	OthValue            float64        `json:"OthValue"`
+	TotInvValue         float64        `json:"totInvValue"`
+	TransMode           int            `json:"transMode"`
+	TransDistance       int            `json:"transDistance"` // 0 lets the portal work it out from the PIN codes
+	TransporterName     string         `json:"transporterName"`
+	TransporterID       string         `json:"transporterId"`
+	TransDocNo          string         `json:"transDocNo"`
+	TransDocDate        string         `json:"transDocDate"`
+	VehicleNo           string         `json:"vehicleNo"`
+	VehicleType         string         `json:"vehicleType"`
+	MainHSNCode         int            `json:"mainHsnCode"`
+	ItemList            []EwayBillItem `json:"itemList"`
+
+	DCID string `json:"-"`
+}
+
+// EwayBillFile is the NIC bulk-upload JSON.
+type EwayBillFile struct {
+	Version   string     `json:"version"`
+	BillLists []EwayBill `json:"billLists"`
+}
+
+// EwayBillProblem is a reason a DC cannot go into the e-way bill JSON.
+type EwayBillProblem struct {
+	DCID     string
+	DCNumber string
+	Message  string
+}
+
+// EwayBillBatch is the outcome of preparing e-way bills for a set of DCs.
+type EwayBillBatch struct {
+	Bills    []EwayBill
+	Skipped  []EwayBill // below EwayBillThreshold, no e-way bill needed
+	Problems []EwayBillProblem
+}
+
+// JSON returns the bulk-upload file for the bills of the batch.
+func (b *EwayBillBatch) JSON() ([]byte, error) {
+	return json.MarshalIndent(EwayBillFile{Version: ewayBillJSONVersion, BillLists: b.Bills}, "", "  ")
+}
+
+// PrepareEwayBills builds the e-way bill of each DC. A DC with missing or
+// invalid details is reported in Problems and a DC below EwayBillThreshold
+// in Skipped; only the rest are in Bills.
+func PrepareEwayBills(app core.App, dcs []*core.Record) *EwayBillBatch {
+	batch := &EwayBillBatch{}
+	for _, dc := range dcs {
+		bill, problems := buildEwayBill(app, dc)
+		for _, msg := range problems {
+			batch.Problems = append(batch.Problems, EwayBillProblem{DCID: dc.Id, DCNumber: dc.GetString("dc_number"), Message: msg})
+		}
+		switch {
+		case len(problems) > 0:
+		case bill.TotInvValue < EwayBillThreshold:
+			batch.Skipped = append(batch.Skipped, bill)
+		default:
+			batch.Bills = append(batch.Bills, bill)
+		}
+	}
+	return batch
+}
+
+// ShipmentGroupEwayBillDCs returns the DCs of a shipment group that need an
+// e-way bill: its official DCs, one per site. The transit DC only summarises
+// the same goods, so it is left out.
+func ShipmentGroupEwayBillDCs(app core.App, sgID string) ([]*core.Record, error) {
+	dcs, err := app.FindRecordsByFilter("delivery_challans",
+		"shipment_group = {:sgId} && dc_type = 'official' && status != 'cancelled'",
+		"dc_number", 0, 0, map[string]any{"sgId": sgID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch shipment group DCs: %w", err)
+	}
+	return dcs, nil
+}
+
+// buildEwayBill converts a DC to an e-way bill, returning everything that
+// stops it being uploaded. Goods move from the dispatch-from address (else
+// bill-from) to the ship-to address (else bill-to).
+func buildEwayBill(app core.App, dc *core.Record) (EwayBill, []string) {
+	var problems []string
+	bill := EwayBill{
+		DCID:          dc.Id,
+		SupplyType:    ewbSupplyOutward,
+		SubSupplyType: ewbSubSupplySupply,
+		DocType:       ewbDocTypeChallan,
+		DocNo:         dc.GetString("dc_number"),
+		TransMode:     ewbTransModeRoad,
+		VehicleType:   ewbVehicleRegular,
+	}
+	if dc.GetString("dc_type") == "returnable" {
+		bill.SubSupplyType = ewbSubSupplyOthers
+		bill.SubSupplyDesc = "Returnable"
+	}
+
+	switch dc.GetString("status") {
+	case "draft":
+		problems = append(problems, "the DC is still a draft; issue it first")
+	case "cancelled":
+		problems = append(problems, "the DC is cancelled")
+	}
+	if len(bill.DocNo) > ewbMaxDocNumberLength || !ewbDocNumberPattern.MatchString(bill.DocNo) {
+		problems = append(problems, fmt.Sprintf("DC number %q must be up to %d letters, digits, / or -", bill.DocNo, ewbMaxDocNumberLength))
+	}
+	challanDate := dc.GetString("challan_date")
+	if len(challanDate) >= 10 {
+		challanDate = challanDate[:10]
+	}
+	if d, err := time.Parse("2006-01-02", challanDate); err == nil {
+		bill.DocDate = d.Format(ewbDateLayout)
+	} else {
+		problems = append(problems, "challan date is missing")
+	}
+
+	// Parties
+	billFrom := ewbAddress(app, dc.GetString("bill_from_address"))
+	dispatchFrom := ewbAddress(app, dc.GetString("dispatch_from_address"))
+	billTo := ewbAddress(app, dc.GetString("bill_to_address"))
+	shipTo := ewbAddress(app, dc.GetString("ship_to_address"))
+	if billFrom == nil {
+		problems = append(problems, "bill-from address is missing")
+		billFrom = map[string]string{}
+	}
+	if billTo == nil {
+		billTo = shipTo
+	}
+	if billTo == nil {
+		problems = append(problems, "bill-to address is missing")
+		billTo = map[string]string{}
+	}
+	diffFrom := dispatchFrom != nil && dc.GetString("dispatch_from_address") != dc.GetString("bill_from_address")
+	diffTo := shipTo != nil && dc.GetString("bill_to_address") != "" && dc.GetString("ship_to_address") != dc.GetString("bill_to_address")
+	switch {
+	case diffFrom && diffTo:
+		bill.TransType = ewbTransCombination
+	case diffFrom:
+		bill.TransType = ewbTransBillFromDisp
+	case diffTo:
+		bill.TransType = ewbTransBillToShipTo
+	default:
+		bill.TransType = ewbTransRegular
+	}
+	if dispatchFrom == nil {
+		dispatchFrom = billFrom
+	}
+	if shipTo == nil {
+		shipTo = billTo
+	}
+
+	bill.FromGSTIN = strings.ToUpper(strings.TrimSpace(billFrom["gstin"]))
+	bill.UserGSTIN = bill.FromGSTIN
+	if bill.FromGSTIN == "" {
+		problems = append(problems, "bill-from GSTIN is missing")
+	} else if !ValidateGSTIN(bill.FromGSTIN) {
+		problems = append(problems, fmt.Sprintf("bill-from GSTIN %s is not a valid GSTIN", bill.FromGSTIN))
+	}
+	bill.ToGSTIN = strings.ToUpper(strings.TrimSpace(billTo["gstin"]))
+	if bill.ToGSTIN == "" {
+		bill.ToGSTIN = ewbUnregisteredGSTIN
+	} else if !ValidateGSTIN(bill.ToGSTIN) {
+		problems = append(problems, fmt.Sprintf("bill-to GSTIN %s is not a valid GSTIN", bill.ToGSTIN))
+	}
+
+	bill.FromTrdName = billFrom["company_name"]
+	bill.FromAddr1, bill.FromAddr2, bill.FromPlace, bill.FromPincode = ewbPlace(dispatchFrom, "dispatch-from", &problems)
+	bill.FromStateCode = ewbStateCode(billFrom, "bill-from", &problems)
+	bill.ActualFromStateCode = ewbStateCode(dispatchFrom, "dispatch-from", &problems)
+	bill.ToTrdName = billTo["company_name"]
+	bill.ToAddr1, bill.ToAddr2, bill.ToPlace, bill.ToPincode = ewbPlace(shipTo, "ship-to", &problems)
+	bill.ToStateCode = ewbStateCode(billTo, "bill-to", &problems)
+	bill.ActualToStateCode = ewbStateCode(shipTo, "ship-to", &problems)
+	if bill.FromTrdName == "" {
+		problems = append(problems, "bill-from company name is missing")
+	}
+	if bill.ToTrdName == "" {
+		problems = append(problems, "bill-to company name is missing")
+	}
+
+	problems = append(problems, fillEwayBillItems(app, dc, &bill)...)
+	problems = append(problems, fillEwayBillTransport(app, dc, &bill)...)
+	return bill, problems
+}
+
+// fillEwayBillItems adds the DC lines and value totals to the bill. A line
+// without a rate is priced from the transit DC of its shipment group, as
+// official DCs usually are.
+func fillEwayBillItems(app core.App, dc *core.Record, bill *EwayBill) []string {
+	var problems []string
+	lines, err := app.FindRecordsByFilter("dc_line_items", "dc = {:dcId}", "line_order", 0, 0, map[string]any{"dcId": dc.Id})
+	if err != nil || len(lines) == 0 {
+		return []string{"the DC has no line items"}
+	}
+	pricing, err := shipmentGroupPricing(app, dc.GetString("shipment_group"))
+	if err != nil {
+		return []string{err.Error()}
+	}
+	taxType, _ := dcTaxTerms(app, dc)
+
+	mainTaxable := -1.0
+	for i, li := range lines {
+		item := EwayBillItem{ItemNo: i + 1, Quantity: li.GetFloat("quantity")}
+		rate, taxPct := li.GetFloat("rate"), li.GetFloat("tax_percentage")
+		if rate == 0 {
+			key := li.GetString("source_item_type") + ":" + li.GetString("source_item_id")
+			if p, ok := pricing[key]; ok {
+				rate, taxPct = p.GetFloat("rate"), p.GetFloat("tax_percentage")
+			}
+		}
+		taxable := li.GetFloat("taxable_amount")
+		if taxable == 0 {
+			taxable = item.Quantity * rate
+		}
+		item.TaxableAmount = ewbRound(taxable)
+
+		collection := "sub_items"
+		if li.GetString("source_item_type") == "sub_sub_item" {
+			collection = "sub_sub_items"
+		}
+		hsn := ""
+		if source, err := app.FindRecordById(collection, li.GetString("source_item_id")); err == nil {
+			item.ProductName = source.GetString("description")
+			hsn = strings.TrimSpace(source.GetString("hsn_code"))
+			item.QtyUnit = ewbUnit(source.GetString("uom"))
+		}
+		item.ProductDesc = item.ProductName
+		if item.QtyUnit == "" {
+			item.QtyUnit = "OTH"
+		}
+		label := fmt.Sprintf("line %d", item.ItemNo)
+		if item.ProductName != "" {
+			label += " (" + item.ProductName + ")"
+		}
+		switch {
+		case hsn == "":
+			problems = append(problems, label+" has no HSN code")
+		case !ewbHSNPattern.MatchString(hsn):
+			problems = append(problems, fmt.Sprintf("%s HSN code %s must be 4 to 8 digits", label, hsn))
+		default:
+			item.HSNCode, _ = strconv.Atoi(hsn)
+		}
+		if item.Quantity <= 0 {
+			problems = append(problems, label+" has no quantity")
+		}
+		if item.TaxableAmount <= 0 {
+			problems = append(problems, label+" has no rate")
+		}
+
+		tax := taxable * taxPct / 100
+		if taxType == TaxTypeIGST {
+			item.IGSTRate = taxPct
+			bill.IGSTValue += tax
+		} else {
+			item.CGSTRate = taxPct / 2
+			item.SGSTRate = taxPct / 2
+			bill.CGSTValue += tax / 2
+			bill.SGSTValue += tax / 2
+		}
+		bill.TotalValue += taxable
+		if taxable > mainTaxable {
+			mainTaxable = taxable
+			bill.MainHSNCode = item.HSNCode
+		}
+		bill.ItemList = append(bill.ItemList, item)
+	}
+
+	bill.TotalValue = ewbRound(bill.TotalValue)
+	bill.CGSTValue = ewbRound(bill.CGSTValue)
+	bill.SGSTValue = ewbRound(bill.SGSTValue)
+	bill.IGSTValue = ewbRound(bill.IGSTValue)
+	bill.TotInvValue = ewbRound(bill.TotalValue + bill.CGSTValue + bill.SGSTValue + bill.IGSTValue)
+	return problems
+}
+
+// fillEwayBillTransport adds the vehicle and transporter to the bill. An
+// official DC without its own transit details travels on its transit DC's.
+func fillEwayBillTransport(app core.App, dc *core.Record, bill *EwayBill) []string {
+	detail := dcTransitDetail(app, dc)
+	if detail == nil {
+		return []string{"no vehicle number or transporter; add the DC's transport details"}
+	}
+
+	var problems []string
+	if tID := detail.GetString("transporter"); tID != "" {
+		if t, err := app.FindRecordById("transporters", tID); err == nil {
+			bill.TransporterName = t.GetString("company_name")
+			bill.TransporterID = strings.ToUpper(strings.TrimSpace(t.GetString("gst_number")))
+		}
+	}
+	if bill.TransporterID != "" && !ValidateGSTIN(bill.TransporterID) {
+		problems = append(problems, fmt.Sprintf("transporter GSTIN %s is not a valid GSTIN", bill.TransporterID))
+	}
+	bill.VehicleNo = strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(detail.GetString("vehicle_number")))
+	if bill.VehicleNo != "" && !ewbVehiclePattern.MatchString(bill.VehicleNo) {
+		problems = append(problems, fmt.Sprintf("vehicle number %s is not a valid registration number", bill.VehicleNo))
+	}
+	if bill.VehicleNo == "" && bill.TransporterID == "" {
+		problems = append(problems, "no vehicle number or transporter GSTIN; add one to the DC's transport details")
+	}
+	if docket := strings.TrimSpace(detail.GetString("docket_number")); docket != "" {
+		bill.TransDocNo = docket
+		bill.TransDocDate = bill.DocDate
+	}
+	return problems
+}
+
+// dcTransitDetail returns the transit details of a DC, falling back to those
+// of the transit DC of its shipment group.
+func dcTransitDetail(app core.App, dc *core.Record) *core.Record {
+	details, _ := app.FindRecordsByFilter("dc_transit_details", "dc = {:did}", "", 1, 0, map[string]any{"did": dc.Id})
+	if len(details) > 0 {
+		return details[0]
+	}
+	if sgID := dc.GetString("shipment_group"); sgID != "" {
+		details, _ = app.FindRecordsByFilter("dc_transit_details",
+			"dc.shipment_group = {:sgId} && dc.dc_type = 'transit'", "", 1, 0, map[string]any{"sgId": sgID})
+		if len(details) > 0 {
+			return details[0]
+		}
+	}
+	return nil
+}
+
+// ewbAddress returns the data of an address, or nil if it is not set.
+func ewbAddress(app core.App, addressID string) map[string]string {
+	if addressID == "" {
+		return nil
+	}
+	rec, err := app.FindRecordById("addresses", addressID)
+	if err != nil {
+		return nil
+	}
+	return ReadAddressData(rec)
+}
+
+// ewbPlace returns the address lines, place and PIN code of a party,
+// recording any that are missing.
+func ewbPlace(addr map[string]string, party string, problems *[]string) (string, string, string, int) {
+	line1, line2 := addr["address_line_1"], addr["address_line_2"]
+	place := addr["city"]
+	if place == "" {
+		place = addr["district"]
+	}
+	if line1 == "" {
+		*problems = append(*problems, party+" address line is missing")
+	}
+	if place == "" {
+		*problems = append(*problems, party+" city is missing")
+	}
+	pin := strings.TrimSpace(addr["pin_code"])
+	pinCode := 0
+	switch {
+	case pin == "":
+		*problems = append(*problems, party+" PIN code is missing")
+	case !ValidatePINCode(pin):
+		*problems = append(*problems, fmt.Sprintf("%s PIN code %s must be 6 digits", party, pin))
+	default:
+		pinCode, _ = strconv.Atoi(pin)
+	}
+	return line1, line2, place, pinCode
+}
+
+// ewbStateCode returns the GST state code of a party, from its GSTIN or
+// state, recording it if neither gives one.
+func ewbStateCode(addr map[string]string, party string, problems *[]string) int {
+	code, err := strconv.Atoi(gstStateKey(addr["state"], addr["gstin"]))
+	if err != nil {
+		if addr["state"] == "" {
+			*problems = append(*problems, party+" state is missing")
+		} else {
+			*problems = append(*problems, fmt.Sprintf("%s state %q is not an Indian state", party, addr["state"]))
+		}
+		return 0
+	}
+	return code
+}
+
+// ewbUnit returns the unit quantity code the portal accepts for a unit of
+// measure used on BOQ items. Anything unknown goes out as OTH.
+func ewbUnit(uom string) string {
+	switch strings.ToLower(strings.TrimSpace(uom)) {
+	case "nos", "no", "no.", "each", "ea":
+		return "NOS"
+	case "pcs", "pc":
+		return "PCS"
+	case "set", "sets":
+		return "SET"
+	case "m", "mtr", "mtrs", "meter", "metre", "rmt":
+		return "MTR"
+	case "km":
+		return "KME"
+	case "kg", "kgs":
+		return "KGS"
+	case "l", "ltr", "litre":
+		return "LTR"
+	case "box":
+		return "BOX"
+	case "roll", "rolls":
+		return "ROL"
+	case "pair", "pairs":
+		return "PRS"
+	case "sqm", "sq.m":
+		return "SQM"
+	default:
+		return "OTH"
+	}
+}
+
+func ewbRound(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// EwayBillNumberUpdate is an e-way bill number read back from the portal.
+type EwayBillNumberUpdate struct {
+	DCID           string
+	DCNumber       string
+	EwayBillNumber string
+}
+
+// EwayBillImportResult is the outcome of importing e-way bill numbers.
+// Nothing is saved when there are errors.
+type EwayBillImportResult struct {
+	Updated []EwayBillNumberUpdate
+	Errors  []ValidationError
+}
+
+// ewbImportColumns maps the normalised column headers of the portal's
+// results (and of a hand-made sheet) to "doc" or "ewb".
+var ewbImportColumns = map[string]string{
+	"docno": "doc", "documentno": "doc", "documentnumber": "doc", "docnumber": "doc", "dcno": "doc", "dcnumber": "doc",
+	"ewbno": "ewb", "ewbnumber": "ewb", "ewaybillno": "ewb", "ewaybillnumber": "ewb", "ewaybill": "ewb",
+}
+
+// ImportEwayBillNumbers reads the e-way bill numbers the portal returned
+// from a .csv or .xlsx file with a Doc No and an EWB No column, and saves
+// each on its DC's transit details. Every row is checked first; if any is
+// wrong nothing is saved.
+func ImportEwayBillNumbers(ctx context.Context, app *pocketbase.PocketBase, projectID string, file multipart.File, fileName string) (*EwayBillImportResult, error) {
+	headers, rows, err := parseImportFile(file, fileName)
+	if err != nil {
+		return nil, err
+	}
+	docCol, ewbCol := -1, -1
+	for i, h := range headers {
+		switch ewbImportColumns[importColumnKey(h)] {
+		case "doc":
+			if docCol < 0 {
+				docCol = i
+			}
+		case "ewb":
+			if ewbCol < 0 {
+				ewbCol = i
+			}
+		}
+	}
+	if docCol < 0 || ewbCol < 0 {
+		return nil, fmt.Errorf("the file needs a Doc No and an EWB No column")
+	}
+
+	result := &EwayBillImportResult{}
+	seen := make(map[string]int)
+	for i, row := range rows {
+		rowNum := i + 2
+		cell := func(col int) string {
+			if col < len(row) {
+				return strings.TrimSpace(row[col])
+			}
+			return ""
+		}
+		docNo := cell(docCol)
+		ewb := strings.NewReplacer(" ", "", "-", "").Replace(cell(ewbCol))
+		if docNo == "" && ewb == "" {
+			continue
+		}
+		if docNo == "" {
+			result.Errors = append(result.Errors, ValidationError{Row: rowNum, Field: "Doc No", Message: "Doc No is required"})
+			continue
+		}
+		if !ewbNumberPattern.MatchString(ewb) {
+			result.Errors = append(result.Errors, ValidationError{Row: rowNum, Field: "EWB No", Message: fmt.Sprintf("%q is not a 12-digit e-way bill number", cell(ewbCol))})
+			continue
+		}
+		if prev, ok := seen[docNo]; ok {
+			result.Errors = append(result.Errors, ValidationError{Row: rowNum, Field: "Doc No", Message: fmt.Sprintf("%s is also on row %d", docNo, prev)})
+			continue
+		}
+		seen[docNo] = rowNum
+
+		dcs, _ := app.FindRecordsByFilter("delivery_challans", "project = {:pid} && dc_number = {:num}", "", 1, 0,
+			map[string]any{"pid": projectID, "num": docNo})
+		if len(dcs) == 0 {
+			result.Errors = append(result.Errors, ValidationError{Row: rowNum, Field: "Doc No", Message: fmt.Sprintf("No DC numbered %s in this project", docNo)})
+			continue
+		}
+		if dcs[0].GetString("status") == "cancelled" {
+			result.Errors = append(result.Errors, ValidationError{Row: rowNum, Field: "Doc No", Message: fmt.Sprintf("%s is cancelled", docNo)})
+			continue
+		}
+		result.Updated = append(result.Updated, EwayBillNumberUpdate{DCID: dcs[0].Id, DCNumber: docNo, EwayBillNumber: ewb})
+	}
+	if len(result.Errors) > 0 {
+		return result, nil
+	}
+	if len(result.Updated) == 0 {
+		return nil, fmt.Errorf("the file has no e-way bill numbers")
+	}
+
+	err = app.RunInTransaction(func(txApp core.App) error {
+		for _, u := range result.Updated {
+			if err := setDCEwayBillNumber(ctx, txApp, u.DCID, u.EwayBillNumber); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// setDCEwayBillNumber saves an e-way bill number on a DC's transit details,
+// creating them if the DC has none. A transfer DC keeps a copy on its
+// transfer record too.
+func setDCEwayBillNumber(ctx context.Context, app core.App, dcID, number string) error {
+	details, _ := app.FindRecordsByFilter("dc_transit_details", "dc = {:did}", "", 1, 0, map[string]any{"did": dcID})
+	var detail *core.Record
+	if len(details) > 0 {
+		detail = details[0]
+	} else {
+		col, err := app.FindCollectionByNameOrId("dc_transit_details")
+		if err != nil {
+			return fmt.Errorf("dc_transit_details collection not found: %w", err)
+		}
+		detail = core.NewRecord(col)
+		detail.Set("dc", dcID)
+	}
+	detail.Set("eway_bill_number", number)
+	if err := app.SaveWithContext(ctx, detail); err != nil {
+		return fmt.Errorf("failed to save e-way bill number: %w", err)
+	}
+
+	transfers, _ := app.FindRecordsByFilter("transfer_dcs", "dc = {:did}", "", 1, 0, map[string]any{"did": dcID})
+	for _, tdc := range transfers {
+		tdc.Set("eway_bill_number", number)
+		if err := app.SaveWithContext(ctx, tdc); err != nil {
+			return fmt.Errorf("failed to save e-way bill number on transfer DC: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/testhelpers"
+)
+
+// ewayBillGroup creates an issued IGST shipment group whose transit DC
+// prices 2 cables at ₹30,000 + 18% and travels on MH 12 AB 1234, with one
+// official DC taking both cables to an unregistered site.
+func ewayBillGroup(t *testing.T) (app *pocketbase.PocketBase, sg, official *core.Record) {
+	t.Helper()
+	app = testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "E-way Bill Project")
+	boq := testhelpers.CreateTestBOQ(t, app, project.Id, "Main BOQ")
+	main := testhelpers.CreateTestMainBOQItem(t, app, boq.Id, "Solar plant")
+	cable := testhelpers.CreateTestSubItem(t, app, main.Id, "DC cable")
+
+	company := testhelpers.CreateTestAddress(t, app, project.Id, "bill_from", "FSS Solar")
+	company.Set("gstin", "27AAACF1234A1Z5")
+	if err := app.Save(company); err != nil {
+		t.Fatalf("failed to save company GSTIN: %v", err)
+	}
+	site := testhelpers.CreateTestAddress(t, app, project.Id, "ship_to", "Site A")
+
+	sg, transit, official := issuedShipmentGroup(t, app, project.Id, "001")
+	for _, dc := range []*core.Record{transit, official} {
+		dc.Set("bill_from_address", company.Id)
+		dc.Set("bill_to_address", site.Id)
+		dc.Set("ship_to_address", site.Id)
+		if err := app.Save(dc); err != nil {
+			t.Fatalf("failed to save DC addresses: %v", err)
+		}
+	}
+	saveTestRecord(t, app, "dc_line_items", map[string]any{
+		"dc": transit.Id, "source_item_type": "sub_item", "source_item_id": cable.Id, "quantity": 2,
+		"rate": 30000, "tax_percentage": 18, "taxable_amount": 60000, "tax_amount": 10800, "total_amount": 70800,
+	})
+	saveTestRecord(t, app, "dc_line_items", map[string]any{
+		"dc": official.Id, "source_item_type": "sub_item", "source_item_id": cable.Id, "quantity": 2,
+	})
+	transporter := saveTestRecord(t, app, "transporters", map[string]any{
+		"project": project.Id, "company_name": "Speed Roadways", "gst_number": "27AABCS1429B1Z1",
+	})
+	saveTestRecord(t, app, "dc_transit_details", map[string]any{
+		"dc": transit.Id, "transporter": transporter.Id, "vehicle_number": "MH 12 AB 1234",
+	})
+	return app, sg, official
+}
+
+func TestPrepareEwayBills(t *testing.T) {
+	app, sg, official := ewayBillGroup(t)
+	dcs, err := ShipmentGroupEwayBillDCs(app, sg.Id)
+	if err != nil || len(dcs) != 1 || dcs[0].Id != official.Id {
+		t.Fatalf("expected the official DC only, got %d (%v)", len(dcs), err)
+	}
+
+	batch := PrepareEwayBills(app, dcs)
+	if len(batch.Problems) > 0 || len(batch.Bills) != 1 {
+		t.Fatalf("expected 1 bill, got %+v", batch)
+	}
+	bill := batch.Bills[0]
+	if bill.DocNo != "ODC-001" || bill.DocDate != "10/03/2026" || bill.DocType != "CHL" {
+		t.Errorf("unexpected document %s %s %s", bill.DocType, bill.DocNo, bill.DocDate)
+	}
+	if bill.FromGSTIN != "27AAACF1234A1Z5" || bill.ToGSTIN != "URP" || bill.FromStateCode != 27 || bill.ToPincode != 400001 {
+		t.Errorf("unexpected parties %+v", bill)
+	}
+	if bill.TotalValue != 60000 || bill.IGSTValue != 10800 || bill.CGSTValue != 0 || bill.TotInvValue != 70800 {
+		t.Errorf("expected ₹60,000 + ₹10,800 IGST priced from the transit DC, got %+v", bill)
+	}
+	item := bill.ItemList[0]
+	if item.HSNCode != 8504 || item.QtyUnit != "MTR" || item.IGSTRate != 18 {
+		t.Errorf("unexpected item %+v", item)
+	}
+	if bill.VehicleNo != "MH12AB1234" || bill.TransporterID != "27AABCS1429B1Z1" {
+		t.Errorf("expected the transit DC's transport, got %q %q", bill.VehicleNo, bill.TransporterID)
+	}
+
+	out, err := batch.JSON()
+	if err != nil || !strings.Contains(string(out), `"billLists"`) || !strings.Contains(string(out), `"docNo": "ODC-001"`) {
+		t.Errorf("unexpected JSON %s (%v)", out, err)
+	}
+}
+
+func TestPrepareEwayBills_Threshold(t *testing.T) {
+	app, _, official := ewayBillGroup(t)
+	lines, _ := app.FindRecordsByFilter("dc_line_items", "dc = {:did}", "", 0, 0, map[string]any{"did": official.Id})
+	lines[0].Set("quantity", 1)
+	if err := app.Save(lines[0]); err != nil {
+		t.Fatalf("failed to save line: %v", err)
+	}
+
+	batch := PrepareEwayBills(app, []*core.Record{official})
+	if len(batch.Bills) != 0 || len(batch.Skipped) != 1 || batch.Skipped[0].TotInvValue != 35400 {
+		t.Errorf("expected ₹35,400 to be below the threshold, got %+v", batch)
+	}
+}
+
+func TestPrepareEwayBills_Problems(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(t *testing.T, app *pocketbase.PocketBase, dc *core.Record)
+		want string
+	}{
+		{"draft", func(t *testing.T, app *pocketbase.PocketBase, dc *core.Record) {
+			dc.Set("status", "draft")
+			app.Save(dc)
+		}, "draft"},
+		{"invalid GSTIN", func(t *testing.T, app *pocketbase.PocketBase, dc *core.Record) {
+			addr, _ := app.FindRecordById("addresses", dc.GetString("bill_from_address"))
+			addr.Set("gstin", "27AAACF1234")
+			app.Save(addr)
+		}, "bill-from GSTIN 27AAACF1234 is not a valid GSTIN"},
+		{"missing PIN code", func(t *testing.T, app *pocketbase.PocketBase, dc *core.Record) {
+			addr, _ := app.FindRecordById("addresses", dc.GetString("ship_to_address"))
+			addr.Set("pin_code", "")
+			app.Save(addr)
+		}, "ship-to PIN code is missing"},
+		{"no HSN code", func(t *testing.T, app *pocketbase.PocketBase, dc *core.Record) {
+			lines, _ := app.FindRecordsByFilter("dc_line_items", "dc = {:did}", "", 0, 0, map[string]any{"did": dc.Id})
+			item, _ := app.FindRecordById("sub_items", lines[0].GetString("source_item_id"))
+			item.Set("hsn_code", "")
+			app.Save(item)
+		}, "has no HSN code"},
+		{"no transport", func(t *testing.T, app *pocketbase.PocketBase, dc *core.Record) {
+			details, _ := app.FindRecordsByFilter("dc_transit_details", "dc.shipment_group = {:sgId}", "", 0, 0, map[string]any{"sgId": dc.GetString("shipment_group")})
+			app.Delete(details[0])
+		}, "no vehicle number or transporter"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, _, official := ewayBillGroup(t)
+			tt.edit(t, app, official)
+			official, _ = app.FindRecordById("delivery_challans", official.Id)
+
+			batch := PrepareEwayBills(app, []*core.Record{official})
+			if len(batch.Bills) != 0 || len(batch.Problems) == 0 {
+				t.Fatalf("expected the DC to be held back, got %+v", batch)
+			}
+			found := false
+			for _, p := range batch.Problems {
+				found = found || strings.Contains(p.Message, tt.want)
+			}
+			if !found {
+				t.Errorf("expected a problem containing %q, got %+v", tt.want, batch.Problems)
+			}
+		})
+	}
+}
+
+func TestImportEwayBillNumbers(t *testing.T) {
+	app, _, official := ewayBillGroup(t)
+	projectID := official.GetString("project")
+	importCSV := func(content string) (*EwayBillImportResult, error) {
+		path := filepath.Join(t.TempDir(), "results.csv")
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write CSV: %v", err)
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			t.Fatalf("failed to open CSV: %v", err)
+		}
+		defer file.Close()
+		return ImportEwayBillNumbers(context.Background(), app, projectID, file, "results.csv")
+	}
+
+	// One bad row holds back the whole file
+	result, err := importCSV("Doc.No,EWB No.,EWB Date\nODC-001,3210 0987 6543,10/03/2026\nODC-999,321009876544,10/03/2026\n")
+	if err != nil {
+		t.Fatalf("ImportEwayBillNumbers failed: %v", err)
+	}
+	if len(result.Errors) != 1 || result.Errors[0].Row != 3 {
+		t.Fatalf("expected an unknown DC on row 3, got %+v", result.Errors)
+	}
+	if details, _ := app.FindRecordsByFilter("dc_transit_details", "dc = {:did}", "", 0, 0, map[string]any{"did": official.Id}); len(details) != 0 {
+		t.Error("expected nothing saved when a row is wrong")
+	}
+
+	result, err = importCSV("Doc.No,EWB No.\nODC-001,3210 0987 6543\n")
+	if err != nil || len(result.Errors) != 0 || len(result.Updated) != 1 {
+		t.Fatalf("expected 1 number imported, got %+v (%v)", result, err)
+	}
+	details, _ := app.FindRecordsByFilter("dc_transit_details", "dc = {:did}", "", 0, 0, map[string]any{"did": official.Id})
+	if len(details) != 1 || details[0].GetString("eway_bill_number") != "321009876543" {
+		t.Errorf("expected the e-way bill number on the official DC, got %d records", len(details))
+	}
+
+	if _, err := importCSV("DC Number,Amount\nODC-001,100\n"); err == nil {
+		t.Error("expected a file without an EWB No column to be rejected")
+	}
+}
@@ -0,0 +1,180 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+)
+
+// Approval statuses of a site expense. Only approved expenses count
+// towards a project's costs.
+const (
+	ExpenseStatusPending  = "pending"
+	ExpenseStatusApproved = "approved"
+	ExpenseStatusRejected = "rejected"
+)
+
+// ExpenseStatuses lists the approval statuses in display order.
+var ExpenseStatuses = []string{ExpenseStatusPending, ExpenseStatusApproved, ExpenseStatusRejected}
+
+var expenseStatusLabels = map[string]string{
+	ExpenseStatusPending:  "Pending approval",
+	ExpenseStatusApproved: "Approved",
+	ExpenseStatusRejected: "Rejected",
+}
+
+// ExpenseStatusLabel returns the display name of an expense status.
+func ExpenseStatusLabel(status string) string {
+	if label, ok := expenseStatusLabels[status]; ok {
+		return label
+	}
+	return status
+}
+
+// expenseDateLayouts are the date formats accepted for an expense date.
+// The form posts ISO dates; imported sheets often use Indian day-first
+// dates.
+var expenseDateLayouts = []string{"2006-01-02", "02-01-2006", "02/01/2006", "2-1-2006", "2/1/2006", "02 Jan 2006"}
+
+// ParseExpenseDate parses an expense date in any accepted layout and
+// returns it as YYYY-MM-DD.
+func ParseExpenseDate(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range expenseDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.Format("2006-01-02"), nil
+		}
+	}
+	return "", fmt.Errorf("%q is not a valid date", value)
+}
+
+// ExpenseParams is a site expense to be recorded. Amount is before GST.
+type ExpenseParams struct {
+	ProjectID      string
+	ExpenseDate    string
+	CostCategoryID string
+	Description    string
+	BillNumber     string
+	Amount         float64
+	GSTAmount      float64
+	PaidBy         string
+}
+
+// ValidateExpense checks an expense before it is saved. Errors are keyed by
+// form field name.
+func ValidateExpense(app core.App, params ExpenseParams) map[string]string {
+	errs := make(map[string]string)
+
+	if params.ExpenseDate == "" {
+		errs["expense_date"] = "Expense date is required"
+	} else if date, err := ParseExpenseDate(params.ExpenseDate); err != nil {
+		errs["expense_date"] = "Expense date must be a valid date"
+	} else if date > time.Now().Format("2006-01-02") {
+		errs["expense_date"] = "Expense date cannot be in the future"
+	}
+	if params.CostCategoryID == "" {
+		errs["cost_category"] = "Choose a cost category"
+	} else if cat, err := app.FindRecordById("cost_categories", params.CostCategoryID); err != nil || !cat.GetBool("active") {
+		errs["cost_category"] = "Choose an active cost category"
+	}
+	if strings.TrimSpace(params.Description) == "" {
+		errs["description"] = "Description is required"
+	}
+	if params.Amount <= 0 {
+		errs["amount"] = "Amount must be greater than zero"
+	}
+	if params.GSTAmount < 0 {
+		errs["gst_amount"] = "GST cannot be negative"
+	} else if params.GSTAmount > params.Amount {
+		errs["gst_amount"] = "GST cannot be more than the amount"
+	}
+	if strings.TrimSpace(params.PaidBy) == "" {
+		errs["paid_by"] = "Paid by is required"
+	}
+	return errs
+}
+
+// newExpenseRecord builds an unsaved expense, pending approval.
+func newExpenseRecord(col *core.Collection, params ExpenseParams) *core.Record {
+	date, _ := ParseExpenseDate(params.ExpenseDate)
+	record := core.NewRecord(col)
+	record.Set("project", params.ProjectID)
+	record.Set("expense_date", date)
+	record.Set("cost_category", params.CostCategoryID)
+	record.Set("description", strings.TrimSpace(params.Description))
+	record.Set("bill_number", strings.TrimSpace(params.BillNumber))
+	record.Set("amount", params.Amount)
+	record.Set("gst_amount", params.GSTAmount)
+	record.Set("paid_by", strings.TrimSpace(params.PaidBy))
+	record.Set("status", ExpenseStatusPending)
+	return record
+}
+
+// CreateExpense records a site expense with its receipts, pending approval.
+func CreateExpense(ctx context.Context, app *pocketbase.PocketBase, params ExpenseParams, receipts []*filesystem.File) (*core.Record, error) {
+	if errs := ValidateExpense(app, params); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid expense: %s", strings.Join(slices.Sorted(maps.Values(errs)), "; "))
+	}
+	col, err := app.FindCollectionByNameOrId("expenses")
+	if err != nil {
+		return nil, fmt.Errorf("expenses collection not found: %w", err)
+	}
+	record := newExpenseRecord(col, params)
+	if len(receipts) > 0 {
+		record.Set("receipts", receipts)
+	}
+	if err := app.SaveWithContext(ctx, record); err != nil {
+		return nil, fmt.Errorf("failed to save expense: %w", err)
+	}
+	return record, nil
+}
+
+// AddExpenseReceipts attaches more receipts to an expense.
+func AddExpenseReceipts(ctx context.Context, app *pocketbase.PocketBase, expense *core.Record, receipts []*filesystem.File) error {
+	if len(receipts) == 0 {
+		return fmt.Errorf("choose at least one receipt")
+	}
+	expense.Set("receipts+", receipts)
+	if err := app.SaveWithContext(ctx, expense); err != nil {
+		return fmt.Errorf("failed to save receipts: %w", err)
+	}
+	return nil
+}
+
+// ReviewExpense approves or rejects a pending expense. A rejection needs a
+// reason; the reviewer is taken from ctx (see WithAuditActor).
+func ReviewExpense(ctx context.Context, app *pocketbase.PocketBase, expenseID string, approve bool, reason string) error {
+	expense, err := app.FindRecordById("expenses", expenseID)
+	if err != nil {
+		return fmt.Errorf("expense not found: %w", err)
+	}
+	if expense.GetString("status") != ExpenseStatusPending {
+		return fmt.Errorf("expense is already %s", strings.ToLower(ExpenseStatusLabel(expense.GetString("status"))))
+	}
+	reason = strings.TrimSpace(reason)
+
+	status := ExpenseStatusApproved
+	if !approve {
+		if reason == "" {
+			return fmt.Errorf("a reason is required to reject an expense")
+		}
+		status = ExpenseStatusRejected
+	}
+	actor := auditActorFromContext(ctx)
+	expense.Set("status", status)
+	expense.Set("rejection_reason", reason)
+	expense.Set("reviewed_at", time.Now().UTC().Format(time.RFC3339))
+	expense.Set("reviewed_by", actor.ID)
+	expense.Set("reviewed_by_name", actor.Name)
+	if err := app.SaveWithContext(ctx, expense); err != nil {
+		return fmt.Errorf("failed to save expense: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,253 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime/multipart"
+	"strconv"
+	"strings"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/xuri/excelize/v2"
+)
+
+// ExpenseTemplateFields returns the ordered list of fields for the expense
+// import template.
+func ExpenseTemplateFields() []TemplateField {
+	return []TemplateField{
+		{Key: "expense_date", Label: "Date", Description: "Date the expense was paid", FormatRule: "YYYY-MM-DD or DD-MM-YYYY", ExampleValue: "2025-06-10", AlwaysRequired: true},
+		{Key: "cost_category", Label: "Cost Category", Description: "Name or code of an active cost category (select from dropdown)", ExampleValue: "Site expenses", AlwaysRequired: true},
+		{Key: "description", Label: "Description", Description: "What the money was spent on", ExampleValue: "Crane hire for pole erection", AlwaysRequired: true},
+		{Key: "bill_number", Label: "Bill Number", Description: "Number on the bill or receipt", ExampleValue: "CR-2291"},
+		{Key: "amount", Label: "Amount", Description: "Amount before GST", FormatRule: "Number greater than zero", ExampleValue: "12500", AlwaysRequired: true},
+		{Key: "gst_amount", Label: "GST Amount", Description: "GST charged on the bill", FormatRule: "Number, blank if none", ExampleValue: "2250"},
+		{Key: "paid_by", Label: "Paid By", Description: "Who paid, e.g. a site engineer or petty cash", ExampleValue: "Ravi (site engineer)", AlwaysRequired: true},
+	}
+}
+
+// expenseCategoryLookup maps the lower-cased name and code of every active
+// cost category to its ID.
+func expenseCategoryLookup(app core.App) (map[string]string, error) {
+	categories, err := app.FindRecordsByFilter("cost_categories", "active = true", "sort_order,created", 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch cost categories: %w", err)
+	}
+	lookup := make(map[string]string, 2*len(categories))
+	for _, c := range categories {
+		lookup[strings.ToLower(c.GetString("code"))] = c.Id
+		lookup[strings.ToLower(c.GetString("name"))] = c.Id
+	}
+	return lookup, nil
+}
+
+// parseImportAmount parses an amount from a sheet, allowing thousands
+// separators and a rupee sign. Blank is zero.
+func parseImportAmount(value string) (float64, error) {
+	value = strings.NewReplacer(",", "", "₹", "", " ", "").Replace(value)
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(value, 64)
+}
+
+// expenseParamsFromRow converts an imported row to expense params and
+// validates it. Blank fields are left to the required-field check.
+func expenseParamsFromRow(app core.App, projectID string, categories map[string]string, rowNum int, row map[string]string) (ExpenseParams, []ValidationError) {
+	params := ExpenseParams{
+		ProjectID:   projectID,
+		ExpenseDate: row["expense_date"],
+		Description: row["description"],
+		BillNumber:  row["bill_number"],
+		PaidBy:      row["paid_by"],
+	}
+	labels := make(map[string]string)
+	for _, f := range ExpenseTemplateFields() {
+		labels[f.Key] = f.Label
+	}
+
+	var errs []ValidationError
+	if name := row["cost_category"]; name != "" {
+		id, ok := categories[strings.ToLower(name)]
+		if !ok {
+			errs = append(errs, ValidationError{Row: rowNum, Field: labels["cost_category"], Message: fmt.Sprintf("No active cost category named %q", name)})
+		}
+		params.CostCategoryID = id
+	}
+	unparsed := make(map[string]bool)
+	for _, key := range []string{"amount", "gst_amount"} {
+		v, err := parseImportAmount(row[key])
+		if err != nil {
+			errs = append(errs, ValidationError{Row: rowNum, Field: labels[key], Message: fmt.Sprintf("%s must be a number", labels[key])})
+			unparsed[key] = true
+		}
+		if key == "amount" {
+			params.Amount = v
+		} else {
+			params.GSTAmount = v
+		}
+	}
+
+	for key, msg := range ValidateExpense(app, params) {
+		if row[key] == "" || unparsed[key] || key == "cost_category" || (key == "gst_amount" && unparsed["amount"]) {
+			continue
+		}
+		errs = append(errs, ValidationError{Row: rowNum, Field: labels[key], Message: msg})
+	}
+	return params, errs
+}
+
+// ValidateExpenseFile parses and validates an uploaded expense file.
+func ValidateExpenseFile(app *pocketbase.PocketBase, file multipart.File, fileName, projectID string) (*ValidationResult, error) {
+	fields := ExpenseTemplateFields()
+	headers, dataRows, err := parseImportFile(file, fileName)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := expenseCategoryLookup(app)
+	if err != nil {
+		return nil, err
+	}
+
+	isRequired := make(map[string]bool)
+	for _, f := range fields {
+		if f.AlwaysRequired {
+			isRequired[f.Key] = true
+		}
+	}
+	return validateImportRows(headers, dataRows, fields, isRequired, fileName, func(rowNum int, rowData map[string]string) []ValidationError {
+		_, errs := expenseParamsFromRow(app, projectID, categories, rowNum, rowData)
+		return errs
+	}), nil
+}
+
+// CommitExpenseImport re-validates parsed expense rows and saves them, all
+// pending approval. The rows are saved in one transaction, so either every
+// expense is imported or none is.
+func CommitExpenseImport(ctx context.Context, app *pocketbase.PocketBase, projectID string, parsedRows []map[string]string) (*ImportResult, error) {
+	categories, err := expenseCategoryLookup(app)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{TotalRows: len(parsedRows)}
+	params := make([]ExpenseParams, 0, len(parsedRows))
+	var errs []ValidationError
+	for rowIdx, row := range parsedRows {
+		rowNum := rowIdx + 2
+		for _, f := range ExpenseTemplateFields() {
+			if f.AlwaysRequired && row[f.Key] == "" {
+				errs = append(errs, ValidationError{Row: rowNum, Field: f.Label, Message: fmt.Sprintf("%s is required", f.Label)})
+			}
+		}
+		p, rowErrs := expenseParamsFromRow(app, projectID, categories, rowNum, row)
+		errs = append(errs, rowErrs...)
+		params = append(params, p)
+	}
+	if len(errs) > 0 {
+		errorRowSet := make(map[int]bool)
+		for _, e := range errs {
+			errorRowSet[e.Row] = true
+		}
+		result.Failed = len(errorRowSet)
+		result.Errors = toImportRowErrors(errs)
+		result.RolledBack = true
+		return result, nil
+	}
+
+	col, err := app.FindCollectionByNameOrId("expenses")
+	if err != nil {
+		return nil, fmt.Errorf("expenses collection not found: %w", err)
+	}
+	var failed *ImportRowError
+	err = app.RunInTransaction(func(txApp core.App) error {
+		for i, p := range params {
+			if err := txApp.SaveWithContext(ctx, newExpenseRecord(col, p)); err != nil {
+				failed = &ImportRowError{Row: i + 2, Message: err.Error()}
+				return err
+			}
+		}
+		return nil
+	})
+	if failed != nil {
+		result.Failed = len(parsedRows)
+		result.Errors = []ImportRowError{*failed}
+		result.RolledBack = true
+		return result, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to import expenses: %w", err)
+	}
+	result.Imported = len(params)
+	return result, nil
+}
+
+// GenerateExpenseTemplate creates a downloadable .xlsx template for the
+// expense import, with a dropdown of the active cost categories.
+func GenerateExpenseTemplate(app core.App) ([]byte, error) {
+	fields := ExpenseTemplateFields()
+	categories, err := app.FindRecordsByFilter("cost_categories", "active = true", "sort_order,created", 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch cost categories: %w", err)
+	}
+
+	f := excelize.NewFile()
+	defer f.Close()
+
+	sheetName := "Expenses"
+	defaultSheet := f.GetSheetName(0)
+	f.SetSheetName(defaultSheet, sheetName)
+
+	requiredHeaderStyle, _ := f.NewStyle(&excelize.Style{
+		Font:      &excelize.Font{Bold: true, Color: "#FFFFFF", Size: 11},
+		Fill:      excelize.Fill{Type: "pattern", Color: []string{"#1D4ED8"}, Pattern: 1},
+		Alignment: &excelize.Alignment{Horizontal: "center", Vertical: "center", WrapText: true},
+		Border:    thinBorders(),
+	})
+	optionalHeaderStyle, _ := f.NewStyle(&excelize.Style{
+		Font:      &excelize.Font{Bold: true, Color: "#FFFFFF", Size: 11},
+		Fill:      excelize.Fill{Type: "pattern", Color: []string{"#6B7280"}, Pattern: 1},
+		Alignment: &excelize.Alignment{Horizontal: "center", Vertical: "center", WrapText: true},
+		Border:    thinBorders(),
+	})
+
+	columns := columnLetters(len(fields))
+	for i, field := range fields {
+		headerCell := fmt.Sprintf("%s1", columns[i])
+		headerText, style := field.Label, optionalHeaderStyle
+		if field.AlwaysRequired {
+			headerText, style = field.Label+" *", requiredHeaderStyle
+		}
+		f.SetCellValue(sheetName, headerCell, headerText)
+		f.SetCellStyle(sheetName, headerCell, headerCell, style)
+		f.SetColWidth(sheetName, columns[i], columns[i], max(float64(len(field.Label))*1.3, 18))
+
+		if field.Key == "cost_category" && len(categories) > 0 {
+			names := make([]string, 0, len(categories))
+			for _, c := range categories {
+				names = append(names, c.GetString("name"))
+			}
+			dv := excelize.NewDataValidation(true)
+			dv.Sqref = fmt.Sprintf("%s2:%s1048576", columns[i], columns[i])
+			// Excel limits an inline list to 255 characters
+			if err := dv.SetDropList(names); err == nil {
+				f.AddDataValidation(sheetName, dv)
+			}
+		}
+	}
+
+	f.SetPanes(sheetName, &excelize.Panes{
+		Freeze:      true,
+		YSplit:      1,
+		TopLeftCell: "A2",
+		ActivePane:  "bottomLeft",
+	})
+	addInstructionsSheet(f, fields, nil, "Site Expense Import - Instructions")
+
+	var buf bytes.Buffer
+	if err := f.Write(&buf); err != nil {
+		return nil, fmt.Errorf("write excel template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase"
+
+	"projectcreation/collections"
+	"projectcreation/testhelpers"
+)
+
+// siteCategoryID returns the ID of the default "site" cost category.
+func siteCategoryID(t *testing.T, app *pocketbase.PocketBase) string {
+	t.Helper()
+	categories, err := collections.GetCostCategories(app)
+	if err != nil {
+		t.Fatalf("GetCostCategories failed: %v", err)
+	}
+	for _, c := range categories {
+		if c.GetString("code") == "site" {
+			return c.Id
+		}
+	}
+	t.Fatal("site cost category not seeded")
+	return ""
+}
+
+func TestValidateExpense(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Expense Project")
+	valid := ExpenseParams{
+		ProjectID:      project.Id,
+		ExpenseDate:    "2025-06-10",
+		CostCategoryID: siteCategoryID(t, app),
+		Description:    "Crane hire",
+		Amount:         12500,
+		GSTAmount:      2250,
+		PaidBy:         "Ravi",
+	}
+	if errs := ValidateExpense(app, valid); len(errs) > 0 {
+		t.Fatalf("expected no errors, got %v", errs)
+	}
+
+	tests := []struct {
+		name  string
+		edit  func(p *ExpenseParams)
+		field string
+	}{
+		{"future date", func(p *ExpenseParams) { p.ExpenseDate = time.Now().AddDate(0, 0, 2).Format("2006-01-02") }, "expense_date"},
+		{"bad date", func(p *ExpenseParams) { p.ExpenseDate = "31-31-2025" }, "expense_date"},
+		{"unknown category", func(p *ExpenseParams) { p.CostCategoryID = "missing" }, "cost_category"},
+		{"zero amount", func(p *ExpenseParams) { p.Amount = 0 }, "amount"},
+		{"GST above amount", func(p *ExpenseParams) { p.GSTAmount = 13000 }, "gst_amount"},
+		{"no payer", func(p *ExpenseParams) { p.PaidBy = " " }, "paid_by"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := valid
+			tt.edit(&p)
+			if errs := ValidateExpense(app, p); errs[tt.field] == "" {
+				t.Errorf("expected an error on %s, got %v", tt.field, errs)
+			}
+		})
+	}
+
+	if date, err := ParseExpenseDate("10-06-2025"); err != nil || date != "2025-06-10" {
+		t.Errorf("expected day-first date to parse as 2025-06-10, got %q (%v)", date, err)
+	}
+}
+
+func TestReviewExpense_ApprovedCountsTowardsBudget(t *testing.T) {
+	app, project, _, _, _ := setupBudgetTest(t)
+	ctx := WithAuditActor(context.Background(), "", "Priya")
+
+	approved, err := CreateExpense(ctx, app, ExpenseParams{
+		ProjectID: project.Id, ExpenseDate: "2025-06-10", CostCategoryID: siteCategoryID(t, app),
+		Description: "Crane hire", Amount: 2000, GSTAmount: 360, PaidBy: "Ravi",
+	}, nil)
+	if err != nil {
+		t.Fatalf("CreateExpense failed: %v", err)
+	}
+	rejected, err := CreateExpense(ctx, app, ExpenseParams{
+		ProjectID: project.Id, ExpenseDate: "2025-06-11", CostCategoryID: siteCategoryID(t, app),
+		Description: "Tea and snacks", Amount: 500, PaidBy: "Ravi",
+	}, nil)
+	if err != nil {
+		t.Fatalf("CreateExpense failed: %v", err)
+	}
+
+	if err := ReviewExpense(ctx, app, rejected.Id, false, ""); err == nil {
+		t.Error("expected a rejection without a reason to fail")
+	}
+	if err := ReviewExpense(ctx, app, rejected.Id, false, "Not a project cost"); err != nil {
+		t.Fatalf("ReviewExpense reject failed: %v", err)
+	}
+	if err := ReviewExpense(ctx, app, approved.Id, true, ""); err != nil {
+		t.Fatalf("ReviewExpense approve failed: %v", err)
+	}
+	if err := ReviewExpense(ctx, app, approved.Id, false, "Changed my mind"); err == nil {
+		t.Error("expected reviewing an approved expense to fail")
+	}
+
+	approved, _ = app.FindRecordById("expenses", approved.Id)
+	if approved.GetString("status") != ExpenseStatusApproved || approved.GetString("reviewed_by_name") != "Priya" {
+		t.Errorf("expected approved by Priya, got %s by %q", approved.GetString("status"), approved.GetString("reviewed_by_name"))
+	}
+
+	v, err := BuildBudgetVariance(app, project.Id)
+	if err != nil {
+		t.Fatalf("BuildBudgetVariance failed: %v", err)
+	}
+	for _, l := range v.Lines {
+		if l.Code == "site" && l.Actual != 2000 {
+			t.Errorf("expected 2,000 of site expenses spent (rejected one excluded), got %v", l.Actual)
+		}
+	}
+}
+
+func TestExpenseImport_ValidateAndCommit(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Import Project")
+	siteCategoryID(t, app)
+
+	path := filepath.Join(t.TempDir(), "expenses.csv")
+	csv := "Date,Cost Category,Description,Bill Number,Amount,GST Amount,Paid By\n" +
+		"10-06-2025,Site expenses,Crane hire,CR-1,\"12,500\",2250,Ravi\n" +
+		"11-06-2025,transport,Tempo to site,,800,,Petty cash\n" +
+		"12-06-2025,Catering,Lunch,,abc,,Ravi\n"
+	if err := os.WriteFile(path, []byte(csv), 0o644); err != nil {
+		t.Fatalf("failed to write CSV: %v", err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open CSV: %v", err)
+	}
+	defer file.Close()
+
+	result, err := ValidateExpenseFile(app, file, "expenses.csv", project.Id)
+	if err != nil {
+		t.Fatalf("ValidateExpenseFile failed: %v", err)
+	}
+	if result.ValidRows != 2 || result.ErrorRows != 1 {
+		t.Fatalf("expected 2 valid rows and 1 with errors, got %d and %d: %+v", result.ValidRows, result.ErrorRows, result.Errors)
+	}
+	fields := make(map[string]bool)
+	for _, e := range result.Errors {
+		fields[e.Field] = true
+	}
+	if !fields["Cost Category"] || !fields["Amount"] {
+		t.Errorf("expected category and amount errors on row 4, got %+v", result.Errors)
+	}
+
+	imported, err := CommitExpenseImport(context.Background(), app, project.Id, result.ParsedRows[:2])
+	if err != nil {
+		t.Fatalf("CommitExpenseImport failed: %v", err)
+	}
+	if imported.Imported != 2 || imported.Failed != 0 {
+		t.Fatalf("expected 2 imported, got %+v", imported)
+	}
+	records, _ := app.FindRecordsByFilter("expenses", "project = {:p}", "expense_date", 0, 0, map[string]any{"p": project.Id})
+	if len(records) != 2 || records[0].GetFloat("amount") != 12500 || records[0].GetString("expense_date") != "2025-06-10" || records[0].GetString("status") != ExpenseStatusPending {
+		t.Errorf("unexpected imported expenses %v", records)
+	}
+
+	failed, err := CommitExpenseImport(context.Background(), app, project.Id, result.ParsedRows)
+	if err != nil {
+		t.Fatalf("CommitExpenseImport failed: %v", err)
+	}
+	if !failed.RolledBack || failed.Imported != 0 {
+		t.Errorf("expected the import with a bad row to be rolled back, got %+v", failed)
+	}
+}
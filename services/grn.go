@@ -0,0 +1,264 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// GRNLineParams is what arrived against one PO line item.
+type GRNLineParams struct {
+	POLineItemID    string
+	QtyReceived     float64
+	QtyRejected     float64
+	RejectionReason string
+}
+
+// GRNParams holds the inputs for a goods receipt note.
+type GRNParams struct {
+	ProjectID         string
+	PurchaseOrderID   string
+	ReceivedDate      string
+	ReceivedBy        string
+	WarehouseLocation string
+	TransporterName   string
+	LRNumber          string
+	VehicleNumber     string
+	Remarks           string
+	Lines             []GRNLineParams
+}
+
+// GRNResult holds the output of CreateGRN.
+type GRNResult struct {
+	GRNID       string
+	GRNNumber   string
+	POCompleted bool // the GRN received everything still pending on the PO
+}
+
+// POReceiptLine is the receipt position of a single PO line item across
+// all GRNs raised against its purchase order.
+type POReceiptLine struct {
+	POLineItemID string
+	Description  string
+	UOM          string
+	Rate         float64
+	GSTPercent   float64
+	Ordered      float64
+	Received     float64
+	Rejected     float64
+	Accepted     float64
+}
+
+// Pending returns the quantity still to be accepted. Rejected goods are
+// expected to be replaced, so they remain pending.
+func (l POReceiptLine) Pending() float64 {
+	return l.Ordered - l.Accepted
+}
+
+// GRNReceivableStatuses are the PO statuses goods can be received against.
+var GRNReceivableStatuses = []string{"sent", "acknowledged"}
+
+// POReceiptStatus returns the receipt position of every line item on a
+// purchase order, in sort order.
+func POReceiptStatus(app core.App, poID string) ([]POReceiptLine, error) {
+	poLines, err := app.FindRecordsByFilter("po_line_items", "purchase_order = {:poId}", "sort_order", 0, 0, map[string]any{"poId": poID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch PO line items: %w", err)
+	}
+
+	lines := make([]POReceiptLine, len(poLines))
+	index := make(map[string]int, len(poLines))
+	for i, li := range poLines {
+		lines[i] = POReceiptLine{
+			POLineItemID: li.Id,
+			Description:  li.GetString("description"),
+			UOM:          li.GetString("uom"),
+			Rate:         li.GetFloat("rate"),
+			GSTPercent:   li.GetFloat("gst_percent"),
+			Ordered:      li.GetFloat("qty"),
+		}
+		index[li.Id] = i
+	}
+
+	grnLines, err := app.FindRecordsByFilter("grn_line_items", "grn.purchase_order = {:poId}", "", 0, 0, map[string]any{"poId": poID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch GRN line items: %w", err)
+	}
+	for _, gl := range grnLines {
+		i, ok := index[gl.GetString("po_line_item")]
+		if !ok {
+			continue
+		}
+		lines[i].Received += gl.GetFloat("qty_received")
+		lines[i].Rejected += gl.GetFloat("qty_rejected")
+		lines[i].Accepted += gl.GetFloat("qty_accepted")
+	}
+	return lines, nil
+}
+
+// POFullyReceived reports whether every line of a purchase order has been
+// accepted in full. A PO without line items is never fully received.
+func POFullyReceived(lines []POReceiptLine) bool {
+	if len(lines) == 0 {
+		return false
+	}
+	for _, l := range lines {
+		if l.Pending() > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidateGRNLines checks the quantities entered on a GRN against the
+// receipt position of its PO. It returns one message per problem, keyed by
+// PO line item ID ("" for problems with the GRN as a whole).
+func ValidateGRNLines(receipt []POReceiptLine, lines []GRNLineParams) map[string]string {
+	errs := make(map[string]string)
+	byID := make(map[string]POReceiptLine, len(receipt))
+	for _, r := range receipt {
+		byID[r.POLineItemID] = r
+	}
+
+	anyReceived := false
+	for _, l := range lines {
+		r, ok := byID[l.POLineItemID]
+		if !ok {
+			errs[l.POLineItemID] = "Line item does not belong to this purchase order"
+			continue
+		}
+		switch {
+		case l.QtyReceived < 0 || l.QtyRejected < 0:
+			errs[l.POLineItemID] = fmt.Sprintf("%s: quantities cannot be negative", r.Description)
+		case l.QtyRejected > l.QtyReceived:
+			errs[l.POLineItemID] = fmt.Sprintf("%s: rejected quantity cannot exceed the received quantity", r.Description)
+		case l.QtyRejected > 0 && strings.TrimSpace(l.RejectionReason) == "":
+			errs[l.POLineItemID] = fmt.Sprintf("%s: a rejection reason is required", r.Description)
+		case l.QtyReceived-l.QtyRejected > r.Pending():
+			errs[l.POLineItemID] = fmt.Sprintf("%s: accepted quantity %s exceeds the %s still pending",
+				r.Description, formatQty(l.QtyReceived-l.QtyRejected), formatQty(r.Pending()))
+		}
+		if l.QtyReceived > 0 {
+			anyReceived = true
+		}
+	}
+	if !anyReceived {
+		errs[""] = "Enter the received quantity for at least one item"
+	}
+	return errs
+}
+
+// CreateGRN records a goods receipt note against a purchase order. Lines
+// with nothing received are skipped. When the GRN leaves nothing pending on
+// the PO, the PO is marked completed.
+func CreateGRN(ctx context.Context, app *pocketbase.PocketBase, params GRNParams) (*GRNResult, error) {
+	po, err := app.FindRecordById("purchase_orders", params.PurchaseOrderID)
+	if err != nil {
+		return nil, fmt.Errorf("purchase order not found: %w", err)
+	}
+	if po.GetString("project") != params.ProjectID {
+		return nil, fmt.Errorf("purchase order does not belong to this project")
+	}
+	if status := po.GetString("status"); !slices.Contains(GRNReceivableStatuses, status) {
+		return nil, fmt.Errorf("goods cannot be received against a %s purchase order", status)
+	}
+
+	receipt, err := POReceiptStatus(app, po.Id)
+	if err != nil {
+		return nil, err
+	}
+	if errs := ValidateGRNLines(receipt, params.Lines); len(errs) > 0 {
+		msgs := make([]string, 0, len(errs))
+		for _, m := range errs {
+			msgs = append(msgs, m)
+		}
+		return nil, fmt.Errorf("invalid GRN quantities: %s", strings.Join(msgs, "; "))
+	}
+
+	docDate, err := time.Parse("2006-01-02", params.ReceivedDate)
+	if err != nil {
+		docDate = time.Now()
+	}
+	grnNumber, err := NextDocNumber(app, params.ProjectID, "grn", docDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate GRN number: %w", err)
+	}
+
+	grnCol, err := app.FindCollectionByNameOrId("grn")
+	if err != nil {
+		return nil, fmt.Errorf("grn collection not found: %w", err)
+	}
+	lineCol, err := app.FindCollectionByNameOrId("grn_line_items")
+	if err != nil {
+		return nil, fmt.Errorf("grn_line_items collection not found: %w", err)
+	}
+
+	byID := make(map[string]*POReceiptLine, len(receipt))
+	for i := range receipt {
+		byID[receipt[i].POLineItemID] = &receipt[i]
+	}
+
+	result := &GRNResult{GRNNumber: grnNumber}
+	err = app.RunInTransaction(func(txApp core.App) error {
+		grn := core.NewRecord(grnCol)
+		grn.Set("project", params.ProjectID)
+		grn.Set("purchase_order", po.Id)
+		grn.Set("grn_number", grnNumber)
+		grn.Set("received_date", params.ReceivedDate)
+		grn.Set("received_by", params.ReceivedBy)
+		grn.Set("warehouse_location", params.WarehouseLocation)
+		grn.Set("transporter_name", params.TransporterName)
+		grn.Set("lr_number", params.LRNumber)
+		grn.Set("vehicle_number", params.VehicleNumber)
+		grn.Set("remarks", params.Remarks)
+		if err := txApp.SaveWithContext(ctx, grn); err != nil {
+			return fmt.Errorf("failed to create GRN: %w", err)
+		}
+		result.GRNID = grn.Id
+
+		for _, l := range params.Lines {
+			if l.QtyReceived <= 0 {
+				continue
+			}
+			r := byID[l.POLineItemID]
+			accepted := l.QtyReceived - l.QtyRejected
+
+			rec := core.NewRecord(lineCol)
+			rec.Set("grn", grn.Id)
+			rec.Set("po_line_item", l.POLineItemID)
+			rec.Set("description", r.Description)
+			rec.Set("uom", r.UOM)
+			rec.Set("rate", r.Rate)
+			rec.Set("qty_ordered", r.Ordered)
+			rec.Set("qty_received", l.QtyReceived)
+			rec.Set("qty_rejected", l.QtyRejected)
+			rec.Set("qty_accepted", accepted)
+			rec.Set("rejection_reason", strings.TrimSpace(l.RejectionReason))
+			if err := txApp.SaveWithContext(ctx, rec); err != nil {
+				return fmt.Errorf("failed to create GRN line item: %w", err)
+			}
+
+			r.Received += l.QtyReceived
+			r.Rejected += l.QtyRejected
+			r.Accepted += accepted
+		}
+
+		if POFullyReceived(receipt) {
+			po.Set("status", "completed")
+			if err := txApp.SaveWithContext(ctx, po); err != nil {
+				return fmt.Errorf("failed to complete purchase order: %w", err)
+			}
+			result.POCompleted = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
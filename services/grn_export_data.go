@@ -0,0 +1,117 @@
+package services
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/pocketbase/pocketbase"
+
+	"projectcreation/collections"
+)
+
+// GRNExportData holds all data needed to generate a GRN PDF.
+type GRNExportData struct {
+	// Company branding (from app_settings)
+	CompanyName  string
+	LogoBytes    []byte
+	LogoFilename string
+
+	// GRN Header
+	GRNNumber         string
+	ReceivedDate      string
+	ReceivedBy        string
+	WarehouseLocation string
+
+	// Purchase order and vendor
+	PONumber    string
+	OrderDate   string
+	VendorName  string
+	VendorGSTIN string
+
+	// Transport
+	TransporterName string
+	LRNumber        string
+	VehicleNumber   string
+
+	// Line Items
+	LineItems []GRNExportLineItem
+
+	// Totals
+	TotalAccepted float64 // accepted qty × rate, before tax
+	Remarks       string
+}
+
+// GRNExportLineItem holds a single GRN line item for PDF export.
+type GRNExportLineItem struct {
+	SINo            int
+	Description     string
+	UoM             string
+	Rate            float64
+	QtyOrdered      float64
+	QtyReceived     float64
+	QtyRejected     float64
+	QtyAccepted     float64
+	Amount          float64 // accepted qty × rate
+	RejectionReason string
+}
+
+// BuildGRNExportData assembles all data needed for PDF generation from PocketBase records.
+func BuildGRNExportData(app *pocketbase.PocketBase, grnID string) (*GRNExportData, error) {
+	grn, err := app.FindRecordById("grn", grnID)
+	if err != nil {
+		return nil, fmt.Errorf("GRN not found: %w", err)
+	}
+
+	data := &GRNExportData{
+		GRNNumber:         grn.GetString("grn_number"),
+		ReceivedDate:      grn.GetString("received_date"),
+		ReceivedBy:        grn.GetString("received_by"),
+		WarehouseLocation: grn.GetString("warehouse_location"),
+		TransporterName:   grn.GetString("transporter_name"),
+		LRNumber:          grn.GetString("lr_number"),
+		VehicleNumber:     grn.GetString("vehicle_number"),
+		Remarks:           grn.GetString("remarks"),
+	}
+
+	if po, err := app.FindRecordById("purchase_orders", grn.GetString("purchase_order")); err == nil {
+		data.PONumber = po.GetString("po_number")
+		data.OrderDate = po.GetString("order_date")
+		if v, err := app.FindRecordById("vendors", po.GetString("vendor")); err == nil {
+			data.VendorName = v.GetString("name")
+			data.VendorGSTIN = v.GetString("gstin")
+		} else {
+			log.Printf("grn_export: could not find vendor for PO %s: %v", po.Id, err)
+		}
+	} else {
+		log.Printf("grn_export: could not find purchase order for GRN %s: %v", grnID, err)
+	}
+
+	lineRecords, err := app.FindRecordsByFilter("grn_line_items", "grn = {:grnId}", "created", 0, 0, map[string]any{"grnId": grnID})
+	if err != nil {
+		log.Printf("grn_export: could not fetch line items for GRN %s: %v", grnID, err)
+		lineRecords = nil
+	}
+	for i, li := range lineRecords {
+		rate := li.GetFloat("rate")
+		accepted := li.GetFloat("qty_accepted")
+		amount := rate * accepted
+		data.LineItems = append(data.LineItems, GRNExportLineItem{
+			SINo:            i + 1,
+			Description:     li.GetString("description"),
+			UoM:             li.GetString("uom"),
+			Rate:            rate,
+			QtyOrdered:      li.GetFloat("qty_ordered"),
+			QtyReceived:     li.GetFloat("qty_received"),
+			QtyRejected:     li.GetFloat("qty_rejected"),
+			QtyAccepted:     accepted,
+			Amount:          amount,
+			RejectionReason: li.GetString("rejection_reason"),
+		})
+		data.TotalAccepted += amount
+	}
+
+	data.CompanyName = collections.GetCompanyName(app)
+	data.LogoBytes, data.LogoFilename, _ = collections.GetLogoBytes(app)
+
+	return data, nil
+}
@@ -0,0 +1,368 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/johnfercher/maroto/v2"
+	"github.com/johnfercher/maroto/v2/pkg/components/col"
+	"github.com/johnfercher/maroto/v2/pkg/components/image"
+	"github.com/johnfercher/maroto/v2/pkg/components/row"
+	"github.com/johnfercher/maroto/v2/pkg/components/text"
+	"github.com/johnfercher/maroto/v2/pkg/config"
+	"github.com/johnfercher/maroto/v2/pkg/consts/align"
+	"github.com/johnfercher/maroto/v2/pkg/consts/fontstyle"
+	"github.com/johnfercher/maroto/v2/pkg/consts/orientation"
+	"github.com/johnfercher/maroto/v2/pkg/consts/pagesize"
+	"github.com/johnfercher/maroto/v2/pkg/core"
+	"github.com/johnfercher/maroto/v2/pkg/props"
+)
+
+// GenerateGRNPDF creates a PDF document for a Goods Receipt Note using maroto/v2.
+// It returns the raw PDF bytes or an error.
+func GenerateGRNPDF(data *GRNExportData) ([]byte, error) {
+	cfg := config.NewBuilder().
+		WithOrientation(orientation.Vertical).
+		WithPageSize(pagesize.A4).
+		WithLeftMargin(10).
+		WithTopMargin(10).
+		WithRightMargin(10).
+		WithPageNumber(props.PageNumber{
+			Pattern: "Page {current} of {total}",
+			Place:   props.RightBottom,
+			Size:    7,
+			Color:   &props.Color{Red: 120, Green: 120, Blue: 120},
+		}).
+		Build()
+
+	m := maroto.New(cfg)
+
+	addGRNHeader(m, data)
+	addGRNDetailsBlock(m, data)
+	addGRNLineItemsTable(m, data)
+	addGRNTotal(m, data)
+	addGRNRejections(m, data)
+	addGRNRemarks(m, data)
+	addGRNSignatures(m)
+
+	doc, err := m.Generate()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate GRN PDF: %w", err)
+	}
+
+	return doc.GetBytes(), nil
+}
+
+// addGRNHeader adds company logo/name, "GOODS RECEIPT NOTE" title, and GRN number.
+func addGRNHeader(m core.Maroto, data *GRNExportData) {
+	titleStyle := props.Text{
+		Size:  14,
+		Style: fontstyle.Bold,
+		Align: align.Right,
+		Color: &props.Color{Red: 33, Green: 37, Blue: 41},
+	}
+
+	if len(data.LogoBytes) > 0 {
+		titleStyle.Top = 2
+		m.AddRows(
+			row.New(14).Add(
+				col.New(3).Add(
+					image.NewFromBytes(data.LogoBytes, logoExtension(data.LogoFilename), props.Rect{
+						Percent: 80,
+						Center:  false,
+					}),
+				),
+				col.New(3).Add(
+					text.New(data.CompanyName, props.Text{
+						Size:  11,
+						Style: fontstyle.Bold,
+						Align: align.Left,
+						Top:   3,
+					}),
+				),
+				col.New(6).Add(text.New("GOODS RECEIPT NOTE", titleStyle)),
+			),
+		)
+	} else {
+		m.AddRows(
+			row.New(10).Add(
+				col.New(6).Add(
+					text.New(data.CompanyName, props.Text{
+						Size:  14,
+						Style: fontstyle.Bold,
+						Align: align.Left,
+					}),
+				),
+				col.New(6).Add(text.New("GOODS RECEIPT NOTE", titleStyle)),
+			),
+		)
+	}
+
+	// Row 2: GRN number (right-aligned)
+	m.AddRows(
+		row.New(8).Add(
+			col.New(6),
+			col.New(6).Add(
+				text.New(fmt.Sprintf("GRN #: %s", data.GRNNumber), props.Text{
+					Size:  10,
+					Style: fontstyle.Bold,
+					Align: align.Right,
+				}),
+			),
+		),
+	)
+
+	// Divider spacer
+	m.AddRows(row.New(3))
+}
+
+// addGRNDetailsBlock adds vendor and PO details on the left and receipt
+// details on the right.
+func addGRNDetailsBlock(m core.Maroto, data *GRNExportData) {
+	labelStyle := props.Text{
+		Size:  7,
+		Style: fontstyle.Bold,
+		Align: align.Left,
+		Color: &props.Color{Red: 100, Green: 100, Blue: 100},
+	}
+	valueStyle := props.Text{
+		Size:  8,
+		Align: align.Left,
+	}
+	rightLabelStyle := props.Text{
+		Size:  7,
+		Style: fontstyle.Bold,
+		Align: align.Right,
+		Color: &props.Color{Red: 100, Green: 100, Blue: 100},
+	}
+	rightValueStyle := props.Text{
+		Size:  8,
+		Align: align.Right,
+	}
+
+	m.AddRows(
+		row.New(6).Add(
+			col.New(6).Add(text.New("VENDOR", labelStyle)),
+			col.New(6).Add(text.New("RECEIPT DETAILS", rightLabelStyle)),
+		),
+	)
+
+	m.AddRows(
+		row.New(7).Add(
+			col.New(6).Add(text.New(data.VendorName, props.Text{
+				Size:  9,
+				Style: fontstyle.Bold,
+				Align: align.Left,
+			})),
+			col.New(3).Add(text.New("Received Date:", rightLabelStyle)),
+			col.New(3).Add(text.New(data.ReceivedDate, rightValueStyle)),
+		),
+	)
+
+	m.AddRows(
+		row.New(7).Add(
+			col.New(6).Add(text.New(fmtField("GSTIN", data.VendorGSTIN), valueStyle)),
+			col.New(3).Add(text.New("Received By:", rightLabelStyle)),
+			col.New(3).Add(text.New(data.ReceivedBy, rightValueStyle)),
+		),
+	)
+
+	m.AddRows(
+		row.New(7).Add(
+			col.New(6).Add(text.New(fmt.Sprintf("PO #: %s", joinNonEmpty([]string{data.PONumber, data.OrderDate}, " dated ")), valueStyle)),
+			col.New(3).Add(text.New("Location:", rightLabelStyle)),
+			col.New(3).Add(text.New(data.WarehouseLocation, rightValueStyle)),
+		),
+	)
+
+	transport := joinNonEmpty([]string{
+		data.TransporterName,
+		fmtField("LR No", data.LRNumber),
+		fmtField("Vehicle", data.VehicleNumber),
+	}, " | ")
+	if transport != "" {
+		m.AddRows(
+			row.New(7).Add(
+				col.New(12).Add(text.New(fmt.Sprintf("Transport: %s", transport), valueStyle)),
+			),
+		)
+	}
+
+	m.AddRows(row.New(3))
+}
+
+// addGRNLineItemsTable adds the received items table with header and body rows.
+func addGRNLineItemsTable(m core.Maroto, data *GRNExportData) {
+	headerBg := &props.Color{Red: 33, Green: 37, Blue: 41}
+	headerText := props.Text{
+		Size:  7,
+		Style: fontstyle.Bold,
+		Align: align.Center,
+		Color: &props.Color{Red: 255, Green: 255, Blue: 255},
+	}
+	headerTextLeft := headerText
+	headerTextLeft.Align = align.Left
+	headerCell := props.Cell{BackgroundColor: headerBg}
+
+	m.AddRows(
+		row.New(8).Add(
+			col.New(1).Add(text.New("SI No", headerText)).WithStyle(&headerCell),
+			col.New(3).Add(text.New("Description", headerTextLeft)).WithStyle(&headerCell),
+			col.New(1).Add(text.New("UoM", headerText)).WithStyle(&headerCell),
+			col.New(1).Add(text.New("Ordered", headerText)).WithStyle(&headerCell),
+			col.New(1).Add(text.New("Received", headerText)).WithStyle(&headerCell),
+			col.New(1).Add(text.New("Rejected", headerText)).WithStyle(&headerCell),
+			col.New(1).Add(text.New("Accepted", headerText)).WithStyle(&headerCell),
+			col.New(1).Add(text.New("Rate", headerText)).WithStyle(&headerCell),
+			col.New(2).Add(text.New("Amount", headerText)).WithStyle(&headerCell),
+		),
+	)
+
+	altBg := &props.Color{Red: 248, Green: 249, Blue: 250}
+
+	for i, item := range data.LineItems {
+		bodyText := props.Text{Size: 7, Align: align.Center}
+		bodyTextLeft := props.Text{Size: 7, Align: align.Left}
+		bodyTextRight := props.Text{Size: 7, Align: align.Right}
+
+		cols := []core.Col{
+			col.New(1).Add(text.New(fmt.Sprintf("%d", item.SINo), bodyText)),
+			col.New(3).Add(text.New(item.Description, bodyTextLeft)),
+			col.New(1).Add(text.New(item.UoM, bodyText)),
+			col.New(1).Add(text.New(formatQty(item.QtyOrdered), bodyTextRight)),
+			col.New(1).Add(text.New(formatQty(item.QtyReceived), bodyTextRight)),
+			col.New(1).Add(text.New(formatQty(item.QtyRejected), bodyTextRight)),
+			col.New(1).Add(text.New(formatQty(item.QtyAccepted), bodyTextRight)),
+			col.New(1).Add(text.New(FormatINR(item.Rate), bodyTextRight)),
+			col.New(2).Add(text.New(FormatINR(item.Amount), bodyTextRight)),
+		}
+		if i%2 == 1 {
+			for j := range cols {
+				cols[j] = cols[j].WithStyle(&props.Cell{BackgroundColor: altBg})
+			}
+		}
+
+		m.AddRows(row.New(7).Add(cols...))
+	}
+
+	m.AddRows(row.New(2))
+}
+
+// addGRNTotal adds the value of accepted goods.
+func addGRNTotal(m core.Maroto, data *GRNExportData) {
+	grandCell := &props.Cell{BackgroundColor: &props.Color{Red: 33, Green: 37, Blue: 41}}
+	grandStyle := props.Text{
+		Size:  9,
+		Style: fontstyle.Bold,
+		Align: align.Right,
+		Color: &props.Color{Red: 255, Green: 255, Blue: 255},
+	}
+
+	m.AddRows(
+		row.New(8).Add(
+			col.New(9).Add(text.New("Value of Accepted Goods (before tax)", grandStyle)).WithStyle(grandCell),
+			col.New(3).Add(text.New(FormatINR(data.TotalAccepted), grandStyle)).WithStyle(grandCell),
+		),
+	)
+
+	m.AddRows(row.New(3))
+}
+
+// addGRNRejections lists the reason for each rejected item.
+func addGRNRejections(m core.Maroto, data *GRNExportData) {
+	var rejected []GRNExportLineItem
+	for _, item := range data.LineItems {
+		if item.QtyRejected > 0 {
+			rejected = append(rejected, item)
+		}
+	}
+	if len(rejected) == 0 {
+		return
+	}
+
+	sectionLabel := props.Text{
+		Size:  7,
+		Style: fontstyle.Bold,
+		Align: align.Left,
+		Color: &props.Color{Red: 100, Green: 100, Blue: 100},
+	}
+	valueStyle := props.Text{
+		Size:  8,
+		Align: align.Left,
+	}
+
+	m.AddRows(
+		row.New(6).Add(
+			col.New(12).Add(text.New("REJECTIONS", sectionLabel)),
+		),
+	)
+	for _, item := range rejected {
+		m.AddRows(
+			row.New(7).Add(
+				col.New(12).Add(text.New(fmt.Sprintf("%s (%s %s): %s",
+					item.Description, formatQty(item.QtyRejected), item.UoM, item.RejectionReason), valueStyle)),
+			),
+		)
+	}
+
+	m.AddRows(row.New(3))
+}
+
+// addGRNRemarks adds the remarks section if non-empty.
+func addGRNRemarks(m core.Maroto, data *GRNExportData) {
+	if data.Remarks == "" {
+		return
+	}
+
+	m.AddRows(
+		row.New(6).Add(
+			col.New(12).Add(text.New("REMARKS", props.Text{
+				Size:  7,
+				Style: fontstyle.Bold,
+				Align: align.Left,
+				Color: &props.Color{Red: 100, Green: 100, Blue: 100},
+			})),
+		),
+	)
+	m.AddRows(
+		row.New(7).Add(
+			col.New(12).Add(text.New(data.Remarks, props.Text{
+				Size:  8,
+				Align: align.Left,
+			})),
+		),
+	)
+
+	m.AddRows(row.New(3))
+}
+
+// addGRNSignatures adds the signature section at the bottom.
+func addGRNSignatures(m core.Maroto) {
+	m.AddRows(row.New(10))
+
+	lineStyle := props.Text{
+		Size:  8,
+		Align: align.Center,
+		Color: &props.Color{Red: 100, Green: 100, Blue: 100},
+	}
+	labelStyle := props.Text{
+		Size:  7,
+		Style: fontstyle.Bold,
+		Align: align.Center,
+		Color: &props.Color{Red: 100, Green: 100, Blue: 100},
+	}
+
+	m.AddRows(
+		row.New(6).Add(
+			col.New(4).Add(text.New("____________________", lineStyle)),
+			col.New(4).Add(text.New("____________________", lineStyle)),
+			col.New(4).Add(text.New("____________________", lineStyle)),
+		),
+	)
+	m.AddRows(
+		row.New(7).Add(
+			col.New(4).Add(text.New("Received By", labelStyle)),
+			col.New(4).Add(text.New("Quality Inspection", labelStyle)),
+			col.New(4).Add(text.New("Store In-charge", labelStyle)),
+		),
+	)
+}
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/testhelpers"
+)
+
+// setupGRNTest creates a project with a sent PO carrying two line items.
+func setupGRNTest(t *testing.T) (*pocketbase.PocketBase, *core.Record, *core.Record, *core.Record, *core.Record) {
+	t.Helper()
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "GRN Project")
+	vendor := testhelpers.CreateTestVendor(t, app, "GRN Vendor")
+	po := testhelpers.CreateTestPurchaseOrder(t, app, project.Id, vendor.Id, "PO-1")
+	po.Set("status", "sent")
+	if err := app.Save(po); err != nil {
+		t.Fatalf("failed to update PO status: %v", err)
+	}
+	panel := testhelpers.CreateTestPOLineItem(t, app, po.Id, 1, "LED Panel", 10, 500, 18)
+	cable := testhelpers.CreateTestPOLineItem(t, app, po.Id, 2, "Cable", 100, 20, 18)
+	return app, project, po, panel, cable
+}
+
+func TestCreateGRN_PartialThenFullReceipt(t *testing.T) {
+	app, project, po, panel, cable := setupGRNTest(t)
+	ctx := context.Background()
+
+	first, err := CreateGRN(ctx, app, GRNParams{
+		ProjectID:       project.Id,
+		PurchaseOrderID: po.Id,
+		ReceivedDate:    "2025-06-10",
+		ReceivedBy:      "Store",
+		Lines: []GRNLineParams{
+			{POLineItemID: panel.Id, QtyReceived: 10, QtyRejected: 2, RejectionReason: "Cracked"},
+			{POLineItemID: cable.Id, QtyReceived: 100},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateGRN failed: %v", err)
+	}
+	if !strings.Contains(first.GRNNumber, "GRN") {
+		t.Errorf("expected a GRN number, got %q", first.GRNNumber)
+	}
+	if first.POCompleted {
+		t.Error("PO should not be completed while rejected panels are pending")
+	}
+
+	receipt, err := POReceiptStatus(app, po.Id)
+	if err != nil {
+		t.Fatalf("POReceiptStatus failed: %v", err)
+	}
+	if receipt[0].Accepted != 8 || receipt[0].Rejected != 2 || receipt[0].Pending() != 2 {
+		t.Errorf("panel: expected 8 accepted, 2 rejected, 2 pending, got %+v", receipt[0])
+	}
+	if receipt[1].Pending() != 0 {
+		t.Errorf("cable: expected nothing pending, got %v", receipt[1].Pending())
+	}
+
+	second, err := CreateGRN(ctx, app, GRNParams{
+		ProjectID:       project.Id,
+		PurchaseOrderID: po.Id,
+		ReceivedDate:    "2025-06-20",
+		Lines:           []GRNLineParams{{POLineItemID: panel.Id, QtyReceived: 2}},
+	})
+	if err != nil {
+		t.Fatalf("second CreateGRN failed: %v", err)
+	}
+	if !second.POCompleted {
+		t.Error("expected the PO to be completed once everything is accepted")
+	}
+	if second.GRNNumber == first.GRNNumber {
+		t.Errorf("expected a new GRN number, got %q twice", second.GRNNumber)
+	}
+
+	updated, err := app.FindRecordById("purchase_orders", po.Id)
+	if err != nil {
+		t.Fatalf("failed to reload PO: %v", err)
+	}
+	if updated.GetString("status") != "completed" {
+		t.Errorf("expected PO status completed, got %q", updated.GetString("status"))
+	}
+
+	lines, err := app.FindRecordsByFilter("grn_line_items", "grn = {:id}", "", 0, 0, map[string]any{"id": second.GRNID})
+	if err != nil {
+		t.Fatalf("failed to fetch GRN lines: %v", err)
+	}
+	if len(lines) != 1 {
+		t.Errorf("expected lines with nothing received to be skipped, got %d lines", len(lines))
+	}
+}
+
+func TestCreateGRN_Rejected(t *testing.T) {
+	app, project, po, panel, _ := setupGRNTest(t)
+	draft := testhelpers.CreateTestPurchaseOrder(t, app, project.Id, po.GetString("vendor"), "PO-2")
+	draftLine := testhelpers.CreateTestPOLineItem(t, app, draft.Id, 1, "Switch", 5, 50, 18)
+
+	tests := []struct {
+		name   string
+		poID   string
+		lines  []GRNLineParams
+		errSub string
+	}{
+		{"draft PO", draft.Id, []GRNLineParams{{POLineItemID: draftLine.Id, QtyReceived: 5}}, "draft purchase order"},
+		{"over pending", po.Id, []GRNLineParams{{POLineItemID: panel.Id, QtyReceived: 11}}, "exceeds the 10 still pending"},
+		{"rejection without reason", po.Id, []GRNLineParams{{POLineItemID: panel.Id, QtyReceived: 5, QtyRejected: 1}}, "rejection reason is required"},
+		{"rejected over received", po.Id, []GRNLineParams{{POLineItemID: panel.Id, QtyReceived: 1, QtyRejected: 2, RejectionReason: "Bent"}}, "cannot exceed the received"},
+		{"nothing received", po.Id, []GRNLineParams{{POLineItemID: panel.Id}}, "at least one item"},
+		{"foreign line", po.Id, []GRNLineParams{{POLineItemID: draftLine.Id, QtyReceived: 1}}, "does not belong"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CreateGRN(context.Background(), app, GRNParams{
+				ProjectID:       project.Id,
+				PurchaseOrderID: tt.poID,
+				ReceivedDate:    "2025-06-10",
+				Lines:           tt.lines,
+			})
+			if err == nil || !strings.Contains(err.Error(), tt.errSub) {
+				t.Errorf("expected error containing %q, got %v", tt.errSub, err)
+			}
+		})
+	}
+
+	grns, err := app.FindAllRecords("grn")
+	if err != nil {
+		t.Fatalf("failed to fetch GRNs: %v", err)
+	}
+	if len(grns) != 0 {
+		t.Errorf("expected no GRNs to be created, got %d", len(grns))
+	}
+}
+
+func TestGenerateGRNPDF(t *testing.T) {
+	data := &GRNExportData{
+		CompanyName:     "FSS Engineering",
+		GRNNumber:       "FSS-GRN-2526-001",
+		ReceivedDate:    "2025-06-10",
+		ReceivedBy:      "Store",
+		PONumber:        "FSS-PO-2526-001",
+		VendorName:      "Test Vendor",
+		TransporterName: "Fast Freight",
+		LRNumber:        "LR-42",
+		LineItems: []GRNExportLineItem{
+			{SINo: 1, Description: "LED Panel", UoM: "Nos", Rate: 500, QtyOrdered: 10, QtyReceived: 10, QtyRejected: 2, QtyAccepted: 8, Amount: 4000, RejectionReason: "Cracked"},
+			{SINo: 2, Description: "Cable", UoM: "Mtr", Rate: 20, QtyOrdered: 100, QtyReceived: 100, QtyAccepted: 100, Amount: 2000},
+		},
+		TotalAccepted: 6000,
+		Remarks:       "Two panels returned",
+	}
+
+	result, err := GenerateGRNPDF(data)
+	if err != nil {
+		t.Fatalf("GenerateGRNPDF() error = %v", err)
+	}
+	if len(result) < 5 || string(result[:5]) != "%PDF-" {
+		t.Errorf("result does not start with PDF header")
+	}
+}
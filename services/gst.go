@@ -0,0 +1,103 @@
+package services
+
+import "strings"
+
+// Tax types shared by delivery challans and purchase orders.
+const (
+	TaxTypeCGSTSGST = "cgst_sgst"
+	TaxTypeIGST     = "igst"
+)
+
+// gstStateCodes maps the two-digit GST state code (the first two characters
+// of a GSTIN) to the state or union territory name.
+var gstStateCodes = map[string]string{
+	"01": "jammu and kashmir",
+	"02": "himachal pradesh",
+	"03": "punjab",
+	"04": "chandigarh",
+	"05": "uttarakhand",
+	"06": "haryana",
+	"07": "delhi",
+	"08": "rajasthan",
+	"09": "uttar pradesh",
+	"10": "bihar",
+	"11": "sikkim",
+	"12": "arunachal pradesh",
+	"13": "nagaland",
+	"14": "manipur",
+	"15": "mizoram",
+	"16": "tripura",
+	"17": "meghalaya",
+	"18": "assam",
+	"19": "west bengal",
+	"20": "jharkhand",
+	"21": "odisha",
+	"22": "chhattisgarh",
+	"23": "madhya pradesh",
+	"24": "gujarat",
+	"26": "dadra and nagar haveli and daman and diu",
+	"27": "maharashtra",
+	"29": "karnataka",
+	"30": "goa",
+	"31": "lakshadweep",
+	"32": "kerala",
+	"33": "tamil nadu",
+	"34": "puducherry",
+	"35": "andaman and nicobar islands",
+	"36": "telangana",
+	"37": "andhra pradesh",
+	"38": "ladakh",
+}
+
+// DetermineTaxTypeByState decides between CGST+SGST (intra-state) and IGST
+// (inter-state) for a supply. Each party's state is taken from its GSTIN
+// when that is valid, otherwise from the state name. If either state cannot
+// be determined the supply is treated as inter-state.
+func DetermineTaxTypeByState(supplierState, supplierGSTIN, recipientState, recipientGSTIN string) string {
+	supplier := gstStateKey(supplierState, supplierGSTIN)
+	recipient := gstStateKey(recipientState, recipientGSTIN)
+	if supplier == "" || recipient == "" || supplier != recipient {
+		return TaxTypeIGST
+	}
+	return TaxTypeCGSTSGST
+}
+
+// gstStateKey returns a comparable key for a party's state: the GST state
+// code where it can be worked out, else the normalised state name.
+func gstStateKey(state, gstin string) string {
+	gstin = strings.TrimSpace(gstin)
+	if len(gstin) == 15 {
+		if _, ok := gstStateCodes[gstin[:2]]; ok {
+			return gstin[:2]
+		}
+	}
+
+	name := strings.Join(strings.Fields(strings.ToLower(state)), " ")
+	name = strings.ReplaceAll(name, "&", "and")
+	if name == "" {
+		return ""
+	}
+	for code, stateName := range gstStateCodes {
+		if stateName == name {
+			return code
+		}
+	}
+	return name
+}
+
+// PlaceOfSupply describes the recipient's state for a tax invoice as
+// "<code> - <state>", e.g. "29 - Karnataka". If the GST state code cannot be
+// worked out the state is returned as given.
+func PlaceOfSupply(state, gstin string) string {
+	code := gstStateKey(state, gstin)
+	name, ok := gstStateCodes[code]
+	if !ok {
+		return strings.TrimSpace(state)
+	}
+	for _, s := range IndianStates {
+		if strings.ToLower(s) == name {
+			return code + " - " + s
+		}
+	}
+	return code + " - " + name
+}
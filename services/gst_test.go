@@ -0,0 +1,54 @@
+package services
+
+import "testing"
+
+func TestDetermineTaxTypeByState(t *testing.T) {
+	tests := []struct {
+		name           string
+		supplierState  string
+		supplierGSTIN  string
+		recipientState string
+		recipientGSTIN string
+		want           string
+	}{
+		{"same_state_name", "Karnataka", "", " karnataka ", "", TaxTypeCGSTSGST},
+		{"different_state_name", "Karnataka", "", "Tamil Nadu", "", TaxTypeIGST},
+		{"gstin_codes_match", "", "29AAPFU0939F1ZV", "", "29AADCB2230M1ZV", TaxTypeCGSTSGST},
+		{"gstin_overrides_state", "Karnataka", "27AADCB2230M1ZV", "Karnataka", "29AAPFU0939F1ZV", TaxTypeIGST},
+		{"gstin_against_state_name", "", "27AADCB2230M1ZV", "Maharashtra", "", TaxTypeCGSTSGST},
+		{"ampersand_in_name", "Jammu & Kashmir", "", "", "01AAPFU0939F1ZV", TaxTypeCGSTSGST},
+		{"invalid_gstin_falls_back", "Delhi", "XX123", "Delhi", "", TaxTypeCGSTSGST},
+		{"unknown_supplier_state", "", "", "Karnataka", "", TaxTypeIGST},
+		{"unknown_recipient_state", "Karnataka", "", "", "", TaxTypeIGST},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DetermineTaxTypeByState(tt.supplierState, tt.supplierGSTIN, tt.recipientState, tt.recipientGSTIN)
+			if got != tt.want {
+				t.Errorf("DetermineTaxTypeByState() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlaceOfSupply(t *testing.T) {
+	tests := []struct {
+		name  string
+		state string
+		gstin string
+		want  string
+	}{
+		{"from_gstin", "", "29AAPFU0939F1ZV", "29 - Karnataka"},
+		{"from_state_name", "tamil nadu", "", "33 - Tamil Nadu"},
+		{"gstin_wins", "Karnataka", "27AADCB2230M1ZV", "27 - Maharashtra"},
+		{"unknown_state", " Atlantis ", "", "Atlantis"},
+		{"nothing_known", "", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PlaceOfSupply(tt.state, tt.gstin); got != tt.want {
+				t.Errorf("PlaceOfSupply(%q, %q) = %q, want %q", tt.state, tt.gstin, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,314 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// Reasons for a material return note.
+const (
+	MRNReasonReturnable = "returnable" // goods on a returnable DC coming back
+	MRNReasonFaulty     = "faulty"
+	MRNReasonExcess     = "excess"
+)
+
+// SerialStatusAvailable marks a serial number that has come back on a
+// material return note and can be dispatched again. Serials on a DC are in
+// use while their status is empty.
+const SerialStatusAvailable = "available"
+
+// ReturnSerial is a serial on a DC line that has not been returned yet.
+type ReturnSerial struct {
+	ID           string
+	SerialNumber string
+}
+
+// ReturnableDCLine is a DC line with what has come back against it on
+// material return notes.
+type ReturnableDCLine struct {
+	DCLineItemID string
+	Description  string
+	UOM          string
+	Dispatched   float64
+	Returned     float64
+	HasSerials   bool
+	Serials      []ReturnSerial // not yet returned
+}
+
+// Outstanding is the quantity on the line still to come back.
+func (l ReturnableDCLine) Outstanding() float64 {
+	return l.Dispatched - l.Returned
+}
+
+// MRNLineParams is what comes back against one DC line. A line with serials
+// names exactly Quantity of them.
+type MRNLineParams struct {
+	DCLineItemID string
+	Quantity     float64
+	Condition    string // good or faulty
+	SerialIDs    []string
+	Remarks      string
+}
+
+// MRNParams holds a material return note against a DC.
+type MRNParams struct {
+	DCID       string
+	ReturnDate string
+	Reason     string
+	ReturnedBy string
+	Remarks    string
+	Lines      []MRNLineParams
+}
+
+// MRNReasons returns the reasons a material return against a DC of the
+// given type can give: a returnable DC's goods simply come back, goods on
+// any other DC come back faulty or in excess.
+func MRNReasons(dcType string) []string {
+	if dcType == "returnable" {
+		return []string{MRNReasonReturnable}
+	}
+	return []string{MRNReasonFaulty, MRNReasonExcess}
+}
+
+// CheckReturnable reports why material cannot be returned against a DC, or
+// nil when it can. Goods come back against issued returnable, transit and
+// transfer DCs, whose lines carry the serials, until every line is back.
+func CheckReturnable(app core.App, dc *core.Record) error {
+	switch dc.GetString("dc_type") {
+	case "returnable", "transit", "transfer":
+	default:
+		return fmt.Errorf("material is returned against the transit DC of a shipment, not its %s DCs", dc.GetString("dc_type"))
+	}
+	switch dc.GetString("status") {
+	case "issued", "splitting", "split", DCStatusDelivered, DCStatusPartiallyDelivered:
+	default:
+		return fmt.Errorf("%s is %s; material can only be returned against issued DCs", dc.GetString("dc_number"), dc.GetString("status"))
+	}
+	lines, err := ReturnableDCLines(app, dc.Id)
+	if err != nil {
+		return err
+	}
+	for _, l := range lines {
+		if l.Outstanding() > 0 {
+			return nil
+		}
+	}
+	return fmt.Errorf("everything on %s has been returned", dc.GetString("dc_number"))
+}
+
+// ReturnableDCLines returns the lines of a DC in line order with the
+// quantities returned so far and the serials still out.
+func ReturnableDCLines(app core.App, dcID string) ([]ReturnableDCLine, error) {
+	lines, err := DeliverableDCLines(app, dcID)
+	if err != nil {
+		return nil, err
+	}
+
+	returned := make(map[string]float64)
+	returnLines, err := app.FindRecordsByFilter("material_return_lines", "dc_line_item.dc = {:did}", "", 0, 0, map[string]any{"did": dcID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch material return lines: %w", err)
+	}
+	for _, rl := range returnLines {
+		returned[rl.GetString("dc_line_item")] += rl.GetFloat("quantity")
+	}
+
+	result := make([]ReturnableDCLine, 0, len(lines))
+	for _, l := range lines {
+		line := ReturnableDCLine{
+			DCLineItemID: l.DCLineItemID,
+			Description:  l.Description,
+			UOM:          l.UOM,
+			Dispatched:   l.Dispatched,
+			Returned:     returned[l.DCLineItemID],
+		}
+		serials, _ := app.FindRecordsByFilter("serial_numbers", "line_item = {:lid}", "created", 0, 0, map[string]any{"lid": l.DCLineItemID})
+		line.HasSerials = len(serials) > 0
+		for _, s := range serials {
+			if s.GetString("status") != SerialStatusAvailable {
+				line.Serials = append(line.Serials, ReturnSerial{ID: s.Id, SerialNumber: s.GetString("serial_number")})
+			}
+		}
+		result = append(result, line)
+	}
+	return result, nil
+}
+
+// ValidateMRN checks a material return note. Errors are keyed by form field
+// name, or by DC line item ID for a line.
+func ValidateMRN(app core.App, params MRNParams) map[string]string {
+	errs := make(map[string]string)
+
+	dc, err := app.FindRecordById("delivery_challans", params.DCID)
+	if err != nil {
+		errs["dc"] = "Delivery challan not found"
+		return errs
+	}
+	if err := CheckReturnable(app, dc); err != nil {
+		errs["dc"] = err.Error()
+		return errs
+	}
+
+	if !slices.Contains(MRNReasons(dc.GetString("dc_type")), params.Reason) {
+		errs["reason"] = "Choose a reason for the return"
+	}
+	date, err := time.Parse("2006-01-02", params.ReturnDate)
+	switch {
+	case err != nil:
+		errs["return_date"] = "Return date is required"
+	case date.After(time.Now()):
+		errs["return_date"] = "Return date cannot be in the future"
+	case params.ReturnDate < dc.GetString("challan_date"):
+		errs["return_date"] = "Return date cannot be before the challan date"
+	}
+
+	lines, err := ReturnableDCLines(app, dc.Id)
+	if err != nil {
+		errs["dc"] = "Could not load the DC's line items"
+		return errs
+	}
+	byID := make(map[string]ReturnableDCLine, len(lines))
+	for _, l := range lines {
+		byID[l.DCLineItemID] = l
+	}
+
+	total := 0.0
+	for _, l := range params.Lines {
+		line, ok := byID[l.DCLineItemID]
+		if !ok {
+			errs[l.DCLineItemID] = "Line item does not belong to this DC"
+			continue
+		}
+		if l.Quantity == 0 && len(l.SerialIDs) == 0 {
+			continue
+		}
+		total += l.Quantity
+		available := make(map[string]bool, len(line.Serials))
+		for _, s := range line.Serials {
+			available[s.ID] = true
+		}
+		switch {
+		case l.Quantity < 0:
+			errs[l.DCLineItemID] = fmt.Sprintf("%s: quantity cannot be negative", line.Description)
+		case l.Quantity > line.Outstanding():
+			errs[l.DCLineItemID] = fmt.Sprintf("%s: only %s still to be returned", line.Description, formatQty(line.Outstanding()))
+		case l.Condition != "good" && l.Condition != "faulty":
+			errs[l.DCLineItemID] = fmt.Sprintf("%s: choose the condition of the returned goods", line.Description)
+		case line.HasSerials && float64(len(l.SerialIDs)) != l.Quantity:
+			errs[l.DCLineItemID] = fmt.Sprintf("%s: select the %s serials being returned", line.Description, formatQty(l.Quantity))
+		case len(slices.Compact(slices.Sorted(slices.Values(l.SerialIDs)))) != len(l.SerialIDs):
+			errs[l.DCLineItemID] = fmt.Sprintf("%s: a serial is selected twice", line.Description)
+		default:
+			for _, id := range l.SerialIDs {
+				if !available[id] {
+					errs[l.DCLineItemID] = fmt.Sprintf("%s: a selected serial is not out on this line", line.Description)
+					break
+				}
+			}
+		}
+	}
+	if total <= 0 && len(errs) == 0 {
+		errs["lines"] = "Enter the quantity returned on at least one line"
+	}
+	return errs
+}
+
+// CreateMRN saves a material return note against a DC and puts the serials
+// it returns back into the available state, so they can be dispatched
+// again. Lines with nothing returned are left out.
+func CreateMRN(ctx context.Context, app *pocketbase.PocketBase, params MRNParams) (*core.Record, error) {
+	if errs := ValidateMRN(app, params); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid material return: %s", strings.Join(slices.Sorted(maps.Values(errs)), "; "))
+	}
+	dc, err := app.FindRecordById("delivery_challans", params.DCID)
+	if err != nil {
+		return nil, fmt.Errorf("delivery challan not found: %w", err)
+	}
+
+	returnDate, _ := time.Parse("2006-01-02", params.ReturnDate)
+	mrnNum, err := NextDocNumber(app, dc.GetString("project"), "mrn", returnDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate MRN number: %w", err)
+	}
+
+	mrnCol, err := app.FindCollectionByNameOrId("material_return_notes")
+	if err != nil {
+		return nil, fmt.Errorf("material_return_notes collection not found: %w", err)
+	}
+	lineCol, err := app.FindCollectionByNameOrId("material_return_lines")
+	if err != nil {
+		return nil, fmt.Errorf("material_return_lines collection not found: %w", err)
+	}
+
+	mrn := core.NewRecord(mrnCol)
+	err = app.RunInTransaction(func(txApp core.App) error {
+		mrn.Set("project", dc.GetString("project"))
+		mrn.Set("dc", dc.Id)
+		mrn.Set("mrn_number", mrnNum)
+		mrn.Set("return_date", params.ReturnDate)
+		mrn.Set("reason", params.Reason)
+		mrn.Set("returned_by", strings.TrimSpace(params.ReturnedBy))
+		mrn.Set("remarks", strings.TrimSpace(params.Remarks))
+		if err := txApp.SaveWithContext(ctx, mrn); err != nil {
+			return fmt.Errorf("failed to save material return note: %w", err)
+		}
+
+		for _, l := range params.Lines {
+			if l.Quantity == 0 {
+				continue
+			}
+			rec := core.NewRecord(lineCol)
+			rec.Set("mrn", mrn.Id)
+			rec.Set("dc_line_item", l.DCLineItemID)
+			rec.Set("quantity", l.Quantity)
+			rec.Set("condition", l.Condition)
+			rec.Set("serial_numbers", l.SerialIDs)
+			rec.Set("remarks", strings.TrimSpace(l.Remarks))
+			if err := txApp.SaveWithContext(ctx, rec); err != nil {
+				return fmt.Errorf("failed to save material return line: %w", err)
+			}
+
+			for _, id := range l.SerialIDs {
+				serial, err := txApp.FindRecordById("serial_numbers", id)
+				if err != nil {
+					return fmt.Errorf("serial number not found: %w", err)
+				}
+				if err := releaseSerial(ctx, txApp, dc.GetString("project"), serial.GetString("serial_number")); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mrn, nil
+}
+
+// releaseSerial makes a returned serial number available again. A serial
+// forwarded along a transfer DC and its splits has a record on each DC it
+// went out on, so every record of it still in use in the project is
+// released, not just the one on the DC it came back against.
+func releaseSerial(ctx context.Context, app core.App, projectID, serialNumber string) error {
+	records, err := app.FindRecordsByFilter("serial_numbers",
+		"project = {:pid} && serial_number = {:sn} && status != {:available}", "", 0, 0,
+		map[string]any{"pid": projectID, "sn": serialNumber, "available": SerialStatusAvailable})
+	if err != nil {
+		return fmt.Errorf("failed to fetch serial %s: %w", serialNumber, err)
+	}
+	for _, rec := range records {
+		rec.Set("status", SerialStatusAvailable)
+		if err := app.SaveWithContext(ctx, rec); err != nil {
+			return fmt.Errorf("failed to release serial %s: %w", serialNumber, err)
+		}
+	}
+	return nil
+}
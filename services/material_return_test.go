@@ -0,0 +1,224 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/testhelpers"
+)
+
+// issuedReturnableDC creates and issues a returnable DC lending 2 demo
+// inverters, serials INV-1 and INV-2, due back on 2026-03-20.
+func issuedReturnableDC(t *testing.T) (app *pocketbase.PocketBase, dc *core.Record, line ReturnableDCLine) {
+	t.Helper()
+	app = testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Returns Project")
+	boq := testhelpers.CreateTestBOQ(t, app, project.Id, "Main BOQ")
+	main := testhelpers.CreateTestMainBOQItem(t, app, boq.Id, "Solar plant")
+	inverter := testhelpers.CreateTestSubItem(t, app, main.Id, "Demo inverter")
+	site := testhelpers.CreateTestAddress(t, app, project.Id, "ship_to", "Site A")
+	ctx := context.Background()
+
+	dc, err := CreateReturnableDC(ctx, app, ReturnableDCParams{
+		ProjectID:          project.Id,
+		ChallanDate:        "2026-03-10",
+		ExpectedReturnDate: "2026-03-20",
+		ShipToID:           site.Id,
+		Items: []ShipmentItemParams{{
+			SourceItemType: "sub_item", SourceItemID: inverter.Id, Rate: 1000,
+			TotalQty: 2, Serials: []string{"INV-1", "INV-2"},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("CreateReturnableDC failed: %v", err)
+	}
+	if err := IssueSingleDC(ctx, app, dc.Id); err != nil {
+		t.Fatalf("IssueSingleDC failed: %v", err)
+	}
+	dc, _ = app.FindRecordById("delivery_challans", dc.Id)
+	lines, err := ReturnableDCLines(app, dc.Id)
+	if err != nil || len(lines) != 1 {
+		t.Fatalf("expected 1 returnable line, got %d (%v)", len(lines), err)
+	}
+	return app, dc, lines[0]
+}
+
+func TestCreateReturnableDC(t *testing.T) {
+	app, dc, line := issuedReturnableDC(t)
+	if dc.GetString("dc_type") != "returnable" || dc.GetString("expected_return_date") != "2026-03-20" {
+		t.Errorf("unexpected DC %v", dc.PublicExport())
+	}
+	if line.Dispatched != 2 || len(line.Serials) != 2 {
+		t.Errorf("expected 2 dispatched with 2 serials, got %v", line)
+	}
+
+	draft := ReturnableDCParams{ProjectID: dc.GetString("project"), ChallanDate: "2026-03-10", ExpectedReturnDate: "2026-03-09"}
+	if _, err := CreateReturnableDC(context.Background(), app, draft); err == nil {
+		t.Error("expected a return date before the challan date to be rejected")
+	}
+}
+
+func TestBuildOutstandingReturns(t *testing.T) {
+	app, dc, line := issuedReturnableDC(t)
+	projectID := dc.GetString("project")
+
+	returns, err := BuildOutstandingReturns(app, projectID, time.Date(2026, 3, 25, 15, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("BuildOutstandingReturns failed: %v", err)
+	}
+	if len(returns) != 1 || returns[0].Outstanding() != 2 || returns[0].DaysOverdue != 5 {
+		t.Fatalf("expected 2 outstanding, 5 days overdue, got %+v", returns)
+	}
+	returns, _ = BuildOutstandingReturns(app, projectID, time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC))
+	if len(returns) != 1 || returns[0].DaysOverdue != 0 {
+		t.Errorf("expected nothing overdue on the due date, got %+v", returns)
+	}
+
+	// Once everything is back the DC drops off the report
+	if _, err := CreateMRN(context.Background(), app, MRNParams{
+		DCID: dc.Id, ReturnDate: "2026-03-24", Reason: MRNReasonReturnable,
+		Lines: []MRNLineParams{{DCLineItemID: line.DCLineItemID, Quantity: 2, Condition: "good", SerialIDs: []string{line.Serials[0].ID, line.Serials[1].ID}}},
+	}); err != nil {
+		t.Fatalf("CreateMRN failed: %v", err)
+	}
+	returns, _ = BuildOutstandingReturns(app, projectID, time.Date(2026, 3, 25, 0, 0, 0, 0, time.UTC))
+	if len(returns) != 0 {
+		t.Errorf("expected no outstanding returns, got %+v", returns)
+	}
+}
+
+func TestValidateMRN(t *testing.T) {
+	app, dc, line := issuedReturnableDC(t)
+	valid := MRNParams{
+		DCID: dc.Id, ReturnDate: "2026-03-18", Reason: MRNReasonReturnable,
+		Lines: []MRNLineParams{{DCLineItemID: line.DCLineItemID, Quantity: 1, Condition: "good", SerialIDs: []string{line.Serials[0].ID}}},
+	}
+	if errs := ValidateMRN(app, valid); len(errs) > 0 {
+		t.Fatalf("expected no errors, got %v", errs)
+	}
+
+	tests := []struct {
+		name  string
+		line  MRNLineParams
+		edit  func(p *MRNParams)
+		field string
+	}{
+		{"wrong reason", valid.Lines[0], func(p *MRNParams) { p.Reason = MRNReasonFaulty }, "reason"},
+		{"before challan date", valid.Lines[0], func(p *MRNParams) { p.ReturnDate = "2026-03-09" }, "return_date"},
+		{"nothing returned", MRNLineParams{DCLineItemID: line.DCLineItemID}, nil, "lines"},
+		{"more than outstanding", MRNLineParams{DCLineItemID: line.DCLineItemID, Quantity: 3, Condition: "good"}, nil, line.DCLineItemID},
+		{"serials missing", MRNLineParams{DCLineItemID: line.DCLineItemID, Quantity: 2, Condition: "good", SerialIDs: []string{line.Serials[0].ID}}, nil, line.DCLineItemID},
+		{"serial twice", MRNLineParams{DCLineItemID: line.DCLineItemID, Quantity: 2, Condition: "good", SerialIDs: []string{line.Serials[0].ID, line.Serials[0].ID}}, nil, line.DCLineItemID},
+		{"unknown serial", MRNLineParams{DCLineItemID: line.DCLineItemID, Quantity: 1, Condition: "good", SerialIDs: []string{"nope"}}, nil, line.DCLineItemID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := valid
+			p.Lines = []MRNLineParams{tt.line}
+			if tt.edit != nil {
+				tt.edit(&p)
+			}
+			if errs := ValidateMRN(app, p); errs[tt.field] == "" {
+				t.Errorf("expected an error on %s, got %v", tt.field, errs)
+			}
+		})
+	}
+}
+
+func TestCreateMRN_ReleasesSerials(t *testing.T) {
+	app, dc, line := issuedReturnableDC(t)
+	ctx := context.Background()
+	projectID := dc.GetString("project")
+
+	mrn, err := CreateMRN(ctx, app, MRNParams{
+		DCID: dc.Id, ReturnDate: "2026-03-18", Reason: MRNReasonReturnable, ReturnedBy: "Site engineer",
+		Lines: []MRNLineParams{{DCLineItemID: line.DCLineItemID, Quantity: 1, Condition: "good", SerialIDs: []string{line.Serials[0].ID}}},
+	})
+	if err != nil {
+		t.Fatalf("CreateMRN failed: %v", err)
+	}
+	if mrn.GetString("mrn_number") == "" {
+		t.Error("expected an MRN number")
+	}
+
+	lines, _ := ReturnableDCLines(app, dc.Id)
+	if lines[0].Outstanding() != 1 || len(lines[0].Serials) != 1 || lines[0].Serials[0].SerialNumber != "INV-2" {
+		t.Errorf("expected INV-2 still out, got %+v", lines[0])
+	}
+	serial, _ := app.FindRecordById("serial_numbers", line.Serials[0].ID)
+	if serial.GetString("status") != SerialStatusAvailable {
+		t.Errorf("expected INV-1 available, got %q", serial.GetString("status"))
+	}
+
+	// INV-1 can go out again
+	next := testhelpers.CreateTestDeliveryChallan(t, app, projectID, "TDC-002", "transfer", "draft")
+	li := saveTestRecord(t, app, "dc_line_items", map[string]any{
+		"dc": next.Id, "source_item_type": "sub_item", "source_item_id": "x", "quantity": 1,
+	})
+	saveTestRecord(t, app, "serial_numbers", map[string]any{"project": projectID, "line_item": li.Id, "serial_number": "INV-1"})
+	if err := validateDCSerials(app, next.Id, projectID); err != nil {
+		t.Errorf("expected returned INV-1 to be reusable, got %v", err)
+	}
+
+	if err := CancelDC(ctx, app, dc.Id, "Wrong site"); err == nil {
+		t.Error("expected a DC with material returned to be kept")
+	}
+}
+
+func TestCreateMRN_ReleasesForwardedSerials(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Return Project")
+	ctx := context.Background()
+	dc, dests, _ := issuedTransferDC(t, app, project.Id, 2, 1)
+	parentLine, _ := app.FindFirstRecordByData("dc_line_items", "dc", dc.Id)
+	for _, serial := range []string{"SN-1", "SN-2", "SN-3"} {
+		saveTestRecord(t, app, "serial_numbers", map[string]any{
+			"project": project.Id, "line_item": parentLine.Id, "serial_number": serial,
+		})
+	}
+	split, err := CreateSplit(ctx, app, SplitParams{
+		TransferDCID:      dc.Id,
+		DestinationIDs:    []string{dests[0].Id},
+		SerialAssignments: map[string][]string{parentLine.Id: {"SN-1", "SN-2"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateSplit failed: %v", err)
+	}
+	if err := IssueShipmentGroup(ctx, app, split.ShipmentGroupID); err != nil {
+		t.Fatalf("IssueShipmentGroup failed: %v", err)
+	}
+
+	// SN-1 comes back faulty against the split's transit DC
+	lines, err := ReturnableDCLines(app, split.TransitDCID)
+	if err != nil || len(lines) != 1 {
+		t.Fatalf("expected one returnable line, got %v (%v)", lines, err)
+	}
+	var sn1 string
+	for _, s := range lines[0].Serials {
+		if s.SerialNumber == "SN-1" {
+			sn1 = s.ID
+		}
+	}
+	if _, err := CreateMRN(ctx, app, MRNParams{
+		DCID: split.TransitDCID, ReturnDate: time.Now().Format("2006-01-02"), Reason: MRNReasonFaulty,
+		Lines: []MRNLineParams{{DCLineItemID: lines[0].DCLineItemID, Quantity: 1, Condition: "faulty", SerialIDs: []string{sn1}}},
+	}); err != nil {
+		t.Fatalf("CreateMRN failed: %v", err)
+	}
+
+	// The copies on the transfer DC are released too, so SN-1 can go out again
+	inUse, err := FindSerialsInUse(app, project.Id, []string{"SN-1", "SN-2"})
+	if err != nil {
+		t.Fatalf("FindSerialsInUse failed: %v", err)
+	}
+	if _, ok := inUse["SN-1"]; ok {
+		t.Errorf("expected SN-1 to be free after its return, still held by %s", inUse["SN-1"])
+	}
+	if _, ok := inUse["SN-2"]; !ok {
+		t.Error("expected SN-2 to stay in use")
+	}
+}
@@ -30,15 +30,29 @@ func TypeCode(seqType string) string {
 		return "ODC"
 	case "stdc":
 		return "STDC"
+	case "grn":
+		return "GRN"
+	case "inv":
+		return "INV"
+	case "wo":
+		return "WO"
+	case "cc":
+		return "CC"
+	case "rdc":
+		return "RDC"
+	case "mrn":
+		return "MRN"
 	default:
 		return strings.ToUpper(seqType)
 	}
 }
 
 // ConfigGroupForType returns "po" or "dc" based on the sequence type.
-// PO types use po_* project fields; DC types use dc_* project fields.
+// Purchasing documents (POs, GRNs and installation work orders) use po_*
+// project fields; DC types, client invoices and commissioning certificates
+// use dc_* project fields.
 func ConfigGroupForType(seqType string) string {
-	if seqType == "po" {
+	if seqType == "po" || seqType == "grn" || seqType == "wo" {
 		return "po"
 	}
 	return "dc"
@@ -84,11 +98,13 @@ func NextDocNumber(app *pocketbase.PocketBase, projectID, seqType string, docDat
 	}
 	projRef := project.GetString("reference_number")
 
-	// Determine start number: PO has one start, DC has per-type starts
+	// Determine start number: PO has one start, DC has per-type starts and
+	// GRNs, work orders and client invoices always start from 1
 	var seqStart int
-	if group == "po" {
+	switch {
+	case seqType == "po":
 		seqStart = project.GetInt("po_seq_start")
-	} else {
+	case group == "dc" && seqType != "inv":
 		seqStart = project.GetInt("dc_seq_start_" + seqType)
 	}
 	if seqStart == 0 {
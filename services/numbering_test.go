@@ -46,6 +46,8 @@ func TestFormatDocNumber(t *testing.T) {
 		{"dc_official", "{PREFIX}{SEP}{TYPE}{SEP}{FY}{SEP}{SEQ}", "-", "ABC", "odc", "2526", 5, 3, "", "ABC-ODC-2526-005"},
 		{"dc_transfer", "{PREFIX}{SEP}{TYPE}{SEP}{FY}{SEP}{SEQ}", "-", "ABC", "stdc", "2526", 1, 4, "", "ABC-STDC-2526-0001"},
 		{"custom_sep", "{PREFIX}{SEP}{TYPE}{SEP}{FY}{SEP}{SEQ}", "/", "XYZ", "odc", "2526", 42, 4, "", "XYZ/ODC/2526/0042"},
+		{"grn", "{PREFIX}{SEP}{TYPE}{SEP}{FY}{SEP}{SEQ}", "-", "FSS", "grn", "2526", 7, 3, "", "FSS-GRN-2526-007"},
+		{"client_invoice", "{PREFIX}{SEP}{TYPE}{SEP}{FY}{SEP}{SEQ}", "-", "ABC", "inv", "2526", 12, 3, "", "ABC-INV-2526-012"},
 		{"po_with_ref", "{PREFIX}{SEP}{TYPE}{SEP}{PROJECT_REF}{SEP}{FY}{SEP}{SEQ}", "-", "FSS", "po", "2526", 42, 4, "OAVS", "FSS-PO-OAVS-2526-0042"},
 	}
 	for _, tt := range tests {
@@ -67,6 +69,10 @@ func TestNumberingConfigForType(t *testing.T) {
 		{"tdc", "dc"},
 		{"odc", "dc"},
 		{"stdc", "dc"},
+		{"grn", "po"},
+		{"inv", "dc"},
+		{"wo", "po"},
+		{"cc", "dc"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.seqType, func(t *testing.T) {
@@ -39,8 +39,12 @@ type POExportData struct {
 
 	// Totals
 	TotalBeforeTax float64
+	TaxType        string // TaxTypeCGSTSGST or TaxTypeIGST
 	IGSTPercent    float64
 	IGSTAmount     float64
+	CGSTAmount     float64
+	SGSTAmount     float64
+	TaxSlabs       []POTaxSlab
 	RoundOff       float64
 	GrandTotal     float64
 	AmountInWords  string
@@ -50,6 +54,10 @@ type POExportData struct {
 	DeliveryTerms string
 	WarrantyTerms string
 	Comments      string
+
+	// VerifyQR is the content of the verification QR code; none is printed
+	// when it is empty.
+	VerifyQR string
 }
 
 // POExportVendor holds vendor details for PDF export.
@@ -205,7 +213,7 @@ func BuildPOExportData(app *pocketbase.PocketBase, poId string) (*POExportData,
 	}
 
 	// 7. Calculate order-level totals
-	totals := CalcPOTotals(calcItems)
+	totals := CalcPOTotalsForTaxType(calcItems, POTaxType(app, po))
 
 	// Fetch company branding from app_settings
 	companyName := collections.GetCompanyName(app)
@@ -231,8 +239,12 @@ func BuildPOExportData(app *pocketbase.PocketBase, poId string) (*POExportData,
 		LineItems: lineItems,
 
 		TotalBeforeTax: totals.TotalBeforeTax,
+		TaxType:        totals.TaxType,
 		IGSTPercent:    totals.IGSTPercent,
 		IGSTAmount:     totals.IGSTAmount,
+		CGSTAmount:     totals.CGSTAmount,
+		SGSTAmount:     totals.SGSTAmount,
+		TaxSlabs:       totals.TaxSlabs,
 		RoundOff:       totals.RoundOff,
 		GrandTotal:     totals.GrandTotal,
 		AmountInWords:  AmountToWords(totals.GrandTotal),
@@ -244,6 +256,25 @@ func BuildPOExportData(app *pocketbase.PocketBase, poId string) (*POExportData,
 	}, nil
 }
 
+// POTaxType returns the tax type for a purchase order: CGST+SGST when the
+// vendor is in the same state as the bill-to address, IGST otherwise or when
+// either side is missing.
+func POTaxType(app core.App, po *core.Record) string {
+	vendor, err := app.FindRecordById("vendors", po.GetString("vendor"))
+	if err != nil {
+		return TaxTypeIGST
+	}
+	billTo, err := app.FindRecordById("addresses", po.GetString("bill_to_address"))
+	if err != nil {
+		return TaxTypeIGST
+	}
+	billToData := ReadAddressData(billTo)
+	return DetermineTaxTypeByState(
+		vendor.GetString("state"), vendor.GetString("gstin"),
+		billToData["state"], billToData["gstin"],
+	)
+}
+
 // buildExportAddress creates a POExportAddress from a PocketBase address record.
 func buildExportAddress(addr *core.Record) *POExportAddress {
 	data := ReadAddressData(addr)
@@ -45,10 +45,14 @@ func GeneratePOPDF(data *POExportData) ([]byte, error) {
 	addPOAddresses(m, data)
 	addPOLineItemsTable(m, data)
 	addPOTotals(m, data)
+	addPOTaxSummary(m, data)
 	addPOAmountInWords(m, data)
 	addPOComments(m, data)
 	addPOTerms(m, data)
 	addPOBankDetails(m, data)
+	if data.VerifyQR != "" {
+		addVerifyQR(m, data.VerifyQR)
+	}
 	addPOSignatures(m)
 
 	doc, err := m.Generate()
@@ -451,14 +455,21 @@ func addPOTotals(m core.Maroto, data *POExportData) {
 		),
 	)
 
-	// IGST
-	igstLabel := fmt.Sprintf("IGST %.0f%%", data.IGSTPercent)
-	m.AddRows(
-		row.New(7).Add(
-			col.New(9).Add(text.New(igstLabel, labelStyle)).WithStyle(summaryCell),
-			col.New(3).Add(text.New(FormatINR(data.IGSTAmount), valueStyle)).WithStyle(summaryCell),
-		),
-	)
+	// Tax: CGST + SGST for intra-state supplies, IGST otherwise
+	totals := POTotals{
+		TaxType:     data.TaxType,
+		IGSTPercent: data.IGSTPercent,
+		IGSTAmount:  data.IGSTAmount,
+		TaxSlabs:    data.TaxSlabs,
+	}
+	for _, line := range totals.TaxLines() {
+		m.AddRows(
+			row.New(7).Add(
+				col.New(9).Add(text.New(line.Label, labelStyle)).WithStyle(summaryCell),
+				col.New(3).Add(text.New(FormatINR(line.Amount), valueStyle)).WithStyle(summaryCell),
+			),
+		)
+	}
 
 	// Round Off
 	m.AddRows(
@@ -484,6 +495,91 @@ func addPOTotals(m core.Maroto, data *POExportData) {
 	m.AddRows(row.New(3))
 }
 
+// addPOTaxSummary adds a table breaking the tax down by GST rate.
+func addPOTaxSummary(m core.Maroto, data *POExportData) {
+	if len(data.TaxSlabs) == 0 {
+		return
+	}
+
+	headerBg := &props.Color{Red: 33, Green: 37, Blue: 41}
+	headerCell := props.Cell{BackgroundColor: headerBg}
+	headerText := props.Text{
+		Size:  7,
+		Style: fontstyle.Bold,
+		Align: align.Right,
+		Color: &props.Color{Red: 255, Green: 255, Blue: 255},
+	}
+	headerTextCenter := headerText
+	headerTextCenter.Align = align.Center
+	bodyText := props.Text{Size: 7, Align: align.Right}
+	bodyTextCenter := props.Text{Size: 7, Align: align.Center}
+	totalText := props.Text{Size: 7, Style: fontstyle.Bold, Align: align.Right}
+	totalCell := &props.Cell{BackgroundColor: &props.Color{Red: 245, Green: 245, Blue: 245}}
+
+	m.AddRows(row.New(3))
+	m.AddRows(
+		row.New(6).Add(
+			col.New(12).Add(text.New("TAX SUMMARY", props.Text{Size: 8, Style: fontstyle.Bold})),
+		),
+	)
+
+	intraState := data.TaxType == TaxTypeCGSTSGST
+	taxHeaders := []string{"IGST"}
+	if intraState {
+		taxHeaders = []string{"CGST", "SGST"}
+	}
+
+	// Columns: GST Rate | Taxable Value | tax column(s) | Total Tax
+	taxWidth := 6 / len(taxHeaders)
+	header := row.New(7).Add(
+		col.New(2).Add(text.New("GST Rate", headerTextCenter)).WithStyle(&headerCell),
+		col.New(2).Add(text.New("Taxable Value", headerText)).WithStyle(&headerCell),
+	)
+	for _, h := range taxHeaders {
+		header.Add(col.New(taxWidth).Add(text.New(h, headerText)).WithStyle(&headerCell))
+	}
+	header.Add(col.New(2).Add(text.New("Total Tax", headerText)).WithStyle(&headerCell))
+	m.AddRows(header)
+
+	var totalTaxable, totalCGST, totalSGST, totalIGST float64
+	for _, slab := range data.TaxSlabs {
+		totalTaxable += slab.Taxable
+		totalCGST += slab.CGST
+		totalSGST += slab.SGST
+		totalIGST += slab.IGST
+
+		r := row.New(6).Add(
+			col.New(2).Add(text.New(formatGSTRate(slab.GSTPercent)+"%", bodyTextCenter)),
+			col.New(2).Add(text.New(FormatINR(slab.Taxable), bodyText)),
+		)
+		if intraState {
+			r.Add(
+				col.New(taxWidth).Add(text.New(FormatINR(slab.CGST), bodyText)),
+				col.New(taxWidth).Add(text.New(FormatINR(slab.SGST), bodyText)),
+			)
+		} else {
+			r.Add(col.New(taxWidth).Add(text.New(FormatINR(slab.IGST), bodyText)))
+		}
+		r.Add(col.New(2).Add(text.New(FormatINR(slab.TotalTax()), bodyText)))
+		m.AddRows(r)
+	}
+
+	total := row.New(6).Add(
+		col.New(2).Add(text.New("Total", totalText)).WithStyle(totalCell),
+		col.New(2).Add(text.New(FormatINR(totalTaxable), totalText)).WithStyle(totalCell),
+	)
+	if intraState {
+		total.Add(
+			col.New(taxWidth).Add(text.New(FormatINR(totalCGST), totalText)).WithStyle(totalCell),
+			col.New(taxWidth).Add(text.New(FormatINR(totalSGST), totalText)).WithStyle(totalCell),
+		)
+	} else {
+		total.Add(col.New(taxWidth).Add(text.New(FormatINR(totalIGST), totalText)).WithStyle(totalCell))
+	}
+	total.Add(col.New(2).Add(text.New(FormatINR(totalCGST+totalSGST+totalIGST), totalText)).WithStyle(totalCell))
+	m.AddRows(total)
+}
+
 // addPOAmountInWords adds the amount in words row.
 func addPOAmountInWords(m core.Maroto, data *POExportData) {
 	if data.AmountInWords == "" {
@@ -425,3 +425,37 @@ func TestPOExportPDF_NilAddresses(t *testing.T) {
 		t.Error("PDF bytes should not be empty even with nil addresses")
 	}
 }
+
+func TestBuildPOExportData_IntraStateTax(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Test Project")
+	// Vendor GSTIN 27 = Maharashtra, same state as the test bill-to address
+	vendor := testhelpers.CreateTestVendor(t, app, "Test Vendor")
+	po := testhelpers.CreateTestPurchaseOrder(t, app, project.Id, vendor.Id, "FSS-PO-009")
+	billTo := testhelpers.CreateTestAddress(t, app, project.Id, "bill_from", "Our Company")
+	po.Set("bill_to_address", billTo.Id)
+	if err := app.Save(po); err != nil {
+		t.Fatalf("failed to update PO: %v", err)
+	}
+	testhelpers.CreateTestPOLineItem(t, app, po.Id, 1, "Widget", 10, 1000.0, 18)
+
+	data, err := BuildPOExportData(app, po.Id)
+	if err != nil {
+		t.Fatalf("BuildPOExportData failed: %v", err)
+	}
+
+	if data.TaxType != TaxTypeCGSTSGST {
+		t.Errorf("TaxType = %q, want %q", data.TaxType, TaxTypeCGSTSGST)
+	}
+	if data.CGSTAmount != 900 || data.SGSTAmount != 900 || data.IGSTAmount != 0 {
+		t.Errorf("CGST/SGST/IGST = %.2f/%.2f/%.2f, want 900/900/0", data.CGSTAmount, data.SGSTAmount, data.IGSTAmount)
+	}
+	if data.GrandTotal != 11800 {
+		t.Errorf("GrandTotal = %.2f, want 11800.00", data.GrandTotal)
+	}
+
+	pdf, err := GeneratePOPDF(data)
+	if err != nil || len(pdf) == 0 {
+		t.Fatalf("GeneratePOPDF() = %d bytes, err %v", len(pdf), err)
+	}
+}
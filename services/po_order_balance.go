@@ -0,0 +1,72 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// OrderBalance compares the BOQ quantity of an item with what has been put
+// on purchase orders so far.
+type OrderBalance struct {
+	SourceItemType string
+	SourceItemID   string
+	Description    string
+	UOM            string
+	BOQQty         float64 // total project quantity of the BOQ item
+	Ordered        float64 // on purchase orders that are not cancelled
+}
+
+// Remaining returns the quantity still to be ordered.
+func (b OrderBalance) Remaining() float64 {
+	return b.BOQQty - b.Ordered
+}
+
+// OverOrderMessage describes a quantity that exceeds the remaining balance.
+func (b OrderBalance) OverOrderMessage(qty float64) string {
+	return fmt.Sprintf("%s: %s exceeds the %s still to order (BOQ quantity %s)",
+		b.Description, formatQty(qty), formatQty(b.Remaining()), formatQty(b.BOQQty))
+}
+
+// ComputeOrderBalances returns the ordered quantity of every BOQ item in the
+// project, keyed by DispatchItemKey. Line items on cancelled purchase orders
+// and manual line items are not counted. excludeLineItemID leaves out a line
+// item being edited so its own quantity is not counted against it.
+func ComputeOrderBalances(app core.App, projectID, excludeLineItemID string) (map[string]*OrderBalance, error) {
+	quantities, err := projectBOQQuantities(app, projectID)
+	if err != nil {
+		return nil, err
+	}
+	balances := make(map[string]*OrderBalance, len(quantities))
+	for key, q := range quantities {
+		balances[key] = &OrderBalance{
+			SourceItemType: q.SourceItemType,
+			SourceItemID:   q.SourceItemID,
+			Description:    q.Description,
+			UOM:            q.UOM,
+			BOQQty:         q.Qty,
+		}
+	}
+
+	lineItems, err := app.FindRecordsByFilter(
+		"po_line_items",
+		"purchase_order.project = {:pid} && purchase_order.status != 'cancelled' && source_item_type != 'manual'",
+		"", 0, 0,
+		map[string]any{"pid": projectID},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch PO line items: %w", err)
+	}
+	for _, li := range lineItems {
+		if li.Id == excludeLineItemID {
+			continue
+		}
+		b := balances[DispatchItemKey(li.GetString("source_item_type"), li.GetString("source_item_id"))]
+		if b == nil {
+			continue
+		}
+		b.Ordered += li.GetFloat("qty")
+	}
+
+	return balances, nil
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/testhelpers"
+)
+
+// orderTestLine puts qty of a BOQ item on the given PO.
+func orderTestLine(t *testing.T, app *pocketbase.PocketBase, poID, sourceType, sourceID string, qty float64) *core.Record {
+	t.Helper()
+	return saveTestRecord(t, app, "po_line_items", map[string]any{
+		"purchase_order": poID, "sort_order": 1, "description": "BOQ line", "qty": qty, "uom": "Nos", "rate": 100, "gst_percent": 18,
+		"source_item_type": sourceType, "source_item_id": sourceID,
+	})
+}
+
+func TestComputeOrderBalances(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Order Project")
+	vendor := testhelpers.CreateTestVendor(t, app, "Order Vendor")
+	boq := testhelpers.CreateTestBOQ(t, app, project.Id, "Order BOQ")
+	mainItem := testhelpers.CreateTestMainBOQItem(t, app, boq.Id, "Lighting")  // qty 10
+	subItem := testhelpers.CreateTestSubItem(t, app, mainItem.Id, "LED Panel") // 5 per unit
+	subSub := testhelpers.CreateTestSubSubItem(t, app, subItem.Id, "Driver")   // 2 per unit
+
+	draftPO := testhelpers.CreateTestPurchaseOrder(t, app, project.Id, vendor.Id, "PO-1")
+	sentPO := testhelpers.CreateTestPurchaseOrder(t, app, project.Id, vendor.Id, "PO-2")
+	sentPO.Set("status", "sent")
+	cancelledPO := testhelpers.CreateTestPurchaseOrder(t, app, project.Id, vendor.Id, "PO-3")
+	cancelledPO.Set("status", "cancelled")
+	for _, po := range []*core.Record{sentPO, cancelledPO} {
+		if err := app.Save(po); err != nil {
+			t.Fatalf("failed to update PO status: %v", err)
+		}
+	}
+
+	draftLine := orderTestLine(t, app, draftPO.Id, "sub_item", subItem.Id, 30)
+	orderTestLine(t, app, sentPO.Id, "sub_item", subItem.Id, 12)
+	orderTestLine(t, app, sentPO.Id, "sub_sub_item", subSub.Id, 20)
+	orderTestLine(t, app, sentPO.Id, "main_item", mainItem.Id, 4)
+	orderTestLine(t, app, cancelledPO.Id, "sub_item", subItem.Id, 40)
+	testhelpers.CreateTestPOLineItem(t, app, draftPO.Id, 2, "Freight", 99, 10, 18)
+
+	balances, err := ComputeOrderBalances(app, project.Id, "")
+	if err != nil {
+		t.Fatalf("ComputeOrderBalances failed: %v", err)
+	}
+
+	tests := []struct {
+		key       string
+		boqQty    float64
+		ordered   float64
+		remaining float64
+	}{
+		{DispatchItemKey("main_item", mainItem.Id), 10, 4, 6},
+		{DispatchItemKey("sub_item", subItem.Id), 50, 42, 8},
+		{DispatchItemKey("sub_sub_item", subSub.Id), 100, 20, 80},
+	}
+	for _, tt := range tests {
+		b := balances[tt.key]
+		if b == nil {
+			t.Fatalf("missing balance for %s", tt.key)
+		}
+		if b.BOQQty != tt.boqQty || b.Ordered != tt.ordered || b.Remaining() != tt.remaining {
+			t.Errorf("%s: expected %v/%v/%v, got %v/%v/%v", tt.key,
+				tt.boqQty, tt.ordered, tt.remaining, b.BOQQty, b.Ordered, b.Remaining())
+		}
+	}
+
+	// A line being edited is not counted against itself
+	balances, err = ComputeOrderBalances(app, project.Id, draftLine.Id)
+	if err != nil {
+		t.Fatalf("ComputeOrderBalances failed: %v", err)
+	}
+	if got := balances[DispatchItemKey("sub_item", subItem.Id)].Ordered; got != 12 {
+		t.Errorf("expected 12 ordered without the edited line, got %v", got)
+	}
+}
+
+func TestOrderBalance_OverOrderMessage(t *testing.T) {
+	b := OrderBalance{Description: "LED Panel", BOQQty: 50, Ordered: 42}
+	want := "LED Panel: 10 exceeds the 8 still to order (BOQ quantity 50)"
+	if got := b.OverOrderMessage(10); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
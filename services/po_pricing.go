@@ -2,6 +2,8 @@ package services
 
 import (
 	"math"
+	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -15,15 +17,69 @@ type POLineItemCalc struct {
 	Total      float64 // BeforeGST + GSTAmount
 }
 
+// POTaxSlab holds the taxable value and tax for all line items sharing one
+// GST rate.
+type POTaxSlab struct {
+	GSTPercent float64
+	Taxable    float64
+	CGST       float64
+	SGST       float64
+	IGST       float64
+}
+
+// TotalTax returns the total tax charged on the slab.
+func (s POTaxSlab) TotalTax() float64 {
+	return s.CGST + s.SGST + s.IGST
+}
+
 // POTotals holds the aggregated totals for a purchase order.
 type POTotals struct {
 	TotalBeforeTax float64
+	TaxType        string // TaxTypeCGSTSGST or TaxTypeIGST
 	IGSTPercent    float64
 	IGSTAmount     float64
+	CGSTAmount     float64
+	SGSTAmount     float64
+	TaxSlabs       []POTaxSlab // sorted by GST rate
 	RoundOff       float64
 	GrandTotal     float64
 }
 
+// POTaxLine is one tax line in a PO's totals, e.g. "CGST @ 9%".
+type POTaxLine struct {
+	Label  string
+	Amount float64
+}
+
+// TaxLines lists the tax lines shown under the total before tax: CGST and
+// SGST per GST slab for intra-state POs, otherwise IGST per slab.
+func (t POTotals) TaxLines() []POTaxLine {
+	if t.TaxType == TaxTypeCGSTSGST {
+		var lines []POTaxLine
+		for _, slab := range t.TaxSlabs {
+			half := formatGSTRate(slab.GSTPercent / 2)
+			lines = append(lines,
+				POTaxLine{Label: "CGST @ " + half + "%", Amount: slab.CGST},
+				POTaxLine{Label: "SGST @ " + half + "%", Amount: slab.SGST},
+			)
+		}
+		return lines
+	}
+	if len(t.TaxSlabs) == 0 {
+		return []POTaxLine{{Label: "IGST @ " + formatGSTRate(t.IGSTPercent) + "%", Amount: t.IGSTAmount}}
+	}
+	var lines []POTaxLine
+	for _, slab := range t.TaxSlabs {
+		lines = append(lines, POTaxLine{Label: "IGST @ " + formatGSTRate(slab.GSTPercent) + "%", Amount: slab.IGST})
+	}
+	return lines
+}
+
+// formatGSTRate formats a GST rate without trailing zeros (9, 2.5, 0.125).
+func formatGSTRate(rate float64) string {
+	return strconv.FormatFloat(rate, 'f', -1, 64)
+}
+
 // CalcPOLineItem calculates the totals for a single PO line item.
 func CalcPOLineItem(rate, qty, gstPercent float64) POLineItemCalc {
 	beforeGST := rate * qty
@@ -38,35 +94,67 @@ func CalcPOLineItem(rate, qty, gstPercent float64) POLineItemCalc {
 	}
 }
 
-// CalcPOTotals computes the aggregate totals for all line items in a PO.
-// It sums all line item totals, computes IGST based on the weighted average
-// GST percent, applies round-off to nearest rupee, and returns the grand total.
+// CalcPOTotals computes the aggregate totals for all line items in a PO,
+// charging the tax as IGST. See CalcPOTotalsForTaxType.
 func CalcPOTotals(items []POLineItemCalc) POTotals {
+	return CalcPOTotalsForTaxType(items, TaxTypeIGST)
+}
+
+// CalcPOTotalsForTaxType computes the aggregate totals for all line items in
+// a PO. The tax is grouped into slabs by GST rate; for TaxTypeCGSTSGST each
+// slab is split equally into CGST and SGST, otherwise it is charged as IGST
+// and IGSTPercent holds the weighted average rate. The subtotal is rounded
+// off to the nearest rupee to give the grand total.
+func CalcPOTotalsForTaxType(items []POLineItemCalc, taxType string) POTotals {
+	if taxType != TaxTypeCGSTSGST {
+		taxType = TaxTypeIGST
+	}
+
 	var totalBeforeTax float64
 	var totalGST float64
+	slabIndex := make(map[float64]int)
+	var slabs []POTaxSlab
 
 	for _, item := range items {
 		totalBeforeTax += item.BeforeGST
 		totalGST += item.GSTAmount
-	}
 
-	// Determine effective IGST percent from the items
-	var igstPercent float64
-	if totalBeforeTax > 0 {
-		igstPercent = (totalGST / totalBeforeTax) * 100
+		idx, ok := slabIndex[item.GSTPercent]
+		if !ok {
+			idx = len(slabs)
+			slabIndex[item.GSTPercent] = idx
+			slabs = append(slabs, POTaxSlab{GSTPercent: item.GSTPercent})
+		}
+		slabs[idx].Taxable += item.BeforeGST
+		if taxType == TaxTypeCGSTSGST {
+			slabs[idx].CGST += item.GSTAmount / 2
+			slabs[idx].SGST += item.GSTAmount / 2
+		} else {
+			slabs[idx].IGST += item.GSTAmount
+		}
 	}
+	sort.Slice(slabs, func(i, j int) bool { return slabs[i].GSTPercent < slabs[j].GSTPercent })
 
-	subtotal := totalBeforeTax + totalGST
-	roundOff := calcRoundOff(subtotal)
-	grandTotal := subtotal + roundOff
-
-	return POTotals{
+	totals := POTotals{
 		TotalBeforeTax: totalBeforeTax,
-		IGSTPercent:    igstPercent,
-		IGSTAmount:     totalGST,
-		RoundOff:       roundOff,
-		GrandTotal:     grandTotal,
+		TaxType:        taxType,
+		TaxSlabs:       slabs,
 	}
+	if taxType == TaxTypeCGSTSGST {
+		totals.CGSTAmount = totalGST / 2
+		totals.SGSTAmount = totalGST / 2
+	} else {
+		// Effective IGST percent across all rates
+		if totalBeforeTax > 0 {
+			totals.IGSTPercent = (totalGST / totalBeforeTax) * 100
+		}
+		totals.IGSTAmount = totalGST
+	}
+
+	subtotal := totalBeforeTax + totalGST
+	totals.RoundOff = calcRoundOff(subtotal)
+	totals.GrandTotal = subtotal + totals.RoundOff
+	return totals
 }
 
 // calcRoundOff rounds to nearest rupee with ±0.50 threshold.
@@ -2,6 +2,7 @@ package services
 
 import (
 	"math"
+	"strings"
 	"testing"
 )
 
@@ -54,6 +55,67 @@ func TestCalcPOTotals(t *testing.T) {
 	}
 }
 
+func TestCalcPOTotalsForTaxType_CGSTSGST(t *testing.T) {
+	items := []POLineItemCalc{
+		CalcPOLineItem(1000, 5, 18), // 5000 + 900
+		CalcPOLineItem(200, 10, 5),  // 2000 + 100
+		CalcPOLineItem(2000, 3, 18), // 6000 + 1080
+	}
+	got := CalcPOTotalsForTaxType(items, TaxTypeCGSTSGST)
+
+	if got.TaxType != TaxTypeCGSTSGST {
+		t.Errorf("TaxType = %q, want %q", got.TaxType, TaxTypeCGSTSGST)
+	}
+	if got.IGSTAmount != 0 {
+		t.Errorf("IGSTAmount = %f, want 0", got.IGSTAmount)
+	}
+	if !floatClose(got.CGSTAmount, 1040) || !floatClose(got.SGSTAmount, 1040) {
+		t.Errorf("CGST/SGST = %f/%f, want 1040/1040", got.CGSTAmount, got.SGSTAmount)
+	}
+	if !floatClose(got.GrandTotal, 15080) {
+		t.Errorf("GrandTotal = %f, want 15080", got.GrandTotal)
+	}
+
+	if len(got.TaxSlabs) != 2 {
+		t.Fatalf("TaxSlabs count = %d, want 2", len(got.TaxSlabs))
+	}
+	low, high := got.TaxSlabs[0], got.TaxSlabs[1]
+	if low.GSTPercent != 5 || !floatClose(low.Taxable, 2000) || !floatClose(low.CGST, 50) || !floatClose(low.SGST, 50) {
+		t.Errorf("5%% slab = %+v", low)
+	}
+	if high.GSTPercent != 18 || !floatClose(high.Taxable, 11000) || !floatClose(high.TotalTax(), 1980) {
+		t.Errorf("18%% slab = %+v", high)
+	}
+
+	var labels []string
+	for _, line := range got.TaxLines() {
+		labels = append(labels, line.Label)
+	}
+	want := []string{"CGST @ 2.5%", "SGST @ 2.5%", "CGST @ 9%", "SGST @ 9%"}
+	if strings.Join(labels, ",") != strings.Join(want, ",") {
+		t.Errorf("TaxLines labels = %v, want %v", labels, want)
+	}
+}
+
+func TestCalcPOTotalsForTaxType_IGSTSlabs(t *testing.T) {
+	items := []POLineItemCalc{
+		CalcPOLineItem(1000, 5, 18),
+		CalcPOLineItem(200, 10, 5),
+	}
+	got := CalcPOTotalsForTaxType(items, TaxTypeIGST)
+
+	if got.CGSTAmount != 0 || got.SGSTAmount != 0 {
+		t.Errorf("CGST/SGST = %f/%f, want 0/0", got.CGSTAmount, got.SGSTAmount)
+	}
+	if !floatClose(got.IGSTAmount, 1000) {
+		t.Errorf("IGSTAmount = %f, want 1000", got.IGSTAmount)
+	}
+	lines := got.TaxLines()
+	if len(lines) != 2 || lines[0].Label != "IGST @ 5%" || !floatClose(lines[1].Amount, 900) {
+		t.Errorf("TaxLines = %+v", lines)
+	}
+}
+
 func TestCalcPORoundOff(t *testing.T) {
 	tests := []struct {
 		name      string
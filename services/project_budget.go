@@ -0,0 +1,472 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// BudgetAlertThresholds are the shares of a category's budget, in percent,
+// at which a budget alert is raised.
+var BudgetAlertThresholds = []float64{80, 100}
+
+// Budget statuses of a cost category.
+const (
+	BudgetStatusOK      = "ok"
+	BudgetStatusWarning = "warning"
+	BudgetStatusOver    = "over"
+)
+
+// BudgetLine is a project's budget for one cost category and what has been
+// spent against it. Amounts are before GST.
+type BudgetLine struct {
+	CategoryID string
+	Code       string
+	Name       string
+	BudgetID   string // "" when the category has no budget yet
+	Budget     float64
+	Committed  float64 // on purchase orders that are not cancelled
+	Actual     float64 // received on GRNs, invoiced by vendors or paid as approved expenses
+	Spent      float64 // expected cost: committed, or actual where more was spent
+}
+
+// Variance returns what is left of the budget; negative when over budget.
+func (l BudgetLine) Variance() float64 {
+	return l.Budget - l.Spent
+}
+
+// UsedPercent returns the share of the budget spent, or 0 without a budget.
+func (l BudgetLine) UsedPercent() float64 {
+	if l.Budget == 0 {
+		return 0
+	}
+	return l.Spent / l.Budget * 100
+}
+
+// Status returns whether the line is within budget, past the first alert
+// threshold, or over budget. Spending without a budget counts as over.
+func (l BudgetLine) Status() string {
+	switch {
+	case l.Budget == 0 && l.Spent > 0, l.Budget > 0 && l.UsedPercent() >= 100:
+		return BudgetStatusOver
+	case l.Budget > 0 && l.UsedPercent() >= BudgetAlertThresholds[0]:
+		return BudgetStatusWarning
+	}
+	return BudgetStatusOK
+}
+
+// BudgetVariance is a project's budget against its costs, by cost category.
+type BudgetVariance struct {
+	ProjectID   string
+	ProjectName string
+	Lines       []BudgetLine
+	Total       BudgetLine
+}
+
+// BOQBudgetByCategory returns a project's BOQ budgeted value by cost
+// category code: product items as material and service items as labour.
+// Main items without sub items have no type and count as material.
+func BOQBudgetByCategory(app core.App, projectID string) (map[string]float64, error) {
+	params := map[string]any{"pid": projectID}
+	mainItems, err := app.FindRecordsByFilter("main_boq_items", "boq.project = {:pid}", "", 0, 0, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch BOQ items: %w", err)
+	}
+	subItems, err := app.FindRecordsByFilter("sub_items", "main_item.boq.project = {:pid}", "", 0, 0, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch BOQ sub items: %w", err)
+	}
+	subSubItems, err := app.FindRecordsByFilter("sub_sub_items", "sub_item.main_item.boq.project = {:pid}", "", 0, 0, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch BOQ sub sub items: %w", err)
+	}
+
+	subsByMain := make(map[string][]*core.Record)
+	for _, si := range subItems {
+		subsByMain[si.GetString("main_item")] = append(subsByMain[si.GetString("main_item")], si)
+	}
+	subSubsBySub := make(map[string][]*core.Record)
+	for _, ssi := range subSubItems {
+		subSubsBySub[ssi.GetString("sub_item")] = append(subSubsBySub[ssi.GetString("sub_item")], ssi)
+	}
+
+	// A main item's budgeted_price is its total; sub and sub sub items hold
+	// the budget per unit of the main item.
+	budget := make(map[string]float64)
+	for _, mi := range mainItems {
+		subs := subsByMain[mi.Id]
+		if len(subs) == 0 {
+			budget[CostCategoryMaterial] += mi.GetFloat("budgeted_price")
+			continue
+		}
+		qty := mi.GetFloat("qty")
+		for _, si := range subs {
+			subSubs := subSubsBySub[si.Id]
+			if len(subSubs) == 0 {
+				budget[costCategoryForItem(si.GetString("type"))] += si.GetFloat("budgeted_price") * qty
+				continue
+			}
+			for _, ssi := range subSubs {
+				budget[costCategoryForItem(ssi.GetString("type"))] += ssi.GetFloat("budgeted_price") * qty
+			}
+		}
+	}
+	return budget, nil
+}
+
+// SeedProjectBudgets creates a budget for every active cost category the
+// project has none for, from the BOQ budgeted value. With resetFromBOQ,
+// existing material and labour budgets are also set back to the BOQ value.
+func SeedProjectBudgets(ctx context.Context, app core.App, projectID string, resetFromBOQ bool) error {
+	categories, err := app.FindRecordsByFilter("cost_categories", "", "sort_order,created", 0, 0)
+	if err != nil {
+		return fmt.Errorf("failed to fetch cost categories: %w", err)
+	}
+	budgets, err := app.FindRecordsByFilter("project_budgets", "project = {:pid}", "", 0, 0, map[string]any{"pid": projectID})
+	if err != nil {
+		return fmt.Errorf("failed to fetch project budgets: %w", err)
+	}
+	existing := make(map[string]*core.Record, len(budgets))
+	for _, b := range budgets {
+		existing[b.GetString("cost_category")] = b
+	}
+	fromBOQ, err := BOQBudgetByCategory(app, projectID)
+	if err != nil {
+		return err
+	}
+
+	col, err := app.FindCollectionByNameOrId("project_budgets")
+	if err != nil {
+		return fmt.Errorf("project_budgets collection not found: %w", err)
+	}
+	return app.RunInTransaction(func(txApp core.App) error {
+		for _, cat := range categories {
+			code := cat.GetString("code")
+			seeded := code == CostCategoryMaterial || code == CostCategoryLabour
+			budget, ok := existing[cat.Id]
+			switch {
+			case !ok && cat.GetBool("active"):
+				budget = core.NewRecord(col)
+				budget.Set("project", projectID)
+				budget.Set("cost_category", cat.Id)
+				budget.Set("amount", fromBOQ[code])
+			case ok && resetFromBOQ && seeded:
+				budget.Set("amount", fromBOQ[code])
+			default:
+				continue
+			}
+			if err := txApp.SaveWithContext(ctx, budget); err != nil {
+				return fmt.Errorf("failed to save budget for %s: %w", code, err)
+			}
+		}
+		return nil
+	})
+}
+
+// BuildBudgetVariance compares a project's budgets with its costs by cost
+// category. PO costs are classified as in BuildProjectPnL and approved
+// expenses count under the category they were booked to. Inactive
+// categories are left out unless they have a budget or costs.
+func BuildBudgetVariance(app core.App, projectID string) (*BudgetVariance, error) {
+	pnl, err := BuildProjectPnL(app, projectID)
+	if err != nil {
+		return nil, err
+	}
+	spent := make(map[string]PnLLine, len(pnl.ByCategory))
+	for _, l := range pnl.ByCategory {
+		spent[l.Key] = l
+	}
+
+	categories, err := app.FindRecordsByFilter("cost_categories", "", "sort_order,created", 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch cost categories: %w", err)
+	}
+	budgets, err := app.FindRecordsByFilter("project_budgets", "project = {:pid}", "", 0, 0, map[string]any{"pid": projectID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch project budgets: %w", err)
+	}
+	budgetByCategory := make(map[string]*core.Record, len(budgets))
+	for _, b := range budgets {
+		budgetByCategory[b.GetString("cost_category")] = b
+	}
+
+	v := &BudgetVariance{ProjectID: pnl.ProjectID, ProjectName: pnl.ProjectName}
+	for _, cat := range categories {
+		code := cat.GetString("code")
+		line := BudgetLine{
+			CategoryID: cat.Id,
+			Code:       code,
+			Name:       cat.GetString("name"),
+			Committed:  spent[code].Committed,
+			Actual:     spent[code].Actual,
+			Spent:      spent[code].Cost,
+		}
+		if b, ok := budgetByCategory[cat.Id]; ok {
+			line.BudgetID = b.Id
+			line.Budget = b.GetFloat("amount")
+		}
+		if !cat.GetBool("active") && line.BudgetID == "" && line.Spent == 0 {
+			continue
+		}
+		v.Lines = append(v.Lines, line)
+		v.Total.Budget += line.Budget
+		v.Total.Committed += line.Committed
+		v.Total.Actual += line.Actual
+		v.Total.Spent += line.Spent
+	}
+	v.Total.Name = "Total"
+	return v, nil
+}
+
+// BudgetAlert is a budget alert raised for a cost category.
+type BudgetAlert struct {
+	ID           string
+	CategoryName string
+	Threshold    float64
+	Budget       float64
+	Spent        float64
+	Raised       string // when the threshold was crossed
+}
+
+// CheckBudgetAlerts raises an alert for every cost category whose spending
+// has reached an alert threshold of its budget. Each threshold is alerted
+// once per category; alerts already raised are left alone. It returns the
+// number of alerts raised.
+func CheckBudgetAlerts(app core.App, projectID string) (int, error) {
+	v, err := BuildBudgetVariance(app, projectID)
+	if err != nil {
+		return 0, err
+	}
+	alerts, err := app.FindRecordsByFilter("budget_alerts", "project = {:pid}", "", 0, 0, map[string]any{"pid": projectID})
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch budget alerts: %w", err)
+	}
+	raised := make(map[string]bool, len(alerts))
+	for _, a := range alerts {
+		raised[fmt.Sprintf("%s_%g", a.GetString("cost_category"), a.GetFloat("threshold"))] = true
+	}
+
+	col, err := app.FindCollectionByNameOrId("budget_alerts")
+	if err != nil {
+		return 0, fmt.Errorf("budget_alerts collection not found: %w", err)
+	}
+	count := 0
+	for _, line := range v.Lines {
+		if line.Budget <= 0 {
+			continue
+		}
+		for _, threshold := range BudgetAlertThresholds {
+			if line.UsedPercent() < threshold || raised[fmt.Sprintf("%s_%g", line.CategoryID, threshold)] {
+				continue
+			}
+			alert := core.NewRecord(col)
+			alert.Set("project", projectID)
+			alert.Set("cost_category", line.CategoryID)
+			alert.Set("threshold", threshold)
+			alert.Set("budget", line.Budget)
+			alert.Set("spent", line.Spent)
+			if err := app.Save(alert); err != nil {
+				return count, fmt.Errorf("failed to save budget alert for %s: %w", line.Code, err)
+			}
+			count++
+		}
+	}
+	return count, nil
+}
+
+// OpenBudgetAlerts returns a project's budget alerts that have not been
+// dismissed, newest first.
+func OpenBudgetAlerts(app core.App, projectID string) ([]BudgetAlert, error) {
+	records, err := app.FindRecordsByFilter("budget_alerts", "project = {:pid} && dismissed = false", "-created", 0, 0, map[string]any{"pid": projectID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch budget alerts: %w", err)
+	}
+	if errs := app.ExpandRecords(records, []string{"cost_category"}, nil); len(errs) > 0 {
+		return nil, fmt.Errorf("failed to expand budget alert categories: %v", errs)
+	}
+	alerts := make([]BudgetAlert, 0, len(records))
+	for _, r := range records {
+		alert := BudgetAlert{
+			ID:        r.Id,
+			Threshold: r.GetFloat("threshold"),
+			Budget:    r.GetFloat("budget"),
+			Spent:     r.GetFloat("spent"),
+			Raised:    r.GetDateTime("created").Time().Format("02 Jan 2006"),
+		}
+		if cat := r.ExpandedOne("cost_category"); cat != nil {
+			alert.CategoryName = cat.GetString("name")
+		}
+		alerts = append(alerts, alert)
+	}
+	return alerts, nil
+}
+
+// RefreshBOQActualPrices sets actual_price on every BOQ item of a project
+// to the actual cost of the PO lines raised against it. Main and sub items
+// include what was spent on the items under them. Like budgeted_price, a
+// main item holds its total while sub and sub sub items hold the cost per
+// unit of their main item; under a main item with no quantity they keep
+// the total.
+func RefreshBOQActualPrices(app core.App, projectID string) error {
+	quantities, err := projectBOQQuantities(app, projectID)
+	if err != nil {
+		return err
+	}
+	poCosts, err := projectPOCosts(app, projectID)
+	if err != nil {
+		return err
+	}
+
+	actual := make(map[string]float64, len(quantities))
+	for _, c := range poCosts {
+		for key := c.sourceKey; key != ""; {
+			q, ok := quantities[key]
+			if !ok {
+				break
+			}
+			actual[key] += c.actual()
+			key = q.ParentKey
+		}
+	}
+	for key, q := range quantities {
+		if q.SourceItemType == "main_item" {
+			continue
+		}
+		if main, ok := quantities[DispatchItemKey("main_item", q.MainItemID)]; ok && main.Qty > 0 {
+			actual[key] /= main.Qty
+		}
+	}
+
+	params := map[string]any{"pid": projectID}
+	for _, level := range []struct{ collection, sourceType, filter string }{
+		{"main_boq_items", "main_item", "boq.project = {:pid}"},
+		{"sub_items", "sub_item", "main_item.boq.project = {:pid}"},
+		{"sub_sub_items", "sub_sub_item", "sub_item.main_item.boq.project = {:pid}"},
+	} {
+		items, err := app.FindRecordsByFilter(level.collection, level.filter, "", 0, 0, params)
+		if err != nil {
+			return fmt.Errorf("failed to fetch %s: %w", level.collection, err)
+		}
+		for _, item := range items {
+			value := actual[DispatchItemKey(level.sourceType, item.Id)]
+			if item.GetFloat("actual_price") == value {
+				continue
+			}
+			item.Set("actual_price", value)
+			if err := app.Save(item); err != nil {
+				return fmt.Errorf("failed to save actual price of %s %s: %w", level.sourceType, item.Id, err)
+			}
+		}
+	}
+	return nil
+}
+
+// budgetProject returns the project a cost record belongs to, if any.
+func budgetProject(app core.App, rec *core.Record) string {
+	if p := rec.GetString("project"); p != "" {
+		return p
+	}
+	parents := map[string][2]string{
+		"po_line_items":             {"purchase_orders", "purchase_order"},
+		"grn_line_items":            {"grn", "grn"},
+		"vendor_invoice_line_items": {"vendor_invoices", "vendor_invoice"},
+	}
+	if parent, ok := parents[rec.Collection().Name]; ok {
+		if doc, err := app.FindRecordById(parent[0], rec.GetString(parent[1])); err == nil {
+			return doc.GetString("project")
+		}
+	}
+	return ""
+}
+
+// BudgetCostCollections lists the collections whose changes move a
+// project's costs or budgets.
+var BudgetCostCollections = []string{
+	"purchase_orders",
+	"po_line_items",
+	"grn_line_items",
+	"vendor_invoice_line_items",
+	"expenses",
+	"project_budgets",
+}
+
+// budgetRefreshKey is the context key of a deferred budget refresh.
+type budgetRefreshKey struct{}
+
+// deferredBudgetRefresh collects the projects whose costs or budgets changed
+// while it was in the context, and whether their BOQ actual prices need
+// refreshing as well as their alerts.
+type deferredBudgetRefresh struct {
+	mu       sync.Mutex
+	projects []string
+	prices   map[string]bool
+}
+
+// WithDeferredBudgetRefresh returns a context under which the budget hooks
+// only note the projects that changed, and a flush that then refreshes each
+// of them once. Saving an N-line document under it costs one refresh rather
+// than N. Saves under other contexts refresh straight away.
+func WithDeferredBudgetRefresh(ctx context.Context) (context.Context, func(app core.App)) {
+	d := &deferredBudgetRefresh{prices: make(map[string]bool)}
+	flush := func(app core.App) {
+		d.mu.Lock()
+		projects, prices := d.projects, d.prices
+		d.projects, d.prices = nil, make(map[string]bool)
+		d.mu.Unlock()
+		for _, projectID := range projects {
+			refreshProjectBudget(app, projectID, prices[projectID])
+		}
+	}
+	return context.WithValue(ctx, budgetRefreshKey{}, d), flush
+}
+
+// refreshProjectBudget refreshes the actual prices of a project's BOQ items
+// when prices is set, then raises any budget alerts it has crossed.
+func refreshProjectBudget(app core.App, projectID string, prices bool) {
+	if prices {
+		if err := RefreshBOQActualPrices(app, projectID); err != nil {
+			log.Printf("budget: could not refresh BOQ actual prices of project %s: %v", projectID, err)
+		}
+	}
+	if _, err := CheckBudgetAlerts(app, projectID); err != nil {
+		log.Printf("budget: could not check budget alerts of project %s: %v", projectID, err)
+	}
+}
+
+// RegisterBudgetHooks binds record hooks that, once a change to a project's
+// costs or budgets has been saved, refresh the actual prices of its BOQ
+// items and raise any budget alerts it has crossed. Under
+// WithDeferredBudgetRefresh the refresh waits for its flush.
+func RegisterBudgetHooks(app *pocketbase.PocketBase) {
+	refresh := func(e *core.RecordEvent) error {
+		if err := e.Next(); err != nil {
+			return err
+		}
+		projectID := budgetProject(e.App, e.Record)
+		if projectID == "" {
+			return nil
+		}
+		name := e.Record.Collection().Name
+		prices := name != "project_budgets" && name != "expenses"
+		if e.Context != nil {
+			if d, ok := e.Context.Value(budgetRefreshKey{}).(*deferredBudgetRefresh); ok {
+				d.mu.Lock()
+				if _, seen := d.prices[projectID]; !seen {
+					d.projects = append(d.projects, projectID)
+				}
+				d.prices[projectID] = d.prices[projectID] || prices
+				d.mu.Unlock()
+				return nil
+			}
+		}
+		refreshProjectBudget(e.App, projectID, prices)
+		return nil
+	}
+	app.OnRecordAfterCreateSuccess(BudgetCostCollections...).BindFunc(refresh)
+	app.OnRecordAfterUpdateSuccess(BudgetCostCollections...).BindFunc(refresh)
+	app.OnRecordAfterDeleteSuccess(BudgetCostCollections...).BindFunc(refresh)
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// GenerateBudgetVarianceExcel creates an Excel workbook of a project's
+// budget against its costs by cost category.
+func GenerateBudgetVarianceExcel(v *BudgetVariance) ([]byte, error) {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	sheetName := "Budget vs Actual"
+	defaultSheet := f.GetSheetName(0)
+	if err := f.SetSheetName(defaultSheet, sheetName); err != nil {
+		return nil, fmt.Errorf("set sheet name: %w", err)
+	}
+
+	columns := []string{"A", "B", "C", "D", "E", "F", "G"}
+	lastCol := columns[len(columns)-1]
+	widths := []float64{32, 16, 16, 16, 16, 16, 10}
+	for i, col := range columns {
+		if err := f.SetColWidth(sheetName, col, col, widths[i]); err != nil {
+			return nil, fmt.Errorf("set col width %s: %w", col, err)
+		}
+	}
+
+	// ── Styles ──────────────────────────────────────────────────────────
+
+	titleStyle, err := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true, Size: 16},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create title style: %w", err)
+	}
+	subtitleStyle, err := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Size: 11},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create subtitle style: %w", err)
+	}
+	headerStyle, err := f.NewStyle(&excelize.Style{
+		Font:      &excelize.Font{Bold: true, Color: "#FFFFFF", Size: 11},
+		Fill:      excelize.Fill{Type: "pattern", Color: []string{"#333333"}, Pattern: 1},
+		Alignment: &excelize.Alignment{Horizontal: "center", Vertical: "center"},
+		Border:    thinBorders(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create header style: %w", err)
+	}
+	textStyle, err := f.NewStyle(&excelize.Style{
+		Font:   &excelize.Font{Size: 10},
+		Border: thinBorders(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create text style: %w", err)
+	}
+	amountStyle, err := f.NewStyle(&excelize.Style{
+		Font:   &excelize.Font{Size: 10},
+		NumFmt: 4, // #,##0.00
+		Border: thinBorders(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create amount style: %w", err)
+	}
+	boldTextStyle, err := f.NewStyle(&excelize.Style{
+		Font:   &excelize.Font{Bold: true, Size: 10},
+		Border: thinBorders(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create bold text style: %w", err)
+	}
+	boldAmountStyle, err := f.NewStyle(&excelize.Style{
+		Font:   &excelize.Font{Bold: true, Size: 10},
+		NumFmt: 4,
+		Border: thinBorders(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create bold amount style: %w", err)
+	}
+
+	// ── Header Rows (1-2) ───────────────────────────────────────────────
+
+	if err := f.MergeCell(sheetName, "A1", lastCol+"1"); err != nil {
+		return nil, fmt.Errorf("merge title: %w", err)
+	}
+	f.SetCellValue(sheetName, "A1", sanitizeExcelCell("Budget vs Actual — "+v.ProjectName))
+	f.SetCellStyle(sheetName, "A1", lastCol+"1", titleStyle)
+
+	if err := f.MergeCell(sheetName, "A2", lastCol+"2"); err != nil {
+		return nil, fmt.Errorf("merge subtitle: %w", err)
+	}
+	f.SetCellValue(sheetName, "A2", "All amounts before GST")
+	f.SetCellStyle(sheetName, "A2", lastCol+"2", subtitleStyle)
+
+	// ── Variance table ──────────────────────────────────────────────────
+
+	row := 4
+	headers := []string{"Cost Category", "Budget", "Committed (PO)", "Actual", "Spent", "Variance", "Used %"}
+	for i, h := range headers {
+		f.SetCellValue(sheetName, cell(columns[i], row), h)
+	}
+	f.SetCellStyle(sheetName, cell("A", row), cell(lastCol, row), headerStyle)
+	row++
+
+	writeLine := func(l BudgetLine, bold bool) {
+		f.SetCellValue(sheetName, cell("A", row), sanitizeExcelCell(l.Name))
+		f.SetCellValue(sheetName, cell("B", row), l.Budget)
+		f.SetCellValue(sheetName, cell("C", row), l.Committed)
+		f.SetCellValue(sheetName, cell("D", row), l.Actual)
+		f.SetCellValue(sheetName, cell("E", row), l.Spent)
+		f.SetCellValue(sheetName, cell("F", row), l.Variance())
+		f.SetCellValue(sheetName, cell("G", row), l.UsedPercent())
+		ts, as := textStyle, amountStyle
+		if bold {
+			ts, as = boldTextStyle, boldAmountStyle
+		}
+		f.SetCellStyle(sheetName, cell("A", row), cell("A", row), ts)
+		f.SetCellStyle(sheetName, cell("B", row), cell(lastCol, row), as)
+		row++
+	}
+	for _, l := range v.Lines {
+		writeLine(l, false)
+	}
+	writeLine(v.Total, true)
+
+	// ── Write to buffer ─────────────────────────────────────────────────
+
+	var buf bytes.Buffer
+	if err := f.Write(&buf); err != nil {
+		return nil, fmt.Errorf("write excel: %w", err)
+	}
+	return buf.Bytes(), nil
+}
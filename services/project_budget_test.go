@@ -0,0 +1,208 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/collections"
+	"projectcreation/testhelpers"
+)
+
+// setupBudgetTest creates a project whose BOQ budgets 13,000 of material
+// and 5,000 of labour, with its budgets seeded, and a sent PO. It returns
+// the product and service sub items.
+func setupBudgetTest(t *testing.T) (app *pocketbase.PocketBase, project, po, product, service *core.Record) {
+	t.Helper()
+	app = testhelpers.NewTestApp(t)
+	project = testhelpers.CreateTestProject(t, app, "Budget Project")
+	boq := testhelpers.CreateTestBOQ(t, app, project.Id, "Budget BOQ")
+
+	// 10 units with 1,000 of product and 500 of service per unit
+	lighting := testhelpers.CreateTestMainBOQItem(t, app, boq.Id, "Street lighting")
+	product = testhelpers.CreateTestSubItem(t, app, lighting.Id, "Pole")
+	product.Set("budgeted_price", 1000)
+	service = testhelpers.CreateTestSubItem(t, app, lighting.Id, "Erection")
+	service.Set("type", "service")
+	service.Set("budgeted_price", 500)
+	// A main item without sub items, budgeted 3,000 in total
+	cabinet := testhelpers.CreateTestMainBOQItem(t, app, boq.Id, "Control cabinet")
+	cabinet.Set("budgeted_price", 3000)
+	for _, rec := range []*core.Record{product, service, cabinet} {
+		if err := app.Save(rec); err != nil {
+			t.Fatalf("failed to budget BOQ item: %v", err)
+		}
+	}
+
+	if _, err := collections.GetCostCategories(app); err != nil {
+		t.Fatalf("GetCostCategories failed: %v", err)
+	}
+	if err := SeedProjectBudgets(context.Background(), app, project.Id, false); err != nil {
+		t.Fatalf("SeedProjectBudgets failed: %v", err)
+	}
+
+	vendor := testhelpers.CreateTestVendor(t, app, "Budget Vendor")
+	po = testhelpers.CreateTestPurchaseOrder(t, app, project.Id, vendor.Id, "PO-1")
+	po.Set("status", "sent")
+	if err := app.Save(po); err != nil {
+		t.Fatalf("failed to update PO status: %v", err)
+	}
+	return app, project, po, product, service
+}
+
+// createBOQPOLine adds a PO line raised against a BOQ sub item.
+func createBOQPOLine(t *testing.T, app *pocketbase.PocketBase, poID string, item *core.Record, qty, rate float64) *core.Record {
+	t.Helper()
+	line := testhelpers.CreateTestPOLineItem(t, app, poID, 1, item.GetString("description"), qty, rate, 18)
+	line.Set("source_item_type", "sub_item")
+	line.Set("source_item_id", item.Id)
+	if err := app.Save(line); err != nil {
+		t.Fatalf("failed to link PO line: %v", err)
+	}
+	return line
+}
+
+func TestSeedProjectBudgets_FromBOQ(t *testing.T) {
+	app, project, _, _, _ := setupBudgetTest(t)
+
+	v, err := BuildBudgetVariance(app, project.Id)
+	if err != nil {
+		t.Fatalf("BuildBudgetVariance failed: %v", err)
+	}
+	budgets := make(map[string]float64)
+	for _, l := range v.Lines {
+		if l.BudgetID == "" {
+			t.Errorf("%s: expected a seeded budget", l.Code)
+		}
+		budgets[l.Code] = l.Budget
+	}
+	if budgets[CostCategoryMaterial] != 13000 || budgets[CostCategoryLabour] != 5000 || budgets[CostCategoryOther] != 0 {
+		t.Errorf("unexpected seeded budgets %v", budgets)
+	}
+
+	// Seeding again leaves edited budgets alone; a reset restores them
+	material, err := app.FindFirstRecordByFilter("project_budgets", "project = {:pid} && cost_category.code = 'material'", map[string]any{"pid": project.Id})
+	if err != nil {
+		t.Fatalf("material budget not found: %v", err)
+	}
+	material.Set("amount", 20000)
+	if err := app.Save(material); err != nil {
+		t.Fatalf("failed to edit budget: %v", err)
+	}
+	if err := SeedProjectBudgets(context.Background(), app, project.Id, false); err != nil {
+		t.Fatalf("SeedProjectBudgets failed: %v", err)
+	}
+	if material, _ = app.FindRecordById("project_budgets", material.Id); material.GetFloat("amount") != 20000 {
+		t.Errorf("expected the edited budget to be kept, got %.2f", material.GetFloat("amount"))
+	}
+	if err := SeedProjectBudgets(context.Background(), app, project.Id, true); err != nil {
+		t.Fatalf("SeedProjectBudgets reset failed: %v", err)
+	}
+	if material, _ = app.FindRecordById("project_budgets", material.Id); material.GetFloat("amount") != 13000 {
+		t.Errorf("expected the budget reset to 13,000, got %.2f", material.GetFloat("amount"))
+	}
+}
+
+func TestCheckBudgetAlerts_RaisesEachThresholdOnce(t *testing.T) {
+	app, project, po, product, service := setupBudgetTest(t)
+
+	// Material at 10,500 of 13,000 (80.8%), labour at 5,200 of 5,000 (104%)
+	createBOQPOLine(t, app, po.Id, product, 10, 1050)
+	createBOQPOLine(t, app, po.Id, service, 1, 5200)
+
+	v, err := BuildBudgetVariance(app, project.Id)
+	if err != nil {
+		t.Fatalf("BuildBudgetVariance failed: %v", err)
+	}
+	status := make(map[string]string)
+	for _, l := range v.Lines {
+		status[l.Code] = l.Status()
+	}
+	if status[CostCategoryMaterial] != BudgetStatusWarning || status[CostCategoryLabour] != BudgetStatusOver || status[CostCategoryOther] != BudgetStatusOK {
+		t.Errorf("unexpected statuses %v", status)
+	}
+	if v.Total.Spent != 15700 || v.Total.Variance() != 2300 {
+		t.Errorf("expected 15,700 spent and 2,300 left, got %+v", v.Total)
+	}
+
+	raised, err := CheckBudgetAlerts(app, project.Id)
+	if err != nil {
+		t.Fatalf("CheckBudgetAlerts failed: %v", err)
+	}
+	if raised != 3 {
+		t.Errorf("expected 3 alerts (material 80%%, labour 80%% and 100%%), got %d", raised)
+	}
+	if raised, _ = CheckBudgetAlerts(app, project.Id); raised != 0 {
+		t.Errorf("expected no new alerts on the second check, got %d", raised)
+	}
+
+	alerts, err := OpenBudgetAlerts(app, project.Id)
+	if err != nil {
+		t.Fatalf("OpenBudgetAlerts failed: %v", err)
+	}
+	if len(alerts) != 3 || alerts[0].CategoryName == "" {
+		t.Errorf("expected 3 open alerts with category names, got %+v", alerts)
+	}
+}
+
+func TestRefreshBOQActualPrices(t *testing.T) {
+	app, project, po, product, _ := setupBudgetTest(t)
+	line := createBOQPOLine(t, app, po.Id, product, 10, 1050)
+
+	if _, err := CreateGRN(context.Background(), app, GRNParams{
+		ProjectID:       project.Id,
+		PurchaseOrderID: po.Id,
+		ReceivedDate:    "2025-06-10",
+		Lines:           []GRNLineParams{{POLineItemID: line.Id, QtyReceived: 4}},
+	}); err != nil {
+		t.Fatalf("CreateGRN failed: %v", err)
+	}
+	if err := RefreshBOQActualPrices(app, project.Id); err != nil {
+		t.Fatalf("RefreshBOQActualPrices failed: %v", err)
+	}
+
+	sub, _ := app.FindRecordById("sub_items", product.Id)
+	main, _ := app.FindRecordById("main_boq_items", sub.GetString("main_item"))
+	// Like budgeted_price, the sub item holds the cost per unit of its main item
+	if sub.GetFloat("actual_price") != 420 || main.GetFloat("actual_price") != 4200 {
+		t.Errorf("expected 420 per unit on the sub item and 4,200 on its main item, got %.2f and %.2f",
+			sub.GetFloat("actual_price"), main.GetFloat("actual_price"))
+	}
+}
+
+func TestWithDeferredBudgetRefresh_RefreshesOnFlush(t *testing.T) {
+	app, project, po, product, service := setupBudgetTest(t)
+	RegisterBudgetHooks(app)
+	col, _ := app.FindCollectionByNameOrId("po_line_items")
+
+	// Material at 10,500 of 13,000 and labour at 5,200 of 5,000, saved line
+	// by line under one deferred refresh
+	ctx, flush := WithDeferredBudgetRefresh(context.Background())
+	for _, l := range []struct {
+		item      *core.Record
+		qty, rate float64
+	}{{product, 10, 1050}, {service, 1, 5200}} {
+		line := core.NewRecord(col)
+		line.Load(map[string]any{
+			"purchase_order": po.Id, "sort_order": 1, "description": l.item.GetString("description"), "qty": l.qty, "uom": "Nos",
+			"rate": l.rate, "gst_percent": 18, "source_item_type": "sub_item", "source_item_id": l.item.Id,
+		})
+		if err := app.SaveWithContext(ctx, line); err != nil {
+			t.Fatalf("failed to save PO line: %v", err)
+		}
+	}
+	if alerts, _ := OpenBudgetAlerts(app, project.Id); len(alerts) != 0 {
+		t.Fatalf("expected no alerts before the flush, got %d", len(alerts))
+	}
+
+	flush(app)
+	alerts, err := OpenBudgetAlerts(app, project.Id)
+	if err != nil {
+		t.Fatalf("OpenBudgetAlerts failed: %v", err)
+	}
+	if len(alerts) != 3 {
+		t.Errorf("expected 3 alerts after the flush, got %d", len(alerts))
+	}
+}
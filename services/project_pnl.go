@@ -0,0 +1,319 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// Cost categories a project's costs are broken down by. BOQ product items
+// are material and service items labour; PO lines not taken from the BOQ
+// are other costs.
+const (
+	CostCategoryMaterial = "material"
+	CostCategoryLabour   = "labour"
+	CostCategoryOther    = "other"
+)
+
+// CostCategories lists the cost categories in display order.
+var CostCategories = []string{CostCategoryMaterial, CostCategoryLabour, CostCategoryOther}
+
+var costCategoryLabels = map[string]string{
+	CostCategoryMaterial: "Material",
+	CostCategoryLabour:   "Labour & services",
+	CostCategoryOther:    "Other",
+}
+
+// CostCategoryLabel returns the display name of a cost category.
+func CostCategoryLabel(category string) string {
+	if label, ok := costCategoryLabels[category]; ok {
+		return label
+	}
+	return category
+}
+
+// costCategoryForItem returns the cost category of a BOQ item type. Main
+// items carry no type and are costed as material.
+func costCategoryForItem(itemType string) string {
+	if itemType == "service" {
+		return CostCategoryLabour
+	}
+	return CostCategoryMaterial
+}
+
+// PnLLine is one line of a project P&L. All amounts are before GST, which
+// is recovered as input credit rather than borne by the project.
+type PnLLine struct {
+	Key       string // main item ID or cost category; "" for unitemised
+	Label     string
+	Quoted    float64 // BOQ quoted value
+	Invoiced  float64 // billed to the client to date
+	Committed float64 // on purchase orders that are not cancelled
+	Actual    float64 // received on GRNs, invoiced by vendors or paid as approved expenses
+	Cost      float64 // expected cost: committed, or actual where more was spent
+}
+
+// Margin returns the gross margin of the line against its quoted value.
+func (l PnLLine) Margin() float64 {
+	return l.Quoted - l.Cost
+}
+
+// MarginPercent returns the gross margin as a percentage of the quoted
+// value, or 0 when nothing is quoted.
+func (l PnLLine) MarginPercent() float64 {
+	if l.Quoted == 0 {
+		return 0
+	}
+	return l.Margin() / l.Quoted * 100
+}
+
+// add accumulates other into l.
+func (l *PnLLine) add(other PnLLine) {
+	l.Quoted += other.Quoted
+	l.Invoiced += other.Invoiced
+	l.Committed += other.Committed
+	l.Actual += other.Actual
+	l.Cost += other.Cost
+}
+
+// ProjectPnL is a project's profit and loss: revenue from the BOQ and
+// client invoices against costs from purchase orders and what has been
+// received or invoiced on them.
+type ProjectPnL struct {
+	ProjectID   string
+	ProjectName string
+	ByMainItem  []PnLLine // in BOQ order, then unitemised amounts
+	ByCategory  []PnLLine // costs only, in CostCategories order, then other categories expenses were booked to
+	Total       PnLLine
+}
+
+// pnlCostLine is what one PO line has committed and cost so far.
+type pnlCostLine struct {
+	committed float64
+	received  float64 // accepted on GRNs, at the GRN rate
+	invoiced  float64 // on vendor invoices, at the invoiced rate
+}
+
+// actual returns the cost incurred on a PO line: the vendor's invoices, or
+// the goods accepted if they have not all been invoiced yet.
+func (c pnlCostLine) actual() float64 {
+	return math.Max(c.received, c.invoiced)
+}
+
+// cost returns the expected cost of a PO line.
+func (c pnlCostLine) cost() float64 {
+	return math.Max(c.committed, c.actual())
+}
+
+// pnlPOLine is a PO line of a project with what it has cost so far.
+type pnlPOLine struct {
+	pnlCostLine
+	purchaseOrder string
+	sourceKey     string // DispatchItemKey of the BOQ item; "" for manual lines
+	calc          POLineItemCalc
+}
+
+// projectPOCosts returns the lines of a project's purchase orders that are
+// not cancelled, with what has been accepted on GRNs and invoiced by vendors
+// against each.
+func projectPOCosts(app core.App, projectID string) ([]*pnlPOLine, error) {
+	params := map[string]any{"pid": projectID}
+	poLines, err := app.FindRecordsByFilter("po_line_items", "purchase_order.project = {:pid} && purchase_order.status != 'cancelled'", "", 0, 0, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch PO line items: %w", err)
+	}
+	lines := make([]*pnlPOLine, 0, len(poLines))
+	byID := make(map[string]*pnlPOLine, len(poLines))
+	for _, li := range poLines {
+		calc := CalcPOLineItem(li.GetFloat("rate"), li.GetFloat("qty"), li.GetFloat("gst_percent"))
+		line := &pnlPOLine{
+			pnlCostLine:   pnlCostLine{committed: calc.BeforeGST},
+			purchaseOrder: li.GetString("purchase_order"),
+			calc:          calc,
+		}
+		if sourceType := li.GetString("source_item_type"); sourceType != "" && sourceType != "manual" {
+			line.sourceKey = DispatchItemKey(sourceType, li.GetString("source_item_id"))
+		}
+		lines = append(lines, line)
+		byID[li.Id] = line
+	}
+
+	grnLines, err := app.FindRecordsByFilter("grn_line_items", "grn.project = {:pid}", "", 0, 0, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch GRN lines: %w", err)
+	}
+	for _, gl := range grnLines {
+		if c, ok := byID[gl.GetString("po_line_item")]; ok {
+			c.received += gl.GetFloat("qty_accepted") * gl.GetFloat("rate")
+		}
+	}
+	vendorLines, err := app.FindRecordsByFilter("vendor_invoice_line_items", "vendor_invoice.project = {:pid}", "", 0, 0, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch vendor invoice lines: %w", err)
+	}
+	for _, vl := range vendorLines {
+		if c, ok := byID[vl.GetString("po_line_item")]; ok {
+			c.invoiced += vl.GetFloat("qty") * vl.GetFloat("rate")
+		}
+	}
+	return lines, nil
+}
+
+// BuildProjectPnL builds the P&L of a project. Client invoice lines and PO
+// lines are attributed to the BOQ main item of the item they were raised
+// against; milestone invoices, manual PO lines and approved site expenses
+// fall under a separate unitemised line.
+func BuildProjectPnL(app core.App, projectID string) (*ProjectPnL, error) {
+	project, err := app.FindRecordById("projects", projectID)
+	if err != nil {
+		return nil, fmt.Errorf("project not found: %w", err)
+	}
+	pnl := &ProjectPnL{ProjectID: project.Id, ProjectName: project.GetString("name")}
+	params := map[string]any{"pid": projectID}
+
+	quantities, err := projectBOQQuantities(app, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Main item lines with their quoted value, in BOQ order
+	lineIndex := make(map[string]int)
+	boqs, err := app.FindRecordsByFilter("boqs", "project = {:pid}", "created", 0, 0, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch BOQs: %w", err)
+	}
+	for _, boq := range boqs {
+		mainItems, err := app.FindRecordsByFilter("main_boq_items", "boq = {:boqId}", "sort_order", 0, 0, map[string]any{"boqId": boq.Id})
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch main items of BOQ %s: %w", boq.Id, err)
+		}
+		for _, mi := range mainItems {
+			lineIndex[mi.Id] = len(pnl.ByMainItem)
+			pnl.ByMainItem = append(pnl.ByMainItem, PnLLine{
+				Key:    mi.Id,
+				Label:  mi.GetString("description"),
+				Quoted: CalcBOQTotals([]MainItemForTotals{{Qty: mi.GetFloat("qty"), QuotedPrice: mi.GetFloat("quoted_price")}}).TotalQuoted,
+			})
+		}
+	}
+	var unitemised PnLLine
+	lineFor := func(mainItemID string) *PnLLine {
+		if idx, ok := lineIndex[mainItemID]; ok {
+			return &pnl.ByMainItem[idx]
+		}
+		return &unitemised
+	}
+	mainItemOf := func(sourceType, sourceID string) string {
+		if q, ok := quantities[DispatchItemKey(sourceType, sourceID)]; ok {
+			return q.MainItemID
+		}
+		return ""
+	}
+
+	// Revenue: client invoices, itemised through the DC lines they bill
+	invoices, err := app.FindRecordsByFilter("client_invoices", "project = {:pid}", "", 0, 0, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch client invoices: %w", err)
+	}
+	for _, inv := range invoices {
+		if inv.GetString("billing_milestone") != "" {
+			unitemised.Invoiced += inv.GetFloat("taxable_amount")
+		}
+	}
+	invoiceLines, err := app.FindRecordsByFilter("client_invoice_line_items", "client_invoice.project = {:pid}", "", 0, 0, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch client invoice lines: %w", err)
+	}
+	if len(invoiceLines) > 0 {
+		dcLines, err := app.FindRecordsByFilter("dc_line_items", "dc.project = {:pid}", "", 0, 0, params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch DC lines: %w", err)
+		}
+		dcLineSource := make(map[string]*core.Record, len(dcLines))
+		for _, dl := range dcLines {
+			dcLineSource[dl.Id] = dl
+		}
+		for _, il := range invoiceLines {
+			var mainItemID string
+			if dl, ok := dcLineSource[il.GetString("dc_line_item")]; ok {
+				mainItemID = mainItemOf(dl.GetString("source_item_type"), dl.GetString("source_item_id"))
+			}
+			lineFor(mainItemID).Invoiced += il.GetFloat("taxable_amount")
+		}
+	}
+
+	// Costs: PO lines, with what has been received and invoiced against them
+	poCosts, err := projectPOCosts(app, projectID)
+	if err != nil {
+		return nil, err
+	}
+	categories := make(map[string]*PnLLine, len(CostCategories))
+	for _, cat := range CostCategories {
+		categories[cat] = &PnLLine{Key: cat, Label: CostCategoryLabel(cat)}
+	}
+	linesByPO := make(map[string][]POLineItemCalc)
+	for _, c := range poCosts {
+		linesByPO[c.purchaseOrder] = append(linesByPO[c.purchaseOrder], c.calc)
+		category := CostCategoryOther
+		var mainItemID string
+		if c.sourceKey != "" {
+			var itemType string
+			if q, ok := quantities[c.sourceKey]; ok {
+				mainItemID, itemType = q.MainItemID, q.ItemType
+			}
+			category = costCategoryForItem(itemType)
+		}
+		for _, l := range []*PnLLine{lineFor(mainItemID), categories[category]} {
+			l.Committed += c.committed
+			l.Actual += c.actual()
+			l.Cost += c.cost()
+		}
+	}
+
+	// Costs: approved site expenses, under the category they were booked to
+	expenses, err := app.FindRecordsByFilter("expenses", "project = {:pid} && status = 'approved'", "expense_date", 0, 0, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch expenses: %w", err)
+	}
+	if errs := app.ExpandRecords(expenses, []string{"cost_category"}, nil); len(errs) > 0 {
+		return nil, fmt.Errorf("failed to expand expense categories: %v", errs)
+	}
+	categoryOrder := slices.Clone(CostCategories)
+	for _, ex := range expenses {
+		category := CostCategoryOther
+		if cat := ex.ExpandedOne("cost_category"); cat != nil {
+			category = cat.GetString("code")
+			if _, ok := categories[category]; !ok {
+				categories[category] = &PnLLine{Key: category, Label: cat.GetString("name")}
+				categoryOrder = append(categoryOrder, category)
+			}
+		}
+		for _, l := range []*PnLLine{&unitemised, categories[category]} {
+			l.Actual += ex.GetFloat("amount")
+			l.Cost += ex.GetFloat("amount")
+		}
+	}
+
+	var committed float64
+	for _, calcs := range linesByPO {
+		committed += CalcPOTotals(calcs).TotalBeforeTax
+	}
+
+	if unitemised.Invoiced != 0 || unitemised.Cost != 0 {
+		unitemised.Label = "Not itemised (milestone invoices, manual PO lines, site expenses)"
+		pnl.ByMainItem = append(pnl.ByMainItem, unitemised)
+	}
+	for _, cat := range categoryOrder {
+		pnl.ByCategory = append(pnl.ByCategory, *categories[cat])
+	}
+	for _, line := range pnl.ByMainItem {
+		pnl.Total.add(line)
+	}
+	pnl.Total.Label = "Total"
+	// Take the committed total from the POs' own totals so the P&L agrees
+	// with the purchase orders
+	pnl.Total.Committed = committed
+	return pnl, nil
+}
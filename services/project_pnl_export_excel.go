@@ -0,0 +1,163 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// GenerateProjectPnLExcel creates an Excel workbook of a project P&L with
+// the main item breakdown followed by the cost category breakdown.
+func GenerateProjectPnLExcel(p *ProjectPnL) ([]byte, error) {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	sheetName := "P&L"
+	defaultSheet := f.GetSheetName(0)
+	if err := f.SetSheetName(defaultSheet, sheetName); err != nil {
+		return nil, fmt.Errorf("set sheet name: %w", err)
+	}
+
+	columns := []string{"A", "B", "C", "D", "E", "F", "G", "H"}
+	lastCol := columns[len(columns)-1]
+	widths := []float64{48, 16, 16, 16, 16, 16, 16, 12}
+	for i, col := range columns {
+		if err := f.SetColWidth(sheetName, col, col, widths[i]); err != nil {
+			return nil, fmt.Errorf("set col width %s: %w", col, err)
+		}
+	}
+
+	// ── Styles ──────────────────────────────────────────────────────────
+
+	titleStyle, err := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true, Size: 16},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create title style: %w", err)
+	}
+	subtitleStyle, err := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Size: 11},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create subtitle style: %w", err)
+	}
+	headerStyle, err := f.NewStyle(&excelize.Style{
+		Font:      &excelize.Font{Bold: true, Color: "#FFFFFF", Size: 11},
+		Fill:      excelize.Fill{Type: "pattern", Color: []string{"#333333"}, Pattern: 1},
+		Alignment: &excelize.Alignment{Horizontal: "center", Vertical: "center"},
+		Border:    thinBorders(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create header style: %w", err)
+	}
+	textStyle, err := f.NewStyle(&excelize.Style{
+		Font:   &excelize.Font{Size: 10},
+		Border: thinBorders(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create text style: %w", err)
+	}
+	amountStyle, err := f.NewStyle(&excelize.Style{
+		Font:   &excelize.Font{Size: 10},
+		NumFmt: 4, // #,##0.00
+		Border: thinBorders(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create amount style: %w", err)
+	}
+	boldTextStyle, err := f.NewStyle(&excelize.Style{
+		Font:   &excelize.Font{Bold: true, Size: 10},
+		Border: thinBorders(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create bold text style: %w", err)
+	}
+	boldAmountStyle, err := f.NewStyle(&excelize.Style{
+		Font:   &excelize.Font{Bold: true, Size: 10},
+		NumFmt: 4,
+		Border: thinBorders(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create bold amount style: %w", err)
+	}
+
+	// ── Header Rows (1-2) ───────────────────────────────────────────────
+
+	if err := f.MergeCell(sheetName, "A1", lastCol+"1"); err != nil {
+		return nil, fmt.Errorf("merge title: %w", err)
+	}
+	f.SetCellValue(sheetName, "A1", sanitizeExcelCell("Profit & Loss — "+p.ProjectName))
+	f.SetCellStyle(sheetName, "A1", lastCol+"1", titleStyle)
+
+	if err := f.MergeCell(sheetName, "A2", lastCol+"2"); err != nil {
+		return nil, fmt.Errorf("merge subtitle: %w", err)
+	}
+	f.SetCellValue(sheetName, "A2", "All amounts before GST")
+	f.SetCellStyle(sheetName, "A2", lastCol+"2", subtitleStyle)
+
+	// ── By main item ────────────────────────────────────────────────────
+
+	row := 4
+	headers := []string{"BOQ Item", "Quoted", "Invoiced", "Committed (PO)", "Actual", "Expected Cost", "Gross Margin", "Margin %"}
+	for i, h := range headers {
+		f.SetCellValue(sheetName, cell(columns[i], row), h)
+	}
+	f.SetCellStyle(sheetName, cell("A", row), cell(lastCol, row), headerStyle)
+	row++
+
+	writeLine := func(l PnLLine, bold bool) {
+		f.SetCellValue(sheetName, cell("A", row), sanitizeExcelCell(l.Label))
+		f.SetCellValue(sheetName, cell("B", row), l.Quoted)
+		f.SetCellValue(sheetName, cell("C", row), l.Invoiced)
+		f.SetCellValue(sheetName, cell("D", row), l.Committed)
+		f.SetCellValue(sheetName, cell("E", row), l.Actual)
+		f.SetCellValue(sheetName, cell("F", row), l.Cost)
+		f.SetCellValue(sheetName, cell("G", row), l.Margin())
+		f.SetCellValue(sheetName, cell("H", row), l.MarginPercent())
+		ts, as := textStyle, amountStyle
+		if bold {
+			ts, as = boldTextStyle, boldAmountStyle
+		}
+		f.SetCellStyle(sheetName, cell("A", row), cell("A", row), ts)
+		f.SetCellStyle(sheetName, cell("B", row), cell(lastCol, row), as)
+		row++
+	}
+	for _, l := range p.ByMainItem {
+		writeLine(l, false)
+	}
+	writeLine(p.Total, true)
+
+	// ── By cost category ────────────────────────────────────────────────
+
+	row += 2
+	headers = []string{"Cost Category", "Committed (PO)", "Actual", "Expected Cost", "Share of Cost %"}
+	for i, h := range headers {
+		f.SetCellValue(sheetName, cell(columns[i], row), h)
+	}
+	f.SetCellStyle(sheetName, cell("A", row), cell("E", row), headerStyle)
+	row++
+
+	for _, l := range p.ByCategory {
+		share := 0.0
+		if p.Total.Cost != 0 {
+			share = l.Cost / p.Total.Cost * 100
+		}
+		f.SetCellValue(sheetName, cell("A", row), sanitizeExcelCell(l.Label))
+		f.SetCellValue(sheetName, cell("B", row), l.Committed)
+		f.SetCellValue(sheetName, cell("C", row), l.Actual)
+		f.SetCellValue(sheetName, cell("D", row), l.Cost)
+		f.SetCellValue(sheetName, cell("E", row), share)
+		f.SetCellStyle(sheetName, cell("A", row), cell("A", row), textStyle)
+		f.SetCellStyle(sheetName, cell("B", row), cell("E", row), amountStyle)
+		row++
+	}
+
+	// ── Write to buffer ─────────────────────────────────────────────────
+
+	var buf bytes.Buffer
+	if err := f.Write(&buf); err != nil {
+		return nil, fmt.Errorf("write excel: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/johnfercher/maroto/v2"
+	"github.com/johnfercher/maroto/v2/pkg/components/col"
+	"github.com/johnfercher/maroto/v2/pkg/components/row"
+	"github.com/johnfercher/maroto/v2/pkg/components/text"
+	"github.com/johnfercher/maroto/v2/pkg/config"
+	"github.com/johnfercher/maroto/v2/pkg/consts/align"
+	"github.com/johnfercher/maroto/v2/pkg/consts/fontstyle"
+	"github.com/johnfercher/maroto/v2/pkg/consts/orientation"
+	"github.com/johnfercher/maroto/v2/pkg/consts/pagesize"
+	"github.com/johnfercher/maroto/v2/pkg/core"
+	"github.com/johnfercher/maroto/v2/pkg/props"
+)
+
+// GenerateProjectPnLPDF creates a landscape PDF of a project P&L using
+// maroto/v2. It returns the raw PDF bytes or an error.
+func GenerateProjectPnLPDF(p *ProjectPnL, companyName string) ([]byte, error) {
+	cfg := config.NewBuilder().
+		WithOrientation(orientation.Horizontal).
+		WithPageSize(pagesize.A4).
+		WithLeftMargin(10).
+		WithTopMargin(10).
+		WithRightMargin(10).
+		WithPageNumber(props.PageNumber{
+			Pattern: "Page {current} of {total}",
+			Place:   props.RightBottom,
+			Size:    7,
+			Color:   &props.Color{Red: 120, Green: 120, Blue: 120},
+		}).
+		Build()
+
+	m := maroto.New(cfg)
+
+	addPnLHeader(m, p, companyName)
+	addPnLMainItemTable(m, p)
+	addPnLCategoryTable(m, p)
+
+	doc, err := m.Generate()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate P&L PDF: %w", err)
+	}
+
+	return doc.GetBytes(), nil
+}
+
+// addPnLHeader adds the company name, title and project.
+func addPnLHeader(m core.Maroto, p *ProjectPnL, companyName string) {
+	m.AddRows(
+		row.New(10).Add(
+			col.New(6).Add(text.New(companyName, props.Text{
+				Size:  14,
+				Style: fontstyle.Bold,
+				Align: align.Left,
+			})),
+			col.New(6).Add(text.New("PROFIT & LOSS", props.Text{
+				Size:  14,
+				Style: fontstyle.Bold,
+				Align: align.Right,
+				Color: &props.Color{Red: 33, Green: 37, Blue: 41},
+			})),
+		),
+		row.New(7).Add(
+			col.New(6).Add(text.New(fmt.Sprintf("Project: %s", p.ProjectName), props.Text{
+				Size:  10,
+				Style: fontstyle.Bold,
+				Align: align.Left,
+			})),
+			col.New(6).Add(text.New("All amounts before GST", props.Text{
+				Size:  8,
+				Align: align.Right,
+				Color: &props.Color{Red: 100, Green: 100, Blue: 100},
+			})),
+		),
+	)
+	m.AddRows(row.New(4))
+}
+
+// pnlHeaderRow returns a dark table header row with the given column sizes.
+func pnlHeaderRow(sizes []int, labels []string) core.Row {
+	headerBg := &props.Color{Red: 33, Green: 37, Blue: 41}
+	headerText := props.Text{
+		Size:  7,
+		Style: fontstyle.Bold,
+		Align: align.Right,
+		Color: &props.Color{Red: 255, Green: 255, Blue: 255},
+	}
+	headerTextLeft := headerText
+	headerTextLeft.Align = align.Left
+	headerCell := props.Cell{BackgroundColor: headerBg}
+
+	cols := make([]core.Col, len(labels))
+	for i, label := range labels {
+		style := headerText
+		if i == 0 {
+			style = headerTextLeft
+		}
+		cols[i] = col.New(sizes[i]).Add(text.New(label, style)).WithStyle(&headerCell)
+	}
+	return row.New(8).Add(cols...)
+}
+
+// pnlBodyRow returns a table row of a label followed by right-aligned values.
+func pnlBodyRow(sizes []int, values []string, bold, shaded bool) core.Row {
+	bodyText := props.Text{Size: 7, Align: align.Right}
+	bodyTextLeft := props.Text{Size: 7, Align: align.Left}
+	if bold {
+		bodyText.Style = fontstyle.Bold
+		bodyTextLeft.Style = fontstyle.Bold
+	}
+
+	cols := make([]core.Col, len(values))
+	for i, v := range values {
+		style := bodyText
+		if i == 0 {
+			style = bodyTextLeft
+		}
+		cols[i] = col.New(sizes[i]).Add(text.New(v, style))
+		if shaded {
+			cols[i] = cols[i].WithStyle(&props.Cell{BackgroundColor: &props.Color{Red: 248, Green: 249, Blue: 250}})
+		}
+	}
+	return row.New(7).Add(cols...)
+}
+
+// addPnLMainItemTable adds the P&L by BOQ main item with a total row.
+func addPnLMainItemTable(m core.Maroto, p *ProjectPnL) {
+	sizes := []int{3, 1, 1, 2, 1, 1, 2, 1}
+	m.AddRows(pnlHeaderRow(sizes, []string{"BOQ Item", "Quoted", "Invoiced", "Committed (PO)", "Actual", "Exp. Cost", "Gross Margin", "Margin %"}))
+
+	values := func(l PnLLine) []string {
+		return []string{
+			l.Label,
+			FormatINR(l.Quoted),
+			FormatINR(l.Invoiced),
+			FormatINR(l.Committed),
+			FormatINR(l.Actual),
+			FormatINR(l.Cost),
+			FormatINR(l.Margin()),
+			fmt.Sprintf("%.1f%%", l.MarginPercent()),
+		}
+	}
+	for i, l := range p.ByMainItem {
+		m.AddRows(pnlBodyRow(sizes, values(l), false, i%2 == 1))
+	}
+	m.AddRows(pnlBodyRow(sizes, values(p.Total), true, false))
+	m.AddRows(row.New(8))
+}
+
+// addPnLCategoryTable adds costs by category with each category's share of
+// the expected cost.
+func addPnLCategoryTable(m core.Maroto, p *ProjectPnL) {
+	sizes := []int{4, 2, 2, 2, 2}
+	m.AddRows(pnlHeaderRow(sizes, []string{"Cost Category", "Committed (PO)", "Actual", "Expected Cost", "Share of Cost"}))
+
+	for i, l := range p.ByCategory {
+		share := 0.0
+		if p.Total.Cost != 0 {
+			share = l.Cost / p.Total.Cost * 100
+		}
+		m.AddRows(pnlBodyRow(sizes, []string{
+			l.Label,
+			FormatINR(l.Committed),
+			FormatINR(l.Actual),
+			FormatINR(l.Cost),
+			fmt.Sprintf("%.1f%%", share),
+		}, false, i%2 == 1))
+	}
+}
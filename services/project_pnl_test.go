@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"projectcreation/testhelpers"
+)
+
+func TestBuildProjectPnL(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	ctx := context.Background()
+	project := testhelpers.CreateTestProject(t, app, "PnL Project")
+	boq := testhelpers.CreateTestBOQ(t, app, project.Id, "PnL BOQ")
+	mainItem := testhelpers.CreateTestMainBOQItem(t, app, boq.Id, "Street lighting")
+	mainItem.Set("quoted_price", 10000)
+	if err := app.Save(mainItem); err != nil {
+		t.Fatalf("failed to price main item: %v", err)
+	}
+	cable := testhelpers.CreateTestSubItem(t, app, mainItem.Id, "Cable")
+	install := testhelpers.CreateTestSubItem(t, app, mainItem.Id, "Installation")
+	install.Set("type", "service")
+	if err := app.Save(install); err != nil {
+		t.Fatalf("failed to update sub item type: %v", err)
+	}
+
+	vendor := testhelpers.CreateTestVendor(t, app, "PnL Vendor")
+	po := testhelpers.CreateTestPurchaseOrder(t, app, project.Id, vendor.Id, "PO-1")
+	po.Set("status", "sent")
+	if err := app.Save(po); err != nil {
+		t.Fatalf("failed to update PO status: %v", err)
+	}
+	cableLine := testhelpers.CreateTestPOLineItem(t, app, po.Id, 1, "Cable", 50, 200, 18)
+	installLine := testhelpers.CreateTestPOLineItem(t, app, po.Id, 2, "Installation", 1, 20000, 18)
+	testhelpers.CreateTestPOLineItem(t, app, po.Id, 3, "Freight", 1, 5000, 18)
+	for line, item := range map[string]string{cableLine.Id: cable.Id, installLine.Id: install.Id} {
+		rec, _ := app.FindRecordById("po_line_items", line)
+		rec.Set("source_item_type", "sub_item")
+		rec.Set("source_item_id", item)
+		if err := app.Save(rec); err != nil {
+			t.Fatalf("failed to link PO line: %v", err)
+		}
+	}
+
+	cancelled := testhelpers.CreateTestPurchaseOrder(t, app, project.Id, vendor.Id, "PO-2")
+	cancelled.Set("status", "cancelled")
+	if err := app.Save(cancelled); err != nil {
+		t.Fatalf("failed to cancel PO: %v", err)
+	}
+	testhelpers.CreateTestPOLineItem(t, app, cancelled.Id, 1, "Cancelled", 1, 99999, 18)
+
+	if _, err := CreateGRN(ctx, app, GRNParams{
+		ProjectID:       project.Id,
+		PurchaseOrderID: po.Id,
+		ReceivedDate:    "2025-06-10",
+		Lines:           []GRNLineParams{{POLineItemID: cableLine.Id, QtyReceived: 50}},
+	}); err != nil {
+		t.Fatalf("CreateGRN failed: %v", err)
+	}
+	// Installation billed at 22,000 against 20,000 ordered
+	if _, err := CreateVendorInvoice(ctx, app, VendorInvoiceParams{
+		ProjectID:       project.Id,
+		PurchaseOrderID: po.Id,
+		InvoiceNumber:   "INV-1",
+		InvoiceDate:     "2025-06-12",
+		VendorGSTIN:     testVendorGSTIN,
+		Lines:           []InvoiceLineParams{{POLineItemID: installLine.Id, Qty: 1, Rate: 22000, GSTPercent: 18}},
+	}); err != nil {
+		t.Fatalf("CreateVendorInvoice failed: %v", err)
+	}
+
+	pnl, err := BuildProjectPnL(app, project.Id)
+	if err != nil {
+		t.Fatalf("BuildProjectPnL failed: %v", err)
+	}
+	if len(pnl.ByMainItem) != 2 {
+		t.Fatalf("expected the main item and an unitemised line, got %+v", pnl.ByMainItem)
+	}
+	main := pnl.ByMainItem[0]
+	if main.Quoted != 100000 || main.Committed != 30000 || main.Actual != 32000 || main.Cost != 32000 {
+		t.Errorf("unexpected main item line %+v", main)
+	}
+	if main.Margin() != 68000 || main.MarginPercent() != 68 {
+		t.Errorf("expected a margin of 68,000 (68%%), got %.2f (%.2f%%)", main.Margin(), main.MarginPercent())
+	}
+	if other := pnl.ByMainItem[1]; other.Key != "" || other.Committed != 5000 || other.Cost != 5000 {
+		t.Errorf("unexpected unitemised line %+v", other)
+	}
+	if pnl.Total.Committed != 35000 || pnl.Total.Cost != 37000 || pnl.Total.Margin() != 63000 {
+		t.Errorf("unexpected total %+v", pnl.Total)
+	}
+
+	want := map[string][3]float64{
+		CostCategoryMaterial: {10000, 10000, 10000},
+		CostCategoryLabour:   {20000, 22000, 22000},
+		CostCategoryOther:    {5000, 0, 5000},
+	}
+	for _, l := range pnl.ByCategory {
+		if got := [3]float64{l.Committed, l.Actual, l.Cost}; got != want[l.Key] {
+			t.Errorf("%s: expected committed/actual/cost %v, got %v", l.Key, want[l.Key], got)
+		}
+	}
+
+	if data, err := GenerateProjectPnLExcel(pnl); err != nil || len(data) == 0 {
+		t.Errorf("GenerateProjectPnLExcel failed: %v", err)
+	}
+	if data, err := GenerateProjectPnLPDF(pnl, "Test Co"); err != nil || len(data) == 0 {
+		t.Errorf("GenerateProjectPnLPDF failed: %v", err)
+	}
+}
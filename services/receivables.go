@@ -0,0 +1,319 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// ClientInvoiceBalance is a client invoice's value and how much of it the
+// client has paid so far.
+type ClientInvoiceBalance struct {
+	InvoiceID     string
+	InvoiceNumber string
+	InvoiceDate   string
+	ProjectID     string
+	ProjectName   string
+	BuyerName     string
+	Total         float64 // grand total including GST
+	Retention     float64 // held back by the client until handover
+	Received      float64 // allocated on client receipts
+}
+
+// Outstanding returns the amount of the invoice still to be received,
+// retention included.
+func (b ClientInvoiceBalance) Outstanding() float64 {
+	return b.Total - b.Received
+}
+
+// RetentionHeld returns the part of the outstanding amount that is
+// retention. Receipts settle the amount due first, so retention is the last
+// part of an invoice to be received.
+func (b ClientInvoiceBalance) RetentionHeld() float64 {
+	return math.Max(0, math.Min(b.Retention, b.Outstanding()))
+}
+
+// Due returns the outstanding amount the client owes now, excluding
+// retention still held.
+func (b ClientInvoiceBalance) Due() float64 {
+	return b.Outstanding() - b.RetentionHeld()
+}
+
+// ClientInvoiceBalances returns the client invoices of a project, oldest
+// first, with the amount received against each. An empty projectID returns
+// the invoices of every project.
+func ClientInvoiceBalances(app core.App, projectID string) ([]ClientInvoiceBalance, error) {
+	filter, allocFilter := "project != ''", "client_invoice != ''"
+	params := map[string]any{}
+	if projectID != "" {
+		filter, allocFilter = "project = {:pid}", "client_invoice.project = {:pid}"
+		params["pid"] = projectID
+	}
+	invoices, err := app.FindRecordsByFilter("client_invoices", filter, "invoice_date,created", 0, 0, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch client invoices: %w", err)
+	}
+	allocs, err := app.FindRecordsByFilter("client_receipt_allocations", allocFilter, "", 0, 0, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch receipt allocations: %w", err)
+	}
+	received := make(map[string]float64, len(allocs))
+	for _, a := range allocs {
+		received[a.GetString("client_invoice")] += a.GetFloat("amount")
+	}
+
+	projectNames := make(map[string]string)
+	buyerNames := make(map[string]string)
+	balances := make([]ClientInvoiceBalance, 0, len(invoices))
+	for _, inv := range invoices {
+		b := ClientInvoiceBalance{
+			InvoiceID:     inv.Id,
+			InvoiceNumber: inv.GetString("invoice_number"),
+			InvoiceDate:   inv.GetString("invoice_date"),
+			ProjectID:     inv.GetString("project"),
+			Total:         inv.GetFloat("total_amount"),
+			Retention:     inv.GetFloat("retention_amount"),
+			Received:      received[inv.Id],
+		}
+		if name, ok := projectNames[b.ProjectID]; ok {
+			b.ProjectName = name
+		} else if p, err := app.FindRecordById("projects", b.ProjectID); err == nil {
+			b.ProjectName = p.GetString("name")
+			projectNames[b.ProjectID] = b.ProjectName
+		}
+		buyerID := inv.GetString("bill_to_address")
+		if name, ok := buyerNames[buyerID]; ok {
+			b.BuyerName = name
+		} else if addr, err := app.FindRecordById("addresses", buyerID); err == nil {
+			data := ReadAddressData(addr)
+			b.BuyerName = data["company_name"]
+			if b.BuyerName == "" {
+				b.BuyerName = data["contact_person"]
+			}
+			buyerNames[buyerID] = b.BuyerName
+		}
+		balances = append(balances, b)
+	}
+	return balances, nil
+}
+
+// ReceiptAllocationParams settles part of one client invoice.
+type ReceiptAllocationParams struct {
+	ClientInvoiceID string
+	Amount          float64
+}
+
+// ClientReceiptParams holds the inputs for a client receipt.
+type ClientReceiptParams struct {
+	ProjectID   string
+	ReceiptDate string
+	Mode        string
+	Reference   string
+	Remarks     string
+	Allocations []ReceiptAllocationParams
+}
+
+// ClientReceiptResult holds the output of CreateClientReceipt.
+type ClientReceiptResult struct {
+	ReceiptID string
+	Amount    float64
+}
+
+// ValidateClientReceipt checks a receipt before it is recorded. It returns
+// one message per problem, keyed by form field or client invoice ID (""
+// for problems with the receipt as a whole).
+func ValidateClientReceipt(params ClientReceiptParams, balances map[string]ClientInvoiceBalance) map[string]string {
+	errs := make(map[string]string)
+
+	if params.ReceiptDate == "" {
+		errs["receipt_date"] = "Receipt date is required"
+	}
+	if !slices.Contains(PaymentModes, params.Mode) {
+		errs["mode"] = "Choose a payment mode"
+	} else if params.Mode != "cash" && strings.TrimSpace(params.Reference) == "" {
+		errs["reference"] = "UTR / cheque reference is required"
+	}
+
+	allocated := false
+	for _, a := range params.Allocations {
+		b, ok := balances[a.ClientInvoiceID]
+		switch {
+		case !ok:
+			errs[a.ClientInvoiceID] = "Invoice does not belong to this project"
+		case a.Amount < 0:
+			errs[a.ClientInvoiceID] = fmt.Sprintf("%s: amount cannot be negative", b.InvoiceNumber)
+		case a.Amount > b.Outstanding()+0.005:
+			errs[a.ClientInvoiceID] = fmt.Sprintf("%s: only %s is outstanding", b.InvoiceNumber, FormatINR(b.Outstanding()))
+		}
+		if a.Amount > 0 {
+			allocated = true
+		}
+	}
+	if !allocated {
+		errs[""] = "Allocate the receipt to at least one invoice"
+	}
+	return errs
+}
+
+// CreateClientReceipt records money received from the client, allocated
+// across the project's invoices. Allocations of zero are skipped.
+func CreateClientReceipt(ctx context.Context, app *pocketbase.PocketBase, params ClientReceiptParams) (*ClientReceiptResult, error) {
+	list, err := ClientInvoiceBalances(app, params.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	balances := make(map[string]ClientInvoiceBalance, len(list))
+	for _, b := range list {
+		balances[b.InvoiceID] = b
+	}
+
+	if errs := ValidateClientReceipt(params, balances); len(errs) > 0 {
+		msgs := make([]string, 0, len(errs))
+		for _, m := range errs {
+			msgs = append(msgs, m)
+		}
+		return nil, fmt.Errorf("invalid client receipt: %s", strings.Join(msgs, "; "))
+	}
+
+	var allocs []ReceiptAllocationParams
+	result := &ClientReceiptResult{}
+	for _, a := range params.Allocations {
+		if a.Amount <= 0 {
+			continue
+		}
+		allocs = append(allocs, a)
+		result.Amount += a.Amount
+	}
+
+	receiptCol, err := app.FindCollectionByNameOrId("client_receipts")
+	if err != nil {
+		return nil, fmt.Errorf("client_receipts collection not found: %w", err)
+	}
+	allocCol, err := app.FindCollectionByNameOrId("client_receipt_allocations")
+	if err != nil {
+		return nil, fmt.Errorf("client_receipt_allocations collection not found: %w", err)
+	}
+
+	err = app.RunInTransaction(func(txApp core.App) error {
+		receipt := core.NewRecord(receiptCol)
+		receipt.Set("project", params.ProjectID)
+		receipt.Set("receipt_date", params.ReceiptDate)
+		receipt.Set("mode", params.Mode)
+		receipt.Set("reference", strings.TrimSpace(params.Reference))
+		receipt.Set("amount", result.Amount)
+		receipt.Set("remarks", params.Remarks)
+		if err := txApp.SaveWithContext(ctx, receipt); err != nil {
+			return fmt.Errorf("failed to create client receipt: %w", err)
+		}
+		result.ReceiptID = receipt.Id
+
+		for _, a := range allocs {
+			rec := core.NewRecord(allocCol)
+			rec.Set("client_receipt", receipt.Id)
+			rec.Set("client_invoice", a.ClientInvoiceID)
+			rec.Set("amount", a.Amount)
+			if err := txApp.SaveWithContext(ctx, rec); err != nil {
+				return fmt.Errorf("failed to create receipt allocation: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// AgingBuckets are the labels of the receivables aging buckets, by days
+// since the invoice date.
+var AgingBuckets = []string{"0-30", "31-60", "61-90", "90+"}
+
+// AgingBucket returns the index in AgingBuckets for an invoice that is the
+// given number of days old.
+func AgingBucket(days int) int {
+	switch {
+	case days <= 30:
+		return 0
+	case days <= 60:
+		return 1
+	case days <= 90:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// InvoiceAgeDays returns the number of days from an invoice date to asOf,
+// or 0 if the date cannot be read.
+func InvoiceAgeDays(invoiceDate string, asOf time.Time) int {
+	if len(invoiceDate) > 10 {
+		invoiceDate = invoiceDate[:10]
+	}
+	d, err := time.Parse("2006-01-02", invoiceDate)
+	if err != nil {
+		return 0
+	}
+	asOf = time.Date(asOf.Year(), asOf.Month(), asOf.Day(), 0, 0, 0, 0, time.UTC)
+	return int(asOf.Sub(d).Hours() / 24)
+}
+
+// AgingRow is what one project's client owes, spread across the aging
+// buckets, with the retention held on its invoices alongside.
+type AgingRow struct {
+	ProjectID   string
+	ProjectName string
+	Buckets     [4]float64 // amount due, indexed like AgingBuckets
+	Retention   float64
+}
+
+// Due returns the amount due across all buckets.
+func (r AgingRow) Due() float64 {
+	return r.Buckets[0] + r.Buckets[1] + r.Buckets[2] + r.Buckets[3]
+}
+
+// ReceivablesAging is the receivables of every project as of a date.
+type ReceivablesAging struct {
+	AsOf  string
+	Rows  []AgingRow // projects with something outstanding, by name
+	Total AgingRow
+}
+
+// BuildReceivablesAging ages the amount due on every client invoice by the
+// days between its invoice date and asOf. Retention is not aged: it falls
+// due on handover rather than with the invoice. When visible is set, only
+// the projects it accepts are aged and totalled.
+func BuildReceivablesAging(app core.App, asOf time.Time, visible func(projectID string) bool) (*ReceivablesAging, error) {
+	balances, err := ClientInvoiceBalances(app, "")
+	if err != nil {
+		return nil, err
+	}
+	aging := &ReceivablesAging{AsOf: asOf.Format("2006-01-02")}
+
+	rowIndex := make(map[string]int)
+	for _, b := range balances {
+		if b.Outstanding() <= 0.005 || (visible != nil && !visible(b.ProjectID)) {
+			continue
+		}
+		idx, ok := rowIndex[b.ProjectID]
+		if !ok {
+			idx = len(aging.Rows)
+			rowIndex[b.ProjectID] = idx
+			aging.Rows = append(aging.Rows, AgingRow{ProjectID: b.ProjectID, ProjectName: b.ProjectName})
+		}
+		bucket := AgingBucket(InvoiceAgeDays(b.InvoiceDate, asOf))
+		aging.Rows[idx].Buckets[bucket] += b.Due()
+		aging.Rows[idx].Retention += b.RetentionHeld()
+		aging.Total.Buckets[bucket] += b.Due()
+		aging.Total.Retention += b.RetentionHeld()
+	}
+	slices.SortFunc(aging.Rows, func(a, b AgingRow) int {
+		return strings.Compare(strings.ToLower(a.ProjectName), strings.ToLower(b.ProjectName))
+	})
+	return aging, nil
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClientInvoiceBalance_RetentionReceivedLast(t *testing.T) {
+	b := ClientInvoiceBalance{Total: 11800, Retention: 590}
+	if b.Due() != 11210 || b.RetentionHeld() != 590 {
+		t.Errorf("unpaid: expected 11210 due and 590 retention, got %v and %v", b.Due(), b.RetentionHeld())
+	}
+
+	b.Received = 11210
+	if b.Due() != 0 || b.RetentionHeld() != 590 {
+		t.Errorf("paid less retention: expected nothing due and 590 retention, got %v and %v", b.Due(), b.RetentionHeld())
+	}
+
+	b.Received = 11500
+	if b.Due() != 0 || b.RetentionHeld() != 300 {
+		t.Errorf("retention part released: expected nothing due and 300 retention, got %v and %v", b.Due(), b.RetentionHeld())
+	}
+}
+
+func TestAgingBucket(t *testing.T) {
+	tests := []struct {
+		days int
+		want int
+	}{
+		{0, 0}, {30, 0}, {31, 1}, {60, 1}, {61, 2}, {90, 2}, {91, 3}, {400, 3},
+	}
+	for _, tt := range tests {
+		if got := AgingBucket(tt.days); got != tt.want {
+			t.Errorf("AgingBucket(%d) = %d, want %d", tt.days, got, tt.want)
+		}
+	}
+}
+
+func TestCreateClientReceipt(t *testing.T) {
+	app, project, billFrom, billTo, advance := setupMilestoneTest(t)
+	invoice, err := CreateMilestoneInvoice(context.Background(), app, MilestoneInvoiceParams{
+		ProjectID: project.Id, MilestoneID: advance.Id, InvoiceDate: "2026-04-02",
+		BillFromID: billFrom.Id, BillToID: billTo.Id, TaxPercent: 18,
+	})
+	if err != nil {
+		t.Fatalf("CreateMilestoneInvoice failed: %v", err)
+	}
+
+	params := ClientReceiptParams{
+		ProjectID:   project.Id,
+		ReceiptDate: "2026-04-20",
+		Mode:        "neft",
+		Reference:   "UTR123",
+		Allocations: []ReceiptAllocationParams{{ClientInvoiceID: invoice.InvoiceID, Amount: 11210}},
+	}
+	result, err := CreateClientReceipt(context.Background(), app, params)
+	if err != nil {
+		t.Fatalf("CreateClientReceipt failed: %v", err)
+	}
+	if result.Amount != 11210 {
+		t.Errorf("expected a receipt of 11210, got %v", result.Amount)
+	}
+
+	balances, err := ClientInvoiceBalances(app, project.Id)
+	if err != nil {
+		t.Fatalf("ClientInvoiceBalances failed: %v", err)
+	}
+	if len(balances) != 1 || balances[0].Due() != 0 || balances[0].RetentionHeld() != 590 {
+		t.Errorf("expected only retention left, got %+v", balances)
+	}
+
+	// Receiving more than is outstanding is rejected
+	params.Allocations[0].Amount = 600
+	if _, err := CreateClientReceipt(context.Background(), app, params); err == nil || !strings.Contains(err.Error(), "outstanding") {
+		t.Errorf("expected an over-receipt to be rejected, got %v", err)
+	}
+}
+
+func TestBuildReceivablesAging(t *testing.T) {
+	app, project, billFrom, billTo, advance := setupMilestoneTest(t)
+	supply := saveTestRecord(t, app, "billing_milestones", map[string]any{
+		"project": project.Id, "name": "On supply", "percentage": 60, "sort_order": 2,
+	})
+	for _, inv := range []struct{ milestone, date string }{
+		{advance.Id, "2026-01-01"},
+		{supply.Id, "2026-03-20"},
+	} {
+		if _, err := CreateMilestoneInvoice(context.Background(), app, MilestoneInvoiceParams{
+			ProjectID: project.Id, MilestoneID: inv.milestone, InvoiceDate: inv.date,
+			BillFromID: billFrom.Id, BillToID: billTo.Id, TaxPercent: 18,
+		}); err != nil {
+			t.Fatalf("CreateMilestoneInvoice failed: %v", err)
+		}
+	}
+
+	aging, err := BuildReceivablesAging(app, time.Date(2026, 4, 15, 0, 0, 0, 0, time.UTC), nil)
+	if err != nil {
+		t.Fatalf("BuildReceivablesAging failed: %v", err)
+	}
+	if len(aging.Rows) != 1 {
+		t.Fatalf("expected one project row, got %d", len(aging.Rows))
+	}
+	row := aging.Rows[0]
+	// Advance: 11800 less 590 retention, 104 days old; supply: 70800 less
+	// 3540 retention, 26 days old
+	if row.Buckets[3] != 11210 || row.Buckets[0] != 67260 || row.Retention != 4130 {
+		t.Errorf("unexpected aging: %+v", row)
+	}
+	if aging.Total.Due() != row.Due() {
+		t.Errorf("expected totals to match the single project, got %v vs %v", aging.Total.Due(), row.Due())
+	}
+}
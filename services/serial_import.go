@@ -0,0 +1,217 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"mime/multipart"
+	"sort"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// SerialImportItem is a serial-tracked line of the DC being built that an
+// imported sheet can fill.
+type SerialImportItem struct {
+	Key         string // wizard item key, "<source type>_<source id>"
+	Code        string // the BOQ item's ID, printed in the serials template
+	Description string
+	ExpectedQty int // total quantity across destinations; 0 if not yet entered
+}
+
+// SerialImportResult holds the serials read for each item, or the rows
+// that stopped the import.
+type SerialImportResult struct {
+	Serials  map[string][]string // item key -> serials in file order
+	Errors   []ValidationError
+	Warnings []string
+	Total    int
+}
+
+// serialImportColumns maps the normalised column headers of a serials
+// sheet to "code", "description" or "serial".
+var serialImportColumns = map[string]string{
+	"itemcode": "code", "code": "code", "itemid": "code",
+	"description": "description", "boqdescription": "description", "itemdescription": "description", "item": "description",
+	"serial": "serial", "serialno": "serial", "serialnumber": "serial",
+}
+
+// importColumnKey lowercases a column header and drops everything but
+// letters and digits, so "Serial No." and "serial_no" read the same.
+func importColumnKey(header string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return -1
+	}, strings.ToLower(header))
+}
+
+// ImportSerials reads serial numbers from a .csv or .xlsx file with an Item
+// Code or Description column and a Serial Number column, and assigns each
+// row to one of the items. Every row is checked against the rest of the
+// file and against serials already on other DCs; if any is wrong no
+// serials are returned. Quantities that do not match are only warned about,
+// as they can still be changed on the form.
+func ImportSerials(file multipart.File, fileName string, items []SerialImportItem, existingSerials map[string]string) (*SerialImportResult, error) {
+	headers, rows, err := parseImportFile(file, fileName)
+	if err != nil {
+		return nil, err
+	}
+	codeCol, descCol, serialCol := -1, -1, -1
+	for i, h := range headers {
+		switch serialImportColumns[importColumnKey(h)] {
+		case "code":
+			if codeCol < 0 {
+				codeCol = i
+			}
+		case "description":
+			if descCol < 0 {
+				descCol = i
+			}
+		case "serial":
+			if serialCol < 0 {
+				serialCol = i
+			}
+		}
+	}
+	if serialCol < 0 || (codeCol < 0 && descCol < 0) {
+		return nil, fmt.Errorf("the file needs a Serial Number column and an Item Code or Description column")
+	}
+
+	byCode := make(map[string]*SerialImportItem, len(items))
+	byDesc := make(map[string][]*SerialImportItem, len(items))
+	for i := range items {
+		item := &items[i]
+		byCode[strings.ToLower(item.Code)] = item
+		byCode[strings.ToLower(item.Key)] = item
+		desc := strings.ToLower(strings.TrimSpace(item.Description))
+		byDesc[desc] = append(byDesc[desc], item)
+	}
+
+	result := &SerialImportResult{Serials: make(map[string][]string)}
+	var all []string
+	serialRows := make(map[string][]int)
+	for i, row := range rows {
+		rowNum := i + 2
+		cell := func(col int) string {
+			if col >= 0 && col < len(row) {
+				return strings.TrimSpace(row[col])
+			}
+			return ""
+		}
+		code, desc, serial := cell(codeCol), cell(descCol), cell(serialCol)
+		// Template rows left without a serial are simply unused
+		if serial == "" {
+			continue
+		}
+
+		var item *SerialImportItem
+		switch {
+		case code != "":
+			item = byCode[strings.ToLower(code)]
+			if item == nil {
+				result.Errors = append(result.Errors, ValidationError{Row: rowNum, Field: "Item Code", Message: fmt.Sprintf("No serial-tracked item with code %s on this DC", code)})
+				continue
+			}
+		case desc != "":
+			matches := byDesc[strings.ToLower(desc)]
+			if len(matches) == 0 {
+				result.Errors = append(result.Errors, ValidationError{Row: rowNum, Field: "Description", Message: fmt.Sprintf("No serial-tracked item %q on this DC", desc)})
+				continue
+			}
+			if len(matches) > 1 {
+				result.Errors = append(result.Errors, ValidationError{Row: rowNum, Field: "Description", Message: fmt.Sprintf("%q matches %d items; use the Item Code", desc, len(matches))})
+				continue
+			}
+			item = matches[0]
+		default:
+			result.Errors = append(result.Errors, ValidationError{Row: rowNum, Field: "Item Code", Message: "Item Code or Description is required"})
+			continue
+		}
+
+		all = append(all, serial)
+		serialRows[serial] = append(serialRows[serial], rowNum)
+		result.Serials[item.Key] = append(result.Serials[item.Key], serial)
+	}
+
+	// Check the whole file at once so a serial repeated under two items is
+	// caught too
+	check := ValidateSerials(all, len(all), existingSerials)
+	reported := make(map[string]bool)
+	for _, s := range check.DuplicatesInInput {
+		if reported[s] {
+			continue
+		}
+		reported[s] = true
+		first := serialRows[s][0]
+		for _, rowNum := range serialRows[s][1:] {
+			result.Errors = append(result.Errors, ValidationError{Row: rowNum, Field: "Serial Number", Message: fmt.Sprintf("%s is also on row %d", s, first)})
+		}
+	}
+	for _, c := range check.DuplicatesInDB {
+		result.Errors = append(result.Errors, ValidationError{Row: serialRows[c.Serial][0], Field: "Serial Number", Message: fmt.Sprintf("%s is already on %s", c.Serial, c.ExistingDC)})
+	}
+	if len(result.Errors) > 0 {
+		sort.SliceStable(result.Errors, func(i, j int) bool { return result.Errors[i].Row < result.Errors[j].Row })
+		result.Serials = nil
+		return result, nil
+	}
+	if len(all) == 0 {
+		return nil, fmt.Errorf("the file has no serial numbers")
+	}
+
+	result.Total = len(all)
+	for _, item := range items {
+		serials, ok := result.Serials[item.Key]
+		if !ok || item.ExpectedQty == 0 {
+			continue
+		}
+		if check := ValidateSerials(serials, item.ExpectedQty, nil); check.CountMismatch {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s: %d serials for a quantity of %d", item.Description, check.Got, check.Expected))
+		}
+	}
+	return result, nil
+}
+
+// GenerateSerialImportTemplate builds an .xlsx with one row per item, its
+// code and description filled in, for the serial numbers to be listed under.
+func GenerateSerialImportTemplate(items []SerialImportItem) ([]byte, error) {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	sheetName := "Serials"
+	f.SetSheetName(f.GetSheetName(0), sheetName)
+
+	headerStyle, _ := f.NewStyle(&excelize.Style{
+		Font:      &excelize.Font{Bold: true, Color: "#FFFFFF", Size: 11},
+		Fill:      excelize.Fill{Type: "pattern", Color: []string{"#1D4ED8"}, Pattern: 1},
+		Alignment: &excelize.Alignment{Horizontal: "center", Vertical: "center"},
+		Border:    thinBorders(),
+	})
+	for i, header := range []string{"Item Code", "Description", "Serial Number"} {
+		cell := fmt.Sprintf("%c1", 'A'+i)
+		f.SetCellValue(sheetName, cell, header)
+		f.SetCellStyle(sheetName, cell, cell, headerStyle)
+	}
+	f.SetColWidth(sheetName, "A", "A", 20)
+	f.SetColWidth(sheetName, "B", "B", 50)
+	f.SetColWidth(sheetName, "C", "C", 28)
+
+	for i, item := range items {
+		f.SetCellValue(sheetName, fmt.Sprintf("A%d", i+2), item.Code)
+		f.SetCellValue(sheetName, fmt.Sprintf("B%d", i+2), item.Description)
+	}
+	f.SetPanes(sheetName, &excelize.Panes{
+		Freeze:      true,
+		YSplit:      1,
+		TopLeftCell: "A2",
+		ActivePane:  "bottomLeft",
+	})
+
+	var buf bytes.Buffer
+	if err := f.Write(&buf); err != nil {
+		return nil, fmt.Errorf("write serials template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
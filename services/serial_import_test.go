@@ -0,0 +1,129 @@
+package services
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// importSerialsFile writes content to a file of the given name and runs
+// ImportSerials on it.
+func importSerialsFile(t *testing.T, name string, content []byte, items []SerialImportItem, existing map[string]string) (*SerialImportResult, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open file: %v", err)
+	}
+	defer file.Close()
+	return ImportSerials(file, name, items, existing)
+}
+
+func serialImportItems() []SerialImportItem {
+	return []SerialImportItem{
+		{Key: "sub_item_inv001", Code: "inv001", Description: "5kW Inverter", ExpectedQty: 2},
+		{Key: "sub_item_pnl001", Code: "pnl001", Description: "540W Panel", ExpectedQty: 3},
+		{Key: "sub_item_pnl002", Code: "pnl002", Description: "540W Panel"},
+	}
+}
+
+func TestImportSerials(t *testing.T) {
+	csv := "Item Code,Description,Serial No.\n" +
+		"INV001,,INV-A1\n" +
+		",5kW inverter,INV-A2\n" +
+		"pnl001,540W Panel,PNL-1\n" +
+		"pnl001,,PNL-2\n" +
+		"pnl002,,\n"
+	result, err := importSerialsFile(t, "serials.csv", []byte(csv), serialImportItems(), map[string]string{"OLD-1": "TDC-001"})
+	if err != nil {
+		t.Fatalf("ImportSerials failed: %v", err)
+	}
+	if len(result.Errors) != 0 {
+		t.Fatalf("expected no errors, got %+v", result.Errors)
+	}
+	if got := result.Serials["sub_item_inv001"]; len(got) != 2 || got[0] != "INV-A1" || got[1] != "INV-A2" {
+		t.Errorf("expected both inverters by code and by description, got %v", got)
+	}
+	if got := result.Serials["sub_item_pnl001"]; len(got) != 2 {
+		t.Errorf("expected 2 panels, got %v", got)
+	}
+	if _, ok := result.Serials["sub_item_pnl002"]; ok || result.Total != 4 {
+		t.Errorf("expected the row without a serial to be skipped, got %d serials", result.Total)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "540W Panel: 2 serials for a quantity of 3") {
+		t.Errorf("expected a count warning for the panels, got %v", result.Warnings)
+	}
+}
+
+func TestImportSerials_RowErrors(t *testing.T) {
+	csv := "Description,Serial Number\n" +
+		"5kW Inverter,INV-A1\n" +
+		"540W Panel,PNL-1\n" +
+		"Battery,BAT-1\n" +
+		"5kW Inverter,OLD-1\n" +
+		"5kW Inverter,INV-A1\n"
+	result, err := importSerialsFile(t, "serials.csv", []byte(csv), serialImportItems(), map[string]string{"OLD-1": "TDC-001"})
+	if err != nil {
+		t.Fatalf("ImportSerials failed: %v", err)
+	}
+	if result.Serials != nil {
+		t.Error("expected no serials when a row is wrong")
+	}
+	want := []struct {
+		row     int
+		message string
+	}{
+		{3, "matches 2 items"},
+		{4, `No serial-tracked item "Battery"`},
+		{5, "OLD-1 is already on TDC-001"},
+		{6, "INV-A1 is also on row 2"},
+	}
+	if len(result.Errors) != len(want) {
+		t.Fatalf("expected %d errors, got %+v", len(want), result.Errors)
+	}
+	for i, w := range want {
+		if e := result.Errors[i]; e.Row != w.row || !strings.Contains(e.Message, w.message) {
+			t.Errorf("error %d: expected row %d %q, got row %d %q", i, w.row, w.message, e.Row, e.Message)
+		}
+	}
+
+	if _, err := importSerialsFile(t, "serials.csv", []byte("Item,Qty\n5kW Inverter,2\n"), serialImportItems(), nil); err == nil {
+		t.Error("expected a file without a serial column to be rejected")
+	}
+}
+
+func TestGenerateSerialImportTemplate(t *testing.T) {
+	items := serialImportItems()
+	xlsxBytes, err := GenerateSerialImportTemplate(items)
+	if err != nil {
+		t.Fatalf("GenerateSerialImportTemplate failed: %v", err)
+	}
+
+	// Fill the template in and read it back
+	f, err := excelize.OpenReader(bytes.NewReader(xlsxBytes))
+	if err != nil {
+		t.Fatalf("failed to open template: %v", err)
+	}
+	f.SetCellValue("Serials", "C2", "INV-A1")
+	f.SetCellValue("Serials", "C3", "PNL-1")
+	var buf bytes.Buffer
+	if err := f.Write(&buf); err != nil {
+		t.Fatalf("failed to write template: %v", err)
+	}
+	f.Close()
+
+	result, err := importSerialsFile(t, "serials.xlsx", buf.Bytes(), items, nil)
+	if err != nil {
+		t.Fatalf("ImportSerials failed: %v", err)
+	}
+	if len(result.Errors) != 0 || result.Total != 2 || result.Serials["sub_item_pnl001"][0] != "PNL-1" {
+		t.Errorf("expected the filled template to import, got %+v", result)
+	}
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// serialLookupBatch caps the serial numbers bound into one IN (...) clause,
+// well under SQLite's limit on query parameters.
+const serialLookupBatch = 500
+
+// serialInUse is a serial number and the DC holding it.
+type serialInUse struct {
+	Serial   string `db:"serial_number"`
+	DCNumber string `db:"dc_number"`
+}
+
+// serialsInUseQuery selects the serial numbers in use in a project with the
+// number of the DC holding each, joining through the DC line in the same
+// query. Serials brought back on a material return note and serials on
+// cancelled DCs can go out again, so are left out. A serial whose line or
+// DC is missing shows as held by "unknown". Serials on the excluded DCs are
+// left out.
+func serialsInUseQuery(app core.App, projectID string, excludeDCIDs []string) *dbx.SelectQuery {
+	q := app.DB().
+		Select("s.serial_number", "COALESCE(dc.dc_number, 'unknown') AS dc_number").
+		From("serial_numbers s").
+		LeftJoin("dc_line_items li", dbx.NewExp("li.id = s.line_item")).
+		LeftJoin("delivery_challans dc", dbx.NewExp("dc.id = li.dc")).
+		Where(dbx.HashExp{"s.project": projectID}).
+		AndWhere(dbx.NewExp("IFNULL(s.status, '') != {:available}", dbx.Params{"available": SerialStatusAvailable})).
+		AndWhere(dbx.NewExp("IFNULL(dc.status, '') != 'cancelled'"))
+	for i, id := range excludeDCIDs {
+		if id == "" {
+			continue
+		}
+		param := fmt.Sprintf("exclude%d", i)
+		q.AndWhere(dbx.NewExp("IFNULL(dc.id, '') != {:"+param+"}", dbx.Params{param: id}))
+	}
+	return q
+}
+
+// ProjectSerialsInUse returns every serial number in use in a project,
+// mapped to the number of the DC holding it. Serials on excludeDCID, a
+// draft being edited, are left out when it is set.
+func ProjectSerialsInUse(app core.App, projectID, excludeDCID string) (map[string]string, error) {
+	var rows []serialInUse
+	if err := serialsInUseQuery(app, projectID, []string{excludeDCID}).All(&rows); err != nil {
+		return nil, fmt.Errorf("failed to fetch serial numbers in use: %w", err)
+	}
+	inUse := make(map[string]string, len(rows))
+	for _, r := range rows {
+		inUse[r.Serial] = r.DCNumber
+	}
+	return inUse, nil
+}
+
+// FindSerialsInUse returns which of the given serial numbers are already in
+// use in a project, mapped to the DC holding each, leaving out the serials on
+// excludeDCIDs: the DC being checked and, for a split, the transfer DC it
+// forwards serials from. It only reads the index entries for those serials,
+// so the cost does not grow with the number of serials in the project.
+func FindSerialsInUse(app core.App, projectID string, serials []string, excludeDCIDs ...string) (map[string]string, error) {
+	inUse := make(map[string]string)
+	for start := 0; start < len(serials); start += serialLookupBatch {
+		batch := serials[start:min(start+serialLookupBatch, len(serials))]
+		values := make([]any, len(batch))
+		for i, s := range batch {
+			values[i] = s
+		}
+		var rows []serialInUse
+		err := serialsInUseQuery(app, projectID, excludeDCIDs).
+			AndWhere(dbx.In("s.serial_number", values...)).
+			All(&rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check serial numbers: %w", err)
+		}
+		for _, r := range rows {
+			inUse[r.Serial] = r.DCNumber
+		}
+	}
+	return inUse, nil
+}
+
+// serialTrackingBySource returns the serial tracking set on DC templates for
+// the source items of the given DC lines, keyed by source type and ID.
+// Items on no template are not in the map.
+func serialTrackingBySource(app core.App, lineItems []*core.Record) (map[string]string, error) {
+	tracking := make(map[string]string)
+	if len(lineItems) == 0 {
+		return tracking, nil
+	}
+	ids := make([]any, len(lineItems))
+	for i, li := range lineItems {
+		ids[i] = li.GetString("source_item_id")
+	}
+	items, err := app.FindAllRecords("dc_template_items", dbx.In("source_item_id", ids...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch DC template items: %w", err)
+	}
+	for _, item := range items {
+		key := item.GetString("source_item_type") + ":" + item.GetString("source_item_id")
+		if _, ok := tracking[key]; !ok {
+			tracking[key] = item.GetString("serial_tracking")
+		}
+	}
+	return tracking, nil
+}
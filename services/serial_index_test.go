@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/testhelpers"
+)
+
+// transferDCWithSerials creates a transfer DC with one line carrying the
+// given serials.
+func transferDCWithSerials(t testing.TB, app *pocketbase.PocketBase, projectID, dcNumber, status string, serials ...string) *core.Record {
+	t.Helper()
+	dc := saveTestRecord(t, app, "delivery_challans", map[string]any{
+		"project": projectID, "dc_number": dcNumber, "dc_type": "transfer", "status": status, "challan_date": "2026-03-10",
+	})
+	li := saveTestRecord(t, app, "dc_line_items", map[string]any{
+		"dc": dc.Id, "source_item_type": "sub_item", "source_item_id": "x", "quantity": max(len(serials), 1),
+	})
+	for _, s := range serials {
+		saveTestRecord(t, app, "serial_numbers", map[string]any{
+			"project": projectID, "line_item": li.Id, "serial_number": s,
+		})
+	}
+	return dc
+}
+
+func TestFindSerialsInUse(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Serial Project")
+	other := testhelpers.CreateTestProject(t, app, "Other Project")
+
+	issued := transferDCWithSerials(t, app, project.Id, "TDC-001", "issued", "SN-1", "SN-2")
+	transferDCWithSerials(t, app, project.Id, "TDC-002", "cancelled", "SN-3")
+	transferDCWithSerials(t, app, other.Id, "TDC-101", "issued", "SN-4")
+	returned := transferDCWithSerials(t, app, project.Id, "TDC-003", "issued", "SN-5")
+	sn5, _ := app.FindFirstRecordByData("serial_numbers", "serial_number", "SN-5")
+	sn5.Set("status", SerialStatusAvailable)
+	if err := app.Save(sn5); err != nil {
+		t.Fatalf("failed to return serial: %v", err)
+	}
+
+	inUse, err := FindSerialsInUse(app, project.Id, []string{"SN-1", "SN-3", "SN-4", "SN-5", "SN-9"}, "")
+	if err != nil {
+		t.Fatalf("FindSerialsInUse failed: %v", err)
+	}
+	if len(inUse) != 1 || inUse["SN-1"] != "TDC-001" {
+		t.Errorf("expected only SN-1 in use on TDC-001, got %v", inUse)
+	}
+
+	// The DC being edited does not hold its own serials
+	inUse, _ = FindSerialsInUse(app, project.Id, []string{"SN-1"}, issued.Id)
+	if len(inUse) != 0 {
+		t.Errorf("expected SN-1 to be free for its own DC, got %v", inUse)
+	}
+
+	all, err := ProjectSerialsInUse(app, project.Id, returned.Id)
+	if err != nil {
+		t.Fatalf("ProjectSerialsInUse failed: %v", err)
+	}
+	if len(all) != 2 || all["SN-2"] != "TDC-001" {
+		t.Errorf("expected SN-1 and SN-2 in use, got %v", all)
+	}
+}
+
+func TestIssueTransferDC_RejectsSerialInUseElsewhere(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Serial Project")
+	transferDCWithSerials(t, app, project.Id, "TDC-001", "issued", "SN-1")
+	transferDCWithSerials(t, app, project.Id, "TDC-002", "cancelled", "SN-2")
+
+	dup := transferDCWithSerials(t, app, project.Id, "TDC-003", "draft", "SN-1")
+	if err := IssueTransferDC(context.Background(), app, dup.Id); err == nil {
+		t.Error("expected a serial already on TDC-001 to be rejected")
+	}
+	reused := transferDCWithSerials(t, app, project.Id, "TDC-004", "draft", "SN-2")
+	if err := IssueTransferDC(context.Background(), app, reused.Id); err != nil {
+		t.Errorf("expected a serial from a cancelled DC to be reusable, got %v", err)
+	}
+}
+
+// BenchmarkIssueTransferDC issues a ten-serial transfer DC in projects
+// holding more and more serials. The time per issue should stay flat.
+func BenchmarkIssueTransferDC(b *testing.B) {
+	for _, existing := range []int{1000, 10000, 50000} {
+		b.Run(fmt.Sprintf("existing=%d", existing), func(b *testing.B) {
+			app := testhelpers.NewTestApp(b)
+			project := testhelpers.CreateTestProject(b, app, "Bench Project")
+			seedSerials(b, app, project.Id, existing)
+
+			serials := make([]string, 10)
+			for i := range serials {
+				serials[i] = fmt.Sprintf("NEW-%d", i)
+			}
+			dc := transferDCWithSerials(b, app, project.Id, "TDC-NEW", "draft", serials...)
+
+			b.ResetTimer()
+			for b.Loop() {
+				if err := IssueTransferDC(context.Background(), app, dc.Id); err != nil {
+					b.Fatalf("IssueTransferDC failed: %v", err)
+				}
+				b.StopTimer()
+				dc.Set("status", "draft")
+				if err := app.Save(dc); err != nil {
+					b.Fatalf("failed to reset DC: %v", err)
+				}
+				b.StartTimer()
+			}
+		})
+	}
+}
+
+// seedSerials fills a project with issued transfer DCs of 100 serials each.
+func seedSerials(b *testing.B, app *pocketbase.PocketBase, projectID string, n int) {
+	b.Helper()
+	err := app.RunInTransaction(func(txApp core.App) error {
+		dcs, _ := txApp.FindCollectionByNameOrId("delivery_challans")
+		lines, _ := txApp.FindCollectionByNameOrId("dc_line_items")
+		serials, _ := txApp.FindCollectionByNameOrId("serial_numbers")
+		var line *core.Record
+		for i := range n {
+			if i%100 == 0 {
+				dc := core.NewRecord(dcs)
+				dc.Load(map[string]any{
+					"project": projectID, "dc_number": fmt.Sprintf("TDC-%05d", i/100), "dc_type": "transfer", "status": "issued", "challan_date": "2026-03-10",
+				})
+				if err := txApp.Save(dc); err != nil {
+					return err
+				}
+				line = core.NewRecord(lines)
+				line.Load(map[string]any{"dc": dc.Id, "source_item_type": "sub_item", "source_item_id": "x", "quantity": 100})
+				if err := txApp.Save(line); err != nil {
+					return err
+				}
+			}
+			sr := core.NewRecord(serials)
+			sr.Load(map[string]any{"project": projectID, "line_item": line.Id, "serial_number": fmt.Sprintf("SN-%06d", i)})
+			if err := txApp.Save(sr); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		b.Fatalf("failed to seed serials: %v", err)
+	}
+}
@@ -0,0 +1,686 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// Kinds of event in the history of a serial number.
+const (
+	SerialEventCreated    = "created"
+	SerialEventDispatched = "dispatched"
+	SerialEventDelivered  = "delivered"
+	SerialEventInstalled  = "installed"
+	SerialEventReturned   = "returned"
+	SerialEventReplaced   = "replaced"
+	SerialEventRemoved    = "removed"
+)
+
+// serialEventOrder orders events that happened on the same day.
+var serialEventOrder = []string{
+	SerialEventCreated, SerialEventDispatched, SerialEventDelivered,
+	SerialEventInstalled, SerialEventReplaced, SerialEventRemoved, SerialEventReturned,
+}
+
+var serialEventLabels = map[string]string{
+	SerialEventCreated:    "Created",
+	SerialEventDispatched: "Dispatched",
+	SerialEventDelivered:  "Delivered",
+	SerialEventInstalled:  "Installed",
+	SerialEventReturned:   "Returned",
+	SerialEventReplaced:   "Replaced",
+	SerialEventRemoved:    "Removed",
+}
+
+// SerialEventLabel returns the display name of a serial event kind.
+func SerialEventLabel(kind string) string {
+	if label, ok := serialEventLabels[kind]; ok {
+		return label
+	}
+	return kind
+}
+
+// SerialEvent is one step in the history of a serial number.
+type SerialEvent struct {
+	Kind        string
+	Date        string // YYYY-MM-DD
+	Description string
+	DCID        string // the DC the event concerns, if any
+	DCNumber    string
+}
+
+// SerialTraceDC is a DC in a serial's chain. Carried DCs list the serial
+// on a line; the official DCs of its shipment groups do not, but are what
+// the goods were delivered under.
+type SerialTraceDC struct {
+	ID              string
+	Number          string
+	Type            string
+	Status          string
+	ChallanDate     string
+	ShipTo          string
+	ShipmentGroupID string
+	Carried         bool
+}
+
+// SerialTraceShipment is a shipment group a serial travelled in.
+type SerialTraceShipment struct {
+	ID               string
+	Label            string
+	Status           string
+	TransferDCID     string // set when the group is a split of a transfer DC
+	TransferDCNumber string
+	SplitNumber      int
+}
+
+// SerialTraceSite is the install-at site a serial is installed at.
+type SerialTraceSite struct {
+	AddressID        string
+	Label            string
+	WorkOrderID      string
+	WONumber         string
+	Status           string
+	InstallationDate string
+}
+
+// SerialTraceGRN is a goods receipt against a PO line of the serial's item.
+type SerialTraceGRN struct {
+	ID           string
+	Number       string
+	ReceivedDate string
+	QtyAccepted  float64
+}
+
+// SerialTraceSource is a purchase order for the serial's BOQ item, with the
+// goods received against it.
+type SerialTraceSource struct {
+	POID       string
+	PONumber   string
+	OrderDate  string
+	VendorName string
+	GRNs       []SerialTraceGRN
+}
+
+// SerialTrace is everything known about one serial number in one project.
+type SerialTrace struct {
+	Serial      string
+	ProjectID   string
+	ProjectName string
+	Item        string // BOQ description of the item it was dispatched as
+	DCs         []SerialTraceDC
+	Shipments   []SerialTraceShipment
+	Site        *SerialTraceSite
+	Sources     []SerialTraceSource
+	Events      []SerialEvent
+}
+
+// SerialMatch is a serial number found by a partial search.
+type SerialMatch struct {
+	Serial      string
+	ProjectID   string
+	ProjectName string
+}
+
+// SearchSerials returns the serial numbers containing query, at most limit
+// of them, each once per project. When visible is set, only matches on the
+// projects it accepts are returned and count towards the limit.
+func SearchSerials(app core.App, query string, limit int, visible func(projectID string) bool) ([]SerialMatch, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, nil
+	}
+	recs, err := app.FindRecordsByFilter("serial_numbers", "serial_number ~ {:q}", "serial_number", 0, 0, map[string]any{"q": query})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search serial numbers: %w", err)
+	}
+	seen := make(map[[2]string]bool)
+	projectNames := make(map[string]string)
+	var matches []SerialMatch
+	for _, r := range recs {
+		m := SerialMatch{Serial: r.GetString("serial_number"), ProjectID: r.GetString("project")}
+		key := [2]string{m.Serial, m.ProjectID}
+		if seen[key] || (visible != nil && !visible(m.ProjectID)) {
+			continue
+		}
+		seen[key] = true
+		name, ok := projectNames[m.ProjectID]
+		if !ok {
+			if project, err := app.FindRecordById("projects", m.ProjectID); err == nil {
+				name = project.GetString("name")
+			}
+			projectNames[m.ProjectID] = name
+		}
+		m.ProjectName = name
+		matches = append(matches, m)
+		if len(matches) == limit {
+			break
+		}
+	}
+	return matches, nil
+}
+
+// TraceSerial returns the history of a serial number, one trace for each
+// project it was dispatched in.
+//
+// serial_numbers only holds the serial against the DC lines that carried
+// it, so the rest of the chain is worked out from the records around them:
+// the shipment groups and splits of those DCs, proof of delivery, the work
+// order site it was installed at, material return notes, and the change
+// history of work order sites for replacements. GRNs do not record serials,
+// so the sources are the POs and GRNs for the same BOQ item.
+func TraceSerial(app core.App, serial string) ([]SerialTrace, error) {
+	serial = strings.TrimSpace(serial)
+	if serial == "" {
+		return nil, nil
+	}
+	recs, err := app.FindRecordsByFilter("serial_numbers", "serial_number = {:s}", "created", 0, 0, map[string]any{"s": serial})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch serial numbers: %w", err)
+	}
+	var projects []string
+	byProject := make(map[string][]*core.Record)
+	for _, r := range recs {
+		pid := r.GetString("project")
+		if _, ok := byProject[pid]; !ok {
+			projects = append(projects, pid)
+		}
+		byProject[pid] = append(byProject[pid], r)
+	}
+
+	var traces []SerialTrace
+	for _, pid := range projects {
+		trace, err := buildSerialTrace(app, serial, pid, byProject[pid])
+		if err != nil {
+			return nil, err
+		}
+		traces = append(traces, *trace)
+	}
+	return traces, nil
+}
+
+// buildSerialTrace assembles the trace of a serial in one project from its
+// serial_numbers records, oldest first.
+func buildSerialTrace(app core.App, serial, projectID string, recs []*core.Record) (*SerialTrace, error) {
+	trace := &SerialTrace{Serial: serial, ProjectID: projectID}
+	if project, err := app.FindRecordById("projects", projectID); err == nil {
+		trace.ProjectName = project.GetString("name")
+	}
+
+	recIDs := make([]string, 0, len(recs))
+	var carried []*core.Record
+	carriedIDs := make(map[string]bool)
+	var sourceLine, firstDC *core.Record
+	for _, r := range recs {
+		recIDs = append(recIDs, r.Id)
+		line, err := app.FindRecordById("dc_line_items", r.GetString("line_item"))
+		if err != nil {
+			continue
+		}
+		if sourceLine == nil {
+			sourceLine = line
+		}
+		dc, err := app.FindRecordById("delivery_challans", line.GetString("dc"))
+		if err != nil || carriedIDs[dc.Id] {
+			continue
+		}
+		if firstDC == nil {
+			firstDC = dc
+		}
+		carriedIDs[dc.Id] = true
+		carried = append(carried, dc)
+	}
+	slices.SortStableFunc(carried, func(a, b *core.Record) int {
+		return strings.Compare(a.GetString("challan_date"), b.GetString("challan_date"))
+	})
+
+	if sourceLine != nil {
+		trace.Item = boqItemDescription(app, sourceLine.GetString("source_item_type"), sourceLine.GetString("source_item_id"))
+	}
+	if firstDC != nil {
+		trace.Events = append(trace.Events, SerialEvent{
+			Kind:        SerialEventCreated,
+			Date:        recs[0].GetDateTime("created").Time().Format("2006-01-02"),
+			Description: fmt.Sprintf("Entered on %s DC %s", strings.ToLower(dcTypeName(firstDC.GetString("dc_type"))), firstDC.GetString("dc_number")),
+			DCID:        firstDC.Id,
+			DCNumber:    firstDC.GetString("dc_number"),
+		})
+	}
+
+	site, installedOn, err := serialSite(app, recIDs)
+	if err != nil {
+		return nil, err
+	}
+	siteShipTo := ""
+	if site != nil {
+		trace.Site = site
+		if addr, err := app.FindRecordById("addresses", site.AddressID); err == nil {
+			siteShipTo = addr.GetString("ship_to_parent")
+		}
+	}
+
+	// The DCs the goods were delivered under: the carried DCs themselves,
+	// except transit DCs, which deliver through the official DC of the
+	// serial's destination
+	var deliveredUnder []*core.Record
+	seenGroups := make(map[string]bool)
+	for _, dc := range carried {
+		trace.DCs = append(trace.DCs, serialTraceDC(app, dc, true))
+		status := dc.GetString("status")
+		if status != "draft" && status != "cancelled" {
+			date := dc.GetString("issued_at")
+			if len(date) >= 10 {
+				date = date[:10]
+			} else {
+				date = dc.GetString("challan_date")
+			}
+			desc := fmt.Sprintf("Dispatched on %s DC %s", strings.ToLower(dcTypeName(dc.GetString("dc_type"))), dc.GetString("dc_number"))
+			if to := addressLabel(app, dc.GetString("ship_to_address")); to != "" {
+				desc += " to " + to
+			}
+			trace.Events = append(trace.Events, SerialEvent{Kind: SerialEventDispatched, Date: date, Description: desc, DCID: dc.Id, DCNumber: dc.GetString("dc_number")})
+		}
+
+		sgID := dc.GetString("shipment_group")
+		if dc.GetString("dc_type") != "transit" || sgID == "" {
+			deliveredUnder = append(deliveredUnder, dc)
+			continue
+		}
+		if seenGroups[sgID] {
+			continue
+		}
+		seenGroups[sgID] = true
+		shipment, officials, err := serialShipment(app, sgID)
+		if err != nil {
+			return nil, err
+		}
+		if shipment == nil {
+			continue
+		}
+		trace.Shipments = append(trace.Shipments, *shipment)
+		var destination []*core.Record
+		for _, o := range officials {
+			if o.Id == dc.Id {
+				continue
+			}
+			trace.DCs = append(trace.DCs, serialTraceDC(app, o, false))
+			if siteShipTo != "" && (o.GetString("ship_to_address") == siteShipTo || o.GetString("ship_to_address") == site.AddressID) {
+				destination = append(destination, o)
+			}
+		}
+		// Without an install site the destination is only known when the
+		// shipment went to a single place
+		if destination == nil && len(officials) == 1 {
+			destination = officials
+		}
+		deliveredUnder = append(deliveredUnder, destination...)
+	}
+
+	for _, dc := range deliveredUnder {
+		delivery, err := app.FindFirstRecordByFilter("dc_deliveries", "dc = {:dc}", map[string]any{"dc": dc.Id})
+		if err != nil {
+			continue
+		}
+		desc := fmt.Sprintf("Delivered on DC %s", dc.GetString("dc_number"))
+		if to := addressLabel(app, dc.GetString("ship_to_address")); to != "" {
+			desc = fmt.Sprintf("Delivered to %s on DC %s", to, dc.GetString("dc_number"))
+		}
+		if by := delivery.GetString("received_by"); by != "" {
+			desc += ", received by " + by
+		}
+		if dc.GetString("status") == "partially_delivered" {
+			desc += " (part delivery)"
+		}
+		trace.Events = append(trace.Events, SerialEvent{Kind: SerialEventDelivered, Date: delivery.GetString("received_date"), Description: desc, DCID: dc.Id, DCNumber: dc.GetString("dc_number")})
+	}
+
+	if site != nil {
+		trace.Events = append(trace.Events, SerialEvent{
+			Kind:        SerialEventInstalled,
+			Date:        installedOn,
+			Description: fmt.Sprintf("Installed at %s under work order %s", site.Label, site.WONumber),
+		})
+	}
+
+	returns, err := serialReturns(app, recIDs)
+	if err != nil {
+		return nil, err
+	}
+	trace.Events = append(trace.Events, returns...)
+
+	swaps, err := serialSiteChanges(app, recIDs)
+	if err != nil {
+		return nil, err
+	}
+	trace.Events = append(trace.Events, swaps...)
+
+	if sourceLine != nil {
+		sources, err := serialSources(app, projectID, sourceLine.GetString("source_item_type"), sourceLine.GetString("source_item_id"))
+		if err != nil {
+			return nil, err
+		}
+		trace.Sources = sources
+	}
+
+	slices.SortStableFunc(trace.Events, func(a, b SerialEvent) int {
+		if c := strings.Compare(a.Date, b.Date); c != 0 {
+			return c
+		}
+		return slices.Index(serialEventOrder, a.Kind) - slices.Index(serialEventOrder, b.Kind)
+	})
+	return trace, nil
+}
+
+// serialTraceDC describes a DC in a serial's chain.
+func serialTraceDC(app core.App, dc *core.Record, carried bool) SerialTraceDC {
+	return SerialTraceDC{
+		ID:              dc.Id,
+		Number:          dc.GetString("dc_number"),
+		Type:            dc.GetString("dc_type"),
+		Status:          dc.GetString("status"),
+		ChallanDate:     dc.GetString("challan_date"),
+		ShipTo:          addressLabel(app, dc.GetString("ship_to_address")),
+		ShipmentGroupID: dc.GetString("shipment_group"),
+		Carried:         carried,
+	}
+}
+
+// serialShipment describes a shipment group and returns its official DCs.
+// A group split from a transfer DC names the transfer DC and split.
+func serialShipment(app core.App, sgID string) (*SerialTraceShipment, []*core.Record, error) {
+	sg, err := app.FindRecordById("shipment_groups", sgID)
+	if err != nil {
+		return nil, nil, nil
+	}
+	dcs, err := app.FindRecordsByFilter("delivery_challans", "shipment_group = {:sg}", "dc_type,dc_number", 0, 0, map[string]any{"sg": sgID})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch shipment DCs: %w", err)
+	}
+	shipment := &SerialTraceShipment{ID: sg.Id, Label: "Shipment group", Status: sg.GetString("status")}
+	var officials []*core.Record
+	for _, dc := range dcs {
+		switch dc.GetString("dc_type") {
+		case "transit":
+			shipment.Label = "Shipment via " + dc.GetString("dc_number")
+		case "official":
+			officials = append(officials, dc)
+		}
+	}
+	if tdcID := sg.GetString("transfer_dc"); tdcID != "" {
+		if tdc, err := app.FindRecordById("transfer_dcs", tdcID); err == nil {
+			if parent, err := app.FindRecordById("delivery_challans", tdc.GetString("dc")); err == nil {
+				shipment.TransferDCID = parent.Id
+				shipment.TransferDCNumber = parent.GetString("dc_number")
+			}
+		}
+		if split, err := app.FindRecordById("transfer_dc_splits", sg.GetString("split")); err == nil {
+			shipment.SplitNumber = split.GetInt("split_number")
+		}
+	}
+	return shipment, officials, nil
+}
+
+// serialSite returns the work order site any of the serial's records is
+// installed at, if any, and the date it was installed: the installation
+// date, or failing that the day the site was last updated.
+func serialSite(app core.App, recIDs []string) (*SerialTraceSite, string, error) {
+	if len(recIDs) == 0 {
+		return nil, "", nil
+	}
+	filter, params := anyRelationFilter("serial_numbers", recIDs)
+	sites, err := app.FindRecordsByFilter("work_order_sites", filter, "-updated", 1, 0, params)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch work order sites: %w", err)
+	}
+	if len(sites) == 0 {
+		return nil, "", nil
+	}
+	s := sites[0]
+	site := &SerialTraceSite{
+		AddressID:        s.GetString("install_at_address"),
+		Label:            addressLabel(app, s.GetString("install_at_address")),
+		WorkOrderID:      s.GetString("work_order"),
+		Status:           s.GetString("status"),
+		InstallationDate: s.GetString("installation_date"),
+	}
+	if wo, err := app.FindRecordById("work_orders", site.WorkOrderID); err == nil {
+		site.WONumber = wo.GetString("wo_number")
+	}
+	installedOn := site.InstallationDate
+	if installedOn == "" {
+		installedOn = s.GetDateTime("updated").Time().Format("2006-01-02")
+	}
+	return site, installedOn, nil
+}
+
+// serialReturns lists the material return notes that brought the serial back.
+func serialReturns(app core.App, recIDs []string) ([]SerialEvent, error) {
+	if len(recIDs) == 0 {
+		return nil, nil
+	}
+	filter, params := anyRelationFilter("serial_numbers", recIDs)
+	lines, err := app.FindRecordsByFilter("material_return_lines", filter, "", 0, 0, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch material return lines: %w", err)
+	}
+	var events []SerialEvent
+	for _, l := range lines {
+		mrn, err := app.FindRecordById("material_return_notes", l.GetString("mrn"))
+		if err != nil {
+			continue
+		}
+		desc := fmt.Sprintf("Returned on MRN %s in %s condition", mrn.GetString("mrn_number"), l.GetString("condition"))
+		event := SerialEvent{Kind: SerialEventReturned, Date: mrn.GetString("return_date"), Description: desc}
+		if dc, err := app.FindRecordById("delivery_challans", mrn.GetString("dc")); err == nil {
+			event.Description += " against DC " + dc.GetString("dc_number")
+			event.DCID = dc.Id
+			event.DCNumber = dc.GetString("dc_number")
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// serialSiteChanges reads the change history of work order sites for the
+// times the serial was taken out of a site, or put in in place of another.
+// A serial swapped for another in the same change is a replacement.
+func serialSiteChanges(app core.App, recIDs []string) ([]SerialEvent, error) {
+	if len(recIDs) == 0 {
+		return nil, nil
+	}
+	ours := make(map[string]bool, len(recIDs))
+	for _, id := range recIDs {
+		ours[id] = true
+	}
+	filter, params := anyRelationFilter("changes", recIDs)
+	entries, err := app.FindRecordsByFilter("audit_log",
+		"collection_name = 'work_order_sites' && action = 'update' && ("+filter+")",
+		"created", 0, 0, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch site history: %w", err)
+	}
+
+	var events []SerialEvent
+	for _, entry := range entries {
+		var changes map[string]AuditChange
+		raw, _ := json.Marshal(entry.Get("changes"))
+		if err := json.Unmarshal(raw, &changes); err != nil {
+			continue
+		}
+		change, ok := changes["serial_numbers"]
+		if !ok {
+			continue
+		}
+		before, after := auditIDList(change.Before), auditIDList(change.After)
+		var removed, added []string
+		for _, id := range before {
+			if !slices.Contains(after, id) {
+				removed = append(removed, id)
+			}
+		}
+		for _, id := range after {
+			if !slices.Contains(before, id) {
+				added = append(added, id)
+			}
+		}
+
+		site := "the site"
+		if s, err := app.FindRecordById("work_order_sites", entry.GetString("record_id")); err == nil {
+			site = addressLabel(app, s.GetString("install_at_address"))
+		}
+		date := entry.GetDateTime("created").Time().Format("2006-01-02")
+		removedHere := slices.ContainsFunc(removed, func(id string) bool { return ours[id] })
+		addedHere := slices.ContainsFunc(added, func(id string) bool { return ours[id] })
+		switch {
+		case removedHere && len(added) > 0:
+			events = append(events, SerialEvent{Kind: SerialEventReplaced, Date: date,
+				Description: fmt.Sprintf("Replaced at %s by %s", site, serialNumbersOf(app, added))})
+		case removedHere:
+			events = append(events, SerialEvent{Kind: SerialEventRemoved, Date: date,
+				Description: "Removed from " + site})
+		case addedHere && len(removed) > 0:
+			events = append(events, SerialEvent{Kind: SerialEventReplaced, Date: date,
+				Description: fmt.Sprintf("Fitted at %s in place of %s", site, serialNumbersOf(app, removed))})
+		}
+	}
+	return events, nil
+}
+
+// serialSources returns the project's purchase orders for a BOQ item with
+// the goods received against them, in order date order.
+func serialSources(app core.App, projectID, sourceType, sourceID string) ([]SerialTraceSource, error) {
+	lines, err := app.FindRecordsByFilter("po_line_items",
+		"purchase_order.project = {:pid} && purchase_order.status != 'cancelled' && source_item_type = {:t} && source_item_id = {:id}",
+		"", 0, 0, map[string]any{"pid": projectID, "t": sourceType, "id": sourceID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch PO lines: %w", err)
+	}
+	var sources []SerialTraceSource
+	index := make(map[string]int)
+	for _, l := range lines {
+		poID := l.GetString("purchase_order")
+		i, ok := index[poID]
+		if !ok {
+			po, err := app.FindRecordById("purchase_orders", poID)
+			if err != nil {
+				continue
+			}
+			src := SerialTraceSource{POID: po.Id, PONumber: po.GetString("po_number"), OrderDate: po.GetString("order_date")}
+			if v, err := app.FindRecordById("vendors", po.GetString("vendor")); err == nil {
+				src.VendorName = v.GetString("name")
+			}
+			i = len(sources)
+			index[poID] = i
+			sources = append(sources, src)
+		}
+
+		grnLines, err := app.FindRecordsByFilter("grn_line_items", "po_line_item = {:id}", "created", 0, 0, map[string]any{"id": l.Id})
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch GRN lines: %w", err)
+		}
+		for _, gl := range grnLines {
+			grn, err := app.FindRecordById("grn", gl.GetString("grn"))
+			if err != nil {
+				continue
+			}
+			sources[i].GRNs = append(sources[i].GRNs, SerialTraceGRN{
+				ID:           grn.Id,
+				Number:       grn.GetString("grn_number"),
+				ReceivedDate: grn.GetString("received_date"),
+				QtyAccepted:  gl.GetFloat("qty_accepted"),
+			})
+		}
+	}
+	slices.SortStableFunc(sources, func(a, b SerialTraceSource) int {
+		return strings.Compare(a.OrderDate, b.OrderDate)
+	})
+	return sources, nil
+}
+
+// anyRelationFilter matches records whose multi-relation (or JSON) field
+// mentions any of the given IDs.
+func anyRelationFilter(field string, ids []string) (string, map[string]any) {
+	clauses := make([]string, 0, len(ids))
+	params := make(map[string]any, len(ids))
+	for i, id := range ids {
+		key := fmt.Sprintf("id%d", i)
+		clauses = append(clauses, fmt.Sprintf("%s ~ {:%s}", field, key))
+		params[key] = id
+	}
+	return strings.Join(clauses, " || "), params
+}
+
+// auditIDList reads a relation list stored in an audit change.
+func auditIDList(v any) []string {
+	switch ids := v.(type) {
+	case []any:
+		out := make([]string, 0, len(ids))
+		for _, id := range ids {
+			if s, ok := id.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	case string:
+		if ids != "" {
+			return []string{ids}
+		}
+	}
+	return nil
+}
+
+// serialNumbersOf joins the serial numbers of serial_numbers records.
+func serialNumbersOf(app core.App, ids []string) string {
+	var serials []string
+	for _, id := range ids {
+		if r, err := app.FindRecordById("serial_numbers", id); err == nil {
+			serials = append(serials, r.GetString("serial_number"))
+		}
+	}
+	if len(serials) == 0 {
+		return "another serial"
+	}
+	return strings.Join(serials, ", ")
+}
+
+// addressLabel returns the short label of an address, or "" if there is none.
+func addressLabel(app core.App, addressID string) string {
+	if addressID == "" {
+		return ""
+	}
+	addr, err := app.FindRecordById("addresses", addressID)
+	if err != nil {
+		return ""
+	}
+	return SiteLabel(addr)
+}
+
+// boqItemDescription returns the description of a BOQ sub item or sub-sub item.
+func boqItemDescription(app core.App, sourceType, sourceID string) string {
+	collection := "sub_items"
+	if sourceType == "sub_sub_item" {
+		collection = "sub_sub_items"
+	}
+	if rec, err := app.FindRecordById(collection, sourceID); err == nil {
+		return rec.GetString("description")
+	}
+	return ""
+}
+
+// dcTypeName returns the display name of a DC type.
+func dcTypeName(dcType string) string {
+	switch dcType {
+	case "transit":
+		return "Transit"
+	case "official":
+		return "Official"
+	case "transfer":
+		return "Transfer"
+	case "returnable":
+		return "Returnable"
+	}
+	return dcType
+}
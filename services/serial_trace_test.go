@@ -0,0 +1,164 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/testhelpers"
+)
+
+func TestTraceSerial_DispatchDeliveryInstallAndReplacement(t *testing.T) {
+	app, project, service, installer, shipTo, sites := setupWorkOrderTest(t)
+	RegisterAuditHooks(app)
+	ctx := context.Background()
+	today := time.Now().Format("2006-01-02")
+
+	_, transit, official := issuedShipmentGroup(t, app, project.Id, "001", "PMP-1", "PMP-2")
+	transit.Set("issued_at", time.Now().Format(time.RFC3339))
+	official.Set("ship_to_address", shipTo.Id)
+	for _, dc := range []*core.Record{transit, official} {
+		if err := app.Save(dc); err != nil {
+			t.Fatalf("failed to update the DC: %v", err)
+		}
+	}
+	pump, _ := app.FindFirstRecordByData("sub_items", "description", "Pump")
+	line, _ := app.FindFirstRecordByData("dc_line_items", "dc", transit.Id)
+	line.Set("source_item_id", pump.Id)
+	if err := app.Save(line); err != nil {
+		t.Fatalf("failed to link the DC line to the item: %v", err)
+	}
+	saveTestRecord(t, app, "dc_deliveries", map[string]any{
+		"dc": official.Id, "received_by": "Ravi", "received_date": today,
+	})
+
+	// The item was bought on a PO and received on a GRN
+	vendor := testhelpers.CreateTestVendor(t, app, "Pump Makers")
+	po := testhelpers.CreateTestPurchaseOrder(t, app, project.Id, vendor.Id, "PO-001")
+	poLine := testhelpers.CreateTestPOLineItem(t, app, po.Id, 1, "Pump", 10, 100, 18)
+	poLine.Set("source_item_type", "sub_item")
+	poLine.Set("source_item_id", pump.Id)
+	if err := app.Save(poLine); err != nil {
+		t.Fatalf("failed to link the PO line to the item: %v", err)
+	}
+	grn := saveTestRecord(t, app, "grn", map[string]any{
+		"project": project.Id, "purchase_order": po.Id, "grn_number": "GRN-001", "received_date": "2025-05-20",
+	})
+	saveTestRecord(t, app, "grn_line_items", map[string]any{
+		"grn": grn.Id, "po_line_item": poLine.Id, "qty_received": 10, "qty_accepted": 10,
+	})
+
+	wo, err := CreateWorkOrder(ctx, app, WorkOrderParams{
+		ProjectID:   project.Id,
+		InstallerID: installer.Id,
+		WODate:      "2025-06-10",
+		Lines:       []WorkOrderLineParams{{SourceItemType: "sub_item", SourceItemID: service.Id, QtyPerSite: 1, Rate: 1500}},
+		SiteIDs:     []string{sites[0].Id},
+	})
+	if err != nil {
+		t.Fatalf("CreateWorkOrder failed: %v", err)
+	}
+	site, _ := app.FindFirstRecordByFilter("work_order_sites", "work_order = {:wo}", map[string]any{"wo": wo.Id})
+	first, _ := app.FindFirstRecordByData("serial_numbers", "serial_number", "PMP-1")
+	second, _ := app.FindFirstRecordByData("serial_numbers", "serial_number", "PMP-2")
+	if err := UpdateWorkOrderSite(ctx, app, site.Id, SiteProgressParams{Status: SiteStatusInstalled, InstallationDate: today, SerialIDs: []string{first.Id}}); err != nil {
+		t.Fatalf("UpdateWorkOrderSite failed: %v", err)
+	}
+
+	traces, err := TraceSerial(app, " PMP-1 ")
+	if err != nil {
+		t.Fatalf("TraceSerial failed: %v", err)
+	}
+	if len(traces) != 1 {
+		t.Fatalf("expected 1 trace, got %d", len(traces))
+	}
+	trace := traces[0]
+	if trace.ProjectName != "Install Project" || trace.Item != "Pump" {
+		t.Errorf("expected project and item, got %q and %q", trace.ProjectName, trace.Item)
+	}
+	if len(trace.DCs) != 2 || !trace.DCs[0].Carried || trace.DCs[1].Carried || trace.DCs[1].ID != official.Id {
+		t.Errorf("expected the transit DC carrying the serial and the official DC, got %+v", trace.DCs)
+	}
+	if len(trace.Shipments) != 1 {
+		t.Errorf("expected 1 shipment group, got %d", len(trace.Shipments))
+	}
+	if trace.Site == nil || trace.Site.AddressID != sites[0].Id || trace.Site.WorkOrderID != wo.Id {
+		t.Errorf("expected the install site, got %+v", trace.Site)
+	}
+	if len(trace.Sources) != 1 || trace.Sources[0].PONumber != "PO-001" || len(trace.Sources[0].GRNs) != 1 {
+		t.Errorf("expected PO-001 with one GRN, got %+v", trace.Sources)
+	}
+
+	var kinds []string
+	for _, ev := range trace.Events {
+		kinds = append(kinds, ev.Kind)
+	}
+	want := []string{SerialEventCreated, SerialEventDispatched, SerialEventDelivered, SerialEventInstalled}
+	if len(kinds) != len(want) {
+		t.Fatalf("expected events %v, got %v", want, kinds)
+	}
+	for i := range want {
+		if kinds[i] != want[i] {
+			t.Fatalf("expected events %v, got %v", want, kinds)
+		}
+	}
+	if trace.Events[2].DCID != official.Id {
+		t.Errorf("expected delivery under the official DC, got %s", trace.Events[2].DCNumber)
+	}
+
+	// Swapping the pump for the other one is a replacement on both
+	if err := UpdateWorkOrderSite(ctx, app, site.Id, SiteProgressParams{Status: SiteStatusInstalled, InstallationDate: today, SerialIDs: []string{second.Id}}); err != nil {
+		t.Fatalf("UpdateWorkOrderSite failed: %v", err)
+	}
+	traces, _ = TraceSerial(app, "PMP-1")
+	last := traces[0].Events[len(traces[0].Events)-1]
+	if last.Kind != SerialEventReplaced || traces[0].Site != nil {
+		t.Errorf("expected PMP-1 to be replaced and off the site, got %s (%+v)", last.Kind, traces[0].Site)
+	}
+	traces, _ = TraceSerial(app, "PMP-2")
+	if traces[0].Site == nil {
+		t.Error("expected PMP-2 to be at the site")
+	}
+	found := false
+	for _, ev := range traces[0].Events {
+		found = found || ev.Kind == SerialEventReplaced
+	}
+	if !found {
+		t.Error("expected PMP-2 to record the replacement")
+	}
+}
+
+func TestSearchSerials_PartialMatches(t *testing.T) {
+	app := testhelpers.NewTestApp(t)
+	project := testhelpers.CreateTestProject(t, app, "Search Project")
+	issuedShipmentGroup(t, app, project.Id, "001", "PMP-10", "PMP-11", "MTR-1")
+
+	traces, err := TraceSerial(app, "PMP-1")
+	if err != nil {
+		t.Fatalf("TraceSerial failed: %v", err)
+	}
+	if len(traces) != 0 {
+		t.Errorf("expected no exact match, got %d", len(traces))
+	}
+
+	matches, err := SearchSerials(app, "pmp-1", 10, nil)
+	if err != nil {
+		t.Fatalf("SearchSerials failed: %v", err)
+	}
+	if len(matches) != 2 || matches[0].Serial != "PMP-10" || matches[0].ProjectName != "Search Project" {
+		t.Errorf("expected PMP-10 and PMP-11, got %+v", matches)
+	}
+	if limited, _ := SearchSerials(app, "PMP", 1, nil); len(limited) != 1 {
+		t.Errorf("expected the limit to apply, got %d", len(limited))
+	}
+
+	// Matches on projects that are not visible do not use up the limit
+	other := testhelpers.CreateTestProject(t, app, "Other Project")
+	issuedShipmentGroup(t, app, other.Id, "002", "PMP-01")
+	visible := func(projectID string) bool { return projectID == project.Id }
+	if limited, _ := SearchSerials(app, "PMP", 1, visible); len(limited) != 1 || limited[0].Serial != "PMP-10" {
+		t.Errorf("expected PMP-10 from the visible project, got %+v", limited)
+	}
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// SiteTrackerRow is the progress of one install-at site through the
+// dispatched, delivered, installed and commissioned stages.
+type SiteTrackerRow struct {
+	SiteID        string
+	Label         string
+	District      string
+	Mandal        string
+	Dispatched    bool
+	Delivered     bool
+	Installed     bool
+	Commissioned  bool
+	WorkOrderID   string
+	WONumber      string
+	CertificateID string // commissioning record
+}
+
+// SiteTracker is the stage matrix of a project's install-at sites.
+type SiteTracker struct {
+	Rows         []SiteTrackerRow
+	Districts    []string // every district of the project's sites
+	Mandals      []string // mandals of the chosen district, or of every site
+	Dispatched   int
+	Delivered    int
+	Installed    int
+	Commissioned int
+}
+
+// BuildSiteTracker returns the stage of each install-at site of a project,
+// optionally limited to a district and mandal (the district_name and
+// mandal_name address fields).
+//
+// A site is dispatched once a live DC is addressed to it or to its ship-to
+// parent, and delivered once proof of delivery is recorded on such a DC
+// (in full or in part). Installed and commissioned follow the site's work
+// order status and commissioning record.
+func BuildSiteTracker(app core.App, projectID, district, mandal string) (*SiteTracker, error) {
+	sites, err := app.FindRecordsByFilter("addresses", "project = {:pid} && address_type = 'install_at'", "address_code,created", 0, 0, map[string]any{"pid": projectID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch install-at sites: %w", err)
+	}
+
+	dcs, err := app.FindRecordsByFilter("delivery_challans", "project = {:pid} && status != 'draft' && status != 'cancelled'", "", 0, 0, map[string]any{"pid": projectID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch delivery challans: %w", err)
+	}
+	deliveries, err := app.FindRecordsByFilter("dc_deliveries", "dc.project = {:pid}", "", 0, 0, map[string]any{"pid": projectID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch deliveries: %w", err)
+	}
+	deliveredDCs := make(map[string]bool, len(deliveries))
+	for _, d := range deliveries {
+		deliveredDCs[d.GetString("dc")] = true
+	}
+	dispatchedTo := make(map[string]bool)
+	deliveredTo := make(map[string]bool)
+	for _, dc := range dcs {
+		if addr := dc.GetString("ship_to_address"); addr != "" {
+			dispatchedTo[addr] = true
+			deliveredTo[addr] = deliveredTo[addr] || deliveredDCs[dc.Id]
+		}
+	}
+
+	onOrder, err := siteWorkOrders(app, projectID)
+	if err != nil {
+		return nil, err
+	}
+	woNumbers := make(map[string]string)
+	workOrders, err := app.FindRecordsByFilter("work_orders", "project = {:pid}", "", 0, 0, map[string]any{"pid": projectID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch work orders: %w", err)
+	}
+	for _, wo := range workOrders {
+		woNumbers[wo.Id] = wo.GetString("wo_number")
+	}
+
+	certificates := make(map[string]string)
+	records, err := app.FindRecordsByFilter("commissioning_records", "project = {:pid}", "", 0, 0, map[string]any{"pid": projectID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch commissioning records: %w", err)
+	}
+	for _, r := range records {
+		certificates[r.GetString("install_at_address")] = r.Id
+	}
+
+	tracker := &SiteTracker{}
+	for _, site := range sites {
+		siteDistrict := site.GetString("district_name")
+		siteMandal := site.GetString("mandal_name")
+		if siteDistrict != "" && !slices.Contains(tracker.Districts, siteDistrict) {
+			tracker.Districts = append(tracker.Districts, siteDistrict)
+		}
+		if siteMandal != "" && (district == "" || district == siteDistrict) && !slices.Contains(tracker.Mandals, siteMandal) {
+			tracker.Mandals = append(tracker.Mandals, siteMandal)
+		}
+		if (district != "" && siteDistrict != district) || (mandal != "" && siteMandal != mandal) {
+			continue
+		}
+
+		row := SiteTrackerRow{
+			SiteID:        site.Id,
+			Label:         SiteLabel(site),
+			District:      siteDistrict,
+			Mandal:        siteMandal,
+			Dispatched:    dispatchedTo[site.Id] || dispatchedTo[site.GetString("ship_to_parent")],
+			Delivered:     deliveredTo[site.Id] || deliveredTo[site.GetString("ship_to_parent")],
+			CertificateID: certificates[site.Id],
+		}
+		if woSite, ok := onOrder[site.Id]; ok {
+			row.WorkOrderID = woSite.GetString("work_order")
+			row.WONumber = woNumbers[row.WorkOrderID]
+			status := woSite.GetString("status")
+			row.Installed = status == SiteStatusInstalled || status == SiteStatusCommissioned
+			row.Commissioned = status == SiteStatusCommissioned
+		}
+		row.Commissioned = row.Commissioned || row.CertificateID != ""
+
+		for _, stage := range []struct {
+			done  bool
+			count *int
+		}{
+			{row.Dispatched, &tracker.Dispatched},
+			{row.Delivered, &tracker.Delivered},
+			{row.Installed, &tracker.Installed},
+			{row.Commissioned, &tracker.Commissioned},
+		} {
+			if stage.done {
+				*stage.count++
+			}
+		}
+		tracker.Rows = append(tracker.Rows, row)
+	}
+	slices.Sort(tracker.Districts)
+	slices.Sort(tracker.Mandals)
+	return tracker, nil
+}
@@ -0,0 +1,377 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// InvoiceableStatuses are the PO statuses a vendor invoice can be booked against.
+var InvoiceableStatuses = []string{"sent", "acknowledged", "completed"}
+
+// InvoiceTolerance is how far an invoice may drift from the PO and GRNs
+// before it is flagged, in percent.
+type InvoiceTolerance struct {
+	QtyPct  float64 // invoiced qty over GRN accepted qty
+	RatePct float64 // invoiced rate either side of the PO rate
+}
+
+// ProjectInvoiceTolerance reads the matching tolerance configured on a
+// project. Missing or negative values mean an exact match is required.
+func ProjectInvoiceTolerance(app core.App, projectID string) InvoiceTolerance {
+	project, err := app.FindRecordById("projects", projectID)
+	if err != nil {
+		return InvoiceTolerance{}
+	}
+	return InvoiceTolerance{
+		QtyPct:  math.Max(project.GetFloat("invoice_qty_tolerance_pct"), 0),
+		RatePct: math.Max(project.GetFloat("invoice_rate_tolerance_pct"), 0),
+	}
+}
+
+// InvoiceLineParams is what a vendor billed against one PO line item.
+type InvoiceLineParams struct {
+	POLineItemID string
+	Qty          float64
+	Rate         float64
+	GSTPercent   float64
+}
+
+// VendorInvoiceParams holds the inputs for a vendor invoice.
+type VendorInvoiceParams struct {
+	ProjectID       string
+	PurchaseOrderID string
+	InvoiceNumber   string
+	InvoiceDate     string
+	VendorGSTIN     string // as printed on the invoice
+	Remarks         string
+	Lines           []InvoiceLineParams
+}
+
+// VendorInvoiceResult holds the output of CreateVendorInvoice.
+type VendorInvoiceResult struct {
+	InvoiceID   string
+	MatchStatus string   // "matched" or "mismatch"
+	Issues      []string // why the invoice did not match
+}
+
+// InvoiceMatchLine is the three-way match position of one PO line item:
+// what was ordered, what was accepted on GRNs and what has been invoiced.
+type InvoiceMatchLine struct {
+	POLineItemID   string
+	Description    string
+	UOM            string
+	OrderedQty     float64
+	PORate         float64
+	POGSTPercent   float64
+	AcceptedQty    float64
+	InvoicedQty    float64
+	InvoicedAmount float64 // sum of qty × rate over invoice lines
+	GSTMismatch    bool    // an invoice line charged a different GST rate
+}
+
+// InvoicedRate returns the average rate invoiced so far.
+func (m InvoiceMatchLine) InvoicedRate() float64 {
+	if m.InvoicedQty == 0 {
+		return 0
+	}
+	return m.InvoicedAmount / m.InvoicedQty
+}
+
+// rateIssue describes a rate outside the tolerance, or "" if it is within.
+func (m InvoiceMatchLine) rateIssue(rate float64, tol InvoiceTolerance) string {
+	if m.PORate == 0 {
+		if rate == 0 {
+			return ""
+		}
+		return fmt.Sprintf("%s: invoiced at %s but the PO rate is nil", m.Description, FormatINR(rate))
+	}
+	variance := (rate - m.PORate) / m.PORate * 100
+	if math.Abs(variance) <= tol.RatePct+1e-9 {
+		return ""
+	}
+	dir := "above"
+	if variance < 0 {
+		dir = "below"
+	}
+	return fmt.Sprintf("%s: rate %s is %.1f%% %s the PO rate %s",
+		m.Description, FormatINR(rate), math.Abs(variance), dir, FormatINR(m.PORate))
+}
+
+// qtyIssue describes invoiced quantity beyond what was accepted, or "".
+func (m InvoiceMatchLine) qtyIssue(tol InvoiceTolerance) string {
+	if m.InvoicedQty <= m.AcceptedQty*(1+tol.QtyPct/100)+1e-9 {
+		return ""
+	}
+	return fmt.Sprintf("%s: invoiced qty %s exceeds the %s accepted on GRNs",
+		m.Description, formatQty(m.InvoicedQty), formatQty(m.AcceptedQty))
+}
+
+// gstIssue describes a GST rate that differs from the PO line.
+func (m InvoiceMatchLine) gstIssue(gstPercent float64) string {
+	if gstPercent == m.POGSTPercent {
+		return ""
+	}
+	return fmt.Sprintf("%s: GST %s%% does not match the PO's %s%%",
+		m.Description, formatGSTRate(gstPercent), formatGSTRate(m.POGSTPercent))
+}
+
+// Issues returns the mismatches on a PO line across all its invoices.
+func (m InvoiceMatchLine) Issues(tol InvoiceTolerance) []string {
+	if m.InvoicedQty == 0 {
+		return nil
+	}
+	var issues []string
+	if msg := m.rateIssue(m.InvoicedRate(), tol); msg != "" {
+		issues = append(issues, msg)
+	}
+	if msg := m.qtyIssue(tol); msg != "" {
+		issues = append(issues, msg)
+	}
+	if m.GSTMismatch {
+		issues = append(issues, fmt.Sprintf("%s: invoiced at a GST rate other than the PO's %s%%", m.Description, formatGSTRate(m.POGSTPercent)))
+	}
+	return issues
+}
+
+// ComputeInvoiceMatch returns the three-way match position of every line
+// item on a purchase order, in sort order. excludeInvoiceID leaves out an
+// invoice so a new one can be matched on top of the others.
+func ComputeInvoiceMatch(app core.App, poID, excludeInvoiceID string) ([]InvoiceMatchLine, error) {
+	receipt, err := POReceiptStatus(app, poID)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]InvoiceMatchLine, len(receipt))
+	index := make(map[string]int, len(receipt))
+	for i, r := range receipt {
+		lines[i] = InvoiceMatchLine{
+			POLineItemID: r.POLineItemID,
+			Description:  r.Description,
+			UOM:          r.UOM,
+			OrderedQty:   r.Ordered,
+			PORate:       r.Rate,
+			POGSTPercent: r.GSTPercent,
+			AcceptedQty:  r.Accepted,
+		}
+		index[r.POLineItemID] = i
+	}
+
+	invLines, err := app.FindRecordsByFilter("vendor_invoice_line_items", "vendor_invoice.purchase_order = {:poId}", "", 0, 0, map[string]any{"poId": poID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch invoice line items: %w", err)
+	}
+	for _, il := range invLines {
+		if il.GetString("vendor_invoice") == excludeInvoiceID {
+			continue
+		}
+		i, ok := index[il.GetString("po_line_item")]
+		if !ok {
+			continue
+		}
+		lines[i].InvoicedQty += il.GetFloat("qty")
+		lines[i].InvoicedAmount += il.GetFloat("qty") * il.GetFloat("rate")
+		if il.GetFloat("gst_percent") != lines[i].POGSTPercent {
+			lines[i].GSTMismatch = true
+		}
+	}
+	return lines, nil
+}
+
+// CheckInvoiceGSTIN validates the GSTIN printed on an invoice against the
+// one stored on the vendor record. It returns "" when the invoice is fine.
+func CheckInvoiceGSTIN(vendorGSTIN, invoiceGSTIN string, chargesGST bool) string {
+	vendorGSTIN = strings.ToUpper(strings.TrimSpace(vendorGSTIN))
+	invoiceGSTIN = strings.ToUpper(strings.TrimSpace(invoiceGSTIN))
+	switch {
+	case invoiceGSTIN != "" && !ValidateGSTIN(invoiceGSTIN):
+		return "Invalid GSTIN format (expected: 15-character, e.g., 27AAPFU0939F1ZV)"
+	case chargesGST && vendorGSTIN == "":
+		return "GST is charged but the vendor has no GSTIN on record"
+	case chargesGST && invoiceGSTIN == "":
+		return "Enter the GSTIN printed on the invoice"
+	case vendorGSTIN != "" && invoiceGSTIN != "" && invoiceGSTIN != vendorGSTIN:
+		return fmt.Sprintf("Invoice GSTIN %s does not match the vendor's GSTIN %s", invoiceGSTIN, vendorGSTIN)
+	}
+	return ""
+}
+
+// ValidateVendorInvoice checks an invoice before it is recorded. It returns
+// one message per problem, keyed by form field or PO line item ID ("" for
+// problems with the invoice as a whole).
+func ValidateVendorInvoice(app core.App, po, vendor *core.Record, params VendorInvoiceParams) map[string]string {
+	errs := make(map[string]string)
+
+	if strings.TrimSpace(params.InvoiceNumber) == "" {
+		errs["invoice_number"] = "Invoice number is required"
+	} else {
+		dupes, err := app.FindRecordsByFilter("vendor_invoices", "vendor = {:vendor} && invoice_number = {:num}", "", 1, 0,
+			map[string]any{"vendor": vendor.Id, "num": strings.TrimSpace(params.InvoiceNumber)})
+		if err == nil && len(dupes) > 0 {
+			errs["invoice_number"] = fmt.Sprintf("Invoice %s from %s is already recorded", params.InvoiceNumber, vendor.GetString("name"))
+		}
+	}
+	if params.InvoiceDate == "" {
+		errs["invoice_date"] = "Invoice date is required"
+	}
+
+	poLines, err := app.FindRecordsByFilter("po_line_items", "purchase_order = {:poId}", "", 0, 0, map[string]any{"poId": po.Id})
+	if err != nil {
+		errs[""] = "Could not load the purchase order's line items"
+		return errs
+	}
+	descriptions := make(map[string]string, len(poLines))
+	for _, li := range poLines {
+		descriptions[li.Id] = li.GetString("description")
+	}
+
+	anyBilled, chargesGST := false, false
+	for _, l := range params.Lines {
+		desc, ok := descriptions[l.POLineItemID]
+		switch {
+		case !ok:
+			errs[l.POLineItemID] = "Line item does not belong to this purchase order"
+		case l.Qty < 0 || l.Rate < 0 || l.GSTPercent < 0:
+			errs[l.POLineItemID] = fmt.Sprintf("%s: quantities, rates and GST cannot be negative", desc)
+		}
+		if l.Qty > 0 {
+			anyBilled = true
+			if l.GSTPercent > 0 {
+				chargesGST = true
+			}
+		}
+	}
+	if !anyBilled {
+		errs[""] = "Enter the invoiced quantity for at least one item"
+	}
+
+	if msg := CheckInvoiceGSTIN(vendor.GetString("gstin"), params.VendorGSTIN, chargesGST); msg != "" {
+		errs["vendor_gstin"] = msg
+	}
+	return errs
+}
+
+// CreateVendorInvoice records a vendor invoice against a purchase order and
+// matches it three ways against the PO and its GRNs. Lines with nothing
+// invoiced are skipped. An invoice outside the project's tolerance is still
+// recorded, flagged as a mismatch with the reasons in match_notes.
+func CreateVendorInvoice(ctx context.Context, app *pocketbase.PocketBase, params VendorInvoiceParams) (*VendorInvoiceResult, error) {
+	po, err := app.FindRecordById("purchase_orders", params.PurchaseOrderID)
+	if err != nil {
+		return nil, fmt.Errorf("purchase order not found: %w", err)
+	}
+	if po.GetString("project") != params.ProjectID {
+		return nil, fmt.Errorf("purchase order does not belong to this project")
+	}
+	if status := po.GetString("status"); !slices.Contains(InvoiceableStatuses, status) {
+		return nil, fmt.Errorf("invoices cannot be recorded against a %s purchase order", status)
+	}
+	vendor, err := app.FindRecordById("vendors", po.GetString("vendor"))
+	if err != nil {
+		return nil, fmt.Errorf("vendor not found: %w", err)
+	}
+
+	if errs := ValidateVendorInvoice(app, po, vendor, params); len(errs) > 0 {
+		msgs := make([]string, 0, len(errs))
+		for _, m := range errs {
+			msgs = append(msgs, m)
+		}
+		return nil, fmt.Errorf("invalid vendor invoice: %s", strings.Join(msgs, "; "))
+	}
+
+	match, err := ComputeInvoiceMatch(app, po.Id, "")
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*InvoiceMatchLine, len(match))
+	for i := range match {
+		byID[match[i].POLineItemID] = &match[i]
+	}
+
+	// Add this invoice on top of earlier ones, then check each billed line
+	tol := ProjectInvoiceTolerance(app, params.ProjectID)
+	var billed []InvoiceLineParams
+	for _, l := range params.Lines {
+		if l.Qty <= 0 {
+			continue
+		}
+		billed = append(billed, l)
+		m := byID[l.POLineItemID]
+		m.InvoicedQty += l.Qty
+		m.InvoicedAmount += l.Qty * l.Rate
+	}
+	result := &VendorInvoiceResult{MatchStatus: "matched"}
+	for _, l := range billed {
+		m := byID[l.POLineItemID]
+		for _, msg := range []string{m.rateIssue(l.Rate, tol), m.gstIssue(l.GSTPercent), m.qtyIssue(tol)} {
+			if msg != "" {
+				result.Issues = append(result.Issues, msg)
+			}
+		}
+	}
+	if len(result.Issues) > 0 {
+		result.MatchStatus = "mismatch"
+	}
+
+	invoiceCol, err := app.FindCollectionByNameOrId("vendor_invoices")
+	if err != nil {
+		return nil, fmt.Errorf("vendor_invoices collection not found: %w", err)
+	}
+	lineCol, err := app.FindCollectionByNameOrId("vendor_invoice_line_items")
+	if err != nil {
+		return nil, fmt.Errorf("vendor_invoice_line_items collection not found: %w", err)
+	}
+
+	err = app.RunInTransaction(func(txApp core.App) error {
+		inv := core.NewRecord(invoiceCol)
+		inv.Set("project", params.ProjectID)
+		inv.Set("purchase_order", po.Id)
+		inv.Set("vendor", vendor.Id)
+		inv.Set("invoice_number", strings.TrimSpace(params.InvoiceNumber))
+		inv.Set("invoice_date", params.InvoiceDate)
+		inv.Set("vendor_gstin", strings.ToUpper(strings.TrimSpace(params.VendorGSTIN)))
+		inv.Set("match_status", result.MatchStatus)
+		inv.Set("match_notes", strings.Join(result.Issues, "\n"))
+		inv.Set("remarks", params.Remarks)
+		if err := txApp.SaveWithContext(ctx, inv); err != nil {
+			return fmt.Errorf("failed to create vendor invoice: %w", err)
+		}
+		result.InvoiceID = inv.Id
+
+		for _, l := range billed {
+			m := byID[l.POLineItemID]
+			rec := core.NewRecord(lineCol)
+			rec.Set("vendor_invoice", inv.Id)
+			rec.Set("po_line_item", l.POLineItemID)
+			rec.Set("description", m.Description)
+			rec.Set("uom", m.UOM)
+			rec.Set("qty", l.Qty)
+			rec.Set("rate", l.Rate)
+			rec.Set("gst_percent", l.GSTPercent)
+			if err := txApp.SaveWithContext(ctx, rec); err != nil {
+				return fmt.Errorf("failed to create vendor invoice line item: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// VendorInvoiceTotals computes the GST totals of an invoice's lines, split
+// CGST/SGST or IGST the same way as the purchase order.
+func VendorInvoiceTotals(app core.App, po *core.Record, lines []*core.Record) POTotals {
+	calcs := make([]POLineItemCalc, 0, len(lines))
+	for _, l := range lines {
+		calcs = append(calcs, CalcPOLineItem(l.GetFloat("rate"), l.GetFloat("qty"), l.GetFloat("gst_percent")))
+	}
+	return CalcPOTotalsForTaxType(calcs, POTaxType(app, po))
+}
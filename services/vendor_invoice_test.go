@@ -0,0 +1,190 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+const testVendorGSTIN = "27AADCB2230M1ZV"
+
+func TestCreateVendorInvoice_MatchedAndMismatched(t *testing.T) {
+	app, project, po, panel, cable := setupGRNTest(t)
+	ctx := context.Background()
+
+	if _, err := CreateGRN(ctx, app, GRNParams{
+		ProjectID:       project.Id,
+		PurchaseOrderID: po.Id,
+		ReceivedDate:    "2025-06-10",
+		Lines: []GRNLineParams{
+			{POLineItemID: panel.Id, QtyReceived: 10, QtyRejected: 2, RejectionReason: "Cracked"},
+			{POLineItemID: cable.Id, QtyReceived: 50},
+		},
+	}); err != nil {
+		t.Fatalf("CreateGRN failed: %v", err)
+	}
+
+	matched, err := CreateVendorInvoice(ctx, app, VendorInvoiceParams{
+		ProjectID:       project.Id,
+		PurchaseOrderID: po.Id,
+		InvoiceNumber:   "INV-1",
+		InvoiceDate:     "2025-06-12",
+		VendorGSTIN:     testVendorGSTIN,
+		Lines: []InvoiceLineParams{
+			{POLineItemID: panel.Id, Qty: 8, Rate: 500, GSTPercent: 18},
+			{POLineItemID: cable.Id, Qty: 0, Rate: 20, GSTPercent: 18},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateVendorInvoice failed: %v", err)
+	}
+	if matched.MatchStatus != "matched" || len(matched.Issues) != 0 {
+		t.Errorf("expected a clean match, got %s %v", matched.MatchStatus, matched.Issues)
+	}
+	lines, err := app.FindRecordsByFilter("vendor_invoice_line_items", "vendor_invoice = {:id}", "", 0, 0, map[string]any{"id": matched.InvoiceID})
+	if err != nil || len(lines) != 1 {
+		t.Fatalf("expected 1 stored line (zero-qty skipped), got %d (%v)", len(lines), err)
+	}
+
+	// Cable: 60 billed against 50 accepted, 10% over the rate, wrong GST
+	mismatch, err := CreateVendorInvoice(ctx, app, VendorInvoiceParams{
+		ProjectID:       project.Id,
+		PurchaseOrderID: po.Id,
+		InvoiceNumber:   "INV-2",
+		InvoiceDate:     "2025-06-15",
+		VendorGSTIN:     testVendorGSTIN,
+		Lines:           []InvoiceLineParams{{POLineItemID: cable.Id, Qty: 60, Rate: 22, GSTPercent: 12}},
+	})
+	if err != nil {
+		t.Fatalf("CreateVendorInvoice failed: %v", err)
+	}
+	if mismatch.MatchStatus != "mismatch" || len(mismatch.Issues) != 3 {
+		t.Fatalf("expected 3 issues, got %s %v", mismatch.MatchStatus, mismatch.Issues)
+	}
+	inv, err := app.FindRecordById("vendor_invoices", mismatch.InvoiceID)
+	if err != nil {
+		t.Fatalf("invoice not saved: %v", err)
+	}
+	if inv.GetString("match_status") != "mismatch" || !strings.Contains(inv.GetString("match_notes"), "accepted on GRNs") {
+		t.Errorf("unexpected stored match: %s %q", inv.GetString("match_status"), inv.GetString("match_notes"))
+	}
+
+	match, err := ComputeInvoiceMatch(app, po.Id, "")
+	if err != nil {
+		t.Fatalf("ComputeInvoiceMatch failed: %v", err)
+	}
+	if match[0].InvoicedQty != 8 || len(match[0].Issues(InvoiceTolerance{})) != 0 {
+		t.Errorf("panel: expected 8 invoiced and no issues, got %+v", match[0])
+	}
+	if match[1].InvoicedQty != 60 || match[1].InvoicedRate() != 22 || !match[1].GSTMismatch {
+		t.Errorf("cable: unexpected match line %+v", match[1])
+	}
+}
+
+func TestCreateVendorInvoice_WithinTolerance(t *testing.T) {
+	app, project, po, panel, _ := setupGRNTest(t)
+	ctx := context.Background()
+
+	project.Set("invoice_qty_tolerance_pct", 10)
+	project.Set("invoice_rate_tolerance_pct", 5)
+	if err := app.Save(project); err != nil {
+		t.Fatalf("failed to set tolerance: %v", err)
+	}
+	if _, err := CreateGRN(ctx, app, GRNParams{
+		ProjectID:       project.Id,
+		PurchaseOrderID: po.Id,
+		ReceivedDate:    "2025-06-10",
+		Lines:           []GRNLineParams{{POLineItemID: panel.Id, QtyReceived: 10}},
+	}); err != nil {
+		t.Fatalf("CreateGRN failed: %v", err)
+	}
+
+	// 11 against 10 accepted and 520 against 500 are both inside tolerance
+	result, err := CreateVendorInvoice(ctx, app, VendorInvoiceParams{
+		ProjectID:       project.Id,
+		PurchaseOrderID: po.Id,
+		InvoiceNumber:   "INV-T",
+		InvoiceDate:     "2025-06-12",
+		VendorGSTIN:     testVendorGSTIN,
+		Lines:           []InvoiceLineParams{{POLineItemID: panel.Id, Qty: 11, Rate: 520, GSTPercent: 18}},
+	})
+	if err != nil {
+		t.Fatalf("CreateVendorInvoice failed: %v", err)
+	}
+	if result.MatchStatus != "matched" {
+		t.Errorf("expected a match within tolerance, got %v", result.Issues)
+	}
+}
+
+func TestCreateVendorInvoice_Rejected(t *testing.T) {
+	app, project, po, panel, _ := setupGRNTest(t)
+	ctx := context.Background()
+
+	base := VendorInvoiceParams{
+		ProjectID:       project.Id,
+		PurchaseOrderID: po.Id,
+		InvoiceNumber:   "INV-9",
+		InvoiceDate:     "2025-06-12",
+		VendorGSTIN:     testVendorGSTIN,
+		Lines:           []InvoiceLineParams{{POLineItemID: panel.Id, Qty: 1, Rate: 500, GSTPercent: 18}},
+	}
+	if _, err := CreateVendorInvoice(ctx, app, base); err != nil {
+		t.Fatalf("CreateVendorInvoice failed: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*VendorInvoiceParams)
+	}{
+		{"duplicate number", func(p *VendorInvoiceParams) {}},
+		{"missing date", func(p *VendorInvoiceParams) { p.InvoiceNumber = "INV-10"; p.InvoiceDate = "" }},
+		{"other GSTIN", func(p *VendorInvoiceParams) { p.InvoiceNumber = "INV-11"; p.VendorGSTIN = "29AADCB2230M1ZV" }},
+		{"nothing billed", func(p *VendorInvoiceParams) {
+			p.InvoiceNumber = "INV-12"
+			p.Lines = []InvoiceLineParams{{POLineItemID: panel.Id}}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := base
+			tt.modify(&params)
+			if _, err := CreateVendorInvoice(ctx, app, params); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+
+	po.Set("status", "draft")
+	if err := app.Save(po); err != nil {
+		t.Fatalf("failed to update PO status: %v", err)
+	}
+	base.InvoiceNumber = "INV-13"
+	if _, err := CreateVendorInvoice(ctx, app, base); err == nil {
+		t.Error("expected an error for a draft PO")
+	}
+}
+
+func TestCheckInvoiceGSTIN(t *testing.T) {
+	tests := []struct {
+		name           string
+		vendor, billed string
+		chargesGST     bool
+		wantErr        bool
+	}{
+		{"same GSTIN", testVendorGSTIN, testVendorGSTIN, true, false},
+		{"lower case", testVendorGSTIN, strings.ToLower(testVendorGSTIN), true, false},
+		{"different GSTIN", testVendorGSTIN, "29AADCB2230M1ZV", true, true},
+		{"bad format", testVendorGSTIN, "27AADCB", true, true},
+		{"GST without vendor GSTIN", "", testVendorGSTIN, true, true},
+		{"GST without invoice GSTIN", testVendorGSTIN, "", true, true},
+		{"unregistered vendor, no GST", "", "", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CheckInvoiceGSTIN(tt.vendor, tt.billed, tt.chargesGST)
+			if (got != "") != tt.wantErr {
+				t.Errorf("CheckInvoiceGSTIN(%q, %q, %v) = %q", tt.vendor, tt.billed, tt.chargesGST, got)
+			}
+		})
+	}
+}
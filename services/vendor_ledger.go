@@ -0,0 +1,337 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/xuri/excelize/v2"
+)
+
+// VendorLedgerEntry is one line of a vendor ledger. Invoices are credits
+// (they add to what is payable); payments and TDS are debits.
+type VendorLedgerEntry struct {
+	Date      string
+	Kind      string // "invoice", "payment" or "tds"
+	Reference string
+	Narration string
+	Debit     float64
+	Credit    float64
+	Balance   float64 // running payable after this entry
+}
+
+// VendorLedger is a vendor's running account between two dates.
+type VendorLedger struct {
+	VendorID       string
+	VendorName     string
+	VendorGSTIN    string
+	VendorPAN      string
+	From           string // "" for the beginning
+	To             string // "" for today
+	OpeningBalance float64
+	Entries        []VendorLedgerEntry
+	TotalDebit     float64
+	TotalCredit    float64
+	ClosingBalance float64 // payable at the end of the period
+}
+
+// ledgerKindOrder keeps an invoice ahead of a payment made the same day.
+var ledgerKindOrder = map[string]int{"invoice": 0, "payment": 1, "tds": 2}
+
+// BuildVendorLedger builds a vendor's ledger for the period from..to
+// (inclusive dates, "" for open-ended). The opening balance is the vendor's
+// opening_balance plus everything before from. When visible is set, only
+// invoices on the projects it accepts are listed, and a payment counts only
+// for its share allocated to those invoices.
+func BuildVendorLedger(app core.App, vendorID, from, to string, visible func(projectID string) bool) (*VendorLedger, error) {
+	vendor, err := app.FindRecordById("vendors", vendorID)
+	if err != nil {
+		return nil, fmt.Errorf("vendor not found: %w", err)
+	}
+	ledger := &VendorLedger{
+		VendorID:       vendor.Id,
+		VendorName:     vendor.GetString("name"),
+		VendorGSTIN:    vendor.GetString("gstin"),
+		VendorPAN:      vendor.GetString("pan"),
+		From:           from,
+		To:             to,
+		OpeningBalance: vendor.GetFloat("opening_balance"),
+	}
+
+	balances, err := VendorInvoiceBalances(app, vendor.Id)
+	if err != nil {
+		return nil, err
+	}
+	numbers := make(map[string]string, len(balances))
+	var entries []VendorLedgerEntry
+	for _, b := range balances {
+		if visible != nil && !visible(b.ProjectID) {
+			continue
+		}
+		numbers[b.InvoiceID] = b.InvoiceNumber
+		entries = append(entries, VendorLedgerEntry{
+			Date:      b.InvoiceDate,
+			Kind:      "invoice",
+			Reference: b.InvoiceNumber,
+			Narration: fmt.Sprintf("Invoice against %s · %s", b.PONumber, b.ProjectName),
+			Credit:    b.Total,
+		})
+	}
+
+	payments, err := app.FindRecordsByFilter("vendor_payments", "vendor = {:vendor}", "payment_date,created", 0, 0, map[string]any{"vendor": vendor.Id})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch vendor payments: %w", err)
+	}
+	for _, p := range payments {
+		ref := PaymentModeLabel(p.GetString("mode"))
+		if utr := p.GetString("utr_reference"); utr != "" {
+			ref += " " + utr
+		}
+		allocs, err := app.FindRecordsByFilter("vendor_payment_allocations", "vendor_payment = {:id}", "created", 0, 0, map[string]any{"id": p.Id})
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch allocations of payment %s: %w", ref, err)
+		}
+		var settled []string
+		var allocated, shown float64
+		for _, a := range allocs {
+			allocated += a.GetFloat("amount")
+			if number, ok := numbers[a.GetString("vendor_invoice")]; ok {
+				settled = append(settled, number)
+				shown += a.GetFloat("amount")
+			}
+		}
+		share := 1.0
+		if visible != nil && shown < allocated {
+			if shown == 0 {
+				continue
+			}
+			share = shown / allocated
+		}
+		entries = append(entries, VendorLedgerEntry{
+			Date:      p.GetString("payment_date"),
+			Kind:      "payment",
+			Reference: ref,
+			Narration: "Payment against " + joinNonEmpty(settled, ", "),
+			Debit:     p.GetFloat("net_amount") * share,
+		})
+		if tds := p.GetFloat("tds_amount") * share; tds > 0 {
+			section := ""
+			if s, err := app.FindRecordById("tds_sections", p.GetString("tds_section")); err == nil {
+				section = " u/s " + s.GetString("section")
+			}
+			entries = append(entries, VendorLedgerEntry{
+				Date:      p.GetString("payment_date"),
+				Kind:      "tds",
+				Reference: ref,
+				Narration: fmt.Sprintf("TDS%s @ %s%%", section, formatGSTRate(p.GetFloat("tds_rate"))),
+				Debit:     tds,
+			})
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Date != entries[j].Date {
+			return entries[i].Date < entries[j].Date
+		}
+		return ledgerKindOrder[entries[i].Kind] < ledgerKindOrder[entries[j].Kind]
+	})
+
+	balance := ledger.OpeningBalance
+	for _, e := range entries {
+		// Dates are stored as YYYY-MM-DD, so they compare as strings
+		date := e.Date
+		if len(date) > 10 {
+			date = date[:10]
+		}
+		if from != "" && date < from {
+			ledger.OpeningBalance += e.Credit - e.Debit
+			balance = ledger.OpeningBalance
+			continue
+		}
+		if to != "" && date > to {
+			continue
+		}
+		balance += e.Credit - e.Debit
+		e.Balance = balance
+		ledger.TotalDebit += e.Debit
+		ledger.TotalCredit += e.Credit
+		ledger.Entries = append(ledger.Entries, e)
+	}
+	ledger.ClosingBalance = balance
+	return ledger, nil
+}
+
+// ledgerKindLabels are the display names of ledger entry kinds.
+var ledgerKindLabels = map[string]string{
+	"invoice": "Invoice",
+	"payment": "Payment",
+	"tds":     "TDS",
+}
+
+// LedgerKindLabel returns the display name of a ledger entry kind.
+func LedgerKindLabel(kind string) string {
+	return ledgerKindLabels[kind]
+}
+
+// GenerateVendorLedgerExcel creates an Excel workbook of a vendor ledger.
+func GenerateVendorLedgerExcel(l *VendorLedger) ([]byte, error) {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	sheetName := "Ledger"
+	defaultSheet := f.GetSheetName(0)
+	if err := f.SetSheetName(defaultSheet, sheetName); err != nil {
+		return nil, fmt.Errorf("set sheet name: %w", err)
+	}
+
+	columns := []string{"A", "B", "C", "D", "E", "F", "G"}
+	lastCol := columns[len(columns)-1]
+	widths := []float64{12, 10, 24, 50, 16, 16, 18}
+	for i, col := range columns {
+		if err := f.SetColWidth(sheetName, col, col, widths[i]); err != nil {
+			return nil, fmt.Errorf("set col width %s: %w", col, err)
+		}
+	}
+
+	// ── Styles ──────────────────────────────────────────────────────────
+
+	titleStyle, err := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true, Size: 16},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create title style: %w", err)
+	}
+	subtitleStyle, err := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Size: 11},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create subtitle style: %w", err)
+	}
+	headerStyle, err := f.NewStyle(&excelize.Style{
+		Font:      &excelize.Font{Bold: true, Color: "#FFFFFF", Size: 11},
+		Fill:      excelize.Fill{Type: "pattern", Color: []string{"#333333"}, Pattern: 1},
+		Alignment: &excelize.Alignment{Horizontal: "center", Vertical: "center"},
+		Border:    thinBorders(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create header style: %w", err)
+	}
+	textStyle, err := f.NewStyle(&excelize.Style{
+		Font:   &excelize.Font{Size: 10},
+		Border: thinBorders(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create text style: %w", err)
+	}
+	amountStyle, err := f.NewStyle(&excelize.Style{
+		Font:   &excelize.Font{Size: 10},
+		NumFmt: 4, // #,##0.00
+		Border: thinBorders(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create amount style: %w", err)
+	}
+	boldTextStyle, err := f.NewStyle(&excelize.Style{
+		Font:   &excelize.Font{Bold: true, Size: 10},
+		Border: thinBorders(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create bold text style: %w", err)
+	}
+	boldAmountStyle, err := f.NewStyle(&excelize.Style{
+		Font:   &excelize.Font{Bold: true, Size: 10},
+		NumFmt: 4,
+		Border: thinBorders(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create bold amount style: %w", err)
+	}
+
+	// ── Header Rows (1-3) ───────────────────────────────────────────────
+
+	if err := f.MergeCell(sheetName, "A1", lastCol+"1"); err != nil {
+		return nil, fmt.Errorf("merge title: %w", err)
+	}
+	f.SetCellValue(sheetName, "A1", sanitizeExcelCell("Vendor Ledger — "+l.VendorName))
+	f.SetCellStyle(sheetName, "A1", lastCol+"1", titleStyle)
+
+	ids := "GSTIN: " + l.VendorGSTIN
+	if l.VendorPAN != "" {
+		ids += "   PAN: " + l.VendorPAN
+	}
+	if err := f.MergeCell(sheetName, "A2", lastCol+"2"); err != nil {
+		return nil, fmt.Errorf("merge ids: %w", err)
+	}
+	f.SetCellValue(sheetName, "A2", sanitizeExcelCell(ids))
+	f.SetCellStyle(sheetName, "A2", lastCol+"2", subtitleStyle)
+
+	period := "Period: " + ledgerDateOr(l.From, "beginning") + " to " + ledgerDateOr(l.To, "date")
+	if err := f.MergeCell(sheetName, "A3", lastCol+"3"); err != nil {
+		return nil, fmt.Errorf("merge period: %w", err)
+	}
+	f.SetCellValue(sheetName, "A3", period)
+	f.SetCellStyle(sheetName, "A3", lastCol+"3", subtitleStyle)
+
+	// ── Row 5: Column Headers ───────────────────────────────────────────
+
+	headers := []string{"Date", "Type", "Reference", "Narration", "Debit", "Credit", "Balance"}
+	for i, h := range headers {
+		f.SetCellValue(sheetName, cell(columns[i], 5), h)
+	}
+	f.SetCellStyle(sheetName, "A5", lastCol+"5", headerStyle)
+
+	// ── Opening, entries, totals and closing ────────────────────────────
+
+	row := 6
+	f.SetCellValue(sheetName, cell("D", row), "Opening balance")
+	f.SetCellValue(sheetName, cell("G", row), l.OpeningBalance)
+	f.SetCellStyle(sheetName, cell("A", row), cell("D", row), boldTextStyle)
+	f.SetCellStyle(sheetName, cell("E", row), cell("G", row), boldAmountStyle)
+	row++
+
+	for _, e := range l.Entries {
+		f.SetCellValue(sheetName, cell("A", row), e.Date)
+		f.SetCellValue(sheetName, cell("B", row), LedgerKindLabel(e.Kind))
+		f.SetCellValue(sheetName, cell("C", row), sanitizeExcelCell(e.Reference))
+		f.SetCellValue(sheetName, cell("D", row), sanitizeExcelCell(e.Narration))
+		if e.Debit != 0 {
+			f.SetCellValue(sheetName, cell("E", row), e.Debit)
+		}
+		if e.Credit != 0 {
+			f.SetCellValue(sheetName, cell("F", row), e.Credit)
+		}
+		f.SetCellValue(sheetName, cell("G", row), e.Balance)
+		f.SetCellStyle(sheetName, cell("A", row), cell("D", row), textStyle)
+		f.SetCellStyle(sheetName, cell("E", row), cell("G", row), amountStyle)
+		row++
+	}
+
+	f.SetCellValue(sheetName, cell("D", row), "Total")
+	f.SetCellValue(sheetName, cell("E", row), l.TotalDebit)
+	f.SetCellValue(sheetName, cell("F", row), l.TotalCredit)
+	f.SetCellStyle(sheetName, cell("A", row), cell("D", row), boldTextStyle)
+	f.SetCellStyle(sheetName, cell("E", row), cell("G", row), boldAmountStyle)
+	row++
+
+	f.SetCellValue(sheetName, cell("D", row), "Closing balance payable")
+	f.SetCellValue(sheetName, cell("G", row), l.ClosingBalance)
+	f.SetCellStyle(sheetName, cell("A", row), cell("D", row), boldTextStyle)
+	f.SetCellStyle(sheetName, cell("E", row), cell("G", row), boldAmountStyle)
+
+	// ── Write to buffer ─────────────────────────────────────────────────
+
+	var buf bytes.Buffer
+	if err := f.Write(&buf); err != nil {
+		return nil, fmt.Errorf("write excel: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// ledgerDateOr returns date, or fallback if it is empty.
+func ledgerDateOr(date, fallback string) string {
+	if date == "" {
+		return fallback
+	}
+	return date
+}
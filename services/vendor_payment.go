@@ -0,0 +1,267 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// PaymentModes are the ways a vendor can be paid.
+var PaymentModes = []string{"neft", "rtgs", "imps", "upi", "cheque", "cash"}
+
+// InvoiceBalance is a vendor invoice's value and how much of it payments
+// have settled so far.
+type InvoiceBalance struct {
+	InvoiceID     string
+	InvoiceNumber string
+	InvoiceDate   string
+	ProjectID     string
+	ProjectName   string
+	PONumber      string
+	Total         float64 // grand total including GST
+	TaxableValue  float64 // total before tax
+	Settled       float64 // allocated on payments, TDS included
+}
+
+// Outstanding returns the amount of the invoice still to be settled.
+func (b InvoiceBalance) Outstanding() float64 {
+	return b.Total - b.Settled
+}
+
+// VendorInvoiceBalances returns every invoice of a vendor, oldest first,
+// with the amount settled against each.
+func VendorInvoiceBalances(app core.App, vendorID string) ([]InvoiceBalance, error) {
+	invoices, err := app.FindRecordsByFilter("vendor_invoices", "vendor = {:vendor}", "invoice_date,created", 0, 0, map[string]any{"vendor": vendorID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch vendor invoices: %w", err)
+	}
+	allocs, err := app.FindRecordsByFilter("vendor_payment_allocations", "vendor_invoice.vendor = {:vendor}", "", 0, 0, map[string]any{"vendor": vendorID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch payment allocations: %w", err)
+	}
+	settled := make(map[string]float64, len(allocs))
+	for _, a := range allocs {
+		settled[a.GetString("vendor_invoice")] += a.GetFloat("amount")
+	}
+
+	balances := make([]InvoiceBalance, 0, len(invoices))
+	for _, inv := range invoices {
+		b := InvoiceBalance{
+			InvoiceID:     inv.Id,
+			InvoiceNumber: inv.GetString("invoice_number"),
+			InvoiceDate:   inv.GetString("invoice_date"),
+			ProjectID:     inv.GetString("project"),
+			Settled:       settled[inv.Id],
+		}
+		if p, err := app.FindRecordById("projects", b.ProjectID); err == nil {
+			b.ProjectName = p.GetString("name")
+		}
+		po, err := app.FindRecordById("purchase_orders", inv.GetString("purchase_order"))
+		if err != nil {
+			return nil, fmt.Errorf("purchase order of invoice %s not found: %w", b.InvoiceNumber, err)
+		}
+		b.PONumber = po.GetString("po_number")
+		lines, err := app.FindRecordsByFilter("vendor_invoice_line_items", "vendor_invoice = {:id}", "", 0, 0, map[string]any{"id": inv.Id})
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch line items of invoice %s: %w", b.InvoiceNumber, err)
+		}
+		totals := VendorInvoiceTotals(app, po, lines)
+		b.Total = totals.GrandTotal
+		b.TaxableValue = totals.TotalBeforeTax
+		balances = append(balances, b)
+	}
+	return balances, nil
+}
+
+// PaymentAllocationParams settles part of one vendor invoice.
+type PaymentAllocationParams struct {
+	VendorInvoiceID string
+	Amount          float64 // amount of the invoice settled, TDS included
+}
+
+// VendorPaymentParams holds the inputs for a vendor payment.
+type VendorPaymentParams struct {
+	VendorID     string
+	PaymentDate  string
+	Mode         string
+	UTRReference string
+	TDSSectionID string // "" for no TDS
+	Remarks      string
+	Allocations  []PaymentAllocationParams
+}
+
+// VendorPaymentResult holds the output of CreateVendorPayment.
+type VendorPaymentResult struct {
+	PaymentID   string
+	GrossAmount float64 // total settled across invoices
+	TDSAmount   float64
+	NetAmount   float64 // paid out to the vendor
+}
+
+// CalcPaymentTDS returns the TDS on a payment. TDS applies to the value
+// before GST, so each allocation contributes its taxable share of the
+// invoice; the result is rounded to the nearest rupee.
+func CalcPaymentTDS(balances map[string]InvoiceBalance, allocs []PaymentAllocationParams, ratePercent float64) float64 {
+	var base float64
+	for _, a := range allocs {
+		b, ok := balances[a.VendorInvoiceID]
+		if !ok || b.Total == 0 {
+			continue
+		}
+		base += a.Amount * b.TaxableValue / b.Total
+	}
+	return math.Round(base * ratePercent / 100)
+}
+
+// ValidateVendorPayment checks a payment before it is recorded. It returns
+// one message per problem, keyed by form field or vendor invoice ID ("" for
+// problems with the payment as a whole).
+func ValidateVendorPayment(app core.App, params VendorPaymentParams, balances map[string]InvoiceBalance) map[string]string {
+	errs := make(map[string]string)
+
+	if params.PaymentDate == "" {
+		errs["payment_date"] = "Payment date is required"
+	}
+	if !slices.Contains(PaymentModes, params.Mode) {
+		errs["mode"] = "Choose a payment mode"
+	} else if params.Mode != "cash" && strings.TrimSpace(params.UTRReference) == "" {
+		errs["utr_reference"] = "UTR / cheque reference is required"
+	}
+	if params.TDSSectionID != "" {
+		section, err := app.FindRecordById("tds_sections", params.TDSSectionID)
+		if err != nil || !section.GetBool("active") {
+			errs["tds_section"] = "Choose an active TDS section"
+		}
+	}
+
+	allocated := false
+	for _, a := range params.Allocations {
+		b, ok := balances[a.VendorInvoiceID]
+		switch {
+		case !ok:
+			errs[a.VendorInvoiceID] = "Invoice does not belong to this vendor"
+		case a.Amount < 0:
+			errs[a.VendorInvoiceID] = fmt.Sprintf("%s: amount cannot be negative", b.InvoiceNumber)
+		case a.Amount > b.Outstanding()+0.005:
+			errs[a.VendorInvoiceID] = fmt.Sprintf("%s: only %s is outstanding", b.InvoiceNumber, FormatINR(b.Outstanding()))
+		}
+		if a.Amount > 0 {
+			allocated = true
+		}
+	}
+	if !allocated {
+		errs[""] = "Allocate the payment to at least one invoice"
+	}
+	return errs
+}
+
+// CreateVendorPayment records a payment to a vendor, allocated across the
+// vendor's invoices, with TDS deducted at the rate of the chosen section.
+// Allocations of zero are skipped.
+func CreateVendorPayment(ctx context.Context, app *pocketbase.PocketBase, params VendorPaymentParams) (*VendorPaymentResult, error) {
+	vendor, err := app.FindRecordById("vendors", params.VendorID)
+	if err != nil {
+		return nil, fmt.Errorf("vendor not found: %w", err)
+	}
+	list, err := VendorInvoiceBalances(app, vendor.Id)
+	if err != nil {
+		return nil, err
+	}
+	balances := make(map[string]InvoiceBalance, len(list))
+	for _, b := range list {
+		balances[b.InvoiceID] = b
+	}
+
+	if errs := ValidateVendorPayment(app, params, balances); len(errs) > 0 {
+		msgs := make([]string, 0, len(errs))
+		for _, m := range errs {
+			msgs = append(msgs, m)
+		}
+		return nil, fmt.Errorf("invalid vendor payment: %s", strings.Join(msgs, "; "))
+	}
+
+	var allocs []PaymentAllocationParams
+	result := &VendorPaymentResult{}
+	for _, a := range params.Allocations {
+		if a.Amount <= 0 {
+			continue
+		}
+		allocs = append(allocs, a)
+		result.GrossAmount += a.Amount
+	}
+	var tdsRate float64
+	if params.TDSSectionID != "" {
+		section, err := app.FindRecordById("tds_sections", params.TDSSectionID)
+		if err != nil {
+			return nil, fmt.Errorf("TDS section not found: %w", err)
+		}
+		tdsRate = section.GetFloat("rate_percent")
+		result.TDSAmount = CalcPaymentTDS(balances, allocs, tdsRate)
+	}
+	result.NetAmount = result.GrossAmount - result.TDSAmount
+
+	paymentCol, err := app.FindCollectionByNameOrId("vendor_payments")
+	if err != nil {
+		return nil, fmt.Errorf("vendor_payments collection not found: %w", err)
+	}
+	allocCol, err := app.FindCollectionByNameOrId("vendor_payment_allocations")
+	if err != nil {
+		return nil, fmt.Errorf("vendor_payment_allocations collection not found: %w", err)
+	}
+
+	err = app.RunInTransaction(func(txApp core.App) error {
+		payment := core.NewRecord(paymentCol)
+		payment.Set("vendor", vendor.Id)
+		payment.Set("payment_date", params.PaymentDate)
+		payment.Set("mode", params.Mode)
+		payment.Set("utr_reference", strings.TrimSpace(params.UTRReference))
+		payment.Set("gross_amount", result.GrossAmount)
+		payment.Set("tds_section", params.TDSSectionID)
+		payment.Set("tds_rate", tdsRate)
+		payment.Set("tds_amount", result.TDSAmount)
+		payment.Set("net_amount", result.NetAmount)
+		payment.Set("remarks", params.Remarks)
+		if err := txApp.SaveWithContext(ctx, payment); err != nil {
+			return fmt.Errorf("failed to create vendor payment: %w", err)
+		}
+		result.PaymentID = payment.Id
+
+		for _, a := range allocs {
+			rec := core.NewRecord(allocCol)
+			rec.Set("vendor_payment", payment.Id)
+			rec.Set("vendor_invoice", a.VendorInvoiceID)
+			rec.Set("amount", a.Amount)
+			if err := txApp.SaveWithContext(ctx, rec); err != nil {
+				return fmt.Errorf("failed to create payment allocation: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// paymentModeLabels are the display names of payment modes.
+var paymentModeLabels = map[string]string{
+	"neft":   "NEFT",
+	"rtgs":   "RTGS",
+	"imps":   "IMPS",
+	"upi":    "UPI",
+	"cheque": "Cheque",
+	"cash":   "Cash",
+}
+
+// PaymentModeLabel returns the display name of a payment mode.
+func PaymentModeLabel(mode string) string {
+	if label, ok := paymentModeLabels[mode]; ok {
+		return label
+	}
+	return mode
+}
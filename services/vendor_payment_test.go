@@ -0,0 +1,216 @@
+package services
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/xuri/excelize/v2"
+
+	"projectcreation/testhelpers"
+)
+
+// setupPaymentTest receives a PO in full and records two invoices against
+// it: INV-1 for the panels (₹5,900) and INV-2 for the cable (₹2,360).
+func setupPaymentTest(t *testing.T) (*pocketbase.PocketBase, *core.Record, *core.Record, *core.Record) {
+	t.Helper()
+	app, project, po, panel, cable := setupGRNTest(t)
+	ctx := context.Background()
+
+	if _, err := CreateGRN(ctx, app, GRNParams{
+		ProjectID:       project.Id,
+		PurchaseOrderID: po.Id,
+		ReceivedDate:    "2025-06-10",
+		Lines: []GRNLineParams{
+			{POLineItemID: panel.Id, QtyReceived: 10},
+			{POLineItemID: cable.Id, QtyReceived: 100},
+		},
+	}); err != nil {
+		t.Fatalf("CreateGRN failed: %v", err)
+	}
+
+	invoice := func(number, date, lineID string, qty, rate float64) *core.Record {
+		res, err := CreateVendorInvoice(ctx, app, VendorInvoiceParams{
+			ProjectID:       project.Id,
+			PurchaseOrderID: po.Id,
+			InvoiceNumber:   number,
+			InvoiceDate:     date,
+			VendorGSTIN:     testVendorGSTIN,
+			Lines:           []InvoiceLineParams{{POLineItemID: lineID, Qty: qty, Rate: rate, GSTPercent: 18}},
+		})
+		if err != nil {
+			t.Fatalf("CreateVendorInvoice %s failed: %v", number, err)
+		}
+		rec, _ := app.FindRecordById("vendor_invoices", res.InvoiceID)
+		return rec
+	}
+	inv1 := invoice("INV-1", "2025-06-12", panel.Id, 10, 500)
+	inv2 := invoice("INV-2", "2025-06-15", cable.Id, 100, 20)
+
+	vendor, err := app.FindRecordById("vendors", po.GetString("vendor"))
+	if err != nil {
+		t.Fatalf("vendor not found: %v", err)
+	}
+	return app, vendor, inv1, inv2
+}
+
+func TestCreateVendorPayment_AllocatesWithTDS(t *testing.T) {
+	app, vendor, inv1, inv2 := setupPaymentTest(t)
+	section := saveTestRecord(t, app, "tds_sections", map[string]any{
+		"section": "194C", "description": "Contractors", "rate_percent": 2, "active": true,
+	})
+
+	result, err := CreateVendorPayment(context.Background(), app, VendorPaymentParams{
+		VendorID:     vendor.Id,
+		PaymentDate:  "2025-06-20",
+		Mode:         "neft",
+		UTRReference: "UTR123",
+		TDSSectionID: section.Id,
+		Allocations: []PaymentAllocationParams{
+			{VendorInvoiceID: inv1.Id, Amount: 5900},
+			{VendorInvoiceID: inv2.Id, Amount: 1180},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateVendorPayment failed: %v", err)
+	}
+	// TDS base is the pre-GST share: 5,000 + 1,000 = 6,000 @ 2%
+	if result.GrossAmount != 7080 || result.TDSAmount != 120 || result.NetAmount != 6960 {
+		t.Errorf("expected 7080 gross, 120 TDS, 6960 net, got %+v", result)
+	}
+
+	balances, err := VendorInvoiceBalances(app, vendor.Id)
+	if err != nil {
+		t.Fatalf("VendorInvoiceBalances failed: %v", err)
+	}
+	if balances[0].Outstanding() != 0 || balances[1].Outstanding() != 1180 {
+		t.Errorf("expected 0 and 1180 outstanding, got %v and %v", balances[0].Outstanding(), balances[1].Outstanding())
+	}
+}
+
+func TestCreateVendorPayment_Rejected(t *testing.T) {
+	app, vendor, inv1, _ := setupPaymentTest(t)
+	ctx := context.Background()
+
+	base := VendorPaymentParams{
+		VendorID:     vendor.Id,
+		PaymentDate:  "2025-06-20",
+		Mode:         "upi",
+		UTRReference: "UPI-1",
+		Allocations:  []PaymentAllocationParams{{VendorInvoiceID: inv1.Id, Amount: 1000}},
+	}
+	tests := []struct {
+		name   string
+		modify func(*VendorPaymentParams)
+	}{
+		{"over allocation", func(p *VendorPaymentParams) { p.Allocations[0].Amount = 6000 }},
+		{"missing reference", func(p *VendorPaymentParams) { p.UTRReference = "" }},
+		{"unknown mode", func(p *VendorPaymentParams) { p.Mode = "barter" }},
+		{"nothing allocated", func(p *VendorPaymentParams) { p.Allocations[0].Amount = 0 }},
+		{"unknown TDS section", func(p *VendorPaymentParams) { p.TDSSectionID = "missing" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := base
+			params.Allocations = []PaymentAllocationParams{base.Allocations[0]}
+			tt.modify(&params)
+			if _, err := CreateVendorPayment(ctx, app, params); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+
+	// Cash needs no reference
+	params := base
+	params.Mode, params.UTRReference = "cash", ""
+	if _, err := CreateVendorPayment(ctx, app, params); err != nil {
+		t.Errorf("cash payment failed: %v", err)
+	}
+}
+
+func TestBuildVendorLedger(t *testing.T) {
+	app, vendor, inv1, inv2 := setupPaymentTest(t)
+	vendor.Set("opening_balance", 1000)
+	if err := app.Save(vendor); err != nil {
+		t.Fatalf("failed to set opening balance: %v", err)
+	}
+	section := saveTestRecord(t, app, "tds_sections", map[string]any{
+		"section": "194C", "rate_percent": 2, "active": true,
+	})
+	if _, err := CreateVendorPayment(context.Background(), app, VendorPaymentParams{
+		VendorID:     vendor.Id,
+		PaymentDate:  "2025-06-20",
+		Mode:         "rtgs",
+		UTRReference: "RTGS9",
+		TDSSectionID: section.Id,
+		Allocations: []PaymentAllocationParams{
+			{VendorInvoiceID: inv1.Id, Amount: 5900},
+			{VendorInvoiceID: inv2.Id, Amount: 1180},
+		},
+	}); err != nil {
+		t.Fatalf("CreateVendorPayment failed: %v", err)
+	}
+
+	full, err := BuildVendorLedger(app, vendor.Id, "", "", nil)
+	if err != nil {
+		t.Fatalf("BuildVendorLedger failed: %v", err)
+	}
+	if len(full.Entries) != 4 {
+		t.Fatalf("expected invoice, invoice, payment, TDS entries, got %+v", full.Entries)
+	}
+	kinds := []string{"invoice", "invoice", "payment", "tds"}
+	for i, k := range kinds {
+		if full.Entries[i].Kind != k {
+			t.Errorf("entry %d: expected %s, got %s", i, k, full.Entries[i].Kind)
+		}
+	}
+	if full.OpeningBalance != 1000 || full.TotalCredit != 8260 || full.TotalDebit != 7080 || full.ClosingBalance != 2180 {
+		t.Errorf("unexpected totals: opening %v, credit %v, debit %v, closing %v",
+			full.OpeningBalance, full.TotalCredit, full.TotalDebit, full.ClosingBalance)
+	}
+
+	period, err := BuildVendorLedger(app, vendor.Id, "2025-06-14", "2025-06-19", nil)
+	if err != nil {
+		t.Fatalf("BuildVendorLedger failed: %v", err)
+	}
+	if period.OpeningBalance != 6900 || len(period.Entries) != 1 || period.ClosingBalance != 9260 {
+		t.Errorf("expected opening 6900, one entry, closing 9260, got %v, %d, %v",
+			period.OpeningBalance, len(period.Entries), period.ClosingBalance)
+	}
+
+	xlsx, err := GenerateVendorLedgerExcel(full)
+	if err != nil {
+		t.Fatalf("GenerateVendorLedgerExcel failed: %v", err)
+	}
+	f, err := excelize.OpenReader(bytesReader(xlsx))
+	if err != nil {
+		t.Fatalf("result is not valid Excel: %v", err)
+	}
+	defer f.Close()
+	if title, _ := f.GetCellValue("Ledger", "A1"); title != "Vendor Ledger — GRN Vendor" {
+		t.Errorf("unexpected title %q", title)
+	}
+	if ref, _ := f.GetCellValue("Ledger", "C9"); ref != "RTGS RTGS9" {
+		t.Errorf("expected the payment reference in C9, got %q", ref)
+	}
+
+	// Someone who cannot see INV-2's project sees INV-1 and the part of the
+	// payment that settled it
+	other := testhelpers.CreateTestProject(t, app, "Other Project")
+	inv2.Set("project", other.Id)
+	if err := app.Save(inv2); err != nil {
+		t.Fatalf("failed to move INV-2: %v", err)
+	}
+	scoped, err := BuildVendorLedger(app, vendor.Id, "", "", func(projectID string) bool { return projectID == inv1.GetString("project") })
+	if err != nil {
+		t.Fatalf("BuildVendorLedger failed: %v", err)
+	}
+	if len(scoped.Entries) != 3 || scoped.Entries[0].Reference != "INV-1" || scoped.Entries[1].Narration != "Payment against INV-1" {
+		t.Fatalf("expected INV-1 with its payment and TDS, got %+v", scoped.Entries)
+	}
+	if math.Abs(scoped.TotalCredit-5900) > 0.01 || math.Abs(scoped.TotalDebit-5900) > 0.01 || math.Abs(scoped.ClosingBalance-1000) > 0.01 {
+		t.Errorf("unexpected scoped totals: credit %v, debit %v, closing %v", scoped.TotalCredit, scoped.TotalDebit, scoped.ClosingBalance)
+	}
+}
@@ -0,0 +1,429 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// Installation progress of a work order site, in order.
+const (
+	SiteStatusPending      = "pending"
+	SiteStatusInProgress   = "in_progress"
+	SiteStatusInstalled    = "installed"
+	SiteStatusCommissioned = "commissioned"
+)
+
+// SiteStatuses lists the installation statuses in progress order.
+var SiteStatuses = []string{SiteStatusPending, SiteStatusInProgress, SiteStatusInstalled, SiteStatusCommissioned}
+
+var siteStatusLabels = map[string]string{
+	SiteStatusPending:      "Pending",
+	SiteStatusInProgress:   "In progress",
+	SiteStatusInstalled:    "Installed",
+	SiteStatusCommissioned: "Commissioned",
+}
+
+// SiteStatusLabel returns the display name of an installation status.
+func SiteStatusLabel(status string) string {
+	if label, ok := siteStatusLabels[status]; ok {
+		return label
+	}
+	return status
+}
+
+// SiteLabel returns a short label for an install-at site: its code, its
+// name (company, else contact person) and its city.
+func SiteLabel(rec *core.Record) string {
+	data := ReadAddressData(rec)
+	name := data["company_name"]
+	if name == "" {
+		name = data["contact_person"]
+	}
+	var parts []string
+	for _, p := range []string{rec.GetString("address_code"), name, data["city"]} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	if len(parts) == 0 {
+		return rec.Id
+	}
+	return strings.Join(parts, " · ")
+}
+
+// WorkOrderItem is a BOQ service item that can be put on a work order.
+type WorkOrderItem struct {
+	SourceItemType string // "sub_item" or "sub_sub_item"
+	SourceItemID   string
+	Description    string
+	UOM            string
+	Qty            float64 // total project quantity
+	Rate           float64 // budgeted price, the default installer rate
+}
+
+// ServiceBOQItems returns the service sub items and sub sub items of the
+// project's BOQs, sub items first.
+func ServiceBOQItems(app core.App, projectID string) ([]WorkOrderItem, error) {
+	quantities, err := projectBOQQuantities(app, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []WorkOrderItem
+	for _, src := range []struct{ collection, itemType, filter string }{
+		{"sub_items", "sub_item", "main_item.boq.project = {:pid} && type = 'service'"},
+		{"sub_sub_items", "sub_sub_item", "sub_item.main_item.boq.project = {:pid} && type = 'service'"},
+	} {
+		records, err := app.FindRecordsByFilter(src.collection, src.filter, "sort_order", 0, 0, map[string]any{"pid": projectID})
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch BOQ service items: %w", err)
+		}
+		for _, r := range records {
+			item := WorkOrderItem{
+				SourceItemType: src.itemType,
+				SourceItemID:   r.Id,
+				Description:    r.GetString("description"),
+				UOM:            r.GetString("uom"),
+				Rate:           r.GetFloat("budgeted_price"),
+			}
+			if q, ok := quantities[DispatchItemKey(src.itemType, r.Id)]; ok {
+				item.Qty = q.Qty
+			}
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+// siteWorkOrders maps each install-at site of a project that is on a work
+// order to its work order site record.
+func siteWorkOrders(app core.App, projectID string) (map[string]*core.Record, error) {
+	sites, err := app.FindRecordsByFilter("work_order_sites", "work_order.project = {:pid}", "", 0, 0, map[string]any{"pid": projectID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch work order sites: %w", err)
+	}
+	bySite := make(map[string]*core.Record, len(sites))
+	for _, s := range sites {
+		bySite[s.GetString("install_at_address")] = s
+	}
+	return bySite, nil
+}
+
+// WorkOrderLineParams is a BOQ service item to be carried out at every site
+// of a work order.
+type WorkOrderLineParams struct {
+	SourceItemType string
+	SourceItemID   string
+	QtyPerSite     float64
+	Rate           float64
+}
+
+// WorkOrderParams holds the inputs for an installation work order.
+type WorkOrderParams struct {
+	ProjectID   string
+	InstallerID string // a vendor
+	WODate      string
+	TargetDate  string
+	Remarks     string
+	Lines       []WorkOrderLineParams
+	SiteIDs     []string // install-at addresses
+}
+
+// ValidateWorkOrder checks a work order before it is created. Errors are
+// keyed by form field name; line errors by source item ID. A site can only
+// be on one work order.
+func ValidateWorkOrder(app core.App, params WorkOrderParams) map[string]string {
+	errs := make(map[string]string)
+
+	if params.InstallerID == "" {
+		errs["installer"] = "Choose an installer"
+	} else if _, err := app.FindRecordById("vendors", params.InstallerID); err != nil {
+		errs["installer"] = "Installer not found"
+	}
+	if _, err := time.Parse("2006-01-02", params.WODate); err != nil {
+		errs["wo_date"] = "Work order date is required"
+	}
+	if params.TargetDate != "" {
+		if _, err := time.Parse("2006-01-02", params.TargetDate); err != nil {
+			errs["target_date"] = "Target date must be a valid date"
+		} else if params.TargetDate < params.WODate {
+			errs["target_date"] = "Target date cannot be before the work order date"
+		}
+	}
+
+	items, err := ServiceBOQItems(app, params.ProjectID)
+	if err != nil {
+		errs["lines"] = "Could not load the BOQ service items"
+		return errs
+	}
+	known := make(map[string]WorkOrderItem, len(items))
+	for _, it := range items {
+		known[DispatchItemKey(it.SourceItemType, it.SourceItemID)] = it
+	}
+	if len(params.Lines) == 0 {
+		errs["lines"] = "Choose at least one service item"
+	}
+	for _, l := range params.Lines {
+		it, ok := known[DispatchItemKey(l.SourceItemType, l.SourceItemID)]
+		switch {
+		case !ok:
+			errs[l.SourceItemID] = "Not a service item of this project's BOQ"
+		case l.QtyPerSite <= 0:
+			errs[l.SourceItemID] = fmt.Sprintf("%s: quantity per site must be greater than zero", it.Description)
+		case l.Rate < 0:
+			errs[l.SourceItemID] = fmt.Sprintf("%s: rate cannot be negative", it.Description)
+		}
+	}
+
+	if len(params.SiteIDs) == 0 {
+		errs["sites"] = "Choose at least one install-at site"
+		return errs
+	}
+	onOrder, err := siteWorkOrders(app, params.ProjectID)
+	if err != nil {
+		errs["sites"] = "Could not check the sites' existing work orders"
+		return errs
+	}
+	for _, id := range params.SiteIDs {
+		site, err := app.FindRecordById("addresses", id)
+		if err != nil || site.GetString("project") != params.ProjectID || site.GetString("address_type") != "install_at" {
+			errs["sites"] = "Choose install-at sites of this project"
+			break
+		}
+		if existing, ok := onOrder[id]; ok {
+			wo, _ := app.FindRecordById("work_orders", existing.GetString("work_order"))
+			number := ""
+			if wo != nil {
+				number = wo.GetString("wo_number")
+			}
+			errs["sites"] = fmt.Sprintf("%s is already on work order %s", SiteLabel(site), number)
+			break
+		}
+	}
+	return errs
+}
+
+// CreateWorkOrder numbers and saves an installation work order with its
+// lines and sites. Every site starts pending.
+func CreateWorkOrder(ctx context.Context, app *pocketbase.PocketBase, params WorkOrderParams) (*core.Record, error) {
+	if errs := ValidateWorkOrder(app, params); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid work order: %s", strings.Join(slices.Sorted(maps.Values(errs)), "; "))
+	}
+
+	items, err := ServiceBOQItems(app, params.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]WorkOrderItem, len(items))
+	for _, it := range items {
+		known[DispatchItemKey(it.SourceItemType, it.SourceItemID)] = it
+	}
+
+	docDate, _ := time.Parse("2006-01-02", params.WODate)
+	woNumber, err := NextDocNumber(app, params.ProjectID, "wo", docDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate work order number: %w", err)
+	}
+
+	woCol, err := app.FindCollectionByNameOrId("work_orders")
+	if err != nil {
+		return nil, fmt.Errorf("work_orders collection not found: %w", err)
+	}
+	lineCol, err := app.FindCollectionByNameOrId("work_order_lines")
+	if err != nil {
+		return nil, fmt.Errorf("work_order_lines collection not found: %w", err)
+	}
+	siteCol, err := app.FindCollectionByNameOrId("work_order_sites")
+	if err != nil {
+		return nil, fmt.Errorf("work_order_sites collection not found: %w", err)
+	}
+
+	wo := core.NewRecord(woCol)
+	err = app.RunInTransaction(func(txApp core.App) error {
+		wo.Set("project", params.ProjectID)
+		wo.Set("wo_number", woNumber)
+		wo.Set("installer", params.InstallerID)
+		wo.Set("wo_date", params.WODate)
+		wo.Set("target_date", params.TargetDate)
+		wo.Set("remarks", strings.TrimSpace(params.Remarks))
+		if err := txApp.SaveWithContext(ctx, wo); err != nil {
+			return fmt.Errorf("failed to create work order: %w", err)
+		}
+
+		for i, l := range params.Lines {
+			it := known[DispatchItemKey(l.SourceItemType, l.SourceItemID)]
+			line := core.NewRecord(lineCol)
+			line.Set("work_order", wo.Id)
+			line.Set("source_item_type", l.SourceItemType)
+			line.Set("source_item_id", l.SourceItemID)
+			line.Set("description", it.Description)
+			line.Set("uom", it.UOM)
+			line.Set("qty_per_site", l.QtyPerSite)
+			line.Set("rate", l.Rate)
+			line.Set("line_order", i+1)
+			if err := txApp.SaveWithContext(ctx, line); err != nil {
+				return fmt.Errorf("failed to create work order line: %w", err)
+			}
+		}
+
+		for _, siteID := range params.SiteIDs {
+			site := core.NewRecord(siteCol)
+			site.Set("work_order", wo.Id)
+			site.Set("install_at_address", siteID)
+			site.Set("status", SiteStatusPending)
+			if err := txApp.SaveWithContext(ctx, site); err != nil {
+				return fmt.Errorf("failed to add work order site: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return wo, nil
+}
+
+// SiteDeliveredSerials returns the serial numbers dispatched to an
+// install-at site's ship-to address, by serial number, leaving out serials
+// installed at any other site. Serials are recorded on the transit DC of a
+// shipment or on a transfer DC, so a serial counts as dispatched to a
+// ship-to when any DC of its shipment group was addressed there. Draft and
+// cancelled DCs are ignored.
+func SiteDeliveredSerials(app core.App, siteAddressID string) ([]*core.Record, error) {
+	site, err := app.FindRecordById("addresses", siteAddressID)
+	if err != nil {
+		return nil, fmt.Errorf("site not found: %w", err)
+	}
+	shipTo := site.GetString("ship_to_parent")
+	if shipTo == "" {
+		return nil, nil
+	}
+
+	const live = "status != 'draft' && status != 'cancelled'"
+	dcs, err := app.FindRecordsByFilter("delivery_challans", "ship_to_address = {:addr} && "+live, "", 0, 0, map[string]any{"addr": shipTo})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch delivery challans: %w", err)
+	}
+	dcIDs := make(map[string]bool)
+	groups := make(map[string]bool)
+	for _, dc := range dcs {
+		dcIDs[dc.Id] = true
+		if g := dc.GetString("shipment_group"); g != "" {
+			groups[g] = true
+		}
+	}
+	for g := range groups {
+		groupDCs, err := app.FindRecordsByFilter("delivery_challans", "shipment_group = {:g} && "+live, "", 0, 0, map[string]any{"g": g})
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch shipment DCs: %w", err)
+		}
+		for _, dc := range groupDCs {
+			dcIDs[dc.Id] = true
+		}
+	}
+
+	installedElsewhere := make(map[string]bool)
+	onOrder, err := siteWorkOrders(app, site.GetString("project"))
+	if err != nil {
+		return nil, err
+	}
+	for addressID, s := range onOrder {
+		if addressID == siteAddressID {
+			continue
+		}
+		for _, id := range s.GetStringSlice("serial_numbers") {
+			installedElsewhere[id] = true
+		}
+	}
+
+	var serials []*core.Record
+	for _, dcID := range slices.Sorted(maps.Keys(dcIDs)) {
+		recs, err := app.FindRecordsByFilter("serial_numbers", "line_item.dc = {:dc}", "serial_number", 0, 0, map[string]any{"dc": dcID})
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch serial numbers: %w", err)
+		}
+		for _, r := range recs {
+			if !installedElsewhere[r.Id] {
+				serials = append(serials, r)
+			}
+		}
+	}
+	slices.SortFunc(serials, func(a, b *core.Record) int {
+		return strings.Compare(a.GetString("serial_number"), b.GetString("serial_number"))
+	})
+	return serials, nil
+}
+
+// SiteProgressParams is an update to the installation of one work order
+// site.
+type SiteProgressParams struct {
+	Status           string
+	InstallationDate string
+	Remarks          string
+	SerialIDs        []string
+}
+
+// UpdateWorkOrderSite records the installation progress of a work order
+// site. Installed and commissioned sites need an installation date, and
+// serials can only be picked from those dispatched to the site (see
+// SiteDeliveredSerials). Sites are only marked commissioned by
+// CreateCommissioning.
+func UpdateWorkOrderSite(ctx context.Context, app *pocketbase.PocketBase, siteID string, params SiteProgressParams) error {
+	site, err := app.FindRecordById("work_order_sites", siteID)
+	if err != nil {
+		return fmt.Errorf("work order site not found: %w", err)
+	}
+	if !slices.Contains(SiteStatuses, params.Status) {
+		return fmt.Errorf("unknown installation status %q", params.Status)
+	}
+	commissioned := site.GetString("status") == SiteStatusCommissioned
+	if commissioned != (params.Status == SiteStatusCommissioned) {
+		if commissioned {
+			return fmt.Errorf("the site is already commissioned")
+		}
+		return fmt.Errorf("commission the site from the site tracker, with its test results and the client's sign-off")
+	}
+
+	date := strings.TrimSpace(params.InstallationDate)
+	if date != "" {
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return fmt.Errorf("installation date must be a valid date")
+		}
+		if date > time.Now().Format("2006-01-02") {
+			return fmt.Errorf("installation date cannot be in the future")
+		}
+	}
+	if (params.Status == SiteStatusInstalled || params.Status == SiteStatusCommissioned) && date == "" {
+		return fmt.Errorf("an installation date is required once the site is %s", strings.ToLower(SiteStatusLabel(params.Status)))
+	}
+
+	available, err := SiteDeliveredSerials(app, site.GetString("install_at_address"))
+	if err != nil {
+		return err
+	}
+	allowed := make(map[string]bool, len(available))
+	for _, s := range available {
+		allowed[s.Id] = true
+	}
+	for _, id := range params.SerialIDs {
+		if !allowed[id] {
+			return fmt.Errorf("serial number was not dispatched to this site or is installed elsewhere")
+		}
+	}
+
+	site.Set("status", params.Status)
+	site.Set("installation_date", date)
+	site.Set("remarks", strings.TrimSpace(params.Remarks))
+	site.Set("serial_numbers", params.SerialIDs)
+	if err := app.SaveWithContext(ctx, site); err != nil {
+		return fmt.Errorf("failed to save work order site: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
+	"projectcreation/testhelpers"
+)
+
+// setupWorkOrderTest creates a project with one BOQ service item, an
+// installer and two install-at sites under one ship-to address.
+func setupWorkOrderTest(t *testing.T) (app *pocketbase.PocketBase, project, service, installer, shipTo *core.Record, sites []*core.Record) {
+	t.Helper()
+	app = testhelpers.NewTestApp(t)
+	project = testhelpers.CreateTestProject(t, app, "Install Project")
+	boq := testhelpers.CreateTestBOQ(t, app, project.Id, "Main BOQ")
+	main := testhelpers.CreateTestMainBOQItem(t, app, boq.Id, "Solar pump set")
+	testhelpers.CreateTestSubItem(t, app, main.Id, "Pump")
+	service = testhelpers.CreateTestSubItem(t, app, main.Id, "Installation and commissioning")
+	service.Set("type", "service")
+	service.Set("budgeted_price", 1500)
+	if err := app.Save(service); err != nil {
+		t.Fatalf("failed to save service item: %v", err)
+	}
+	installer = testhelpers.CreateTestVendor(t, app, "Surya Installers")
+
+	shipTo = testhelpers.CreateTestAddress(t, app, project.Id, "ship_to", "Mandal Store")
+	for _, name := range []string{"Farmer A", "Farmer B"} {
+		site := testhelpers.CreateTestAddress(t, app, project.Id, "install_at", name)
+		site.Set("ship_to_parent", shipTo.Id)
+		if err := app.Save(site); err != nil {
+			t.Fatalf("failed to link site to ship-to: %v", err)
+		}
+		sites = append(sites, site)
+	}
+	return app, project, service, installer, shipTo, sites
+}
+
+func TestValidateWorkOrder(t *testing.T) {
+	app, project, service, installer, _, sites := setupWorkOrderTest(t)
+	product, _ := app.FindFirstRecordByData("sub_items", "description", "Pump")
+	valid := WorkOrderParams{
+		ProjectID:   project.Id,
+		InstallerID: installer.Id,
+		WODate:      "2025-06-10",
+		Lines:       []WorkOrderLineParams{{SourceItemType: "sub_item", SourceItemID: service.Id, QtyPerSite: 1, Rate: 1500}},
+		SiteIDs:     []string{sites[0].Id},
+	}
+	if errs := ValidateWorkOrder(app, valid); len(errs) > 0 {
+		t.Fatalf("expected no errors, got %v", errs)
+	}
+
+	tests := []struct {
+		name  string
+		edit  func(p *WorkOrderParams)
+		field string
+	}{
+		{"no installer", func(p *WorkOrderParams) { p.InstallerID = "" }, "installer"},
+		{"target before date", func(p *WorkOrderParams) { p.TargetDate = "2025-06-01" }, "target_date"},
+		{"no lines", func(p *WorkOrderParams) { p.Lines = nil }, "lines"},
+		{"product item", func(p *WorkOrderParams) {
+			p.Lines = []WorkOrderLineParams{{SourceItemType: "sub_item", SourceItemID: product.Id, QtyPerSite: 1}}
+		}, product.Id},
+		{"zero quantity", func(p *WorkOrderParams) {
+			p.Lines = []WorkOrderLineParams{{SourceItemType: "sub_item", SourceItemID: service.Id}}
+		}, service.Id},
+		{"no sites", func(p *WorkOrderParams) { p.SiteIDs = nil }, "sites"},
+		{"not an install-at site", func(p *WorkOrderParams) { p.SiteIDs = []string{sites[0].GetString("ship_to_parent")} }, "sites"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := valid
+			tt.edit(&p)
+			if errs := ValidateWorkOrder(app, p); errs[tt.field] == "" {
+				t.Errorf("expected an error on %s, got %v", tt.field, errs)
+			}
+		})
+	}
+}
+
+func TestCreateWorkOrder_NumbersAndBlocksSecondOrderForSite(t *testing.T) {
+	app, project, service, installer, _, sites := setupWorkOrderTest(t)
+	params := WorkOrderParams{
+		ProjectID:   project.Id,
+		InstallerID: installer.Id,
+		WODate:      "2025-06-10",
+		Lines:       []WorkOrderLineParams{{SourceItemType: "sub_item", SourceItemID: service.Id, QtyPerSite: 1, Rate: 1400}},
+		SiteIDs:     []string{sites[0].Id, sites[1].Id},
+	}
+	wo, err := CreateWorkOrder(context.Background(), app, params)
+	if err != nil {
+		t.Fatalf("CreateWorkOrder failed: %v", err)
+	}
+	if !strings.Contains(wo.GetString("wo_number"), "WO") {
+		t.Errorf("expected a WO number, got %q", wo.GetString("wo_number"))
+	}
+
+	lines, _ := app.FindRecordsByFilter("work_order_lines", "work_order = {:id}", "", 0, 0, map[string]any{"id": wo.Id})
+	if len(lines) != 1 || lines[0].GetString("description") != "Installation and commissioning" || lines[0].GetFloat("rate") != 1400 {
+		t.Errorf("unexpected work order lines %v", lines)
+	}
+	woSites, _ := app.FindRecordsByFilter("work_order_sites", "work_order = {:id}", "", 0, 0, map[string]any{"id": wo.Id})
+	if len(woSites) != 2 || woSites[0].GetString("status") != SiteStatusPending {
+		t.Errorf("expected 2 pending sites, got %v", woSites)
+	}
+
+	params.SiteIDs = []string{sites[1].Id}
+	errs := ValidateWorkOrder(app, params)
+	if !strings.Contains(errs["sites"], wo.GetString("wo_number")) {
+		t.Errorf("expected the site to be reported on %s, got %v", wo.GetString("wo_number"), errs)
+	}
+}
+
+func TestUpdateWorkOrderSite_InstalledSerials(t *testing.T) {
+	app, project, service, installer, shipTo, sites := setupWorkOrderTest(t)
+	ctx := context.Background()
+
+	_, _, official := issuedShipmentGroup(t, app, project.Id, "001", "PMP-2", "PMP-1")
+	official.Set("ship_to_address", shipTo.Id)
+	if err := app.Save(official); err != nil {
+		t.Fatalf("failed to address the DC: %v", err)
+	}
+	// Serials shipped elsewhere are not offered
+	issuedShipmentGroup(t, app, project.Id, "002", "PMP-9")
+
+	wo, err := CreateWorkOrder(ctx, app, WorkOrderParams{
+		ProjectID:   project.Id,
+		InstallerID: installer.Id,
+		WODate:      "2025-06-10",
+		Lines:       []WorkOrderLineParams{{SourceItemType: "sub_item", SourceItemID: service.Id, QtyPerSite: 1, Rate: 1500}},
+		SiteIDs:     []string{sites[0].Id, sites[1].Id},
+	})
+	if err != nil {
+		t.Fatalf("CreateWorkOrder failed: %v", err)
+	}
+	first, _ := app.FindFirstRecordByFilter("work_order_sites", "work_order = {:wo} && install_at_address = {:a}", map[string]any{"wo": wo.Id, "a": sites[0].Id})
+	second, _ := app.FindFirstRecordByFilter("work_order_sites", "work_order = {:wo} && install_at_address = {:a}", map[string]any{"wo": wo.Id, "a": sites[1].Id})
+
+	serials, err := SiteDeliveredSerials(app, sites[0].Id)
+	if err != nil {
+		t.Fatalf("SiteDeliveredSerials failed: %v", err)
+	}
+	if len(serials) != 2 || serials[0].GetString("serial_number") != "PMP-1" {
+		t.Fatalf("expected PMP-1 and PMP-2, got %v", serials)
+	}
+
+	today := time.Now().Format("2006-01-02")
+	if err := UpdateWorkOrderSite(ctx, app, first.Id, SiteProgressParams{Status: SiteStatusInstalled}); err == nil {
+		t.Error("expected an installed site without a date to fail")
+	}
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+	if err := UpdateWorkOrderSite(ctx, app, first.Id, SiteProgressParams{Status: SiteStatusInstalled, InstallationDate: tomorrow}); err == nil {
+		t.Error("expected a future installation date to fail")
+	}
+	stray, _ := app.FindFirstRecordByData("serial_numbers", "serial_number", "PMP-9")
+	if err := UpdateWorkOrderSite(ctx, app, first.Id, SiteProgressParams{Status: SiteStatusInstalled, InstallationDate: today, SerialIDs: []string{stray.Id}}); err == nil {
+		t.Error("expected a serial shipped elsewhere to be rejected")
+	}
+	if err := UpdateWorkOrderSite(ctx, app, first.Id, SiteProgressParams{Status: SiteStatusInstalled, InstallationDate: today, SerialIDs: []string{serials[0].Id}}); err != nil {
+		t.Fatalf("UpdateWorkOrderSite failed: %v", err)
+	}
+
+	first, _ = app.FindRecordById("work_order_sites", first.Id)
+	if first.GetString("status") != SiteStatusInstalled || len(first.GetStringSlice("serial_numbers")) != 1 {
+		t.Errorf("expected an installed site with 1 serial, got %s with %v", first.GetString("status"), first.GetStringSlice("serial_numbers"))
+	}
+
+	// A serial installed at one site is no longer offered at the other
+	remaining, _ := SiteDeliveredSerials(app, sites[1].Id)
+	if len(remaining) != 1 || remaining[0].GetString("serial_number") != "PMP-2" {
+		t.Errorf("expected only PMP-2 left for the second site, got %v", remaining)
+	}
+	if err := UpdateWorkOrderSite(ctx, app, second.Id, SiteProgressParams{Status: SiteStatusInProgress, SerialIDs: []string{serials[0].Id}}); err == nil {
+		t.Error("expected a serial installed at another site to be rejected")
+	}
+}
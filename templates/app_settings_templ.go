@@ -128,7 +128,7 @@ func AppSettingsContent(data AppSettingsData) templ.Component {
 				return templ_7745c5c3_Err
 			}
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 13, "<p style=\"font-family: 'Inter', sans-serif; font-size: 11px; color: var(--text-muted); margin-top: 8px;\">Used in the header and as the company name on PDF exports.</p></div><!-- Save Button --><div style=\"display: flex; justify-content: flex-end;\"><button type=\"submit\" style=\"padding: 10px 32px; cursor: pointer; font-family: 'Space Grotesk', sans-serif; font-size: 12px; font-weight: 600; letter-spacing: 0.05em; text-transform: uppercase; color: white; background-color: var(--terracotta); border: none;\">SAVE SETTINGS</button></div></form></div>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 13, "<p style=\"font-family: 'Inter', sans-serif; font-size: 11px; color: var(--text-muted); margin-top: 8px;\">Used in the header and as the company name on PDF exports.</p></div><!-- Save Button --><div style=\"display: flex; justify-content: flex-end;\"><button type=\"submit\" style=\"padding: 10px 32px; cursor: pointer; font-family: 'Space Grotesk', sans-serif; font-size: 12px; font-weight: 600; letter-spacing: 0.05em; text-transform: uppercase; color: white; background-color: var(--terracotta); border: none;\">SAVE SETTINGS</button></div></form><!-- TDS Sections --><div class=\"flex items-center justify-between\" style=\"padding: 24px 32px; margin-top: 24px; background-color: var(--bg-card);\"><div><div style=\"font-family: 'Space Grotesk', sans-serif; font-size: 11px; font-weight: 600; letter-spacing: 0.05em; text-transform: uppercase; color: var(--text-secondary);\">TDS SECTIONS</div><p style=\"font-family: 'Inter', sans-serif; font-size: 13px; color: var(--text-secondary); margin-top: 6px;\">Sections and rates available when deducting TDS on vendor payments.</p></div><a href=\"/settings/tds\" hx-get=\"/settings/tds\" hx-target=\"#main-content\" hx-push-url=\"true\" style=\"font-family: 'Space Grotesk', sans-serif; font-size: 11px; font-weight: 600; letter-spacing: 0.05em; color: var(--terracotta); text-decoration: none;\">MANAGE</a></div><!-- Cost Categories --><div class=\"flex items-center justify-between\" style=\"padding: 24px 32px; margin-top: 24px; background-color: var(--bg-card);\"><div><div style=\"font-family: 'Space Grotesk', sans-serif; font-size: 11px; font-weight: 600; letter-spacing: 0.05em; text-transform: uppercase; color: var(--text-secondary);\">COST CATEGORIES</div><p style=\"font-family: 'Inter', sans-serif; font-size: 13px; color: var(--text-secondary); margin-top: 6px;\">Categories project budgets and costs are tracked against.</p></div><a href=\"/settings/cost-categories\" hx-get=\"/settings/cost-categories\" hx-target=\"#main-content\" hx-push-url=\"true\" style=\"font-family: 'Space Grotesk', sans-serif; font-size: 11px; font-weight: 600; letter-spacing: 0.05em; color: var(--terracotta); text-decoration: none;\">MANAGE</a></div></div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
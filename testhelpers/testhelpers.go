@@ -14,7 +14,7 @@ import (
 // NewTestApp creates a PocketBase instance backed by a temporary directory.
 // It bootstraps the app and runs collections.Setup to create all tables.
 // The temporary directory is cleaned up automatically when the test finishes.
-func NewTestApp(t *testing.T) *pocketbase.PocketBase {
+func NewTestApp(t testing.TB) *pocketbase.PocketBase {
 	t.Helper()
 
 	tmpDir := t.TempDir()
@@ -32,7 +32,7 @@ func NewTestApp(t *testing.T) *pocketbase.PocketBase {
 }
 
 // CreateTestProject creates a project record with the given name and returns it.
-func CreateTestProject(t *testing.T, app *pocketbase.PocketBase, name string) *core.Record {
+func CreateTestProject(t testing.TB, app *pocketbase.PocketBase, name string) *core.Record {
 	t.Helper()
 
 	col, err := app.FindCollectionByNameOrId("projects")
@@ -353,6 +353,26 @@ func CreateTestShipmentGroup(t *testing.T, app *pocketbase.PocketBase, projectID
 	return record
 }
 
+// CreateTestUser creates a users record with the given role and project
+// memberships. The password is always "password123".
+func CreateTestUser(t *testing.T, app *pocketbase.PocketBase, email, role string, projectIDs ...string) *core.Record {
+	t.Helper()
+	col, err := app.FindCollectionByNameOrId("users")
+	if err != nil {
+		t.Fatalf("failed to find users collection: %v", err)
+	}
+	record := core.NewRecord(col)
+	record.SetEmail(email)
+	record.SetPassword("password123")
+	record.Set("name", "Test User")
+	record.Set("role", role)
+	record.Set("projects", projectIDs)
+	if err := app.Save(record); err != nil {
+		t.Fatalf("failed to save test user: %v", err)
+	}
+	return record
+}
+
 func truncate(s string, n int) string {
 	if len(s) <= n {
 		return s